@@ -135,18 +135,25 @@ func readPolicyDefinition(bindingReqParams policyAndBindingCfg) *models.PolicyDe
 	}
 
 	policyDefinition := models.PolicyDefinition{
-		InstanceMin: bindingReqParams.InstanceMin,
-		InstanceMax: bindingReqParams.InstanceMax,
+		InstanceMin:             bindingReqParams.InstanceMin,
+		InstanceMax:             bindingReqParams.InstanceMax,
+		ScaleOutCoolDownSeconds: bindingReqParams.ScaleOutCoolDownSeconds,
+		ScaleInCoolDownSeconds:  bindingReqParams.ScaleInCoolDownSeconds,
 	}
 
 	for _, rule := range bindingReqParams.ScalingRules {
 		scalingRule := &models.ScalingRule{
-			MetricType:            rule.MetricType,
-			BreachDurationSeconds: rule.BreachDurationSeconds,
-			Threshold:             rule.Threshold,
-			Operator:              rule.Operator,
-			CoolDownSeconds:       rule.CoolDownSeconds,
-			Adjustment:            rule.Adjustment,
+			MetricType:              rule.MetricType,
+			BreachDurationSeconds:   rule.BreachDurationSeconds,
+			Threshold:               rule.Threshold,
+			Operator:                rule.Operator,
+			CoolDownSeconds:         rule.CoolDownSeconds,
+			ScaleOutCoolDownSeconds: rule.ScaleOutCoolDownSeconds,
+			ScaleInCoolDownSeconds:  rule.ScaleInCoolDownSeconds,
+			Adjustment:              rule.Adjustment,
+			EmergencyThreshold:      rule.EmergencyThreshold,
+			EmergencyAdjustment:     rule.EmergencyAdjustment,
+			SourceAppId:             rule.SourceAppId,
 		}
 		policyDefinition.ScalingRules = append(policyDefinition.ScalingRules, scalingRule)
 	}
@@ -185,5 +192,42 @@ func readPolicyDefinition(bindingReqParams policyAndBindingCfg) *models.PolicyDe
 		}
 	}
 
+	if bindingReqParams.BlackoutPeriods != nil {
+		policyDefinition.BlackoutPeriods = &models.BlackoutPeriods{
+			Timezone: bindingReqParams.BlackoutPeriods.Timezone,
+		}
+
+		for _, recurring := range bindingReqParams.BlackoutPeriods.RecurringBlackouts {
+			recurringBlackout := &models.RecurringBlackout{
+				StartTime:   recurring.StartTime,
+				EndTime:     recurring.EndTime,
+				DaysOfWeek:  recurring.DaysOfWeek,
+				DaysOfMonth: recurring.DaysOfMonth,
+				StartDate:   recurring.StartDate,
+				EndDate:     recurring.EndDate,
+				Mode:        models.BlackoutMode(recurring.Mode),
+			}
+			policyDefinition.BlackoutPeriods.RecurringBlackouts = append(
+				policyDefinition.BlackoutPeriods.RecurringBlackouts, recurringBlackout)
+		}
+
+		for _, specific := range bindingReqParams.BlackoutPeriods.SpecificDateBlackouts {
+			specificDateBlackout := &models.SpecificDateBlackout{
+				StartDateTime: specific.StartDateTime,
+				EndDateTime:   specific.EndDateTime,
+				Mode:          models.BlackoutMode(specific.Mode),
+			}
+			policyDefinition.BlackoutPeriods.SpecificDateBlackouts = append(
+				policyDefinition.BlackoutPeriods.SpecificDateBlackouts, specificDateBlackout)
+		}
+	}
+
+	if bindingReqParams.InstanceHourBudget != nil {
+		policyDefinition.InstanceHourBudget = &models.InstanceHourBudget{
+			InstanceHours: bindingReqParams.InstanceHourBudget.InstanceHours,
+			Period:        models.BudgetPeriod(bindingReqParams.InstanceHourBudget.Period),
+		}
+	}
+
 	return &policyDefinition
 }
@@ -1,13 +1,17 @@
 package v0_1
 
 type parameters struct {
-	SchemaVersion  string           `json:"schema-version"`
-	CredentialType string           `json:"credential-type,omitempty"`
-	Configuration  *bindingCfg      `json:"configuration,omitempty"`
-	InstanceMin    int              `json:"instance_min_count,omitempty"`
-	InstanceMax    int              `json:"instance_max_count,omitempty"`
-	ScalingRules   []scalingRule    `json:"scaling_rules,omitempty"`
-	Schedules      *scalingSchedule `json:"schedules,omitempty"`
+	SchemaVersion        string              `json:"schema-version"`
+	CredentialType       string              `json:"credential-type,omitempty"`
+	Configuration        *bindingCfg         `json:"configuration,omitempty"`
+	InstanceMin          int                 `json:"instance_min_count,omitempty"`
+	InstanceMax          int                 `json:"instance_max_count,omitempty"`
+	ScalingRules         []scalingRule       `json:"scaling_rules,omitempty"`
+	Schedules            *scalingSchedule    `json:"schedules,omitempty"`
+	BlackoutPeriods      *blackoutPeriods    `json:"blackout_periods,omitempty"`
+	InstanceHourBudget   *instanceHourBudget `json:"instance_hour_budget,omitempty"`
+	ScaleOutCoolDownSecs int                 `json:"scale_out_cooldown_secs,omitempty"`
+	ScaleInCoolDownSecs  int                 `json:"scale_in_cooldown_secs,omitempty"`
 }
 
 type bindingCfg struct {
@@ -24,13 +28,18 @@ type metricSubmStrat struct {
 }
 
 type scalingRule struct {
-	MetricType         string `json:"metric_type"`
-	BreachDurationSecs int    `json:"breach_duration_secs,omitempty"`
-	StatsWindowSecs    int    `json:"stats_window_secs,omitempty"`
-	Threshold          int64  `json:"threshold"`
-	Operator           string `json:"operator"`
-	CoolDownSecs       int    `json:"cool_down_secs,omitempty"`
-	Adjustment         string `json:"adjustment"`
+	MetricType           string `json:"metric_type"`
+	BreachDurationSecs   int    `json:"breach_duration_secs,omitempty"`
+	StatsWindowSecs      int    `json:"stats_window_secs,omitempty"`
+	Threshold            int64  `json:"threshold"`
+	Operator             string `json:"operator"`
+	CoolDownSecs         int    `json:"cool_down_secs,omitempty"`
+	ScaleOutCoolDownSecs int    `json:"scale_out_cooldown_secs,omitempty"`
+	ScaleInCoolDownSecs  int    `json:"scale_in_cooldown_secs,omitempty"`
+	Adjustment           string `json:"adjustment"`
+	EmergencyThreshold   *int64 `json:"emergency_threshold,omitempty"`
+	EmergencyAdjustment  string `json:"emergency_adjustment,omitempty"`
+	SourceAppId          string `json:"source_app_id,omitempty"`
 }
 
 type scalingSchedule struct {
@@ -58,3 +67,30 @@ type specificDate struct {
 	InstanceMaxCount        int    `json:"instance_max_count"`
 	InitialMinInstanceCount int    `json:"initial_min_instance_count,omitempty"`
 }
+
+type blackoutPeriods struct {
+	Timezone          string                 `json:"timezone"`
+	RecurringBlackout []recurringBlackout    `json:"recurring_blackout,omitempty"`
+	SpecificDate      []specificDateBlackout `json:"specific_date,omitempty"`
+}
+
+type recurringBlackout struct {
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	DaysOfWeek  []int  `json:"days_of_week,omitempty"`
+	DaysOfMonth []int  `json:"days_of_month,omitempty"`
+	StartDate   string `json:"start_date,omitempty"`
+	EndDate     string `json:"end_date,omitempty"`
+	Mode        string `json:"mode"`
+}
+
+type specificDateBlackout struct {
+	StartDateTime string `json:"start_date_time"`
+	EndDateTime   string `json:"end_date_time"`
+	Mode          string `json:"mode"`
+}
+
+type instanceHourBudget struct {
+	InstanceHours int    `json:"instance_hours"`
+	Period        string `json:"period"`
+}
@@ -3,6 +3,7 @@ package broker
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/brokerapi/v13/domain"
 	"code.cloudfoundry.org/brokerapi/v13/domain/apiresponses"
+	"code.cloudfoundry.org/brokerapi/v13/middlewares"
 	"code.cloudfoundry.org/lager/v3"
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
@@ -52,6 +54,7 @@ var (
 	ErrDeleteSchedulesForUnbinding  = errors.New("failed to delete schedules for unbinding")
 	ErrBindingDoesNotExist          = errors.New("service binding does not exist")
 	ErrDeletePolicyForUnbinding     = errors.New("failed to delete policy for unbinding")
+	ErrDeleteAppGroupForUnbinding   = errors.New("failed to remove app from its app group for unbinding")
 	ErrDeleteServiceBinding         = errors.New("error deleting service binding")
 	ErrCredentialNotDeleted         = errors.New("failed to delete custom metrics credential for unbinding")
 	ErrInvalidCredentialType        = errors.New("invalid credential type provided: allowed values are [binding-secret, x509]") // 🚧 To-do: Unused?
@@ -86,26 +89,10 @@ func New(
 		conf.ScalingRules.DiskUtil.UpperThreshold,
 		conf.ScalingRules.Disk.LowerThreshold,
 		conf.ScalingRules.Disk.UpperThreshold,
+		conf.MetricCollectionInterval,
 	)
 
-	defaultCustomMetricsCredentialType := &models.X509Certificate
-	isBasicAuthAvailable := conf.CustomMetricsAuthConfig != nil
-	if isBasicAuthAvailable {
-		defaultCustomMetricsCredentialType = &conf.CustomMetricsAuthConfig.DefaultCustomMetricAuthType
-	}
-
-	pathToParserDir, err := filepath.Abs(filepath.Dir(conf.BindingRequestSchemaPath))
-	// We need the absolute path because our json-schema-library "gojsonschema" can not deal with
-	// relative paths in "$ref"s.
-	if err != nil {
-		logger.Fatal("resolve-binding-request-schema-path", err)
-	}
-
-	pathToLegacySchema := fmt.Sprintf("file://%s/legacy/schema.json", pathToParserDir)
-	pathToV0_1Schema := fmt.Sprintf("file://%s/v0_1/meta.schema.json", pathToParserDir)
-	bindingReqParser, err := brParser.New(
-		pathToLegacySchema, pathToV0_1Schema, *defaultCustomMetricsCredentialType)
-
+	bindingReqParser, err := NewBindingRequestParser(conf)
 	if err != nil {
 		logger.Fatal("create-binding-request-parser", err)
 	}
@@ -126,6 +113,27 @@ func New(
 	return broker
 }
 
+// NewBindingRequestParser creates the parser for binding-requests from the schemas that are
+// located next to the configured policy-schema.
+func NewBindingRequestParser(conf *config.Config) (brParser.BindRequestParser, error) {
+	defaultCustomMetricsCredentialType := &models.X509Certificate
+	isBasicAuthAvailable := conf.CustomMetricsAuthConfig != nil
+	if isBasicAuthAvailable {
+		defaultCustomMetricsCredentialType = &conf.CustomMetricsAuthConfig.DefaultCustomMetricAuthType
+	}
+
+	pathToParserDir, err := filepath.Abs(filepath.Dir(conf.BindingRequestSchemaPath))
+	// We need the absolute path because our json-schema-library "gojsonschema" can not deal with
+	// relative paths in "$ref"s.
+	if err != nil {
+		return brParser.BindRequestParser{}, fmt.Errorf("failed to resolve binding-request-schema-path: %w", err)
+	}
+
+	pathToLegacySchema := fmt.Sprintf("file://%s/legacy/schema.json", pathToParserDir)
+	pathToV0_1Schema := fmt.Sprintf("file://%s/v0_1/meta.schema.json", pathToParserDir)
+	return brParser.New(pathToLegacySchema, pathToV0_1Schema, *defaultCustomMetricsCredentialType)
+}
+
 // Services gets the catalog of services offered by the service broker
 // GET /v2/catalog
 func (b *Broker) Services(_ context.Context) ([]domain.Service, error) {
@@ -181,6 +189,10 @@ func (b *Broker) Provision(ctx context.Context, instanceID string, details domai
 		if err := b.planDefinitionExceeded(policy.GetPolicyDefinition(), details.PlanID, instanceID); err != nil {
 			return result, err
 		}
+		sourceAppIds := policy.GetPolicyDefinition().SourceAppIds("")
+		if err := b.checkSourceAppsInSpace(logger, ctx, models.GUID(details.SpaceGUID), sourceAppIds); err != nil {
+			return result, err
+		}
 	}
 
 	var policyStr, policyGuidStr string
@@ -277,6 +289,7 @@ func (b *Broker) Deprovision(ctx context.Context, instanceID string, details dom
 		wrappedError := fmt.Errorf("service binding deletion failed: %w", err)
 		if err != nil && (errors.Is(err, ErrDeleteServiceBinding) ||
 			errors.Is(err, ErrDeletePolicyForUnbinding) ||
+			errors.Is(err, ErrDeleteAppGroupForUnbinding) ||
 			errors.Is(err, ErrDeleteSchedulesForUnbinding) ||
 			errors.Is(err, ErrCredentialNotDeleted)) {
 			logger.Error("delete-bindings-of-service-instance-to-be-deleted", wrappedError)
@@ -359,6 +372,13 @@ func (b *Broker) Update(ctx context.Context, instanceID string, details domain.U
 		return result, err
 	}
 
+	if defaultPolicyIsNew {
+		sourceAppIds := defaultPolicy.SourceAppIds("")
+		if err := b.checkSourceAppsInSpace(logger, ctx, models.GUID(serviceInstance.SpaceId), sourceAppIds); err != nil {
+			return result, err
+		}
+	}
+
 	if !servicePlanIsNew && !defaultPolicyIsNew {
 		logger.Info("no-changes-requested")
 		return result, nil
@@ -448,7 +468,8 @@ func (b *Broker) setDefaultPolicyOnApps(ctx context.Context, updatedDefaultPolic
 	instanceID := serviceInstance.ServiceInstanceId
 	b.logger.Info("update-service-instance-set-or-update", lager.Data{"instanceID": instanceID, "updatedDefaultPolicy": updatedDefaultPolicy, "updatedDefaultPolicyGuid": updatedDefaultPolicyGuid, "allBoundApps": allBoundApps, "serviceInstance": serviceInstance})
 
-	appIds, err := b.policydb.SetOrUpdateDefaultAppPolicy(ctx, allBoundApps, serviceInstance.DefaultPolicyGuid, updatedDefaultPolicy, updatedDefaultPolicyGuid)
+	change := models.PolicyChange{Author: originatingUser(ctx), Source: models.PolicySourceDefaultPolicy}
+	appIds, err := b.policydb.SetOrUpdateDefaultAppPolicy(ctx, allBoundApps, serviceInstance.DefaultPolicyGuid, updatedDefaultPolicy, updatedDefaultPolicyGuid, change)
 	if err != nil {
 		b.logger.Error("failed to set default policies", err, lager.Data{"instanceID": instanceID})
 		return apiresponses.NewFailureResponse(errors.New("failed to set default policy"), http.StatusInternalServerError, "updating-default-policy")
@@ -614,6 +635,10 @@ func (b *Broker) Bind(
 		}
 	}
 
+	if err := b.checkBindingSourceAppsInSpace(logger, ctx, instanceID, details, appScalingConfig); err != nil {
+		return result, err
+	}
+
 	if err := b.planDefinitionExceeded(appScalingConfig.GetScalingPolicy().GetPolicyDefinition(), details.PlanID, instanceID); err != nil {
 		return result, err
 	}
@@ -679,8 +704,14 @@ func (b *Broker) Bind(
 		return result, err
 	}
 
+	var policyWarnings []string
+	for _, warning := range b.policyValidator.Lint(appScalingConfig.GetScalingPolicy().GetPolicyDefinition()) {
+		policyWarnings = append(policyWarnings, warning.Description)
+	}
+
 	result.Credentials = models.Credentials{
-		CustomMetrics: *customMetricsCredentials,
+		CustomMetrics:  *customMetricsCredentials,
+		PolicyWarnings: policyWarnings,
 	}
 	return result, nil
 }
@@ -746,19 +777,10 @@ func (b *Broker) checkAppInSpace(
 	instanceID string, details domain.BindDetails, appScalingConfig models.AppScalingConfig,
 ) error {
 	appGUID := appScalingConfig.GetConfiguration().GetAppGUID()
-	var instanceSpaceGuid models.GUID
-
-	cloudcontrollerProvidesSpaceGuid := details.BindResource != nil && details.BindResource.SpaceGuid != ""
-	if cloudcontrollerProvidesSpaceGuid {
-		instanceSpaceGuid = models.GUID(details.BindResource.SpaceGuid)
-	} else {
-		serviceInstance, err := b.bindingdb.GetServiceInstance(ctx, instanceID)
-		if err != nil {
-			logger.Error("get-service-instance-for-service-key-bind", err, lager.Data{"instanceID": instanceID})
-			return apiresponses.NewFailureResponse(
-				fmt.Errorf("internal error"), http.StatusInternalServerError, "create-service-key")
-		}
-		instanceSpaceGuid = models.GUID(serviceInstance.SpaceId)
+	instanceSpaceGuid, err := b.getInstanceSpaceGuid(logger, ctx, instanceID, details)
+	if err != nil {
+		return apiresponses.NewFailureResponse(
+			fmt.Errorf("internal error"), http.StatusInternalServerError, "create-service-key")
 	}
 
 	appData, err := b.cfClient.GetApp(ctx, appGUID)
@@ -789,6 +811,78 @@ func (b *Broker) checkAppInSpace(
 	return nil
 }
 
+// getInstanceSpaceGuid returns the guid of the space of a service instance, as provided by the cloudcontroller with
+// the binding request or otherwise as stored for the service instance.
+func (b *Broker) getInstanceSpaceGuid(
+	logger lager.Logger, ctx context.Context, instanceID string, details domain.BindDetails,
+) (models.GUID, error) {
+	cloudcontrollerProvidesSpaceGuid := details.BindResource != nil && details.BindResource.SpaceGuid != ""
+	if cloudcontrollerProvidesSpaceGuid {
+		return models.GUID(details.BindResource.SpaceGuid), nil
+	}
+
+	serviceInstance, err := b.bindingdb.GetServiceInstance(ctx, instanceID)
+	if err != nil {
+		logger.Error("get-service-instance-for-bind", err, lager.Data{"instanceID": instanceID})
+		return "", err
+	}
+	return models.GUID(serviceInstance.SpaceId), nil
+}
+
+// checkBindingSourceAppsInSpace checks that the source apps of the policy of a binding request are in the space of
+// the service instance.
+func (b *Broker) checkBindingSourceAppsInSpace(
+	logger lager.Logger, ctx context.Context,
+	instanceID string, details domain.BindDetails, appScalingConfig models.AppScalingConfig,
+) error {
+	appGUID := appScalingConfig.GetConfiguration().GetAppGUID()
+	sourceAppIds := appScalingConfig.GetScalingPolicy().GetPolicyDefinition().SourceAppIds(string(appGUID))
+	if len(sourceAppIds) == 0 {
+		return nil
+	}
+
+	instanceSpaceGuid, err := b.getInstanceSpaceGuid(logger, ctx, instanceID, details)
+	if err != nil {
+		return apiresponses.NewFailureResponse(
+			fmt.Errorf("internal error"), http.StatusInternalServerError, "get-service-instance-for-bind")
+	}
+	return b.checkSourceAppsInSpace(logger, ctx, instanceSpaceGuid, sourceAppIds)
+}
+
+// ☢️ Like `checkAppInSpace`, this check prevents users from scaling their apps on the metrics of
+// apps of other users and orgs! It applies to the policies of bindings as well as to default
+// policies, which are attached to all apps bound without a policy.
+func (b *Broker) checkSourceAppsInSpace(
+	logger lager.Logger, ctx context.Context, instanceSpaceGuid models.GUID, sourceAppIds []string,
+) error {
+	for _, sourceAppId := range sourceAppIds {
+		sourceApp, err := b.cfClient.GetApp(ctx, cf.Guid(sourceAppId))
+		switch {
+		case cf.IsNotFound(err):
+			err := fmt.Errorf("source app %s not found", sourceAppId)
+			logger.Info("source-app-not-found", lager.Data{"sourceAppId": sourceAppId})
+			return apiresponses.NewFailureResponseBuilder(
+				err, http.StatusUnprocessableEntity, "source-app-not-in-service-instance-space").
+				WithErrorKey("SourceAppNotInSpace").Build()
+		case err != nil:
+			logger.Error("cf-client-get-source-app", err, lager.Data{"sourceAppId": sourceAppId})
+			return apiresponses.NewFailureResponse(
+				fmt.Errorf("internal error"), http.StatusInternalServerError, "cf-client-get-source-app")
+		}
+
+		sourceAppIsInSpace := sourceApp.Relationships.Space != nil &&
+			models.GUID(sourceApp.Relationships.Space.Data.Guid) == instanceSpaceGuid
+		if !sourceAppIsInSpace {
+			err := fmt.Errorf("source app %s not found in space %s", sourceAppId, instanceSpaceGuid)
+			logger.Info("source-app-not-in-service-instance-space", lager.Data{"sourceAppId": sourceAppId, "instanceSpaceGuid": instanceSpaceGuid})
+			return apiresponses.NewFailureResponseBuilder(
+				err, http.StatusUnprocessableEntity, "source-app-not-in-service-instance-space").
+				WithErrorKey("SourceAppNotInSpace").Build()
+		}
+	}
+	return nil
+}
+
 func (b *Broker) attachPolicyOrDefaultPolicyToApp(
 	ctx context.Context,
 	instanceID string, appGUID models.GUID,
@@ -800,7 +894,7 @@ func (b *Broker) attachPolicyOrDefaultPolicyToApp(
 	if policyProvided := policyDefinition != nil; policyProvided {
 		logger.Info("saving policy")
 
-		if err := attachPolicyToApp(ctx, b, appGUID, policyDefinition, policyGuidStr, logger); err != nil {
+		if err := attachPolicyToApp(ctx, b, appGUID, policyDefinition, policyGuidStr, models.PolicySourceBrokerBind, logger); err != nil {
 			return err
 		}
 	} else {
@@ -825,7 +919,7 @@ func (b *Broker) attachPolicyOrDefaultPolicyToApp(
 			}
 			defaultPolicyGuid := serviceInstance.DefaultPolicyGuid
 
-			if err := attachPolicyToApp(ctx, b, appGUID, defaultScalingPolicy.GetPolicyDefinition(), defaultPolicyGuid, logger); err != nil {
+			if err := attachPolicyToApp(ctx, b, appGUID, defaultScalingPolicy.GetPolicyDefinition(), defaultPolicyGuid, models.PolicySourceDefaultPolicy, logger); err != nil {
 				return err
 			}
 		} else {
@@ -839,7 +933,7 @@ func (b *Broker) attachPolicyOrDefaultPolicyToApp(
 func attachPolicyToApp(
 	ctx context.Context, b *Broker, appGUID models.GUID,
 	policyDefinition *models.PolicyDefinition, policyGuidStr string,
-	logger lager.Logger,
+	source models.PolicySource, logger lager.Logger,
 ) error {
 	if policyDefinition == nil {
 		return &models.InvalidArgumentError{
@@ -850,7 +944,8 @@ func attachPolicyToApp(
 	}
 	appGUIDStr := string(appGUID)
 
-	if err := b.policydb.SaveAppPolicy(ctx, appGUIDStr, policyDefinition, policyGuidStr); err != nil {
+	change := models.PolicyChange{Author: originatingUser(ctx), Source: source}
+	if err := b.policydb.SaveAppPolicy(ctx, appGUIDStr, policyDefinition, policyGuidStr, change); err != nil {
 		logger.Error("save-appGUID-policy", err)
 		//failed to save policy, so revert creating binding and custom metrics credential
 		err = b.credentials.Delete(ctx, appGUIDStr)
@@ -899,6 +994,7 @@ func (b *Broker) handleExistingBindingsResiliently(ctx context.Context, instance
 				logger.Error("failed to deleteBinding", err, lager.Data{"existingBindingID": existingBindingId})
 				if errors.Is(err, ErrDeleteServiceBinding) ||
 					errors.Is(err, ErrDeletePolicyForUnbinding) ||
+					errors.Is(err, ErrDeleteAppGroupForUnbinding) ||
 					errors.Is(err, ErrDeleteSchedulesForUnbinding) ||
 					errors.Is(err, ErrCredentialNotDeleted) {
 					return apiresponses.NewFailureResponse(
@@ -1077,12 +1173,19 @@ func (b *Broker) deleteBinding(ctx context.Context, bindingId string, serviceIns
 	}
 
 	logger.Info("deleting policy json")
-	err = b.policydb.DeletePolicy(ctx, appId)
+	err = b.policydb.DeletePolicyAndRevisions(ctx, appId)
 	if err != nil {
 		logger.Error("failed to delete policy for unbinding", err)
 		return ErrDeletePolicyForUnbinding
 	}
 
+	logger.Info("removing app from app group")
+	err = b.removeFromAppGroup(ctx, appId)
+	if err != nil {
+		logger.Error("failed to remove app from app group for unbinding", err)
+		return ErrDeleteAppGroupForUnbinding
+	}
+
 	logger.Info("deleting schedules")
 	err = b.schedulerUtil.DeleteSchedule(ctx, appId)
 	if err != nil {
@@ -1115,6 +1218,22 @@ func (b *Broker) deleteBinding(ctx context.Context, bindingId string, serviceIns
 	return nil
 }
 
+// removeFromAppGroup removes an app which is unbound from the app group it belongs to. The group is deleted if the app
+// is its leader or its last member, as the group cannot be scaled or managed without them.
+func (b *Broker) removeFromAppGroup(ctx context.Context, appId string) error {
+	group, err := b.policydb.GetAppGroupByAppId(ctx, appId)
+	if err != nil || group == nil {
+		return err
+	}
+
+	members := slices.DeleteFunc(slices.Clone(group.Members), func(member models.AppGroupMember) bool { return member.AppId == appId })
+	if group.LeaderAppId() == appId || len(members) == 0 {
+		return b.policydb.DeleteAppGroup(ctx, group.GroupId)
+	}
+	group.Members = members
+	return b.policydb.SaveAppGroup(ctx, group)
+}
+
 func createServiceBinding(
 	ctx context.Context,
 	bindingDB db.BindingDB, bindingID, instanceID string,
@@ -1132,3 +1251,24 @@ func createServiceBinding(
 
 	return nil
 }
+
+// originatingUser returns the id of the user on whose behalf the platform sends a broker request, as found in the
+// X-Broker-API-Originating-Identity header, e.g. "cloudfoundry eyJ1c2VyX2lkIjoiNjgzZWE3NDgifQ==".
+func originatingUser(ctx context.Context) string {
+	originatingIdentity, _ := ctx.Value(middlewares.OriginatingIdentityKey).(string)
+	_, value, found := strings.Cut(originatingIdentity, " ")
+	if !found {
+		return ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return ""
+	}
+	identity := struct {
+		UserId string `json:"user_id"`
+	}{}
+	if err := json.Unmarshal(decoded, &identity); err != nil {
+		return ""
+	}
+	return identity.UserId
+}
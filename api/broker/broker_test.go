@@ -53,7 +53,103 @@ var _ = Describe("Broker", func() {
 		})
 	})
 
-	// 🚧 To-do: Untested functions: “Provision” and “Deprovision”
+	// 🚧 To-do: Untested function: “Deprovision”
+
+	const defaultPolicyWithSourceApp = `{
+		"default_policy": {
+			"instance_min_count": 1,
+			"instance_max_count": 5,
+			"scaling_rules": [{
+				"metric_type": "queue_length",
+				"threshold": 100,
+				"operator": ">",
+				"adjustment": "+1",
+				"source_app_id": "source-app-guid"
+			}]
+		}
+	}`
+	sourceAppInSpace := func(spaceGuid cf.SpaceId) *cf.App {
+		return &cf.App{
+			Guid:          "source-app-guid",
+			Relationships: cf.Relationships{Space: &cf.Space{Data: cf.SpaceData{Guid: spaceGuid}}},
+		}
+	}
+
+	Describe("Provision", func() {
+		var provisionDetails domain.ProvisionDetails
+		BeforeEach(func() {
+			provisionDetails = domain.ProvisionDetails{
+				ServiceID:        testServiceID,
+				PlanID:           testPlanID,
+				OrganizationGUID: "some-org-guid",
+				SpaceGUID:        "some-space-guid",
+				RawParameters:    json.RawMessage(defaultPolicyWithSourceApp),
+			}
+		})
+		JustBeforeEach(func() {
+			_, err = aBroker.Provision(context.TODO(), testInstanceId, provisionDetails, false)
+		})
+		Context("when the default policy uses the metrics of a source app in the space", func() {
+			BeforeEach(func() {
+				fakeCfCtxClient.GetAppReturns(sourceAppInSpace("some-space-guid"), nil)
+			})
+			It("creates the service instance with the default policy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, appGuid := fakeCfCtxClient.GetAppArgsForCall(0)
+				Expect(appGuid).To(Equal(cf.Guid("source-app-guid")))
+				Expect(fakeBindingDB.CreateServiceInstanceCallCount()).To(Equal(1))
+			})
+		})
+		Context("when the default policy uses the metrics of a source app in another space", func() {
+			BeforeEach(func() {
+				fakeCfCtxClient.GetAppReturns(sourceAppInSpace("some-other-space-guid"), nil)
+			})
+			It("fails without creating the service instance", func() {
+				Expect(err).To(MatchError(ContainSubstring("source app source-app-guid not found in space some-space-guid")))
+				Expect(fakeBindingDB.CreateServiceInstanceCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		var updateDetails domain.UpdateDetails
+		BeforeEach(func() {
+			updateDetails = domain.UpdateDetails{
+				ServiceID:      testServiceID,
+				PreviousValues: domain.PreviousValues{PlanID: testPlanID},
+				RawParameters:  json.RawMessage(defaultPolicyWithSourceApp),
+			}
+			fakeBindingDB.GetServiceInstanceReturns(&models.ServiceInstance{
+				ServiceInstanceId: testInstanceId,
+				OrgId:             "some-org-guid",
+				SpaceId:           "some-space-guid",
+			}, nil)
+			fakeBindingDB.GetAppIdsByInstanceIdReturns([]string{"an-app-id"}, nil)
+		})
+		JustBeforeEach(func() {
+			_, err = aBroker.Update(context.TODO(), testInstanceId, updateDetails, false)
+		})
+		Context("when the default policy uses the metrics of a source app in the space", func() {
+			BeforeEach(func() {
+				fakeCfCtxClient.GetAppReturns(sourceAppInSpace("some-space-guid"), nil)
+			})
+			It("sets the default policy on the bound apps", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakePolicyDB.SetOrUpdateDefaultAppPolicyCallCount()).To(Equal(1))
+				Expect(fakeBindingDB.UpdateServiceInstanceCallCount()).To(Equal(1))
+			})
+		})
+		Context("when the default policy uses the metrics of a source app in another space", func() {
+			BeforeEach(func() {
+				fakeCfCtxClient.GetAppReturns(sourceAppInSpace("some-other-space-guid"), nil)
+			})
+			It("fails without propagating the default policy to the bound apps", func() {
+				Expect(err).To(MatchError(ContainSubstring("source app source-app-guid not found in space some-space-guid")))
+				Expect(fakePolicyDB.SetOrUpdateDefaultAppPolicyCallCount()).To(BeZero())
+				Expect(fakeBindingDB.UpdateServiceInstanceCallCount()).To(BeZero())
+			})
+		})
+	})
 
 	Describe("GetInstance", func() {
 		var instance domain.GetInstanceDetailsSpec
@@ -359,8 +455,9 @@ var _ = Describe("Broker", func() {
 				// Check if there is a policy that is associated with the generated binding and that
 				// it corresponds to the json in `bindingParams`
 				Expect(fakePolicyDB.SaveAppPolicyCallCount()).To(Equal(1))
-				_, appId, policy, policyGuid := fakePolicyDB.SaveAppPolicyArgsForCall(0)
+				_, appId, policy, policyGuid, change := fakePolicyDB.SaveAppPolicyArgsForCall(0)
 				Expect(appId).To(Equal("AppGUID_for_bindings"))
+				Expect(change.Source).To(Equal(models.PolicySourceBrokerBind))
 				Expect(policy).NotTo(BeNil())
 				Expect(policyGuid).NotTo(BeEmpty())
 
@@ -368,6 +465,36 @@ var _ = Describe("Broker", func() {
 				Expect(err).To(BeNil())
 				Expect(policyJson).To(MatchJSON(bindingParams))
 			})
+			It("Returns the warnings about the provided policy in the credentials", func() {
+				details = domain.BindDetails{
+					PlanID:    "some_plan-id",
+					ServiceID: "some_service-id",
+					BindResource: &domain.BindResource{
+						AppGuid: "AppGUID_for_bindings",
+					},
+					RawParameters: []byte(`
+						{
+						  "instance_min_count": 2,
+						  "instance_max_count": 2,
+						  "scaling_rules": [
+							{
+							  "metric_type": "memoryused",
+							  "threshold": 30,
+							  "operator": "<",
+							  "adjustment": "-1"
+							}
+						  ]
+						}`),
+				}
+
+				binding, err := aBroker.Bind(ctx, instanceID, bindingID, details, false)
+
+				Expect(err).To(BeNil())
+				Expect(binding.Credentials).To(BeAssignableToTypeOf(models.Credentials{}))
+				Expect(binding.Credentials.(models.Credentials).PolicyWarnings).To(ConsistOf(
+					"scaling_rules[0].adjustment can never change the number of instances as instance_min_count and instance_max_count are both 2",
+				))
+			})
 			When("Not providing an Autoscaler Policy as RawParameters", func() {
 				It("Does not require neither that policy nor the default-policy", func() {
 					// Setup
@@ -545,6 +672,65 @@ var _ = Describe("Broker", func() {
 					Expect(err).To(MatchError(ContainSubstring("app GUID provided in both, binding resource and binding configuration")))
 				})
 			})
+			When("Created with a policy using the metrics of a source app", func() {
+				BeforeEach(func() {
+					details = domain.BindDetails{
+						PlanID:    "some_plan-id",
+						ServiceID: "some_service-id",
+						BindResource: &domain.BindResource{
+							AppGuid:   "AppGUID_for_bindings",
+							SpaceGuid: "some-space-guid",
+						},
+						RawParameters: []byte(`
+							{
+							  "instance_min_count": 1,
+							  "instance_max_count": 5,
+							  "scaling_rules": [
+								{
+								  "metric_type": "queue_length",
+								  "threshold": 100,
+								  "operator": ">",
+								  "adjustment": "+1",
+								  "source_app_id": "source-app-guid"
+								}
+							  ]
+							}`),
+					}
+				})
+				It("succeeds when the source app is in the space of the service instance", func() {
+					fakeCfCtxClient.GetAppReturns(&cf.App{
+						Guid:          "source-app-guid",
+						Relationships: cf.Relationships{Space: &cf.Space{Data: cf.SpaceData{Guid: "some-space-guid"}}},
+					}, nil)
+
+					_, err := aBroker.Bind(ctx, instanceID, bindingID, details, false)
+
+					Expect(err).To(BeNil())
+					Expect(fakeCfCtxClient.GetAppCallCount()).To(Equal(1))
+					_, appGuid := fakeCfCtxClient.GetAppArgsForCall(0)
+					Expect(appGuid).To(Equal(cf.Guid("source-app-guid")))
+					Expect(fakePolicyDB.SaveAppPolicyCallCount()).To(Equal(1))
+				})
+				It("fails when the source app is in another space", func() {
+					fakeCfCtxClient.GetAppReturns(&cf.App{
+						Guid:          "source-app-guid",
+						Relationships: cf.Relationships{Space: &cf.Space{Data: cf.SpaceData{Guid: "some-other-space-guid"}}},
+					}, nil)
+
+					_, err := aBroker.Bind(ctx, instanceID, bindingID, details, false)
+
+					Expect(err).To(MatchError(ContainSubstring("source app source-app-guid not found in space some-space-guid")))
+					Expect(fakeBindingDB.CreateServiceBindingCallCount()).To(BeZero())
+				})
+				It("fails when the source app does not exist", func() {
+					fakeCfCtxClient.GetAppReturns(nil, cf.CfResourceNotFound)
+
+					_, err := aBroker.Bind(ctx, instanceID, bindingID, details, false)
+
+					Expect(err).To(MatchError(ContainSubstring("source app source-app-guid not found")))
+					Expect(fakeBindingDB.CreateServiceBindingCallCount()).To(BeZero())
+				})
+			})
 			When("Created for an App outside the selected space", func() {
 				var bindingParams []byte
 				BeforeEach(func() {
@@ -781,7 +967,7 @@ var _ = Describe("Broker", func() {
 
 					By("verifying that the policy was saved with the correct app GUID and attributes.")
 					Expect(fakePolicyDB.SaveAppPolicyCallCount()).To(Equal(1))
-					_, savedAppId, savedPolicy, savedPolicyGuid := fakePolicyDB.SaveAppPolicyArgsForCall(0)
+					_, savedAppId, savedPolicy, savedPolicyGuid, _ := fakePolicyDB.SaveAppPolicyArgsForCall(0)
 					Expect(savedAppId).To(Equal("12345678-abcd-1234-5678-123456789abc"))
 					Expect(savedPolicy).NotTo(BeNil())
 					Expect(savedPolicyGuid).NotTo(BeEmpty())
@@ -839,7 +1025,7 @@ var _ = Describe("Broker", func() {
 
 					// Verify policy was saved with the correct app GUID
 					Expect(fakePolicyDB.SaveAppPolicyCallCount()).To(Equal(1))
-					_, savedAppId, savedPolicy, savedPolicyGuid := fakePolicyDB.SaveAppPolicyArgsForCall(0)
+					_, savedAppId, savedPolicy, savedPolicyGuid, _ := fakePolicyDB.SaveAppPolicyArgsForCall(0)
 					Expect(savedAppId).To(Equal("12345678-abcd-1234-5678-123456789abc"))
 					Expect(savedPolicy).NotTo(BeNil())
 					Expect(savedPolicyGuid).NotTo(BeEmpty())
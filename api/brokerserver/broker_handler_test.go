@@ -445,8 +445,9 @@ var _ = Describe("BrokerHandler", func() {
 					Expect(ctx).To(Not(BeNil()))
 					Expect(lookedUpInstance).To(Equal(testInstanceId))
 					Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(Equal(1))
-					ctx, appsUpdated, oldPolicyGuid, policy, policySetGuid := policydb.SetOrUpdateDefaultAppPolicyArgsForCall(0)
+					ctx, appsUpdated, oldPolicyGuid, policy, policySetGuid, change := policydb.SetOrUpdateDefaultAppPolicyArgsForCall(0)
 					Expect(ctx).To(Not(BeNil()))
+					Expect(change.Source).To(Equal(models.PolicySourceDefaultPolicy))
 					Expect(oldPolicyGuid).To(BeEmpty())
 					Expect(policySetGuid).To(Equal(serviceInstance.DefaultPolicyGuid))
 					Expect(policy).To(MatchJSON(serviceInstance.DefaultPolicy))
@@ -493,7 +494,7 @@ var _ = Describe("BrokerHandler", func() {
 
 				By("setting the default policy on the already bound apps")
 				Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(Equal(1))
-				ctx, appToUpdate, oldPolicyGuid, newPolicy, newPolicyGuid := policydb.SetOrUpdateDefaultAppPolicyArgsForCall(0)
+				ctx, appToUpdate, oldPolicyGuid, newPolicy, newPolicyGuid, _ := policydb.SetOrUpdateDefaultAppPolicyArgsForCall(0)
 				newPolicyStr, err := json.Marshal(newPolicy)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ctx).To(Not(BeNil()))
@@ -678,7 +679,7 @@ var _ = Describe("BrokerHandler", func() {
 
 				By("setting the default policy on the already bound apps")
 				Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(Equal(1))
-				ctx, appToUpdate, oldPolicyGuid, newPolicy, newPolicyGuid := policydb.SetOrUpdateDefaultAppPolicyArgsForCall(0)
+				ctx, appToUpdate, oldPolicyGuid, newPolicy, newPolicyGuid, _ := policydb.SetOrUpdateDefaultAppPolicyArgsForCall(0)
 				Expect(ctx).To(Not(BeNil()))
 				Expect(appToUpdate).To(Equal([]string{"app-id-1", "app-id-2"}))
 				Expect(oldPolicyGuid).To(Equal("a-default-policy-guid"))
@@ -741,8 +742,8 @@ var _ = Describe("BrokerHandler", func() {
 				Expect(ctx).ToNot(BeNil())
 				Expect(bindingid).To(Equal(testBindingId))
 				Expect(bindingdb.DeleteServiceInstanceCallCount()).To(Equal(1))
-				Expect(policydb.DeletePolicyCallCount(), 1)
-				ctx, appid := policydb.DeletePolicyArgsForCall(0)
+				Expect(policydb.DeletePolicyAndRevisionsCallCount(), 1)
+				ctx, appid := policydb.DeletePolicyAndRevisionsArgsForCall(0)
 				Expect(ctx).To(Not(BeNil()))
 				Expect(appid, testAppId)
 			})
@@ -764,8 +765,8 @@ var _ = Describe("BrokerHandler", func() {
 				Expect(ctx).ToNot(BeNil())
 				Expect(bindingid).To(Equal(testBindingId))
 				Expect(bindingdb.DeleteServiceInstanceCallCount()).To(Equal(1))
-				Expect(policydb.DeletePolicyCallCount(), 1)
-				ctx, appid := policydb.DeletePolicyArgsForCall(0)
+				Expect(policydb.DeletePolicyAndRevisionsCallCount(), 1)
+				ctx, appid := policydb.DeletePolicyAndRevisionsArgsForCall(0)
 				Expect(ctx).To(Not(BeNil()))
 				Expect(appid, testAppId)
 			})
@@ -1326,7 +1327,7 @@ var _ = Describe("BrokerHandler", func() {
 				It("should return 201 response code", func() {
 					Expect(resp.Code).To(Equal(http.StatusCreated))
 					Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
-					ctx, appID, policy, _ := policydb.SaveAppPolicyArgsForCall(0)
+					ctx, appID, policy, _, _ := policydb.SaveAppPolicyArgsForCall(0)
 					Expect(ctx).To(Not(BeNil()))
 					Expect(appID).To(Equal(testAppId))
 					Expect(policy).NotTo(MatchJSON(testBindingPolicy))
@@ -1594,7 +1595,7 @@ var _ = Describe("BrokerHandler", func() {
 				It("succeeds with 201 and saves the binding's policy", func() {
 					Expect(resp.Code).To(Equal(http.StatusCreated))
 					Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
-					ctx, appID, policy, _ := policydb.SaveAppPolicyArgsForCall(0)
+					ctx, appID, policy, _, _ := policydb.SaveAppPolicyArgsForCall(0)
 					Expect(ctx).To(Not(BeNil()))
 					Expect(appID).To(Equal(testAppId))
 					Expect(policy).NotTo(MatchJSON(testDefaultPolicy))
@@ -1621,7 +1622,7 @@ var _ = Describe("BrokerHandler", func() {
 				It("succeeds with 201 and saves the default policy", func() {
 					Expect(resp.Code).To(Equal(http.StatusCreated))
 					Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
-					ctx, appID, policy, _ := policydb.SaveAppPolicyArgsForCall(0)
+					ctx, appID, policy, _, _ := policydb.SaveAppPolicyArgsForCall(0)
 					Expect(ctx).To(Not(BeNil()))
 					Expect(appID).To(Equal(testAppId))
 					Expect(policy).To(MatchJSON(testDefaultPolicy))
@@ -1735,6 +1736,67 @@ var _ = Describe("BrokerHandler", func() {
 				Expect(schedulerServer.ReceivedRequests()).To(HaveLen(1))
 			})
 		})
+		Context("When the app leads an app group", func() {
+			BeforeEach(func() {
+				policydb.GetAppGroupByAppIdReturns(&models.AppGroup{
+					GroupId:       "a-group-id",
+					LeadingMetric: models.LeadingMetric{AppId: testAppId, MetricType: "cpu"},
+					Members:       []models.AppGroupMember{{AppId: "a-member-id"}},
+				}, nil)
+				verifyScheduleIsDeletedInScheduler(testAppId)
+			})
+			It("deletes the app group", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, appId := policydb.GetAppGroupByAppIdArgsForCall(0)
+				Expect(appId).To(Equal(testAppId))
+				Expect(policydb.DeleteAppGroupCallCount()).To(Equal(1))
+				_, groupId := policydb.DeleteAppGroupArgsForCall(0)
+				Expect(groupId).To(Equal("a-group-id"))
+				Expect(policydb.SaveAppGroupCallCount()).To(BeZero())
+			})
+		})
+		Context("When the app is a member of an app group", func() {
+			BeforeEach(func() {
+				policydb.GetAppGroupByAppIdReturns(&models.AppGroup{
+					GroupId:       "a-group-id",
+					LeadingMetric: models.LeadingMetric{AppId: "a-leader-id", MetricType: "cpu"},
+					Members:       []models.AppGroupMember{{AppId: testAppId}, {AppId: "another-member-id"}},
+				}, nil)
+				verifyScheduleIsDeletedInScheduler(testAppId)
+			})
+			It("removes only the app from the app group", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.DeleteAppGroupCallCount()).To(BeZero())
+				Expect(policydb.SaveAppGroupCallCount()).To(Equal(1))
+				_, group := policydb.SaveAppGroupArgsForCall(0)
+				Expect(group.GroupId).To(Equal("a-group-id"))
+				Expect(group.Members).To(Equal([]models.AppGroupMember{{AppId: "another-member-id"}}))
+			})
+		})
+		Context("When the app is the last member of an app group", func() {
+			BeforeEach(func() {
+				policydb.GetAppGroupByAppIdReturns(&models.AppGroup{
+					GroupId:       "a-group-id",
+					LeadingMetric: models.LeadingMetric{AppId: "a-leader-id", MetricType: "cpu"},
+					Members:       []models.AppGroupMember{{AppId: testAppId}},
+				}, nil)
+				verifyScheduleIsDeletedInScheduler(testAppId)
+			})
+			It("deletes the app group", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.DeleteAppGroupCallCount()).To(Equal(1))
+				Expect(policydb.SaveAppGroupCallCount()).To(BeZero())
+			})
+		})
+		Context("When removing the app from its app group fails", func() {
+			BeforeEach(func() {
+				policydb.GetAppGroupByAppIdReturns(nil, errors.New("some error"))
+			})
+			It("fails with 500 and keeps the binding", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(bindingdb.DeleteServiceBindingCallCount()).To(BeZero())
+			})
+		})
 		Context("When there is no app with the bindingId", func() {
 			BeforeEach(func() {
 				bindingdb.GetAppIdByBindingIdReturns("", sql.ErrNoRows)
@@ -24,6 +24,12 @@ const (
 	DefaultDiskUtilUpperThreshold = 100
 	DefaultDiskLowerThreshold     = 1
 	DefaultDiskUpperThreshold     = 2 * 1024
+
+	// DefaultMetricCollectionInterval matches the default interval in which the eventgenerator aggregates metrics.
+	DefaultMetricCollectionInterval = 40 * time.Second
+
+	DefaultScalingEventsPollInterval      = 1 * time.Second
+	DefaultScalingEventsHeartbeatInterval = 15 * time.Second
 )
 
 var (
@@ -50,11 +56,21 @@ type SchedulerConfig struct {
 type ScalingEngineConfig struct {
 	ScalingEngineUrl string          `yaml:"scaling_engine_url" json:"scaling_engine_url"`
 	TLSClientCerts   models.TLSCerts `yaml:"tls" json:"tls"`
+
+	// EventsPollInterval is how often the scaling events of an app are read from the scaling engine while a
+	// client is connected to its event stream. EventsHeartbeatInterval is how long the stream may be silent before a
+	// heartbeat is sent. The defaults are used if they are zero.
+	EventsPollInterval      time.Duration `yaml:"events_poll_interval" json:"events_poll_interval"`
+	EventsHeartbeatInterval time.Duration `yaml:"events_heartbeat_interval" json:"events_heartbeat_interval"`
 }
 
 type EventGeneratorConfig struct {
-	EventGeneratorUrl string          `yaml:"event_generator_url" json:"event_generator_url"`
-	TLSClientCerts    models.TLSCerts `yaml:"tls" json:"tls"`
+	EventGeneratorUrl string `yaml:"event_generator_url" json:"event_generator_url"`
+	// InstanceUrls are the URLs of the single eventgenerator instances, ordered by their index in the pool. The
+	// evaluation state of an app is only known to the instance which evaluates it, so it is read from that instance.
+	// Without them it is read from EventGeneratorUrl, which only works for a pool of one instance.
+	InstanceUrls   []string        `yaml:"instance_urls" json:"instance_urls"`
+	TLSClientCerts models.TLSCerts `yaml:"tls" json:"tls"`
 }
 type MetricsForwarderConfig struct {
 	MetricsForwarderUrl     string `yaml:"metrics_forwarder_url" json:"metrics_forwarder_url"`
@@ -111,6 +127,7 @@ type Config struct {
 	Health                   helpers.HealthConfig
 	RateLimit                models.RateLimitConfig
 	ScalingRules             ScalingRulesConfig
+	MetricCollectionInterval time.Duration
 
 	CustomMetricsAuthConfig *CustomMetricsBasicAuthCfg
 }
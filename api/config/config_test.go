@@ -231,6 +231,9 @@ var _ = Describe("Config", func() {
 					Expect(conf.ScalingRules.DiskUtil.UpperThreshold).To(Equal(33))
 					Expect(conf.ScalingRules.Disk.LowerThreshold).To(Equal(22))
 					Expect(conf.ScalingRules.Disk.UpperThreshold).To(Equal(33))
+					Expect(conf.MetricCollectionInterval).To(Equal(30 * time.Second))
+					Expect(conf.ScalingEngine.EventsPollInterval).To(Equal(2 * time.Second))
+					Expect(conf.ScalingEngine.EventsHeartbeatInterval).To(Equal(DefaultScalingEventsHeartbeatInterval))
 				})
 			})
 			Context("with partial config", func() {
@@ -269,6 +272,9 @@ var _ = Describe("Config", func() {
 					Expect(conf.ScalingRules.DiskUtil.UpperThreshold).To(Equal(100))
 					Expect(conf.ScalingRules.Disk.LowerThreshold).To(Equal(1))
 					Expect(conf.ScalingRules.Disk.UpperThreshold).To(Equal(2 * 1024))
+					Expect(conf.MetricCollectionInterval).To(Equal(40 * time.Second))
+					Expect(conf.ScalingEngine.EventsPollInterval).To(Equal(DefaultScalingEventsPollInterval))
+					Expect(conf.ScalingEngine.EventsHeartbeatInterval).To(Equal(DefaultScalingEventsHeartbeatInterval))
 				})
 			})
 			Context("when max_amount of rate_limit is not an integer", func() {
@@ -39,6 +39,7 @@ type rawConfig struct {
 	Health                   helpers.HealthConfig         `yaml:"health" json:"health"`
 	RateLimit                models.RateLimitConfig       `yaml:"rate_limit" json:"rate_limit,omitempty"`
 	ScalingRules             ScalingRulesConfig           `yaml:"scaling_rules" json:"scaling_rules"`
+	MetricCollectionInterval time.Duration                `yaml:"metric_collection_interval" json:"metric_collection_interval"`
 
 	CredHelperImpl                     string                        `yaml:"cred_helper_impl" json:"cred_helper_impl"`
 	StoredProcedureConfig              *models.StoredProcedureConfig `yaml:"stored_procedure_binding_credential_config" json:"stored_procedure_binding_credential_config"`
@@ -76,6 +77,7 @@ func toConfig(rawConfig rawConfig) (Config, error) {
 		Health:                   rawConfig.Health,
 		RateLimit:                rawConfig.RateLimit,
 		ScalingRules:             rawConfig.ScalingRules,
+		MetricCollectionInterval: rawConfig.MetricCollectionInterval,
 	}
 
 	cmBasicAuthCfg := parseCMBasicAuthCfg(rawConfig)
@@ -161,9 +163,15 @@ func (c *rawConfig) validate() error {
 	if c.ScalingEngine.ScalingEngineUrl == "" {
 		return fmt.Errorf("Configuration error: scaling_engine.scaling_engine_url is empty")
 	}
+	if c.ScalingEngine.EventsPollInterval < 0 || c.ScalingEngine.EventsHeartbeatInterval < 0 {
+		return fmt.Errorf("Configuration error: scaling_engine.events_poll_interval and scaling_engine.events_heartbeat_interval must not be negative")
+	}
 	if c.EventGenerator.EventGeneratorUrl == "" {
 		return fmt.Errorf("Configuration error: event_generator.event_generator_url is empty")
 	}
+	if slices.Contains(c.EventGenerator.InstanceUrls, "") {
+		return fmt.Errorf("Configuration error: event_generator.instance_urls contains an empty url")
+	}
 	if c.MetricsForwarder.MetricsForwarderUrl == "" {
 		return fmt.Errorf("Configuration error: metrics_forwarder.metrics_forwarder_url is empty")
 	}
@@ -373,6 +381,10 @@ func defaultConfig() rawConfig {
 			},
 		},
 		Db: make(map[string]db.DatabaseConfig),
+		ScalingEngine: ScalingEngineConfig{
+			EventsPollInterval:      DefaultScalingEventsPollInterval,
+			EventsHeartbeatInterval: DefaultScalingEventsHeartbeatInterval,
+		},
 		RateLimit: models.RateLimitConfig{
 			MaxAmount:     DefaultMaxAmount,
 			ValidDuration: DefaultValidDuration,
@@ -395,5 +407,6 @@ func defaultConfig() rawConfig {
 				UpperThreshold: DefaultDiskUpperThreshold,
 			},
 		},
+		MetricCollectionInterval: DefaultMetricCollectionInterval,
 	}
 }
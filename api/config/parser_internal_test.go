@@ -188,6 +188,14 @@ var _ = Describe("rawConfig validation", func() {
 			Expect(err).To(MatchError(MatchRegexp("Configuration error: event_generator.event_generator_url is empty")))
 		})
 	})
+	Context("when an eventgenerator instance url is empty", func() {
+		BeforeEach(func() {
+			conf.EventGenerator.InstanceUrls = []string{"https://eventgenerator-0.example.com", ""}
+		})
+		It("should err", func() {
+			Expect(err).To(MatchError(MatchRegexp("Configuration error: event_generator.instance_urls contains an empty url")))
+		})
+	})
 	Context("when scalingengine url is not set", func() {
 		BeforeEach(func() {
 			conf.ScalingEngine.ScalingEngineUrl = ""
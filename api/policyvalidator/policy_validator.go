@@ -31,9 +31,10 @@ type (
 	}
 
 	PolicyValidator struct {
-		scalingRules       ScalingRulesConfig
-		policySchemaPath   string
-		policySchemaLoader gojsonschema.JSONLoader
+		scalingRules             ScalingRulesConfig
+		metricCollectionInterval time.Duration
+		policySchemaPath         string
+		policySchemaLoader       gojsonschema.JSONLoader
 	}
 
 	PolicyValidationError struct {
@@ -87,9 +88,10 @@ func newPolicyValidationError(context *gojsonschema.JsonContext, formatString st
 	return &err
 }
 
-func NewPolicyValidator(policySchemaPath string, lowerCPUThreshold int, upperCPUThreshold int, lowerCPUUtilThreshold int, upperCPUUtilThreshold int, lowerDiskUtilThreshold int, upperDiskUtilThreshold int, lowerDiskThreshold int, upperDiskThreshold int) *PolicyValidator {
+func NewPolicyValidator(policySchemaPath string, lowerCPUThreshold int, upperCPUThreshold int, lowerCPUUtilThreshold int, upperCPUUtilThreshold int, lowerDiskUtilThreshold int, upperDiskUtilThreshold int, lowerDiskThreshold int, upperDiskThreshold int, metricCollectionInterval time.Duration) *PolicyValidator {
 	policyValidator := &PolicyValidator{
-		policySchemaPath: policySchemaPath,
+		policySchemaPath:         policySchemaPath,
+		metricCollectionInterval: metricCollectionInterval,
 		scalingRules: ScalingRulesConfig{
 			CPU: LowerUpperThresholdConfig{
 				LowerThreshold: lowerCPUThreshold,
@@ -170,12 +172,20 @@ func (pv *PolicyValidator) validateAttributes(policy *models.PolicyDefinition, r
 
 	scalingRulesContext := gojsonschema.NewJsonContext("scaling_rules", rootContext)
 	pv.validateScalingRuleThreshold(policy, scalingRulesContext, result)
+	pv.validateEmergencyThresholds(policy, scalingRulesContext, result)
+	pv.validateScalingRuleSources(policy, scalingRulesContext, result)
 
 	if policy.Schedules != nil {
 		schedulesContext := gojsonschema.NewJsonContext("schedules", rootContext)
 		pv.validateRecurringSchedules(policy, schedulesContext, result)
 		pv.validateSpecificDateSchedules(policy, schedulesContext, result)
 	}
+
+	if policy.BlackoutPeriods != nil {
+		blackoutPeriodsContext := gojsonschema.NewJsonContext("blackout_periods", rootContext)
+		pv.validateRecurringBlackouts(policy, blackoutPeriodsContext, result)
+		pv.validateSpecificDateBlackouts(policy, blackoutPeriodsContext, result)
+	}
 }
 
 func (pv *PolicyValidator) validateScalingRuleThreshold(policy *models.PolicyDefinition, scalingRulesContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
@@ -253,6 +263,63 @@ func (pv *PolicyValidator) validateScalingRuleThreshold(policy *models.PolicyDef
 	}
 }
 
+// validateEmergencyThresholds checks that the emergency threshold of a rule is breached only when
+// its regular threshold is breached as well and that both adjustments scale in the same direction.
+func (pv *PolicyValidator) validateEmergencyThresholds(policy *models.PolicyDefinition, scalingRulesContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	for srIndex, scalingRule := range policy.ScalingRules {
+		if scalingRule.EmergencyThreshold == nil {
+			continue
+		}
+		errDetails := gojsonschema.ErrorDetails{
+			"scalingRuleIndex":    srIndex,
+			"threshold":           scalingRule.Threshold,
+			"emergency_threshold": *scalingRule.EmergencyThreshold,
+		}
+
+		beyondThreshold := *scalingRule.EmergencyThreshold > scalingRule.Threshold
+		formatString := "scaling_rules[{{.scalingRuleIndex}}].emergency_threshold {{.emergency_threshold}} should be greater than threshold {{.threshold}}"
+		if scalingRule.Operator == "<" || scalingRule.Operator == "<=" {
+			beyondThreshold = *scalingRule.EmergencyThreshold < scalingRule.Threshold
+			formatString = "scaling_rules[{{.scalingRuleIndex}}].emergency_threshold {{.emergency_threshold}} should be less than threshold {{.threshold}}"
+		}
+		if !beyondThreshold {
+			currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d.emergency_threshold", srIndex), scalingRulesContext)
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+
+		if models.DirectionOf(scalingRule.EmergencyAdjustment) != models.DirectionOf(scalingRule.Adjustment) {
+			currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d.emergency_adjustment", srIndex), scalingRulesContext)
+			formatString := "scaling_rules[{{.scalingRuleIndex}}].emergency_adjustment should scale in the same direction as adjustment"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	}
+}
+
+// validateScalingRuleSources checks that all rules of a metric type take the metric from the same app, as an app is
+// monitored only once per metric type.
+func (pv *PolicyValidator) validateScalingRuleSources(policy *models.PolicyDefinition, scalingRulesContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	sourceAppIds := map[string]string{}
+	for srIndex, scalingRule := range policy.ScalingRules {
+		sourceAppId, found := sourceAppIds[scalingRule.MetricType]
+		if !found {
+			sourceAppIds[scalingRule.MetricType] = scalingRule.SourceAppId
+			continue
+		}
+		if sourceAppId != scalingRule.SourceAppId {
+			currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d.source_app_id", srIndex), scalingRulesContext)
+			errDetails := gojsonschema.ErrorDetails{
+				"scalingRuleIndex": srIndex,
+				"metric_type":      scalingRule.MetricType,
+			}
+			formatString := "scaling_rules[{{.scalingRuleIndex}}].source_app_id should be the same as for the other rules of metric_type {{.metric_type}}"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	}
+}
+
 func (pv *PolicyValidator) validateRecurringSchedules(policy *models.PolicyDefinition, schedulesContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
 	recurringScheduleContext := gojsonschema.NewJsonContext("recurring_schedule", schedulesContext)
 	for scheduleIndex, recSched := range policy.Schedules.RecurringSchedules {
@@ -485,6 +552,104 @@ func (pv *PolicyValidator) validateOverlappingInSpecificDateSchedules(policy *mo
 	}
 }
 
+func (pv *PolicyValidator) validateRecurringBlackouts(policy *models.PolicyDefinition, blackoutPeriodsContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	recurringBlackoutContext := gojsonschema.NewJsonContext("recurring_blackout", blackoutPeriodsContext)
+	for blackoutIndex, recBlackout := range policy.BlackoutPeriods.RecurringBlackouts {
+		//start_time should be before end_time
+		if compareTimesGTEQ(recBlackout.StartTime, recBlackout.EndTime) {
+			currentRecBlackoutContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d", blackoutIndex), recurringBlackoutContext)
+			errDetails := gojsonschema.ErrorDetails{
+				"blackoutIndex": blackoutIndex,
+			}
+			formatString := "recurring_blackout[{{.blackoutIndex}}].start_time is same or after recurring_blackout[{{.blackoutIndex}}].end_time"
+			err := newPolicyValidationError(currentRecBlackoutContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+
+		if recBlackout.StartDate != "" && recBlackout.EndDate != "" && !compareDatesGTEQ(recBlackout.EndDate, recBlackout.StartDate) {
+			currentRecBlackoutContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d", blackoutIndex), recurringBlackoutContext)
+			errDetails := gojsonschema.ErrorDetails{
+				"blackoutIndex": blackoutIndex,
+			}
+			formatString := "recurring_blackout[{{.blackoutIndex}}].start_date is after recurring_blackout[{{.blackoutIndex}}].end_date"
+			err := newPolicyValidationError(currentRecBlackoutContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	}
+
+	pv.validateOverlappingInRecurringBlackouts(policy, recurringBlackoutContext, result)
+}
+
+func (pv *PolicyValidator) validateSpecificDateBlackouts(policy *models.PolicyDefinition, blackoutPeriodsContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	specificDateBlackoutContext := gojsonschema.NewJsonContext("specific_date", blackoutPeriodsContext)
+	for blackoutIndex, specBlackout := range policy.BlackoutPeriods.SpecificDateBlackouts {
+		dateTime := newDateTimeRange(specBlackout.StartDateTime, specBlackout.EndDateTime, policy.BlackoutPeriods.Timezone)
+		if dateTime.endDateTime.Sub(dateTime.startDateTime) <= 0 {
+			currentSpecBlackoutContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d", blackoutIndex), specificDateBlackoutContext)
+			errDetails := gojsonschema.ErrorDetails{
+				"blackoutIndex": blackoutIndex,
+			}
+			formatString := "specific_date[{{.blackoutIndex}}].start_date_time is same or after specific_date[{{.blackoutIndex}}].end_date_time"
+			err := newPolicyValidationError(currentSpecBlackoutContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	}
+
+	pv.validateOverlappingInSpecificDateBlackouts(policy, specificDateBlackoutContext, result)
+}
+
+func (pv *PolicyValidator) validateOverlappingInRecurringBlackouts(policy *models.PolicyDefinition, recurringBlackoutContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	recBlackouts := policy.BlackoutPeriods.RecurringBlackouts
+	for blackoutIndexB := 0; blackoutIndexB < len(recBlackouts)-1; blackoutIndexB++ {
+		for blackoutIndexA := blackoutIndexB + 1; blackoutIndexA < len(recBlackouts); blackoutIndexA++ {
+			a, b := recBlackouts[blackoutIndexA], recBlackouts[blackoutIndexB]
+			sameDays := (len(a.DaysOfWeek) > 0 && len(b.DaysOfWeek) > 0 && hasIntersection(a.DaysOfWeek, b.DaysOfWeek)) ||
+				(len(a.DaysOfMonth) > 0 && len(b.DaysOfMonth) > 0 && hasIntersection(a.DaysOfMonth, b.DaysOfMonth))
+			if sameDays &&
+				compareTimesGTEQ(b.EndTime, a.StartTime) && compareTimesGTEQ(a.EndTime, b.StartTime) &&
+				compareDatesGTEQ(b.EndDate, a.StartDate) && compareDatesGTEQ(a.EndDate, b.StartDate) {
+				context := gojsonschema.NewJsonContext(fmt.Sprintf("%d", blackoutIndexB), recurringBlackoutContext)
+				errDetails := gojsonschema.ErrorDetails{
+					"blackoutIndexA": blackoutIndexA,
+					"blackoutIndexB": blackoutIndexB,
+				}
+
+				formatString := "recurring_blackout[{{.blackoutIndexB}}] and recurring_blackout[{{.blackoutIndexA}}] are overlapping"
+				err := newPolicyValidationError(context, formatString, errDetails)
+				result.AddError(err, errDetails)
+			}
+		}
+	}
+}
+
+func (pv *PolicyValidator) validateOverlappingInSpecificDateBlackouts(policy *models.PolicyDefinition, specificDateBlackoutContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	specBlackouts := policy.BlackoutPeriods.SpecificDateBlackouts
+	var dateTimeRangeList []*DateTimeRange
+	for _, specBlackout := range specBlackouts {
+		dateTimeRangeList = append(dateTimeRangeList, newDateTimeRange(specBlackout.StartDateTime, specBlackout.EndDateTime, policy.BlackoutPeriods.Timezone))
+	}
+
+	for blackoutIndexB := 0; blackoutIndexB < len(specBlackouts); blackoutIndexB++ {
+		for blackoutIndexA := blackoutIndexB + 1; blackoutIndexA < len(specBlackouts); blackoutIndexA++ {
+			if dateTimeRangeList[blackoutIndexB].overlaps(dateTimeRangeList[blackoutIndexA]) {
+				context := gojsonschema.NewJsonContext(fmt.Sprintf("%d", blackoutIndexB), specificDateBlackoutContext)
+				errDetails := gojsonschema.ErrorDetails{
+					"blackoutIndexA":   blackoutIndexA,
+					"blackoutIndexB":   blackoutIndexB,
+					"start_date_time1": specBlackouts[blackoutIndexB].StartDateTime,
+					"end_date_time1":   specBlackouts[blackoutIndexB].EndDateTime,
+					"start_date_time2": specBlackouts[blackoutIndexA].StartDateTime,
+					"end_date_time2":   specBlackouts[blackoutIndexA].EndDateTime,
+				}
+
+				formatString := "specific_date[{{.blackoutIndexB}}]:{start_date_time: {{.start_date_time1}}, end_date_time: {{.end_date_time1}}} and specific_date[{{.blackoutIndexA}}]:{start_date_time: {{.start_date_time2}}, end_date_time: {{.end_date_time2}}} are overlapping"
+				err := newPolicyValidationError(context, formatString, errDetails)
+				result.AddError(err, errDetails)
+			}
+		}
+	}
+}
+
 func getErrorsObject(resErr []gojsonschema.ResultError) []PolicyValidationErrors {
 	var policyValidationErrorsResult []PolicyValidationErrors
 	for _, err := range resErr {
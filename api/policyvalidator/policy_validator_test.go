@@ -57,6 +57,7 @@ var _ = Describe("PolicyValidator", func() {
 			upperDiskUtilThreshold,
 			lowerDiskThreshold,
 			upperDiskThreshold,
+			40*time.Second,
 		)
 	})
 	JustBeforeEach(func() {
@@ -2508,6 +2509,545 @@ var _ = Describe("PolicyValidator", func() {
 			})
 
 		})
+
+		Context("Blackout Periods", func() {
+			Context("when valid blackout_periods are present", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"blackout_periods":{
+							"timezone":"Asia/Kolkata",
+							"recurring_blackout":[
+								{
+									"start_time":"02:00",
+									"end_time":"04:00",
+									"days_of_week":[6,7],
+									"mode":"no_scale_in"
+								}
+							],
+							"specific_date":[
+								{
+									"start_date_time":"2099-01-04T20:00",
+									"end_date_time":"2099-01-05T06:00",
+									"mode":"no_scaling"
+								}
+							]
+						}
+					}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyDefinition.BlackoutPeriods).To(Equal(&models.BlackoutPeriods{
+						Timezone: "Asia/Kolkata",
+						RecurringBlackouts: []*models.RecurringBlackout{
+							{StartTime: "02:00", EndTime: "04:00", DaysOfWeek: []int{6, 7}, Mode: models.BlackoutModeNoScaleIn},
+						},
+						SpecificDateBlackouts: []*models.SpecificDateBlackout{
+							{StartDateTime: "2099-01-04T20:00", EndDateTime: "2099-01-05T06:00", Mode: models.BlackoutModeNoScaling},
+						},
+					}))
+				})
+			})
+
+			Context("when mode is invalid", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"blackout_periods":{
+							"timezone":"Asia/Kolkata",
+							"specific_date":[
+								{
+									"start_date_time":"2099-01-04T20:00",
+									"end_date_time":"2099-01-05T06:00",
+									"mode":"no_scale_out"
+								}
+							]
+						}
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).blackout_periods.specific_date.0.mode",
+						Description: "blackout_periods.specific_date.0.mode must be one of the following: \"no_scale_in\", \"no_scaling\"",
+					}))
+				})
+			})
+
+			Context("when recurring_blackout start_time is after end_time", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"blackout_periods":{
+							"timezone":"Asia/Kolkata",
+							"recurring_blackout":[
+								{
+									"start_time":"04:00",
+									"end_time":"02:00",
+									"days_of_month":[1],
+									"mode":"no_scaling"
+								}
+							]
+						}
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).blackout_periods.recurring_blackout.0",
+						Description: "recurring_blackout[0].start_time is same or after recurring_blackout[0].end_time",
+					}))
+				})
+			})
+
+			Context("when there's an overlap between two recurring_blackout periods", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"blackout_periods":{
+							"timezone":"Asia/Kolkata",
+							"recurring_blackout":[
+								{
+									"start_time":"02:00",
+									"end_time":"04:00",
+									"days_of_week":[1,2],
+									"mode":"no_scaling"
+								},
+								{
+									"start_time":"03:00",
+									"end_time":"05:00",
+									"days_of_week":[2,3],
+									"mode":"no_scale_in"
+								}
+							]
+						}
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).blackout_periods.recurring_blackout.0",
+						Description: "recurring_blackout[0] and recurring_blackout[1] are overlapping",
+					}))
+				})
+			})
+
+			Context("when there's an overlap between two specific_date blackout periods", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"blackout_periods":{
+							"timezone":"Asia/Kolkata",
+							"specific_date":[
+								{
+									"start_date_time":"2099-01-04T20:00",
+									"end_date_time":"2099-01-05T06:00",
+									"mode":"no_scaling"
+								},
+								{
+									"start_date_time":"2099-01-05T05:00",
+									"end_date_time":"2099-01-05T08:00",
+									"mode":"no_scale_in"
+								}
+							]
+						}
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).blackout_periods.specific_date.0",
+						Description: "specific_date[0]:{start_date_time: 2099-01-04T20:00, end_date_time: 2099-01-05T06:00} and specific_date[1]:{start_date_time: 2099-01-05T05:00, end_date_time: 2099-01-05T08:00} are overlapping",
+					}))
+				})
+			})
+		})
+
+		Context("Instance Hour Budget", func() {
+			Context("when a valid instance_hour_budget is present", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"instance_hour_budget":{
+							"instance_hours":2000,
+							"period":"month"
+						}
+					}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyDefinition.InstanceHourBudget).To(Equal(&models.InstanceHourBudget{
+						InstanceHours: 2000,
+						Period:        models.BudgetPeriodMonth,
+					}))
+				})
+			})
+
+			Context("when period is invalid", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"instance_hour_budget":{
+							"instance_hours":2000,
+							"period":"year"
+						}
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).instance_hour_budget.period",
+						Description: "instance_hour_budget.period must be one of the following: \"day\", \"week\", \"month\"",
+					}))
+				})
+			})
+
+			Context("when instance_hours is less than 1", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						],
+						"instance_hour_budget":{
+							"instance_hours":0,
+							"period":"day"
+						}
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).instance_hour_budget.instance_hours",
+						Description: "Must be greater than or equal to 1",
+					}))
+				})
+			})
+		})
+
+		Context("Directional Cool-Downs", func() {
+			Context("when valid scale_out_cooldown_secs and scale_in_cooldown_secs are present", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scale_out_cooldown_secs":60,
+						"scale_in_cooldown_secs":600,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":30,
+								"operator":"<",
+								"adjustment": "-1",
+								"scale_in_cooldown_secs":900
+							}
+						]
+					}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyDefinition.ScaleOutCoolDownSeconds).To(Equal(60))
+					Expect(policyDefinition.ScaleInCoolDownSeconds).To(Equal(600))
+					Expect(policyDefinition.ScalingRules[0].ScaleInCoolDownSeconds).To(Equal(900))
+				})
+			})
+
+			Context("when scale_out_cooldown_secs is less than 60", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scale_out_cooldown_secs":30,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":90,
+								"operator":">=",
+								"adjustment": "+1"
+							}
+						]
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).scale_out_cooldown_secs",
+						Description: "Must be greater than or equal to 60",
+					}))
+				})
+			})
+
+			Context("when scale_in_cooldown_secs of a rule is greater than 3600", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":30,
+								"operator":"<",
+								"adjustment": "-1",
+								"scale_in_cooldown_secs":7200
+							}
+						]
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).scaling_rules.0.scale_in_cooldown_secs",
+						Description: "Must be less than or equal to 3600",
+					}))
+				})
+			})
+		})
+
+		Context("Emergency Thresholds", func() {
+			Context("when a valid emergency_threshold is present", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":80,
+								"operator":">=",
+								"adjustment": "+1",
+								"emergency_threshold":95,
+								"emergency_adjustment":"+3"
+							}
+						]
+					}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(*policyDefinition.ScalingRules[0].EmergencyThreshold).To(Equal(int64(95)))
+					Expect(policyDefinition.ScalingRules[0].EmergencyAdjustment).To(Equal("+3"))
+				})
+			})
+
+			Context("when emergency_adjustment is missing", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":80,
+								"operator":">=",
+								"adjustment": "+1",
+								"emergency_threshold":95
+							}
+						]
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).scaling_rules.0",
+						Description: "Has a dependency on emergency_adjustment",
+					}))
+				})
+			})
+
+			Context("when emergency_threshold is not beyond the threshold", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":80,
+								"operator":">=",
+								"adjustment": "+1",
+								"emergency_threshold":70,
+								"emergency_adjustment":"+3"
+							}
+						]
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).scaling_rules.0.emergency_threshold",
+						Description: "scaling_rules[0].emergency_threshold 70 should be greater than threshold 80",
+					}))
+				})
+			})
+
+			Context("when emergency_adjustment scales in the other direction", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"memoryutil",
+								"threshold":80,
+								"operator":">=",
+								"adjustment": "+1",
+								"emergency_threshold":95,
+								"emergency_adjustment":"-3"
+							}
+						]
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).scaling_rules.0.emergency_adjustment",
+						Description: "scaling_rules[0].emergency_adjustment should scale in the same direction as adjustment",
+					}))
+				})
+			})
+		})
+
+		Context("when scaling rules have a source app", func() {
+			Context("when all rules of a metric type have the same source app", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"queue_length",
+								"threshold":100,
+								"operator":">",
+								"adjustment": "+1",
+								"source_app_id":"a-source-app-id"
+							},
+							{
+								"metric_type":"queue_length",
+								"threshold":10,
+								"operator":"<",
+								"adjustment": "-1",
+								"source_app_id":"a-source-app-id"
+							}
+						]
+					}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policy.GetPolicyDefinition().ScalingRules[0].SourceAppId).To(Equal("a-source-app-id"))
+				})
+			})
+
+			Context("when the rules of a metric type have different source apps", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"queue_length",
+								"threshold":100,
+								"operator":">",
+								"adjustment": "+1",
+								"source_app_id":"a-source-app-id"
+							},
+							{
+								"metric_type":"queue_length",
+								"threshold":10,
+								"operator":"<",
+								"adjustment": "-1"
+							}
+						]
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).scaling_rules.1.source_app_id",
+						Description: "scaling_rules[1].source_app_id should be the same as for the other rules of metric_type queue_length",
+					}))
+				})
+			})
+
+			Context("when the source app id is not a valid id", func() {
+				BeforeEach(func() {
+					policyString = `{
+						"instance_max_count":4,
+						"instance_min_count":1,
+						"scaling_rules":[
+							{
+								"metric_type":"queue_length",
+								"threshold":100,
+								"operator":">",
+								"adjustment": "+1",
+								"source_app_id":"not an id"
+							}
+						]
+					}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(ContainElement(PolicyValidationErrors{
+						Context:     "(root).scaling_rules.0.source_app_id",
+						Description: "Does not match pattern '(^[a-zA-Z0-9_-]+$)'",
+					}))
+				})
+			})
+		})
 	})
 	Context("Binding Configuration with custom metrics strategy", func() {
 		When("custom_metrics is missing", func() {
@@ -0,0 +1,159 @@
+package policyvalidator
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+)
+
+// Lint returns warnings about a valid policy that is most likely not behaving as intended, e.g. because
+// a scale-in and a scale-out rule can both be breached by the same metric value. Unlike validation errors,
+// warnings do not prevent a policy from being attached.
+func (pv *PolicyValidator) Lint(policy *models.PolicyDefinition) ValidationErrors {
+	if policy == nil {
+		return nil
+	}
+
+	var warnings ValidationErrors
+	warnings = append(warnings, lintOverlappingThresholds(policy)...)
+	warnings = append(warnings, lintIneffectiveAdjustments(policy)...)
+	warnings = append(warnings, pv.lintBreachDurations(policy)...)
+	warnings = append(warnings, lintCoolDowns(policy)...)
+	warnings = append(warnings, lintScheduleInstanceMin(policy)...)
+	return warnings
+}
+
+func newWarning(context string, format string, args ...any) PolicyValidationErrors {
+	return PolicyValidationErrors{
+		Context:     "(root)." + context,
+		Description: fmt.Sprintf(format, args...),
+	}
+}
+
+func lintOverlappingThresholds(policy *models.PolicyDefinition) ValidationErrors {
+	var warnings ValidationErrors
+	for inIndex, scaleInRule := range policy.ScalingRules {
+		if models.DirectionOf(scaleInRule.Adjustment) != models.ScalingDirectionIn {
+			continue
+		}
+		for outIndex, scaleOutRule := range policy.ScalingRules {
+			if models.DirectionOf(scaleOutRule.Adjustment) != models.ScalingDirectionOut ||
+				scaleOutRule.MetricType != scaleInRule.MetricType {
+				continue
+			}
+			if thresholdsOverlap(scaleInRule, scaleOutRule) {
+				warnings = append(warnings, newWarning(fmt.Sprintf("scaling_rules.%d", inIndex),
+					"scaling_rules[%d] scaling in and scaling_rules[%d] scaling out can both be breached by the same value of metric_type %s",
+					inIndex, outIndex, scaleInRule.MetricType))
+			}
+		}
+	}
+	return warnings
+}
+
+// thresholdsOverlap reports whether there is a metric value that breaches both rules.
+func thresholdsOverlap(a *models.ScalingRule, b *models.ScalingRule) bool {
+	aIsUpperBound := a.Operator == "<" || a.Operator == "<="
+	bIsUpperBound := b.Operator == "<" || b.Operator == "<="
+	if aIsUpperBound == bIsUpperBound {
+		return true
+	}
+
+	upper, lower := a, b
+	if bIsUpperBound {
+		upper, lower = b, a
+	}
+	if upper.Threshold != lower.Threshold {
+		return upper.Threshold > lower.Threshold
+	}
+	return upper.Operator == "<=" && lower.Operator == ">="
+}
+
+// lintIneffectiveAdjustments warns about scaling rules which can never change the number of instances because
+// instance_min_count equals instance_max_count and no schedule allows another number of instances.
+func lintIneffectiveAdjustments(policy *models.PolicyDefinition) ValidationErrors {
+	if policy.InstanceMin != policy.InstanceMax || schedulesAllowScaling(policy.Schedules) {
+		return nil
+	}
+	var warnings ValidationErrors
+	for srIndex := range policy.ScalingRules {
+		warnings = append(warnings, newWarning(fmt.Sprintf("scaling_rules.%d.adjustment", srIndex),
+			"scaling_rules[%d].adjustment can never change the number of instances as instance_min_count and instance_max_count are both %d",
+			srIndex, policy.InstanceMax))
+	}
+	return warnings
+}
+
+func schedulesAllowScaling(schedules *models.ScalingSchedules) bool {
+	if schedules == nil {
+		return false
+	}
+	for _, recSched := range schedules.RecurringSchedules {
+		if recSched.ScheduledInstanceMin != recSched.ScheduledInstanceMax {
+			return true
+		}
+	}
+	for _, specSched := range schedules.SpecificDateSchedules {
+		if specSched.ScheduledInstanceMin != specSched.ScheduledInstanceMax {
+			return true
+		}
+	}
+	return false
+}
+
+// lintBreachDurations warns about breach durations which are shorter than the interval in which the metrics are
+// collected, as the threshold is then breached for at least a whole collection interval anyway.
+func (pv *PolicyValidator) lintBreachDurations(policy *models.PolicyDefinition) ValidationErrors {
+	collectionIntervalSecs := int(pv.metricCollectionInterval.Seconds())
+	var warnings ValidationErrors
+	for srIndex, scalingRule := range policy.ScalingRules {
+		if scalingRule.BreachDurationSeconds > 0 && scalingRule.BreachDurationSeconds < collectionIntervalSecs {
+			warnings = append(warnings, newWarning(fmt.Sprintf("scaling_rules.%d.breach_duration_secs", srIndex),
+				"scaling_rules[%d].breach_duration_secs %d is shorter than the metric collection interval of %d seconds",
+				srIndex, scalingRule.BreachDurationSeconds, collectionIntervalSecs))
+		}
+	}
+	return warnings
+}
+
+// lintCoolDowns warns about scaling rules whose cool-down is shorter than their breach duration, which lets the
+// rule scale again based on metrics that have mostly been collected before its previous scaling action.
+func lintCoolDowns(policy *models.PolicyDefinition) ValidationErrors {
+	var warnings ValidationErrors
+	for srIndex, scalingRule := range policy.ScalingRules {
+		coolDownSecs := policy.DirectionalCoolDownSeconds(scalingRule, models.DirectionOf(scalingRule.Adjustment))
+		if coolDownSecs <= 0 {
+			coolDownSecs = scalingRule.CoolDownSeconds
+		}
+		if coolDownSecs > 0 && scalingRule.BreachDurationSeconds > 0 && coolDownSecs < scalingRule.BreachDurationSeconds {
+			warnings = append(warnings, newWarning(fmt.Sprintf("scaling_rules.%d", srIndex),
+				"scaling_rules[%d] has a cool-down of %d seconds which is shorter than its breach_duration_secs %d",
+				srIndex, coolDownSecs, scalingRule.BreachDurationSeconds))
+		}
+	}
+	return warnings
+}
+
+// lintScheduleInstanceMin warns about schedules whose instance_min_count is above the instance_max_count of the
+// policy, so that the app is kept above the maximum it is scaled to outside of the schedule.
+func lintScheduleInstanceMin(policy *models.PolicyDefinition) ValidationErrors {
+	if policy.Schedules == nil {
+		return nil
+	}
+	var warnings ValidationErrors
+	for scheduleIndex, recSched := range policy.Schedules.RecurringSchedules {
+		if recSched.ScheduledInstanceMin > policy.InstanceMax {
+			warnings = append(warnings, newWarning(fmt.Sprintf("schedules.recurring_schedule.%d.instance_min_count", scheduleIndex),
+				"recurring_schedule[%d].instance_min_count %d is higher than the instance_max_count %d of the policy",
+				scheduleIndex, recSched.ScheduledInstanceMin, policy.InstanceMax))
+		}
+	}
+	for scheduleIndex, specSched := range policy.Schedules.SpecificDateSchedules {
+		if specSched.ScheduledInstanceMin > policy.InstanceMax {
+			warnings = append(warnings, newWarning(fmt.Sprintf("schedules.specific_date.%d.instance_min_count", scheduleIndex),
+				"specific_date[%d].instance_min_count %d is higher than the instance_max_count %d of the policy",
+				scheduleIndex, specSched.ScheduledInstanceMin, policy.InstanceMax))
+		}
+	}
+	return warnings
+}
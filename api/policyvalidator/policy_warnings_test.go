@@ -0,0 +1,215 @@
+package policyvalidator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/policyvalidator"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lint", func() {
+	var (
+		policyValidator *PolicyValidator
+		policy          *models.PolicyDefinition
+		warnings        ValidationErrors
+	)
+
+	rule := func(metricType string, operator string, threshold int64, adjustment string) *models.ScalingRule {
+		return &models.ScalingRule{
+			MetricType:            metricType,
+			BreachDurationSeconds: 120,
+			Threshold:             threshold,
+			Operator:              operator,
+			CoolDownSeconds:       300,
+			Adjustment:            adjustment,
+		}
+	}
+
+	BeforeEach(func() {
+		policyValidator = NewPolicyValidator("./json-schema/meta.schema.json", 1, 100, 1, 100, 1, 100, 1, 2048, 40*time.Second)
+		policy = &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 5}
+	})
+
+	JustBeforeEach(func() {
+		warnings = policyValidator.Lint(policy)
+	})
+
+	Context("when there is no policy", func() {
+		BeforeEach(func() {
+			policy = nil
+		})
+
+		It("returns no warnings", func() {
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	Context("when the scale-in threshold is below the scale-out threshold", func() {
+		BeforeEach(func() {
+			policy.ScalingRules = []*models.ScalingRule{
+				rule("memoryutil", ">", 80, "+1"),
+				rule("memoryutil", "<", 30, "-1"),
+			}
+		})
+
+		It("returns no warnings", func() {
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	Context("when the scale-in threshold is above the scale-out threshold", func() {
+		BeforeEach(func() {
+			policy.ScalingRules = []*models.ScalingRule{
+				rule("memoryutil", ">", 50, "+1"),
+				rule("memoryutil", "<", 60, "-1"),
+			}
+		})
+
+		It("warns about the overlapping thresholds", func() {
+			Expect(warnings).To(Equal(ValidationErrors{{
+				Context:     "(root).scaling_rules.1",
+				Description: "scaling_rules[1] scaling in and scaling_rules[0] scaling out can both be breached by the same value of metric_type memoryutil",
+			}}))
+		})
+	})
+
+	Context("when both thresholds are equal", func() {
+		Context("and both operators include the threshold", func() {
+			BeforeEach(func() {
+				policy.ScalingRules = []*models.ScalingRule{
+					rule("throughput", ">=", 100, "+1"),
+					rule("throughput", "<=", 100, "-1"),
+				}
+			})
+
+			It("warns about the overlapping thresholds", func() {
+				Expect(warnings).To(HaveLen(1))
+			})
+		})
+
+		Context("and one operator excludes the threshold", func() {
+			BeforeEach(func() {
+				policy.ScalingRules = []*models.ScalingRule{
+					rule("throughput", ">", 100, "+1"),
+					rule("throughput", "<=", 100, "-1"),
+				}
+			})
+
+			It("returns no warnings", func() {
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when the overlapping rules are for different metric types", func() {
+		BeforeEach(func() {
+			policy.ScalingRules = []*models.ScalingRule{
+				rule("memoryutil", ">", 50, "+1"),
+				rule("cpu", "<", 60, "-1"),
+			}
+		})
+
+		It("returns no warnings", func() {
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	Context("when instance_min_count equals instance_max_count", func() {
+		BeforeEach(func() {
+			policy.InstanceMin = 3
+			policy.InstanceMax = 3
+			policy.ScalingRules = []*models.ScalingRule{rule("memoryutil", ">", 80, "+1")}
+		})
+
+		It("warns that the adjustment can never change the number of instances", func() {
+			Expect(warnings).To(Equal(ValidationErrors{{
+				Context:     "(root).scaling_rules.0.adjustment",
+				Description: "scaling_rules[0].adjustment can never change the number of instances as instance_min_count and instance_max_count are both 3",
+			}}))
+		})
+
+		Context("and a schedule allows a different number of instances", func() {
+			BeforeEach(func() {
+				policy.Schedules = &models.ScalingSchedules{
+					Timezone: "UTC",
+					RecurringSchedules: []*models.RecurringSchedule{{
+						StartTime:            "10:00",
+						EndTime:              "18:00",
+						DaysOfWeek:           []int{1, 2, 3, 4, 5},
+						ScheduledInstanceMin: 2,
+						ScheduledInstanceMax: 3,
+					}},
+				}
+			})
+
+			It("returns no warnings", func() {
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when the breach duration is shorter than the metric collection interval", func() {
+		BeforeEach(func() {
+			scalingRule := rule("memoryutil", ">", 80, "+1")
+			scalingRule.BreachDurationSeconds = 30
+			policy.ScalingRules = []*models.ScalingRule{scalingRule}
+		})
+
+		It("warns about the breach duration", func() {
+			Expect(warnings).To(Equal(ValidationErrors{{
+				Context:     "(root).scaling_rules.0.breach_duration_secs",
+				Description: "scaling_rules[0].breach_duration_secs 30 is shorter than the metric collection interval of 40 seconds",
+			}}))
+		})
+
+		Context("and the metric collection interval is not configured", func() {
+			BeforeEach(func() {
+				policyValidator = NewPolicyValidator("./json-schema/meta.schema.json", 1, 100, 1, 100, 1, 100, 1, 2048, 0)
+			})
+
+			It("returns no warnings", func() {
+				Expect(warnings).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when the cool-down is shorter than the breach duration", func() {
+		BeforeEach(func() {
+			scalingRule := rule("memoryutil", ">", 80, "+1")
+			scalingRule.CoolDownSeconds = 60
+			policy.ScalingRules = []*models.ScalingRule{scalingRule}
+		})
+
+		It("warns about the cool-down", func() {
+			Expect(warnings).To(Equal(ValidationErrors{{
+				Context:     "(root).scaling_rules.0",
+				Description: "scaling_rules[0] has a cool-down of 60 seconds which is shorter than its breach_duration_secs 120",
+			}}))
+		})
+	})
+
+	Context("when the instance_min_count of a schedule is above the instance_max_count of the policy", func() {
+		BeforeEach(func() {
+			policy.Schedules = &models.ScalingSchedules{
+				Timezone: "UTC",
+				SpecificDateSchedules: []*models.SpecificDateSchedule{{
+					StartDateTime:        "2099-01-01T10:00",
+					EndDateTime:          "2099-01-02T10:00",
+					ScheduledInstanceMin: 8,
+					ScheduledInstanceMax: 10,
+				}},
+			}
+		})
+
+		It("warns about the schedule", func() {
+			Expect(warnings).To(Equal(ValidationErrors{{
+				Context:     "(root).schedules.specific_date.0.instance_min_count",
+				Description: "specific_date[0].instance_min_count 8 is higher than the instance_max_count 5 of the policy",
+			}}))
+		})
+	})
+})
@@ -0,0 +1,220 @@
+package policyvalidator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+)
+
+const (
+	ScheduleTypeRecurring    = "recurring_schedule"
+	ScheduleTypeSpecificDate = "specific_date"
+
+	// maxPreviewDays limits how far recurring schedules are looked ahead, which is enough to find a hundred
+	// windows of a schedule that only recurs on the 31st of a month.
+	maxPreviewDays = 10 * 366
+)
+
+type (
+	// ScheduleWindow is a period of time in which a schedule of a policy is in effect.
+	ScheduleWindow struct {
+		Type                    string    `json:"type"`
+		Index                   int       `json:"index"`
+		StartTime               time.Time `json:"start_time"`
+		EndTime                 time.Time `json:"end_time"`
+		LocalStartTime          time.Time `json:"local_start_time"`
+		LocalEndTime            time.Time `json:"local_end_time"`
+		InstanceMin             int       `json:"instance_min_count"`
+		InstanceMax             int       `json:"instance_max_count"`
+		InitialMinInstanceCount int       `json:"initial_min_instance_count,omitempty"`
+		// DSTGap is set if the start or end of the window does not exist in the local time as the clocks
+		// are set forward, and DSTOverlap if it exists twice as the clocks are set back.
+		DSTGap     bool `json:"dst_gap"`
+		DSTOverlap bool `json:"dst_overlap"`
+	}
+
+	SchedulePreview struct {
+		Timezone string           `json:"timezone,omitempty"`
+		Windows  []ScheduleWindow `json:"windows"`
+	}
+)
+
+// PreviewSchedules returns the next count windows of the schedules which end after from, ordered by their start.
+// Recurring schedules are expanded day by day in the timezone of the schedules, following the same date and time
+// layouts as the validation of the schedules.
+func PreviewSchedules(schedules *models.ScalingSchedules, from time.Time, count int) (*SchedulePreview, error) {
+	preview := &SchedulePreview{Windows: []ScheduleWindow{}}
+	if schedules == nil || count <= 0 {
+		return preview, nil
+	}
+
+	location, err := time.LoadLocation(schedules.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone %q: %w", schedules.Timezone, err)
+	}
+	preview.Timezone = schedules.Timezone
+
+	windows, err := specificDateWindows(schedules.SpecificDateSchedules, location, from)
+	if err != nil {
+		return nil, err
+	}
+	recurringWindows, err := recurringScheduleWindows(schedules.RecurringSchedules, location, from, count)
+	if err != nil {
+		return nil, err
+	}
+	windows = append(windows, recurringWindows...)
+
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].StartTime.Before(windows[j].StartTime)
+	})
+	if len(windows) > count {
+		windows = windows[:count]
+	}
+	preview.Windows = windows
+	return preview, nil
+}
+
+func specificDateWindows(specSchedules []*models.SpecificDateSchedule, location *time.Location, from time.Time) ([]ScheduleWindow, error) {
+	var windows []ScheduleWindow
+	for scheduleIndex, specSched := range specSchedules {
+		startWallClock, err := time.Parse(DateTimeLayout, specSched.StartDateTime)
+		if err != nil {
+			return nil, fmt.Errorf("specific_date[%d].start_date_time is invalid: %w", scheduleIndex, err)
+		}
+		endWallClock, err := time.Parse(DateTimeLayout, specSched.EndDateTime)
+		if err != nil {
+			return nil, fmt.Errorf("specific_date[%d].end_date_time is invalid: %w", scheduleIndex, err)
+		}
+
+		window := newScheduleWindow(ScheduleTypeSpecificDate, scheduleIndex, startWallClock, endWallClock, location)
+		window.InstanceMin = specSched.ScheduledInstanceMin
+		window.InstanceMax = specSched.ScheduledInstanceMax
+		window.InitialMinInstanceCount = specSched.ScheduledInstanceInit
+		if window.EndTime.After(from) {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+// recurringScheduleWindows returns the first count windows of the recurring schedules which end after from.
+func recurringScheduleWindows(recSchedules []*models.RecurringSchedule, location *time.Location, from time.Time, count int) ([]ScheduleWindow, error) {
+	if len(recSchedules) == 0 {
+		return nil, nil
+	}
+
+	startOffsets := make([]time.Duration, len(recSchedules))
+	endOffsets := make([]time.Duration, len(recSchedules))
+	for scheduleIndex, recSched := range recSchedules {
+		var err error
+		if startOffsets[scheduleIndex], err = timeOfDay(recSched.StartTime); err != nil {
+			return nil, fmt.Errorf("recurring_schedule[%d].start_time is invalid: %w", scheduleIndex, err)
+		}
+		if endOffsets[scheduleIndex], err = timeOfDay(recSched.EndTime); err != nil {
+			return nil, fmt.Errorf("recurring_schedule[%d].end_time is invalid: %w", scheduleIndex, err)
+		}
+	}
+
+	localFrom := from.In(location)
+	firstDate := time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, time.UTC)
+
+	var windows []ScheduleWindow
+	for day := 0; day < maxPreviewDays && len(windows) < count; day++ {
+		date := firstDate.AddDate(0, 0, day)
+		for scheduleIndex, recSched := range recSchedules {
+			if !recursOn(recSched, date) {
+				continue
+			}
+			window := newScheduleWindow(ScheduleTypeRecurring, scheduleIndex,
+				date.Add(startOffsets[scheduleIndex]), date.Add(endOffsets[scheduleIndex]), location)
+			window.InstanceMin = recSched.ScheduledInstanceMin
+			window.InstanceMax = recSched.ScheduledInstanceMax
+			window.InitialMinInstanceCount = recSched.ScheduledInstanceInit
+			if window.EndTime.After(from) {
+				windows = append(windows, window)
+			}
+		}
+	}
+	return windows, nil
+}
+
+func timeOfDay(clock string) (time.Duration, error) {
+	t, err := time.Parse(TimeLayout, clock)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// recursOn reports whether a recurring schedule is in effect on a date. Days of the week are numbered from 1 for
+// Monday to 7 for Sunday. The start and end dates are inclusive.
+func recursOn(recSched *models.RecurringSchedule, date time.Time) bool {
+	formattedDate := date.Format(DateLayout)
+	if recSched.StartDate != "" && formattedDate < recSched.StartDate {
+		return false
+	}
+	if recSched.EndDate != "" && formattedDate > recSched.EndDate {
+		return false
+	}
+
+	if len(recSched.DaysOfWeek) > 0 {
+		weekday := int(date.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		return slices.Contains(recSched.DaysOfWeek, weekday)
+	}
+	return slices.Contains(recSched.DaysOfMonth, date.Day())
+}
+
+// newScheduleWindow creates a window from a start and an end wall clock time, which are given in UTC and
+// interpreted in the location of the schedules.
+func newScheduleWindow(scheduleType string, scheduleIndex int, startWallClock time.Time, endWallClock time.Time, location *time.Location) ScheduleWindow {
+	start, startInGap, startInOverlap := resolveWallClock(startWallClock, location)
+	end, endInGap, endInOverlap := resolveWallClock(endWallClock, location)
+	return ScheduleWindow{
+		Type:           scheduleType,
+		Index:          scheduleIndex,
+		StartTime:      start.UTC(),
+		EndTime:        end.UTC(),
+		LocalStartTime: start,
+		LocalEndTime:   end,
+		DSTGap:         startInGap || endInGap,
+		DSTOverlap:     startInOverlap || endInOverlap,
+	}
+}
+
+// resolveWallClock returns the time at which a location's clocks show the wall clock time, which is given in UTC.
+// If the clocks skip the wall clock time, it returns the time as normalized by time.Date and reports a gap. If the
+// clocks show the wall clock time twice, it returns the earlier time and reports an overlap.
+func resolveWallClock(wallClock time.Time, location *time.Location) (t time.Time, inGap bool, inOverlap bool) {
+	var candidates []time.Time
+	// The offsets a day before and after are the ones on both sides of a daylight saving time transition.
+	for _, probe := range []time.Time{wallClock.Add(-24 * time.Hour), wallClock.Add(24 * time.Hour)} {
+		_, offset := probe.In(location).Zone()
+		candidate := wallClock.Add(-time.Duration(offset) * time.Second).In(location)
+		if showsWallClock(candidate, wallClock) && !slices.ContainsFunc(candidates, candidate.Equal) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(), wallClock.Hour(), wallClock.Minute(), 0, 0, location), true, false
+	case 1:
+		return candidates[0], false, false
+	default:
+		if candidates[1].Before(candidates[0]) {
+			return candidates[1], false, true
+		}
+		return candidates[0], false, true
+	}
+}
+
+func showsWallClock(t time.Time, wallClock time.Time) bool {
+	return t.Year() == wallClock.Year() && t.Month() == wallClock.Month() && t.Day() == wallClock.Day() &&
+		t.Hour() == wallClock.Hour() && t.Minute() == wallClock.Minute()
+}
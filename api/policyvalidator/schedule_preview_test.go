@@ -0,0 +1,195 @@
+package policyvalidator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/policyvalidator"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreviewSchedules", func() {
+	var (
+		schedules *models.ScalingSchedules
+		from      time.Time
+		count     int
+		preview   *SchedulePreview
+		err       error
+	)
+
+	BeforeEach(func() {
+		// A Monday in the week in which daylight saving time starts in Europe/Berlin
+		from = time.Date(2030, time.March, 25, 12, 0, 0, 0, time.UTC)
+		count = 3
+		schedules = &models.ScalingSchedules{Timezone: "Europe/Berlin"}
+	})
+
+	JustBeforeEach(func() {
+		preview, err = PreviewSchedules(schedules, from, count)
+	})
+
+	Context("when there are no schedules", func() {
+		BeforeEach(func() {
+			schedules = nil
+		})
+
+		It("returns no windows", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.Windows).To(BeEmpty())
+		})
+	})
+
+	Context("when the timezone is invalid", func() {
+		BeforeEach(func() {
+			schedules.Timezone = "Not/AZone"
+		})
+
+		It("fails", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when there is a recurring schedule on days of the week", func() {
+		BeforeEach(func() {
+			schedules.RecurringSchedules = []*models.RecurringSchedule{{
+				StartTime:             "08:00",
+				EndTime:               "14:00",
+				DaysOfWeek:            []int{1, 3},
+				ScheduledInstanceMin:  2,
+				ScheduledInstanceMax:  5,
+				ScheduledInstanceInit: 3,
+			}}
+		})
+
+		It("returns the next windows including the active one", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.Timezone).To(Equal("Europe/Berlin"))
+			Expect(preview.Windows).To(HaveLen(3))
+
+			Expect(preview.Windows[0]).To(Equal(ScheduleWindow{
+				Type:                    ScheduleTypeRecurring,
+				Index:                   0,
+				StartTime:               time.Date(2030, time.March, 25, 7, 0, 0, 0, time.UTC),
+				EndTime:                 time.Date(2030, time.March, 25, 13, 0, 0, 0, time.UTC),
+				LocalStartTime:          preview.Windows[0].LocalStartTime,
+				LocalEndTime:            preview.Windows[0].LocalEndTime,
+				InstanceMin:             2,
+				InstanceMax:             5,
+				InitialMinInstanceCount: 3,
+			}))
+			Expect(preview.Windows[0].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-03-25T08:00:00+01:00"))
+			Expect(preview.Windows[1].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-03-27T08:00:00+01:00"))
+			Expect(preview.Windows[2].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-04-01T08:00:00+02:00"))
+			Expect(preview.Windows[2].StartTime).To(Equal(time.Date(2030, time.April, 1, 6, 0, 0, 0, time.UTC)))
+		})
+
+		Context("and the schedule has ended", func() {
+			BeforeEach(func() {
+				schedules.RecurringSchedules[0].EndDate = "2030-03-24"
+			})
+
+			It("returns no windows", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(preview.Windows).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when there is a recurring schedule on days of the month", func() {
+		BeforeEach(func() {
+			count = 2
+			schedules.RecurringSchedules = []*models.RecurringSchedule{{
+				StartTime:            "10:00",
+				EndTime:              "11:00",
+				DaysOfMonth:          []int{31},
+				StartDate:            "2030-04-01",
+				ScheduledInstanceMin: 1,
+				ScheduledInstanceMax: 2,
+			}}
+		})
+
+		It("skips the months without that day and the days before the start date", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.Windows).To(HaveLen(2))
+			Expect(preview.Windows[0].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-05-31T10:00:00+02:00"))
+			Expect(preview.Windows[1].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-07-31T10:00:00+02:00"))
+		})
+	})
+
+	Context("when a schedule starts while the clocks are set forward", func() {
+		BeforeEach(func() {
+			schedules.RecurringSchedules = []*models.RecurringSchedule{{
+				StartTime:            "02:30",
+				EndTime:              "04:00",
+				DaysOfWeek:           []int{7},
+				ScheduledInstanceMin: 1,
+				ScheduledInstanceMax: 2,
+			}}
+		})
+
+		It("flags the gap", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.Windows[0].LocalEndTime.Format(time.RFC3339)).To(Equal("2030-03-31T04:00:00+02:00"))
+			Expect(preview.Windows[0].DSTGap).To(BeTrue())
+			Expect(preview.Windows[0].DSTOverlap).To(BeFalse())
+			Expect(preview.Windows[1].DSTGap).To(BeFalse())
+		})
+	})
+
+	Context("when a schedule starts while the clocks are set back", func() {
+		BeforeEach(func() {
+			schedules.SpecificDateSchedules = []*models.SpecificDateSchedule{{
+				StartDateTime:        "2030-10-27T02:30",
+				EndDateTime:          "2030-10-27T05:00",
+				ScheduledInstanceMin: 1,
+				ScheduledInstanceMax: 2,
+			}}
+		})
+
+		It("flags the overlap and starts at the first occurrence", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.Windows).To(HaveLen(1))
+			Expect(preview.Windows[0].Type).To(Equal(ScheduleTypeSpecificDate))
+			Expect(preview.Windows[0].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-10-27T02:30:00+02:00"))
+			Expect(preview.Windows[0].StartTime).To(Equal(time.Date(2030, time.October, 27, 0, 30, 0, 0, time.UTC)))
+			Expect(preview.Windows[0].DSTOverlap).To(BeTrue())
+			Expect(preview.Windows[0].DSTGap).To(BeFalse())
+		})
+	})
+
+	Context("when there are recurring and specific date schedules", func() {
+		BeforeEach(func() {
+			schedules.RecurringSchedules = []*models.RecurringSchedule{{
+				StartTime:            "08:00",
+				EndTime:              "09:00",
+				DaysOfWeek:           []int{1, 2, 3, 4, 5, 6, 7},
+				ScheduledInstanceMin: 1,
+				ScheduledInstanceMax: 2,
+			}}
+			schedules.SpecificDateSchedules = []*models.SpecificDateSchedule{{
+				StartDateTime:        "2030-03-26T12:00",
+				EndDateTime:          "2030-03-26T14:00",
+				ScheduledInstanceMin: 3,
+				ScheduledInstanceMax: 4,
+			}, {
+				StartDateTime:        "2030-03-01T12:00",
+				EndDateTime:          "2030-03-02T12:00",
+				ScheduledInstanceMin: 3,
+				ScheduledInstanceMax: 4,
+			}}
+		})
+
+		It("orders the windows by their start and leaves out the past ones", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview.Windows).To(HaveLen(3))
+			Expect(preview.Windows[0].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-03-26T08:00:00+01:00"))
+			Expect(preview.Windows[1].Type).To(Equal(ScheduleTypeSpecificDate))
+			Expect(preview.Windows[1].Index).To(Equal(0))
+			Expect(preview.Windows[1].InstanceMin).To(Equal(3))
+			Expect(preview.Windows[2].LocalStartTime.Format(time.RFC3339)).To(Equal("2030-03-27T08:00:00+01:00"))
+		})
+	})
+})
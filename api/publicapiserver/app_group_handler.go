@@ -0,0 +1,256 @@
+package publicapiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/google/uuid"
+)
+
+const ErrorMessageAppGroupNotFound = "App Group Not Found"
+
+// GetAppGroup returns the app group the app belongs to as leader or member.
+func (h *PublicApiHandler) GetAppGroup(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetAppGroup", lager.Data{"appId": appId})
+	logger.Info("Get App Group")
+
+	group, err := h.getAppGroup(w, r, logger, appId)
+	if err != nil {
+		return
+	}
+	handlers.WriteJSONResponse(w, http.StatusOK, group)
+}
+
+// SetAppGroup creates or replaces the app group which is led by the app. The leader of the group is the app, and the
+// leading metric is a metric type of its policy. The user needs to be allowed to manage all members, which need to be
+// bound to the autoscaler and must not belong to another group.
+func (h *PublicApiHandler) SetAppGroup(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("SetAppGroup", lager.Data{"appId": appId})
+	logger.Info("Set App Group")
+
+	groupBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("Failed to read request body", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to read request body")
+		return
+	}
+
+	group := &models.AppGroup{}
+	if err := json.Unmarshal(groupBytes, group); err != nil {
+		logger.Info("Failed to parse app group", lager.Data{"error": err.Error(), "group": string(groupBytes)})
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid app group: "+err.Error())
+		return
+	}
+	group.LeadingMetric.AppId = appId
+	if err := group.Validate(); err != nil {
+		logger.Info("Failed to validate app group", lager.Data{"error": err.Error()})
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid app group: "+err.Error())
+		return
+	}
+
+	policy, err := h.policydb.GetAppPolicy(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve policy", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
+		return
+	}
+	if !hasScalingRuleFor(policy, group.LeadingMetric.MetricType) {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid app group: no scaling rule of the policy of the app uses the leading metric %s", group.LeadingMetric.MetricType))
+		return
+	}
+
+	currentGroup, err := h.policydb.GetAppGroupByAppId(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve app group", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving app group")
+		return
+	}
+	switch {
+	case currentGroup == nil:
+		group.GroupId = uuid.NewString()
+	case currentGroup.LeaderAppId() == appId:
+		group.GroupId = currentGroup.GroupId
+	default:
+		writeErrorResponse(w, http.StatusConflict, fmt.Sprintf("The app is a member of the app group led by %s", currentGroup.LeaderAppId()))
+		return
+	}
+
+	for _, member := range group.Members {
+		if err := h.checkAppAccess(w, r, logger, member.AppId); err != nil {
+			return
+		}
+	}
+
+	err = h.policydb.SaveAppGroup(r.Context(), group)
+	if errors.Is(err, db.ErrConflict) {
+		writeErrorResponse(w, http.StatusConflict, "A member of the app group belongs to another app group")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to save app group", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error saving app group")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, group)
+}
+
+// DeleteAppGroup deletes the app group which is led by the app, after which all its apps scale on their own again.
+func (h *PublicApiHandler) DeleteAppGroup(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("DeleteAppGroup", lager.Data{"appId": appId})
+	logger.Info("Delete App Group")
+
+	group, err := h.getAppGroup(w, r, logger, appId)
+	if err != nil {
+		return
+	}
+	if group.LeaderAppId() != appId {
+		writeErrorResponse(w, http.StatusConflict, fmt.Sprintf("The app group can only be deleted through its leader %s", group.LeaderAppId()))
+		return
+	}
+
+	if err := h.policydb.DeleteAppGroup(r.Context(), group.GroupId); err != nil {
+		logger.Error("Failed to delete app group", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error deleting app group")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write([]byte("{}"))
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
+// GetGroupScalingHistories returns the scaling histories of the app group the app belongs to, the latest first. The
+// time range is given by the optional start-time and end-time parameters, the number of histories by limit.
+func (h *PublicApiHandler) GetGroupScalingHistories(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetGroupScalingHistories", lager.Data{"appId": appId})
+	logger.Info("Get Group Scaling Histories")
+
+	group, err := h.getAppGroup(w, r, logger, appId)
+	if err != nil {
+		return
+	}
+
+	query := url.Values{"start-time": []string{"0"}}
+	for _, parameter := range []string{"start-time", "end-time", "limit"} {
+		if value := r.URL.Query().Get(parameter); value != "" {
+			query.Set(parameter, value)
+		}
+	}
+
+	resp, err := h.getFromScalingEngineRoute(r.Context(), routes.GetGroupScalingHistoriesRouteName, query.Encode(), "groupid", group.GroupId)
+	if err != nil {
+		logger.Error("Failed to retrieve group scaling histories from scalingengine", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving group scaling histories")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		errorResponse := &models.ErrorResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(errorResponse); err != nil {
+			logger.Error("Error occurred during parsing group scaling histories error", err)
+		}
+		writeErrorResponse(w, http.StatusBadRequest, errorResponse.Message)
+		return
+	default:
+		logger.Error("Error occurred during getting group scaling histories", nil, lager.Data{"statusCode": resp.StatusCode})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving group scaling histories")
+		return
+	}
+
+	var histories []*models.GroupScalingHistory
+	if err := json.NewDecoder(resp.Body).Decode(&histories); err != nil {
+		logger.Error("Error occurred during parsing group scaling histories", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error parsing group scaling histories")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, histories)
+}
+
+// hasScalingRuleFor returns whether a scaling rule of the policy uses the metric type. Without such a rule, the
+// leader of a group with this leading metric would never be scaled.
+func hasScalingRuleFor(policy *models.PolicyDefinition, metricType string) bool {
+	if policy == nil {
+		return false
+	}
+	return slices.ContainsFunc(policy.ScalingRules, func(rule *models.ScalingRule) bool { return rule.MetricType == metricType })
+}
+
+// getAppGroup returns the app group the app belongs to, or responds with an error.
+func (h *PublicApiHandler) getAppGroup(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string) (*models.AppGroup, error) {
+	group, err := h.policydb.GetAppGroupByAppId(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve app group", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving app group")
+		return nil, err
+	}
+	if group == nil {
+		writeErrorResponse(w, http.StatusNotFound, ErrorMessageAppGroupNotFound)
+		return nil, db.ErrDoesNotExist
+	}
+	return group, nil
+}
+
+// checkAppAccess checks that an app other than the one of the request is bound to the autoscaler and that the user
+// of the request is an admin or allowed to manage it, like the middleware does for the app of the request. Otherwise,
+// it responds with an error.
+func (h *PublicApiHandler) checkAppAccess(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string) error {
+	if !h.bindingdb.CheckServiceBinding(appId) {
+		writeErrorResponse(w, http.StatusForbidden, fmt.Sprintf("The app %s is not bound to Auto-Scaling service", appId))
+		return db.ErrDoesNotExist
+	}
+	return h.checkUserAppRole(w, r, logger, appId, "manage", cf.AppDeveloperRoles...)
+}
+
+// checkUserAppRole checks that the user of the request is an admin or has one of the roles for an app other than
+// the one of the request. Otherwise, it responds with an error saying that the user may not perform the action on
+// the app.
+func (h *PublicApiHandler) checkUserAppRole(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string, action string, roles ...cf.RoleType) error {
+	_, userToken, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	isUserAdmin, err := h.cfClient.IsUserAdmin(r.Context(), userToken)
+	if err != nil {
+		logger.Error("Failed to check if user is admin", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check if user is admin")
+		return err
+	}
+	if isUserAdmin {
+		return nil
+	}
+
+	hasRole, err := h.cfClient.HasUserAppRole(r.Context(), userToken, cf.Guid(appId), roles...)
+	switch {
+	case cf.IsNotFound(err):
+		writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("The app %s does not exist", appId))
+		return err
+	case err != nil && !errors.Is(err, cf.ErrUnauthorized):
+		logger.Error("Failed to check role permissions", err, lager.Data{"otherAppId": appId})
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check role permissions")
+		return err
+	case err != nil || !hasRole:
+		writeErrorResponse(w, http.StatusForbidden, fmt.Sprintf("You are not authorized to %s the app %s", action, appId))
+		return cf.ErrUnauthorized
+	}
+	return nil
+}
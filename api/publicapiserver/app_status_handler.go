@@ -0,0 +1,169 @@
+package publicapiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// GetAppStatus returns the current autoscaling state of an app: the instance limits that apply now, its cool-downs,
+// whether scaling is disabled, the state of its scaling rules and its last scaling. The last value of each rule is the
+// latest aggregated metric of the app. Whether the rules are breached and the state of the circuit breaker are kept in
+// memory by the eventgenerator instance which evaluates the app, so they are left out when that instance cannot be
+// reached.
+func (h *PublicApiHandler) GetAppStatus(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetAppStatus", lager.Data{"appId": appId})
+	logger.Info("Get AppStatus")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	ctx := req.Context()
+	policy, err := h.policydb.GetAppPolicy(ctx, appId)
+	if err != nil {
+		logger.Error("Failed to retrieve policy from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
+		return
+	}
+	if policy == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Policy Not Found")
+		return
+	}
+
+	resp, err := h.getFromScalingEngine(ctx, routes.GetScalingStateRouteName, appId, "")
+	if err != nil {
+		logger.Error("Failed to retrieve scaling state from scalingengine", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving app status")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// the policy has been detached in the meantime
+		writeErrorResponse(w, http.StatusNotFound, "Policy Not Found")
+		return
+	default:
+		logger.Error("Error occurred during getting scaling state", nil, lager.Data{"statusCode": resp.StatusCode})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving app status")
+		return
+	}
+
+	scalingState := &models.AppScalingState{}
+	if err := json.NewDecoder(resp.Body).Decode(scalingState); err != nil {
+		logger.Error("Error occurred during parsing scaling state", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error parsing app status")
+		return
+	}
+
+	evaluationState, err := h.getEvaluationState(ctx, appId)
+	if err != nil {
+		logger.Info("evaluation state is not available", lager.Data{"reason": err.Error()})
+	}
+
+	latestMetrics := map[string]*models.AppMetric{}
+	for _, rule := range policy.ScalingRules {
+		if _, found := latestMetrics[rule.MetricType]; found {
+			continue
+		}
+		metric, err := h.getLatestMetric(ctx, appId, rule.MetricType)
+		if err != nil {
+			logger.Info("latest metric is not available", lager.Data{"metricType": rule.MetricType, "reason": err.Error()})
+		}
+		latestMetrics[rule.MetricType] = metric
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, models.NewAppStatus(policy, scalingState, evaluationState, latestMetrics))
+}
+
+// eventGeneratorUrlFor returns the URL of the eventgenerator instance which evaluates an app. The instances split the
+// apps by the same hash of the app id.
+func (h *PublicApiHandler) eventGeneratorUrlFor(appId string) string {
+	instanceUrls := h.conf.EventGenerator.InstanceUrls
+	if len(instanceUrls) == 0 {
+		return h.conf.EventGenerator.EventGeneratorUrl
+	}
+	return instanceUrls[helpers.OwnerIndex(appId, len(instanceUrls))]
+}
+
+// getEvaluationState returns the evaluation state of an app from the eventgenerator instance which evaluates it, or nil
+// if that instance does not know the app.
+func (h *PublicApiHandler) getEvaluationState(ctx context.Context, appId string) (*models.AppEvaluationState, error) {
+	path, err := routes.NewRouter().CreateEventGeneratorSubrouter().Get(routes.GetEvaluationStateRouteName).URLPath("appid", appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create path: %w", err)
+	}
+
+	aUrl := h.eventGeneratorUrlFor(appId) + path.RequestURI()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, aUrl, nil) // #nosec G704 -- URL host from internal config, path from validated route params
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := h.eventGeneratorClient.Do(req) // #nosec G704 -- URL host from internal config, path from validated route params
+	if err != nil {
+		return nil, fmt.Errorf("failed to get evaluation state: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get evaluation state: unexpected status code %d", resp.StatusCode)
+	}
+
+	state := &models.AppEvaluationState{}
+	if err := json.NewDecoder(resp.Body).Decode(state); err != nil {
+		return nil, fmt.Errorf("failed to parse evaluation state: %w", err)
+	}
+	return state, nil
+}
+
+// getLatestMetric returns the latest aggregated metric of an app of the given type, or nil if there is none. The
+// aggregated metrics are stored in the database, so any eventgenerator instance can return them.
+func (h *PublicApiHandler) getLatestMetric(ctx context.Context, appId string, metricType string) (*models.AppMetric, error) {
+	path, err := routes.NewRouter().CreateEventGeneratorSubrouter().Get(routes.GetAggregatedMetricHistoriesRouteName).URLPath("appid", appId, "metrictype", metricType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create path: %w", err)
+	}
+
+	parameters := url.Values{"order": {db.DESCSTR}, "limit": {"1"}}
+	aUrl := h.conf.EventGenerator.EventGeneratorUrl + path.RequestURI() + "?" + parameters.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, aUrl, nil) // #nosec G704 -- URL host from internal config, path from validated route params
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := h.eventGeneratorClient.Do(req) // #nosec G704 -- URL host from internal config, path from validated route params
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest metric: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get latest metric: unexpected status code %d", resp.StatusCode)
+	}
+
+	page := &models.AppMetricPage{}
+	if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+		return nil, fmt.Errorf("failed to parse latest metric: %w", err)
+	}
+	if len(page.Resources) == 0 {
+		return nil, nil
+	}
+	return page.Resources[0], nil
+}
@@ -0,0 +1,210 @@
+package publicapiserver
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/google/uuid"
+)
+
+const ErrorMessageDefaultPolicyNotFound = "Default Policy Not Found"
+
+// GetDefaultPolicy returns the default policy of a service instance, including its configuration.
+func (h *PublicApiHandler) GetDefaultPolicy(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	instanceId := vars["instanceId"]
+	logger := h.logger.Session("GetDefaultPolicy", lager.Data{"instanceId": instanceId})
+	logger.Info("Get Default Policy")
+
+	serviceInstance, err := h.getServiceInstance(w, r, logger, instanceId)
+	if err != nil {
+		return
+	}
+	if serviceInstance.DefaultPolicy == "" {
+		writeErrorResponse(w, http.StatusNotFound, ErrorMessageDefaultPolicyNotFound)
+		return
+	}
+
+	defaultPolicy, err := models.ScalingPolicyFromRawJSON([]byte(serviceInstance.DefaultPolicy))
+	if err != nil {
+		logger.Error("Failed to parse default policy", err, lager.Data{"defaultPolicy": serviceInstance.DefaultPolicy})
+		writeErrorResponse(w, http.StatusInternalServerError, "Default policy not valid")
+		return
+	}
+	handlers.WriteJSONResponse(w, http.StatusOK, defaultPolicy)
+}
+
+// SetDefaultPolicy sets the default policy of a service instance. The policy is validated and checked against the
+// service plan of the service instance, and replaces the policy of all bound apps which use the previous default
+// policy or have no policy, like an update of the service instance with a new default policy does.
+func (h *PublicApiHandler) SetDefaultPolicy(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	instanceId := vars["instanceId"]
+	logger := h.logger.Session("SetDefaultPolicy", lager.Data{"instanceId": instanceId})
+	logger.Info("Set Default Policy")
+
+	policyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("Failed to read request body", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to read request body")
+		return
+	}
+
+	defaultPolicy, errResults := h.policyValidator.ParseAndValidatePolicy(policyBytes)
+	if errResults != nil {
+		logger.Info("Failed to validate policy", lager.Data{"errResults": errResults, "policy": string(policyBytes)})
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, errResults)
+		return
+	}
+	policy := defaultPolicy.GetPolicyDefinition()
+
+	serviceInstance, err := h.getServiceInstance(w, r, logger, instanceId)
+	if err != nil {
+		return
+	}
+
+	// a default policy may use the metrics of apps in the space of the service instance
+	if err := h.checkSourceAppsInSpace(w, r, logger, serviceInstance.SpaceId, policy.SourceAppIds("")); err != nil {
+		return
+	}
+
+	if h.conf.PlanCheck != nil {
+		planId, err := h.getServicePlanIdOfInstance(r.Context(), instanceId)
+		if err != nil {
+			logger.Error("Failed to determine the service plan", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error determining the service plan")
+			return
+		}
+		if err := h.checkPolicyAdheresToPlan(w, logger, planId, policy); err != nil {
+			return
+		}
+	}
+
+	// We generate the json again on our own to ensure a consistent formatting.
+	defaultPolicyJson, err := defaultPolicy.ToRawJSON()
+	if err != nil {
+		logger.Error("Failed to convert default policy to raw JSON", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error saving default policy")
+		return
+	}
+
+	boundApps, err := h.bindingdb.GetAppIdsByInstanceId(r.Context(), instanceId)
+	if err != nil {
+		logger.Error("Failed to retrieve bound apps", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving bound apps")
+		return
+	}
+
+	defaultPolicyGuid := uuid.NewString()
+	change := models.PolicyChange{Author: requestAuthor(r), Source: models.PolicySourceDefaultPolicy}
+	updatedApps, err := h.policydb.SetOrUpdateDefaultAppPolicy(r.Context(), boundApps, serviceInstance.DefaultPolicyGuid, policy, defaultPolicyGuid, change)
+	if err != nil {
+		logger.Error("Failed to set default policy on bound apps", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error setting default policy")
+		return
+	}
+
+	// the apps refer to the new default policy guid now, so it is saved before the schedules are updated
+	if err := h.updateDefaultPolicy(w, r, logger, serviceInstance, string(defaultPolicyJson), defaultPolicyGuid); err != nil {
+		return
+	}
+
+	var errs []error
+	for _, appId := range updatedApps {
+		if err := h.schedulerUtil.CreateOrUpdateSchedule(r.Context(), appId, policy, defaultPolicyGuid); err != nil {
+			logger.Error("Failed to create/update schedules", err, lager.Data{"appId": appId})
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.Join(errs...).Error())
+		return
+	}
+
+	responseJson, err := withPolicyWarnings(defaultPolicyJson, h.policyValidator.Lint(policy))
+	if err != nil {
+		logger.Error("Failed to build response", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error building response")
+		return
+	}
+	_, err = w.Write(responseJson) // #nosec G705 -- JSON marshaled from struct, not user input
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
+// DeleteDefaultPolicy removes the default policy of a service instance, and detaches it from all bound apps which use
+// it, like an update of the service instance with an empty default policy does.
+func (h *PublicApiHandler) DeleteDefaultPolicy(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	instanceId := vars["instanceId"]
+	logger := h.logger.Session("DeleteDefaultPolicy", lager.Data{"instanceId": instanceId})
+	logger.Info("Delete Default Policy")
+
+	serviceInstance, err := h.getServiceInstance(w, r, logger, instanceId)
+	if err != nil {
+		return
+	}
+	if serviceInstance.DefaultPolicy == "" {
+		writeErrorResponse(w, http.StatusNotFound, ErrorMessageDefaultPolicyNotFound)
+		return
+	}
+
+	updatedApps, err := h.policydb.DeletePoliciesByPolicyGuid(r.Context(), serviceInstance.DefaultPolicyGuid)
+	if err != nil {
+		logger.Error("Failed to delete default policy from bound apps", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error deleting default policy")
+		return
+	}
+
+	if err := h.updateDefaultPolicy(w, r, logger, serviceInstance, "", ""); err != nil {
+		return
+	}
+
+	// there is synchronization between policy and schedule, so errors deleting schedules do not fail the request
+	for _, appId := range updatedApps {
+		if err := h.schedulerUtil.DeleteSchedule(r.Context(), appId); err != nil {
+			logger.Error("Failed to delete schedules", err, lager.Data{"appId": appId})
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write([]byte("{}"))
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
+// getServiceInstance returns the service instance with the id instanceId, or responds with an error.
+func (h *PublicApiHandler) getServiceInstance(w http.ResponseWriter, r *http.Request, logger lager.Logger, instanceId string) (*models.ServiceInstance, error) {
+	serviceInstance, err := h.bindingdb.GetServiceInstance(r.Context(), instanceId)
+	if errors.Is(err, db.ErrDoesNotExist) {
+		writeErrorResponse(w, http.StatusNotFound, "Service Instance Not Found")
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to retrieve service instance from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving service instance")
+		return nil, err
+	}
+	return serviceInstance, nil
+}
+
+// updateDefaultPolicy saves the default policy of a service instance, or removes it if defaultPolicy is empty.
+func (h *PublicApiHandler) updateDefaultPolicy(w http.ResponseWriter, r *http.Request, logger lager.Logger, serviceInstance *models.ServiceInstance, defaultPolicy string, defaultPolicyGuid string) error {
+	updatedServiceInstance := models.ServiceInstance{
+		ServiceInstanceId: serviceInstance.ServiceInstanceId,
+		OrgId:             serviceInstance.OrgId,
+		SpaceId:           serviceInstance.SpaceId,
+		DefaultPolicy:     defaultPolicy,
+		DefaultPolicyGuid: defaultPolicyGuid,
+	}
+	if err := h.bindingdb.UpdateServiceInstance(r.Context(), updatedServiceInstance); err != nil {
+		logger.Error("Failed to update service instance", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error updating service instance")
+		return err
+	}
+	return nil
+}
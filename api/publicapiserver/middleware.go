@@ -8,6 +8,7 @@ import (
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
@@ -35,19 +36,8 @@ func (mw *Middleware) Oauth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 
-		authHeaderValue := r.Header.Get("Authorization")
-		if authHeaderValue == "" {
-			mw.logger.Error("authorization-header-is-not-present", nil, lager.Data{"url": r.URL.String()})
-			handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
-				Code:    "Unauthorized",
-				Message: "Authorization header is not present"})
-			return
-		}
-		userToken, err := mw.extractBearerToken(authHeaderValue)
-		if err != nil {
-			handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
-				Code:    "Unauthorized",
-				Message: "Invalid bearer token"})
+		userToken, ok := mw.getUserToken(w, r)
+		if !ok {
 			return
 		}
 		appId := vars["appId"]
@@ -59,49 +49,212 @@ func (mw *Middleware) Oauth(next http.Handler) http.Handler {
 			})
 			return
 		}
-		isUserAdmin, err := mw.cfClient.IsUserAdmin(r.Context(), userToken)
+		mw.authorizeForApp(w, r, next, userToken, appId, requiredAppRoles(r))
+	})
+}
+
+// requiredAppRoles returns the roles of which a user needs one to perform a request for an app. Requests which only
+// read, like getting the policy, histories or metrics, are allowed to more roles than requests which change anything.
+func requiredAppRoles(r *http.Request) []cf.RoleType {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return cf.AppReaderRoles
+	default:
+		return cf.AppDeveloperRoles
+	}
+}
+
+// OauthWithOptionalAppId authorizes the user to read the app given in the query parameter `app_id`, as the
+// requests it protects do not change anything. Without such an app the user only needs to be authenticated.
+func (mw *Middleware) OauthWithOptionalAppId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userToken, ok := mw.getUserToken(w, r)
+		if !ok {
+			return
+		}
+		if appId := r.URL.Query().Get("app_id"); appId != "" {
+			mw.authorizeForApp(w, r, next, userToken, appId, cf.AppReaderRoles)
+			return
+		}
+		isUserAuthenticated, err := mw.cfClient.IsUserAuthenticated(r.Context(), userToken)
 		if err != nil {
-			mw.logger.Error("failed to check if user is admin", err, nil)
+			mw.logger.Error("failed to check if user is authenticated", err, nil)
 			handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
 				Code:    http.StatusText(http.StatusInternalServerError),
-				Message: "Failed to check if user is admin"})
+				Message: "Failed to check if user is authenticated"})
 			return
 		}
-		if isUserAdmin {
+		if isUserAuthenticated {
 			next.ServeHTTP(w, r)
 			return
 		}
-		isUserSpaceDeveloper, err := mw.cfClient.IsUserSpaceDeveloper(r.Context(), userToken, cf.Guid(appId))
-		if err != nil {
-			switch {
-			case cf.IsNotFound(err):
-				handlers.WriteJSONResponse(w, http.StatusNotFound, models.ErrorResponse{
-					Code:    "App not found",
-					Message: "The app guid supplied does not exist"})
+
+		handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "Unauthorized",
+			Message: "You are not authorized to perform the requested action"})
+	})
+}
+
+func (mw *Middleware) getUserToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	authHeaderValue := r.Header.Get("Authorization")
+	if authHeaderValue == "" {
+		mw.logger.Error("authorization-header-is-not-present", nil, lager.Data{"url": r.URL.String()})
+		handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "Unauthorized",
+			Message: "Authorization header is not present"})
+		return "", false
+	}
+	userToken, err := mw.extractBearerToken(authHeaderValue)
+	if err != nil {
+		handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "Unauthorized",
+			Message: "Invalid bearer token"})
+		return "", false
+	}
+	return userToken, true
+}
+
+func (mw *Middleware) authorizeForApp(w http.ResponseWriter, r *http.Request, next http.Handler, userToken string, appId string, roleTypes []cf.RoleType) {
+	mw.authorize(w, r, next, userToken, roleTypes, func() (bool, error) {
+		return mw.cfClient.HasUserAppRole(r.Context(), userToken, cf.Guid(appId), roleTypes...)
+	}, models.ErrorResponse{
+		Code:    "App not found",
+		Message: "The app guid supplied does not exist"})
+}
+
+// OauthForSpace authorizes the user to read the space given in the route variable `spaceId`, which requires one of
+// the roles that allow reading the apps of the space.
+func (mw *Middleware) OauthForSpace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userToken, ok := mw.getUserToken(w, r)
+		if !ok {
+			return
+		}
+		spaceId := mux.Vars(r)["spaceId"]
+		if spaceId == "" {
+			mw.logger.Error("spaceId is not present", nil, lager.Data{"url": r.URL.String()})
+			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+				Code:    "Bad Request",
+				Message: "Malformed or missing spaceId",
+			})
+			return
+		}
+		mw.authorize(w, r, next, userToken, cf.AppReaderRoles, func() (bool, error) {
+			return mw.cfClient.HasUserSpaceRole(r.Context(), userToken, cf.SpaceId(spaceId), cf.AppReaderRoles...)
+		}, models.ErrorResponse{
+			Code:    "Space not found",
+			Message: "The space guid supplied does not exist"})
+	})
+}
+
+// OauthForOrg authorizes the user to read the organization given in the route variable `orgId`, which requires one
+// of the roles that allow reading the apps of all spaces of the organization.
+func (mw *Middleware) OauthForOrg(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userToken, ok := mw.getUserToken(w, r)
+		if !ok {
+			return
+		}
+		orgId := mux.Vars(r)["orgId"]
+		if orgId == "" {
+			mw.logger.Error("orgId is not present", nil, lager.Data{"url": r.URL.String()})
+			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+				Code:    "Bad Request",
+				Message: "Malformed or missing orgId",
+			})
+			return
+		}
+		mw.authorize(w, r, next, userToken, cf.OrgAppReaderRoles, func() (bool, error) {
+			return mw.cfClient.HasUserOrgRole(r.Context(), userToken, cf.OrgId(orgId), cf.OrgAppReaderRoles...)
+		}, models.ErrorResponse{
+			Code:    "Organization not found",
+			Message: "The organization guid supplied does not exist"})
+	})
+}
+
+// OauthForServiceInstance authorizes the user to manage the service instance given in the route variable
+// `instanceId`, which requires to be a space developer of the space of the service instance.
+func (mw *Middleware) OauthForServiceInstance(bindingDB db.BindingDB) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userToken, ok := mw.getUserToken(w, r)
+			if !ok {
 				return
-			case errors.Is(err, cf.ErrUnauthorized):
-				handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
-					Code:    "Unauthorized",
-					Message: "You are not authorized to perform the requested action"})
+			}
+			instanceId := mux.Vars(r)["instanceId"]
+			if instanceId == "" {
+				mw.logger.Error("instanceId is not present", nil, lager.Data{"url": r.URL.String()})
+				handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+					Code:    "Bad Request",
+					Message: "Malformed or missing instanceId",
+				})
 				return
-			default:
-				mw.logger.Error("failed to check space developer permissions", err, nil)
+			}
+			serviceInstanceNotFound := models.ErrorResponse{
+				Code:    "Service instance not found",
+				Message: "The service instance guid supplied does not exist"}
+			serviceInstance, err := bindingDB.GetServiceInstance(r.Context(), instanceId)
+			if err != nil {
+				if errors.Is(err, db.ErrDoesNotExist) {
+					handlers.WriteJSONResponse(w, http.StatusNotFound, serviceInstanceNotFound)
+					return
+				}
+				mw.logger.Error("failed to get service instance", err, lager.Data{"instanceId": instanceId})
 				handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
-					Code:    "Internal-Server-Error",
-					Message: "Failed to check space developer permission"})
+					Code:    http.StatusText(http.StatusInternalServerError),
+					Message: "Failed to retrieve service instance"})
 				return
 			}
-		}
+			mw.authorize(w, r, next, userToken, cf.AppDeveloperRoles, func() (bool, error) {
+				return mw.cfClient.HasUserSpaceRole(r.Context(), userToken, cf.SpaceId(serviceInstance.SpaceId), cf.AppDeveloperRoles...)
+			}, serviceInstanceNotFound)
+		})
+	}
+}
 
-		if isUserSpaceDeveloper {
-			next.ServeHTTP(w, r)
+// authorize lets admins and users for which hasRole is true through. notFound is written if hasRole fails because
+// the app, space or organization does not exist.
+func (mw *Middleware) authorize(w http.ResponseWriter, r *http.Request, next http.Handler, userToken string, roleTypes []cf.RoleType, hasRole func() (bool, error), notFound models.ErrorResponse) {
+	isUserAdmin, err := mw.cfClient.IsUserAdmin(r.Context(), userToken)
+	if err != nil {
+		mw.logger.Error("failed to check if user is admin", err, nil)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    http.StatusText(http.StatusInternalServerError),
+			Message: "Failed to check if user is admin"})
+		return
+	}
+	if isUserAdmin {
+		next.ServeHTTP(w, r)
+		return
+	}
+	hasRequiredRole, err := hasRole()
+	if err != nil {
+		switch {
+		case cf.IsNotFound(err):
+			handlers.WriteJSONResponse(w, http.StatusNotFound, notFound)
+			return
+		case errors.Is(err, cf.ErrUnauthorized):
+			handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
+				Code:    "Unauthorized",
+				Message: "You are not authorized to perform the requested action"})
+			return
+		default:
+			mw.logger.Error("failed to check role permissions", err, lager.Data{"roleTypes": roleTypes})
+			handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+				Code:    "Internal-Server-Error",
+				Message: "Failed to check role permissions"})
 			return
 		}
+	}
 
-		handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
-			Code:    "Unauthorized",
-			Message: "You are not authorized to perform the requested action"})
-	})
+	if hasRequiredRole {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusUnauthorized, models.ErrorResponse{
+		Code:    "Unauthorized",
+		Message: "You are not authorized to perform the requested action"})
 }
 
 func (mw *Middleware) CheckServiceBinding(next http.Handler) http.Handler {
@@ -16,6 +16,7 @@ import (
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/publicapiserver"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
@@ -111,7 +112,7 @@ var _ = Describe("Middleware", func() {
 		Context("App does not exist", func() {
 			BeforeEach(func() {
 				fakeCFClient.IsUserAdminReturns(false, nil)
-				fakeCFClient.IsUserSpaceDeveloperReturns(false, cf.CfResourceNotFound)
+				fakeCFClient.HasUserAppRoleReturns(false, cf.CfResourceNotFound)
 				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID, nil)
 				req.Header.Add("Authorization", TEST_USER_TOKEN)
 			})
@@ -163,7 +164,7 @@ var _ = Describe("Middleware", func() {
 		Context("isspacedeveloper check fails", func() {
 			BeforeEach(func() {
 				fakeCFClient.IsUserAdminReturns(false, nil)
-				fakeCFClient.IsUserSpaceDeveloperReturns(false, fmt.Errorf("failed to check space developer permissions"))
+				fakeCFClient.HasUserAppRoleReturns(false, fmt.Errorf("failed to check space developer permissions"))
 
 				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID, nil)
 				req.Header.Add("Authorization", TEST_USER_TOKEN)
@@ -171,14 +172,14 @@ var _ = Describe("Middleware", func() {
 			It("should fail with 500", func() {
 				CheckResponse(resp, http.StatusInternalServerError, models.ErrorResponse{
 					Code:    "Internal-Server-Error",
-					Message: "Failed to check space developer permission",
+					Message: "Failed to check role permissions",
 				})
 			})
 		})
 		Context("isspacedeveloper check fails unauthorised", func() {
 			BeforeEach(func() {
 				fakeCFClient.IsUserAdminReturns(false, nil)
-				fakeCFClient.IsUserSpaceDeveloperReturns(false, fmt.Errorf("wrapped error %w", cf.ErrUnauthorized))
+				fakeCFClient.HasUserAppRoleReturns(false, fmt.Errorf("wrapped error %w", cf.ErrUnauthorized))
 
 				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID, nil)
 				req.Header.Add("Authorization", TEST_USER_TOKEN)
@@ -194,7 +195,7 @@ var _ = Describe("Middleware", func() {
 		Context("user is space developer", func() {
 			BeforeEach(func() {
 				fakeCFClient.IsUserAdminReturns(false, nil)
-				fakeCFClient.IsUserSpaceDeveloperReturns(true, nil)
+				fakeCFClient.HasUserAppRoleReturns(true, nil)
 
 				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID, nil)
 				req.Header.Add("Authorization", TEST_USER_TOKEN)
@@ -204,10 +205,43 @@ var _ = Describe("Middleware", func() {
 			})
 		})
 
+		Context("user only reads the app", func() {
+			BeforeEach(func() {
+				fakeCFClient.IsUserAdminReturns(false, nil)
+				fakeCFClient.HasUserAppRoleReturns(true, nil)
+
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID, nil)
+				req.Header.Add("Authorization", TEST_USER_TOKEN)
+			})
+			It("accepts the read-only roles", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, token, appId, roleTypes := fakeCFClient.HasUserAppRoleArgsForCall(0)
+				Expect(token).To(Equal(TEST_BEARER_TOKEN))
+				Expect(appId).To(Equal(cf.Guid(TEST_APP_ID)))
+				Expect(roleTypes).To(ConsistOf(cf.RoleSpaceDeveloper, cf.RoleSpaceAuditor, cf.RoleSpaceSupporter,
+					cf.RoleSpaceManager, cf.RoleOrganizationManager))
+			})
+		})
+
+		Context("user changes the app", func() {
+			BeforeEach(func() {
+				fakeCFClient.IsUserAdminReturns(false, nil)
+				fakeCFClient.HasUserAppRoleReturns(true, nil)
+
+				req = httptest.NewRequest(http.MethodPut, "/v1/apps/"+TEST_APP_ID, nil)
+				req.Header.Add("Authorization", TEST_USER_TOKEN)
+			})
+			It("requires the space developer role", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, _, _, roleTypes := fakeCFClient.HasUserAppRoleArgsForCall(0)
+				Expect(roleTypes).To(ConsistOf(cf.RoleSpaceDeveloper))
+			})
+		})
+
 		Context("user is neither admin nor space developer", func() {
 			BeforeEach(func() {
 				fakeCFClient.IsUserAdminReturns(false, nil)
-				fakeCFClient.IsUserSpaceDeveloperReturns(false, nil)
+				fakeCFClient.HasUserAppRoleReturns(false, nil)
 
 				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID, nil)
 				req.Header.Add("Authorization", TEST_USER_TOKEN)
@@ -221,6 +255,366 @@ var _ = Describe("Middleware", func() {
 		})
 	})
 
+	Describe("OauthWithOptionalAppId", func() {
+		BeforeEach(func() {
+			fakeCFClient = &fakes.FakeCFClient{}
+			logger = lagertest.NewTestLogger("oauth")
+			mw = NewMiddleware(logger, fakeCFClient, func(appId string) bool {
+				return true
+			}, "")
+
+			router = mux.NewRouter()
+			router.HandleFunc(routes.PublicApiValidatePolicyPath, GetTestHandler())
+			router.Use(mw.OauthWithOptionalAppId)
+
+			resp = httptest.NewRecorder()
+		})
+
+		JustBeforeEach(func() {
+			router.ServeHTTP(resp, req)
+		})
+
+		When("Authorization header is not preset", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, routes.PublicApiValidatePolicyPath, nil)
+			})
+			It("should fail with 401", func() {
+				CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+					Code:    "Unauthorized",
+					Message: "Authorization header is not present",
+				})
+			})
+		})
+
+		Context("without an app", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, routes.PublicApiValidatePolicyPath, nil)
+				req.Header.Add("Authorization", TEST_USER_TOKEN)
+			})
+
+			Context("user is authenticated", func() {
+				BeforeEach(func() {
+					fakeCFClient.IsUserAuthenticatedReturns(true, nil)
+				})
+				It("should succeed with 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					_, token := fakeCFClient.IsUserAuthenticatedArgsForCall(0)
+					Expect(token).To(Equal(TEST_BEARER_TOKEN))
+					Expect(fakeCFClient.HasUserAppRoleCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("user is not authenticated", func() {
+				BeforeEach(func() {
+					fakeCFClient.IsUserAuthenticatedReturns(false, nil)
+				})
+				It("should fail with 401", func() {
+					CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+						Code:    "Unauthorized",
+						Message: "You are not authorized to perform the requested action",
+					})
+				})
+			})
+
+			Context("authentication check fails", func() {
+				BeforeEach(func() {
+					fakeCFClient.IsUserAuthenticatedReturns(false, fmt.Errorf("failed to introspect token"))
+				})
+				It("should fail with 500", func() {
+					CheckResponse(resp, http.StatusInternalServerError, models.ErrorResponse{
+						Code:    http.StatusText(http.StatusInternalServerError),
+						Message: "Failed to check if user is authenticated",
+					})
+				})
+			})
+		})
+
+		Context("with an app", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, routes.PublicApiValidatePolicyPath+"?app_id="+TEST_APP_ID, nil)
+				req.Header.Add("Authorization", TEST_USER_TOKEN)
+			})
+
+			Context("user may read the app", func() {
+				BeforeEach(func() {
+					fakeCFClient.HasUserAppRoleReturns(true, nil)
+				})
+				It("should succeed with 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					_, _, appId, roleTypes := fakeCFClient.HasUserAppRoleArgsForCall(0)
+					Expect(appId).To(Equal(cf.Guid(TEST_APP_ID)))
+					Expect(roleTypes).To(Equal(cf.AppReaderRoles))
+				})
+			})
+
+			Context("user may not read the app", func() {
+				BeforeEach(func() {
+					fakeCFClient.IsUserAuthenticatedReturns(true, nil)
+					fakeCFClient.HasUserAppRoleReturns(false, nil)
+				})
+				It("should fail with 401", func() {
+					CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+						Code:    "Unauthorized",
+						Message: "You are not authorized to perform the requested action",
+					})
+				})
+			})
+		})
+	})
+
+	Describe("OauthForSpace", func() {
+		BeforeEach(func() {
+			fakeCFClient = &fakes.FakeCFClient{}
+			logger = lagertest.NewTestLogger("oauth")
+			mw = NewMiddleware(logger, fakeCFClient, func(appId string) bool {
+				return true
+			}, "")
+
+			router = mux.NewRouter()
+			router.HandleFunc(routes.PublicApiSpaceAppsPath, GetTestHandler())
+			router.Use(mw.OauthForSpace)
+
+			resp = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/v1/spaces/a-space-id/apps", nil)
+			req.Header.Add("Authorization", TEST_USER_TOKEN)
+		})
+
+		JustBeforeEach(func() {
+			router.ServeHTTP(resp, req)
+		})
+
+		When("Authorization header is not preset", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/spaces/a-space-id/apps", nil)
+			})
+			It("should fail with 401", func() {
+				CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+					Code:    "Unauthorized",
+					Message: "Authorization header is not present",
+				})
+			})
+		})
+
+		Context("user is admin", func() {
+			BeforeEach(func() {
+				fakeCFClient.IsUserAdminReturns(true, nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(fakeCFClient.HasUserSpaceRoleCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("user may read the space", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserSpaceRoleReturns(true, nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, token, spaceId, roleTypes := fakeCFClient.HasUserSpaceRoleArgsForCall(0)
+				Expect(token).To(Equal(TEST_BEARER_TOKEN))
+				Expect(spaceId).To(Equal(cf.SpaceId("a-space-id")))
+				Expect(roleTypes).To(Equal(cf.AppReaderRoles))
+			})
+		})
+
+		Context("user may not read the space", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserSpaceRoleReturns(false, nil)
+			})
+			It("should fail with 401", func() {
+				CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+					Code:    "Unauthorized",
+					Message: "You are not authorized to perform the requested action",
+				})
+			})
+		})
+
+		Context("role check fails", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserSpaceRoleReturns(false, fmt.Errorf("failed to list roles"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("OauthForOrg", func() {
+		BeforeEach(func() {
+			fakeCFClient = &fakes.FakeCFClient{}
+			logger = lagertest.NewTestLogger("oauth")
+			mw = NewMiddleware(logger, fakeCFClient, func(appId string) bool {
+				return true
+			}, "")
+
+			router = mux.NewRouter()
+			router.HandleFunc(routes.PublicApiOrgAppsPath, GetTestHandler())
+			router.Use(mw.OauthForOrg)
+
+			resp = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/v1/organizations/an-org-id/apps", nil)
+			req.Header.Add("Authorization", TEST_USER_TOKEN)
+		})
+
+		JustBeforeEach(func() {
+			router.ServeHTTP(resp, req)
+		})
+
+		When("Authorization header is not preset", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/organizations/an-org-id/apps", nil)
+			})
+			It("should fail with 401", func() {
+				CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+					Code:    "Unauthorized",
+					Message: "Authorization header is not present",
+				})
+			})
+		})
+
+		Context("user is admin", func() {
+			BeforeEach(func() {
+				fakeCFClient.IsUserAdminReturns(true, nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(fakeCFClient.HasUserOrgRoleCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("user may read the organization", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserOrgRoleReturns(true, nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, token, orgId, roleTypes := fakeCFClient.HasUserOrgRoleArgsForCall(0)
+				Expect(token).To(Equal(TEST_BEARER_TOKEN))
+				Expect(orgId).To(Equal(cf.OrgId("an-org-id")))
+				Expect(roleTypes).To(Equal(cf.OrgAppReaderRoles))
+			})
+		})
+
+		Context("user may not read the organization", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserOrgRoleReturns(false, nil)
+			})
+			It("should fail with 401", func() {
+				CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+					Code:    "Unauthorized",
+					Message: "You are not authorized to perform the requested action",
+				})
+			})
+		})
+
+		Context("role check fails", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserOrgRoleReturns(false, fmt.Errorf("failed to list roles"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("OauthForServiceInstance", func() {
+		var fakeBindingDB *fakes.FakeBindingDB
+
+		BeforeEach(func() {
+			fakeCFClient = &fakes.FakeCFClient{}
+			fakeBindingDB = &fakes.FakeBindingDB{}
+			fakeBindingDB.GetServiceInstanceReturns(&models.ServiceInstance{ServiceInstanceId: "an-instance-id", SpaceId: "a-space-id"}, nil)
+			logger = lagertest.NewTestLogger("oauth")
+			mw = NewMiddleware(logger, fakeCFClient, func(appId string) bool {
+				return true
+			}, "")
+
+			router = mux.NewRouter()
+			router.HandleFunc(routes.PublicApiDefaultPolicyPath, GetTestHandler())
+			router.Use(mw.OauthForServiceInstance(fakeBindingDB))
+
+			resp = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPut, "/v1/service_instances/an-instance-id/default_policy", nil)
+			req.Header.Add("Authorization", TEST_USER_TOKEN)
+		})
+
+		JustBeforeEach(func() {
+			router.ServeHTTP(resp, req)
+		})
+
+		When("Authorization header is not preset", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPut, "/v1/service_instances/an-instance-id/default_policy", nil)
+			})
+			It("should fail with 401", func() {
+				CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+					Code:    "Unauthorized",
+					Message: "Authorization header is not present",
+				})
+			})
+		})
+
+		Context("service instance does not exist", func() {
+			BeforeEach(func() {
+				fakeBindingDB.GetServiceInstanceReturns(nil, db.ErrDoesNotExist)
+			})
+			It("should fail with 404", func() {
+				CheckResponse(resp, http.StatusNotFound, models.ErrorResponse{
+					Code:    "Service instance not found",
+					Message: "The service instance guid supplied does not exist",
+				})
+				Expect(fakeCFClient.IsUserAdminCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("service instance can not be retrieved", func() {
+			BeforeEach(func() {
+				fakeBindingDB.GetServiceInstanceReturns(nil, fmt.Errorf("database error"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		Context("user is admin", func() {
+			BeforeEach(func() {
+				fakeCFClient.IsUserAdminReturns(true, nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(fakeCFClient.HasUserSpaceRoleCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("user is space developer of the space of the service instance", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserSpaceRoleReturns(true, nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, instanceId := fakeBindingDB.GetServiceInstanceArgsForCall(0)
+				Expect(instanceId).To(Equal("an-instance-id"))
+				_, token, spaceId, roleTypes := fakeCFClient.HasUserSpaceRoleArgsForCall(0)
+				Expect(token).To(Equal(TEST_BEARER_TOKEN))
+				Expect(spaceId).To(Equal(cf.SpaceId("a-space-id")))
+				Expect(roleTypes).To(Equal([]cf.RoleType{cf.RoleSpaceDeveloper}))
+			})
+		})
+
+		Context("user is not space developer of the space of the service instance", func() {
+			BeforeEach(func() {
+				fakeCFClient.HasUserSpaceRoleReturns(false, nil)
+			})
+			It("should fail with 401", func() {
+				CheckResponse(resp, http.StatusUnauthorized, models.ErrorResponse{
+					Code:    "Unauthorized",
+					Message: "You are not authorized to perform the requested action",
+				})
+			})
+		})
+	})
+
 	Describe("CheckBinding", func() {
 
 		JustBeforeEach(func() {
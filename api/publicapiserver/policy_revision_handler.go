@@ -0,0 +1,180 @@
+package publicapiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+func (h *PublicApiHandler) GetPolicyRevisions(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetPolicyRevisions", lager.Data{"appId": appId})
+	logger.Info("Get PolicyRevisions")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	parameters, err := parsePageParameters(r)
+	if err != nil {
+		logger.Error("Bad Request", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	revisions, err := h.policydb.RetrievePolicyRevisions(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve policy revisions from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revisions")
+		return
+	}
+
+	revisionsJson, err := json.Marshal(revisions)
+	if err != nil {
+		logger.Error("Failed to marshal policy revisions", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revisions")
+		return
+	}
+	result, err := paginateResource(revisionsJson, parameters, r.URL)
+	if err != nil {
+		logger.Error("Failed to paginate policy revisions", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revisions")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+func (h *PublicApiHandler) GetPolicyRevisionDiff(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetPolicyRevisionDiff", lager.Data{"appId": appId})
+	logger.Info("Get PolicyRevisionDiff")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil || from <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "from must be a positive integer")
+		return
+	}
+	to := 0
+	if toParameter := r.URL.Query().Get("to"); toParameter != "" {
+		to, err = strconv.Atoi(toParameter)
+		if err != nil || to <= 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "to must be a positive integer")
+			return
+		}
+	}
+
+	fromRevision, err := h.policydb.GetPolicyRevision(r.Context(), appId, from)
+	if err != nil {
+		logger.Error("Failed to retrieve policy revision from database", err, lager.Data{"revision": from})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revision")
+		return
+	}
+
+	var toRevision *models.PolicyRevision
+	if to == 0 {
+		// compare with the latest revision
+		revisions, err := h.policydb.RetrievePolicyRevisions(r.Context(), appId)
+		if err != nil {
+			logger.Error("Failed to retrieve policy revisions from database", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revision")
+			return
+		}
+		if len(revisions) > 0 {
+			toRevision = revisions[0]
+		}
+	} else {
+		toRevision, err = h.policydb.GetPolicyRevision(r.Context(), appId, to)
+		if err != nil {
+			logger.Error("Failed to retrieve policy revision from database", err, lager.Data{"revision": to})
+			writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revision")
+			return
+		}
+	}
+
+	if fromRevision == nil || toRevision == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Policy revision not found")
+		return
+	}
+
+	changes, err := models.DiffPolicies(fromRevision.Policy, toRevision.Policy)
+	if err != nil {
+		logger.Error("Failed to diff policy revisions", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error comparing policy revisions")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, models.PolicyRevisionDiff{
+		From:    fromRevision.Revision,
+		To:      toRevision.Revision,
+		Changes: changes,
+	})
+}
+
+// RollbackPolicy attaches a previous revision of the policy of an app again. The revision is validated like a
+// newly attached policy and becomes the latest revision.
+func (h *PublicApiHandler) RollbackPolicy(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("RollbackPolicy", lager.Data{"appId": appId, "revision": vars["revision"]})
+	logger.Info("Rollback Policy")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	revisionNumber, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "revision must be an integer")
+		return
+	}
+
+	revision, err := h.policydb.GetPolicyRevision(r.Context(), appId, revisionNumber)
+	if err != nil {
+		logger.Error("Failed to retrieve policy revision from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revision")
+		return
+	}
+	if revision == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Policy revision not found")
+		return
+	}
+
+	policyBytes, err := json.Marshal(revision.Policy)
+	if err != nil {
+		logger.Error("Failed to marshal policy revision", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revision")
+		return
+	}
+
+	// the limits may have changed since the revision has been saved
+	scalingPolicy, errResults := h.policyValidator.ParseAndValidatePolicy(policyBytes)
+	if errResults != nil {
+		logger.Info("Failed to validate policy revision", lager.Data{"errResults": errResults, "policy": string(policyBytes)})
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, errResults)
+		return
+	}
+
+	if err := h.checkSourceApps(w, r, logger, appId, scalingPolicy.GetPolicyDefinition()); err != nil {
+		return
+	}
+
+	change := models.PolicyChange{Author: requestAuthor(r), Source: models.PolicySourceRollback}
+	policyGuid, err := h.saveAndSyncPolicy(w, r, logger, appId, scalingPolicy.GetPolicyDefinition(), "", change)
+	if err != nil {
+		return
+	}
+
+	w.Header().Set("ETag", policyETag(policyGuid))
+	handlers.WriteJSONResponse(w, http.StatusOK, scalingPolicy.GetPolicyDefinition())
+}
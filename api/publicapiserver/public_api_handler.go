@@ -1,17 +1,25 @@
 package publicapiserver
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/broker"
+	brParser "code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/broker/binding_request_parser"
+	brParserTypes "code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/broker/binding_request_parser/types"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/config"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/plancheck"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/policyvalidator"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/schedulerclient"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cred_helper"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
@@ -22,39 +30,75 @@ import (
 	"github.com/google/uuid"
 )
 
+// PolicyValidationResult is the result of validating a policy without attaching it.
+type PolicyValidationResult struct {
+	Valid    bool                             `json:"valid"`
+	Errors   policyvalidator.ValidationErrors `json:"errors"`
+	Warnings policyvalidator.ValidationErrors `json:"warnings"`
+}
+
 type PublicApiHandler struct {
 	logger               lager.Logger
 	conf                 *config.Config
 	policydb             db.PolicyDB
 	bindingdb            db.BindingDB
 	eventGeneratorClient *http.Client
+	scalingEngineClient  *http.Client
 	policyValidator      *policyvalidator.PolicyValidator
+	bindingReqParser     brParser.BindRequestParser
 	schedulerUtil        *schedulerclient.Client
+	planChecker          plancheck.PlanChecker
+	cfClient             cf.CFClient
 }
 
 const (
 	ActionWriteBody             = "write-body"
 	ActionCheckAppId            = "check-for-id-appid"
 	ErrorMessageAppidIsRequired = "AppId is required"
+	ErrorMessagePolicyModified  = "Policy has been modified concurrently"
+	ErrorMessageIfMatchFailed   = "Policy does not match If-Match"
+
+	mergePatchMediaType = "application/merge-patch+json"
 )
 
-var ErrInvalidConfigurations = errors.New("invalid binding configurations provided")
+var (
+	ErrInvalidConfigurations = errors.New("invalid binding configurations provided")
+	ErrPolicyModified        = errors.New("policy has been modified concurrently")
+	ErrIfMatchFailed         = errors.New("policy does not match If-Match")
+	ErrSourceAppNotInSpace   = errors.New("source app is not in the same space")
+)
 
-func NewPublicApiHandler(logger lager.Logger, conf *config.Config, policydb db.PolicyDB, bindingdb db.BindingDB, credentials cred_helper.Credentials) *PublicApiHandler {
+func NewPublicApiHandler(logger lager.Logger, conf *config.Config, policydb db.PolicyDB, bindingdb db.BindingDB, credentials cred_helper.Credentials, cfClient cf.CFClient) *PublicApiHandler {
 	egClient, err := helpers.CreateHTTPSClient(&conf.EventGenerator.TLSClientCerts, helpers.DefaultClientConfig(), logger.Session("event_client"))
 	if err != nil {
 		logger.Error("Failed to create http client for EventGenerator", err, lager.Data{"eventgenerator": conf.EventGenerator.TLSClientCerts})
 		os.Exit(1)
 	}
 
+	seClient, err := helpers.CreateHTTPSClient(&conf.ScalingEngine.TLSClientCerts, helpers.DefaultClientConfig(), logger.Session("scaling_client"))
+	if err != nil {
+		logger.Error("Failed to create http client for ScalingEngine", err, lager.Data{"scalingengine": conf.ScalingEngine.TLSClientCerts})
+		os.Exit(1)
+	}
+
+	bindingReqParser, err := broker.NewBindingRequestParser(conf)
+	if err != nil {
+		logger.Error("Failed to create binding request parser", err, lager.Data{"policySchemaPath": conf.BindingRequestSchemaPath})
+		os.Exit(1)
+	}
+
 	return &PublicApiHandler{
 		logger:               logger,
 		conf:                 conf,
 		policydb:             policydb,
 		bindingdb:            bindingdb,
 		eventGeneratorClient: egClient,
+		scalingEngineClient:  seClient,
 		policyValidator:      createPolicyValidator(conf),
+		bindingReqParser:     bindingReqParser,
 		schedulerUtil:        schedulerclient.New(conf, logger),
+		planChecker:          plancheck.NewPlanChecker(conf.PlanCheck, logger),
+		cfClient:             cfClient,
 	}
 }
 
@@ -69,6 +113,7 @@ func createPolicyValidator(conf *config.Config) *policyvalidator.PolicyValidator
 		conf.ScalingRules.DiskUtil.UpperThreshold,
 		conf.ScalingRules.Disk.LowerThreshold,
 		conf.ScalingRules.Disk.UpperThreshold,
+		conf.MetricCollectionInterval,
 	)
 }
 
@@ -89,7 +134,7 @@ func (h *PublicApiHandler) GetScalingPolicy(w http.ResponseWriter, r *http.Reque
 	logger := h.logger.Session("GetScalingPolicy", lager.Data{"appId": appId})
 	logger.Info("Get Scaling Policy")
 
-	policyDef, err := h.policydb.GetAppPolicy(r.Context(), appId)
+	policyDef, policyGuid, err := h.policydb.GetAppPolicyWithGuid(r.Context(), appId)
 	if err != nil {
 		logger.Error("Failed to retrieve scaling policy from database", err)
 		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
@@ -116,6 +161,9 @@ func (h *PublicApiHandler) GetScalingPolicy(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if policyGuid != "" {
+		w.Header().Set("ETag", policyETag(policyGuid))
+	}
 	handlers.WriteJSONResponse(w, http.StatusOK, scalingPolicy)
 }
 
@@ -144,20 +192,101 @@ func (h *PublicApiHandler) AttachScalingPolicy(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	policyGuid := uuid.NewString()
-	if err := h.policydb.SaveAppPolicy(r.Context(),
-		appId, scalingPolicy.GetPolicyDefinition(), policyGuid); err != nil {
-		logger.Error("Failed to save policy", err)
-		writeErrorResponse(w, http.StatusInternalServerError, "Error saving policy")
+	currentPolicyGuid, err := h.checkIfMatch(w, r, logger, appId)
+	if err != nil {
 		return
 	}
 
-	h.logger.Info("creating/updating schedules", lager.Data{"policy": scalingPolicy})
+	h.applyScalingPolicy(w, r, logger, appId, scalingPolicy, currentPolicyGuid)
+}
 
-	if err := h.schedulerUtil.CreateOrUpdateSchedule(r.Context(),
-		appId, scalingPolicy.GetPolicyDefinition(), policyGuid); err != nil {
-		logger.Error("Failed to create/update schedule", err)
-		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+// PatchScalingPolicy updates the policy of an app with a JSON merge patch (RFC 7386), which must be sent with
+// its media type, otherwise the request fails with 415. The merged policy is validated and checked against the
+// service plan like a newly attached one. It is only saved if the policy has not been changed since it has been
+// read, otherwise the request fails with 409, or with 412 if the request has an If-Match header.
+func (h *PublicApiHandler) PatchScalingPolicy(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	logger := h.logger.Session("PatchScalingPolicy", lager.Data{"appId": appId})
+	logger.Info("Patch Scaling Policy")
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != mergePatchMediaType {
+		logger.Info("unsupported content type", lager.Data{"contentType": r.Header.Get("Content-Type")})
+		w.Header().Set("Accept-Patch", mergePatchMediaType)
+		writeErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be "+mergePatchMediaType)
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("Failed to read request body", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to read request body")
+		return
+	}
+
+	policyDef, policyGuid, err := h.policydb.GetAppPolicyWithGuid(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve scaling policy from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
+		return
+	}
+	if !ifMatch(r, policyGuid, policyDef != nil) {
+		logger.Info("policy does not match If-Match", lager.Data{"policyGuid": policyGuid})
+		writeErrorResponse(w, http.StatusPreconditionFailed, ErrorMessageIfMatchFailed)
+		return
+	}
+	if policyDef == nil {
+		logger.Info("policy doesn't exist")
+		writeErrorResponse(w, http.StatusNotFound, "Policy Not Found")
+		return
+	}
+
+	customMetricStrategy, err := h.bindingdb.GetCustomMetricStrategyByAppId(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve customMetricStrategy from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving binding policy")
+		return
+	}
+
+	policyJson, err := models.NewScalingPolicy(customMetricStrategy, policyDef).ToRawJSON()
+	if err != nil {
+		logger.Error("Failed to convert scaling policy to raw JSON", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
+		return
+	}
+
+	mergedPolicy, err := models.ApplyMergePatch(policyJson, patch)
+	if err != nil {
+		logger.Info("Failed to apply merge patch", lager.Data{"error": err.Error(), "patch": string(patch)})
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	scalingPolicy, errResults := h.policyValidator.ParseAndValidatePolicy(mergedPolicy)
+	if errResults != nil {
+		logger.Info("Failed to validate policy", lager.Data{"errResults": errResults, "policy": string(mergedPolicy)})
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, errResults)
+		return
+	}
+
+	h.applyScalingPolicy(w, r, logger, appId, scalingPolicy, policyGuid)
+}
+
+// applyScalingPolicy saves a validated policy, including its custom metrics strategy, and responds with it. The
+// policy replaces the current one unconditionally if currentPolicyGuid is empty.
+func (h *PublicApiHandler) applyScalingPolicy(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string, scalingPolicy *models.ScalingPolicy, currentPolicyGuid string) {
+	if err := h.checkSourceApps(w, r, logger, appId, scalingPolicy.GetPolicyDefinition()); err != nil {
+		return
+	}
+
+	change := models.PolicyChange{Author: requestAuthor(r), Source: models.PolicySourcePublicAPI}
+	policyGuid, err := h.saveAndSyncPolicy(w, r, logger, appId, scalingPolicy.GetPolicyDefinition(), currentPolicyGuid, change)
+	if err != nil {
 		return
 	}
 
@@ -172,17 +301,292 @@ func (h *PublicApiHandler) AttachScalingPolicy(w http.ResponseWriter, r *http.Re
 	}
 
 	responseJson, err := scalingPolicy.ToRawJSON()
+	if err == nil {
+		responseJson, err = withPolicyWarnings(responseJson, h.policyValidator.Lint(scalingPolicy.GetPolicyDefinition()))
+	}
 	if err != nil {
 		logger.Error("Failed to to build response", err)
 		writeErrorResponse(w, http.StatusInternalServerError, "Error building response")
 		return
 	}
+	w.Header().Set("ETag", policyETag(policyGuid))
 	_, err = w.Write(responseJson) // #nosec G705 -- JSON marshaled from struct, not user input
 	if err != nil {
 		h.logger.Error("Failed to write body", err)
 	}
 }
 
+// withPolicyWarnings adds the lint warnings of an attached policy to its JSON representation. The policy is
+// returned unchanged if there are no warnings.
+func withPolicyWarnings(policyJson json.RawMessage, warnings policyvalidator.ValidationErrors) (json.RawMessage, error) {
+	if len(warnings) == 0 {
+		return policyJson, nil
+	}
+
+	response := map[string]json.RawMessage{}
+	if err := json.Unmarshal(policyJson, &response); err != nil {
+		return nil, err
+	}
+	warningsJson, err := json.Marshal(warnings)
+	if err != nil {
+		return nil, err
+	}
+	response["warnings"] = warningsJson
+	return json.Marshal(response)
+}
+
+// saveAndSyncPolicy saves the policy of an app under a new policy guid, which it returns, and creates or updates its
+// schedules. If currentPolicyGuid is not empty, the policy is only saved if it is still the guid of the app's policy.
+// The policy must adhere to the service plan of the app.
+func (h *PublicApiHandler) saveAndSyncPolicy(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string, policy *models.PolicyDefinition, currentPolicyGuid string, change models.PolicyChange) (string, error) {
+	if err := h.checkPlan(w, r, logger, appId, policy); err != nil {
+		return "", err
+	}
+
+	policyGuid := uuid.NewString()
+	if currentPolicyGuid == "" {
+		if err := h.policydb.SaveAppPolicy(r.Context(), appId, policy, policyGuid, change); err != nil {
+			logger.Error("Failed to save policy", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error saving policy")
+			return "", err
+		}
+	} else {
+		saved, err := h.policydb.CompareAndSwapAppPolicy(r.Context(), appId, currentPolicyGuid, policy, policyGuid, change)
+		if err != nil {
+			logger.Error("Failed to save policy", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error saving policy")
+			return "", err
+		}
+		if !saved {
+			logger.Info("policy has been modified concurrently", lager.Data{"policyGuid": currentPolicyGuid})
+			if r.Header.Get("If-Match") != "" {
+				writeErrorResponse(w, http.StatusPreconditionFailed, ErrorMessageIfMatchFailed)
+				return "", ErrIfMatchFailed
+			}
+			writeErrorResponse(w, http.StatusConflict, ErrorMessagePolicyModified)
+			return "", ErrPolicyModified
+		}
+	}
+
+	logger.Info("creating/updating schedules", lager.Data{"policy": policy})
+
+	if err := h.schedulerUtil.CreateOrUpdateSchedule(r.Context(), appId, policy, policyGuid); err != nil {
+		logger.Error("Failed to create/update schedule", err)
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return "", err
+	}
+	return policyGuid, nil
+}
+
+// checkIfMatch evaluates the If-Match header of a request against the current policy of an app. It returns the
+// guid of the current policy, which the request has to be applied to, or an empty guid if there is no If-Match.
+func (h *PublicApiHandler) checkIfMatch(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string) (string, error) {
+	if r.Header.Get("If-Match") == "" {
+		return "", nil
+	}
+
+	policyDef, policyGuid, err := h.policydb.GetAppPolicyWithGuid(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve scaling policy from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
+		return "", err
+	}
+	if !ifMatch(r, policyGuid, policyDef != nil) {
+		logger.Info("policy does not match If-Match", lager.Data{"policyGuid": policyGuid})
+		writeErrorResponse(w, http.StatusPreconditionFailed, ErrorMessageIfMatchFailed)
+		return "", ErrIfMatchFailed
+	}
+	return policyGuid, nil
+}
+
+// checkPlan checks the policy of an app against the service plan of the service instance the app is bound to.
+func (h *PublicApiHandler) checkPlan(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string, policy *models.PolicyDefinition) error {
+	if h.conf.PlanCheck == nil {
+		return nil
+	}
+
+	planId, err := h.getServicePlanId(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to determine the service plan", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error determining the service plan")
+		return err
+	}
+	return h.checkPolicyAdheresToPlan(w, logger, planId, policy)
+}
+
+// checkSourceApps checks that the apps whose metrics the scaling rules of the policy of an app use are in the space
+// of the app and that the user of the request is allowed to read them. Otherwise, it responds with an error.
+func (h *PublicApiHandler) checkSourceApps(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string, policy *models.PolicyDefinition) error {
+	sourceAppIds := policy.SourceAppIds(appId)
+	if len(sourceAppIds) == 0 {
+		return nil
+	}
+
+	app, err := h.cfClient.GetApp(r.Context(), cf.Guid(appId))
+	if err != nil {
+		logger.Error("Failed to retrieve app", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving app")
+		return err
+	}
+	if app.Relationships.Space == nil {
+		err := fmt.Errorf("cloudcontroller has no space-relationship for app %s", appId)
+		logger.Error("Failed to determine the space of the app", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving app")
+		return err
+	}
+	return h.checkSourceAppsInSpace(w, r, logger, string(app.Relationships.Space.Data.Guid), sourceAppIds)
+}
+
+// checkSourceAppsInSpace checks that the source apps are in the space with the id spaceId and that the user of the
+// request is allowed to read them. Otherwise, it responds with an error.
+func (h *PublicApiHandler) checkSourceAppsInSpace(w http.ResponseWriter, r *http.Request, logger lager.Logger, spaceId string, sourceAppIds []string) error {
+	for _, sourceAppId := range sourceAppIds {
+		sourceApp, err := h.cfClient.GetApp(r.Context(), cf.Guid(sourceAppId))
+		switch {
+		case cf.IsNotFound(err):
+			writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("The source app %s does not exist", sourceAppId))
+			return err
+		case err != nil:
+			logger.Error("Failed to retrieve source app", err, lager.Data{"sourceAppId": sourceAppId})
+			writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving source app")
+			return err
+		case sourceApp.Relationships.Space == nil || string(sourceApp.Relationships.Space.Data.Guid) != spaceId:
+			logger.Info("source app is not in the space", lager.Data{"sourceAppId": sourceAppId, "spaceId": spaceId})
+			writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("The source app %s is not in the same space", sourceAppId))
+			return ErrSourceAppNotInSpace
+		}
+
+		if err := h.checkUserAppRole(w, r, logger, sourceAppId, "read", cf.AppReaderRoles...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPolicyAdheresToPlan checks a policy against the service plan with the id planId in the broker catalog.
+func (h *PublicApiHandler) checkPolicyAdheresToPlan(w http.ResponseWriter, logger lager.Logger, planId string, policy *models.PolicyDefinition) error {
+	ok, checkResult, err := h.planChecker.CheckPlan(policy, planId)
+	if err != nil {
+		logger.Error("Failed to check policy for plan adherence", err, lager.Data{"planId": planId})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error validating policy")
+		return err
+	}
+	if !ok {
+		logger.Info("policy did not adhere to plan", lager.Data{"planId": planId, "checkResult": checkResult})
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("policy did not adhere to plan: %s", checkResult))
+		return errors.New(checkResult)
+	}
+	return nil
+}
+
+// getServicePlanId returns the id of the service plan in the broker catalog of the service instance the app is
+// bound to.
+func (h *PublicApiHandler) getServicePlanId(ctx context.Context, appId string) (string, error) {
+	serviceInstance, err := h.bindingdb.GetServiceInstanceByAppId(appId)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the service instance of app %s: %w", appId, err)
+	}
+	return h.getServicePlanIdOfInstance(ctx, serviceInstance.ServiceInstanceId)
+}
+
+// getServicePlanIdOfInstance returns the id of the service plan of a service instance in the broker catalog.
+func (h *PublicApiHandler) getServicePlanIdOfInstance(ctx context.Context, serviceInstanceId string) (string, error) {
+	cfServiceInstance, err := h.cfClient.GetServiceInstance(ctx, serviceInstanceId)
+	if err != nil {
+		return "", err
+	}
+	servicePlan, err := h.cfClient.GetServicePlan(ctx, cfServiceInstance.Relationships.ServicePlan.Data.Guid)
+	if err != nil {
+		return "", err
+	}
+	return servicePlan.BrokerCatalog.Id, nil
+}
+
+// ValidateScalingPolicy checks a policy the same way as when it is attached or provided with a binding, without
+// persisting anything. The plan check applies to the service plan given with plan_id or, if app_id is given, to
+// the service plan of the service instance the app is bound to.
+func (h *PublicApiHandler) ValidateScalingPolicy(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	appId := r.URL.Query().Get("app_id")
+	planId := r.URL.Query().Get("plan_id")
+	logger := h.logger.Session("ValidateScalingPolicy", lager.Data{"appId": appId, "planId": planId})
+	logger.Info("Validate Scaling Policy")
+
+	if appId != "" && planId != "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Only one of app_id and plan_id can be given")
+		return
+	}
+
+	policyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("Failed to read request body", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to read request body")
+		return
+	}
+
+	result := PolicyValidationResult{
+		Errors:   policyvalidator.ValidationErrors{},
+		Warnings: policyvalidator.ValidationErrors{},
+	}
+	_, err = h.bindingReqParser.Parse(string(policyBytes), models.GUID(appId))
+	result.Errors = append(result.Errors, bindingRequestErrors(err)...)
+
+	if len(result.Errors) == 0 {
+		scalingPolicy, errResults := h.policyValidator.ParseAndValidatePolicy(policyBytes)
+		result.Errors = append(result.Errors, errResults...)
+		if len(errResults) == 0 {
+			policy := scalingPolicy.GetPolicyDefinition()
+			if planId == "" && appId != "" && h.conf.PlanCheck != nil {
+				planId, err = h.getServicePlanId(r.Context(), appId)
+				if err != nil {
+					logger.Error("Failed to determine the service plan", err)
+					writeErrorResponse(w, http.StatusInternalServerError, "Error determining the service plan")
+					return
+				}
+			}
+			if planId != "" {
+				ok, checkResult, err := h.planChecker.CheckPlan(policy, planId)
+				if err != nil {
+					logger.Info("Failed to check policy for plan adherence", lager.Data{"error": err.Error()})
+					writeErrorResponse(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				if !ok {
+					result.Errors = append(result.Errors, policyvalidator.PolicyValidationErrors{
+						Context:     "(root)",
+						Description: fmt.Sprintf("policy did not adhere to plan: %s", checkResult),
+					})
+				}
+			}
+			result.Warnings = append(result.Warnings, h.policyValidator.Lint(policy)...)
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+	handlers.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// bindingRequestErrors converts the error of parsing a binding-request into validation messages. A missing app is
+// no error when validating a policy without an app.
+func bindingRequestErrors(err error) policyvalidator.ValidationErrors {
+	var jsonSchemaErr *brParserTypes.JsonSchemaError
+	var appGuidErr *brParserTypes.BindReqNoAppGuid
+	switch {
+	case err == nil, errors.As(err, &appGuidErr):
+		return nil
+	case errors.As(err, &jsonSchemaErr):
+		var messages policyvalidator.ValidationErrors
+		for _, schemaErr := range *jsonSchemaErr {
+			messages = append(messages, policyvalidator.PolicyValidationErrors{
+				Context:     schemaErr.Context().String(),
+				Description: schemaErr.Description(),
+			})
+		}
+		return messages
+	default:
+		return policyvalidator.ValidationErrors{{Context: "(root)", Description: err.Error()}}
+	}
+}
+
 func (h *PublicApiHandler) DetachScalingPolicy(w http.ResponseWriter, r *http.Request, vars map[string]string) {
 	appId := vars["appId"]
 	if appId == "" {
@@ -194,7 +598,23 @@ func (h *PublicApiHandler) DetachScalingPolicy(w http.ResponseWriter, r *http.Re
 	logger := h.logger.Session("DetachScalingPolicy", lager.Data{"appId": appId})
 	logger.Info("Deleting policy json", lager.Data{"appId": appId})
 
-	if err := h.policydb.DeletePolicy(r.Context(), appId); err != nil {
+	currentPolicyGuid, err := h.checkIfMatch(w, r, logger, appId)
+	if err != nil {
+		return
+	}
+
+	if currentPolicyGuid == "" {
+		err = h.policydb.DeletePolicy(r.Context(), appId)
+	} else {
+		var deleted bool
+		deleted, err = h.policydb.CompareAndDeleteAppPolicy(r.Context(), appId, currentPolicyGuid)
+		if err == nil && !deleted {
+			logger.Info("policy has been modified concurrently", lager.Data{"policyGuid": currentPolicyGuid})
+			writeErrorResponse(w, http.StatusPreconditionFailed, ErrorMessageIfMatchFailed)
+			return
+		}
+	}
+	if err != nil {
 		logger.Error("Failed to delete policy from database", err)
 		writeErrorResponse(w, http.StatusInternalServerError, "Error deleting policy")
 		return
@@ -222,7 +642,7 @@ func (h *PublicApiHandler) DetachScalingPolicy(w http.ResponseWriter, r *http.Re
 	// find via the app id the binding -> service instance
 	// default policy? then apply that
 	w.WriteHeader(http.StatusOK)
-	_, err := w.Write([]byte("{}"))
+	_, err = w.Write([]byte("{}"))
 	if err != nil {
 		logger.Error(ActionWriteBody, err)
 	}
@@ -255,7 +675,8 @@ func (h *PublicApiHandler) saveDefaultPolicy(w http.ResponseWriter, r *http.Requ
 		return errors.New("default policy not valid")
 	}
 
-	if err := h.policydb.SaveAppPolicy(r.Context(), appId, policy, policyGuidStr); err != nil {
+	change := models.PolicyChange{Author: requestAuthor(r), Source: models.PolicySourceDefaultPolicy}
+	if err := h.policydb.SaveAppPolicy(r.Context(), appId, policy, policyGuidStr, change); err != nil {
 		logger.Error("failed to save policy", err, lager.Data{"policy": policyStr})
 		writeErrorResponse(w, http.StatusInternalServerError, "Error attaching the default policy")
 		return errors.New("error attaching the default policy")
@@ -271,8 +692,24 @@ func (h *PublicApiHandler) saveDefaultPolicy(w http.ResponseWriter, r *http.Requ
 	return nil
 }
 
-func (h *PublicApiHandler) proxyRequest(logger lager.Logger, appId string, metricType string, w http.ResponseWriter, req *http.Request, parameters *url.Values, requestDescription string) {
-	reqUrl := req.URL
+func (h *PublicApiHandler) proxyRequest(logger lager.Logger, routeName string, appId string, metricType string, w http.ResponseWriter, req *http.Request, parameters *url.Values, requestDescription string) {
+	responseData, ok := h.getFromEventGenerator(logger, routeName, appId, metricType, w, parameters, requestDescription)
+	if !ok {
+		return
+	}
+
+	paginatedResponse, err := paginateResource(responseData, parameters, req.URL)
+	if err != nil {
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, paginatedResponse)
+}
+
+// getFromEventGenerator returns the body of a successful response of the eventgenerator. Otherwise, it writes an
+// error response and returns false.
+func (h *PublicApiHandler) getFromEventGenerator(logger lager.Logger, routeName string, appId string, metricType string, w http.ResponseWriter, parameters *url.Values, requestDescription string) ([]byte, bool) {
 	r := routes.NewRouter()
 	router := r.CreateEventGeneratorSubrouter()
 
@@ -280,7 +717,7 @@ func (h *PublicApiHandler) proxyRequest(logger lager.Logger, appId string, metri
 		panic("Failed to create event generator routes")
 	}
 
-	route := router.Get(routes.GetAggregatedMetricHistoriesRouteName)
+	route := router.Get(routeName)
 	path, err := route.URLPath("appid", appId, "metrictype", metricType)
 	if err != nil {
 		logger.Error("Failed to create path", err)
@@ -288,13 +725,13 @@ func (h *PublicApiHandler) proxyRequest(logger lager.Logger, appId string, metri
 	}
 
 	aUrl := h.conf.EventGenerator.EventGeneratorUrl + path.RequestURI() + "?" + parameters.Encode()
-	req, _ = http.NewRequest("GET", aUrl, nil) // #nosec G704 -- URL host from internal config, path from validated route params
+	req, _ := http.NewRequest("GET", aUrl, nil) // #nosec G704 -- URL host from internal config, path from validated route params
 
 	resp, err := h.eventGeneratorClient.Do(req) // #nosec G704 -- URL host from internal config, path from validated route params
 	if err != nil {
 		logger.Error("Failed to retrieve "+requestDescription, err, lager.Data{"url": aUrl})
 		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving "+requestDescription)
-		return
+		return nil, false
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -302,29 +739,70 @@ func (h *PublicApiHandler) proxyRequest(logger lager.Logger, appId string, metri
 	if err != nil {
 		logger.Error("Error occurred during parsing "+requestDescription+" result", err, lager.Data{"url": aUrl})
 		writeErrorResponse(w, http.StatusInternalServerError, "Error parsing "+requestDescription)
-		return
+		return nil, false
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		logger.Error("Error occurred during getting "+requestDescription, nil, lager.Data{"statusCode": resp.StatusCode, "body": string(responseData), "url": aUrl})
 		writeErrorResponse(w, resp.StatusCode, string(responseData))
+		return nil, false
+	}
+
+	return responseData, true
+}
+
+func (h *PublicApiHandler) GetAggregatedMetricsHistories(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	metricType := vars["metricType"]
+	logger := h.logger.Session("GetScalingHistories", lager.Data{"appId": appId, "metricType": metricType})
+	logger.Info("Get AggregatedMetricHistories", lager.Data{"appId": appId, "metricType": metricType})
+
+	parameters, err := parseParameter(req, vars)
+	if err != nil {
+		logger.Error("Bad Request", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if metricType == "" {
+		logger.Error("Bad Request", nil)
+		writeErrorResponse(w, http.StatusBadRequest, "Metrictype is required")
 		return
 	}
 
-	paginatedResponse, err := paginateResource(responseData, parameters, reqUrl)
+	cursorMode, err := addDownsamplingParameters(req, parameters)
 	if err != nil {
-		handlers.WriteJSONResponse(w, http.StatusInternalServerError, err.Error())
+		logger.Error("Bad Request", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !cursorMode {
+		h.proxyRequest(logger, routes.GetAggregatedMetricHistoriesRouteName, appId, metricType, w, req, parameters, "metrics history from eventgenerator")
 		return
 	}
 
-	handlers.WriteJSONResponse(w, resp.StatusCode, paginatedResponse)
+	responseData, ok := h.getFromEventGenerator(logger, routes.GetAggregatedMetricHistoriesRouteName, appId, metricType, w, parameters, "metrics history from eventgenerator")
+	if !ok {
+		return
+	}
+	page := models.AppMetricPage{}
+	if err := json.Unmarshal(responseData, &page); err != nil {
+		logger.Error("Failed to unmarshal metrics history page", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error parsing metrics history from eventgenerator")
+		return
+	}
+	if page.NextCursor != "" {
+		page.NextUrl = getCursorUrl(req.URL, page.NextCursor)
+	}
+	handlers.WriteJSONResponse(w, http.StatusOK, page)
 }
 
-func (h *PublicApiHandler) GetAggregatedMetricsHistories(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+// GetMetricsHistories returns the metrics of the individual instances of an app, which are read from log-cache by the
+// eventgenerator. The instance-index parameter restricts them to a single instance.
+func (h *PublicApiHandler) GetMetricsHistories(w http.ResponseWriter, req *http.Request, vars map[string]string) {
 	appId := vars["appId"]
 	metricType := vars["metricType"]
-	logger := h.logger.Session("GetScalingHistories", lager.Data{"appId": appId, "metricType": metricType})
-	logger.Info("Get AggregatedMetricHistories", lager.Data{"appId": appId, "metricType": metricType})
+	logger := h.logger.Session("GetMetricsHistories", lager.Data{"appId": appId, "metricType": metricType})
+	logger.Info("Get MetricHistories")
 
 	parameters, err := parseParameter(req, vars)
 	if err != nil {
@@ -338,7 +816,173 @@ func (h *PublicApiHandler) GetAggregatedMetricsHistories(w http.ResponseWriter,
 		return
 	}
 
-	h.proxyRequest(logger, appId, metricType, w, req, parameters, "metrics history from eventgenerator")
+	if instanceIndex := req.URL.Query().Get("instance-index"); instanceIndex != "" {
+		index, err := strconv.Atoi(instanceIndex)
+		if err != nil || index < 0 {
+			logger.Error("Bad Request", err)
+			writeErrorResponse(w, http.StatusBadRequest, "instance-index must be a non-negative integer")
+			return
+		}
+		parameters.Add("instance_index", instanceIndex)
+	}
+
+	h.proxyRequest(logger, routes.GetMetricHistoriesRouteName, appId, metricType, w, req, parameters, "instance metrics history from eventgenerator")
+}
+
+func (h *PublicApiHandler) GetInstanceHourUsage(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetInstanceHourUsage", lager.Data{"appId": appId})
+	logger.Info("Get InstanceHourUsage")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	resp, err := h.getFromScalingEngine(req.Context(), routes.GetInstanceHourUsageRouteName, appId, "")
+	if err != nil {
+		logger.Error("Failed to retrieve instance-hour usage from scalingengine", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving instance-hour usage")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		writeErrorResponse(w, http.StatusNotFound, "No instance-hour budget is set for the app")
+		return
+	default:
+		logger.Error("Error occurred during getting instance-hour usage", nil, lager.Data{"statusCode": resp.StatusCode})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving instance-hour usage")
+		return
+	}
+
+	usage := &models.InstanceHourUsage{}
+	if err := json.NewDecoder(resp.Body).Decode(usage); err != nil {
+		logger.Error("Error occurred during parsing instance-hour usage", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error parsing instance-hour usage")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, usage)
+}
+
+func (h *PublicApiHandler) GetScalingAnalytics(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetScalingAnalytics", lager.Data{"appId": appId})
+	logger.Info("Get ScalingAnalytics")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	query := url.Values{}
+	for _, parameter := range []string{"start-time", "end-time"} {
+		if value := req.URL.Query().Get(parameter); value != "" {
+			query.Set(parameter, value)
+		}
+	}
+
+	resp, err := h.getFromScalingEngine(req.Context(), routes.GetScalingAnalyticsRouteName, appId, query.Encode())
+	if err != nil {
+		logger.Error("Failed to retrieve scaling analytics from scalingengine", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling analytics")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		errorResponse := &models.ErrorResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(errorResponse); err != nil {
+			logger.Error("Error occurred during parsing scaling analytics error", err)
+		}
+		writeErrorResponse(w, http.StatusBadRequest, errorResponse.Message)
+		return
+	case http.StatusNotFound:
+		writeErrorResponse(w, http.StatusNotFound, "No policy is attached to the app")
+		return
+	default:
+		logger.Error("Error occurred during getting scaling analytics", nil, lager.Data{"statusCode": resp.StatusCode})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling analytics")
+		return
+	}
+
+	analytics := &models.ScalingAnalytics{}
+	if err := json.NewDecoder(resp.Body).Decode(analytics); err != nil {
+		logger.Error("Error occurred during parsing scaling analytics", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error parsing scaling analytics")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, analytics)
+}
+
+func (h *PublicApiHandler) GetScalingDecision(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	decisionId := vars["decisionId"]
+	logger := h.logger.Session("GetScalingDecision", lager.Data{"appId": appId, "decisionId": decisionId})
+	logger.Info("Get ScalingDecision")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	resp, err := h.getFromScalingEngine(req.Context(), routes.GetScalingDecisionRouteName, appId, "", "decisionid", decisionId)
+	if err != nil {
+		logger.Error("Failed to retrieve scaling decision from scalingengine", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling decision")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		writeErrorResponse(w, http.StatusNotFound, "Scaling decision not found")
+		return
+	default:
+		logger.Error("Error occurred during getting scaling decision", nil, lager.Data{"statusCode": resp.StatusCode})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling decision")
+		return
+	}
+
+	decision := &models.DecisionTrace{}
+	if err := json.NewDecoder(resp.Body).Decode(decision); err != nil {
+		logger.Error("Error occurred during parsing scaling decision", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error parsing scaling decision")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, decision)
+}
+
+// getFromScalingEngine sends a GET request for the given scaling engine route of an app,
+// further route variables are given as name-value pairs.
+func (h *PublicApiHandler) getFromScalingEngine(ctx context.Context, routeName string, appId string, rawQuery string, pairs ...string) (*http.Response, error) {
+	return h.getFromScalingEngineRoute(ctx, routeName, rawQuery, append([]string{"appid", appId}, pairs...)...)
+}
+
+// getFromScalingEngineRoute sends a GET request for the given scaling engine route, whose variables are given as
+// name-value pairs.
+func (h *PublicApiHandler) getFromScalingEngineRoute(ctx context.Context, routeName string, rawQuery string, pairs ...string) (*http.Response, error) {
+	path, err := routes.NewRouter().CreateScalingEngineRoutes().Get(routeName).URLPath(pairs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create path: %w", err)
+	}
+	path.RawQuery = rawQuery
+
+	aUrl := h.conf.ScalingEngine.ScalingEngineUrl + path.RequestURI()
+	seReq, err := http.NewRequestWithContext(ctx, http.MethodGet, aUrl, nil) // #nosec G704 -- URL host from internal config, path from validated route params
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", aUrl, err)
+	}
+
+	return h.scalingEngineClient.Do(seReq) // #nosec G704 -- URL host from internal config, path from validated route params
 }
 
 func (h *PublicApiHandler) GetApiInfo(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
@@ -1,7 +1,10 @@
 package publicapiserver_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,11 +12,18 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/config"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/policyvalidator"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/publicapiserver"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"code.cloudfoundry.org/lager/v3/lagertest"
@@ -160,6 +170,7 @@ var _ = Describe("PublicApiHandler", func() {
 		policydb      *fakes.FakePolicyDB
 		bindingdb     *fakes.FakeBindingDB
 		credentials   *fakes.FakeCredentials
+		cfClient      *fakes.FakeCFClient
 		handler       *PublicApiHandler
 		resp          *httptest.ResponseRecorder
 		req           *http.Request
@@ -170,13 +181,14 @@ var _ = Describe("PublicApiHandler", func() {
 		policydb = &fakes.FakePolicyDB{}
 		credentials = &fakes.FakeCredentials{}
 		bindingdb = &fakes.FakeBindingDB{}
+		cfClient = &fakes.FakeCFClient{}
 		resp = httptest.NewRecorder()
 		req = httptest.NewRequest("GET", "/v1/info", nil)
 		pathVariables = map[string]string{}
 	})
 
 	JustBeforeEach(func() {
-		handler = NewPublicApiHandler(lagertest.NewTestLogger("public_api_handler"), conf, policydb, bindingdb, credentials)
+		handler = NewPublicApiHandler(lagertest.NewTestLogger("public_api_handler"), conf, policydb, bindingdb, credentials, cfClient)
 	})
 
 	Describe("GetInfo", func() {
@@ -217,7 +229,7 @@ var _ = Describe("PublicApiHandler", func() {
 		When("database gives error", func() {
 			BeforeEach(func() {
 				pathVariables["appId"] = TEST_APP_ID
-				policydb.GetAppPolicyReturns(nil, fmt.Errorf("Failed to retrieve policy"))
+				policydb.GetAppPolicyWithGuidReturns(nil, "", fmt.Errorf("Failed to retrieve policy"))
 			})
 			It("should fail with 500", func() {
 				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
@@ -228,7 +240,7 @@ var _ = Describe("PublicApiHandler", func() {
 		When("policy doesn't exist", func() {
 			BeforeEach(func() {
 				pathVariables["appId"] = TEST_APP_ID
-				policydb.GetAppPolicyReturns(nil, nil)
+				policydb.GetAppPolicyWithGuidReturns(nil, "", nil)
 			})
 			It("should fail with 404", func() {
 				Expect(resp.Code).To(Equal(http.StatusNotFound))
@@ -240,7 +252,7 @@ var _ = Describe("PublicApiHandler", func() {
 			BeforeEach(func() {
 				pathVariables["appId"] = TEST_APP_ID
 				bindingdb.GetCustomMetricStrategyByAppIdReturns(models.DefaultCustomMetricsStrategy, nil)
-				policydb.GetAppPolicyReturns(&models.PolicyDefinition{
+				policydb.GetAppPolicyWithGuidReturns(&models.PolicyDefinition{
 					InstanceMax: 5,
 					InstanceMin: 1,
 					ScalingRules: []*models.ScalingRule{
@@ -265,7 +277,7 @@ var _ = Describe("PublicApiHandler", func() {
 							},
 						},
 					},
-				}, nil)
+				}, "a-policy-guid", nil)
 			})
 			It("should succeed", func() {
 				Expect(resp.Code).To(Equal(http.StatusOK))
@@ -275,6 +287,10 @@ var _ = Describe("PublicApiHandler", func() {
 				expectation := `{"instance_min_count":1,"instance_max_count":5,"scaling_rules":[{"metric_type":"memoryused","breach_duration_secs":300,"threshold":30,"operator":"<","cool_down_secs":300,"adjustment":"-1"}],"schedules":{"timezone":"Asia/Kolkata","recurring_schedule":[{"start_time":"10:00","end_time":"18:00","days_of_week":[1,2,3],"instance_min_count":1,"instance_max_count":10,"initial_min_instance_count":5}]}}`
 				Expect(result).To(Equal(expectation))
 			})
+
+			It("returns the policy guid as entity tag", func() {
+				Expect(resp.Header().Get("ETag")).To(Equal(`"a-policy-guid"`))
+			})
 		})
 		Context("and custom metric strategy", func() {
 			When("custom metric strategy retrieval fails", func() {
@@ -381,6 +397,232 @@ var _ = Describe("PublicApiHandler", func() {
 			})
 		})
 
+		When("the policy exceeds the plan of the service instance", func() {
+			BeforeEach(func() {
+				pathVariables["appId"] = TEST_APP_ID
+				req, _ = http.NewRequest(http.MethodPut, "", bytes.NewBufferString(ValidPolicyStr))
+				schedulerStatus = 200
+				planCheck := conf.PlanCheck
+				DeferCleanup(func() { conf.PlanCheck = planCheck })
+				conf.PlanCheck = &config.PlanCheckConfig{PlanDefinitions: map[string]config.PlanDefinition{
+					"a-plan-id": {PlanCheckEnabled: true, SchedulesCount: 1, ScalingRulesCount: 0},
+				}}
+				bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{ServiceInstanceId: "a-service-instance-id"}, nil)
+				cfServiceInstance := &cf.ServiceInstance{Guid: "a-service-instance-id"}
+				cfServiceInstance.Relationships.ServicePlan.Data.Guid = "a-service-plan-guid"
+				cfClient.GetServiceInstanceReturns(cfServiceInstance, nil)
+				cfClient.GetServicePlanReturns(&cf.ServicePlan{Guid: "a-service-plan-guid", BrokerCatalog: cf.BrokerCatalog{Id: "a-plan-id"}}, nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("policy did not adhere to plan: Too many scaling rules"))
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		When("the policy uses the metrics of a source app", func() {
+			const policyWithSourceApp = `{
+				"instance_min_count": 1,
+				"instance_max_count": 5,
+				"scaling_rules": [{
+					"metric_type": "queue_length",
+					"threshold": 100,
+					"operator": ">",
+					"adjustment": "+1",
+					"source_app_id": "source-app-id"
+				}]
+			}`
+			var sourceAppSpace cf.SpaceId
+
+			BeforeEach(func() {
+				pathVariables["appId"] = TEST_APP_ID
+				req, _ = http.NewRequest(http.MethodPut, "", bytes.NewBufferString(policyWithSourceApp))
+				req.Header.Set("Authorization", "Bearer user-token")
+				schedulerStatus = 200
+				sourceAppSpace = "a-space-id"
+				cfClient.GetAppStub = func(_ context.Context, appId cf.Guid) (*cf.App, error) {
+					space := cf.SpaceId("a-space-id")
+					if appId == "source-app-id" {
+						space = sourceAppSpace
+					}
+					return &cf.App{Guid: string(appId), Relationships: cf.Relationships{Space: &cf.Space{Data: cf.SpaceData{Guid: space}}}}, nil
+				}
+				cfClient.HasUserAppRoleReturns(true, nil)
+			})
+
+			It("should succeed when the user may read the source app in the space of the app", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(policyWithSourceApp))
+
+				Expect(cfClient.HasUserAppRoleCallCount()).To(Equal(1))
+				_, userToken, appId, roles := cfClient.HasUserAppRoleArgsForCall(0)
+				Expect(userToken).To(Equal("user-token"))
+				Expect(appId).To(Equal(cf.Guid("source-app-id")))
+				Expect(roles).To(Equal(cf.AppReaderRoles))
+			})
+
+			When("the source app is in another space", func() {
+				BeforeEach(func() {
+					sourceAppSpace = "another-space-id"
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"The source app source-app-id is not in the same space"}`))
+					Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+				})
+			})
+
+			When("the source app does not exist", func() {
+				BeforeEach(func() {
+					cfClient.GetAppStub = func(_ context.Context, appId cf.Guid) (*cf.App, error) {
+						if appId == "source-app-id" {
+							return nil, cf.CfResourceNotFound
+						}
+						return &cf.App{Guid: string(appId), Relationships: cf.Relationships{Space: &cf.Space{Data: cf.SpaceData{Guid: "a-space-id"}}}}, nil
+					}
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"The source app source-app-id does not exist"}`))
+				})
+			})
+
+			When("the user may not read the source app", func() {
+				BeforeEach(func() {
+					cfClient.HasUserAppRoleReturns(false, nil)
+				})
+				It("should fail with 403", func() {
+					Expect(resp.Code).To(Equal(http.StatusForbidden))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Forbidden","message":"You are not authorized to read the app source-app-id"}`))
+					Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		When("the policy is most likely not behaving as intended", func() {
+			const policyWithWarnings = `{
+				"instance_min_count": 1,
+				"instance_max_count": 5,
+				"scaling_rules": [{
+					"metric_type": "memoryutil",
+					"breach_duration_secs": 600,
+					"threshold": 50,
+					"operator": ">",
+					"cool_down_secs": 300,
+					"adjustment": "+1"
+				}, {
+					"metric_type": "memoryutil",
+					"breach_duration_secs": 600,
+					"threshold": 60,
+					"operator": "<",
+					"cool_down_secs": 600,
+					"adjustment": "-1"
+				}]
+			}`
+
+			BeforeEach(func() {
+				pathVariables["appId"] = TEST_APP_ID
+				req, _ = http.NewRequest(http.MethodPut, "", bytes.NewBufferString(policyWithWarnings))
+				schedulerStatus = 200
+			})
+
+			It("should succeed and return the warnings along with the policy", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
+				Expect(resp.Body.String()).To(MatchJSON(`{
+					"instance_min_count": 1,
+					"instance_max_count": 5,
+					"scaling_rules": [{
+						"metric_type": "memoryutil",
+						"breach_duration_secs": 600,
+						"threshold": 50,
+						"operator": ">",
+						"cool_down_secs": 300,
+						"adjustment": "+1"
+					}, {
+						"metric_type": "memoryutil",
+						"breach_duration_secs": 600,
+						"threshold": 60,
+						"operator": "<",
+						"cool_down_secs": 600,
+						"adjustment": "-1"
+					}],
+					"warnings": [{
+						"context": "(root).scaling_rules.1",
+						"description": "scaling_rules[1] scaling in and scaling_rules[0] scaling out can both be breached by the same value of metric_type memoryutil"
+					}, {
+						"context": "(root).scaling_rules.0",
+						"description": "scaling_rules[0] has a cool-down of 300 seconds which is shorter than its breach_duration_secs 600"
+					}]
+				}`))
+			})
+		})
+
+		When("the request has an If-Match header", func() {
+			BeforeEach(func() {
+				pathVariables["appId"] = TEST_APP_ID
+				req, _ = http.NewRequest(http.MethodPut, "", bytes.NewBufferString(ValidPolicyStr))
+				req.Header.Set("If-Match", `"a-policy-guid"`)
+				schedulerStatus = 200
+				policydb.GetAppPolicyWithGuidReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 2}, "a-policy-guid", nil)
+				policydb.CompareAndSwapAppPolicyReturns(true, nil)
+			})
+
+			It("replaces the policy only if it still matches", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+				_, _, expectedPolicyGuid, _, policyGuid, _ := policydb.CompareAndSwapAppPolicyArgsForCall(0)
+				Expect(expectedPolicyGuid).To(Equal("a-policy-guid"))
+				Expect(resp.Header().Get("ETag")).To(Equal(`"` + policyGuid + `"`))
+			})
+
+			When("the entity tag does not match", func() {
+				BeforeEach(func() {
+					req.Header.Set("If-Match", `"another-policy-guid", W/"a-policy-guid"`)
+				})
+
+				It("should fail with 412", func() {
+					Expect(resp.Code).To(Equal(http.StatusPreconditionFailed))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Precondition Failed","message":"Policy does not match If-Match"}`))
+					Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(BeZero())
+				})
+			})
+
+			When("any policy is matched", func() {
+				BeforeEach(func() {
+					req.Header.Set("If-Match", "*")
+				})
+
+				It("replaces the current policy", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(Equal(1))
+				})
+
+				When("there is no policy", func() {
+					BeforeEach(func() {
+						policydb.GetAppPolicyWithGuidReturns(nil, "", nil)
+					})
+
+					It("should fail with 412", func() {
+						Expect(resp.Code).To(Equal(http.StatusPreconditionFailed))
+						Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+					})
+				})
+			})
+
+			When("the policy is modified before it is saved", func() {
+				BeforeEach(func() {
+					policydb.CompareAndSwapAppPolicyReturns(false, nil)
+				})
+
+				It("should fail with 412", func() {
+					Expect(resp.Code).To(Equal(http.StatusPreconditionFailed))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Precondition Failed","message":"Policy does not match If-Match"}`))
+				})
+			})
+		})
+
 		When("providing extra fields", func() {
 			BeforeEach(func() {
 				pathVariables["appId"] = TEST_APP_ID
@@ -463,481 +705,763 @@ var _ = Describe("PublicApiHandler", func() {
 		})
 	})
 
-	Describe("DetachScalingPolicy", func() {
+	Describe("PatchScalingPolicy", func() {
+		var contentType string
+
 		BeforeEach(func() {
-			req, _ = http.NewRequest(http.MethodDelete, "", nil)
+			contentType = "application/merge-patch+json"
 			pathVariables["appId"] = TEST_APP_ID
+			req, _ = http.NewRequest(http.MethodPatch, "", bytes.NewBufferString(`{"instance_max_count": 8}`))
+			schedulerStatus = 200
+			var policy *models.PolicyDefinition
+			Expect(json.Unmarshal([]byte(ValidPolicyStr), &policy)).To(Succeed())
+			policydb.GetAppPolicyWithGuidReturns(policy, "current-policy-guid", nil)
+			policydb.CompareAndSwapAppPolicyReturns(true, nil)
+			bindingdb.GetCustomMetricStrategyByAppIdReturns(models.CustomMetricsSameApp, nil)
 		})
+
 		JustBeforeEach(func() {
-			handler.DetachScalingPolicy(resp, req, pathVariables)
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			handler.PatchScalingPolicy(resp, req, pathVariables)
+		})
+
+		When("the patch is sent as application/json", func() {
+			BeforeEach(func() {
+				contentType = "application/json"
+			})
+
+			It("should fail with 415", func() {
+				Expect(resp.Code).To(Equal(http.StatusUnsupportedMediaType))
+				Expect(resp.Header().Get("Accept-Patch")).To(Equal("application/merge-patch+json"))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Unsupported Media Type","message":"Content-Type must be application/merge-patch+json"}`))
+				Expect(policydb.GetAppPolicyWithGuidCallCount()).To(BeZero())
+			})
+		})
+
+		When("the patch is sent without a content type", func() {
+			BeforeEach(func() {
+				contentType = ""
+			})
+
+			It("should fail with 415", func() {
+				Expect(resp.Code).To(Equal(http.StatusUnsupportedMediaType))
+			})
+		})
+
+		When("the media type has parameters", func() {
+			BeforeEach(func() {
+				contentType = "application/merge-patch+json; charset=utf-8"
+			})
+
+			It("accepts the patch", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+			})
 		})
 
 		When("appId is not present", func() {
 			BeforeEach(func() {
 				delete(pathVariables, "appId")
 			})
+
 			It("should fail with 400", func() {
 				Expect(resp.Code).To(Equal(http.StatusBadRequest))
 				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
 			})
 		})
 
-		When("delete policy errors", func() {
+		When("the patch is valid", func() {
+			It("saves the merged policy if it has not been modified", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				expected := strings.Replace(ValidPolicyStr, `"instance_max_count": 5,`, `"instance_max_count": 8,`, 1)
+				Expect(resp.Body.String()).To(MatchJSON(expected))
+
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+				Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(Equal(1))
+				_, appId, expectedPolicyGuid, policy, policyGuid, change := policydb.CompareAndSwapAppPolicyArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(expectedPolicyGuid).To(Equal("current-policy-guid"))
+				Expect(policyGuid).NotTo(BeEmpty())
+				Expect(policyGuid).NotTo(Equal("current-policy-guid"))
+				Expect(policy.InstanceMax).To(Equal(8))
+				Expect(change.Source).To(Equal(models.PolicySourcePublicAPI))
+
+				Expect(bindingdb.SetOrUpdateCustomMetricStrategyCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the patch removes a member", func() {
 			BeforeEach(func() {
-				policydb.DeletePolicyReturns(fmt.Errorf("Failed to save policy"))
+				req, _ = http.NewRequest(http.MethodPatch, "", bytes.NewBufferString(`{"schedules": null}`))
 			})
-			It("should fail with 500", func() {
-				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
-				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting policy"}`))
+
+			It("saves the policy without it", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, _, _, policy, _, _ := policydb.CompareAndSwapAppPolicyArgsForCall(0)
+				Expect(policy.Schedules).To(BeNil())
 			})
 		})
 
-		When("scheduler returns non 200 and non 204 status code", func() {
+		When("the patch is not JSON", func() {
 			BeforeEach(func() {
-				schedulerStatus = 500
+				req, _ = http.NewRequest(http.MethodPatch, "", bytes.NewBufferString(`{"instance_max_count":`))
 			})
-			It("should fail with 500", func() {
-				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
-				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting schedules"}`))
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("invalid merge patch"))
+				Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(BeZero())
 			})
 		})
 
-		When("scheduler returns 200 status code", func() {
+		When("the merged policy is invalid", func() {
 			BeforeEach(func() {
-				schedulerStatus = 200
+				req, _ = http.NewRequest(http.MethodPatch, "", bytes.NewBufferString(`{"instance_min_count": null}`))
 			})
-			It("should succeed", func() {
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring(`{"context":"(root)","description":"instance_min_count is required"}`))
+				Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		When("the app has no policy", func() {
+			BeforeEach(func() {
+				policydb.GetAppPolicyWithGuidReturns(nil, "", nil)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Policy Not Found"}`))
+			})
+		})
+
+		When("the policy has been modified concurrently", func() {
+			BeforeEach(func() {
+				policydb.CompareAndSwapAppPolicyReturns(false, nil)
+			})
+
+			It("should fail with 409", func() {
+				Expect(resp.Code).To(Equal(http.StatusConflict))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Conflict","message":"Policy has been modified concurrently"}`))
+				Expect(bindingdb.SetOrUpdateCustomMetricStrategyCallCount()).To(BeZero())
+			})
+		})
+
+		When("the request has a matching If-Match header", func() {
+			BeforeEach(func() {
+				req.Header.Set("If-Match", `"current-policy-guid"`)
+			})
+
+			It("saves the merged policy", func() {
 				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(Equal(1))
 			})
 
-			When("the service is offered in brokered mode", func() {
+			When("the policy is modified before it is saved", func() {
 				BeforeEach(func() {
-					bindingdb = &fakes.FakeBindingDB{}
-				})
-				Context("but there is no service instance", func() {
-					BeforeEach(func() {
-						bindingdb.GetServiceInstanceByAppIdReturns(nil, db.ErrDoesNotExist)
-					})
-					It("should error", func() {
-						Expect(resp.Code).To(Equal(http.StatusInternalServerError))
-						Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving service instance"}`))
-					})
-				})
-				Context("and there is a service instance without a default policy", func() {
-					BeforeEach(func() {
-						bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{}, nil)
-					})
-					It("should still succeed", func() {
-						Expect(resp.Code).To(Equal(http.StatusOK))
-					})
+					policydb.CompareAndSwapAppPolicyReturns(false, nil)
 				})
-				Context("and there is a service instance with a default policy", func() {
-					BeforeEach(func() {
-						bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{
-							DefaultPolicy:     ValidPolicyStr,
-							DefaultPolicyGuid: "test-policy-guid",
-						}, nil)
-					})
-					Context("and setting the default policy fails", func() {
-						BeforeEach(func() {
-							policydb.SaveAppPolicyReturns(fmt.Errorf("failed to save new (default) policy"))
-						})
-						It("should error", func() {
-							Expect(resp.Code).To(Equal(http.StatusInternalServerError))
-							Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error attaching the default policy"}`))
-						})
-					})
-					Context("and setting the default policy succeeds", func() {
-						It("should succeed and set the default policy", func() {
-							Expect(resp.Code).To(Equal(http.StatusOK))
-							Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
-							c, a, p, g := policydb.SaveAppPolicyArgsForCall(0)
-							Expect(c).NotTo(BeNil())
-							Expect(a).To(Equal(TEST_APP_ID))
-							Expect(p).To(MatchJSON(ValidPolicyStr))
-							Expect(g).To(Equal("test-policy-guid"))
-						})
-					})
+
+				It("should fail with 412", func() {
+					Expect(resp.Code).To(Equal(http.StatusPreconditionFailed))
 				})
 			})
 		})
 
-		When("scheduler returns 204 status code", func() {
+		When("the request has an If-Match header which does not match", func() {
 			BeforeEach(func() {
-				schedulerStatus = 204
-				bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{}, nil)
+				req.Header.Set("If-Match", `"another-policy-guid"`)
 			})
-			It("should succeed", func() {
-				Expect(resp.Code).To(Equal(http.StatusOK))
+
+			It("should fail with 412", func() {
+				Expect(resp.Code).To(Equal(http.StatusPreconditionFailed))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Precondition Failed","message":"Policy does not match If-Match"}`))
+				Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(BeZero())
 			})
 		})
 
-		Context("Custom Metrics Strategy Submission Configuration", func() {
-			When("delete configuration in db return errors", func() {
+		When("the plan check is configured", func() {
+			BeforeEach(func() {
+				planCheck := conf.PlanCheck
+				DeferCleanup(func() { conf.PlanCheck = planCheck })
+				conf.PlanCheck = &config.PlanCheckConfig{PlanDefinitions: map[string]config.PlanDefinition{
+					"a-plan-id": {PlanCheckEnabled: true, SchedulesCount: 1, ScalingRulesCount: 1},
+				}}
+				bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{ServiceInstanceId: "a-service-instance-id"}, nil)
+				cfServiceInstance := &cf.ServiceInstance{Guid: "a-service-instance-id"}
+				cfServiceInstance.Relationships.ServicePlan.Data.Guid = "a-service-plan-guid"
+				cfClient.GetServiceInstanceReturns(cfServiceInstance, nil)
+				cfClient.GetServicePlanReturns(&cf.ServicePlan{Guid: "a-service-plan-guid", BrokerCatalog: cf.BrokerCatalog{Id: "a-plan-id"}}, nil)
+			})
+
+			It("checks the merged policy against the plan of the service instance", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, serviceInstanceGuid := cfClient.GetServiceInstanceArgsForCall(0)
+				Expect(serviceInstanceGuid).To(Equal("a-service-instance-id"))
+				_, servicePlanGuid := cfClient.GetServicePlanArgsForCall(0)
+				Expect(servicePlanGuid).To(Equal("a-service-plan-guid"))
+			})
+
+			When("the merged policy exceeds the plan", func() {
 				BeforeEach(func() {
-					schedulerStatus = 200
-					bindingdb.SetOrUpdateCustomMetricStrategyReturns(fmt.Errorf("failed to delete custom metric submission strategy in the database"))
+					req, _ = http.NewRequest(http.MethodPatch, "", bytes.NewBufferString(`{"scaling_rules": [
+						{"metric_type": "memoryused", "threshold": 30, "operator": ">", "adjustment": "+1"},
+						{"metric_type": "memoryused", "threshold": 10, "operator": "<", "adjustment": "-1"}
+					]}`))
 				})
-				It("should not succeed and fail with 500", func() {
-					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
-					Expect(resp.Body.String()).To(MatchJSON(`{"code":"Internal Server Error","message":"failed to delete custom metric submission strategy in the database"}`))
+
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(ContainSubstring("policy did not adhere to plan: Too many scaling rules"))
+					Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(BeZero())
 				})
 			})
-			When("binding exist for a valid app", func() {
+
+			When("the service plan cannot be determined", func() {
 				BeforeEach(func() {
-					schedulerStatus = 200
-					bindingdb.SetOrUpdateCustomMetricStrategyReturns(nil)
+					cfClient.GetServiceInstanceReturns(nil, fmt.Errorf("cf error"))
 				})
-				It("delete the custom metric strategy and returns 200", func() {
-					Expect(resp.Code).To(Equal(http.StatusOK))
-					Expect(resp.Body.String()).To(Equal(`{}`))
+
+				It("should fail with 500", func() {
+					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error determining the service plan"}`))
 				})
 			})
 		})
 	})
 
-	Describe("GetAggregatedMetricsHistories", func() {
+	Describe("ValidateScalingPolicy", func() {
+		var result PolicyValidationResult
+
+		BeforeEach(func() {
+			req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate", bytes.NewBufferString(ValidPolicyStr))
+		})
+
 		JustBeforeEach(func() {
-			eventGeneratorResponse = []models.AppMetric{
-				{
-					AppId:      TEST_APP_ID,
-					Timestamp:  100,
-					MetricType: TEST_METRIC_TYPE,
-					Unit:       TEST_METRIC_UNIT,
-					Value:      "200",
-				},
-				{
-					AppId:      TEST_APP_ID,
-					Timestamp:  110,
-					MetricType: TEST_METRIC_TYPE,
-					Unit:       TEST_METRIC_UNIT,
-					Value:      "250",
-				},
-				{
-					AppId:      TEST_APP_ID,
-					Timestamp:  150,
-					MetricType: TEST_METRIC_TYPE,
-					Unit:       TEST_METRIC_UNIT,
-					Value:      "250",
-				},
-				{
-					AppId:      TEST_APP_ID,
-					Timestamp:  170,
-					MetricType: TEST_METRIC_TYPE,
-					Unit:       TEST_METRIC_UNIT,
-					Value:      "200",
-				},
-				{
-					AppId:      TEST_APP_ID,
-					Timestamp:  200,
-					MetricType: TEST_METRIC_TYPE,
-					Unit:       TEST_METRIC_UNIT,
-					Value:      "200",
-				},
+			handler.ValidateScalingPolicy(resp, req, pathVariables)
+			result = PolicyValidationResult{}
+			if resp.Code == http.StatusOK {
+				Expect(json.Unmarshal(resp.Body.Bytes(), &result)).To(Succeed())
 			}
-			handler.GetAggregatedMetricsHistories(resp, req, pathVariables)
 		})
 
-		When("CF_INSTANCE_CERT is not set", func() {
-			BeforeEach(func() {
-				os.Unsetenv("CF_INSTANCE_CERT")
+		When("the policy is valid", func() {
+			It("reports no errors and persists nothing", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"valid": true, "errors": [], "warnings": []}`))
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+				Expect(policydb.CompareAndSwapAppPolicyCallCount()).To(BeZero())
+				Expect(bindingdb.SetOrUpdateCustomMetricStrategyCallCount()).To(BeZero())
 			})
+		})
 
-			When("appId is not present", func() {
-				BeforeEach(func() {
-					pathVariables["metricType"] = TEST_METRIC_TYPE
+		When("the policy violates the schema", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate", bytes.NewBufferString(InvalidPolicyStr))
+			})
 
-					eventGeneratorStatus = http.StatusOK
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("end-time", "300")
-					params.Add("order-direction", "desc")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
+			It("reports all errors", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(result.Valid).To(BeFalse())
+				Expect(result.Errors).To(ContainElement(policyvalidator.PolicyValidationErrors{
+					Context:     "(root)",
+					Description: "instance_min_count is required",
+				}))
+			})
+		})
 
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
-				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"appId is required"}`))
-				})
+		When("the policy has semantic errors", func() {
+			BeforeEach(func() {
+				invalidPolicy := strings.Replace(ValidPolicyStr, `"instance_min_count": 1,`, `"instance_min_count": 6,`, 1)
+				req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate", bytes.NewBufferString(invalidPolicy))
 			})
 
-			When("metricType is not present", func() {
-				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
+			It("reports the errors of the policy validator", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(result.Valid).To(BeFalse())
+				Expect(result.Errors).To(ConsistOf(policyvalidator.PolicyValidationErrors{
+					Context:     "(root).instance_min_count",
+					Description: "instance_min_count 6 is higher than instance_max_count 5",
+				}))
+			})
+		})
 
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("end-time", "300")
-					params.Add("order-direction", "desc")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
+		When("the binding configuration is invalid", func() {
+			BeforeEach(func() {
+				policy := strings.Replace(ValidPolicyStr, `"instance_min_count": 1,`,
+					`"configuration": {"custom_metrics": {"metric_submission_strategy": {"allow_from": "nowhere"}}}, "instance_min_count": 1,`, 1)
+				req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate", bytes.NewBufferString(policy))
+			})
 
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
-				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Metrictype is required"}`))
-				})
+			It("reports the errors of the binding-request parser", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(result.Valid).To(BeFalse())
+				Expect(result.Errors).NotTo(BeEmpty())
 			})
+		})
 
-			When("start-time is not integer", func() {
-				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
+		When("the scale-in and scale-out thresholds overlap", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate", bytes.NewBufferString(`{
+					"instance_min_count": 1,
+					"instance_max_count": 5,
+					"scaling_rules": [
+						{"metric_type": "memoryutil", "threshold": 50, "operator": ">", "adjustment": "+1"},
+						{"metric_type": "memoryutil", "threshold": 60, "operator": "<", "adjustment": "-1"}
+					]
+				}`))
+			})
 
-					params := url.Values{}
-					params.Add("start-time", "not-integer")
-					params.Add("end-time", "300")
-					params.Add("order-direction", "desc")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
+			It("reports a warning", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(result.Valid).To(BeTrue())
+				Expect(result.Warnings).To(ConsistOf(policyvalidator.PolicyValidationErrors{
+					Context:     "(root).scaling_rules.1",
+					Description: "scaling_rules[1] scaling in and scaling_rules[0] scaling out can both be breached by the same value of metric_type memoryutil",
+				}))
+			})
+		})
 
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
-				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"start-time must be an integer"}`))
-				})
+		When("both app_id and plan_id are given", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate?app_id="+TEST_APP_ID+"&plan_id=a-plan-id", bytes.NewBufferString(ValidPolicyStr))
 			})
 
-			When("start-time is not provided", func() {
-				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Only one of app_id and plan_id can be given"}`))
+			})
+		})
 
-					params := url.Values{}
-					params.Add("end-time", "300")
-					params.Add("order-direction", "desc")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
+		When("the plan check is configured", func() {
+			BeforeEach(func() {
+				planCheck := conf.PlanCheck
+				DeferCleanup(func() { conf.PlanCheck = planCheck })
+				conf.PlanCheck = &config.PlanCheckConfig{PlanDefinitions: map[string]config.PlanDefinition{
+					"a-plan-id": {PlanCheckEnabled: true, SchedulesCount: 0, ScalingRulesCount: 1},
+				}}
+			})
 
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+			When("the policy is validated for a plan", func() {
+				BeforeEach(func() {
+					req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate?plan_id=a-plan-id", bytes.NewBufferString(ValidPolicyStr))
 				})
-				It("should succeed with 200", func() {
+
+				It("reports that the policy exceeds the plan", func() {
 					Expect(resp.Code).To(Equal(http.StatusOK))
+					Expect(result.Valid).To(BeFalse())
+					Expect(result.Errors).To(HaveLen(1))
+					Expect(result.Errors[0].Description).To(HavePrefix("policy did not adhere to plan: Too many schedules"))
 				})
 			})
 
-			When("end-time is not integer", func() {
+			When("the plan is unknown", func() {
 				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
-
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("end-time", "not-integer")
-					params.Add("order-direction", "desc")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
-
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+					req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate?plan_id=unknown-plan-id", bytes.NewBufferString(ValidPolicyStr))
 				})
+
 				It("should fail with 400", func() {
 					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"end-time must be an integer"}`))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"unknown plan id \"unknown-plan-id\""}`))
 				})
 			})
 
-			When("end-time is not provided", func() {
+			When("the policy is validated for an app", func() {
 				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
-
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("order-direction", "desc")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
-
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+					req = httptest.NewRequest(http.MethodPost, "/v1/policy/validate?app_id="+TEST_APP_ID, bytes.NewBufferString(ValidPolicyStr))
+					bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{ServiceInstanceId: "a-service-instance-id"}, nil)
+					cfServiceInstance := &cf.ServiceInstance{Guid: "a-service-instance-id"}
+					cfServiceInstance.Relationships.ServicePlan.Data.Guid = "a-service-plan-guid"
+					cfClient.GetServiceInstanceReturns(cfServiceInstance, nil)
+					cfClient.GetServicePlanReturns(&cf.ServicePlan{Guid: "a-service-plan-guid", BrokerCatalog: cf.BrokerCatalog{Id: "a-plan-id"}}, nil)
 				})
-				It("should succeed with 200", func() {
+
+				It("checks the policy against the plan of the service instance the app is bound to", func() {
 					Expect(resp.Code).To(Equal(http.StatusOK))
+					Expect(result.Valid).To(BeFalse())
+					Expect(bindingdb.GetServiceInstanceByAppIdArgsForCall(0)).To(Equal(TEST_APP_ID))
+					Expect(result.Errors[0].Description).To(HavePrefix("policy did not adhere to plan"))
 				})
 			})
+		})
+	})
 
-			When("order-direction is not provided", func() {
-				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
-
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("end-time", "300")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
+	Describe("DetachScalingPolicy", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest(http.MethodDelete, "", nil)
+			pathVariables["appId"] = TEST_APP_ID
+		})
+		JustBeforeEach(func() {
+			handler.DetachScalingPolicy(resp, req, pathVariables)
+		})
 
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
-				})
-				It("should succeed with 200", func() {
-					Expect(resp.Code).To(Equal(http.StatusOK))
-				})
+		When("appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
 			})
+		})
 
-			When("order-direction is not desc or asc", func() {
-				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
+		When("delete policy errors", func() {
+			BeforeEach(func() {
+				policydb.DeletePolicyReturns(fmt.Errorf("Failed to save policy"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting policy"}`))
+			})
+		})
 
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("end-time", "300")
-					params.Add("order-direction", "not-asc-desc")
-					params.Add("page", "1")
-					params.Add("results-per-page", "2")
+		When("the request has an If-Match header", func() {
+			BeforeEach(func() {
+				schedulerStatus = 200
+				bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{}, nil)
+				req.Header.Set("If-Match", `"a-policy-guid"`)
+				policydb.GetAppPolicyWithGuidReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 2}, "a-policy-guid", nil)
+				policydb.CompareAndDeleteAppPolicyReturns(true, nil)
+			})
 
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
-				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"order-direction must be DESC or ASC"}`))
-				})
+			It("deletes the policy only if it still matches", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.DeletePolicyCallCount()).To(BeZero())
+				_, appId, expectedPolicyGuid := policydb.CompareAndDeleteAppPolicyArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(expectedPolicyGuid).To(Equal("a-policy-guid"))
 			})
 
-			When("page is not integer", func() {
+			When("the entity tag does not match", func() {
 				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
-
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("end-time", "300")
-					params.Add("order-direction", "desc")
-					params.Add("page", "not-integer")
-					params.Add("results-per-page", "2")
-
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+					req.Header.Set("If-Match", `"another-policy-guid"`)
 				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"page must be an integer"}`))
+
+				It("should fail with 412", func() {
+					Expect(resp.Code).To(Equal(http.StatusPreconditionFailed))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Precondition Failed","message":"Policy does not match If-Match"}`))
+					Expect(policydb.CompareAndDeleteAppPolicyCallCount()).To(BeZero())
 				})
 			})
 
-			When("page is not provided", func() {
+			When("the policy is modified before it is deleted", func() {
 				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
-
-					params := url.Values{}
-					params.Add("start-time", "100")
-					params.Add("end-time", "300")
-					params.Add("order-direction", "desc")
-					params.Add("results-per-page", "2")
-
-					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+					policydb.CompareAndDeleteAppPolicyReturns(false, nil)
 				})
-				It("should succeed with 200", func() {
-					Expect(resp.Code).To(Equal(http.StatusOK))
+
+				It("should fail with 412", func() {
+					Expect(resp.Code).To(Equal(http.StatusPreconditionFailed))
+					Expect(bindingdb.SetOrUpdateCustomMetricStrategyCallCount()).To(BeZero())
 				})
 			})
+		})
 
-			When("results-per-page is not integer", func() {
-				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusOK
-					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
+		When("scheduler returns non 200 and non 204 status code", func() {
+			BeforeEach(func() {
+				schedulerStatus = 500
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting schedules"}`))
+			})
+		})
+
+		When("scheduler returns 200 status code", func() {
+			BeforeEach(func() {
+				schedulerStatus = 200
+			})
+			It("should succeed", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+			})
+
+			When("the service is offered in brokered mode", func() {
+				BeforeEach(func() {
+					bindingdb = &fakes.FakeBindingDB{}
+				})
+				Context("but there is no service instance", func() {
+					BeforeEach(func() {
+						bindingdb.GetServiceInstanceByAppIdReturns(nil, db.ErrDoesNotExist)
+					})
+					It("should error", func() {
+						Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+						Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving service instance"}`))
+					})
+				})
+				Context("and there is a service instance without a default policy", func() {
+					BeforeEach(func() {
+						bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{}, nil)
+					})
+					It("should still succeed", func() {
+						Expect(resp.Code).To(Equal(http.StatusOK))
+					})
+				})
+				Context("and there is a service instance with a default policy", func() {
+					BeforeEach(func() {
+						bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{
+							DefaultPolicy:     ValidPolicyStr,
+							DefaultPolicyGuid: "test-policy-guid",
+						}, nil)
+					})
+					Context("and setting the default policy fails", func() {
+						BeforeEach(func() {
+							policydb.SaveAppPolicyReturns(fmt.Errorf("failed to save new (default) policy"))
+						})
+						It("should error", func() {
+							Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+							Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error attaching the default policy"}`))
+						})
+					})
+					Context("and setting the default policy succeeds", func() {
+						It("should succeed and set the default policy", func() {
+							Expect(resp.Code).To(Equal(http.StatusOK))
+							Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
+							c, a, p, g, change := policydb.SaveAppPolicyArgsForCall(0)
+							Expect(c).NotTo(BeNil())
+							Expect(change.Source).To(Equal(models.PolicySourceDefaultPolicy))
+							Expect(a).To(Equal(TEST_APP_ID))
+							Expect(p).To(MatchJSON(ValidPolicyStr))
+							Expect(g).To(Equal("test-policy-guid"))
+						})
+					})
+				})
+			})
+		})
+
+		When("scheduler returns 204 status code", func() {
+			BeforeEach(func() {
+				schedulerStatus = 204
+				bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{}, nil)
+			})
+			It("should succeed", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("Custom Metrics Strategy Submission Configuration", func() {
+			When("delete configuration in db return errors", func() {
+				BeforeEach(func() {
+					schedulerStatus = 200
+					bindingdb.SetOrUpdateCustomMetricStrategyReturns(fmt.Errorf("failed to delete custom metric submission strategy in the database"))
+				})
+				It("should not succeed and fail with 500", func() {
+					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+					Expect(resp.Body.String()).To(MatchJSON(`{"code":"Internal Server Error","message":"failed to delete custom metric submission strategy in the database"}`))
+				})
+			})
+			When("binding exist for a valid app", func() {
+				BeforeEach(func() {
+					schedulerStatus = 200
+					bindingdb.SetOrUpdateCustomMetricStrategyReturns(nil)
+				})
+				It("delete the custom metric strategy and returns 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					Expect(resp.Body.String()).To(Equal(`{}`))
+				})
+			})
+		})
+	})
+
+	Describe("GetMetricsHistories", func() {
+		var (
+			instanceMetrics []models.AppInstanceMetric
+			eventGenQuery   url.Values
+		)
+
+		BeforeEach(func() {
+			instanceMetrics = []models.AppInstanceMetric{
+				{AppId: TEST_APP_ID, InstanceIndex: 1, Name: TEST_METRIC_TYPE, Unit: TEST_METRIC_UNIT, Value: "300", Timestamp: 100},
+				{AppId: TEST_APP_ID, InstanceIndex: 1, Name: TEST_METRIC_TYPE, Unit: TEST_METRIC_UNIT, Value: "350", Timestamp: 110},
+				{AppId: TEST_APP_ID, InstanceIndex: 1, Name: TEST_METRIC_TYPE, Unit: TEST_METRIC_UNIT, Value: "400", Timestamp: 120},
+			}
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["metricType"] = TEST_METRIC_TYPE
+			eventGenQuery = nil
+			instanceMetricsMatcher := regexp.MustCompile(`/v1/apps/[A-Za-z0-9\-]+/metric_histories/[a-zA-Z0-9_]+`)
+			eventGeneratorServer.RouteToHandler(http.MethodGet, instanceMetricsMatcher, func(w http.ResponseWriter, r *http.Request) {
+				eventGenQuery = r.URL.Query()
+				ghttp.RespondWithJSONEncoded(http.StatusOK, instanceMetrics)(w, r)
+			})
+		})
+
+		JustBeforeEach(func() {
+			handler.GetMetricsHistories(resp, req, pathVariables)
+		})
+
+		When("an instance index is given", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/metric_histories/"+TEST_METRIC_TYPE+"?instance-index=1&results-per-page=2", nil)
+			})
+
+			It("passes it to the eventgenerator and paginates the metrics", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(eventGenQuery.Get("instance_index")).To(Equal("1"))
+				Expect(eventGenQuery.Get("order")).To(Equal("DESC"))
+
+				result := &models.InstanceMetricResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), result)).To(Succeed())
+				Expect(result.TotalResults).To(Equal(3))
+				Expect(result.TotalPages).To(Equal(2))
+				Expect(result.NextUrl).To(ContainSubstring("page=2"))
+				Expect(result.Resources).To(Equal(instanceMetrics[:2]))
+			})
+		})
+
+		When("the instance index is invalid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/metric_histories/"+TEST_METRIC_TYPE+"?instance-index=first", nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"instance-index must be a non-negative integer"}`))
+				Expect(eventGenQuery).To(BeNil())
+			})
+		})
+
+		When("the eventgenerator fails", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/metric_histories/"+TEST_METRIC_TYPE, nil)
+				eventGeneratorServer.RouteToHandler(http.MethodGet, regexp.MustCompile(`/v1/apps/[A-Za-z0-9\-]+/metric_histories/[a-zA-Z0-9_]+`),
+					ghttp.RespondWith(http.StatusInternalServerError, `{"code":"Internal-Server-Error","message":"Error getting metric histories"}`))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("GetAggregatedMetricsHistories", func() {
+		JustBeforeEach(func() {
+			eventGeneratorResponse = []models.AppMetric{
+				{
+					AppId:      TEST_APP_ID,
+					Timestamp:  100,
+					MetricType: TEST_METRIC_TYPE,
+					Unit:       TEST_METRIC_UNIT,
+					Value:      "200",
+				},
+				{
+					AppId:      TEST_APP_ID,
+					Timestamp:  110,
+					MetricType: TEST_METRIC_TYPE,
+					Unit:       TEST_METRIC_UNIT,
+					Value:      "250",
+				},
+				{
+					AppId:      TEST_APP_ID,
+					Timestamp:  150,
+					MetricType: TEST_METRIC_TYPE,
+					Unit:       TEST_METRIC_UNIT,
+					Value:      "250",
+				},
+				{
+					AppId:      TEST_APP_ID,
+					Timestamp:  170,
+					MetricType: TEST_METRIC_TYPE,
+					Unit:       TEST_METRIC_UNIT,
+					Value:      "200",
+				},
+				{
+					AppId:      TEST_APP_ID,
+					Timestamp:  200,
+					MetricType: TEST_METRIC_TYPE,
+					Unit:       TEST_METRIC_UNIT,
+					Value:      "200",
+				},
+			}
+			handler.GetAggregatedMetricsHistories(resp, req, pathVariables)
+		})
+
+		When("CF_INSTANCE_CERT is not set", func() {
+			BeforeEach(func() {
+				os.Unsetenv("CF_INSTANCE_CERT")
+			})
+
+			When("appId is not present", func() {
+				BeforeEach(func() {
+					pathVariables["metricType"] = TEST_METRIC_TYPE
 
+					eventGeneratorStatus = http.StatusOK
 					params := url.Values{}
 					params.Add("start-time", "100")
 					params.Add("end-time", "300")
-					params.Add("page", "1")
 					params.Add("order-direction", "desc")
-					params.Add("results-per-page", "not-integer")
+					params.Add("page", "1")
+					params.Add("results-per-page", "2")
 
 					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
 				})
 				It("should fail with 400", func() {
 					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"results-per-page must be an integer"}`))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"appId is required"}`))
 				})
 			})
-			When("results-per-page is not provided", func() {
+
+			When("metricType is not present", func() {
 				BeforeEach(func() {
 					eventGeneratorStatus = http.StatusOK
 					pathVariables["appId"] = TEST_APP_ID
-					pathVariables["metricType"] = TEST_METRIC_TYPE
 
 					params := url.Values{}
 					params.Add("start-time", "100")
 					params.Add("end-time", "300")
-					params.Add("page", "1")
 					params.Add("order-direction", "desc")
+					params.Add("page", "1")
+					params.Add("results-per-page", "2")
 
 					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
 				})
-				It("should succeed with 200", func() {
-					Expect(resp.Code).To(Equal(http.StatusOK))
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Metrictype is required"}`))
 				})
 			})
 
-			When("scaling engine returns 500", func() {
+			When("start-time is not integer", func() {
 				BeforeEach(func() {
-					eventGeneratorStatus = http.StatusInternalServerError
+					eventGeneratorStatus = http.StatusOK
 					pathVariables["appId"] = TEST_APP_ID
 					pathVariables["metricType"] = TEST_METRIC_TYPE
 
 					params := url.Values{}
-					params.Add("start-time", "100")
+					params.Add("start-time", "not-integer")
 					params.Add("end-time", "300")
-					params.Add("page", "1")
 					params.Add("order-direction", "desc")
+					params.Add("page", "1")
 					params.Add("results-per-page", "2")
 
 					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
 				})
-				It("should succeed with 200", func() {
-					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"start-time must be an integer"}`))
 				})
 			})
 
-			When("getting 1st page", func() {
+			When("start-time is not provided", func() {
 				BeforeEach(func() {
 					eventGeneratorStatus = http.StatusOK
 					pathVariables["appId"] = TEST_APP_ID
 					pathVariables["metricType"] = TEST_METRIC_TYPE
 
 					params := url.Values{}
-					params.Add("start-time", "100")
 					params.Add("end-time", "300")
-					params.Add("page", "1")
 					params.Add("order-direction", "desc")
+					params.Add("page", "1")
 					params.Add("results-per-page", "2")
 
 					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
 				})
-				It("should get full page", func() {
+				It("should succeed with 200", func() {
 					Expect(resp.Code).To(Equal(http.StatusOK))
-					var result models.AppMetricResponse
-					err := json.Unmarshal(resp.Body.Bytes(), &result)
-					Expect(err).NotTo(HaveOccurred())
-					Expect(result).To(Equal(
-						models.AppMetricResponse{
-							PublicApiResponseBase: models.PublicApiResponseBase{
-								TotalResults: 5,
-								TotalPages:   3,
-								Page:         1,
-								PrevUrl:      "",
-								NextUrl:      "/v1/apps/" + TEST_APP_ID + "/aggregated_metric_histories/test_metric?end-time=300\u0026order-direction=desc\u0026page=2\u0026results-per-page=2\u0026start-time=100",
-							},
-							Resources: eventGeneratorResponse[0:2],
-						},
-					))
-
 				})
 			})
-			When("getting 2nd page", func() {
+
+			When("end-time is not integer", func() {
 				BeforeEach(func() {
 					eventGeneratorStatus = http.StatusOK
 					pathVariables["appId"] = TEST_APP_ID
@@ -945,20 +1469,234 @@ var _ = Describe("PublicApiHandler", func() {
 
 					params := url.Values{}
 					params.Add("start-time", "100")
-					params.Add("end-time", "300")
-					params.Add("page", "2")
+					params.Add("end-time", "not-integer")
 					params.Add("order-direction", "desc")
+					params.Add("page", "1")
 					params.Add("results-per-page", "2")
 
 					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
 				})
-				It("should get full page", func() {
-					Expect(resp.Code).To(Equal(http.StatusOK))
-					var result models.AppMetricResponse
-					err := json.Unmarshal(resp.Body.Bytes(), &result)
-					Expect(err).NotTo(HaveOccurred())
-					Expect(result).To(Equal(
-						models.AppMetricResponse{
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"end-time must be an integer"}`))
+				})
+			})
+
+			When("end-time is not provided", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("order-direction", "desc")
+					params.Add("page", "1")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should succeed with 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+				})
+			})
+
+			When("order-direction is not provided", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("page", "1")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should succeed with 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+				})
+			})
+
+			When("order-direction is not desc or asc", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("order-direction", "not-asc-desc")
+					params.Add("page", "1")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"order-direction must be DESC or ASC"}`))
+				})
+			})
+
+			When("page is not integer", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("order-direction", "desc")
+					params.Add("page", "not-integer")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"page must be an integer"}`))
+				})
+			})
+
+			When("page is not provided", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("order-direction", "desc")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should succeed with 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+				})
+			})
+
+			When("results-per-page is not integer", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("page", "1")
+					params.Add("order-direction", "desc")
+					params.Add("results-per-page", "not-integer")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"results-per-page must be an integer"}`))
+				})
+			})
+			When("results-per-page is not provided", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("page", "1")
+					params.Add("order-direction", "desc")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should succeed with 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+				})
+			})
+
+			When("scaling engine returns 500", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusInternalServerError
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("page", "1")
+					params.Add("order-direction", "desc")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should succeed with 200", func() {
+					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				})
+			})
+
+			When("getting 1st page", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("page", "1")
+					params.Add("order-direction", "desc")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should get full page", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					var result models.AppMetricResponse
+					err := json.Unmarshal(resp.Body.Bytes(), &result)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(
+						models.AppMetricResponse{
+							PublicApiResponseBase: models.PublicApiResponseBase{
+								TotalResults: 5,
+								TotalPages:   3,
+								Page:         1,
+								PrevUrl:      "",
+								NextUrl:      "/v1/apps/" + TEST_APP_ID + "/aggregated_metric_histories/test_metric?end-time=300\u0026order-direction=desc\u0026page=2\u0026results-per-page=2\u0026start-time=100",
+							},
+							Resources: eventGeneratorResponse[0:2],
+						},
+					))
+
+				})
+			})
+			When("getting 2nd page", func() {
+				BeforeEach(func() {
+					eventGeneratorStatus = http.StatusOK
+					pathVariables["appId"] = TEST_APP_ID
+					pathVariables["metricType"] = TEST_METRIC_TYPE
+
+					params := url.Values{}
+					params.Add("start-time", "100")
+					params.Add("end-time", "300")
+					params.Add("page", "2")
+					params.Add("order-direction", "desc")
+					params.Add("results-per-page", "2")
+
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+params.Encode(), nil)
+				})
+				It("should get full page", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					var result models.AppMetricResponse
+					err := json.Unmarshal(resp.Body.Bytes(), &result)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(
+						models.AppMetricResponse{
 							PublicApiResponseBase: models.PublicApiResponseBase{
 								TotalResults: 5,
 								TotalPages:   3,
@@ -1044,7 +1782,1913 @@ var _ = Describe("PublicApiHandler", func() {
 		})
 
 	})
-})
+
+	Describe("GetAggregatedMetricsHistories with downsampling and a cursor", func() {
+		var (
+			metricsPage   models.AppMetricPage
+			eventGenQuery url.Values
+		)
+
+		BeforeEach(func() {
+			metricsPage = models.AppMetricPage{
+				Resources: []*models.AppMetric{
+					{AppId: TEST_APP_ID, MetricType: TEST_METRIC_TYPE, Unit: TEST_METRIC_UNIT, Value: "200", Timestamp: 120000000000},
+					{AppId: TEST_APP_ID, MetricType: TEST_METRIC_TYPE, Unit: TEST_METRIC_UNIT, Value: "250", Timestamp: 60000000000},
+				},
+				NextCursor: "the-next-cursor",
+			}
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["metricType"] = TEST_METRIC_TYPE
+			eventGenQuery = nil
+			eventGeneratorHandler = func(w http.ResponseWriter, r *http.Request) {
+				eventGenQuery = r.URL.Query()
+				if r.URL.Query().Has("limit") {
+					ghttp.RespondWithJSONEncoded(http.StatusOK, metricsPage)(w, r)
+					return
+				}
+				ghttp.RespondWithJSONEncoded(http.StatusOK, metricsPage.Resources)(w, r)
+			}
+		})
+
+		JustBeforeEach(func() {
+			handler.GetAggregatedMetricsHistories(resp, req, pathVariables)
+		})
+
+		When("a step and an aggregation are given", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?step=60&aggregation=MAX", nil)
+			})
+
+			It("passes them to the eventgenerator and paginates the buckets with page numbers", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(eventGenQuery.Get("step")).To(Equal("60"))
+				Expect(eventGenQuery.Get("aggregation")).To(Equal("max"))
+				Expect(eventGenQuery.Has("limit")).To(BeFalse())
+
+				result := &models.AppMetricResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), result)).To(Succeed())
+				Expect(result.TotalResults).To(Equal(2))
+				Expect(result.Resources).To(HaveLen(2))
+			})
+		})
+
+		When("a limit is given", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?step=60&limit=2", nil)
+			})
+
+			It("returns the page of the eventgenerator with the url of the next page", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(eventGenQuery.Get("limit")).To(Equal("2"))
+				Expect(eventGenQuery.Has("cursor")).To(BeFalse())
+				Expect(eventGenQuery.Has("page")).To(BeFalse())
+
+				result := &models.AppMetricPage{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), result)).To(Succeed())
+				Expect(result.Resources).To(Equal(metricsPage.Resources))
+				Expect(result.NextCursor).To(Equal("the-next-cursor"))
+				Expect(result.NextUrl).To(ContainSubstring("cursor=the-next-cursor"))
+				Expect(result.NextUrl).To(ContainSubstring("limit=2"))
+				Expect(result.NextUrl).To(ContainSubstring("step=60"))
+			})
+		})
+
+		When("only a cursor is given", func() {
+			BeforeEach(func() {
+				metricsPage.NextCursor = ""
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?cursor=a-cursor", nil)
+			})
+
+			It("passes it to the eventgenerator with the default limit", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(eventGenQuery.Get("cursor")).To(Equal("a-cursor"))
+				Expect(eventGenQuery.Get("limit")).To(Equal("50"))
+
+				result := &models.AppMetricPage{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), result)).To(Succeed())
+				Expect(result.NextUrl).To(BeEmpty())
+			})
+		})
+
+		DescribeTable("when a parameter is invalid",
+			func(query string, message string) {
+				resp = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/"+TEST_METRIC_TYPE+"?"+query, nil)
+				handler.GetAggregatedMetricsHistories(resp, req, pathVariables)
+
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"` + message + `"}`))
+			},
+			Entry("step is not a number", "step=1m", "step must be a positive integer"),
+			Entry("step is negative", "step=-60", "step must be a positive integer"),
+			Entry("aggregation is unknown", "aggregation=sum", "aggregation must be avg, min or max"),
+			Entry("limit is zero", "limit=0", "limit must be an integer between 1 and 1000"),
+		)
+	})
+
+	Describe("GetInstanceHourUsage", func() {
+		var (
+			usageStatus   int
+			usageResponse any
+		)
+
+		BeforeEach(func() {
+			usageStatus = http.StatusOK
+			usageResponse = models.InstanceHourUsage{
+				Budget:                models.InstanceHourBudget{InstanceHours: 2000, Period: models.BudgetPeriodMonth},
+				CurrentInstances:      2,
+				ConsumedInstanceHours: 672,
+				ForecastInstanceHours: 1440,
+			}
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/instance_hour_usage", nil)
+
+			instanceHourUsagePathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/instance_hour_usage`)
+			Expect(err).NotTo(HaveOccurred())
+			scalingEngineServer.RouteToHandler(http.MethodGet, instanceHourUsagePathMatcher, ghttp.RespondWithJSONEncodedPtr(&usageStatus, &usageResponse))
+		})
+
+		JustBeforeEach(func() {
+			handler.GetInstanceHourUsage(resp, req, pathVariables)
+		})
+
+		Context("when the app has an instance-hour budget", func() {
+			It("returns the usage from the scaling engine", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				usage := &models.InstanceHourUsage{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), usage)).To(Succeed())
+				Expect(usage.Budget.InstanceHours).To(Equal(2000))
+				Expect(usage.ConsumedInstanceHours).To(Equal(672.0))
+				Expect(usage.ForecastInstanceHours).To(Equal(1440.0))
+			})
+		})
+
+		Context("when the app has no instance-hour budget", func() {
+			BeforeEach(func() {
+				usageStatus = http.StatusNotFound
+				usageResponse = models.ErrorResponse{Code: "Not-Found", Message: "Instance-hour budget not found"}
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"No instance-hour budget is set for the app"}`))
+			})
+		})
+
+		Context("when the scaling engine fails", func() {
+			BeforeEach(func() {
+				usageStatus = http.StatusInternalServerError
+				usageResponse = models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting instance-hour usage"}
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving instance-hour usage"}`))
+			})
+		})
+	})
+
+	Describe("GetScalingAnalytics", func() {
+		var (
+			analyticsStatus   int
+			analyticsResponse any
+			analyticsRequest  *http.Request
+		)
+
+		BeforeEach(func() {
+			analyticsStatus = http.StatusOK
+			analyticsResponse = models.ScalingAnalytics{
+				StartTime:     100,
+				EndTime:       500,
+				InstanceHours: 12.5,
+				ScaleOuts:     3,
+				ScaleIns:      2,
+				Failures:      1,
+			}
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/scaling_analytics?start-time=100&end-time=500&page=2", nil)
+
+			scalingAnalyticsPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_analytics`)
+			Expect(err).NotTo(HaveOccurred())
+			scalingEngineServer.RouteToHandler(http.MethodGet, scalingAnalyticsPathMatcher, ghttp.CombineHandlers(
+				func(_ http.ResponseWriter, r *http.Request) { analyticsRequest = r },
+				ghttp.RespondWithJSONEncodedPtr(&analyticsStatus, &analyticsResponse),
+			))
+		})
+
+		JustBeforeEach(func() {
+			handler.GetScalingAnalytics(resp, req, pathVariables)
+		})
+
+		Context("when the scaling engine returns the analytics", func() {
+			It("returns the analytics for the requested time range", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(analyticsRequest.URL.RawQuery).To(Equal("end-time=500&start-time=100"))
+				analytics := &models.ScalingAnalytics{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), analytics)).To(Succeed())
+				Expect(analytics).To(Equal(&models.ScalingAnalytics{StartTime: 100, EndTime: 500, InstanceHours: 12.5, ScaleOuts: 3, ScaleIns: 2, Failures: 1}))
+			})
+		})
+
+		Context("when the time range is invalid", func() {
+			BeforeEach(func() {
+				analyticsStatus = http.StatusBadRequest
+				analyticsResponse = models.ErrorResponse{Code: "Bad-Request", Message: "start-time is required"}
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"start-time is required"}`))
+			})
+		})
+
+		Context("when the app has no policy", func() {
+			BeforeEach(func() {
+				analyticsStatus = http.StatusNotFound
+				analyticsResponse = models.ErrorResponse{Code: "Not-Found", Message: "Policy not found"}
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"No policy is attached to the app"}`))
+			})
+		})
+
+		Context("when the scaling engine fails", func() {
+			BeforeEach(func() {
+				analyticsStatus = http.StatusInternalServerError
+				analyticsResponse = models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting scaling analytics"}
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling analytics"}`))
+			})
+		})
+	})
+
+	Describe("GetAppStatus", func() {
+		var (
+			scalingStateStatus      int
+			scalingStateResponse    any
+			evaluationStateStatus   int
+			evaluationStateResponse any
+			latestMetricStatus      int
+			latestMetricResponse    any
+		)
+
+		BeforeEach(func() {
+			ruleIndex := 0
+			policydb.GetAppPolicyReturns(&models.PolicyDefinition{
+				InstanceMin: 1,
+				InstanceMax: 5,
+				ScalingRules: []*models.ScalingRule{
+					{MetricType: "memoryused", Threshold: 300, Operator: ">", Adjustment: "+1"},
+				},
+			}, nil)
+			scalingStateStatus = http.StatusOK
+			scalingStateResponse = models.AppScalingState{
+				AppId:          TEST_APP_ID,
+				AppState:       models.AppStatusStarted,
+				Instances:      2,
+				InstanceMin:    2,
+				InstanceMax:    4,
+				ActiveSchedule: &models.ActiveSchedule{ScheduleId: "a-schedule-id", InstanceMin: 2, InstanceMax: 4},
+				Cooldowns:      []models.Cooldown{{Direction: models.ScalingDirectionOut, ExpiredAt: 300}},
+			}
+			evaluationStateStatus = http.StatusOK
+			evaluationStateResponse = models.AppEvaluationState{
+				AppId:   TEST_APP_ID,
+				Breaker: &models.BreakerState{Tripped: true, ConsecutiveFailures: 3},
+				Rules: []models.RuleEvaluationState{{
+					RuleEvaluation: models.RuleEvaluation{
+						RuleIndex:  &ruleIndex,
+						MetricType: "memoryused",
+						Samples:    []models.MetricSample{{Value: "350", Unit: "MB", Timestamp: 200}},
+						Breached:   true,
+					},
+					EvaluatedAt: 250,
+				}},
+			}
+			latestMetricStatus = http.StatusOK
+			latestMetricResponse = models.AppMetricPage{Resources: []*models.AppMetric{
+				{AppId: TEST_APP_ID, MetricType: "memoryused", Value: "350", Unit: "MB", Timestamp: 200},
+			}}
+			eventGeneratorHandler = ghttp.RespondWithJSONEncodedPtr(&latestMetricStatus, &latestMetricResponse)
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/status", nil)
+
+			scalingEngineServer.RouteToHandler(http.MethodGet, regexp.MustCompile(`/v1/apps/[A-Za-z0-9\-]+/scaling_state`),
+				ghttp.RespondWithJSONEncodedPtr(&scalingStateStatus, &scalingStateResponse))
+			eventGeneratorServer.RouteToHandler(http.MethodGet, regexp.MustCompile(`/v1/apps/[A-Za-z0-9\-]+/evaluation_state`),
+				ghttp.RespondWithJSONEncodedPtr(&evaluationStateStatus, &evaluationStateResponse))
+		})
+
+		JustBeforeEach(func() {
+			handler.GetAppStatus(resp, req, pathVariables)
+		})
+
+		Context("when the eventgenerator evaluates the app", func() {
+			It("returns the status of the app including its rules and circuit breaker", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				status := &models.AppStatus{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), status)).To(Succeed())
+				Expect(status.InstanceMin).To(Equal(2))
+				Expect(status.InstanceMax).To(Equal(4))
+				Expect(status.ActiveSchedule.ScheduleId).To(Equal("a-schedule-id"))
+				Expect(status.Cooldowns).To(Equal([]models.Cooldown{{Direction: models.ScalingDirectionOut, ExpiredAt: 300}}))
+				Expect(status.ScalingDisabled).To(BeFalse())
+				Expect(status.Breaker).To(Equal(&models.BreakerState{Tripped: true, ConsecutiveFailures: 3}))
+				Expect(status.Rules).To(HaveLen(1))
+				Expect(*status.Rules[0].LastValue).To(Equal(int64(350)))
+				Expect(*status.Rules[0].DistanceToThreshold).To(Equal(int64(50)))
+				Expect(status.Rules[0].Breached).To(BeTrue())
+			})
+
+			It("reads the latest aggregated metric of each rule", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(eventGeneratorServer.ReceivedRequests()).To(ContainElement(WithTransform(func(r *http.Request) string {
+					return r.URL.RequestURI()
+				}, Equal("/v1/apps/"+TEST_APP_ID+"/aggregated_metric_histories/memoryused?limit=1&order=DESC"))))
+			})
+		})
+
+		Context("when there are several eventgenerator instances", func() {
+			var instanceServers []*ghttp.Server
+
+			BeforeEach(func() {
+				instanceServers = []*ghttp.Server{ghttp.NewServer(), ghttp.NewServer(), ghttp.NewServer()}
+				instanceUrls := []string{}
+				for _, server := range instanceServers {
+					server.AllowUnhandledRequests = true
+					server.UnhandledRequestStatusCode = http.StatusNotFound
+					DeferCleanup(server.Close)
+					instanceUrls = append(instanceUrls, server.URL())
+				}
+				owner := instanceServers[helpers.OwnerIndex(TEST_APP_ID, len(instanceServers))]
+				owner.RouteToHandler(http.MethodGet, regexp.MustCompile(`/v1/apps/[A-Za-z0-9\-]+/evaluation_state`),
+					ghttp.RespondWithJSONEncodedPtr(&evaluationStateStatus, &evaluationStateResponse))
+
+				conf.EventGenerator.InstanceUrls = instanceUrls
+				DeferCleanup(func() { conf.EventGenerator.InstanceUrls = nil })
+			})
+
+			It("reads the evaluation state from the instance which evaluates the app", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				status := &models.AppStatus{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), status)).To(Succeed())
+				Expect(status.Breaker).To(Equal(&models.BreakerState{Tripped: true, ConsecutiveFailures: 3}))
+				Expect(status.Rules[0].Breached).To(BeTrue())
+				for i, server := range instanceServers {
+					if i == helpers.OwnerIndex(TEST_APP_ID, len(instanceServers)) {
+						Expect(server.ReceivedRequests()).To(HaveLen(1))
+					} else {
+						Expect(server.ReceivedRequests()).To(BeEmpty())
+					}
+				}
+			})
+		})
+
+		Context("when the app has no aggregated metrics yet", func() {
+			BeforeEach(func() {
+				latestMetricResponse = models.AppMetricPage{Resources: []*models.AppMetric{}}
+			})
+
+			It("returns the rules without their last value", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				status := &models.AppStatus{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), status)).To(Succeed())
+				Expect(status.Rules[0].LastValue).To(BeNil())
+				Expect(status.Rules[0].Breached).To(BeTrue())
+			})
+		})
+
+		Context("when the eventgenerator does not know the app", func() {
+			BeforeEach(func() {
+				evaluationStateStatus = http.StatusNotFound
+				evaluationStateResponse = models.ErrorResponse{Code: "Not-Found", Message: "App is not evaluated by this instance"}
+			})
+
+			It("returns the status of the app without the evaluation state", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				status := &models.AppStatus{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), status)).To(Succeed())
+				Expect(status.Breaker).To(BeNil())
+				Expect(status.Rules).To(HaveLen(1))
+				Expect(*status.Rules[0].LastValue).To(Equal(int64(350)))
+				Expect(status.Rules[0].Breached).To(BeFalse())
+			})
+		})
+
+		Context("when the eventgenerator fails", func() {
+			BeforeEach(func() {
+				evaluationStateStatus = http.StatusInternalServerError
+				evaluationStateResponse = models.ErrorResponse{Code: "Internal-Server-Error", Message: "an error"}
+			})
+
+			It("returns the status of the app without the evaluation state", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				status := &models.AppStatus{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), status)).To(Succeed())
+				Expect(status.Breaker).To(BeNil())
+			})
+		})
+
+		Context("when the app has no policy", func() {
+			BeforeEach(func() {
+				policydb.GetAppPolicyReturns(nil, nil)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Policy Not Found"}`))
+			})
+		})
+
+		Context("when retrieving the policy fails", func() {
+			BeforeEach(func() {
+				policydb.GetAppPolicyReturns(nil, fmt.Errorf("an error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling policy"}`))
+			})
+		})
+
+		Context("when the scaling engine fails", func() {
+			BeforeEach(func() {
+				scalingStateStatus = http.StatusInternalServerError
+				scalingStateResponse = models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting scaling state"}
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving app status"}`))
+			})
+		})
+	})
+
+	Describe("GetSpaceApps", func() {
+		var (
+			lastScalingStatus   int
+			lastScalingResponse any
+			lastScalingRequests []*http.Request
+		)
+
+		BeforeEach(func() {
+			lastScalingRequests = nil
+			lastScalingStatus = http.StatusOK
+			lastScalingResponse = map[string]*models.AppScalingHistory{
+				"app-1": {AppId: "app-1", Timestamp: 300, OldInstances: 1, NewInstances: 2},
+			}
+			pathVariables["spaceId"] = "a-space-id"
+			req = httptest.NewRequest(http.MethodGet, "/v1/spaces/a-space-id/apps", nil)
+
+			bindingdb.GetAppIdsBySpaceIdReturns([]string{"app-1", "app-2", "app-3"}, nil)
+			policydb.GetAppPoliciesReturns(map[string]*models.PolicyDefinition{
+				"app-1": {
+					InstanceMin: 1,
+					InstanceMax: 5,
+					ScalingRules: []*models.ScalingRule{
+						{MetricType: "cpu", Threshold: 80, Operator: ">", Adjustment: "+1"},
+						{MetricType: "cpu", Threshold: 20, Operator: "<", Adjustment: "-1"},
+					},
+					Schedules: &models.ScalingSchedules{RecurringSchedules: []*models.RecurringSchedule{{}}},
+				},
+				"app-2": {InstanceMin: 2, InstanceMax: 4},
+			}, nil)
+			cfClient.GetProcessesOfAppsReturns(map[cf.Guid]cf.Processes{
+				"app-1": {{Type: "web", Instances: 2}},
+				"app-2": {{Type: "web", Instances: 3}},
+			}, nil)
+
+			scalingEngineServer.RouteToHandler(http.MethodGet, "/v1/last_scaling_histories", ghttp.CombineHandlers(
+				func(_ http.ResponseWriter, r *http.Request) { lastScalingRequests = append(lastScalingRequests, r) },
+				ghttp.RespondWithJSONEncodedPtr(&lastScalingStatus, &lastScalingResponse),
+			))
+		})
+
+		JustBeforeEach(func() {
+			handler.GetSpaceApps(resp, req, pathVariables)
+		})
+
+		It("lists the bound apps with their policy, instances and last scaling", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			_, spaceId := bindingdb.GetAppIdsBySpaceIdArgsForCall(0)
+			Expect(spaceId).To(Equal("a-space-id"))
+			_, appIds := policydb.GetAppPoliciesArgsForCall(0)
+			Expect(appIds).To(Equal([]string{"app-1", "app-2", "app-3"}))
+			_, guids, processTypes := cfClient.GetProcessesOfAppsArgsForCall(0)
+			Expect(guids).To(Equal([]cf.Guid{"app-1", "app-2", "app-3"}))
+			Expect(processTypes).To(Equal([]string{cf.ProcessTypeWeb}))
+			Expect(lastScalingRequests).To(HaveLen(1))
+			Expect(lastScalingRequests[0].URL.Query()["app-id"]).To(Equal([]string{"app-1", "app-2", "app-3"}))
+
+			spaceApps := &models.SpaceApps{}
+			Expect(json.Unmarshal(resp.Body.Bytes(), spaceApps)).To(Succeed())
+			two, three := 2, 3
+			Expect(spaceApps).To(Equal(&models.SpaceApps{
+				SpaceId: "a-space-id",
+				Apps: []models.SpaceApp{
+					{
+						AppId:       "app-1",
+						Instances:   &two,
+						Policy:      &models.PolicySummary{InstanceMin: 1, InstanceMax: 5, ScalingRules: 2, MetricTypes: []string{"cpu"}, Schedules: 1},
+						LastScaling: &models.AppScalingHistory{AppId: "app-1", Timestamp: 300, OldInstances: 1, NewInstances: 2},
+					},
+					{
+						AppId:     "app-2",
+						Instances: &three,
+						Policy:    &models.PolicySummary{InstanceMin: 2, InstanceMax: 4, MetricTypes: []string{}},
+					},
+					{AppId: "app-3"},
+				},
+			}))
+		})
+
+		Context("when many apps are bound in the space", func() {
+			BeforeEach(func() {
+				appIds := []string{}
+				for i := range 120 {
+					appIds = append(appIds, fmt.Sprintf("app-%d", i))
+				}
+				bindingdb.GetAppIdsBySpaceIdReturns(appIds, nil)
+			})
+
+			It("requests the last scalings in batches", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(lastScalingRequests).To(HaveLen(3))
+				Expect(lastScalingRequests[0].URL.Query()["app-id"]).To(HaveLen(50))
+				Expect(lastScalingRequests[2].URL.Query()["app-id"]).To(HaveLen(20))
+			})
+		})
+
+		Context("when no app is bound in the space", func() {
+			BeforeEach(func() {
+				bindingdb.GetAppIdsBySpaceIdReturns([]string{}, nil)
+				policydb.GetAppPoliciesReturns(map[string]*models.PolicyDefinition{}, nil)
+				cfClient.GetProcessesOfAppsReturns(map[cf.Guid]cf.Processes{}, nil)
+			})
+
+			It("returns an empty list", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(Equal(`{"space_id":"a-space-id","apps":[]}`))
+				Expect(lastScalingRequests).To(BeEmpty())
+			})
+		})
+
+		Context("when retrieving the bound apps fails", func() {
+			BeforeEach(func() {
+				bindingdb.GetAppIdsBySpaceIdReturns(nil, fmt.Errorf("an error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving bound apps"}`))
+			})
+		})
+
+		Context("when retrieving the policies fails", func() {
+			BeforeEach(func() {
+				policydb.GetAppPoliciesReturns(nil, fmt.Errorf("an error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling policies"}`))
+			})
+		})
+
+		Context("when retrieving the processes fails", func() {
+			BeforeEach(func() {
+				cfClient.GetProcessesOfAppsReturns(nil, fmt.Errorf("an error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving app instances"}`))
+			})
+		})
+
+		Context("when the scaling engine fails", func() {
+			BeforeEach(func() {
+				lastScalingStatus = http.StatusInternalServerError
+				lastScalingResponse = models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting scaling histories from database"}
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling histories"}`))
+			})
+		})
+	})
+
+	Describe("GetOrgApps", func() {
+		var lastScalingRequests []*http.Request
+
+		BeforeEach(func() {
+			lastScalingRequests = nil
+			pathVariables["orgId"] = "an-org-id"
+			req = httptest.NewRequest(http.MethodGet, "/v1/organizations/an-org-id/apps", nil)
+
+			bindingdb.GetAppIdsByOrgIdReturns(map[string][]string{
+				"space-b": {"app-3"},
+				"space-a": {"app-1", "app-2"},
+			}, nil)
+			policydb.GetAppPoliciesReturns(map[string]*models.PolicyDefinition{
+				"app-1": {InstanceMin: 1, InstanceMax: 5},
+			}, nil)
+			cfClient.GetProcessesOfAppsReturns(map[cf.Guid]cf.Processes{
+				"app-1": {{Type: "web", Instances: 2}},
+			}, nil)
+
+			scalingEngineServer.RouteToHandler(http.MethodGet, "/v1/last_scaling_histories", ghttp.CombineHandlers(
+				func(_ http.ResponseWriter, r *http.Request) { lastScalingRequests = append(lastScalingRequests, r) },
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]*models.AppScalingHistory{
+					"app-3": {AppId: "app-3", Timestamp: 300, OldInstances: 1, NewInstances: 2},
+				}),
+			))
+		})
+
+		JustBeforeEach(func() {
+			handler.GetOrgApps(resp, req, pathVariables)
+		})
+
+		It("lists the bound apps of all spaces at once", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			_, orgId := bindingdb.GetAppIdsByOrgIdArgsForCall(0)
+			Expect(orgId).To(Equal("an-org-id"))
+			Expect(policydb.GetAppPoliciesCallCount()).To(Equal(1))
+			_, appIds := policydb.GetAppPoliciesArgsForCall(0)
+			Expect(appIds).To(Equal([]string{"app-1", "app-2", "app-3"}))
+			Expect(cfClient.GetProcessesOfAppsCallCount()).To(Equal(1))
+			Expect(lastScalingRequests).To(HaveLen(1))
+
+			orgApps := &models.OrgApps{}
+			Expect(json.Unmarshal(resp.Body.Bytes(), orgApps)).To(Succeed())
+			two := 2
+			Expect(orgApps).To(Equal(&models.OrgApps{
+				OrgId: "an-org-id",
+				Spaces: []models.SpaceApps{
+					{
+						SpaceId: "space-a",
+						Apps: []models.SpaceApp{
+							{AppId: "app-1", Instances: &two, Policy: &models.PolicySummary{InstanceMin: 1, InstanceMax: 5, MetricTypes: []string{}}},
+							{AppId: "app-2"},
+						},
+					},
+					{
+						SpaceId: "space-b",
+						Apps: []models.SpaceApp{
+							{AppId: "app-3", LastScaling: &models.AppScalingHistory{AppId: "app-3", Timestamp: 300, OldInstances: 1, NewInstances: 2}},
+						},
+					},
+				},
+			}))
+		})
+
+		Context("when no app is bound in the organization", func() {
+			BeforeEach(func() {
+				bindingdb.GetAppIdsByOrgIdReturns(map[string][]string{}, nil)
+				policydb.GetAppPoliciesReturns(map[string]*models.PolicyDefinition{}, nil)
+				cfClient.GetProcessesOfAppsReturns(map[cf.Guid]cf.Processes{}, nil)
+			})
+
+			It("returns an empty list", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(Equal(`{"org_id":"an-org-id","spaces":[]}`))
+				Expect(lastScalingRequests).To(BeEmpty())
+			})
+		})
+
+		Context("when retrieving the bound apps fails", func() {
+			BeforeEach(func() {
+				bindingdb.GetAppIdsByOrgIdReturns(nil, fmt.Errorf("an error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving bound apps"}`))
+			})
+		})
+
+		Context("when retrieving the policies fails", func() {
+			BeforeEach(func() {
+				policydb.GetAppPoliciesReturns(nil, fmt.Errorf("an error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling policies"}`))
+			})
+		})
+	})
+
+	Describe("GetDefaultPolicy", func() {
+		BeforeEach(func() {
+			pathVariables["instanceId"] = "an-instance-id"
+			req = httptest.NewRequest(http.MethodGet, "/v1/service_instances/an-instance-id/default_policy", nil)
+			bindingdb.GetServiceInstanceReturns(&models.ServiceInstance{
+				ServiceInstanceId: "an-instance-id",
+				DefaultPolicy:     validCustomMetricsConfigurationStr,
+				DefaultPolicyGuid: "a-default-policy-guid",
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler.GetDefaultPolicy(resp, req, pathVariables)
+		})
+
+		It("returns the default policy with its configuration", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Body.String()).To(MatchJSON(validCustomMetricsConfigurationStr))
+			_, instanceId := bindingdb.GetServiceInstanceArgsForCall(0)
+			Expect(instanceId).To(Equal("an-instance-id"))
+		})
+
+		Context("when the service instance has no default policy", func() {
+			BeforeEach(func() {
+				bindingdb.GetServiceInstanceReturns(&models.ServiceInstance{ServiceInstanceId: "an-instance-id"}, nil)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Default Policy Not Found"}`))
+			})
+		})
+
+		Context("when the service instance does not exist", func() {
+			BeforeEach(func() {
+				bindingdb.GetServiceInstanceReturns(nil, db.ErrDoesNotExist)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Service Instance Not Found"}`))
+			})
+		})
+
+		Context("when the service instance cannot be retrieved", func() {
+			BeforeEach(func() {
+				bindingdb.GetServiceInstanceReturns(nil, fmt.Errorf("database error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving service instance"}`))
+			})
+		})
+	})
+
+	Describe("SetDefaultPolicy", func() {
+		BeforeEach(func() {
+			schedulerStatus = http.StatusOK
+			pathVariables["instanceId"] = "an-instance-id"
+			req = httptest.NewRequest(http.MethodPut, "/v1/service_instances/an-instance-id/default_policy", bytes.NewBufferString(validCustomMetricsConfigurationStr))
+			bindingdb.GetServiceInstanceReturns(&models.ServiceInstance{
+				ServiceInstanceId: "an-instance-id",
+				OrgId:             "an-org-id",
+				SpaceId:           "a-space-id",
+				DefaultPolicy:     ValidPolicyStr,
+				DefaultPolicyGuid: "an-old-default-policy-guid",
+			}, nil)
+			bindingdb.GetAppIdsByInstanceIdReturns([]string{"app-1", "app-2", "app-3"}, nil)
+			policydb.SetOrUpdateDefaultAppPolicyReturns([]string{"app-1", "app-3"}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler.SetDefaultPolicy(resp, req, pathVariables)
+		})
+
+		It("sets the default policy on the bound apps which use the previous default policy", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Body.String()).To(MatchJSON(validCustomMetricsConfigurationStr))
+
+			Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(Equal(1))
+			_, appIds, oldPolicyGuid, policy, newPolicyGuid, change := policydb.SetOrUpdateDefaultAppPolicyArgsForCall(0)
+			Expect(appIds).To(Equal([]string{"app-1", "app-2", "app-3"}))
+			Expect(oldPolicyGuid).To(Equal("an-old-default-policy-guid"))
+			Expect(policy.InstanceMax).To(Equal(5))
+			Expect(newPolicyGuid).NotTo(BeEmpty())
+			Expect(change.Source).To(Equal(models.PolicySourceDefaultPolicy))
+
+			Expect(bindingdb.UpdateServiceInstanceCallCount()).To(Equal(1))
+			_, serviceInstance := bindingdb.UpdateServiceInstanceArgsForCall(0)
+			Expect(serviceInstance.ServiceInstanceId).To(Equal("an-instance-id"))
+			Expect(serviceInstance.OrgId).To(Equal("an-org-id"))
+			Expect(serviceInstance.SpaceId).To(Equal("a-space-id"))
+			Expect(serviceInstance.DefaultPolicyGuid).To(Equal(newPolicyGuid))
+			Expect(serviceInstance.DefaultPolicy).To(MatchJSON(validCustomMetricsConfigurationStr))
+
+			Expect(schedulerServer.ReceivedRequests()).To(ContainElements(
+				WithTransform(func(r *http.Request) string { return r.Method + " " + r.URL.Path }, Equal("PUT /v1/apps/app-1/schedules")),
+				WithTransform(func(r *http.Request) string { return r.Method + " " + r.URL.Path }, Equal("PUT /v1/apps/app-3/schedules")),
+			))
+		})
+
+		Context("when the policy is invalid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPut, "/v1/service_instances/an-instance-id/default_policy", bytes.NewBufferString(InvalidPolicyStr))
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("instance_min_count is required"))
+				Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(BeZero())
+				Expect(bindingdb.UpdateServiceInstanceCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the policy uses the metrics of a source app in another space", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPut, "/v1/service_instances/an-instance-id/default_policy", bytes.NewBufferString(`{
+					"instance_min_count": 1,
+					"instance_max_count": 5,
+					"scaling_rules": [{
+						"metric_type": "queue_length",
+						"threshold": 100,
+						"operator": ">",
+						"adjustment": "+1",
+						"source_app_id": "source-app-id"
+					}]
+				}`))
+				cfClient.GetAppReturns(&cf.App{Guid: "source-app-id", Relationships: cf.Relationships{Space: &cf.Space{Data: cf.SpaceData{Guid: "another-space-id"}}}}, nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"The source app source-app-id is not in the same space"}`))
+				_, appId := cfClient.GetAppArgsForCall(0)
+				Expect(appId).To(Equal(cf.Guid("source-app-id")))
+				Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the service instance does not exist", func() {
+			BeforeEach(func() {
+				bindingdb.GetServiceInstanceReturns(nil, db.ErrDoesNotExist)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		When("the plan check is configured", func() {
+			BeforeEach(func() {
+				planCheck := conf.PlanCheck
+				DeferCleanup(func() { conf.PlanCheck = planCheck })
+				conf.PlanCheck = &config.PlanCheckConfig{PlanDefinitions: map[string]config.PlanDefinition{
+					"a-plan-id": {PlanCheckEnabled: true, SchedulesCount: 1, ScalingRulesCount: 0},
+				}}
+				cfServiceInstance := &cf.ServiceInstance{Guid: "an-instance-id"}
+				cfServiceInstance.Relationships.ServicePlan.Data.Guid = "a-service-plan-guid"
+				cfClient.GetServiceInstanceReturns(cfServiceInstance, nil)
+				cfClient.GetServicePlanReturns(&cf.ServicePlan{Guid: "a-service-plan-guid", BrokerCatalog: cf.BrokerCatalog{Id: "a-plan-id"}}, nil)
+			})
+
+			It("checks the policy against the plan of the service instance", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("policy did not adhere to plan: Too many scaling rules"))
+				_, serviceInstanceGuid := cfClient.GetServiceInstanceArgsForCall(0)
+				Expect(serviceInstanceGuid).To(Equal("an-instance-id"))
+				Expect(policydb.SetOrUpdateDefaultAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when setting the default policy on the bound apps fails", func() {
+			BeforeEach(func() {
+				policydb.SetOrUpdateDefaultAppPolicyReturns(nil, fmt.Errorf("database error"))
+			})
+
+			It("should fail with 500 and keep the previous default policy", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error setting default policy"}`))
+				Expect(bindingdb.UpdateServiceInstanceCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when updating the schedules fails", func() {
+			BeforeEach(func() {
+				schedulerStatus = http.StatusInternalServerError
+			})
+
+			It("should fail with 500 after saving the default policy", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(bindingdb.UpdateServiceInstanceCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("DeleteDefaultPolicy", func() {
+		BeforeEach(func() {
+			schedulerStatus = http.StatusOK
+			pathVariables["instanceId"] = "an-instance-id"
+			req = httptest.NewRequest(http.MethodDelete, "/v1/service_instances/an-instance-id/default_policy", nil)
+			bindingdb.GetServiceInstanceReturns(&models.ServiceInstance{
+				ServiceInstanceId: "an-instance-id",
+				OrgId:             "an-org-id",
+				SpaceId:           "a-space-id",
+				DefaultPolicy:     ValidPolicyStr,
+				DefaultPolicyGuid: "a-default-policy-guid",
+			}, nil)
+			policydb.DeletePoliciesByPolicyGuidReturns([]string{"app-1"}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler.DeleteDefaultPolicy(resp, req, pathVariables)
+		})
+
+		It("removes the default policy from the service instance and the bound apps which use it", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Body.String()).To(Equal("{}"))
+
+			_, policyGuid := policydb.DeletePoliciesByPolicyGuidArgsForCall(0)
+			Expect(policyGuid).To(Equal("a-default-policy-guid"))
+			_, serviceInstance := bindingdb.UpdateServiceInstanceArgsForCall(0)
+			Expect(serviceInstance).To(Equal(models.ServiceInstance{ServiceInstanceId: "an-instance-id", OrgId: "an-org-id", SpaceId: "a-space-id"}))
+			Expect(schedulerServer.ReceivedRequests()).To(ContainElement(
+				WithTransform(func(r *http.Request) string { return r.Method + " " + r.URL.Path }, Equal("DELETE /v1/apps/app-1/schedules")),
+			))
+		})
+
+		Context("when the service instance has no default policy", func() {
+			BeforeEach(func() {
+				bindingdb.GetServiceInstanceReturns(&models.ServiceInstance{ServiceInstanceId: "an-instance-id"}, nil)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(policydb.DeletePoliciesByPolicyGuidCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when deleting the schedules fails", func() {
+			BeforeEach(func() {
+				schedulerStatus = http.StatusInternalServerError
+			})
+
+			It("succeeds", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("when deleting the default policy from the bound apps fails", func() {
+			BeforeEach(func() {
+				policydb.DeletePoliciesByPolicyGuidReturns(nil, fmt.Errorf("database error"))
+			})
+
+			It("should fail with 500 and keep the default policy", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting default policy"}`))
+				Expect(bindingdb.UpdateServiceInstanceCallCount()).To(BeZero())
+			})
+		})
+	})
+	Describe("App groups", func() {
+		var group *models.AppGroup
+
+		BeforeEach(func() {
+			ratio := 2.0
+			group = &models.AppGroup{
+				GroupId:       "a-group-id",
+				LeadingMetric: models.LeadingMetric{AppId: TEST_APP_ID, MetricType: "throughput"},
+				Members:       []models.AppGroupMember{{AppId: "member-app-id", Ratio: &ratio}},
+			}
+			pathVariables["appId"] = TEST_APP_ID
+		})
+
+		Describe("GetAppGroup", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/group", nil)
+				policydb.GetAppGroupByAppIdReturns(group, nil)
+			})
+
+			JustBeforeEach(func() {
+				handler.GetAppGroup(resp, req, pathVariables)
+			})
+
+			It("returns the group of the app", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"group_id":"a-group-id","leading_metric":{"app_id":"` + TEST_APP_ID + `","metric_type":"throughput"},"members":[{"app_id":"member-app-id","ratio":2}]}`))
+			})
+
+			Context("when the app belongs to no group", func() {
+				BeforeEach(func() {
+					policydb.GetAppGroupByAppIdReturns(nil, nil)
+				})
+
+				It("should fail with 404", func() {
+					Expect(resp.Code).To(Equal(http.StatusNotFound))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"App Group Not Found"}`))
+				})
+			})
+		})
+
+		Describe("SetAppGroup", func() {
+			var body string
+
+			BeforeEach(func() {
+				body = `{"leading_metric":{"metric_type":"throughput"},"members":[{"app_id":"member-app-id","ratio":2}]}`
+				policydb.GetAppPolicyReturns(&models.PolicyDefinition{
+					InstanceMin:  1,
+					InstanceMax:  5,
+					ScalingRules: []*models.ScalingRule{{MetricType: "throughput", Threshold: 100, Operator: ">", Adjustment: "+1"}},
+				}, nil)
+				bindingdb.CheckServiceBindingReturns(true)
+				cfClient.HasUserAppRoleReturns(true, nil)
+			})
+
+			JustBeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPut, "/v1/apps/"+TEST_APP_ID+"/group", strings.NewReader(body))
+				req.Header.Set("Authorization", "bearer a-user-token")
+				handler.SetAppGroup(resp, req, pathVariables)
+			})
+
+			It("creates a group led by the app", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.SaveAppGroupCallCount()).To(Equal(1))
+				_, savedGroup := policydb.SaveAppGroupArgsForCall(0)
+				Expect(savedGroup.GroupId).NotTo(BeEmpty())
+				Expect(savedGroup.LeaderAppId()).To(Equal(TEST_APP_ID))
+				Expect(savedGroup.Members).To(Equal(group.Members))
+
+				Expect(bindingdb.CheckServiceBindingArgsForCall(0)).To(Equal("member-app-id"))
+				_, userToken, appId, roles := cfClient.HasUserAppRoleArgsForCall(0)
+				Expect(userToken).To(Equal("a-user-token"))
+				Expect(appId).To(Equal(cf.Guid("member-app-id")))
+				Expect(roles).To(Equal(cf.AppDeveloperRoles))
+			})
+
+			Context("when the app already leads a group", func() {
+				BeforeEach(func() {
+					policydb.GetAppGroupByAppIdReturns(group, nil)
+				})
+
+				It("replaces the group", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					_, savedGroup := policydb.SaveAppGroupArgsForCall(0)
+					Expect(savedGroup.GroupId).To(Equal("a-group-id"))
+				})
+			})
+
+			Context("when the app is a member of another group", func() {
+				BeforeEach(func() {
+					group.LeadingMetric.AppId = "leader-app-id"
+					policydb.GetAppGroupByAppIdReturns(group, nil)
+				})
+
+				It("should fail with 409", func() {
+					Expect(resp.Code).To(Equal(http.StatusConflict))
+					Expect(policydb.SaveAppGroupCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the group is invalid", func() {
+				BeforeEach(func() {
+					body = `{"leading_metric":{"metric_type":"throughput"},"members":[{"app_id":"member-app-id"}]}`
+				})
+
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid app group: members[0]: either ratio or instance_min_count and instance_max_count must be given"}`))
+				})
+			})
+
+			Context("when no scaling rule of the policy of the app uses the leading metric", func() {
+				BeforeEach(func() {
+					body = `{"leading_metric":{"metric_type":"cpu"},"members":[{"app_id":"member-app-id","ratio":2}]}`
+				})
+
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid app group: no scaling rule of the policy of the app uses the leading metric cpu"}`))
+					Expect(policydb.SaveAppGroupCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the app has no policy", func() {
+				BeforeEach(func() {
+					policydb.GetAppPolicyReturns(nil, nil)
+				})
+
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(policydb.SaveAppGroupCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when retrieving the policy of the app fails", func() {
+				BeforeEach(func() {
+					policydb.GetAppPolicyReturns(nil, fmt.Errorf("an error"))
+				})
+
+				It("should fail with 500", func() {
+					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling policy"}`))
+				})
+			})
+
+			Context("when a member is not bound to the autoscaler", func() {
+				BeforeEach(func() {
+					bindingdb.CheckServiceBindingReturns(false)
+				})
+
+				It("should fail with 403", func() {
+					Expect(resp.Code).To(Equal(http.StatusForbidden))
+					Expect(policydb.SaveAppGroupCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the user may not manage a member", func() {
+				BeforeEach(func() {
+					cfClient.HasUserAppRoleReturns(false, nil)
+				})
+
+				It("should fail with 403", func() {
+					Expect(resp.Code).To(Equal(http.StatusForbidden))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Forbidden","message":"You are not authorized to manage the app member-app-id"}`))
+					Expect(policydb.SaveAppGroupCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the user is an admin", func() {
+				BeforeEach(func() {
+					cfClient.IsUserAdminReturns(true, nil)
+					cfClient.HasUserAppRoleReturns(false, nil)
+				})
+
+				It("creates the group", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					Expect(cfClient.HasUserAppRoleCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when a member belongs to another group", func() {
+				BeforeEach(func() {
+					policydb.SaveAppGroupReturns(db.ErrConflict)
+				})
+
+				It("should fail with 409", func() {
+					Expect(resp.Code).To(Equal(http.StatusConflict))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Conflict","message":"A member of the app group belongs to another app group"}`))
+				})
+			})
+		})
+
+		Describe("DeleteAppGroup", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodDelete, "/v1/apps/"+TEST_APP_ID+"/group", nil)
+				policydb.GetAppGroupByAppIdReturns(group, nil)
+			})
+
+			JustBeforeEach(func() {
+				handler.DeleteAppGroup(resp, req, pathVariables)
+			})
+
+			It("deletes the group led by the app", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, groupId := policydb.DeleteAppGroupArgsForCall(0)
+				Expect(groupId).To(Equal("a-group-id"))
+			})
+
+			Context("when the app is a member of the group", func() {
+				BeforeEach(func() {
+					group.LeadingMetric.AppId = "leader-app-id"
+				})
+
+				It("should fail with 409", func() {
+					Expect(resp.Code).To(Equal(http.StatusConflict))
+					Expect(policydb.DeleteAppGroupCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Describe("GetGroupScalingHistories", func() {
+			var (
+				historiesStatus   int
+				historiesResponse any
+				historiesRequest  *http.Request
+			)
+
+			BeforeEach(func() {
+				historiesStatus = http.StatusOK
+				historiesResponse = []*models.GroupScalingHistory{{
+					GroupId:    "a-group-id",
+					Timestamp:  100,
+					MetricType: "throughput",
+					Status:     models.GroupScalingSucceeded,
+					Apps: []models.GroupMemberScaling{
+						{AppId: TEST_APP_ID, OldInstances: 1, NewInstances: 2, Status: models.GroupMemberScaled},
+						{AppId: "member-app-id", OldInstances: 2, NewInstances: 4, Status: models.GroupMemberScaled},
+					},
+				}}
+				policydb.GetAppGroupByAppIdReturns(group, nil)
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/group/scaling_histories?limit=5", nil)
+
+				scalingEngineServer.RouteToHandler(http.MethodGet, "/v1/app_groups/a-group-id/scaling_histories", ghttp.CombineHandlers(
+					func(_ http.ResponseWriter, r *http.Request) { historiesRequest = r },
+					ghttp.RespondWithJSONEncodedPtr(&historiesStatus, &historiesResponse),
+				))
+			})
+
+			JustBeforeEach(func() {
+				handler.GetGroupScalingHistories(resp, req, pathVariables)
+			})
+
+			It("returns the scaling histories of the group", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(historiesRequest.URL.Query().Get("start-time")).To(Equal("0"))
+				Expect(historiesRequest.URL.Query().Get("limit")).To(Equal("5"))
+				var histories []*models.GroupScalingHistory
+				Expect(json.Unmarshal(resp.Body.Bytes(), &histories)).To(Succeed())
+				Expect(histories).To(Equal(historiesResponse))
+			})
+
+			Context("when the app belongs to no group", func() {
+				BeforeEach(func() {
+					policydb.GetAppGroupByAppIdReturns(nil, nil)
+				})
+
+				It("should fail with 404", func() {
+					Expect(resp.Code).To(Equal(http.StatusNotFound))
+				})
+			})
+
+			Context("when the scaling engine rejects the query", func() {
+				BeforeEach(func() {
+					historiesStatus = http.StatusBadRequest
+					historiesResponse = models.ErrorResponse{Code: "Bad-Request", Message: "limit must be an integer between 1 and 1000"}
+				})
+
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"limit must be an integer between 1 and 1000"}`))
+				})
+			})
+
+			Context("when the scaling engine fails", func() {
+				BeforeEach(func() {
+					historiesStatus = http.StatusInternalServerError
+					historiesResponse = models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting group scaling histories from database"}
+				})
+
+				It("should fail with 500", func() {
+					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				})
+			})
+		})
+	})
+
+	Describe("GetScalingDecision", func() {
+		var (
+			decisionStatus   int
+			decisionResponse any
+			decisionRequest  *http.Request
+		)
+
+		BeforeEach(func() {
+			decisionStatus = http.StatusOK
+			decisionResponse = models.DecisionTrace{
+				DecisionId: "a-decision-id",
+				AppId:      TEST_APP_ID,
+				Timestamp:  100,
+				Evaluation: []models.RuleEvaluation{{
+					MetricType: "cpu",
+					Threshold:  80,
+					Operator:   ">",
+					Adjustment: "+1",
+					Samples:    []models.MetricSample{{Timestamp: 90, Value: "95", Unit: "%"}},
+					Breached:   true,
+				}},
+				Scaling:    &models.AppScalingHistory{AppId: TEST_APP_ID, Timestamp: 100, OldInstances: 1, NewInstances: 2, DecisionId: "a-decision-id"},
+				CFResponse: &models.CFScalingResponse{Instances: 2},
+			}
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["decisionId"] = "a-decision-id"
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/scaling_decisions/a-decision-id", nil)
+
+			scalingDecisionPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_decisions/[A-Za-z0-9\-]+`)
+			Expect(err).NotTo(HaveOccurred())
+			scalingEngineServer.RouteToHandler(http.MethodGet, scalingDecisionPathMatcher, ghttp.CombineHandlers(
+				func(_ http.ResponseWriter, r *http.Request) { decisionRequest = r },
+				ghttp.RespondWithJSONEncodedPtr(&decisionStatus, &decisionResponse),
+			))
+		})
+
+		JustBeforeEach(func() {
+			handler.GetScalingDecision(resp, req, pathVariables)
+		})
+
+		Context("when the scaling engine returns the decision", func() {
+			It("returns the trace of the decision", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(decisionRequest.URL.Path).To(Equal("/v1/apps/" + TEST_APP_ID + "/scaling_decisions/a-decision-id"))
+				decision := &models.DecisionTrace{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), decision)).To(Succeed())
+				Expect(*decision).To(Equal(decisionResponse))
+			})
+		})
+
+		Context("when the decision does not exist", func() {
+			BeforeEach(func() {
+				decisionStatus = http.StatusNotFound
+				decisionResponse = models.ErrorResponse{Code: "Not-Found", Message: "Decision not found"}
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Scaling decision not found"}`))
+			})
+		})
+
+		Context("when the scaling engine fails", func() {
+			BeforeEach(func() {
+				decisionStatus = http.StatusInternalServerError
+				decisionResponse = models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting scaling decision from database"}
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling decision"}`))
+			})
+		})
+	})
+
+	Describe("GetPolicyRevisions", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions?page=1&results-per-page=1", nil)
+			policydb.RetrievePolicyRevisionsReturns([]*models.PolicyRevision{
+				{AppId: TEST_APP_ID, Revision: 2, PolicyGuid: "guid-2", Author: "a-user", Source: models.PolicySourcePublicAPI, Timestamp: 200, Policy: &models.PolicyDefinition{InstanceMin: 2, InstanceMax: 5}},
+				{AppId: TEST_APP_ID, Revision: 1, PolicyGuid: "guid-1", Author: "a-user", Source: models.PolicySourceBrokerBind, Timestamp: 100, Policy: &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 5}},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler.GetPolicyRevisions(resp, req, pathVariables)
+		})
+
+		Context("when the app has revisions", func() {
+			It("returns the revisions page by page", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				result := &models.PublicApiResponseBase{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), result)).To(Succeed())
+				Expect(result.TotalResults).To(Equal(2))
+				Expect(result.TotalPages).To(Equal(2))
+				Expect(result.Resources).To(HaveLen(1))
+				Expect(result.Resources[0]).To(HaveKeyWithValue("revision", BeNumerically("==", 2)))
+				Expect(result.Resources[0]).To(HaveKeyWithValue("source", "public-api"))
+				Expect(result.NextUrl).To(ContainSubstring("page=2"))
+			})
+		})
+
+		Context("when the page is invalid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions?page=0", nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"page must be greater than 0"}`))
+			})
+		})
+
+		Context("when the database fails", func() {
+			BeforeEach(func() {
+				policydb.RetrievePolicyRevisionsReturns(nil, fmt.Errorf("database error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving policy revisions"}`))
+			})
+		})
+	})
+
+	Describe("GetPolicyRevisionDiff", func() {
+		var revisions map[int]*models.PolicyRevision
+
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			revisions = map[int]*models.PolicyRevision{
+				1: {AppId: TEST_APP_ID, Revision: 1, Policy: &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 5}},
+				2: {AppId: TEST_APP_ID, Revision: 2, Policy: &models.PolicyDefinition{InstanceMin: 2, InstanceMax: 5}},
+				3: {AppId: TEST_APP_ID, Revision: 3, Policy: &models.PolicyDefinition{InstanceMin: 2, InstanceMax: 8}},
+			}
+			policydb.GetPolicyRevisionStub = func(_ context.Context, _ string, revision int) (*models.PolicyRevision, error) {
+				return revisions[revision], nil
+			}
+			policydb.RetrievePolicyRevisionsReturns([]*models.PolicyRevision{revisions[3], revisions[2], revisions[1]}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler.GetPolicyRevisionDiff(resp, req, pathVariables)
+		})
+
+		Context("when both revisions are given", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions/diff?from=1&to=2", nil)
+			})
+
+			It("returns the differences", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"from":1,"to":2,"changes":[{"path":"instance_min_count","from":1,"to":2}]}`))
+			})
+		})
+
+		Context("when only the from revision is given", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions/diff?from=1", nil)
+			})
+
+			It("compares with the latest revision", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"from":1,"to":3,"changes":[{"path":"instance_max_count","from":5,"to":8},{"path":"instance_min_count","from":1,"to":2}]}`))
+			})
+		})
+
+		Context("when from is missing", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions/diff", nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"from must be a positive integer"}`))
+			})
+		})
+
+		Context("when a revision does not exist", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions/diff?from=1&to=4", nil)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Policy revision not found"}`))
+			})
+		})
+	})
+
+	Describe("RollbackPolicy", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["revision"] = "1"
+			req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/policy/revisions/1/rollback", nil)
+			req.Header.Set("Authorization", "bearer "+fakeToken(`{"user_name":"a-user","client_id":"cf"}`))
+			schedulerStatus = 200
+			var policy *models.PolicyDefinition
+			Expect(json.Unmarshal([]byte(ValidPolicyStr), &policy)).To(Succeed())
+			policydb.GetPolicyRevisionReturns(&models.PolicyRevision{AppId: TEST_APP_ID, Revision: 1, Policy: policy}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler.RollbackPolicy(resp, req, pathVariables)
+		})
+
+		Context("when the revision exists", func() {
+			It("attaches the policy of the revision again", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(ValidPolicyStr))
+
+				_, appId, revision := policydb.GetPolicyRevisionArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(revision).To(Equal(1))
+
+				Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
+				_, appId, policy, _, change := policydb.SaveAppPolicyArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(json.Marshal(policy)).To(MatchJSON(ValidPolicyStr))
+				Expect(change).To(Equal(models.PolicyChange{Author: "a-user", Source: models.PolicySourceRollback}))
+			})
+		})
+
+		Context("when the revision does not exist", func() {
+			BeforeEach(func() {
+				policydb.GetPolicyRevisionReturns(nil, nil)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Policy revision not found"}`))
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the revision is no longer valid", func() {
+			BeforeEach(func() {
+				policydb.GetPolicyRevisionReturns(&models.PolicyRevision{AppId: TEST_APP_ID, Revision: 1, Policy: &models.PolicyDefinition{InstanceMin: 5, InstanceMax: 2}}, nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("scaling_rules is required"))
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the scheduler fails", func() {
+			BeforeEach(func() {
+				schedulerStatus = 500
+				schedulerErrJson = `["err one"]`
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(ContainSubstring("unable to creation/update schedule"))
+			})
+		})
+
+		Context("when the policy of the revision exceeds the plan of the service instance", func() {
+			BeforeEach(func() {
+				planCheck := conf.PlanCheck
+				DeferCleanup(func() { conf.PlanCheck = planCheck })
+				conf.PlanCheck = &config.PlanCheckConfig{PlanDefinitions: map[string]config.PlanDefinition{
+					"a-plan-id": {PlanCheckEnabled: true, SchedulesCount: 1, ScalingRulesCount: 0},
+				}}
+				bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{ServiceInstanceId: "a-service-instance-id"}, nil)
+				cfServiceInstance := &cf.ServiceInstance{Guid: "a-service-instance-id"}
+				cfServiceInstance.Relationships.ServicePlan.Data.Guid = "a-service-plan-guid"
+				cfClient.GetServiceInstanceReturns(cfServiceInstance, nil)
+				cfClient.GetServicePlanReturns(&cf.ServicePlan{Guid: "a-service-plan-guid", BrokerCatalog: cf.BrokerCatalog{Id: "a-plan-id"}}, nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("policy did not adhere to plan: Too many scaling rules"))
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("GetSchedulePreview", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/schedule_preview?count=4", nil)
+			policydb.GetAppPolicyReturns(&models.PolicyDefinition{
+				InstanceMin: 1,
+				InstanceMax: 5,
+				Schedules: &models.ScalingSchedules{
+					Timezone: "Asia/Kolkata",
+					RecurringSchedules: []*models.RecurringSchedule{{
+						StartTime:             "10:00",
+						EndTime:               "18:00",
+						DaysOfWeek:            []int{1, 2, 3},
+						ScheduledInstanceMin:  1,
+						ScheduledInstanceMax:  10,
+						ScheduledInstanceInit: 5,
+					}},
+				},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler.GetSchedulePreview(resp, req, pathVariables)
+		})
+
+		Context("when the policy has schedules", func() {
+			It("returns the requested number of windows", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				preview := &policyvalidator.SchedulePreview{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), preview)).To(Succeed())
+				Expect(preview.Timezone).To(Equal("Asia/Kolkata"))
+				Expect(preview.Windows).To(HaveLen(4))
+				for _, window := range preview.Windows {
+					Expect(window.Type).To(Equal(policyvalidator.ScheduleTypeRecurring))
+					Expect(window.LocalStartTime.Format(policyvalidator.TimeLayout)).To(Equal("10:00"))
+					Expect(window.StartTime.UTC().Format(policyvalidator.TimeLayout)).To(Equal("04:30"))
+					Expect(window.InstanceMax).To(Equal(10))
+					Expect(window.InitialMinInstanceCount).To(Equal(5))
+				}
+			})
+		})
+
+		Context("when the policy has no schedules", func() {
+			BeforeEach(func() {
+				policydb.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 5}, nil)
+			})
+
+			It("returns no windows", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"windows": []}`))
+			})
+		})
+
+		Context("when the count is invalid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/schedule_preview?count=101", nil)
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"count must be an integer between 1 and 100"}`))
+			})
+		})
+
+		Context("when there is no policy", func() {
+			BeforeEach(func() {
+				policydb.GetAppPolicyReturns(nil, nil)
+			})
+
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("when the policy cannot be retrieved", func() {
+			BeforeEach(func() {
+				policydb.GetAppPolicyReturns(nil, fmt.Errorf("database error"))
+			})
+
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("PreviewPolicySchedules", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest(http.MethodPost, "/v1/policy/schedule_preview", bytes.NewBufferString(ValidPolicyStr))
+		})
+
+		JustBeforeEach(func() {
+			handler.PreviewPolicySchedules(resp, req, pathVariables)
+		})
+
+		Context("when the policy is valid", func() {
+			It("returns the default number of windows without persisting the policy", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				preview := &policyvalidator.SchedulePreview{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), preview)).To(Succeed())
+				Expect(preview.Windows).To(HaveLen(10))
+				Expect(policydb.SaveAppPolicyCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the policy is invalid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, "/v1/policy/schedule_preview", bytes.NewBufferString(InvalidPolicyStr))
+			})
+
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("instance_min_count is required"))
+			})
+		})
+	})
+
+	Describe("GetScalingEvents", func() {
+		type serverSentEvent struct {
+			Id    string
+			Event string
+			Data  string
+			Retry string
+		}
+
+		var (
+			eventsMutex    sync.Mutex
+			storedEvents   []*models.ScalingEvent
+			eventsStatus   int
+			eventsQueries  []url.Values
+			streamServer   *httptest.Server
+			cancelStream   context.CancelFunc
+			streamResponse *http.Response
+			received       chan serverSentEvent
+		)
+
+		storeEvent := func(event *models.ScalingEvent) {
+			eventsMutex.Lock()
+			defer eventsMutex.Unlock()
+			storedEvents = append(storedEvents, event)
+		}
+
+		BeforeEach(func() {
+			storedEvents = []*models.ScalingEvent{
+				{Id: 1, AppId: TEST_APP_ID, Timestamp: 100, Type: models.ScalingEventScaling, History: &models.AppScalingHistory{AppId: TEST_APP_ID, Timestamp: 100, OldInstances: 1, NewInstances: 2, Status: models.ScalingStatusSucceeded}},
+				{Id: 2, AppId: TEST_APP_ID, Timestamp: 200, Type: models.ScalingEventTriggerIgnored, History: &models.AppScalingHistory{AppId: TEST_APP_ID, Timestamp: 200, OldInstances: 2, NewInstances: 2, Status: models.ScalingStatusIgnored}},
+			}
+			eventsStatus = http.StatusOK
+			eventsQueries = nil
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/events", nil)
+
+			scalingEventsPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_events`)
+			Expect(err).NotTo(HaveOccurred())
+			scalingEngineServer.RouteToHandler(http.MethodGet, scalingEventsPathMatcher, func(w http.ResponseWriter, r *http.Request) {
+				eventsMutex.Lock()
+				defer eventsMutex.Unlock()
+				eventsQueries = append(eventsQueries, r.URL.Query())
+				if eventsStatus != http.StatusOK {
+					w.WriteHeader(eventsStatus)
+					return
+				}
+
+				result := models.ScalingEvents{Events: []*models.ScalingEvent{}}
+				after := r.URL.Query().Get("after")
+				if after == "" {
+					result.LastEventId = storedEvents[len(storedEvents)-1].Id
+				} else {
+					afterId, err := strconv.ParseInt(after, 10, 64)
+					Expect(err).NotTo(HaveOccurred())
+					result.LastEventId = afterId
+					for _, event := range storedEvents {
+						if event.Id > afterId {
+							result.Events = append(result.Events, event)
+							result.LastEventId = max(result.LastEventId, event.Id)
+						}
+					}
+				}
+				Expect(json.NewEncoder(w).Encode(result)).To(Succeed())
+			})
+
+			pollInterval, heartbeatInterval := conf.ScalingEngine.EventsPollInterval, conf.ScalingEngine.EventsHeartbeatInterval
+			conf.ScalingEngine.EventsPollInterval = 10 * time.Millisecond
+			conf.ScalingEngine.EventsHeartbeatInterval = time.Hour
+			DeferCleanup(func() {
+				conf.ScalingEngine.EventsPollInterval, conf.ScalingEngine.EventsHeartbeatInterval = pollInterval, heartbeatInterval
+			})
+		})
+
+		Context("when the stream is opened", func() {
+			JustBeforeEach(func() {
+				streamServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					handler.GetScalingEvents(w, r, pathVariables)
+				}))
+
+				var ctx context.Context
+				ctx, cancelStream = context.WithCancel(context.Background())
+				streamReq, err := http.NewRequestWithContext(ctx, http.MethodGet, streamServer.URL+req.URL.RequestURI(), nil)
+				Expect(err).NotTo(HaveOccurred())
+				streamReq.Header = req.Header
+				streamResponse, err = http.DefaultClient.Do(streamReq)
+				Expect(err).NotTo(HaveOccurred())
+
+				received = make(chan serverSentEvent, 100)
+				go func() {
+					defer GinkgoRecover()
+					scanner := bufio.NewScanner(streamResponse.Body)
+					event := serverSentEvent{}
+					for scanner.Scan() {
+						field, value, _ := strings.Cut(scanner.Text(), ": ")
+						switch field {
+						case "id":
+							event.Id = value
+						case "event":
+							event.Event = value
+						case "data":
+							event.Data = value
+						case "retry":
+							event.Retry = value
+						case "":
+							received <- event
+							event = serverSentEvent{}
+						}
+					}
+				}()
+			})
+
+			AfterEach(func() {
+				cancelStream()
+				_ = streamResponse.Body.Close()
+				streamServer.Close()
+			})
+
+			It("streams the events which happen after it is opened", func() {
+				Expect(streamResponse.StatusCode).To(Equal(http.StatusOK))
+				Expect(streamResponse.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+				Expect(streamResponse.Header.Get("Cache-Control")).To(Equal("no-cache"))
+
+				var event serverSentEvent
+				Eventually(received).Should(Receive(&event))
+				Expect(event).To(Equal(serverSentEvent{Retry: "3000"}))
+
+				storeEvent(&models.ScalingEvent{Id: 3, AppId: TEST_APP_ID, Timestamp: 300, Type: models.ScalingEventScheduleStart, History: &models.AppScalingHistory{AppId: TEST_APP_ID, Timestamp: 300, OldInstances: 2, NewInstances: 3, Status: models.ScalingStatusSucceeded}})
+				Eventually(received).Should(Receive(&event))
+				Expect(event.Id).To(Equal("3"))
+				Expect(event.Event).To(Equal("schedule_start"))
+				scalingEvent := &models.ScalingEvent{}
+				Expect(json.Unmarshal([]byte(event.Data), scalingEvent)).To(Succeed())
+				Expect(scalingEvent.Id).To(Equal(int64(3)))
+				Expect(scalingEvent.History.NewInstances).To(Equal(3))
+
+				eventsMutex.Lock()
+				defer eventsMutex.Unlock()
+				Expect(eventsQueries[0].Has("after")).To(BeFalse())
+				Expect(eventsQueries[0].Get("limit")).To(Equal("100"))
+				Expect(eventsQueries[1].Get("after")).To(Equal("2"))
+			})
+
+			It("streams events which are committed after events with a higher id once", func() {
+				var event serverSentEvent
+				Eventually(received).Should(Receive(&event))
+
+				storeEvent(&models.ScalingEvent{Id: 4, AppId: TEST_APP_ID, Timestamp: 400, Type: models.ScalingEventScaling, History: &models.AppScalingHistory{AppId: TEST_APP_ID, Timestamp: 400, Status: models.ScalingStatusSucceeded}})
+				Eventually(received).Should(Receive(&event))
+				Expect(event.Id).To(Equal("4"))
+
+				lateEvent := models.NewEvaluationEvent(TEST_APP_ID, 300, []models.RuleEvaluation{{MetricType: "memoryused", Threshold: 100, Operator: ">", Adjustment: "+1", Breached: true}})
+				lateEvent.Id = 3
+				storeEvent(lateEvent)
+				Eventually(received).Should(Receive(&event))
+				Expect(event.Id).To(Equal("3"))
+				Expect(event.Event).To(Equal("evaluation"))
+				scalingEvent := &models.ScalingEvent{}
+				Expect(json.Unmarshal([]byte(event.Data), scalingEvent)).To(Succeed())
+				Expect(scalingEvent.Evaluation[0].Breached).To(BeTrue())
+
+				Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+				eventsMutex.Lock()
+				defer eventsMutex.Unlock()
+				Expect(eventsQueries[len(eventsQueries)-1].Get("after")).To(Equal("2"))
+			})
+
+			Context("when the client reconnects with the Last-Event-ID header", func() {
+				BeforeEach(func() {
+					req.Header.Set("Last-Event-ID", "1")
+				})
+
+				It("streams the events it has missed", func() {
+					var event serverSentEvent
+					Eventually(received).Should(Receive(&event))
+					Expect(event.Retry).To(Equal("3000"))
+					Eventually(received).Should(Receive(&event))
+					Expect(event.Id).To(Equal("2"))
+					Expect(event.Event).To(Equal("trigger_ignored"))
+					Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+				})
+			})
+
+			Context("when the client reconnects with the last-event-id parameter", func() {
+				BeforeEach(func() {
+					req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/events?last-event-id=0", nil)
+				})
+
+				It("streams the events it has missed", func() {
+					var event serverSentEvent
+					Eventually(received).Should(Receive(&event))
+					Eventually(received).Should(Receive(&event))
+					Expect(event.Id).To(Equal("1"))
+					Expect(event.Event).To(Equal("scaling"))
+					Eventually(received).Should(Receive(&event))
+					Expect(event.Id).To(Equal("2"))
+				})
+			})
+
+			Context("when there are no events for the heartbeat interval", func() {
+				BeforeEach(func() {
+					conf.ScalingEngine.EventsHeartbeatInterval = 50 * time.Millisecond
+				})
+
+				It("sends heartbeats without an id", func() {
+					var event serverSentEvent
+					Eventually(received).Should(Receive(&event))
+					Eventually(received).Should(Receive(&event))
+					Expect(event.Id).To(BeEmpty())
+					Expect(event.Event).To(Equal("heartbeat"))
+					scalingEvent := &models.ScalingEvent{}
+					Expect(json.Unmarshal([]byte(event.Data), scalingEvent)).To(Succeed())
+					Expect(scalingEvent.AppId).To(Equal(TEST_APP_ID))
+					Expect(scalingEvent.Type).To(Equal(models.ScalingEventHeartbeat))
+				})
+			})
+		})
+
+		Context("when the stream cannot be opened", func() {
+			JustBeforeEach(func() {
+				handler.GetScalingEvents(resp, req, pathVariables)
+			})
+
+			Context("when the Last-Event-ID is invalid", func() {
+				BeforeEach(func() {
+					req.Header.Set("Last-Event-ID", "not-a-number")
+				})
+
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Last-Event-ID must be a non-negative integer"}`))
+				})
+			})
+
+			Context("when the scaling engine fails", func() {
+				BeforeEach(func() {
+					eventsStatus = http.StatusInternalServerError
+				})
+
+				It("should fail with 500", func() {
+					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving scaling events"}`))
+				})
+			})
+		})
+	})
+})
+
+func fakeToken(claims string) string {
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
 
 func setupRequest(requestBody, appId string, pathVariables map[string]string) *http.Request {
 	pathVariables["appId"] = appId
@@ -1052,7 +3696,7 @@ func setupRequest(requestBody, appId string, pathVariables map[string]string) *h
 	return req
 }
 func setupPolicy(policyDb *fakes.FakePolicyDB) {
-	policyDb.GetAppPolicyReturns(&models.PolicyDefinition{
+	policyDb.GetAppPolicyWithGuidReturns(&models.PolicyDefinition{
 		InstanceMax: 5,
 		InstanceMin: 1,
 		ScalingRules: []*models.ScalingRule{
@@ -1077,5 +3721,5 @@ func setupPolicy(policyDb *fakes.FakePolicyDB) {
 				},
 			},
 		},
-	}, nil)
+	}, "a-policy-guid", nil)
 }
@@ -7,6 +7,7 @@ import (
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cred_helper"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api"
@@ -48,6 +49,15 @@ type PublicApiServer struct {
 	healthRouter              *mux.Router
 	publicApiServerMiddleware *Middleware
 	rateLimiterMiddleware     *ratelimiter.RateLimiterMiddleware
+	// spaceRateLimiterMiddleware limits the requests per space with the same limiter as the requests per app.
+	spaceRateLimiterMiddleware *ratelimiter.RateLimiterMiddleware
+	// orgRateLimiterMiddleware limits the requests per organization with the same limiter.
+	orgRateLimiterMiddleware *ratelimiter.RateLimiterMiddleware
+	// instanceRateLimiterMiddleware limits the requests per service instance with the same limiter.
+	instanceRateLimiterMiddleware *ratelimiter.RateLimiterMiddleware
+	// requestRateLimiterMiddleware limits the requests without an app in their path per app given in the query, or
+	// otherwise per user or client, with the same limiter.
+	requestRateLimiterMiddleware *ratelimiter.RateLimiterMiddleware
 }
 
 func NewPublicApiServer(logger lager.Logger, conf *config.Config, policyDB db.PolicyDB,
@@ -55,18 +65,22 @@ func NewPublicApiServer(logger lager.Logger, conf *config.Config, policyDB db.Po
 	cfClient cf.CFClient, httpStatusCollector healthendpoint.HTTPStatusCollector,
 	rateLimiter ratelimiter.Limiter, brokerServer brokerserver.BrokerServer) *PublicApiServer {
 	return &PublicApiServer{
-		logger:                    logger,
-		conf:                      conf,
-		policyDB:                  policyDB,
-		bindingDB:                 bindingDB,
-		credentials:               credentials,
-		checkBindingFunc:          checkBindingFunc,
-		cfClient:                  cfClient,
-		httpStatusCollector:       httpStatusCollector,
-		brokerServer:              brokerServer,
-		autoscalerRouter:          routes.NewRouter(),
-		publicApiServerMiddleware: NewMiddleware(logger, cfClient, checkBindingFunc, conf.APIClientId),
-		rateLimiterMiddleware:     ratelimiter.NewRateLimiterMiddleware("appId", rateLimiter, logger.Session("api-ratelimiter-middleware")),
+		logger:                        logger,
+		conf:                          conf,
+		policyDB:                      policyDB,
+		bindingDB:                     bindingDB,
+		credentials:                   credentials,
+		checkBindingFunc:              checkBindingFunc,
+		cfClient:                      cfClient,
+		httpStatusCollector:           httpStatusCollector,
+		brokerServer:                  brokerServer,
+		autoscalerRouter:              routes.NewRouter(),
+		publicApiServerMiddleware:     NewMiddleware(logger, cfClient, checkBindingFunc, conf.APIClientId),
+		rateLimiterMiddleware:         ratelimiter.NewRateLimiterMiddleware("appId", rateLimiter, logger.Session("api-ratelimiter-middleware")),
+		spaceRateLimiterMiddleware:    ratelimiter.NewRateLimiterMiddleware("spaceId", rateLimiter, logger.Session("api-space-ratelimiter-middleware")),
+		orgRateLimiterMiddleware:      ratelimiter.NewRateLimiterMiddleware("orgId", rateLimiter, logger.Session("api-org-ratelimiter-middleware")),
+		instanceRateLimiterMiddleware: ratelimiter.NewRateLimiterMiddleware("instanceId", rateLimiter, logger.Session("api-instance-ratelimiter-middleware")),
+		requestRateLimiterMiddleware:  ratelimiter.NewRateLimiterMiddlewareWithKeyFunc("requestKey", requestRateLimitKey, rateLimiter, logger.Session("api-request-ratelimiter-middleware")),
 	}
 }
 
@@ -125,7 +139,53 @@ func (s *PublicApiServer) setupApiProtectedRoutes(pah *PublicApiHandler, scaling
 	apiProtectedRouter.Use(s.publicApiServerMiddleware.CheckServiceBinding)
 	apiProtectedRouter.Use(healthendpoint.NewHTTPStatusCollectMiddleware(s.httpStatusCollector).Collect)
 	apiProtectedRouter.Get(routes.PublicApiScalingHistoryRouteName).Handler(scalingHistoryHandler)
+	apiProtectedRouter.Get(routes.PublicApiMetricsHistoryRouteName).Handler(VarsFunc(pah.GetMetricsHistories))
 	apiProtectedRouter.Get(routes.PublicApiAggregatedMetricsHistoryRouteName).Handler(VarsFunc(pah.GetAggregatedMetricsHistories))
+	apiProtectedRouter.Get(routes.PublicApiInstanceHourUsageRouteName).Handler(VarsFunc(pah.GetInstanceHourUsage))
+	apiProtectedRouter.Get(routes.PublicApiScalingAnalyticsRouteName).Handler(VarsFunc(pah.GetScalingAnalytics))
+	apiProtectedRouter.Get(routes.PublicApiScalingDecisionRouteName).Handler(VarsFunc(pah.GetScalingDecision))
+	apiProtectedRouter.Get(routes.PublicApiPolicyRevisionsRouteName).Handler(VarsFunc(pah.GetPolicyRevisions))
+	apiProtectedRouter.Get(routes.PublicApiPolicyRevisionDiffRouteName).Handler(VarsFunc(pah.GetPolicyRevisionDiff))
+	apiProtectedRouter.Get(routes.PublicApiPolicyRollbackRouteName).Handler(VarsFunc(pah.RollbackPolicy))
+	apiProtectedRouter.Get(routes.PublicApiSchedulePreviewRouteName).Handler(VarsFunc(pah.GetSchedulePreview))
+	apiProtectedRouter.Get(routes.PublicApiScalingEventsRouteName).Handler(VarsFunc(pah.GetScalingEvents))
+	apiProtectedRouter.Get(routes.PublicApiAppStatusRouteName).Handler(VarsFunc(pah.GetAppStatus))
+	apiProtectedRouter.Get(routes.PublicApiGetAppGroupRouteName).Handler(VarsFunc(pah.GetAppGroup))
+	apiProtectedRouter.Get(routes.PublicApiSetAppGroupRouteName).Handler(VarsFunc(pah.SetAppGroup))
+	apiProtectedRouter.Get(routes.PublicApiDeleteAppGroupRouteName).Handler(VarsFunc(pah.DeleteAppGroup))
+	apiProtectedRouter.Get(routes.PublicApiGroupScalingHistoriesRouteName).Handler(VarsFunc(pah.GetGroupScalingHistories))
+}
+
+func (s *PublicApiServer) setupSpaceRoutes(pah *PublicApiHandler) {
+	rspace := s.autoscalerRouter.CreateApiSpaceSubrouter()
+	rspace.Use(otelmux.Middleware("apiserver"))
+	rspace.Use(healthendpoint.NewHTTPStatusCollectMiddleware(s.httpStatusCollector).Collect)
+	rspace.Use(s.spaceRateLimiterMiddleware.CheckRateLimit)
+	rspace.Use(s.publicApiServerMiddleware.HasClientToken)
+	rspace.Use(s.publicApiServerMiddleware.OauthForSpace)
+	rspace.Get(routes.PublicApiSpaceAppsRouteName).Handler(VarsFunc(pah.GetSpaceApps))
+}
+
+func (s *PublicApiServer) setupOrgRoutes(pah *PublicApiHandler) {
+	rorg := s.autoscalerRouter.CreateApiOrgSubrouter()
+	rorg.Use(otelmux.Middleware("apiserver"))
+	rorg.Use(healthendpoint.NewHTTPStatusCollectMiddleware(s.httpStatusCollector).Collect)
+	rorg.Use(s.orgRateLimiterMiddleware.CheckRateLimit)
+	rorg.Use(s.publicApiServerMiddleware.HasClientToken)
+	rorg.Use(s.publicApiServerMiddleware.OauthForOrg)
+	rorg.Get(routes.PublicApiOrgAppsRouteName).Handler(VarsFunc(pah.GetOrgApps))
+}
+
+func (s *PublicApiServer) setupDefaultPolicyRoutes(pah *PublicApiHandler) {
+	rdefault := s.autoscalerRouter.CreateApiDefaultPolicySubrouter()
+	rdefault.Use(otelmux.Middleware("apiserver"))
+	rdefault.Use(healthendpoint.NewHTTPStatusCollectMiddleware(s.httpStatusCollector).Collect)
+	rdefault.Use(s.instanceRateLimiterMiddleware.CheckRateLimit)
+	rdefault.Use(s.publicApiServerMiddleware.HasClientToken)
+	rdefault.Use(s.publicApiServerMiddleware.OauthForServiceInstance(s.bindingDB))
+	rdefault.Get(routes.PublicApiGetDefaultPolicyRouteName).Handler(VarsFunc(pah.GetDefaultPolicy))
+	rdefault.Get(routes.PublicApiSetDefaultPolicyRouteName).Handler(VarsFunc(pah.SetDefaultPolicy))
+	rdefault.Get(routes.PublicApiDeleteDefaultPolicyRouteName).Handler(VarsFunc(pah.DeleteDefaultPolicy))
 }
 
 func (s *PublicApiServer) setupPolicyRoutes(pah *PublicApiHandler) {
@@ -138,6 +198,29 @@ func (s *PublicApiServer) setupPolicyRoutes(pah *PublicApiHandler) {
 	rpolicy.Get(routes.PublicApiGetPolicyRouteName).Handler(VarsFunc(pah.GetScalingPolicy))
 	rpolicy.Get(routes.PublicApiAttachPolicyRouteName).Handler(VarsFunc(pah.AttachScalingPolicy))
 	rpolicy.Get(routes.PublicApiDetachPolicyRouteName).Handler(VarsFunc(pah.DetachScalingPolicy))
+	rpolicy.Get(routes.PublicApiPatchPolicyRouteName).Handler(VarsFunc(pah.PatchScalingPolicy))
+}
+
+func (s *PublicApiServer) setupPolicyValidationRoutes(pah *PublicApiHandler) {
+	rvalidation := s.autoscalerRouter.CreateApiPolicyValidationSubrouter()
+	rvalidation.Use(otelmux.Middleware("apiserver"))
+	rvalidation.Use(healthendpoint.NewHTTPStatusCollectMiddleware(s.httpStatusCollector).Collect)
+	rvalidation.Use(s.publicApiServerMiddleware.HasClientToken)
+	rvalidation.Use(s.publicApiServerMiddleware.OauthWithOptionalAppId)
+	// the limit is checked after the authentication as it is keyed on the claims of the token
+	rvalidation.Use(s.requestRateLimiterMiddleware.CheckRateLimit)
+	rvalidation.Get(routes.PublicApiValidatePolicyRouteName).Handler(VarsFunc(pah.ValidateScalingPolicy))
+}
+
+func (s *PublicApiServer) setupPolicySchedulePreviewRoutes(pah *PublicApiHandler) {
+	rpreview := s.autoscalerRouter.CreateApiPolicySchedulePreviewSubrouter()
+	rpreview.Use(otelmux.Middleware("apiserver"))
+	rpreview.Use(healthendpoint.NewHTTPStatusCollectMiddleware(s.httpStatusCollector).Collect)
+	rpreview.Use(s.publicApiServerMiddleware.HasClientToken)
+	rpreview.Use(s.publicApiServerMiddleware.OauthWithOptionalAppId)
+	// the limit is checked after the authentication as it is keyed on the claims of the token
+	rpreview.Use(s.requestRateLimiterMiddleware.CheckRateLimit)
+	rpreview.Get(routes.PublicApiPreviewPolicySchedulesRouteName).Handler(VarsFunc(pah.PreviewPolicySchedules))
 }
 
 func (s *PublicApiServer) setupPublicApiRoutes(pah *PublicApiHandler) {
@@ -147,14 +230,19 @@ func (s *PublicApiServer) setupPublicApiRoutes(pah *PublicApiHandler) {
 }
 
 func (s *PublicApiServer) setupApiRoutes() error {
-	publicApiHandler := NewPublicApiHandler(s.logger, s.conf, s.policyDB, s.bindingDB, s.credentials)
+	publicApiHandler := NewPublicApiHandler(s.logger, s.conf, s.policyDB, s.bindingDB, s.credentials, s.cfClient)
 	scalingHistoryHandler, err := s.newScalingHistoryHandler()
 	if err != nil {
 		return err
 	}
 	s.setupApiProtectedRoutes(publicApiHandler, scalingHistoryHandler)
 	s.setupPublicApiRoutes(publicApiHandler)
+	s.setupSpaceRoutes(publicApiHandler)
+	s.setupOrgRoutes(publicApiHandler)
+	s.setupDefaultPolicyRoutes(publicApiHandler)
 	s.setupPolicyRoutes(publicApiHandler)
+	s.setupPolicyValidationRoutes(publicApiHandler)
+	s.setupPolicySchedulePreviewRoutes(publicApiHandler)
 
 	return nil
 }
@@ -175,13 +263,16 @@ func (s *PublicApiServer) setupHealthRouter() error {
 
 func (s *PublicApiServer) createPrometheusRegistry() *prometheus.Registry {
 	promRegistry := prometheus.NewRegistry()
-	healthendpoint.RegisterCollectors(promRegistry,
-		[]prometheus.Collector{
-			healthendpoint.NewDatabaseStatusCollector("autoscaler", "golangapiserver", "policyDB", s.policyDB),
-			healthendpoint.NewDatabaseStatusCollector("autoscaler", "golangapiserver", "bindingDB", s.bindingDB),
-			s.httpStatusCollector,
-		},
-		true, s.logger.Session("golangapiserver-prometheus"))
+	promCollectors := []prometheus.Collector{
+		healthendpoint.NewDatabaseStatusCollector("autoscaler", "golangapiserver", "policyDB", s.policyDB),
+		healthendpoint.NewDatabaseStatusCollector("autoscaler", "golangapiserver", "bindingDB", s.bindingDB),
+		s.httpStatusCollector,
+	}
+	// The CF client exposes the hit and miss counts of its token key and role caches.
+	if cfClientCollector, ok := s.cfClient.(prometheus.Collector); ok {
+		promCollectors = append(promCollectors, cfClientCollector)
+	}
+	healthendpoint.RegisterCollectors(promRegistry, promCollectors, true, s.logger.Session("golangapiserver-prometheus"))
 	return promRegistry
 }
 
@@ -191,5 +282,9 @@ func (s *PublicApiServer) newScalingHistoryHandler() (http.Handler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error creating scaling history handler: %w", err)
 	}
-	return scalinghistory.NewServer(scalingHistoryHandler, ss)
+	server, err := scalinghistory.NewServer(scalingHistoryHandler, ss)
+	if err != nil {
+		return nil, fmt.Errorf("error creating ogen scaling history server: %w", err)
+	}
+	return handlers.WithAcceptHeader(server), nil
 }
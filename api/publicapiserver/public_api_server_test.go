@@ -1,6 +1,7 @@
 package publicapiserver_test
 
 import (
+	"encoding/base64"
 	"io"
 	"net/http"
 	"net/url"
@@ -15,6 +16,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
 	"github.com/tedsuo/ifrit/ginkgomon_v2"
 )
 
@@ -136,6 +138,13 @@ var _ = Describe("PublicApiServer", func() {
 					})
 				})
 
+				Context("when calling instance metrics endpoint", func() {
+					It("should fail with 429", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/metric_histories/"+TEST_METRIC_TYPE,
+							nil, http.MethodGet, "", http.StatusTooManyRequests)
+					})
+				})
+
 				Context("when calling get policy endpoint", func() {
 					It("should fail with 429", func() {
 						verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/policy",
@@ -158,6 +167,64 @@ var _ = Describe("PublicApiServer", func() {
 
 				})
 
+				Context("when calling space apps endpoint", func() {
+					It("should fail with 429", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/spaces/a-space-id/apps",
+							nil, http.MethodGet, "", http.StatusTooManyRequests)
+					})
+				})
+
+				Context("when calling org apps endpoint", func() {
+					It("should fail with 429", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/organizations/an-org-id/apps",
+							nil, http.MethodGet, "", http.StatusTooManyRequests)
+					})
+				})
+
+				Context("when calling default policy endpoint", func() {
+					It("should fail with 429", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/service_instances/an-instance-id/default_policy",
+							nil, http.MethodGet, "", http.StatusTooManyRequests)
+					})
+				})
+
+				Context("when calling validate policy endpoint", func() {
+					BeforeEach(func() {
+						fakeCFClient.IsTokenAuthorizedReturns(true, nil)
+						fakeCFClient.IsUserAuthenticatedReturns(true, nil)
+						fakeCFClient.HasUserAppRoleReturns(true, nil)
+					})
+
+					It("should fail with 429 for the user of the token", func() {
+						userToken := "bearer e30." + base64.RawURLEncoding.EncodeToString([]byte(`{"user_id":"a-user-id","client_id":"cf"}`)) + ".c2ln"
+						verifyResponse(httpClient, serverUrl, "/v1/policy/validate",
+							map[string]string{"Authorization": userToken, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodPost, policy, http.StatusTooManyRequests)
+						Expect(fakeRateLimiter.ExceedsLimitArgsForCall(fakeRateLimiter.ExceedsLimitCallCount() - 1)).To(Equal("a-user-id"))
+					})
+
+					It("should fail with 429 for the app given in the query", func() {
+						serverUrl.RawQuery = "app_id=" + TEST_APP_ID
+						DeferCleanup(func() { serverUrl.RawQuery = "" })
+						verifyResponse(httpClient, serverUrl, "/v1/policy/validate",
+							map[string]string{"Authorization": TEST_USER_TOKEN, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodPost, policy, http.StatusTooManyRequests)
+						Expect(fakeRateLimiter.ExceedsLimitArgsForCall(fakeRateLimiter.ExceedsLimitCallCount() - 1)).To(Equal(TEST_APP_ID))
+					})
+				})
+
+				Context("when calling schedule preview endpoint", func() {
+					BeforeEach(func() {
+						fakeCFClient.IsTokenAuthorizedReturns(true, nil)
+						fakeCFClient.IsUserAuthenticatedReturns(true, nil)
+					})
+
+					It("should fail with 429 for the user of the token", func() {
+						userToken := "bearer e30." + base64.RawURLEncoding.EncodeToString([]byte(`{"user_id":"a-user-id","client_id":"cf"}`)) + ".c2ln"
+						verifyResponse(httpClient, serverUrl, "/v1/policy/schedule_preview",
+							map[string]string{"Authorization": userToken, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodPost, policy, http.StatusTooManyRequests)
+						Expect(fakeRateLimiter.ExceedsLimitArgsForCall(fakeRateLimiter.ExceedsLimitCallCount() - 1)).To(Equal("a-user-id"))
+					})
+				})
+
 			})
 
 			Describe("Without AuthorizatioToken", func() {
@@ -175,6 +242,13 @@ var _ = Describe("PublicApiServer", func() {
 					})
 				})
 
+				Context("when calling instance metrics endpoint", func() {
+					It("should fail with 401", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/metric_histories/"+TEST_METRIC_TYPE,
+							nil, http.MethodGet, "", http.StatusUnauthorized)
+					})
+				})
+
 				Context("when calling get policy endpoint", func() {
 					It("should fail with 401", func() {
 						verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/policy",
@@ -197,11 +271,53 @@ var _ = Describe("PublicApiServer", func() {
 
 				})
 
+				Context("when calling validate policy endpoint", func() {
+					It("should fail with 401", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/policy/validate",
+							nil, http.MethodPost, policy, http.StatusUnauthorized)
+					})
+				})
+
+				Context("when calling schedule preview endpoint", func() {
+					It("should fail with 401", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/policy/schedule_preview",
+							nil, http.MethodPost, policy, http.StatusUnauthorized)
+					})
+				})
+
+				Context("when calling space apps endpoint", func() {
+					It("should fail with 401", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/spaces/a-space-id/apps",
+							nil, http.MethodGet, "", http.StatusUnauthorized)
+					})
+				})
+
+				Context("when calling org apps endpoint", func() {
+					It("should fail with 401", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/organizations/an-org-id/apps",
+							nil, http.MethodGet, "", http.StatusUnauthorized)
+					})
+				})
+
+				Context("when calling default policy endpoint", func() {
+					It("should fail with 401", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/service_instances/an-instance-id/default_policy",
+							nil, http.MethodPut, policy, http.StatusUnauthorized)
+					})
+				})
+
+				Context("when calling app group endpoint", func() {
+					It("should fail with 401", func() {
+						verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/group",
+							nil, http.MethodPut, "{}", http.StatusUnauthorized)
+					})
+				})
+
 			})
 
 			Describe("Without Client Token", func() {
 				BeforeEach(func() {
-					fakeCFClient.IsUserSpaceDeveloperReturns(true, nil)
+					fakeCFClient.HasUserAppRoleReturns(true, nil)
 				})
 
 				Context("when calling scaling_histories endpoint", func() {
@@ -261,7 +377,7 @@ var _ = Describe("PublicApiServer", func() {
 			Describe("With Invalid Client Token", func() {
 				BeforeEach(func() {
 					fakeCFClient.IsTokenAuthorizedReturns(false, nil)
-					fakeCFClient.IsUserSpaceDeveloperReturns(true, nil)
+					fakeCFClient.HasUserAppRoleReturns(true, nil)
 				})
 
 				Context("when calling scaling_histories endpoint", func() {
@@ -320,7 +436,7 @@ var _ = Describe("PublicApiServer", func() {
 
 			Describe("With Invalid Authorization Token", func() {
 				BeforeEach(func() {
-					fakeCFClient.IsUserSpaceDeveloperReturns(false, nil)
+					fakeCFClient.HasUserAppRoleReturns(false, nil)
 				})
 
 				Context("when calling scaling_histories endpoint", func() {
@@ -381,7 +497,7 @@ var _ = Describe("PublicApiServer", func() {
 			Describe("With valid authorization token", func() {
 				BeforeEach(func() {
 					fakeCFClient.IsTokenAuthorizedReturns(true, nil)
-					fakeCFClient.IsUserSpaceDeveloperReturns(true, nil)
+					fakeCFClient.HasUserAppRoleReturns(true, nil)
 				})
 
 				Context("when calling scaling_histories endpoint", func() {
@@ -392,6 +508,65 @@ var _ = Describe("PublicApiServer", func() {
 						verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/scaling_histories",
 							map[string]string{"Authorization": TEST_USER_TOKEN, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodGet, "", http.StatusOK)
 					})
+
+					Context("with filters", func() {
+						var scalingEngineRequest *http.Request
+
+						BeforeEach(func() {
+							scalingEngineServer.RouteToHandler(http.MethodGet, scalingHistoryPathMatcher, ghttp.CombineHandlers(
+								func(_ http.ResponseWriter, r *http.Request) { scalingEngineRequest = r },
+								ghttp.RespondWithJSONEncodedPtr(&scalingEngineStatus, &scalingEngineResponse),
+							))
+						})
+
+						AfterEach(func() {
+							scalingEngineServer.RouteToHandler(http.MethodGet, scalingHistoryPathMatcher, ghttp.RespondWithJSONEncodedPtr(&scalingEngineStatus, &scalingEngineResponse))
+						})
+
+						It("should pass the filters to the scaling engine", func() {
+							serverUrl.RawQuery = "status=1&scaling-type=1&max-instance-delta=-1"
+							defer func() { serverUrl.RawQuery = "" }()
+							verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/scaling_histories",
+								map[string]string{"Authorization": TEST_USER_TOKEN, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodGet, "", http.StatusOK)
+
+							query := scalingEngineRequest.URL.Query()
+							Expect(query["status"]).To(Equal([]string{"1"}))
+							Expect(query["scaling-type"]).To(Equal([]string{"1"}))
+							Expect(query.Get("max-instance-delta")).To(Equal("-1"))
+						})
+					})
+
+					Context("with filters and an accepted export format", func() {
+						var scalingEngineRequest *http.Request
+
+						BeforeEach(func() {
+							scalingEngineServer.RouteToHandler(http.MethodGet, scalingHistoryPathMatcher, func(w http.ResponseWriter, r *http.Request) {
+								scalingEngineRequest = r
+								w.Header().Set("Content-Type", r.Header.Get("Accept"))
+								_, _ = w.Write([]byte("an export\n"))
+							})
+						})
+
+						AfterEach(func() {
+							scalingEngineServer.RouteToHandler(http.MethodGet, scalingHistoryPathMatcher, ghttp.RespondWithJSONEncodedPtr(&scalingEngineStatus, &scalingEngineResponse))
+						})
+
+						It("should stream the export of the filtered scaling histories from the scaling engine", func() {
+							serverUrl.RawQuery = "status=1&status=2&scaling-type=0&min-instance-delta=1&end-time=500"
+							defer func() { serverUrl.RawQuery = "" }()
+							body := verifyResponse(httpClient, serverUrl, "/v1/apps/"+TEST_APP_ID+"/scaling_histories",
+								map[string]string{"Authorization": TEST_USER_TOKEN, "X-Autoscaler-Token": TEST_CLIENT_TOKEN, "Accept": "text/csv"}, http.MethodGet, "", http.StatusOK)
+							Expect(body).To(Equal("an export\n"))
+
+							Expect(scalingEngineRequest.Header.Get("Accept")).To(Equal("text/csv"))
+							query := scalingEngineRequest.URL.Query()
+							Expect(query["status"]).To(Equal([]string{"1", "2"}))
+							Expect(query["scaling-type"]).To(Equal([]string{"0"}))
+							Expect(query.Get("min-instance-delta")).To(Equal("1"))
+							Expect(query.Get("end-time")).To(Equal("500"))
+							Expect(query.Has("page")).To(BeFalse())
+						})
+					})
 				})
 
 				Context("when calling aggregated metric endpoint", func() {
@@ -408,7 +583,7 @@ var _ = Describe("PublicApiServer", func() {
 				Context("when calling get policy endpoint", func() {
 					JustBeforeEach(func() {
 						schedulerStatus = http.StatusOK
-						fakePolicyDB.GetAppPolicyReturns(&models.PolicyDefinition{
+						fakePolicyDB.GetAppPolicyWithGuidReturns(&models.PolicyDefinition{
 							InstanceMax: 5,
 							InstanceMin: 1,
 							ScalingRules: []*models.ScalingRule{
@@ -420,7 +595,7 @@ var _ = Describe("PublicApiServer", func() {
 									Operator:              "<",
 									Adjustment:            "-1",
 								}},
-						}, nil)
+						}, "a-policy-guid", nil)
 
 					})
 					It("should succeed", func() {
@@ -451,6 +626,32 @@ var _ = Describe("PublicApiServer", func() {
 							map[string]string{"Authorization": TEST_USER_TOKEN, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodPut, policy, http.StatusOK)
 					})
 				})
+
+				Context("when calling validate policy endpoint", func() {
+					BeforeEach(func() {
+						fakeCFClient.IsUserAuthenticatedReturns(true, nil)
+					})
+
+					It("should succeed without persisting the policy", func() {
+						savedPolicies := fakePolicyDB.SaveAppPolicyCallCount()
+						body := verifyResponse(httpClient, serverUrl, "/v1/policy/validate",
+							map[string]string{"Authorization": TEST_USER_TOKEN, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodPost, policy, http.StatusOK)
+						Expect(body).To(ContainSubstring(`"valid":true`))
+						Expect(fakePolicyDB.SaveAppPolicyCallCount()).To(Equal(savedPolicies))
+					})
+				})
+
+				Context("when calling schedule preview endpoint", func() {
+					BeforeEach(func() {
+						fakeCFClient.IsUserAuthenticatedReturns(true, nil)
+					})
+
+					It("should succeed", func() {
+						body := verifyResponse(httpClient, serverUrl, "/v1/policy/schedule_preview",
+							map[string]string{"Authorization": TEST_USER_TOKEN, "X-Autoscaler-Token": TEST_CLIENT_TOKEN}, http.MethodPost, policy, http.StatusOK)
+						Expect(body).To(ContainSubstring(`"windows":`))
+					})
+				})
 			})
 		})
 	})
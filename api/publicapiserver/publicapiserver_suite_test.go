@@ -50,10 +50,12 @@ var (
 	infoBytes  []byte
 	httpClient *http.Client
 
-	scalingEngineServer    *ghttp.Server
-	metricsCollectorServer *ghttp.Server
-	eventGeneratorServer   *ghttp.Server
-	schedulerServer        *ghttp.Server
+	scalingEngineServer *ghttp.Server
+
+	scalingHistoryPathMatcher *regexp.Regexp
+	metricsCollectorServer    *ghttp.Server
+	eventGeneratorServer      *ghttp.Server
+	schedulerServer           *ghttp.Server
 
 	scalingEngineStatus    int
 	metricsCollectorStatus int
@@ -182,7 +184,7 @@ var _ = BeforeSuite(func() {
 	infoBytes, err = os.ReadFile("../exampleconfig/info-file.json")
 	Expect(err).NotTo(HaveOccurred())
 
-	scalingHistoryPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_histories`)
+	scalingHistoryPathMatcher, err = regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_histories`)
 	Expect(err).NotTo(HaveOccurred())
 	scalingEngineServer.RouteToHandler(http.MethodGet, scalingHistoryPathMatcher, ghttp.RespondWithJSONEncodedPtr(&scalingEngineStatus, &scalingEngineResponse))
 
@@ -1,6 +1,7 @@
 package publicapiserver
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,6 +15,8 @@ import (
 const (
 	ASC  = "ASC"
 	DESC = "DESC"
+
+	maxCursorLimit = 1000
 )
 
 func parseParameter(r *http.Request, vars map[string]string) (*url.Values, error) {
@@ -22,8 +25,6 @@ func parseParameter(r *http.Request, vars map[string]string) (*url.Values, error
 	endTime := r.URL.Query().Get("end-time")
 	orderDirection := r.URL.Query().Get("order-direction")
 	order := r.URL.Query().Get("order")
-	page := r.URL.Query().Get("page")
-	resultsPerPage := r.URL.Query().Get("results-per-page")
 
 	if appId == "" {
 		return nil, fmt.Errorf("appId is required")
@@ -54,6 +55,27 @@ func parseParameter(r *http.Request, vars map[string]string) (*url.Values, error
 	if orderDirection != DESC && orderDirection != ASC {
 		return nil, fmt.Errorf("order-direction must be DESC or ASC")
 	}
+
+	pageParameters, err := parsePageParameters(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := &url.Values{}
+	parameters.Add("start", startTime)
+	parameters.Add("end", endTime)
+	parameters.Add("order", orderDirection)
+	parameters.Add("page", pageParameters.Get("page"))
+	parameters.Add("results-per-page", pageParameters.Get("results-per-page"))
+
+	return parameters, nil
+}
+
+// parsePageParameters reads the page and results-per-page parameters, which default to the first page of 50 results.
+func parsePageParameters(r *http.Request) (*url.Values, error) {
+	page := r.URL.Query().Get("page")
+	resultsPerPage := r.URL.Query().Get("results-per-page")
+
 	if page == "" {
 		page = "1"
 	}
@@ -76,12 +98,8 @@ func parseParameter(r *http.Request, vars map[string]string) (*url.Values, error
 		return nil, fmt.Errorf("results-per-page must be greater than 0")
 	}
 	parameters := &url.Values{}
-	parameters.Add("start", startTime)
-	parameters.Add("end", endTime)
-	parameters.Add("order", orderDirection)
 	parameters.Add("page", page)
 	parameters.Add("results-per-page", resultsPerPage)
-
 	return parameters, nil
 }
 
@@ -150,3 +168,135 @@ func getPageUrl(r *url.URL, targetPageNo int) string {
 	pageUrl.RawQuery = pageParams.Encode()
 	return pageUrl.String()
 }
+
+// addDownsamplingParameters validates the step and aggregation parameters of an aggregated metric histories request
+// and adds them to the parameters for the eventgenerator. If a limit or a cursor is given, the histories are paged
+// with a cursor instead of page numbers and true is returned.
+func addDownsamplingParameters(r *http.Request, parameters *url.Values) (bool, error) {
+	query := r.URL.Query()
+
+	if step := query.Get("step"); step != "" {
+		stepSecs, err := strconv.Atoi(step)
+		if err != nil || stepSecs <= 0 {
+			return false, fmt.Errorf("step must be a positive integer")
+		}
+		parameters.Add("step", step)
+	}
+
+	if aggregation := strings.ToLower(query.Get("aggregation")); aggregation != "" {
+		if aggregation != "avg" && aggregation != "min" && aggregation != "max" {
+			return false, fmt.Errorf("aggregation must be avg, min or max")
+		}
+		parameters.Add("aggregation", aggregation)
+	}
+
+	limit := query.Get("limit")
+	cursor := query.Get("cursor")
+	if limit == "" && cursor == "" {
+		return false, nil
+	}
+	if limit == "" {
+		limit = "50"
+	}
+	limitCount, err := strconv.Atoi(limit)
+	if err != nil || limitCount <= 0 || limitCount > maxCursorLimit {
+		return false, fmt.Errorf("limit must be an integer between 1 and %d", maxCursorLimit)
+	}
+	parameters.Del("page")
+	parameters.Del("results-per-page")
+	parameters.Add("limit", limit)
+	if cursor != "" {
+		parameters.Add("cursor", cursor)
+	}
+	return true, nil
+}
+
+// getCursorUrl returns the url of the request with the cursor replaced by the given one.
+func getCursorUrl(r *url.URL, cursor string) string {
+	cursorUrl := *r
+	queries := cursorUrl.Query()
+	queries.Set("cursor", cursor)
+	cursorUrl.RawQuery = queries.Encode()
+	return cursorUrl.String()
+}
+
+type tokenClaims struct {
+	UserId   string `json:"user_id"`
+	UserName string `json:"user_name"`
+	ClientId string `json:"client_id"`
+}
+
+// bearerTokenClaims returns the bearer token of a request and its claims, which are empty if the token is no JWT. The
+// token has already been checked by the Oauth middlewares, so its claims are read without verification.
+func bearerTokenClaims(r *http.Request) (string, tokenClaims) {
+	claims := tokenClaims{}
+	_, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found {
+		return "", claims
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return token, claims
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return token, claims
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return token, tokenClaims{}
+	}
+	return token, claims
+}
+
+// requestAuthor returns the user, or the client for client credentials, on whose behalf a request is sent.
+func requestAuthor(r *http.Request) string {
+	_, claims := bearerTokenClaims(r)
+	if claims.UserName != "" {
+		return claims.UserName
+	}
+	return claims.ClientId
+}
+
+// requestRateLimitKey returns the key to rate limit requests by which are not sent for an app in their path: the app
+// given by the `app_id` query parameter, or otherwise the user or client on whose behalf the request is sent. Tokens
+// carrying neither are the key themselves.
+func requestRateLimitKey(r *http.Request) string {
+	if appId := r.URL.Query().Get("app_id"); appId != "" {
+		return appId
+	}
+	token, claims := bearerTokenClaims(r)
+	switch {
+	case claims.UserId != "":
+		return claims.UserId
+	case claims.ClientId != "":
+		return claims.ClientId
+	default:
+		return token
+	}
+}
+
+// policyETag returns the entity tag of the policy of an app, which is the guid the policy has been saved under.
+func policyETag(policyGuid string) string {
+	return `"` + policyGuid + `"`
+}
+
+// ifMatch evaluates the If-Match header of a request against the policy of an app. A request without If-Match
+// always matches, "*" matches any existing policy and entity tags are compared strongly.
+func ifMatch(r *http.Request, policyGuid string, policyExists bool) bool {
+	header := r.Header.Values("If-Match")
+	if len(header) == 0 {
+		return true
+	}
+	for _, value := range header {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" && policyExists {
+				return true
+			}
+			if policyExists && policyGuid != "" && tag == policyETag(policyGuid) {
+				return true
+			}
+		}
+	}
+	return false
+}
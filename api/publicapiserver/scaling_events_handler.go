@@ -0,0 +1,228 @@
+package publicapiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/config"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const (
+	// scalingEventsBatchSize is the number of events read from the scaling engine at once. A full batch is followed
+	// by the next one right away, so that a reconnecting client catches up quickly.
+	scalingEventsBatchSize = 100
+
+	// scalingEventsRetry tells clients how long to wait before they reconnect to a closed stream.
+	scalingEventsRetry = 3 * time.Second
+
+	// scalingEventsReplayWindow is the number of ids before the last event sent to a client whose events are read
+	// again with every poll, see scalingEventsCursor.
+	scalingEventsReplayWindow = 1000
+)
+
+// GetScalingEvents streams the scaling decisions, ignored triggers, schedule starts and ends and rule evaluations of
+// an app as server-sent events. The scaling engine stores the events of all its instances in its database, from which
+// they are polled for every stream. Heartbeats are sent to keep the stream open while there are no events. A client
+// which reconnects with the Last-Event-ID header, or the last-event-id parameter, receives the events it has missed
+// unless they are pruned.
+func (h *PublicApiHandler) GetScalingEvents(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetScalingEvents", lager.Data{"appId": appId})
+	logger.Info("Get ScalingEvents")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	lastEventId, err := parseLastEventId(req)
+	if err != nil {
+		logger.Error("Bad Request", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The first events are read before the stream starts, so that failures are still reported with a status code.
+	ctx := req.Context()
+	events, err := h.getScalingEvents(ctx, appId, lastEventId)
+	if err != nil {
+		logger.Error("Failed to retrieve scaling events from scalingengine", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling events")
+		return
+	}
+
+	if lastEventId < 0 {
+		lastEventId = events.LastEventId
+	}
+	cursor := newScalingEventsCursor(lastEventId)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", scalingEventsRetry.Milliseconds())
+
+	pollInterval := durationOrDefault(h.conf.ScalingEngine.EventsPollInterval, config.DefaultScalingEventsPollInterval)
+	heartbeatInterval := durationOrDefault(h.conf.ScalingEngine.EventsHeartbeatInterval, config.DefaultScalingEventsHeartbeatInterval)
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		unsent := cursor.add(events.Events)
+		for _, event := range unsent {
+			writeServerSentEvent(w, event)
+		}
+		if len(unsent) > 0 {
+			heartbeat.Reset(heartbeatInterval)
+		}
+		if err := rc.Flush(); err != nil {
+			logger.Info("stream-closed", lager.Data{"error": err.Error()})
+			return
+		}
+
+		if len(events.Events) == scalingEventsBatchSize {
+			next, err := h.getScalingEvents(ctx, appId, events.Events[len(events.Events)-1].Id)
+			if err == nil {
+				events = next
+				continue
+			}
+			logger.Error("Failed to retrieve scaling events from scalingengine", err)
+		}
+		events = &models.ScalingEvents{}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("stream-closed")
+			return
+		case <-heartbeat.C:
+			writeServerSentEvent(w, &models.ScalingEvent{AppId: appId, Timestamp: time.Now().UnixNano(), Type: models.ScalingEventHeartbeat})
+		case <-poll.C:
+			next, err := h.getScalingEvents(ctx, appId, cursor.after())
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Error("Failed to retrieve scaling events from scalingengine", err)
+				continue
+			}
+			events = next
+		}
+	}
+}
+
+// scalingEventsCursor keeps track of the events which have been sent to a client. The ids of events are assigned when
+// they are inserted, but an event can only be read once it is committed, which may happen after events with higher
+// ids have been read. Therefore, the events of the last scalingEventsReplayWindow ids are read again with every poll
+// and those which have already been sent are skipped. The ids are shared by the events of all apps. Events which are
+// committed later than that, or before the id a client reconnects with, are not sent.
+type scalingEventsCursor struct {
+	// first is the id after which events are sent.
+	first  int64
+	lastId int64
+	sent   map[int64]bool
+}
+
+func newScalingEventsCursor(lastEventId int64) *scalingEventsCursor {
+	return &scalingEventsCursor{first: lastEventId, lastId: lastEventId, sent: map[int64]bool{}}
+}
+
+// after returns the id after which the events are read with the next poll.
+func (c *scalingEventsCursor) after() int64 {
+	return max(c.first, c.lastId-scalingEventsReplayWindow)
+}
+
+// add returns the events which have not been sent yet in their order and records them as sent.
+func (c *scalingEventsCursor) add(events []*models.ScalingEvent) []*models.ScalingEvent {
+	unsent := []*models.ScalingEvent{}
+	for _, event := range events {
+		if event.Id <= c.first || c.sent[event.Id] {
+			continue
+		}
+		c.sent[event.Id] = true
+		c.lastId = max(c.lastId, event.Id)
+		unsent = append(unsent, event)
+	}
+	for id := range c.sent {
+		if id <= c.after() {
+			delete(c.sent, id)
+		}
+	}
+	return unsent
+}
+
+// getScalingEvents reads the events of an app after an event id from the scaling engine. If the id is below 0,
+// no events are read, but the id of the latest event.
+func (h *PublicApiHandler) getScalingEvents(ctx context.Context, appId string, afterId int64) (*models.ScalingEvents, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(scalingEventsBatchSize))
+	if afterId >= 0 {
+		query.Set("after", strconv.FormatInt(afterId, 10))
+	}
+
+	resp, err := h.getFromScalingEngine(ctx, routes.GetScalingEventsRouteName, appId, query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("scalingengine responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	events := &models.ScalingEvents{}
+	if err := json.NewDecoder(resp.Body).Decode(events); err != nil {
+		return nil, fmt.Errorf("failed to parse scaling events: %w", err)
+	}
+	return events, nil
+}
+
+// writeServerSentEvent writes an event in the format of server-sent events. Heartbeats have no id, so that the last
+// event id of the client is kept.
+func writeServerSentEvent(w io.Writer, event *models.ScalingEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if event.Id > 0 {
+		_, _ = fmt.Fprintf(w, "id: %d\n", event.Id)
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
+
+// parseLastEventId reads the id of the last event a client has received, which is -1 for a new client.
+func parseLastEventId(req *http.Request) (int64, error) {
+	lastEventId := req.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = req.URL.Query().Get("last-event-id")
+	}
+	if lastEventId == "" {
+		return -1, nil
+	}
+
+	id, err := strconv.ParseInt(lastEventId, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("Last-Event-ID must be a non-negative integer")
+	}
+	return id, nil
+}
+
+func durationOrDefault(d time.Duration, defaultDuration time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultDuration
+	}
+	return d
+}
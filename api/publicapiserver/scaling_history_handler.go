@@ -3,13 +3,19 @@ package publicapiserver
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/apis/scalinghistory"
 	internalscalingenginehistory "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/apis/scalinghistory"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/config"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
 	"code.cloudfoundry.org/lager/v3"
 )
 
@@ -33,9 +39,10 @@ func (h SecuritySource) HandleBearerAuth(ctx context.Context, operationName stri
 }
 
 type ScalingHistoryHandler struct {
-	logger lager.Logger
-	conf   *config.Config
-	client *internalscalingenginehistory.Client
+	logger     lager.Logger
+	conf       *config.Config
+	client     *internalscalingenginehistory.Client
+	httpClient *http.Client
 }
 
 func NewScalingHistoryHandler(logger lager.Logger, conf *config.Config) (*ScalingHistoryHandler, error) {
@@ -44,8 +51,9 @@ func NewScalingHistoryHandler(logger lager.Logger, conf *config.Config) (*Scalin
 		return nil, fmt.Errorf("error creating scaling history HTTP client: %w", err)
 	}
 	newHandler := &ScalingHistoryHandler{
-		logger: logger.Session("scaling-history-handler"),
-		conf:   conf,
+		logger:     logger.Session("scaling-history-handler"),
+		conf:       conf,
+		httpClient: seClient,
 	}
 
 	if client, err := internalscalingenginehistory.NewClient(conf.ScalingEngine.ScalingEngineUrl, internalscalingenginehistory.WithClient(seClient)); err != nil {
@@ -67,12 +75,30 @@ func (h *ScalingHistoryHandler) NewError(_ context.Context, _ error) *scalinghis
 	return result
 }
 
-func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Context, params scalinghistory.V1AppsGUIDScalingHistoriesGetParams) (*scalinghistory.History, error) {
+func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Context, params scalinghistory.V1AppsGUIDScalingHistoriesGetParams) (scalinghistory.V1AppsGUIDScalingHistoriesGetRes, error) {
 	result := &scalinghistory.History{}
-	logger := h.logger.Session("get-scaling-histories", helpers.AddTraceID(ctx, lager.Data{"app_guid": params.GUID}))
+	mediaType := handlers.NegotiateMediaType(handlers.AcceptHeaderFromContext(ctx), handlers.MediaTypeJSON, handlers.MediaTypeCSV, handlers.MediaTypeNDJSON)
+	logger := h.logger.Session("get-scaling-histories", helpers.AddTraceID(ctx, lager.Data{"app_guid": params.GUID, "media_type": mediaType}))
 	logger.Info("start")
 	defer logger.Info("end")
 
+	switch mediaType {
+	case handlers.MediaTypeCSV:
+		export, err := h.export(ctx, params, mediaType)
+		if err != nil {
+			logger.Error("export", err)
+			return nil, err
+		}
+		return &scalinghistory.V1AppsGUIDScalingHistoriesGetOKTextCsv{Data: export}, nil
+	case handlers.MediaTypeNDJSON:
+		export, err := h.export(ctx, params, mediaType)
+		if err != nil {
+			logger.Error("export", err)
+			return nil, err
+		}
+		return &scalinghistory.V1AppsGUIDScalingHistoriesGetOKApplicationXNdjson{Data: export}, nil
+	}
+
 	internalParams := internalscalingenginehistory.V1AppsGUIDScalingHistoriesGetParams{
 		GUID:      internalscalingenginehistory.GUID(params.GUID),
 		StartTime: internalscalingenginehistory.OptInt(params.StartTime),
@@ -81,15 +107,29 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 			Value: internalscalingenginehistory.V1AppsGUIDScalingHistoriesGetOrderDirection(params.OrderDirection.Value),
 			Set:   params.OrderDirection.Set,
 		},
-		Page:           internalscalingenginehistory.OptInt(params.Page),
-		ResultsPerPage: internalscalingenginehistory.OptInt(params.ResultsPerPage),
+		Page:             internalscalingenginehistory.OptInt(params.Page),
+		ResultsPerPage:   internalscalingenginehistory.OptInt(params.ResultsPerPage),
+		MinInstanceDelta: internalscalingenginehistory.OptInt(params.MinInstanceDelta),
+		MaxInstanceDelta: internalscalingenginehistory.OptInt(params.MaxInstanceDelta),
+	}
+	for _, status := range params.Status {
+		internalParams.Status = append(internalParams.Status, internalscalingenginehistory.V1AppsGUIDScalingHistoriesGetStatusItem(status))
+	}
+	for _, scalingType := range params.ScalingType {
+		internalParams.ScalingType = append(internalParams.ScalingType, internalscalingenginehistory.V1AppsGUIDScalingHistoriesGetScalingTypeItem(scalingType))
 	}
 	internalResult, err := h.client.V1AppsGUIDScalingHistoriesGet(ctx, internalParams)
 	if err != nil {
 		logger.Error("get", err)
 		return nil, err
 	}
-	jsonResult, err := internalResult.MarshalJSON()
+	internalHistory, ok := internalResult.(*internalscalingenginehistory.History)
+	if !ok {
+		err = fmt.Errorf("unexpected response of type %T", internalResult)
+		logger.Error("get", err)
+		return nil, err
+	}
+	jsonResult, err := internalHistory.MarshalJSON()
 	if err != nil {
 		logger.Error("marshal", err)
 		return nil, err
@@ -103,3 +143,60 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 
 	return result, err
 }
+
+// export requests an export of the scaling histories in the given media type from the scaling engine.
+// The ogen client reads responses completely, so it is not used here in order to pass the stream through.
+func (h *ScalingHistoryHandler) export(ctx context.Context, params scalinghistory.V1AppsGUIDScalingHistoriesGetParams, mediaType string) (io.ReadCloser, error) {
+	exportURL, err := url.Parse(h.conf.ScalingEngine.ScalingEngineUrl)
+	if err != nil {
+		return nil, err
+	}
+	exportURL = exportURL.JoinPath(strings.Replace(routes.ScalingHistoriesPath, "{guid}", url.PathEscape(string(params.GUID)), 1))
+	exportURL.RawQuery = exportQuery(params).Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, exportURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", mediaType)
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("scaling engine responded with status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+func exportQuery(params scalinghistory.V1AppsGUIDScalingHistoriesGetParams) url.Values {
+	query := url.Values{}
+	if params.StartTime.IsSet() {
+		query.Set("start-time", strconv.Itoa(params.StartTime.Value))
+	}
+	if params.EndTime.IsSet() {
+		query.Set("end-time", strconv.Itoa(params.EndTime.Value))
+	}
+	if params.OrderDirection.IsSet() {
+		query.Set("order-direction", string(params.OrderDirection.Value))
+	}
+	//nolint:staticcheck // For backwards-compatibility with our CF CLI plugin we want to honor the deprecated parameter if is used
+	if params.Order.IsSet() {
+		query.Set("order", string(params.Order.Value))
+	}
+	for _, status := range params.Status {
+		query.Add("status", strconv.Itoa(int(status)))
+	}
+	for _, scalingType := range params.ScalingType {
+		query.Add("scaling-type", strconv.Itoa(int(scalingType)))
+	}
+	if params.MinInstanceDelta.IsSet() {
+		query.Set("min-instance-delta", strconv.Itoa(params.MinInstanceDelta.Value))
+	}
+	if params.MaxInstanceDelta.IsSet() {
+		query.Set("max-instance-delta", strconv.Itoa(params.MaxInstanceDelta.Value))
+	}
+	return query
+}
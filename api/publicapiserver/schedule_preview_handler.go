@@ -0,0 +1,111 @@
+package publicapiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/policyvalidator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const (
+	defaultScheduleWindowCount = 10
+	maxScheduleWindowCount     = 100
+)
+
+// GetSchedulePreview returns the upcoming windows of the schedules of the policy attached to an app.
+func (h *PublicApiHandler) GetSchedulePreview(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	logger := h.logger.Session("GetSchedulePreview", lager.Data{"appId": appId})
+	logger.Info("Get SchedulePreview")
+
+	if appId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	count, err := parseScheduleWindowCount(r)
+	if err != nil {
+		logger.Error("Bad Request", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policy, err := h.policydb.GetAppPolicy(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve scaling policy from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
+		return
+	}
+	if policy == nil {
+		logger.Info("policy doesn't exist")
+		writeErrorResponse(w, http.StatusNotFound, "Policy Not Found")
+		return
+	}
+
+	h.writeSchedulePreview(w, logger, policy, count)
+}
+
+// PreviewPolicySchedules returns the upcoming windows of the schedules of a policy given in the request body,
+// which is validated like a policy which is attached.
+func (h *PublicApiHandler) PreviewPolicySchedules(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	logger := h.logger.Session("PreviewPolicySchedules")
+	logger.Info("Preview Policy Schedules")
+
+	count, err := parseScheduleWindowCount(r)
+	if err != nil {
+		logger.Error("Bad Request", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("Failed to read request body", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to read request body")
+		return
+	}
+
+	scalingPolicy, errResults := h.policyValidator.ParseAndValidatePolicy(policyBytes)
+	if errResults != nil {
+		logger.Info("Failed to validate policy", lager.Data{"errResults": errResults})
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, errResults)
+		return
+	}
+
+	h.writeSchedulePreview(w, logger, scalingPolicy.GetPolicyDefinition(), count)
+}
+
+func (h *PublicApiHandler) writeSchedulePreview(w http.ResponseWriter, logger lager.Logger, policy *models.PolicyDefinition, count int) {
+	var schedules *models.ScalingSchedules
+	if policy != nil {
+		schedules = policy.Schedules
+	}
+
+	preview, err := policyvalidator.PreviewSchedules(schedules, time.Now(), count)
+	if err != nil {
+		logger.Error("Failed to preview schedules", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error previewing schedules")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, preview)
+}
+
+func parseScheduleWindowCount(r *http.Request) (int, error) {
+	countParam := r.URL.Query().Get("count")
+	if countParam == "" {
+		return defaultScheduleWindowCount, nil
+	}
+
+	count, err := strconv.Atoi(countParam)
+	if err != nil || count < 1 || count > maxScheduleWindowCount {
+		return 0, fmt.Errorf("count must be an integer between 1 and %d", maxScheduleWindowCount)
+	}
+	return count, nil
+}
@@ -0,0 +1,177 @@
+package publicapiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// lastScalingBatchSize is the number of apps whose last scaling is requested from the scaling engine at once.
+const lastScalingBatchSize = 50
+
+// GetSpaceApps lists the apps of a space which are bound to the autoscaler, with a summary of their policy, their
+// current instances and their last scaling. The policies are read for all apps at once, and the instances and last
+// scalings are requested for batches of apps, so that the number of requests does not grow with each app.
+func (h *PublicApiHandler) GetSpaceApps(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	spaceId := vars["spaceId"]
+	logger := h.logger.Session("GetSpaceApps", lager.Data{"spaceId": spaceId})
+	logger.Info("Get SpaceApps")
+
+	if spaceId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "SpaceId is required")
+		return
+	}
+
+	ctx := req.Context()
+	appIds, err := h.bindingdb.GetAppIdsBySpaceId(ctx, spaceId)
+	if err != nil {
+		logger.Error("Failed to retrieve bound apps from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving bound apps")
+		return
+	}
+
+	apps, err := h.getAppsOverview(w, req, logger, appIds)
+	if err != nil {
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, &models.SpaceApps{SpaceId: spaceId, Apps: apps})
+}
+
+// GetOrgApps lists the apps of all spaces of an organization which are bound to the autoscaler, like GetSpaceApps
+// does for a space. The apps of all spaces are summarised together, and only spaces with bound apps are listed.
+func (h *PublicApiHandler) GetOrgApps(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+	orgId := vars["orgId"]
+	logger := h.logger.Session("GetOrgApps", lager.Data{"orgId": orgId})
+	logger.Info("Get OrgApps")
+
+	if orgId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "OrgId is required")
+		return
+	}
+
+	ctx := req.Context()
+	appIdsBySpace, err := h.bindingdb.GetAppIdsByOrgId(ctx, orgId)
+	if err != nil {
+		logger.Error("Failed to retrieve bound apps from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving bound apps")
+		return
+	}
+
+	spaceIds := slices.Sorted(maps.Keys(appIdsBySpace))
+	appIds := []string{}
+	for _, spaceId := range spaceIds {
+		appIds = append(appIds, appIdsBySpace[spaceId]...)
+	}
+	apps, err := h.getAppsOverview(w, req, logger, appIds)
+	if err != nil {
+		return
+	}
+
+	result := &models.OrgApps{OrgId: orgId, Spaces: []models.SpaceApps{}}
+	for _, spaceId := range spaceIds {
+		spaceApps := len(appIdsBySpace[spaceId])
+		result.Spaces = append(result.Spaces, models.SpaceApps{SpaceId: spaceId, Apps: apps[:spaceApps]})
+		apps = apps[spaceApps:]
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// getAppsOverview returns the overview of each of the apps, in the same order.
+func (h *PublicApiHandler) getAppsOverview(w http.ResponseWriter, r *http.Request, logger lager.Logger, appIds []string) ([]models.SpaceApp, error) {
+	ctx := r.Context()
+	policies, err := h.policydb.GetAppPolicies(ctx, appIds)
+	if err != nil {
+		logger.Error("Failed to retrieve policies from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policies")
+		return nil, err
+	}
+
+	guids := make([]cf.Guid, len(appIds))
+	for i, appId := range appIds {
+		guids[i] = cf.Guid(appId)
+	}
+	processes, err := h.cfClient.GetProcessesOfApps(ctx, guids, cf.ProcessTypeWeb)
+	if err != nil {
+		logger.Error("Failed to retrieve processes from cloud controller", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving app instances")
+		return nil, err
+	}
+
+	lastScalings, err := h.getLastScalingHistories(ctx, appIds)
+	if err != nil {
+		logger.Error("Failed to retrieve last scalings from scalingengine", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling histories")
+		return nil, err
+	}
+
+	apps := []models.SpaceApp{}
+	for _, appId := range appIds {
+		app := models.SpaceApp{AppId: appId, LastScaling: lastScalings[appId]}
+		if policy, found := policies[appId]; found {
+			app.Policy = models.NewPolicySummary(policy)
+		}
+		if appProcesses, found := processes[cf.Guid(appId)]; found {
+			instances := appProcesses.GetInstances()
+			app.Instances = &instances
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// getLastScalingHistories returns the last scaling of each app which has been scaled, by app.
+func (h *PublicApiHandler) getLastScalingHistories(ctx context.Context, appIds []string) (map[string]*models.AppScalingHistory, error) {
+	path, err := routes.NewRouter().CreateScalingEngineRoutes().Get(routes.GetLastScalingHistoriesRouteName).URLPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create path: %w", err)
+	}
+
+	result := map[string]*models.AppScalingHistory{}
+	for batch := range slices.Chunk(appIds, lastScalingBatchSize) {
+		path.RawQuery = url.Values{"app-id": batch}.Encode()
+		aUrl := h.conf.ScalingEngine.ScalingEngineUrl + path.RequestURI()
+		seReq, err := http.NewRequestWithContext(ctx, http.MethodGet, aUrl, nil) // #nosec G704 -- URL host from internal config, app ids from the binding database
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request for %s: %w", aUrl, err)
+		}
+
+		histories, err := h.doGetLastScalingHistories(seReq)
+		if err != nil {
+			return nil, err
+		}
+		for appId, history := range histories {
+			result[appId] = history
+		}
+	}
+	return result, nil
+}
+
+func (h *PublicApiHandler) doGetLastScalingHistories(seReq *http.Request) (map[string]*models.AppScalingHistory, error) {
+	resp, err := h.scalingEngineClient.Do(seReq) // #nosec G704 -- URL host from internal config, app ids from the binding database
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last scaling histories: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get last scaling histories: unexpected status code %d", resp.StatusCode)
+	}
+
+	histories := map[string]*models.AppScalingHistory{}
+	if err := json.NewDecoder(resp.Body).Decode(&histories); err != nil {
+		return nil, fmt.Errorf("failed to parse last scaling histories: %w", err)
+	}
+	return histories, nil
+}
@@ -0,0 +1,49 @@
+package cf
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	cacheTokenKeys     = "token_keys"
+	cacheRoleDecisions = "role_decisions"
+)
+
+// cacheMetrics counts the hits and misses of the caches of the CF client, labelled by cache.
+type cacheMetrics struct {
+	hits   *prometheus.CounterVec
+	misses *prometheus.CounterVec
+}
+
+func newCacheMetrics() *cacheMetrics {
+	return &cacheMetrics{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "autoscaler",
+			Subsystem: "cf_client",
+			Name:      "cache_hits_total",
+			Help:      "Number of lookups answered from a cache of the CF client",
+		}, []string{"cache"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "autoscaler",
+			Subsystem: "cf_client",
+			Name:      "cache_misses_total",
+			Help:      "Number of lookups which required a request to CF or UAA",
+		}, []string{"cache"}),
+	}
+}
+
+func (m *cacheMetrics) hit(cache string) {
+	m.hits.WithLabelValues(cache).Inc()
+}
+
+func (m *cacheMetrics) miss(cache string) {
+	m.misses.WithLabelValues(cache).Inc()
+}
+
+func (m *cacheMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.hits.Describe(ch)
+	m.misses.Describe(ch)
+}
+
+func (m *cacheMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.hits.Collect(ch)
+	m.misses.Collect(ch)
+}
@@ -20,11 +20,16 @@ import (
 	"github.com/cloudfoundry/go-cfclient/v3/client"
 	"github.com/cloudfoundry/go-cfclient/v3/config"
 	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const uaaRequestTimeout = 30 * time.Second
 const defaultDialTimeout = 10 * time.Second
 
+// processesBatchSize is the number of apps whose processes are listed with one request, which keeps the URL short.
+const processesBatchSize = 50
+
 type CFClientWrapper struct {
 	cfClient    *client.Client
 	conf        *Config
@@ -32,6 +37,12 @@ type CFClientWrapper struct {
 	httpClient  *http.Client
 	endpointsMu sync.RWMutex
 	endpoints   *Endpoints
+
+	// tokenVerifier validates user tokens offline and is nil if they are introspected at UAA.
+	tokenVerifier *tokenVerifier
+	// roleCache holds whether users have roles in spaces or organizations and is nil if role decisions are not cached.
+	roleCache    *cache.Cache
+	cacheMetrics *cacheMetrics
 }
 
 type WrapperOption func(*wrapperOptions)
@@ -47,6 +58,7 @@ func WithHTTPClient(client *http.Client) WrapperOption {
 }
 
 var _ CFClient = &CFClientWrapper{}
+var _ prometheus.Collector = &CFClientWrapper{}
 
 func NewCFClientWrapper(conf *Config, logger lager.Logger, opts ...WrapperOption) (*CFClientWrapper, error) {
 	wo := &wrapperOptions{}
@@ -58,6 +70,7 @@ func NewCFClientWrapper(conf *Config, logger lager.Logger, opts ...WrapperOption
 	if httpClient == nil {
 		httpClient = createConfiguredHTTPClient(conf, logger)
 	}
+	httpClient = withTracing(httpClient)
 
 	options := []config.Option{
 		config.UserAgent(GetUserAgent()),
@@ -80,12 +93,33 @@ func NewCFClientWrapper(conf *Config, logger lager.Logger, opts ...WrapperOption
 		return nil, fmt.Errorf("failed to create cfclient: %w", err)
 	}
 
-	return &CFClientWrapper{
-		cfClient:   cfClient,
-		conf:       conf,
-		logger:     logger,
-		httpClient: httpClient,
-	}, nil
+	wrapper := &CFClientWrapper{
+		cfClient:     cfClient,
+		conf:         conf,
+		logger:       logger,
+		httpClient:   httpClient,
+		cacheMetrics: newCacheMetrics(),
+	}
+	if conf.TokenValidation.Offline {
+		refreshInterval := conf.TokenValidation.KeysRefreshInterval
+		if refreshInterval <= 0 {
+			refreshInterval = DefaultTokenKeysRefreshInterval
+		}
+		wrapper.tokenVerifier = newTokenVerifier(httpClient, wrapper.getUaaURL, refreshInterval, wrapper.cacheMetrics, logger)
+	}
+	if conf.TokenValidation.RoleCacheTTL > 0 {
+		wrapper.roleCache = cache.New(conf.TokenValidation.RoleCacheTTL, 2*conf.TokenValidation.RoleCacheTTL)
+	}
+	return wrapper, nil
+}
+
+// Describe and Collect expose the hit and miss counts of the token key and role caches.
+func (w *CFClientWrapper) Describe(ch chan<- *prometheus.Desc) {
+	w.cacheMetrics.Describe(ch)
+}
+
+func (w *CFClientWrapper) Collect(ch chan<- prometheus.Metric) {
+	w.cacheMetrics.Collect(ch)
 }
 
 func createConfiguredHTTPClient(conf *Config, logger lager.Logger) *http.Client {
@@ -126,35 +160,140 @@ func (w *CFClientWrapper) IsUserAdmin(ctx context.Context, userToken string) (bo
 	return isAdmin, nil
 }
 
-func (w *CFClientWrapper) IsUserSpaceDeveloper(ctx context.Context, userToken string, appId Guid) (bool, error) {
+func (w *CFClientWrapper) IsUserAuthenticated(ctx context.Context, userToken string) (bool, error) {
+	resp, err := w.introspectToken(ctx, userToken)
+	if err != nil {
+		return false, err
+	}
+	return resp.Active, nil
+}
+
+// HasUserAppRole returns whether the user has any of the roles in the space of the app or, for organization roles,
+// in the organization of that space. If a role cache TTL is configured, the decision is cached per user and space.
+func (w *CFClientWrapper) HasUserAppRole(ctx context.Context, userToken string, appId Guid, roleTypes ...RoleType) (bool, error) {
 	userId, err := w.getUserId(ctx, userToken)
 	if err != nil {
 		if errors.Is(err, ErrUnauthorized) {
 			w.logger.Error("getUserId: token not authorized", err)
 			return false, nil
 		}
-		return false, fmt.Errorf("failed IsUserSpaceDeveloper for appId(%s): %w", appId, err)
+		return false, fmt.Errorf("failed HasUserAppRole for appId(%s): %w", appId, err)
 	}
 
 	spaceId, err := w.getSpaceId(ctx, appId)
 	if err != nil {
-		return false, fmt.Errorf("failed IsUserSpaceDeveloper for appId(%s): %w", appId, err)
+		return false, fmt.Errorf("failed HasUserAppRole for appId(%s): %w", appId, err)
 	}
 
-	roles, err := w.GetSpaceDeveloperRoles(ctx, spaceId, userId)
+	return w.hasUserSpaceRoleCached(ctx, userId, spaceId, roleTypes)
+}
+
+// HasUserSpaceRole returns whether the user has any of the roles in the space or, for organization roles, in the
+// organization of the space. Decisions are cached like those of HasUserAppRole.
+func (w *CFClientWrapper) HasUserSpaceRole(ctx context.Context, userToken string, spaceId SpaceId, roleTypes ...RoleType) (bool, error) {
+	userId, err := w.getUserId(ctx, userToken)
 	if err != nil {
-		if IsNotFound(err) {
-			w.logger.Info("GetSpaceDeveloperRoles: not found", lager.Data{"userId": userId, "spaceId": spaceId})
+		if errors.Is(err, ErrUnauthorized) {
+			w.logger.Error("getUserId: token not authorized", err)
+			return false, nil
+		}
+		return false, fmt.Errorf("failed HasUserSpaceRole for spaceId(%s): %w", spaceId, err)
+	}
+
+	return w.hasUserSpaceRoleCached(ctx, userId, spaceId, roleTypes)
+}
+
+// HasUserOrgRole returns whether the user has any of the organization roles in the organization. Decisions are
+// cached like those of HasUserAppRole.
+func (w *CFClientWrapper) HasUserOrgRole(ctx context.Context, userToken string, orgId OrgId, roleTypes ...RoleType) (bool, error) {
+	userId, err := w.getUserId(ctx, userToken)
+	if err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			w.logger.Error("getUserId: token not authorized", err)
 			return false, nil
 		}
-		return false, fmt.Errorf("failed IsUserSpaceDeveloper userId(%s), spaceId(%s): %w", userId, spaceId, err)
+		return false, fmt.Errorf("failed HasUserOrgRole for orgId(%s): %w", orgId, err)
 	}
 
-	if !roles.HasRole(RoleSpaceDeveloper) {
-		w.logger.Info("user without SpaceDeveloper role tried to access API")
+	return w.cachedRoleDecision(roleCacheKey(userId, string(orgId), roleTypes), func() (bool, error) {
+		roles, err := w.GetOrgRoles(ctx, orgId, userId, roleTypes...)
+		if err != nil && !IsNotFound(err) {
+			return false, fmt.Errorf("failed HasUserOrgRole userId(%s), orgId(%s): %w", userId, orgId, err)
+		}
+		if roles.HasAnyRole(roleTypes...) {
+			return true, nil
+		}
+		w.logger.Info("user without required role tried to access API", lager.Data{"userId": userId, "orgId": orgId, "roleTypes": roleTypes})
 		return false, nil
+	})
+}
+
+func (w *CFClientWrapper) hasUserSpaceRoleCached(ctx context.Context, userId UserId, spaceId SpaceId, roleTypes []RoleType) (bool, error) {
+	return w.cachedRoleDecision(roleCacheKey(userId, string(spaceId), roleTypes), func() (bool, error) {
+		return w.hasUserSpaceRole(ctx, userId, spaceId, roleTypes)
+	})
+}
+
+// cachedRoleDecision returns the cached role decision for the key, or takes and caches it with hasRole.
+func (w *CFClientWrapper) cachedRoleDecision(cacheKey string, hasRole func() (bool, error)) (bool, error) {
+	if w.roleCache == nil {
+		return hasRole()
+	}
+
+	if decision, found := w.roleCache.Get(cacheKey); found {
+		w.cacheMetrics.hit(cacheRoleDecisions)
+		return decision.(bool), nil
+	}
+	w.cacheMetrics.miss(cacheRoleDecisions)
+
+	decision, err := hasRole()
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	w.roleCache.SetDefault(cacheKey, decision)
+	return decision, nil
+}
+
+func roleCacheKey(userId UserId, scopeId string, roleTypes []RoleType) string {
+	return string(userId) + "|" + scopeId + "|" + strings.Join(roleTypeValues(roleTypes), ",")
+}
+
+func (w *CFClientWrapper) hasUserSpaceRole(ctx context.Context, userId UserId, spaceId SpaceId, roleTypes []RoleType) (bool, error) {
+	var spaceRoleTypes, orgRoleTypes []RoleType
+	for _, roleType := range roleTypes {
+		if roleType.IsOrganizationRole() {
+			orgRoleTypes = append(orgRoleTypes, roleType)
+		} else {
+			spaceRoleTypes = append(spaceRoleTypes, roleType)
+		}
+	}
+
+	if len(spaceRoleTypes) > 0 {
+		roles, err := w.GetSpaceRoles(ctx, spaceId, userId, spaceRoleTypes...)
+		if err != nil && !IsNotFound(err) {
+			return false, fmt.Errorf("failed HasUserAppRole userId(%s), spaceId(%s): %w", userId, spaceId, err)
+		}
+		if roles.HasAnyRole(spaceRoleTypes...) {
+			return true, nil
+		}
+	}
+
+	if len(orgRoleTypes) > 0 {
+		orgId, err := w.getOrgId(ctx, spaceId)
+		if err != nil {
+			return false, fmt.Errorf("failed HasUserAppRole spaceId(%s): %w", spaceId, err)
+		}
+		roles, err := w.GetOrgRoles(ctx, orgId, userId, orgRoleTypes...)
+		if err != nil && !IsNotFound(err) {
+			return false, fmt.Errorf("failed HasUserAppRole userId(%s), orgId(%s): %w", userId, orgId, err)
+		}
+		if roles.HasAnyRole(orgRoleTypes...) {
+			return true, nil
+		}
+	}
+
+	w.logger.Info("user without required role tried to access API", lager.Data{"userId": userId, "spaceId": spaceId, "roleTypes": roleTypes})
+	return false, nil
 }
 
 func (w *CFClientWrapper) IsTokenAuthorized(ctx context.Context, token, clientId string) (bool, error) {
@@ -203,6 +342,18 @@ func (w *CFClientWrapper) doUaaRequest(req *http.Request, result any) error {
 }
 
 func (w *CFClientWrapper) introspectToken(ctx context.Context, token string) (*IntrospectionResponse, error) {
+	if w.tokenVerifier != nil {
+		claims, err := w.tokenVerifier.Verify(ctx, token)
+		if err != nil {
+			if errors.Is(err, ErrInvalidToken) {
+				w.logger.Info("token is invalid", lager.Data{"reason": err.Error()})
+				return &IntrospectionResponse{Active: false}, nil
+			}
+			return nil, fmt.Errorf("verify token failed: %w", err)
+		}
+		return &IntrospectionResponse{Active: true, Email: claims.Email, ClientId: claims.ClientId, Scopes: claims.Scopes}, nil
+	}
+
 	uaaURL, err := w.getUaaURL(ctx)
 	if err != nil {
 		return nil, err
@@ -227,6 +378,20 @@ func (w *CFClientWrapper) introspectToken(ctx context.Context, token string) (*I
 }
 
 func (w *CFClientWrapper) getUserId(ctx context.Context, userToken string) (UserId, error) {
+	if w.tokenVerifier != nil {
+		claims, err := w.tokenVerifier.Verify(ctx, userToken)
+		if err != nil {
+			if errors.Is(err, ErrInvalidToken) {
+				return "", fmt.Errorf("%w: %w", ErrUnauthorized, err)
+			}
+			return "", fmt.Errorf("failed to verify token: %w", err)
+		}
+		if claims.UserId == "" {
+			return "", fmt.Errorf("%w: token has not been issued to a user", ErrUnauthorized)
+		}
+		return UserId(claims.UserId), nil
+	}
+
 	uaaURL, err := w.getUaaURL(ctx)
 	if err != nil {
 		return "", err
@@ -258,6 +423,17 @@ func (w *CFClientWrapper) getSpaceId(ctx context.Context, appId Guid) (SpaceId,
 	return app.Relationships.Space.Data.Guid, nil
 }
 
+func (w *CFClientWrapper) getOrgId(ctx context.Context, spaceId SpaceId) (OrgId, error) {
+	space, err := w.cfClient.Spaces.Get(ctx, string(spaceId))
+	if err != nil {
+		return "", fmt.Errorf("getOrgId failed: %w", MapCFClientError(err))
+	}
+	if space.Relationships == nil || space.Relationships.Organization == nil || space.Relationships.Organization.Data == nil {
+		return "", fmt.Errorf("empty organization-guid for space %s", spaceId)
+	}
+	return OrgId(space.Relationships.Organization.Data.GUID), nil
+}
+
 func (w *CFClientWrapper) GetEndpoints(ctx context.Context) (Endpoints, error) {
 	w.endpointsMu.RLock()
 	if w.endpoints != nil {
@@ -306,6 +482,41 @@ func (w *CFClientWrapper) GetAppProcesses(ctx context.Context, appId Guid, proce
 	return mapResourceProcesses(processes), nil
 }
 
+// GetProcessesOfApps returns the processes of several apps by app. The processes are listed for batches of apps at
+// once, so that the number of requests to the Cloud Controller does not grow with each app. Apps without processes
+// are missing in the result.
+func (w *CFClientWrapper) GetProcessesOfApps(ctx context.Context, appIds []Guid, processTypes ...string) (map[Guid]Processes, error) {
+	result := map[Guid]Processes{}
+	for batch := range slices.Chunk(appIds, processesBatchSize) {
+		opts := &client.ProcessListOptions{AppGUIDs: client.Filter{Values: guidValues(batch)}}
+		if len(processTypes) > 0 {
+			opts.Types = client.Filter{Values: processTypes}
+		}
+
+		processes, err := w.cfClient.Processes.ListAll(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed GetProcessesOfApps: %w", MapCFClientError(err))
+		}
+
+		for _, process := range processes {
+			if process.Relationships.App.Data == nil {
+				continue
+			}
+			appId := Guid(process.Relationships.App.Data.GUID)
+			result[appId] = append(result[appId], mapResourceProcess(process))
+		}
+	}
+	return result, nil
+}
+
+func guidValues(guids []Guid) []string {
+	values := make([]string, len(guids))
+	for i, guid := range guids {
+		values[i] = string(guid)
+	}
+	return values
+}
+
 func (w *CFClientWrapper) GetAppAndProcesses(ctx context.Context, appId Guid) (*AppAndProcesses, error) {
 	var wg sync.WaitGroup
 	var app *App
@@ -370,21 +581,88 @@ func (w *CFClientWrapper) GetServicePlan(ctx context.Context, guid string) (*Ser
 	return mapResourceServicePlan(sp), nil
 }
 
-func (w *CFClientWrapper) GetSpaceDeveloperRoles(ctx context.Context, spaceId SpaceId, userId UserId) (Roles, error) {
+func (w *CFClientWrapper) GetAppQuotaUsage(ctx context.Context, appId Guid) (*AppQuotaUsage, error) {
+	spaceId, err := w.getSpaceId(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed GetAppQuotaUsage for appId(%s): %w", appId, err)
+	}
+
+	space, org, err := w.cfClient.Spaces.GetIncludeOrganization(ctx, string(spaceId))
+	if err != nil {
+		return nil, fmt.Errorf("failed GetAppQuotaUsage spaceId(%s): %w", spaceId, MapCFClientError(err))
+	}
+
+	orgUsage, err := w.cfClient.Organizations.GetUsageSummary(ctx, org.GUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed GetAppQuotaUsage orgId(%s): %w", org.GUID, MapCFClientError(err))
+	}
+	result := &AppQuotaUsage{Org: mapResourceUsageSummary(orgUsage.UsageSummary)}
+
+	if org.Relationships.Quota.Data != nil {
+		orgQuota, err := w.cfClient.OrganizationQuotas.Get(ctx, org.Relationships.Quota.Data.GUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed GetAppQuotaUsage orgId(%s): %w", org.GUID, MapCFClientError(err))
+		}
+		result.Org.TotalInstances = orgQuota.Apps.TotalInstances
+		result.Org.TotalMemoryInMb = orgQuota.Apps.TotalMemoryInMB
+	}
+
+	if space.Relationships != nil && space.Relationships.Quota != nil && space.Relationships.Quota.Data != nil {
+		spaceQuota, err := w.cfClient.SpaceQuotas.Get(ctx, space.Relationships.Quota.Data.GUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed GetAppQuotaUsage spaceId(%s): %w", spaceId, MapCFClientError(err))
+		}
+		spaceUsage, err := w.cfClient.Spaces.GetUsageSummary(ctx, string(spaceId))
+		if err != nil {
+			return nil, fmt.Errorf("failed GetAppQuotaUsage spaceId(%s): %w", spaceId, MapCFClientError(err))
+		}
+		usage := mapResourceUsageSummary(spaceUsage.UsageSummary)
+		usage.TotalInstances = spaceQuota.Apps.TotalInstances
+		usage.TotalMemoryInMb = spaceQuota.Apps.TotalMemoryInMB
+		result.Space = &usage
+	}
+
+	return result, nil
+}
+
+func (w *CFClientWrapper) GetSpaceRoles(ctx context.Context, spaceId SpaceId, userId UserId, roleTypes ...RoleType) (Roles, error) {
 	opts := &client.RoleListOptions{
-		Types:      client.Filter{Values: []string{string(RoleSpaceDeveloper)}},
+		Types:      client.Filter{Values: roleTypeValues(roleTypes)},
 		SpaceGUIDs: client.Filter{Values: []string{string(spaceId)}},
 		UserGUIDs:  client.Filter{Values: []string{string(userId)}},
 	}
 
 	roles, err := w.cfClient.Roles.ListAll(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed GetSpaceDeveloperRoles spaceId(%s) userId(%s): %w", spaceId, userId, MapCFClientError(err))
+		return nil, fmt.Errorf("failed GetSpaceRoles spaceId(%s) userId(%s): %w", spaceId, userId, MapCFClientError(err))
 	}
 
 	return mapResourceRoles(roles), nil
 }
 
+func (w *CFClientWrapper) GetOrgRoles(ctx context.Context, orgId OrgId, userId UserId, roleTypes ...RoleType) (Roles, error) {
+	opts := &client.RoleListOptions{
+		Types:             client.Filter{Values: roleTypeValues(roleTypes)},
+		OrganizationGUIDs: client.Filter{Values: []string{string(orgId)}},
+		UserGUIDs:         client.Filter{Values: []string{string(userId)}},
+	}
+
+	roles, err := w.cfClient.Roles.ListAll(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed GetOrgRoles orgId(%s) userId(%s): %w", orgId, userId, MapCFClientError(err))
+	}
+
+	return mapResourceRoles(roles), nil
+}
+
+func roleTypeValues(roleTypes []RoleType) []string {
+	values := make([]string, len(roleTypes))
+	for i, roleType := range roleTypes {
+		values[i] = string(roleType)
+	}
+	return values
+}
+
 func mapRootToEndpoints(root *resource.Root) Endpoints {
 	return Endpoints{
 		CloudControllerV3: Href{Url: root.Links.CloudControllerV3.Href},
@@ -472,6 +750,13 @@ func mapResourceServicePlan(sp *resource.ServicePlan) *ServicePlan {
 	}
 }
 
+func mapResourceUsageSummary(summary resource.UsageSummary) QuotaUsage {
+	return QuotaUsage{
+		StartedInstances: summary.StartedInstances,
+		MemoryInMb:       summary.MemoryInMb,
+	}
+}
+
 func mapResourceRoles(roles []*resource.Role) Roles {
 	result := make(Roles, len(roles))
 	for i, r := range roles {
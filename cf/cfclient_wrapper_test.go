@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf/mocks"
 	"code.cloudfoundry.org/lager/v3"
+	"github.com/prometheus/client_golang/prometheus"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -140,6 +144,35 @@ var _ = Describe("CFClientWrapper", func() {
 		})
 	})
 
+	Describe("GetProcessesOfApps", func() {
+		It("returns the processes by app", func() {
+			mockServer.Add().ProcessesOfApps(map[string]int{"app-1": 2, "app-2": 3})
+
+			processes, err := client.GetProcessesOfApps(ctx, []cf.Guid{"app-1", "app-2", "app-3"}, cf.ProcessTypeWeb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processes).To(HaveLen(2))
+			Expect(processes["app-1"].GetInstances()).To(Equal(2))
+			Expect(processes["app-2"].GetInstances()).To(Equal(3))
+			Expect(mockServer.Count().Requests(`^/v3/processes\?.*types=web`)).To(Equal(1))
+		})
+
+		It("lists the processes of many apps in batches", func() {
+			appIds := []cf.Guid{}
+			instancesByApp := map[string]int{}
+			for i := range 120 {
+				appId := fmt.Sprintf("app-%d", i)
+				appIds = append(appIds, cf.Guid(appId))
+				instancesByApp[appId] = 1
+			}
+			mockServer.Add().ProcessesOfApps(instancesByApp)
+
+			processes, err := client.GetProcessesOfApps(ctx, appIds)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processes).To(HaveLen(120))
+			Expect(mockServer.Count().Requests(`^/v3/processes`)).To(Equal(3))
+		})
+	})
+
 	Describe("GetAppAndProcesses", func() {
 		It("returns both app and processes", func() {
 			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
@@ -162,6 +195,18 @@ var _ = Describe("CFClientWrapper", func() {
 			err := client.ScaleAppWebProcess(ctx, "test-app-guid", 5)
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("sets the request id of the context", func() {
+			mockServer.Add().GetAppProcesses(2)
+			mockServer.Add().ScaleAppWebProcess()
+
+			err := client.ScaleAppWebProcess(cf.ContextWithRequestId(ctx, "a-decision-id"), "test-app-guid", 5)
+			Expect(err).NotTo(HaveOccurred())
+
+			requests := mockServer.ReceivedRequests()
+			Expect(requests[len(requests)-1].URL.Path).To(HaveSuffix("/actions/scale"))
+			Expect(requests[len(requests)-1].Header.Get("X-Vcap-Request-Id")).To(Equal("a-decision-id"))
+		})
 	})
 
 	Describe("GetServiceInstance", func() {
@@ -187,6 +232,45 @@ var _ = Describe("CFClientWrapper", func() {
 		})
 	})
 
+	Describe("GetAppQuotaUsage", func() {
+		BeforeEach(func() {
+			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
+		})
+
+		It("returns the org quota usage when no space quota is assigned", func() {
+			mockServer.Add().QuotaUsage(
+				map[string]any{"total_instances": 10, "total_memory_in_mb": nil},
+				map[string]any{"started_instances": 7, "memory_in_mb": 1024},
+				"", nil, nil)
+
+			usage, err := client.GetAppQuotaUsage(ctx, "test-app-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage.Org.TotalInstances).To(HaveValue(Equal(10)))
+			Expect(usage.Org.TotalMemoryInMb).To(BeNil())
+			Expect(usage.Org.StartedInstances).To(Equal(7))
+			Expect(usage.Org.MemoryInMb).To(Equal(1024))
+			Expect(usage.Space).To(BeNil())
+		})
+
+		It("returns the org and space quota usage", func() {
+			mockServer.Add().QuotaUsage(
+				map[string]any{"total_instances": nil, "total_memory_in_mb": 10240},
+				map[string]any{"started_instances": 7, "memory_in_mb": 2048},
+				"space-quota-guid",
+				map[string]any{"total_instances": 5, "total_memory_in_mb": 4096},
+				map[string]any{"started_instances": 3, "memory_in_mb": 1536})
+
+			usage, err := client.GetAppQuotaUsage(ctx, "test-app-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage.Org.TotalInstances).To(BeNil())
+			Expect(usage.Org.TotalMemoryInMb).To(HaveValue(Equal(10240)))
+			Expect(usage.Space).NotTo(BeNil())
+			Expect(usage.Space.TotalInstances).To(HaveValue(Equal(5)))
+			Expect(usage.Space.StartedInstances).To(Equal(3))
+			Expect(usage.Space.MemoryInMb).To(Equal(1536))
+		})
+	})
+
 	Describe("IsUserAdmin", func() {
 		It("returns true when user has admin scope", func() {
 			mockServer.Add().Introspect([]string{"cloud_controller.admin", "openid"})
@@ -205,15 +289,37 @@ var _ = Describe("CFClientWrapper", func() {
 		})
 	})
 
-	Describe("IsUserSpaceDeveloper", func() {
+	Describe("IsUserAuthenticated", func() {
+		It("returns true when the token is active", func() {
+			mockServer.RouteToHandler(http.MethodPost, "/introspect",
+				RespondWithJSON(http.StatusOK, map[string]any{"active": true}))
+
+			isAuthenticated, err := client.IsUserAuthenticated(ctx, "user-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAuthenticated).To(BeTrue())
+		})
+
+		It("returns false when the token is inactive", func() {
+			mockServer.RouteToHandler(http.MethodPost, "/introspect",
+				RespondWithJSON(http.StatusOK, map[string]any{"active": false}))
+
+			isAuthenticated, err := client.IsUserAuthenticated(ctx, "user-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAuthenticated).To(BeFalse())
+		})
+	})
+
+	Describe("HasUserAppRole", func() {
 		It("returns true when user is space developer", func() {
 			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
 			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
 			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleSpaceDeveloper})
 
-			isSpaceDev, err := client.IsUserSpaceDeveloper(ctx, "user-token", "test-app-guid")
+			hasRole, err := client.HasUserAppRole(ctx, "user-token", "test-app-guid", cf.RoleSpaceDeveloper)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(isSpaceDev).To(BeTrue())
+			Expect(hasRole).To(BeTrue())
+			Expect(mockServer.Count().Requests(`^/v3/roles\?.*space_guids=test-space-guid`)).To(Equal(1))
+			Expect(mockServer.Count().Requests(`^/v3/spaces/`)).To(Equal(0))
 		})
 
 		It("returns false when user is not space developer", func() {
@@ -221,18 +327,305 @@ var _ = Describe("CFClientWrapper", func() {
 			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
 			mockServer.Add().Roles(http.StatusOK) // No roles
 
-			isSpaceDev, err := client.IsUserSpaceDeveloper(ctx, "user-token", "test-app-guid")
+			hasRole, err := client.HasUserAppRole(ctx, "user-token", "test-app-guid", cf.RoleSpaceDeveloper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeFalse())
+		})
+
+		It("returns true when user has one of several space roles", func() {
+			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
+			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
+			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleSpaceAuditor})
+
+			hasRole, err := client.HasUserAppRole(ctx, "user-token", "test-app-guid", cf.RoleSpaceDeveloper, cf.RoleSpaceAuditor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeTrue())
+			Expect(mockServer.Count().Requests(`^/v3/roles\?.*types=space_developer%2Cspace_auditor`)).To(Equal(1))
+		})
+
+		It("looks up organization roles in the organization of the space", func() {
+			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
+			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
+			mockServer.Add().Space("test-org-guid")
+			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleOrganizationManager})
+
+			hasRole, err := client.HasUserAppRole(ctx, "user-token", "test-app-guid", cf.RoleSpaceAuditor, cf.RoleOrganizationManager)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(isSpaceDev).To(BeFalse())
+			Expect(hasRole).To(BeTrue())
+			Expect(mockServer.Count().Requests(`^/v3/roles\?.*organization_guids=test-org-guid`)).To(Equal(1))
 		})
 
 		It("returns false without error when token is unauthorized", func() {
 			mockServer.Add().UserInfo(http.StatusUnauthorized, "")
 
-			isSpaceDev, err := client.IsUserSpaceDeveloper(ctx, "invalid-token", "test-app-guid")
+			hasRole, err := client.HasUserAppRole(ctx, "invalid-token", "test-app-guid", cf.RoleSpaceDeveloper)
 			// ErrUnauthorized is handled gracefully - returns false without error
 			Expect(err).NotTo(HaveOccurred())
-			Expect(isSpaceDev).To(BeFalse())
+			Expect(hasRole).To(BeFalse())
+		})
+	})
+
+	Describe("HasUserSpaceRole", func() {
+		It("returns true when user has one of the roles in the space", func() {
+			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
+			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleSpaceAuditor})
+
+			hasRole, err := client.HasUserSpaceRole(ctx, "user-token", "test-space-guid", cf.AppReaderRoles...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeTrue())
+			Expect(mockServer.Count().Requests(`^/v3/roles\?.*space_guids=test-space-guid`)).To(Equal(1))
+			Expect(mockServer.Count().Requests(`^/v3/apps/`)).To(Equal(0))
+		})
+
+		It("returns false when user has no role in the space", func() {
+			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
+			mockServer.Add().Roles(http.StatusOK)
+
+			hasRole, err := client.HasUserSpaceRole(ctx, "user-token", "test-space-guid", cf.RoleSpaceDeveloper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeFalse())
+		})
+
+		It("returns false without error when token is unauthorized", func() {
+			mockServer.Add().UserInfo(http.StatusUnauthorized, "")
+
+			hasRole, err := client.HasUserSpaceRole(ctx, "invalid-token", "test-space-guid", cf.RoleSpaceDeveloper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeFalse())
+		})
+	})
+
+	Describe("HasUserOrgRole", func() {
+		It("returns true when user has one of the roles in the organization", func() {
+			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
+			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleOrganizationManager})
+
+			hasRole, err := client.HasUserOrgRole(ctx, "user-token", "test-org-guid", cf.OrgAppReaderRoles...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeTrue())
+			Expect(mockServer.Count().Requests(`^/v3/roles\?.*organization_guids=test-org-guid`)).To(Equal(1))
+		})
+
+		It("returns false when user has no role in the organization", func() {
+			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
+			mockServer.Add().Roles(http.StatusOK)
+
+			hasRole, err := client.HasUserOrgRole(ctx, "user-token", "test-org-guid", cf.RoleOrganizationManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeFalse())
+		})
+
+		It("returns false without error when token is unauthorized", func() {
+			mockServer.Add().UserInfo(http.StatusUnauthorized, "")
+
+			hasRole, err := client.HasUserOrgRole(ctx, "invalid-token", "test-org-guid", cf.RoleOrganizationManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeFalse())
+		})
+	})
+
+	Describe("with offline token validation", func() {
+		var tokenIssuer *mocks.TokenIssuer
+
+		BeforeEach(func() {
+			conf.TokenValidation = cf.TokenValidationConfig{Offline: true, KeysRefreshInterval: time.Hour}
+			tokenIssuer = mockServer.Add().TokenIssuer()
+		})
+
+		It("validates tokens without introspecting them and caches the token keys", func() {
+			token := tokenIssuer.UserToken("test-user-id", cf.CCAdminScope)
+
+			isAdmin, err := client.IsUserAdmin(ctx, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAdmin).To(BeTrue())
+
+			isAuthenticated, err := client.IsUserAuthenticated(ctx, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAuthenticated).To(BeTrue())
+
+			isAuthorized, err := client.IsTokenAuthorized(ctx, token, "cf")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAuthorized).To(BeTrue())
+
+			Expect(mockServer.Count().Requests(`^/introspect`)).To(Equal(0))
+			Expect(mockServer.Count().Requests(`^/token_keys`)).To(Equal(1))
+			Expect(cacheCount(client, "autoscaler_cf_client_cache_misses_total", "token_keys")).To(Equal(1.0))
+			Expect(cacheCount(client, "autoscaler_cf_client_cache_hits_total", "token_keys")).To(Equal(2.0))
+		})
+
+		It("takes the user id from the token", func() {
+			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
+			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleSpaceDeveloper})
+
+			hasRole, err := client.HasUserAppRole(ctx, tokenIssuer.UserToken("test-user-id"), "test-app-guid", cf.RoleSpaceDeveloper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasRole).To(BeTrue())
+			Expect(mockServer.Count().Requests(`^/userinfo`)).To(Equal(0))
+			Expect(mockServer.Count().Requests(`^/v3/roles\?.*user_guids=test-user-id`)).To(Equal(1))
+		})
+
+		It("fetches the token keys again after they have been rotated", func() {
+			_, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+			Expect(err).NotTo(HaveOccurred())
+
+			tokenIssuer.RotateKey()
+			isAuthenticated, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAuthenticated).To(BeTrue())
+			Expect(mockServer.Count().Requests(`^/token_keys`)).To(Equal(2))
+		})
+
+		Context("when the token keys are older than the refresh interval", func() {
+			BeforeEach(func() {
+				conf.TokenValidation.KeysRefreshInterval = time.Nanosecond
+			})
+
+			It("fetches the token keys again in the background", func() {
+				for range 2 {
+					isAuthenticated, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(isAuthenticated).To(BeTrue())
+				}
+				Eventually(func() int { return mockServer.Count().Requests(`^/token_keys`) }).Should(Equal(2))
+			})
+
+			It("keeps using the outdated keys and backs off when the token keys cannot be fetched", func() {
+				_, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+				Expect(err).NotTo(HaveOccurred())
+
+				mockServer.RouteToHandler(http.MethodGet, "/token_keys", RespondWithJSON(http.StatusInternalServerError, map[string]any{}))
+				for range 3 {
+					isAuthenticated, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(isAuthenticated).To(BeTrue())
+				}
+				Eventually(func() int { return mockServer.Count().Requests(`^/token_keys`) }).Should(Equal(2))
+
+				isAuthenticated, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(isAuthenticated).To(BeTrue())
+				Consistently(func() int { return mockServer.Count().Requests(`^/token_keys`) }, 200*time.Millisecond).Should(Equal(2))
+			})
+
+			It("does not wait for the token keys when UAA hangs", func() {
+				_, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+				Expect(err).NotTo(HaveOccurred())
+
+				release := make(chan struct{})
+				mockServer.RouteToHandler(http.MethodGet, "/token_keys", func(w http.ResponseWriter, _ *http.Request) {
+					select {
+					case <-release:
+					case <-time.After(10 * time.Second):
+					}
+					w.WriteHeader(http.StatusInternalServerError)
+				})
+				defer close(release)
+
+				for range 3 {
+					start := time.Now()
+					isAuthenticated, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(isAuthenticated).To(BeTrue())
+					Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+				}
+				Eventually(func() int { return mockServer.Count().Requests(`^/token_keys`) }).Should(Equal(2))
+				Consistently(func() int { return mockServer.Count().Requests(`^/token_keys`) }, 200*time.Millisecond).Should(Equal(2))
+			})
+		})
+
+		It("rejects tokens signed with an unknown key without fetching the token keys repeatedly", func() {
+			_, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+			Expect(err).NotTo(HaveOccurred())
+
+			for range 3 {
+				isAuthenticated, err := client.IsUserAuthenticated(ctx, tokenIssuer.ForeignToken(map[string]any{
+					"iss": tokenIssuer.Issuer, "user_id": "test-user-id", "exp": time.Now().Add(time.Hour).Unix(),
+				}))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(isAuthenticated).To(BeFalse())
+			}
+			Expect(mockServer.Count().Requests(`^/token_keys`)).To(Equal(2))
+		})
+
+		DescribeTable("rejects invalid tokens",
+			func(token func() string) {
+				isAuthenticated, err := client.IsUserAuthenticated(ctx, token())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(isAuthenticated).To(BeFalse())
+
+				hasRole, err := client.HasUserAppRole(ctx, token(), "test-app-guid", cf.RoleSpaceDeveloper)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasRole).To(BeFalse())
+			},
+			Entry("malformed", func() string { return "not-a-token" }),
+			Entry("expired", func() string {
+				return tokenIssuer.Token(map[string]any{"iss": tokenIssuer.Issuer, "user_id": "test-user-id", "exp": time.Now().Add(-time.Minute).Unix()})
+			}),
+			Entry("not yet valid", func() string {
+				return tokenIssuer.Token(map[string]any{"iss": tokenIssuer.Issuer, "user_id": "test-user-id",
+					"nbf": time.Now().Add(time.Hour).Unix(), "exp": time.Now().Add(2 * time.Hour).Unix()})
+			}),
+			Entry("from another issuer", func() string {
+				return tokenIssuer.Token(map[string]any{"iss": "https://other-uaa/oauth/token", "user_id": "test-user-id", "exp": time.Now().Add(time.Hour).Unix()})
+			}),
+			Entry("with a forged signature", func() string {
+				token := tokenIssuer.UserToken("test-user-id")
+				return token[:strings.LastIndex(token, ".")] + ".c2lnbmF0dXJl"
+			}),
+		)
+
+		It("fails if the token keys cannot be fetched", func() {
+			mockServer.RouteToHandler(http.MethodGet, "/token_keys", RespondWithJSON(http.StatusInternalServerError, map[string]any{}))
+
+			_, err := client.IsUserAuthenticated(ctx, tokenIssuer.UserToken("test-user-id"))
+			Expect(err).To(MatchError(ContainSubstring("failed to get token keys")))
+		})
+	})
+
+	Describe("with a role cache", func() {
+		BeforeEach(func() {
+			conf.TokenValidation = cf.TokenValidationConfig{RoleCacheTTL: time.Minute}
+			mockServer.Add().UserInfo(http.StatusOK, "test-user-id")
+			mockServer.Add().GetApp("STARTED", http.StatusOK, "test-space-guid")
+		})
+
+		It("caches the role decisions per user and space", func() {
+			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleSpaceDeveloper})
+
+			for range 3 {
+				hasRole, err := client.HasUserAppRole(ctx, "user-token", "test-app-guid", cf.RoleSpaceDeveloper)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasRole).To(BeTrue())
+			}
+			Expect(mockServer.Count().Requests(`^/v3/roles`)).To(Equal(1))
+			Expect(cacheCount(client, "autoscaler_cf_client_cache_misses_total", "role_decisions")).To(Equal(1.0))
+			Expect(cacheCount(client, "autoscaler_cf_client_cache_hits_total", "role_decisions")).To(Equal(2.0))
+
+			_, err := client.HasUserAppRole(ctx, "user-token", "test-app-guid", cf.AppReaderRoles...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockServer.Count().Requests(`^/v3/roles`)).To(Equal(2))
+		})
+
+		It("caches the role decisions per user and organization", func() {
+			mockServer.Add().Roles(http.StatusOK, cf.Role{Guid: "role-guid", Type: cf.RoleOrganizationManager})
+
+			for range 2 {
+				hasRole, err := client.HasUserOrgRole(ctx, "user-token", "test-org-guid", cf.OrgAppReaderRoles...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasRole).To(BeTrue())
+			}
+			Expect(mockServer.Count().Requests(`^/v3/roles`)).To(Equal(1))
+			Expect(cacheCount(client, "autoscaler_cf_client_cache_hits_total", "role_decisions")).To(Equal(1.0))
+		})
+
+		It("does not cache failed role lookups", func() {
+			mockServer.Add().Roles(http.StatusInternalServerError)
+
+			for range 2 {
+				_, err := client.HasUserAppRole(ctx, "user-token", "test-app-guid", cf.RoleSpaceDeveloper)
+				Expect(err).To(HaveOccurred())
+			}
+			Expect(mockServer.Count().Requests(`^/v3/roles`)).To(Equal(2))
 		})
 	})
 
@@ -314,6 +707,26 @@ var _ = Describe("CFClientWrapper", func() {
 	})
 })
 
+func cacheCount(client cf.CFClient, metricName string, cache string) float64 {
+	registry := prometheus.NewRegistry()
+	Expect(registry.Register(client.(prometheus.Collector))).To(Succeed())
+	metricFamilies, err := registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != metricName {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "cache" && label.GetValue() == cache {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
 func RespondWithJSON(statusCode int, body any) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -30,15 +30,20 @@ type (
 	CFClient interface {
 		Login(ctx context.Context) error
 		IsUserAdmin(ctx context.Context, userToken string) (bool, error)
-		IsUserSpaceDeveloper(ctx context.Context, userToken string, appId Guid) (bool, error)
+		IsUserAuthenticated(ctx context.Context, userToken string) (bool, error)
+		HasUserAppRole(ctx context.Context, userToken string, appId Guid, roleTypes ...RoleType) (bool, error)
+		HasUserSpaceRole(ctx context.Context, userToken string, spaceId SpaceId, roleTypes ...RoleType) (bool, error)
+		HasUserOrgRole(ctx context.Context, userToken string, orgId OrgId, roleTypes ...RoleType) (bool, error)
 		IsTokenAuthorized(ctx context.Context, token, clientId string) (bool, error)
 		GetEndpoints(ctx context.Context) (Endpoints, error)
 		GetApp(ctx context.Context, appId Guid) (*App, error)
 		GetAppProcesses(ctx context.Context, appId Guid, processTypes ...string) (Processes, error)
 		GetAppAndProcesses(ctx context.Context, appId Guid) (*AppAndProcesses, error)
+		GetProcessesOfApps(ctx context.Context, appIds []Guid, processTypes ...string) (map[Guid]Processes, error)
 		ScaleAppWebProcess(ctx context.Context, appId Guid, numberOfProcesses int) error
 		GetServiceInstance(ctx context.Context, serviceInstanceGuid string) (*ServiceInstance, error)
 		GetServicePlan(ctx context.Context, servicePlanGuid string) (*ServicePlan, error)
+		GetAppQuotaUsage(ctx context.Context, appId Guid) (*AppQuotaUsage, error)
 	}
 )
 
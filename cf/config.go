@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
+const DefaultTokenKeysRefreshInterval = 10 * time.Minute
+
 type ClientConfig struct {
 	MaxRetries              int   `yaml:"max_retries" json:"max_retries,omitempty"`
 	MaxRetryWaitMs          int64 `yaml:"max_retry_wait_ms" json:"max_retry_wait_ms"`
@@ -14,14 +17,25 @@ type ClientConfig struct {
 	SkipSSLValidation       bool  `yaml:"skip_ssl_validation" json:"skip_ssl_validation"`
 }
 
+// TokenValidationConfig determines how user tokens are validated and how long role decisions are cached.
+type TokenValidationConfig struct {
+	// Offline validates tokens locally against the token keys of UAA instead of introspecting them at UAA.
+	Offline bool `yaml:"offline" json:"offline"`
+	// KeysRefreshInterval is the maximum age of the cached token keys of UAA.
+	KeysRefreshInterval time.Duration `yaml:"keys_refresh_interval" json:"keys_refresh_interval"`
+	// RoleCacheTTL is how long whether a user has a role in a space is cached. Zero disables the cache.
+	RoleCacheTTL time.Duration `yaml:"role_cache_ttl" json:"role_cache_ttl"`
+}
+
 type Config struct {
-	ClientConfig `yaml:",inline"`
-	API          string `yaml:"api" json:"api"`
-	ClientID     string `yaml:"client_id" json:"client_id"`
-	Secret       string `yaml:"secret" json:"secret"`
-	GrantType    string `yaml:"grant_type" json:"grant_type"`
-	Username     string `yaml:"username" json:"username"`
-	Password     string `yaml:"password" json:"password"`
+	ClientConfig    `yaml:",inline"`
+	API             string                `yaml:"api" json:"api"`
+	ClientID        string                `yaml:"client_id" json:"client_id"`
+	Secret          string                `yaml:"secret" json:"secret"`
+	GrantType       string                `yaml:"grant_type" json:"grant_type"`
+	Username        string                `yaml:"username" json:"username"`
+	Password        string                `yaml:"password" json:"password"`
+	TokenValidation TokenValidationConfig `yaml:"token_validation" json:"token_validation"`
 }
 
 func (conf *Config) IsPasswordGrant() bool {
@@ -50,6 +64,13 @@ func (conf *Config) Validate() error {
 	apiURL.Path = strings.TrimSuffix(apiURL.Path, "/")
 	conf.API = apiURL.String()
 
+	if conf.TokenValidation.KeysRefreshInterval < 0 || conf.TokenValidation.RoleCacheTTL < 0 {
+		return fmt.Errorf("Configuration error: token_validation intervals must not be negative")
+	}
+	if conf.TokenValidation.Offline && conf.TokenValidation.KeysRefreshInterval == 0 {
+		conf.TokenValidation.KeysRefreshInterval = DefaultTokenKeysRefreshInterval
+	}
+
 	if conf.IsPasswordGrant() {
 		return conf.validatePasswordGrant()
 	}
@@ -1,6 +1,8 @@
 package cf_test
 
 import (
+	"time"
+
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"go.yaml.in/yaml/v4"
 
@@ -33,6 +35,10 @@ max_retries: 3
 max_retry_wait_ms: 27
 max_idle_conns_per_host_ms: 200
 idle_connection_timeout_ms: 200
+token_validation:
+  offline: true
+  keys_refresh_interval: 5m
+  role_cache_ttl: 30s
 `
 			})
 			It("should deserialise correctly", func() {
@@ -48,6 +54,11 @@ idle_connection_timeout_ms: 200
 						MaxIdleConnsPerHost:     200,
 						IdleConnectionTimeoutMs: 200,
 					},
+					TokenValidation: cf.TokenValidationConfig{
+						Offline:             true,
+						KeysRefreshInterval: 5 * time.Minute,
+						RoleCacheTTL:        30 * time.Second,
+					},
 				}))
 			})
 		})
@@ -180,5 +191,26 @@ idle_connection_timeout_ms: 200
 			})
 		})
 
+		Context("when tokens are validated offline without a keys refresh interval", func() {
+			BeforeEach(func() {
+				conf.TokenValidation.Offline = true
+			})
+
+			It("should default the keys refresh interval", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(conf.TokenValidation.KeysRefreshInterval).To(Equal(cf.DefaultTokenKeysRefreshInterval))
+			})
+		})
+
+		Context("when the role cache ttl is negative", func() {
+			BeforeEach(func() {
+				conf.TokenValidation.RoleCacheTTL = -time.Second
+			})
+
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: token_validation intervals must not be negative"))
+			})
+		})
+
 	})
 })
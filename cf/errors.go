@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/cloudfoundry/go-cfclient/v3/resource"
@@ -16,6 +17,13 @@ const (
 	cfNotAuthorised    = 10003
 )
 
+// Validation keys the cloud controller reports in the error detail if scaling a process would
+// exceed the org or space quota, e.g. "memory quota_exceeded, app_instance_limit quota_exceeded".
+const (
+	cfOrgQuotaExceeded   = "quota_exceeded"
+	cfSpaceQuotaExceeded = "space_quota_exceeded"
+)
+
 var (
 	ErrUnauthorized       = errors.New("Unauthorized")
 	ErrInvalidTokenFormat = errors.New("invalid token format")
@@ -110,6 +118,27 @@ func (c *CfError) IsNotAuthenticated() bool {
 	return c.ContainsError(cfNotAuthenticated)
 }
 
+func (c *CfError) IsOrgQuotaExceeded() bool {
+	return c.containsDetailKey(cfOrgQuotaExceeded)
+}
+
+func (c *CfError) IsSpaceQuotaExceeded() bool {
+	return c.containsDetailKey(cfSpaceQuotaExceeded)
+}
+
+func (c *CfError) containsDetailKey(key string) bool {
+	if !c.IsValid() {
+		return false
+	}
+	for _, item := range c.Errors {
+		fields := strings.FieldsFunc(item.Detail, func(r rune) bool { return r == ' ' || r == ',' })
+		if slices.Contains(fields, key) {
+			return true
+		}
+	}
+	return false
+}
+
 func truncateString(stringToTrunk string, length int) string {
 	if len(stringToTrunk) > length {
 		return stringToTrunk[:length]
@@ -122,6 +151,16 @@ func IsNotFound(err error) bool {
 	return errors.As(err, &cfError) && cfError.IsNotFound()
 }
 
+func IsOrgQuotaExceeded(err error) bool {
+	var cfError *CfError
+	return errors.As(err, &cfError) && cfError.IsOrgQuotaExceeded()
+}
+
+func IsSpaceQuotaExceeded(err error) bool {
+	var cfError *CfError
+	return errors.As(err, &cfError) && cfError.IsSpaceQuotaExceeded()
+}
+
 func MapCFClientError(err error) error {
 	if err == nil {
 		return nil
@@ -77,6 +77,24 @@ var _ = Describe("Errors test", func() {
 				Expect(cfError.IsNotAuthenticated()).To(BeTrue())
 			})
 		})
+		Context("org quota exceeded", func() {
+			BeforeEach(func() {
+				errorResponse = `{"errors": [{"detail": "memory quota_exceeded, app_instance_limit quota_exceeded","title": "CF-UnprocessableEntity","code": 10008}]}`
+			})
+			It("Should return true for IsOrgQuotaExceeded()", func() {
+				Expect(cfError.IsOrgQuotaExceeded()).To(BeTrue())
+				Expect(cfError.IsSpaceQuotaExceeded()).To(BeFalse())
+			})
+		})
+		Context("space quota exceeded", func() {
+			BeforeEach(func() {
+				errorResponse = `{"errors": [{"detail": "app_instance_limit space_quota_exceeded","title": "CF-UnprocessableEntity","code": 10008}]}`
+			})
+			It("Should return true for IsSpaceQuotaExceeded()", func() {
+				Expect(cfError.IsSpaceQuotaExceeded()).To(BeTrue())
+				Expect(cfError.IsOrgQuotaExceeded()).To(BeFalse())
+			})
+		})
 		Context("There is one error", func() {
 			BeforeEach(func() { errorResponse = `{"errors": [{"code": 1,"title": "Title","detail": "Detail"}]}` })
 			It("Should have the right message", func() {
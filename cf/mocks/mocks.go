@@ -3,6 +3,7 @@ package mocks
 import (
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
@@ -110,6 +111,28 @@ func (a AddMock) GetAppProcesses(processes int) AddMock {
 	return a
 }
 
+// ProcessesOfApps lists a web process with the given instances for each of the apps requested with app_guids.
+func (a AddMock) ProcessesOfApps(instancesByApp map[string]int) AddMock {
+	a.server.RouteToHandler("GET", "/v3/processes", func(w http.ResponseWriter, r *http.Request) {
+		resources := []map[string]any{}
+		for _, appGuid := range strings.Split(r.URL.Query().Get("app_guids"), ",") {
+			if instances, ok := instancesByApp[appGuid]; ok {
+				resources = append(resources, map[string]any{
+					"guid":          appGuid + "-web",
+					"type":          "web",
+					"instances":     instances,
+					"relationships": map[string]any{"app": map[string]any{"data": map[string]any{"guid": appGuid}}},
+				})
+			}
+		}
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]any{
+			"pagination": map[string]any{"total_results": len(resources), "total_pages": 1},
+			"resources":  resources,
+		})(w, r)
+	})
+	return a
+}
+
 func (a AddMock) Info(url string) AddMock {
 	a.server.RouteToHandler("GET", "/", ghttp.RespondWithJSONEncoded(http.StatusOK, cf.EndpointsResponse{
 		Links: cf.Endpoints{
@@ -132,6 +155,17 @@ func (a AddMock) Roles(statusCode int, roles ...cf.Role) AddMock {
 	return a
 }
 
+func (a AddMock) Space(orgGuid string) AddMock {
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/spaces/[^/]+$`),
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]any{
+			"guid": "mock-space-guid",
+			"relationships": map[string]any{
+				"organization": map[string]any{"data": map[string]any{"guid": orgGuid}},
+			},
+		}))
+	return a
+}
+
 func (a AddMock) ServiceInstance(planGuid string) AddMock {
 	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/service_instances/[^/]+$`),
 		ghttp.RespondWithJSONEncoded(http.StatusOK, &cf.ServiceInstance{
@@ -178,3 +212,34 @@ func (a AddMock) OauthToken(accessToken string) AddMock {
 		}{AccessToken: accessToken, ExpiresIn: 12000}))
 	return a
 }
+
+// QuotaUsage mocks the endpoints needed to determine the quota usage of an app. An empty
+// spaceQuotaGuid means that no space quota is assigned to the space.
+func (a AddMock) QuotaUsage(orgQuota, orgUsage map[string]any, spaceQuotaGuid string, spaceQuota, spaceUsage map[string]any) AddMock {
+	spaceRelationships := map[string]any{
+		"organization": map[string]any{"data": map[string]any{"guid": "mock-org-guid"}},
+	}
+	if spaceQuotaGuid != "" {
+		spaceRelationships["quota"] = map[string]any{"data": map[string]any{"guid": spaceQuotaGuid}}
+	}
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/spaces/[^/]+$`),
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]any{
+			"guid":          "mock-space-guid",
+			"relationships": spaceRelationships,
+			"included": map[string]any{
+				"organizations": []map[string]any{{
+					"guid":          "mock-org-guid",
+					"relationships": map[string]any{"quota": map[string]any{"data": map[string]any{"guid": "mock-org-quota-guid"}}},
+				}},
+			},
+		}))
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/organizations/[^/]+/usage_summary$`),
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]any{"usage_summary": orgUsage}))
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/organization_quotas/[^/]+$`),
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]any{"guid": "mock-org-quota-guid", "apps": orgQuota}))
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/spaces/[^/]+/usage_summary$`),
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]any{"usage_summary": spaceUsage}))
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/space_quotas/[^/]+$`),
+		ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]any{"guid": spaceQuotaGuid, "apps": spaceQuota}))
+	return a
+}
@@ -0,0 +1,108 @@
+package mocks
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	. "github.com/onsi/gomega"
+)
+
+// TokenIssuer is a fake UAA which signs tokens with RSA keys and serves the public keys as UAA does.
+type TokenIssuer struct {
+	Issuer string
+
+	mu    sync.Mutex
+	keyId string
+	key   *rsa.PrivateKey
+	keys  []cf.JSONWebKey
+	count int
+}
+
+// TokenIssuer serves the openid configuration and the token keys of a fake UAA at the mock server.
+func (a AddMock) TokenIssuer() *TokenIssuer {
+	issuer := &TokenIssuer{Issuer: a.server.URL() + "/oauth/token"}
+	issuer.RotateKey()
+
+	a.server.RouteToHandler(http.MethodGet, "/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, cf.OpenIDConfiguration{Issuer: issuer.Issuer, JwksURI: a.server.URL() + "/token_keys"})
+	})
+	a.server.RouteToHandler(http.MethodGet, "/token_keys", func(w http.ResponseWriter, _ *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		writeJSON(w, cf.JSONWebKeySet{Keys: issuer.keys})
+	})
+	return issuer
+}
+
+// RotateKey makes the issuer sign tokens with a new key. Like UAA, it keeps serving the previous keys.
+func (t *TokenIssuer) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count++
+	t.keyId = fmt.Sprintf("key-%d", t.count)
+	t.key = key
+	t.keys = append(t.keys, cf.JSONWebKey{
+		KeyId:     t.keyId,
+		KeyType:   "RSA",
+		Algorithm: "RS256",
+		Use:       "sig",
+		Modulus:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	})
+}
+
+// UserToken returns a token of the issuer for a user which expires in an hour.
+func (t *TokenIssuer) UserToken(userId string, scopes ...string) string {
+	return t.Token(map[string]any{
+		"iss":       t.Issuer,
+		"user_id":   userId,
+		"client_id": "cf",
+		"scope":     scopes,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+// Token returns a token with the claims signed with the current key of the issuer.
+func (t *TokenIssuer) Token(claims map[string]any) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return signToken(t.keyId, t.key, claims)
+}
+
+// ForeignToken returns a token with the claims signed with a key which the issuer does not serve.
+func (t *TokenIssuer) ForeignToken(claims map[string]any) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+	return signToken("foreign-key", key, claims)
+}
+
+func signToken(keyId string, key *rsa.PrivateKey, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyId})
+	Expect(err).NotTo(HaveOccurred())
+	payload, err := json.Marshal(claims)
+	Expect(err).NotTo(HaveOccurred())
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	Expect(err).NotTo(HaveOccurred())
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	Expect(json.NewEncoder(w).Encode(body)).To(Succeed())
+}
@@ -0,0 +1,44 @@
+package cf
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// vcapRequestIdHeader carries the request id the cloud controller logs for every request.
+const vcapRequestIdHeader = "X-Vcap-Request-Id"
+
+type requestIdKey struct{}
+
+// ContextWithRequestId sets the request id of the cloud controller requests done with the context,
+// so that they can be found in the cloud controller logs.
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// tracingTransport adds the request id and the trace context of the request context to requests.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	if requestId, ok := req.Context().Value(requestIdKey{}).(string); ok && requestId != "" {
+		req.Header.Set(vcapRequestIdHeader, requestId)
+	}
+	return t.next.RoundTrip(req)
+}
+
+func withTracing(client *http.Client) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	tracingClient := *client
+	tracingClient.Transport = tracingTransport{next: next}
+	return &tracingClient
+}
@@ -0,0 +1,331 @@
+package cf
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for RS256
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for RS384 and RS512
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// minForcedKeysRefreshInterval limits how often tokens signed with an unknown key make the verifier fetch the
+// token keys again, so that forged tokens cannot be used to flood UAA.
+const minForcedKeysRefreshInterval = 5 * time.Second
+
+// failedKeysRefreshBackoff is the time to wait after a failed refresh of the token keys before UAA is asked again, so
+// that requests do not pile up behind calls to an unavailable UAA.
+const failedKeysRefreshBackoff = 30 * time.Second
+
+var ErrInvalidToken = errors.New("invalid token")
+
+var signingHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+}
+
+type (
+	TokenClaims struct {
+		UserId    string   `json:"user_id"`
+		ClientId  string   `json:"client_id"`
+		Email     string   `json:"email"`
+		Scopes    []string `json:"scope"`
+		Issuer    string   `json:"iss"`
+		ExpiresAt int64    `json:"exp"`
+		NotBefore int64    `json:"nbf"`
+	}
+
+	// JSONWebKey is a public key of UAA as served by the token_keys endpoint.
+	JSONWebKey struct {
+		KeyId     string `json:"kid"`
+		KeyType   string `json:"kty"`
+		Algorithm string `json:"alg,omitempty"`
+		Use       string `json:"use,omitempty"`
+		Modulus   string `json:"n"`
+		Exponent  string `json:"e"`
+	}
+
+	JSONWebKeySet struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+
+	OpenIDConfiguration struct {
+		Issuer  string `json:"issuer"`
+		JwksURI string `json:"jwks_uri"`
+	}
+
+	tokenHeader struct {
+		Algorithm string `json:"alg"`
+		KeyId     string `json:"kid"`
+	}
+)
+
+// tokenVerifier validates the signature, issuer and lifetime of tokens issued by UAA without calling UAA for every
+// token. The token keys of UAA are cached and fetched again once they are older than the refresh interval or when a
+// token is signed with a key which is not known yet, which is the case after UAA rotated its keys. Outdated keys are
+// refreshed in the background and stay in use until the refresh succeeds, so that tokens can be validated while UAA
+// is unavailable.
+type tokenVerifier struct {
+	httpClient      *http.Client
+	getUaaURL       func(ctx context.Context) (string, error)
+	refreshInterval time.Duration
+	metrics         *cacheMetrics
+	logger          lager.Logger
+
+	mu                sync.Mutex
+	issuer            string
+	keys              map[string]*rsa.PublicKey
+	fetchedAt         time.Time
+	forcedRefreshedAt time.Time
+	// refreshing is closed when the running refresh of the token keys is done, it is nil if there is none.
+	refreshing chan struct{}
+	failedAt   time.Time
+	refreshErr error
+}
+
+func newTokenVerifier(httpClient *http.Client, getUaaURL func(ctx context.Context) (string, error), refreshInterval time.Duration, metrics *cacheMetrics, logger lager.Logger) *tokenVerifier {
+	return &tokenVerifier{
+		httpClient:      httpClient,
+		getUaaURL:       getUaaURL,
+		refreshInterval: refreshInterval,
+		metrics:         metrics,
+		logger:          logger.Session("token-verifier"),
+	}
+}
+
+// Verify returns the claims of a token. It returns an error wrapping ErrInvalidToken if the token is malformed, is
+// not signed by UAA, has been issued by another issuer or is not valid at this time.
+func (v *tokenVerifier) Verify(ctx context.Context, token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: token must consist of three parts", ErrInvalidToken)
+	}
+
+	var header tokenHeader
+	if err := decodeTokenSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidToken, err)
+	}
+	hash, ok := signingHashes[header.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported signing algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %w", ErrInvalidToken, err)
+	}
+
+	key, issuer, err := v.getKey(ctx, header.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, hash, hasher.Sum(nil), signature); err != nil {
+		return nil, fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+	}
+
+	var claims TokenClaims
+	if err := decodeTokenSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %w", ErrInvalidToken, err)
+	}
+	if claims.Issuer != issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	now := time.Now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return nil, fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return nil, fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+	return &claims, nil
+}
+
+func (v *tokenVerifier) getKey(ctx context.Context, keyId string) (*rsa.PublicKey, string, error) {
+	v.mu.Lock()
+
+	now := time.Now()
+	key, known := v.keys[keyId]
+	if known {
+		if now.Sub(v.fetchedAt) < v.refreshInterval {
+			v.metrics.hit(cacheTokenKeys)
+		} else {
+			v.metrics.miss(cacheTokenKeys)
+			v.startRefresh(ctx, now)
+		}
+		issuer := v.issuer
+		v.mu.Unlock()
+		return key, issuer, nil
+	}
+
+	if v.keys != nil && now.Sub(v.forcedRefreshedAt) < minForcedKeysRefreshInterval && now.Sub(v.fetchedAt) < v.refreshInterval {
+		v.mu.Unlock()
+		return nil, "", fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, keyId)
+	}
+
+	v.metrics.miss(cacheTokenKeys)
+	done := v.startRefresh(ctx, now)
+	if done == nil {
+		err := v.refreshErr
+		v.mu.Unlock()
+		return nil, "", err
+	}
+	if v.keys != nil {
+		v.forcedRefreshedAt = now
+	}
+	v.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key, known = v.keys[keyId]
+	switch {
+	case known:
+		return key, v.issuer, nil
+	case v.refreshErr != nil:
+		return nil, "", v.refreshErr
+	default:
+		return nil, "", fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, keyId)
+	}
+}
+
+// startRefresh fetches the token keys in the background, unless a refresh is running already, and returns a channel
+// which is closed when the refresh is done. It returns nil without starting a refresh if the last one failed less
+// than failedKeysRefreshBackoff ago. v.mu must be held.
+func (v *tokenVerifier) startRefresh(ctx context.Context, now time.Time) chan struct{} {
+	if v.refreshing != nil {
+		return v.refreshing
+	}
+	if !v.failedAt.IsZero() && now.Sub(v.failedAt) < failedKeysRefreshBackoff {
+		return nil
+	}
+
+	done := make(chan struct{})
+	v.refreshing = done
+	// The refresh must not be canceled with the request which starts it, as other requests wait for it as well.
+	refreshCtx := context.WithoutCancel(ctx)
+	go func() {
+		issuer, keys, err := v.fetchKeys(refreshCtx)
+
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if err != nil {
+			v.logger.Error("failed-to-refresh-token-keys", err)
+			v.failedAt = time.Now()
+			v.refreshErr = err
+		} else {
+			v.issuer = issuer
+			v.keys = keys
+			v.fetchedAt = time.Now()
+			v.failedAt = time.Time{}
+			v.refreshErr = nil
+		}
+		v.refreshing = nil
+		close(done)
+	}()
+	return done
+}
+
+// fetchKeys fetches the issuer and the token keys from UAA.
+func (v *tokenVerifier) fetchKeys(ctx context.Context) (string, map[string]*rsa.PublicKey, error) {
+	uaaURL, err := v.getUaaURL(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var openIDConfig OpenIDConfiguration
+	if err := v.getJSON(ctx, uaaURL+"/.well-known/openid-configuration", &openIDConfig); err != nil {
+		return "", nil, fmt.Errorf("failed to get openid configuration: %w", err)
+	}
+	if openIDConfig.Issuer == "" {
+		return "", nil, fmt.Errorf("openid configuration of UAA contains no issuer")
+	}
+	jwksURI := openIDConfig.JwksURI
+	if jwksURI == "" {
+		jwksURI = uaaURL + "/token_keys"
+	}
+
+	var keySet JSONWebKeySet
+	if err := v.getJSON(ctx, jwksURI, &keySet); err != nil {
+		return "", nil, fmt.Errorf("failed to get token keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			v.logger.Error("skipping-token-key", err, lager.Data{"kid": jwk.KeyId})
+			continue
+		}
+		keys[jwk.KeyId] = key
+	}
+
+	v.logger.Info("refreshed-token-keys", lager.Data{"issuer": openIDConfig.Issuer, "keyCount": len(keys)})
+	return openIDConfig.Issuer, keys, nil
+}
+
+func (v *tokenVerifier) getJSON(ctx context.Context, url string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", GetUserAgent())
+	// #nosec G704 -- UAA URL is fetched from trusted CF API endpoints
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (k JSONWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	if k.KeyType != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+	modulus, err := base64.RawURLEncoding.DecodeString(k.Modulus)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(k.Exponent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	e := new(big.Int).SetBytes(exponent)
+	if len(modulus) == 0 || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid key")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(e.Int64())}, nil
+}
+
+func decodeTokenSegment(segment string, result any) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, result)
+}
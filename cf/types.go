@@ -1,6 +1,10 @@
 package cf
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 const (
 	TokenTypeBearer   = "Bearer"
@@ -20,6 +24,15 @@ const (
 	RoleSpaceSupporter             RoleType = "space_supporter"
 )
 
+var (
+	// AppDeveloperRoles are the roles which allow to change how an app is scaled.
+	AppDeveloperRoles = []RoleType{RoleSpaceDeveloper}
+	// AppReaderRoles are the roles which allow to view the policy, histories and metrics of an app.
+	AppReaderRoles = []RoleType{RoleSpaceDeveloper, RoleSpaceAuditor, RoleSpaceSupporter, RoleSpaceManager, RoleOrganizationManager}
+	// OrgAppReaderRoles are the roles which allow to view the apps of all spaces of an organization.
+	OrgAppReaderRoles = []RoleType{RoleOrganizationManager}
+)
+
 type (
 	// App the app information from cf for full version look at https://v3-apidocs.cloudfoundry.org/version/3.122.0/index.html#apps
 	App struct {
@@ -152,8 +165,14 @@ type (
 type Roles []Role
 
 type SpaceId string
+type OrgId string
 type UserId string
 
+// IsOrganizationRole reports whether the role is assigned in an organization rather than in a space.
+func (t RoleType) IsOrganizationRole() bool {
+	return strings.HasPrefix(string(t), "organization_")
+}
+
 func (r Roles) HasRole(roleType RoleType) bool {
 	for _, role := range r {
 		if role.Type == roleType {
@@ -162,3 +181,42 @@ func (r Roles) HasRole(roleType RoleType) bool {
 	}
 	return false
 }
+
+func (r Roles) HasAnyRole(roleTypes ...RoleType) bool {
+	return slices.ContainsFunc(roleTypes, r.HasRole)
+}
+
+type (
+	// QuotaUsage holds the app related limits of an org or space quota together with the current
+	// consumption. A nil limit means that the quota does not restrict the resource.
+	QuotaUsage struct {
+		TotalInstances   *int
+		TotalMemoryInMb  *int
+		StartedInstances int
+		MemoryInMb       int
+	}
+
+	// AppQuotaUsage holds the quota usage of the org and space an app lives in. Space is nil if
+	// no space quota is assigned.
+	AppQuotaUsage struct {
+		Org   QuotaUsage
+		Space *QuotaUsage
+	}
+)
+
+// RemainingInstances returns how many additional instances with the given memory footprint fit
+// into the quota. The second return value is false if the quota does not limit instances at all.
+func (q QuotaUsage) RemainingInstances(memoryPerInstanceInMb int) (int, bool) {
+	remaining, limited := 0, false
+	if q.TotalInstances != nil && *q.TotalInstances >= 0 {
+		remaining, limited = max(*q.TotalInstances-q.StartedInstances, 0), true
+	}
+	if q.TotalMemoryInMb != nil && *q.TotalMemoryInMb >= 0 && memoryPerInstanceInMb > 0 {
+		byMemory := max(*q.TotalMemoryInMb-q.MemoryInMb, 0) / memoryPerInstanceInMb
+		if !limited || byMemory < remaining {
+			remaining = byMemory
+		}
+		limited = true
+	}
+	return remaining, limited
+}
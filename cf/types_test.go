@@ -0,0 +1,28 @@
+package cf_test
+
+import (
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QuotaUsage", func() {
+	limit := func(v int) *int { return &v }
+
+	DescribeTable("RemainingInstances",
+		func(usage cf.QuotaUsage, memoryPerInstance int, expectedRemaining int, expectedLimited bool) {
+			remaining, limited := usage.RemainingInstances(memoryPerInstance)
+			Expect(remaining).To(Equal(expectedRemaining))
+			Expect(limited).To(Equal(expectedLimited))
+		},
+		Entry("unlimited quota", cf.QuotaUsage{StartedInstances: 5, MemoryInMb: 1024}, 256, 0, false),
+		Entry("unlimited quota with -1 limits", cf.QuotaUsage{TotalInstances: limit(-1), TotalMemoryInMb: limit(-1)}, 256, 0, false),
+		Entry("limited by instances", cf.QuotaUsage{TotalInstances: limit(10), StartedInstances: 7}, 256, 3, true),
+		Entry("limited by memory", cf.QuotaUsage{TotalMemoryInMb: limit(2048), MemoryInMb: 1024}, 256, 4, true),
+		Entry("memory is the tighter limit", cf.QuotaUsage{TotalInstances: limit(10), StartedInstances: 2, TotalMemoryInMb: limit(1024), MemoryInMb: 512}, 256, 2, true),
+		Entry("instances is the tighter limit", cf.QuotaUsage{TotalInstances: limit(10), StartedInstances: 9, TotalMemoryInMb: limit(8192)}, 256, 1, true),
+		Entry("quota already exceeded", cf.QuotaUsage{TotalInstances: limit(5), StartedInstances: 7}, 256, 0, true),
+		Entry("unknown memory per instance ignores the memory limit", cf.QuotaUsage{TotalMemoryInMb: limit(1024)}, 0, 0, false),
+	)
+})
@@ -54,14 +54,26 @@ type PolicyDB interface {
 
 	GetAppIds(ctx context.Context) (map[string]bool, error)
 	GetAppPolicy(ctx context.Context, appId string) (*models.PolicyDefinition, error)
-	SaveAppPolicy(ctx context.Context, appId string, policy *models.PolicyDefinition, policyGuid string) error
-	SetOrUpdateDefaultAppPolicy(ctx context.Context, appIds []string, oldPolicyGuid string, newPolicy *models.PolicyDefinition, newPolicyGuid string) ([]string, error)
+	GetAppPolicies(ctx context.Context, appIds []string) (map[string]*models.PolicyDefinition, error)
+	GetAppPolicyWithGuid(ctx context.Context, appId string) (*models.PolicyDefinition, string, error)
+	GetAppIdsSharingPolicy(ctx context.Context, appId string) ([]string, error)
+	SaveAppPolicy(ctx context.Context, appId string, policy *models.PolicyDefinition, policyGuid string, change models.PolicyChange) error
+	CompareAndSwapAppPolicy(ctx context.Context, appId string, expectedPolicyGuid string, policy *models.PolicyDefinition, policyGuid string, change models.PolicyChange) (bool, error)
+	SetOrUpdateDefaultAppPolicy(ctx context.Context, appIds []string, oldPolicyGuid string, newPolicy *models.PolicyDefinition, newPolicyGuid string, change models.PolicyChange) ([]string, error)
+	RetrievePolicyRevisions(ctx context.Context, appId string) ([]*models.PolicyRevision, error)
+	GetPolicyRevision(ctx context.Context, appId string, revision int) (*models.PolicyRevision, error)
 	DeletePoliciesByPolicyGuid(ctx context.Context, policyGuid string) ([]string, error)
 	RetrievePolicies() ([]*models.PolicyJson, error)
 	DeletePolicy(ctx context.Context, appId string) error
+	DeletePolicyAndRevisions(ctx context.Context, appId string) error
+	CompareAndDeleteAppPolicy(ctx context.Context, appId string, expectedPolicyGuid string) (bool, error)
 	SaveCredential(ctx context.Context, appId string, cred models.Credential) error
 	DeleteCredential(ctx context.Context, appId string) error
 	GetCredential(appId string) (*models.Credential, error)
+	SaveAppGroup(ctx context.Context, group *models.AppGroup) error
+	GetAppGroup(ctx context.Context, groupId string) (*models.AppGroup, error)
+	GetAppGroupByAppId(ctx context.Context, appId string) (*models.AppGroup, error)
+	DeleteAppGroup(ctx context.Context, groupId string) error
 }
 
 type BindingDB interface {
@@ -79,6 +91,8 @@ type BindingDB interface {
 	CheckServiceBinding(appId string) bool
 	GetAppIdByBindingId(ctx context.Context, bindingId string) (string, error)
 	GetAppIdsByInstanceId(ctx context.Context, instanceId string) ([]string, error)
+	GetAppIdsBySpaceId(ctx context.Context, spaceId string) ([]string, error)
+	GetAppIdsByOrgId(ctx context.Context, orgId string) (map[string][]string, error)
 	CountServiceInstancesInOrg(orgId string) (int, error)
 	GetServiceBinding(ctx context.Context, serviceBindingId string) (*models.ServiceBinding, error)
 	GetBindingIdsByInstanceId(ctx context.Context, instanceId string) ([]string, error)
@@ -92,20 +106,42 @@ type AppMetricDB interface {
 	SaveAppMetric(appMetric *models.AppMetric) error
 	SaveAppMetricsInBulk(metrics []*models.AppMetric) error
 	RetrieveAppMetrics(appId string, metricType string, start int64, end int64, orderType OrderType) ([]*models.AppMetric, error)
+	RetrieveAppMetricsPage(appId string, metricType string, start int64, end int64, orderType OrderType, limit int) ([]*models.AppMetric, error)
+	RetrieveAppMetricBuckets(appId string, metricType string, start int64, end int64, step int64, orderType OrderType, limit int) ([]int64, error)
 	PruneAppMetrics(ctx context.Context, before int64) error
 	io.Closer
 }
 
+// ScalingHistoryFilter restricts the scaling histories that are counted or retrieved.
+// Without any statuses given, ignored scalings are only included if IncludeAll is set.
+type ScalingHistoryFilter struct {
+	IncludeAll       bool
+	Statuses         []models.ScalingStatus
+	ScalingTypes     []models.ScalingType
+	MinInstanceDelta *int
+	MaxInstanceDelta *int
+}
+
 type ScalingEngineDB interface {
 	healthendpoint.DatabaseStatus
 	SaveScalingHistory(history *models.AppScalingHistory) error
 
-	CountScalingHistories(ctx context.Context, appId string, start int64, end int64, includeAll bool) (int, error)
-	RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType OrderType, includeAll bool, page int, resultsPerPAge int) ([]*models.AppScalingHistory, error)
+	CountScalingHistories(ctx context.Context, appId string, start int64, end int64, filter ScalingHistoryFilter) (int, error)
+	RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType OrderType, filter ScalingHistoryFilter, page int, resultsPerPAge int) ([]*models.AppScalingHistory, error)
+	RetrieveInstanceChanges(ctx context.Context, appId string, start int64, end int64) ([]*models.AppScalingHistory, error)
+	CountEmergencyScalings(ctx context.Context, appId string, start int64, end int64) (int, error)
+	RetrieveScalingAnalytics(ctx context.Context, appId string, start int64, end int64, currentInstances int, instanceMin int, instanceMax int) (*models.ScalingAnalytics, error)
+	SaveScalingDecision(ctx context.Context, decision *models.DecisionTrace) error
+	SaveGroupScalingHistory(ctx context.Context, history *models.GroupScalingHistory) error
+	RetrieveGroupScalingHistories(ctx context.Context, groupId string, start int64, end int64, limit int) ([]*models.GroupScalingHistory, error)
+	RetrieveScalingDecision(ctx context.Context, appId string, decisionId string) (*models.DecisionTrace, error)
+	SaveScalingEvent(ctx context.Context, event *models.ScalingEvent) error
+	RetrieveScalingEvents(ctx context.Context, appId string, afterId int64, limit int) ([]*models.ScalingEvent, error)
+	GetLastScalingEventId(ctx context.Context, appId string) (int64, error)
 	PruneScalingHistories(ctx context.Context, before int64) error
 	PruneCooldowns(ctx context.Context, before int64) error
-	UpdateScalingCooldownExpireTime(appId string, expireAt int64) error
-	CanScaleApp(appId string) (bool, int64, error)
+	UpdateScalingCooldownExpireTime(appId string, direction models.ScalingDirection, expireAt int64) error
+	CanScaleApp(appId string, direction models.ScalingDirection) (bool, int64, error)
 	GetActiveSchedule(appId string) (*models.ActiveSchedule, error)
 	GetActiveSchedules() (map[string]string, error)
 	SetActiveSchedule(appId string, schedule *models.ActiveSchedule) error
@@ -98,20 +98,45 @@ func (adb *AppMetricSQLDB) SaveAppMetricsInBulk(appMetrics []*models.AppMetric)
 	return nil
 }
 func (adb *AppMetricSQLDB) RetrieveAppMetrics(appIdP string, metricTypeP string, startP int64, endP int64, orderType db.OrderType) ([]*models.AppMetric, error) {
-	var orderStr string
-	if orderType == db.ASC {
-		orderStr = db.ASCSTR
-	} else {
-		orderStr = db.DESCSTR
+	return adb.retrieveAppMetrics(appIdP, metricTypeP, startP, endP, orderType, 0)
+}
+
+// RetrieveAppMetricsPage retrieves at most limit metrics of an app in the time range.
+func (adb *AppMetricSQLDB) RetrieveAppMetricsPage(appId string, metricType string, start int64, end int64, orderType db.OrderType, limit int) ([]*models.AppMetric, error) {
+	return adb.retrieveAppMetrics(appId, metricType, start, end, orderType, limit)
+}
+
+// RetrieveAppMetricBuckets retrieves the start of at most limit buckets of the step, in nanoseconds, which contain
+// metrics with a value in the time range. Buckets are aligned to multiples of the step since the epoch.
+func (adb *AppMetricSQLDB) RetrieveAppMetricBuckets(appId string, metricType string, start int64, end int64, step int64, orderType db.OrderType, limit int) ([]int64, error) {
+	if end < 0 {
+		end = time.Now().UnixNano()
 	}
 
+	query := adb.sqldb.Rebind("SELECT DISTINCT timestamp - MOD(timestamp, ?) AS bucket FROM app_metric WHERE app_id=? AND metric_type=? AND timestamp>=? AND timestamp<=? AND value IS NOT NULL AND value<>'' ORDER BY bucket " + orderString(orderType) + " LIMIT ?")
+	buckets := []int64{}
+	err := adb.sqldb.Select(&buckets, query, step, appId, metricType, start, end, limit)
+	if err != nil {
+		adb.logger.Error("retrieve-app-metric-buckets-from-app_metric-table", err, lager.Data{"query": query})
+		return nil, err
+	}
+	return buckets, nil
+}
+
+func (adb *AppMetricSQLDB) retrieveAppMetrics(appIdP string, metricTypeP string, startP int64, endP int64, orderType db.OrderType, limit int) ([]*models.AppMetric, error) {
 	if endP < 0 {
 		endP = time.Now().UnixNano()
 	}
 
-	query := adb.sqldb.Rebind("SELECT app_id,metric_type,value,unit,timestamp FROM app_metric WHERE app_id=? AND metric_type=? AND timestamp>=? AND timestamp<=? ORDER BY timestamp " + orderStr)
+	query := "SELECT app_id,metric_type,value,unit,timestamp FROM app_metric WHERE app_id=? AND metric_type=? AND timestamp>=? AND timestamp<=? ORDER BY timestamp " + orderString(orderType)
+	args := []any{appIdP, metricTypeP, startP, endP}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	query = adb.sqldb.Rebind(query)
 	appMetricList := []*models.AppMetric{}
-	rows, err := adb.sqldb.Query(query, appIdP, metricTypeP, startP, endP)
+	rows, err := adb.sqldb.Query(query, args...)
 	if err != nil {
 		adb.logger.Error("retrieve-app-metric-list-from-app_metric-table", err, lager.Data{"query": query})
 		return nil, err
@@ -140,6 +165,13 @@ func (adb *AppMetricSQLDB) RetrieveAppMetrics(appIdP string, metricTypeP string,
 	return appMetricList, rows.Err()
 }
 
+func orderString(orderType db.OrderType) string {
+	if orderType == db.ASC {
+		return db.ASCSTR
+	}
+	return db.DESCSTR
+}
+
 func (adb *AppMetricSQLDB) PruneAppMetrics(ctx context.Context, before int64) error {
 	query := adb.sqldb.Rebind("DELETE FROM app_metric WHERE timestamp <= ?")
 	_, err := adb.sqldb.ExecContext(ctx, query, before)
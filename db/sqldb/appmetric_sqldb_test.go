@@ -380,6 +380,33 @@ var _ = Describe("AppMetricSQLDB", func() {
 		})
 	})
 
+	Context("RetrieveAppMetricsPage and RetrieveAppMetricBuckets", func() {
+		BeforeEach(func() {
+			for timestamp, value := range map[int64]string{11111111: "100", 33333333: "200", 35555555: "300", 55555555: "", 77777777: "400"} {
+				err = adb.SaveAppMetric(&models.AppMetric{AppId: appId, MetricType: testMetricName, Unit: testMetricUnit, Timestamp: timestamp, Value: value})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("returns at most limit appMetrics ordered by timestamp", func() {
+			appMetrics, err = adb.RetrieveAppMetricsPage(appId, testMetricName, 0, -1, db.DESC, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(appMetrics).To(HaveLen(2))
+			Expect(appMetrics[0].Timestamp).To(Equal(int64(77777777)))
+			Expect(appMetrics[1].Timestamp).To(Equal(int64(55555555)))
+		})
+
+		It("returns the starts of at most limit buckets with a value", func() {
+			buckets, err := adb.RetrieveAppMetricBuckets(appId, testMetricName, 0, -1, 10000000, db.ASC, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buckets).To(Equal([]int64{10000000, 30000000, 70000000}))
+
+			buckets, err = adb.RetrieveAppMetricBuckets(appId, testMetricName, 20000000, 80000000, 10000000, db.DESC, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buckets).To(Equal([]int64{70000000}))
+		})
+	})
+
 	Context("PruneAppMetrics", Serial, func() {
 		BeforeEach(func() {
 			appMetric := &models.AppMetric{
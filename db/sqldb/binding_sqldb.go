@@ -388,6 +388,60 @@ func (bdb *BindingSQLDB) GetAppIdsByInstanceId(ctx context.Context, instanceId s
 	return appIds, rows.Err()
 }
 
+// GetAppIdsBySpaceId returns the ids of the apps which are bound to a service instance in the space.
+func (bdb *BindingSQLDB) GetAppIdsBySpaceId(ctx context.Context, spaceId string) ([]string, error) {
+	appIds := []string{}
+	query := bdb.sqldb.Rebind("SELECT binding.app_id FROM binding" +
+		" JOIN service_instance ON binding.service_instance_id = service_instance.service_instance_id" +
+		" WHERE service_instance.space_id = ?" +
+		" ORDER BY binding.app_id")
+	rows, err := bdb.sqldb.QueryContext(ctx, query, spaceId)
+	if err != nil {
+		bdb.logger.Error("get-appids-by-space-id", err, lager.Data{"query": query, "spaceId": spaceId})
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var appId string
+	for rows.Next() {
+		if err = rows.Scan(&appId); err != nil {
+			bdb.logger.Error("scan-appids-by-space-id", err)
+			return nil, err
+		}
+		appIds = append(appIds, appId)
+	}
+
+	return appIds, rows.Err()
+}
+
+// GetAppIdsByOrgId returns the ids of the apps which are bound to a service instance in the organization, by space.
+func (bdb *BindingSQLDB) GetAppIdsByOrgId(ctx context.Context, orgId string) (map[string][]string, error) {
+	appIds := map[string][]string{}
+	query := bdb.sqldb.Rebind("SELECT service_instance.space_id, binding.app_id FROM binding" +
+		" JOIN service_instance ON binding.service_instance_id = service_instance.service_instance_id" +
+		" WHERE service_instance.org_id = ?" +
+		" ORDER BY service_instance.space_id, binding.app_id")
+	rows, err := bdb.sqldb.QueryContext(ctx, query, orgId)
+	if err != nil {
+		bdb.logger.Error("get-appids-by-org-id", err, lager.Data{"query": query, "orgId": orgId})
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var spaceId, appId string
+	for rows.Next() {
+		if err = rows.Scan(&spaceId, &appId); err != nil {
+			bdb.logger.Error("scan-appids-by-org-id", err)
+			return nil, err
+		}
+		appIds[spaceId] = append(appIds[spaceId], appId)
+	}
+
+	return appIds, rows.Err()
+}
+
 func (bdb *BindingSQLDB) CountServiceInstancesInOrg(orgId string) (int, error) {
 	var count int
 	query := bdb.sqldb.Rebind("SELECT COUNT(*) FROM service_instance WHERE org_id=?")
@@ -635,6 +635,79 @@ var _ = Describe("BindingSqldb", func() {
 
 	})
 
+	Describe("GetAppIdsBySpaceId", func() {
+		var results []string
+		JustBeforeEach(func() {
+			results, err = bdb.GetAppIdsBySpaceId(context.Background(), testSpaceGuid)
+		})
+		Context("when apps are bound to service instances in the space", func() {
+			BeforeEach(func() {
+				err = bdb.CreateServiceInstance(context.Background(), models.ServiceInstance{ServiceInstanceId: testInstanceId, OrgId: testOrgGuid, SpaceId: testSpaceGuid, DefaultPolicy: policyJsonStr, DefaultPolicyGuid: policyGuid})
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceBinding(context.Background(), testBindingId, testInstanceId, models.GUID(testAppId), models.CustomMetricsSameApp)
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceInstance(context.Background(), models.ServiceInstance{ServiceInstanceId: testInstanceId2, OrgId: testOrgGuid, SpaceId: testSpaceGuid, DefaultPolicy: policyJsonStr, DefaultPolicyGuid: policyGuid})
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceBinding(context.Background(), testBindingId2, testInstanceId2, models.GUID(testAppId2), models.CustomMetricsSameApp)
+				Expect(err).NotTo(HaveOccurred())
+
+				// service instance with bindings in another space
+				err = bdb.CreateServiceInstance(context.Background(), models.ServiceInstance{ServiceInstanceId: testInstanceId3, OrgId: testOrgGuid, SpaceId: "another-space-guid", DefaultPolicy: policyJsonStr, DefaultPolicyGuid: policyGuid})
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceBinding(context.Background(), testBindingId3, testInstanceId3, models.GUID(testAppId3), models.CustomMetricsSameApp)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns the apps of the space", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(ConsistOf(testAppId, testAppId2))
+			})
+		})
+		Context("when no app is bound in the space", func() {
+			It("should not return an error, but an empty result", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("GetAppIdsByOrgId", func() {
+		var results map[string][]string
+		JustBeforeEach(func() {
+			results, err = bdb.GetAppIdsByOrgId(context.Background(), testOrgGuid)
+		})
+		Context("when apps are bound to service instances in the organization", func() {
+			BeforeEach(func() {
+				err = bdb.CreateServiceInstance(context.Background(), models.ServiceInstance{ServiceInstanceId: testInstanceId, OrgId: testOrgGuid, SpaceId: testSpaceGuid, DefaultPolicy: policyJsonStr, DefaultPolicyGuid: policyGuid})
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceBinding(context.Background(), testBindingId, testInstanceId, models.GUID(testAppId), models.CustomMetricsSameApp)
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceInstance(context.Background(), models.ServiceInstance{ServiceInstanceId: testInstanceId2, OrgId: testOrgGuid, SpaceId: "another-space-guid", DefaultPolicy: policyJsonStr, DefaultPolicyGuid: policyGuid})
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceBinding(context.Background(), testBindingId2, testInstanceId2, models.GUID(testAppId2), models.CustomMetricsSameApp)
+				Expect(err).NotTo(HaveOccurred())
+
+				// service instance with bindings in another organization
+				err = bdb.CreateServiceInstance(context.Background(), models.ServiceInstance{ServiceInstanceId: testInstanceId3, OrgId: testOrgGuid2, SpaceId: testSpaceGuid, DefaultPolicy: policyJsonStr, DefaultPolicyGuid: policyGuid})
+				Expect(err).NotTo(HaveOccurred())
+				err = bdb.CreateServiceBinding(context.Background(), testBindingId3, testInstanceId3, models.GUID(testAppId3), models.CustomMetricsSameApp)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns the apps of the organization by space", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal(map[string][]string{
+					testSpaceGuid:        {testAppId},
+					"another-space-guid": {testAppId2},
+				}))
+			})
+		})
+		Context("when no app is bound in the organization", func() {
+			It("should not return an error, but an empty result", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("CountServiceInstancesInOrg", func() {
 		var serviceInstanceCount int
 		JustBeforeEach(func() {
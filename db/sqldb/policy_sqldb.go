@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
@@ -92,6 +93,44 @@ func (pdb *PolicySQLDB) GetAppIds(ctx context.Context) (map[string]bool, error)
 	return appIds, rows.Err()
 }
 
+// GetAppPolicies retrieves the scaling policies of several apps at once. Apps without a policy are missing in the
+// result.
+func (pdb *PolicySQLDB) GetAppPolicies(ctx context.Context, appIds []string) (map[string]*models.PolicyDefinition, error) {
+	policies := map[string]*models.PolicyDefinition{}
+	if len(appIds) == 0 {
+		return policies, nil
+	}
+
+	query, args, err := sqlx.In("SELECT app_id, policy_json FROM policy_json WHERE app_id IN (?)", appIds)
+	if err != nil {
+		return nil, err
+	}
+	query = pdb.sqldb.Rebind(query)
+
+	rows, err := pdb.sqldb.QueryContext(ctx, query, args...)
+	if err != nil {
+		pdb.logger.Error("get-app-policies-from-policy-table", err, lager.Data{"query": query, "appIds": appIds})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var appId string
+	var policyJson []byte
+	for rows.Next() {
+		if err = rows.Scan(&appId, &policyJson); err != nil {
+			pdb.logger.Error("get-app-policies-scan", err)
+			return nil, err
+		}
+		scalingPolicy := &models.PolicyDefinition{}
+		if err = json.Unmarshal(policyJson, scalingPolicy); err != nil {
+			pdb.logger.Error("get-app-policies-unmarshal", err, lager.Data{"appId": appId, "policyJson": string(policyJson)})
+			return nil, err
+		}
+		policies[appId] = scalingPolicy
+	}
+	return policies, rows.Err()
+}
+
 func (pdb *PolicySQLDB) RetrievePolicies() ([]*models.PolicyJson, error) {
 	query := "SELECT app_id,policy_json FROM policy_json WHERE 1=1 "
 	policyList := []*models.PolicyJson{}
@@ -138,29 +177,62 @@ func (pdb *PolicySQLDB) RetrievePolicies() ([]*models.PolicyJson, error) {
 //   - Returns (nil, error) on database connection issues or query execution failures
 //   - Returns (nil, error) when policy JSON is malformed or cannot be unmarshaled
 func (pdb *PolicySQLDB) GetAppPolicy(ctx context.Context, appId string) (*models.PolicyDefinition, error) {
+	scalingPolicy, _, err := pdb.GetAppPolicyWithGuid(ctx, appId)
+	return scalingPolicy, err
+}
+
+// GetAppPolicyWithGuid retrieves the scaling policy of an app like GetAppPolicy together with the guid it has been
+// saved under. The guid is empty when there is no policy.
+func (pdb *PolicySQLDB) GetAppPolicyWithGuid(ctx context.Context, appId string) (*models.PolicyDefinition, string, error) {
 	var policyJson []byte
-	query := pdb.sqldb.Rebind("SELECT policy_json FROM policy_json WHERE app_id =?")
-	err := pdb.sqldb.QueryRowContext(ctx, query, appId).Scan(&policyJson)
+	var policyGuid sql.NullString
+	query := pdb.sqldb.Rebind("SELECT policy_json, guid FROM policy_json WHERE app_id =?")
+	err := pdb.sqldb.QueryRowContext(ctx, query, appId).Scan(&policyJson, &policyGuid)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
+		return nil, "", nil
 	}
 
 	if err != nil {
 		pdb.logger.Error("get-app-policy-from-policy-table", err, lager.Data{"query": query, "appid": appId})
-		return nil, err
+		return nil, "", err
 	}
 
 	scalingPolicy := &models.PolicyDefinition{}
 	err = json.Unmarshal(policyJson, scalingPolicy)
 	if err != nil {
 		pdb.logger.Error("get-app-policy-unmarshal", err, lager.Data{"policyJson": string(policyJson)})
+		return nil, "", err
+	}
+	return scalingPolicy, policyGuid.String, nil
+}
+
+// GetAppIdsSharingPolicy returns the ids of the apps whose policy has been saved under the same guid as the policy of
+// the given app, including the app itself. Only apps bound with the default policy of a service instance share it.
+func (pdb *PolicySQLDB) GetAppIdsSharingPolicy(ctx context.Context, appId string) ([]string, error) {
+	var appIds []string
+	query := pdb.sqldb.Rebind("SELECT app_id FROM policy_json WHERE guid = (SELECT guid FROM policy_json WHERE app_id = ?) ORDER BY app_id")
+
+	rows, err := pdb.sqldb.QueryContext(ctx, query, appId)
+	if err != nil {
+		pdb.logger.Error("get-app-ids-sharing-policy", err, lager.Data{"query": query, "appId": appId})
 		return nil, err
 	}
-	return scalingPolicy, nil
+	defer func() { _ = rows.Close() }()
+
+	var id string
+	for rows.Next() {
+		if err = rows.Scan(&id); err != nil {
+			pdb.logger.Error("get-app-ids-sharing-policy-scan", err)
+			return nil, err
+		}
+		appIds = append(appIds, id)
+	}
+	return appIds, rows.Err()
 }
 
-func (pdb *PolicySQLDB) SaveAppPolicy(ctx context.Context, appId string, policy *models.PolicyDefinition, policyGuid string) error {
+// SaveAppPolicy creates or replaces the policy of an app and records it as a new revision of the app's policy.
+func (pdb *PolicySQLDB) SaveAppPolicy(ctx context.Context, appId string, policy *models.PolicyDefinition, policyGuid string, change models.PolicyChange) error {
 	var query string
 	queryPrefix := "INSERT INTO policy_json (app_id, policy_json, guid) VALUES (?,?,?) "
 	switch pdb.sqldb.DriverName() {
@@ -173,14 +245,160 @@ func (pdb *PolicySQLDB) SaveAppPolicy(ctx context.Context, appId string, policy
 	if err != nil {
 		return fmt.Errorf("SaveAppPolicy failed to marshal policy:  %w", err)
 	}
-	_, err = pdb.sqldb.ExecContext(ctx, query, appId, policyJSON, policyGuid)
+
+	tx, err := pdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		pdb.logger.Error("save-app-policy-begin-transaction", err, lager.Data{"app_id": appId, "policyGuid": policyGuid})
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, query, appId, policyJSON, policyGuid)
 	if err != nil {
 		pdb.logger.Error("save-app-policy", err, lager.Data{"query": query, "app_id": appId, "policyJSON": policyJSON, "policyGuid": policyGuid})
+		return err
+	}
+
+	if err = pdb.insertPolicyRevision(ctx, tx, appId, string(policyJSON), policyGuid, change); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		pdb.logger.Error("save-app-policy-commit", err, lager.Data{"app_id": appId, "policyGuid": policyGuid})
 	}
 	return err
 }
 
-func (pdb *PolicySQLDB) SetOrUpdateDefaultAppPolicy(ctx context.Context, boundApps []string, oldPolicyGuid string, policy *models.PolicyDefinition, newPolicyGuid string) ([]string, error) {
+// CompareAndSwapAppPolicy replaces the policy of an app only if it is still saved under expectedPolicyGuid, and
+// records it as a new revision. It returns false without saving anything if the policy has been changed or
+// deleted in the meantime.
+func (pdb *PolicySQLDB) CompareAndSwapAppPolicy(ctx context.Context, appId string, expectedPolicyGuid string, policy *models.PolicyDefinition, policyGuid string, change models.PolicyChange) (bool, error) {
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return false, fmt.Errorf("CompareAndSwapAppPolicy failed to marshal policy:  %w", err)
+	}
+
+	tx, err := pdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		pdb.logger.Error("compare-and-swap-app-policy-begin-transaction", err, lager.Data{"app_id": appId, "policyGuid": policyGuid})
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := tx.Rebind("UPDATE policy_json SET policy_json = ?, guid = ? WHERE app_id = ? AND COALESCE(guid, '') = ?")
+	res, err := tx.ExecContext(ctx, query, policyJSON, policyGuid, appId, expectedPolicyGuid)
+	if err != nil {
+		pdb.logger.Error("compare-and-swap-app-policy", err, lager.Data{"query": query, "app_id": appId, "expectedPolicyGuid": expectedPolicyGuid, "policyGuid": policyGuid})
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		pdb.logger.Error("compare-and-swap-app-policy-determine-rows-affected", err, lager.Data{"query": query, "app_id": appId})
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	if err = pdb.insertPolicyRevision(ctx, tx, appId, string(policyJSON), policyGuid, change); err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		pdb.logger.Error("compare-and-swap-app-policy-commit", err, lager.Data{"app_id": appId, "policyGuid": policyGuid})
+		return false, err
+	}
+	return true, nil
+}
+
+// insertPolicyRevision records the policy as the next revision of the app's policy. The policy of the app is locked
+// until the end of the transaction, so that concurrent saves of the policy cannot take the same revision number.
+func (pdb *PolicySQLDB) insertPolicyRevision(ctx context.Context, tx *sqlx.Tx, appId string, policyJson string, policyGuid string, change models.PolicyChange) error {
+	query := tx.Rebind("SELECT app_id FROM policy_json WHERE app_id = ? FOR UPDATE")
+	rows, err := tx.QueryContext(ctx, query, appId)
+	if err != nil {
+		pdb.logger.Error("lock-app-policy", err, lager.Data{"query": query, "app_id": appId})
+		return err
+	}
+	if err := rows.Close(); err != nil {
+		pdb.logger.Error("lock-app-policy", err, lager.Data{"query": query, "app_id": appId})
+		return err
+	}
+
+	// a locking read sees the latest committed revision, regardless of the isolation level of the transaction
+	var revision int
+	query = tx.Rebind("SELECT revision FROM policy_revision WHERE app_id = ? ORDER BY revision DESC LIMIT 1 FOR UPDATE")
+	err = tx.QueryRowContext(ctx, query, appId).Scan(&revision)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		pdb.logger.Error("get-latest-policy-revision", err, lager.Data{"query": query, "app_id": appId})
+		return err
+	}
+
+	query = tx.Rebind("INSERT INTO policy_revision (app_id, revision, policy_json, guid, author, source, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	_, err = tx.ExecContext(ctx, query, appId, revision+1, policyJson, policyGuid, change.Author, string(change.Source), time.Now().UnixNano())
+	if err != nil {
+		pdb.logger.Error("insert-policy-revision", err, lager.Data{"query": query, "app_id": appId, "revision": revision + 1, "policyGuid": policyGuid})
+	}
+	return err
+}
+
+// RetrievePolicyRevisions returns all revisions of the policy of an app, the latest first.
+func (pdb *PolicySQLDB) RetrievePolicyRevisions(ctx context.Context, appId string) ([]*models.PolicyRevision, error) {
+	query := pdb.sqldb.Rebind("SELECT revision, policy_json, guid, author, source, timestamp FROM policy_revision WHERE app_id = ? ORDER BY revision DESC")
+	rows, err := pdb.sqldb.QueryContext(ctx, query, appId)
+	if err != nil {
+		pdb.logger.Error("retrieve-policy-revisions", err, lager.Data{"query": query, "app_id": appId})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	revisions := []*models.PolicyRevision{}
+	for rows.Next() {
+		revision := &models.PolicyRevision{AppId: appId}
+		if err := scanPolicyRevision(rows, revision); err != nil {
+			pdb.logger.Error("retrieve-policy-revisions-scan", err, lager.Data{"app_id": appId})
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+// GetPolicyRevision returns a revision of the policy of an app, or nil if it does not exist.
+func (pdb *PolicySQLDB) GetPolicyRevision(ctx context.Context, appId string, revision int) (*models.PolicyRevision, error) {
+	query := pdb.sqldb.Rebind("SELECT revision, policy_json, guid, author, source, timestamp FROM policy_revision WHERE app_id = ? AND revision = ?")
+	rows, err := pdb.sqldb.QueryContext(ctx, query, appId, revision)
+	if err != nil {
+		pdb.logger.Error("get-policy-revision", err, lager.Data{"query": query, "app_id": appId, "revision": revision})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	policyRevision := &models.PolicyRevision{AppId: appId}
+	if err := scanPolicyRevision(rows, policyRevision); err != nil {
+		pdb.logger.Error("get-policy-revision-scan", err, lager.Data{"app_id": appId, "revision": revision})
+		return nil, err
+	}
+	return policyRevision, nil
+}
+
+func scanPolicyRevision(rows *sql.Rows, revision *models.PolicyRevision) error {
+	var policyJson []byte
+	var source string
+	if err := rows.Scan(&revision.Revision, &policyJson, &revision.PolicyGuid, &revision.Author, &source, &revision.Timestamp); err != nil {
+		return err
+	}
+	revision.Source = models.PolicySource(source)
+	revision.Policy = &models.PolicyDefinition{}
+	return json.Unmarshal(policyJson, revision.Policy)
+}
+
+func (pdb *PolicySQLDB) SetOrUpdateDefaultAppPolicy(ctx context.Context, boundApps []string, oldPolicyGuid string, policy *models.PolicyDefinition, newPolicyGuid string, change models.PolicyChange) ([]string, error) {
 	if len(boundApps) == 0 && oldPolicyGuid == "" {
 		return nil, nil
 	}
@@ -283,6 +501,12 @@ func (pdb *PolicySQLDB) SetOrUpdateDefaultAppPolicy(ctx context.Context, boundAp
 		}
 	}
 
+	for _, appId := range modifiedApps {
+		if err := pdb.insertPolicyRevision(ctx, tx, appId, policyJson, newPolicyGuid, change); err != nil {
+			return nil, err
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		pdb.logger.Error("update-app-policies-commit", err, lager.Data{"newPolicyGuid": newPolicyGuid, "policyJson": policyJson})
@@ -300,6 +524,48 @@ func (pdb *PolicySQLDB) DeletePolicy(ctx context.Context, appId string) error {
 	return err
 }
 
+// DeletePolicyAndRevisions deletes the policy of an app which is unbound or no longer exists together with all its
+// revisions, so that an app which is bound again starts a new revision history.
+func (pdb *PolicySQLDB) DeletePolicyAndRevisions(ctx context.Context, appId string) error {
+	tx, err := pdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		pdb.logger.Error("delete-policy-and-revisions-begin-transaction", err, lager.Data{"appId": appId})
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"policy_json", "policy_revision"} {
+		query := tx.Rebind("DELETE FROM " + table + " WHERE app_id = ?") // #nosec G202 -- table names are constants
+		if _, err = tx.ExecContext(ctx, query, appId); err != nil {
+			pdb.logger.Error("delete-policy-and-revisions", err, lager.Data{"query": query, "appId": appId})
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		pdb.logger.Error("delete-policy-and-revisions-commit", err, lager.Data{"appId": appId})
+	}
+	return err
+}
+
+// CompareAndDeleteAppPolicy deletes the policy of an app only if it is still saved under expectedPolicyGuid. It
+// returns false without deleting anything if the policy has been changed or deleted in the meantime.
+func (pdb *PolicySQLDB) CompareAndDeleteAppPolicy(ctx context.Context, appId string, expectedPolicyGuid string) (bool, error) {
+	query := pdb.sqldb.Rebind("DELETE FROM policy_json WHERE app_id = ? AND COALESCE(guid, '') = ?")
+	res, err := pdb.sqldb.ExecContext(ctx, query, appId, expectedPolicyGuid)
+	if err != nil {
+		pdb.logger.Error("compare-and-delete-app-policy", err, lager.Data{"query": query, "appId": appId, "expectedPolicyGuid": expectedPolicyGuid})
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		pdb.logger.Error("compare-and-delete-app-policy-determine-rows-affected", err, lager.Data{"query": query, "appId": appId})
+		return false, err
+	}
+	return count == 1, nil
+}
+
 func (pdb *PolicySQLDB) DeletePoliciesByPolicyGuid(ctx context.Context, policyGuid string) ([]string, error) {
 	var appIds []string
 
@@ -406,3 +672,120 @@ func (pdb *PolicySQLDB) DeleteCredential(ctx context.Context, appId string) erro
 	}
 	return err
 }
+
+// SaveAppGroup creates or replaces an app group together with its apps. An app can only belong to one group, so it
+// returns db.ErrConflict without saving anything if an app of the group belongs to another group.
+func (pdb *PolicySQLDB) SaveAppGroup(ctx context.Context, group *models.AppGroup) error {
+	groupJson, err := json.Marshal(group)
+	if err != nil {
+		return fmt.Errorf("SaveAppGroup failed to marshal group: %w", err)
+	}
+
+	tx, err := pdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		pdb.logger.Error("save-app-group-begin-transaction", err, lager.Data{"groupId": group.GroupId})
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query, args, err := sqlx.In("SELECT COUNT(*) FROM app_group_member WHERE app_id IN (?) AND group_id <> ?", group.AppIds(), group.GroupId)
+	if err != nil {
+		return err
+	}
+	query = tx.Rebind(query)
+	var count int
+	if err = tx.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		pdb.logger.Error("save-app-group-check-members", err, lager.Data{"query": query, "groupId": group.GroupId})
+		return err
+	}
+	if count > 0 {
+		return db.ErrConflict
+	}
+
+	var upsert string
+	queryPrefix := "INSERT INTO app_group (group_id, group_json) VALUES (?, ?) "
+	switch pdb.sqldb.DriverName() {
+	case "pgx":
+		upsert = tx.Rebind(queryPrefix + "ON CONFLICT(group_id) DO UPDATE SET group_json=EXCLUDED.group_json")
+	case "mysql":
+		upsert = tx.Rebind(queryPrefix + "ON DUPLICATE KEY UPDATE group_json=VALUES(group_json)")
+	}
+	if _, err = tx.ExecContext(ctx, upsert, group.GroupId, groupJson); err != nil {
+		pdb.logger.Error("save-app-group", err, lager.Data{"query": upsert, "groupId": group.GroupId})
+		return err
+	}
+
+	query = tx.Rebind("DELETE FROM app_group_member WHERE group_id = ?")
+	if _, err = tx.ExecContext(ctx, query, group.GroupId); err != nil {
+		pdb.logger.Error("save-app-group-delete-members", err, lager.Data{"query": query, "groupId": group.GroupId})
+		return err
+	}
+	query = tx.Rebind("INSERT INTO app_group_member (app_id, group_id) VALUES (?, ?)")
+	for _, appId := range group.AppIds() {
+		if _, err = tx.ExecContext(ctx, query, appId, group.GroupId); err != nil {
+			pdb.logger.Error("save-app-group-insert-member", err, lager.Data{"query": query, "groupId": group.GroupId, "appId": appId})
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		pdb.logger.Error("save-app-group-commit", err, lager.Data{"groupId": group.GroupId})
+	}
+	return err
+}
+
+// GetAppGroup returns an app group, or nil if it does not exist.
+func (pdb *PolicySQLDB) GetAppGroup(ctx context.Context, groupId string) (*models.AppGroup, error) {
+	query := pdb.sqldb.Rebind("SELECT group_json FROM app_group WHERE group_id = ?")
+	return pdb.getAppGroup(ctx, query, groupId)
+}
+
+// GetAppGroupByAppId returns the app group an app belongs to as leader or member, or nil if it belongs to no group.
+func (pdb *PolicySQLDB) GetAppGroupByAppId(ctx context.Context, appId string) (*models.AppGroup, error) {
+	query := pdb.sqldb.Rebind("SELECT g.group_json FROM app_group g JOIN app_group_member m ON g.group_id = m.group_id WHERE m.app_id = ?")
+	return pdb.getAppGroup(ctx, query, appId)
+}
+
+func (pdb *PolicySQLDB) getAppGroup(ctx context.Context, query string, arg string) (*models.AppGroup, error) {
+	var groupJson []byte
+	err := pdb.sqldb.QueryRowContext(ctx, query, arg).Scan(&groupJson)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		pdb.logger.Error("get-app-group", err, lager.Data{"query": query, "arg": arg})
+		return nil, err
+	}
+
+	group := &models.AppGroup{}
+	if err = json.Unmarshal(groupJson, group); err != nil {
+		pdb.logger.Error("get-app-group-unmarshal", err, lager.Data{"groupJson": string(groupJson)})
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteAppGroup deletes an app group together with its apps.
+func (pdb *PolicySQLDB) DeleteAppGroup(ctx context.Context, groupId string) error {
+	tx, err := pdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		pdb.logger.Error("delete-app-group-begin-transaction", err, lager.Data{"groupId": groupId})
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"app_group_member", "app_group"} {
+		query := tx.Rebind("DELETE FROM " + table + " WHERE group_id = ?") // #nosec G202 -- table names are constants
+		if _, err = tx.ExecContext(ctx, query, groupId); err != nil {
+			pdb.logger.Error("delete-app-group", err, lager.Data{"query": query, "groupId": groupId})
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		pdb.logger.Error("delete-app-group-commit", err, lager.Data{"groupId": groupId})
+	}
+	return err
+}
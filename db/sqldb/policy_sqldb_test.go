@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/testhelpers"
@@ -82,6 +83,9 @@ var _ = Describe("PolicySQLDB", func() {
 		deletePolicies(pdb, logger, policyGuid, policyGuid2, policyGuid3)
 		deleteApps(pdb, logger, appId, appId2, appId3)
 		deleteCredentials(pdb, logger, appId, appId2, appId3)
+		for _, id := range []string{appId, appId2, appId3} {
+			removePolicyRevisionsForApp(id)
+		}
 	})
 
 	Describe("NewPolicySQLDB", func() {
@@ -213,6 +217,83 @@ var _ = Describe("PolicySQLDB", func() {
 		})
 	})
 
+	Describe("GetAppPolicies", func() {
+		var policies map[string]*models.PolicyDefinition
+
+		BeforeEach(func() {
+			insertPolicy(appId, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, policyGuid)
+			insertPolicy(appId2, &models.PolicyDefinition{InstanceMin: 2, InstanceMax: 8}, policyGuid)
+		})
+
+		JustBeforeEach(func() {
+			policies, err = pdb.GetAppPolicies(context.Background(), []string{appId, appId2, appId3})
+		})
+
+		It("returns the policies of the apps which have one", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies).To(Equal(map[string]*models.PolicyDefinition{
+				appId:  {InstanceMin: 1, InstanceMax: 6},
+				appId2: {InstanceMin: 2, InstanceMax: 8},
+			}))
+		})
+	})
+
+	Describe("GetAppPolicyWithGuid", func() {
+		var guid string
+
+		JustBeforeEach(func() {
+			scalingPolicy, guid, err = pdb.GetAppPolicyWithGuid(context.Background(), appId)
+		})
+
+		Context("when policy table has the app", func() {
+			BeforeEach(func() {
+				insertPolicy(appId, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, policyGuid)
+			})
+
+			It("returns the policy and its guid", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingPolicy).To(Equal(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}))
+				Expect(guid).To(Equal(policyGuid))
+			})
+		})
+
+		Context("when policy table does not have the app", func() {
+			It("should return nil", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingPolicy).To(BeNil())
+				Expect(guid).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("GetAppIdsSharingPolicy", func() {
+		var appIds []string
+
+		JustBeforeEach(func() {
+			appIds, err = pdb.GetAppIdsSharingPolicy(context.Background(), appId)
+		})
+
+		Context("when other apps have a policy with the same guid", func() {
+			BeforeEach(func() {
+				insertPolicy(appId, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, policyGuid)
+				insertPolicy(appId2, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, policyGuid)
+				insertPolicy(appId3, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, policyGuid2)
+			})
+
+			It("returns the app and the apps sharing its policy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appIds).To(ConsistOf(appId, appId2))
+			})
+		})
+
+		Context("when policy table does not have the app", func() {
+			It("returns no apps", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appIds).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("retrieve all policies", Serial, func() {
 
 		JustBeforeEach(func() {
@@ -275,7 +356,7 @@ var _ = Describe("PolicySQLDB", func() {
 					}]
 				}`
 				Expect(json.Unmarshal([]byte(policyJsonStr), &policy)).ToNot(HaveOccurred())
-				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid)
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid, models.PolicyChange{Author: "a-user", Source: models.PolicySourcePublicAPI})
 			})
 			It("saves the policy", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -312,7 +393,7 @@ var _ = Describe("PolicySQLDB", func() {
 					}]
 				}`
 				Expect(json.Unmarshal([]byte(policyJsonStr), &policy)).ToNot(HaveOccurred())
-				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid)
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid, models.PolicyChange{Author: "a-user", Source: models.PolicySourcePublicAPI})
 			})
 			It("updates the policy", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -321,6 +402,148 @@ var _ = Describe("PolicySQLDB", func() {
 				Expect(formatPolicyString(getAppPolicy(appId))).To(Equal(policyString))
 			})
 		})
+
+		Context("when the policy is saved several times", func() {
+			JustBeforeEach(func() {
+				policy = &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid, models.PolicyChange{Author: "a-user", Source: models.PolicySourceBrokerBind})
+				Expect(err).NotTo(HaveOccurred())
+				policy = &models.PolicyDefinition{InstanceMin: 2, InstanceMax: 5}
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid2, models.PolicyChange{Author: "another-user", Source: models.PolicySourcePublicAPI})
+			})
+			It("keeps a revision for every save", func() {
+				Expect(err).NotTo(HaveOccurred())
+				revisions, err := pdb.RetrievePolicyRevisions(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(HaveLen(2))
+
+				Expect(revisions[0].Revision).To(Equal(2))
+				Expect(revisions[0].PolicyGuid).To(Equal(policyGuid2))
+				Expect(revisions[0].Author).To(Equal("another-user"))
+				Expect(revisions[0].Source).To(Equal(models.PolicySourcePublicAPI))
+				Expect(revisions[0].Policy).To(Equal(&models.PolicyDefinition{InstanceMin: 2, InstanceMax: 5}))
+
+				Expect(revisions[1].Revision).To(Equal(1))
+				Expect(revisions[1].PolicyGuid).To(Equal(policyGuid))
+				Expect(revisions[1].Author).To(Equal("a-user"))
+				Expect(revisions[1].Source).To(Equal(models.PolicySourceBrokerBind))
+				Expect(revisions[1].Policy).To(Equal(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}))
+				Expect(revisions[1].Timestamp).To(BeNumerically("<=", revisions[0].Timestamp))
+			})
+		})
+
+		Context("when the policy is saved concurrently", func() {
+			const saves = 5
+			var errs []error
+
+			BeforeEach(func() {
+				insertPolicy(appId, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}, policyGuid)
+			})
+			JustBeforeEach(func() {
+				errs = make([]error, saves)
+				var wg sync.WaitGroup
+				for i := range saves {
+					wg.Go(func() {
+						policy := &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 5 + i}
+						errs[i] = pdb.SaveAppPolicy(context.Background(), appId, policy, fmt.Sprintf("concurrent-policy-guid-%d", i), models.PolicyChange{Author: "a-user", Source: models.PolicySourcePublicAPI})
+					})
+				}
+				wg.Wait()
+			})
+			It("records every save under its own revision", func() {
+				for _, err := range errs {
+					Expect(err).NotTo(HaveOccurred())
+				}
+				revisions, err := pdb.RetrievePolicyRevisions(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(HaveLen(saves))
+				for i, revision := range revisions {
+					Expect(revision.Revision).To(Equal(saves - i))
+				}
+			})
+		})
+	})
+
+	Describe("CompareAndSwapAppPolicy", func() {
+		var (
+			saved  bool
+			policy *models.PolicyDefinition
+		)
+
+		BeforeEach(func() {
+			insertPolicy(appId, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}, policyGuid)
+			policy = &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 8}
+		})
+
+		Context("when the policy is still saved under the expected guid", func() {
+			JustBeforeEach(func() {
+				saved, err = pdb.CompareAndSwapAppPolicy(context.Background(), appId, policyGuid, policy, policyGuid2, models.PolicyChange{Author: "a-user", Source: models.PolicySourcePublicAPI})
+			})
+
+			It("replaces the policy and records a revision", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(saved).To(BeTrue())
+				currentPolicy, guid, err := pdb.GetAppPolicyWithGuid(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(currentPolicy).To(Equal(policy))
+				Expect(guid).To(Equal(policyGuid2))
+
+				revisions, err := pdb.RetrievePolicyRevisions(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(HaveLen(1))
+				Expect(revisions[0].PolicyGuid).To(Equal(policyGuid2))
+			})
+		})
+
+		Context("when the policy has been changed in the meantime", func() {
+			JustBeforeEach(func() {
+				saved, err = pdb.CompareAndSwapAppPolicy(context.Background(), appId, anotherPolicyGuid, policy, policyGuid2, models.PolicyChange{Author: "a-user", Source: models.PolicySourcePublicAPI})
+			})
+
+			It("keeps the current policy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(saved).To(BeFalse())
+				currentPolicy, guid, err := pdb.GetAppPolicyWithGuid(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(currentPolicy).To(Equal(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}))
+				Expect(guid).To(Equal(policyGuid))
+
+				revisions, err := pdb.RetrievePolicyRevisions(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("GetPolicyRevision", func() {
+		var revision *models.PolicyRevision
+
+		BeforeEach(func() {
+			err = pdb.SaveAppPolicy(context.Background(), appId, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}, policyGuid, models.PolicyChange{Author: "a-user", Source: models.PolicySourcePublicAPI})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the revision exists", func() {
+			JustBeforeEach(func() {
+				revision, err = pdb.GetPolicyRevision(context.Background(), appId, 1)
+			})
+			It("returns the revision", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revision.AppId).To(Equal(appId))
+				Expect(revision.Revision).To(Equal(1))
+				Expect(revision.Policy).To(Equal(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}))
+			})
+		})
+
+		Context("when the revision does not exist", func() {
+			JustBeforeEach(func() {
+				revision, err = pdb.GetPolicyRevision(context.Background(), appId, 2)
+			})
+			It("returns nil", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revision).To(BeNil())
+			})
+		})
 	})
 
 	Describe("DeletePolicy", func() {
@@ -358,6 +581,99 @@ var _ = Describe("PolicySQLDB", func() {
 		})
 	})
 
+	Describe("DeletePolicyAndRevisions", func() {
+		JustBeforeEach(func() {
+			err = pdb.DeletePolicyAndRevisions(context.Background(), appId)
+		})
+
+		Context("when there is no policy in the table", func() {
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the apps have policies with revisions", func() {
+			BeforeEach(func() {
+				change := models.PolicyChange{Author: "a-user", Source: models.PolicySourceBrokerBind}
+				for _, id := range []string{appId, appId2} {
+					err = pdb.SaveAppPolicy(context.Background(), id, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}, policyGuid, change)
+					Expect(err).NotTo(HaveOccurred())
+					err = pdb.SaveAppPolicy(context.Background(), id, &models.PolicyDefinition{InstanceMin: 2, InstanceMax: 5}, policyGuid2, change)
+					Expect(err).NotTo(HaveOccurred())
+				}
+			})
+
+			It("deletes the policy and the revisions of the app", func() {
+				Expect(err).NotTo(HaveOccurred())
+				policy, err := pdb.GetAppPolicy(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy).To(BeNil())
+				revisions, err := pdb.RetrievePolicyRevisions(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(BeEmpty())
+			})
+
+			It("keeps the policy and the revisions of other apps", func() {
+				Expect(err).NotTo(HaveOccurred())
+				policy, err := pdb.GetAppPolicy(context.Background(), appId2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy).NotTo(BeNil())
+				revisions, err := pdb.RetrievePolicyRevisions(context.Background(), appId2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(HaveLen(2))
+			})
+		})
+
+		Context("when there is database error", func() {
+			BeforeEach(func() {
+				pdb.Close()
+			})
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("CompareAndDeleteAppPolicy", func() {
+		var (
+			deleted            bool
+			expectedPolicyGuid string
+		)
+
+		BeforeEach(func() {
+			insertPolicy(appId, &models.PolicyDefinition{InstanceMin: 1, InstanceMax: 4}, policyGuid)
+			expectedPolicyGuid = policyGuid
+		})
+
+		JustBeforeEach(func() {
+			deleted, err = pdb.CompareAndDeleteAppPolicy(context.Background(), appId, expectedPolicyGuid)
+		})
+
+		Context("when the policy is still saved under the expected guid", func() {
+			It("deletes the policy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deleted).To(BeTrue())
+				policy, err := pdb.GetAppPolicy(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy).To(BeNil())
+			})
+		})
+
+		Context("when the policy has been changed in the meantime", func() {
+			BeforeEach(func() {
+				expectedPolicyGuid = anotherPolicyGuid
+			})
+
+			It("keeps the policy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deleted).To(BeFalse())
+				policy, err := pdb.GetAppPolicy(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy).NotTo(BeNil())
+			})
+		})
+	})
+
 	Describe("DeletePoliciesByPolicyGuid", func() {
 		var updatedApps []string
 
@@ -414,7 +730,7 @@ var _ = Describe("PolicySQLDB", func() {
 		})
 		JustBeforeEach(func() {
 			Expect(json.Unmarshal([]byte(newPolicyStr), &newPolicy)).ToNot(HaveOccurred())
-			modifiedApps, err = pdb.SetOrUpdateDefaultAppPolicy(context.Background(), []string{appId, appId2, appId3}, policyGuid, newPolicy, policyGuid2)
+			modifiedApps, err = pdb.SetOrUpdateDefaultAppPolicy(context.Background(), []string{appId, appId2, appId3}, policyGuid, newPolicy, policyGuid2, models.PolicyChange{Source: models.PolicySourceDefaultPolicy})
 		})
 
 		Context("when policy table is empty", func() {
@@ -443,6 +759,19 @@ var _ = Describe("PolicySQLDB", func() {
 				Expect(getAppPolicy(appId3)).To(MatchJSON(newPolicy))
 				Expect(getAppPolicy("unrelated-app-id")).NotTo(MatchJSON(newPolicy))
 			})
+
+			It("records a revision for the modified apps only", func() {
+				Expect(err).NotTo(HaveOccurred())
+				revisions, err := pdb.RetrievePolicyRevisions(context.Background(), appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(HaveLen(1))
+				Expect(revisions[0].Source).To(Equal(models.PolicySourceDefaultPolicy))
+				Expect(revisions[0].PolicyGuid).To(Equal(policyGuid2))
+
+				revisions, err = pdb.RetrievePolicyRevisions(context.Background(), appId2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(BeEmpty())
+			})
 		})
 
 		Context("when there is database error", func() {
@@ -516,6 +845,110 @@ var _ = Describe("PolicySQLDB", func() {
 			})
 		})
 	})
+	Describe("AppGroups", func() {
+		var (
+			groupId string
+			group   *models.AppGroup
+			result  *models.AppGroup
+		)
+
+		BeforeEach(func() {
+			groupId = addProcessIdTo("a-group-id")
+			ratio := 2.0
+			group = &models.AppGroup{
+				GroupId:       groupId,
+				LeadingMetric: models.LeadingMetric{AppId: appId, MetricType: "throughput"},
+				Members:       []models.AppGroupMember{{AppId: appId2, Ratio: &ratio}},
+			}
+			for _, id := range []string{groupId, addProcessIdTo("another-group-id")} {
+				Expect(pdb.DeleteAppGroup(context.Background(), id)).To(Succeed())
+			}
+		})
+
+		Context("when the group is saved", func() {
+			JustBeforeEach(func() {
+				err = pdb.SaveAppGroup(context.Background(), group)
+			})
+
+			It("can be retrieved by its id and by the ids of its apps", func() {
+				Expect(err).NotTo(HaveOccurred())
+				result, err = pdb.GetAppGroup(context.Background(), groupId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(group))
+				for _, id := range []string{appId, appId2} {
+					result, err = pdb.GetAppGroupByAppId(context.Background(), id)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(group))
+				}
+				result, err = pdb.GetAppGroupByAppId(context.Background(), appId3)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(BeNil())
+			})
+
+			Context("when the group is saved again with other members", func() {
+				JustBeforeEach(func() {
+					group.Members[0].AppId = appId3
+					err = pdb.SaveAppGroup(context.Background(), group)
+				})
+
+				It("replaces the members", func() {
+					Expect(err).NotTo(HaveOccurred())
+					result, err = pdb.GetAppGroupByAppId(context.Background(), appId2)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(BeNil())
+					result, err = pdb.GetAppGroupByAppId(context.Background(), appId3)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(group))
+				})
+			})
+
+			Context("when an app of the group belongs to another group", func() {
+				JustBeforeEach(func() {
+					anotherGroup := &models.AppGroup{
+						GroupId:       addProcessIdTo("another-group-id"),
+						LeadingMetric: models.LeadingMetric{AppId: appId3, MetricType: "throughput"},
+						Members:       []models.AppGroupMember{{AppId: appId2, Ratio: group.Members[0].Ratio}},
+					}
+					err = pdb.SaveAppGroup(context.Background(), anotherGroup)
+				})
+
+				It("returns a conflict and does not save the group", func() {
+					Expect(err).To(MatchError(db.ErrConflict))
+					result, err = pdb.GetAppGroupByAppId(context.Background(), appId3)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(BeNil())
+				})
+			})
+
+			Context("when the group is deleted", func() {
+				JustBeforeEach(func() {
+					err = pdb.DeleteAppGroup(context.Background(), groupId)
+				})
+
+				It("removes the group and its apps", func() {
+					Expect(err).NotTo(HaveOccurred())
+					result, err = pdb.GetAppGroup(context.Background(), groupId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(BeNil())
+					result, err = pdb.GetAppGroupByAppId(context.Background(), appId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(BeNil())
+				})
+			})
+		})
+
+		Context("when there is database error", func() {
+			BeforeEach(func() {
+				_ = pdb.Close()
+			})
+
+			It("should error", func() {
+				_, err = pdb.GetAppGroup(context.Background(), groupId)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
 	Describe("DeleteCred", func() {
 
 		JustBeforeEach(func() {
@@ -2,8 +2,11 @@ package sqldb
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
@@ -67,10 +70,14 @@ func (sdb *ScalingEngineSQLDB) Close() error {
 
 func (sdb *ScalingEngineSQLDB) SaveScalingHistory(history *models.AppScalingHistory) error {
 	query := sdb.sqldb.Rebind("INSERT INTO scalinghistory" +
-		"(appid, timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error) " +
-		" VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		"(appid, timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error, emergency, " +
+		"ruleindex, metrictype, observedvalue, threshold, operator, adjustment, scheduleid, cooldownexpiredat, correlationid, decisionid) " +
+		" VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	_, err := sdb.sqldb.Exec(query, history.AppId, history.Timestamp, history.ScalingType, history.Status,
-		history.OldInstances, history.NewInstances, history.Reason, history.Message, history.Error)
+		history.OldInstances, history.NewInstances, history.Reason, history.Message, history.Error, boolToInt(history.Emergency),
+		history.RuleIndex, nullableString(history.MetricType), history.ObservedValue, history.Threshold, nullableString(history.Operator),
+		nullableString(history.Adjustment), nullableString(history.ScheduleId), history.CooldownExpiredAt, nullableString(history.CorrelationId),
+		nullableString(history.DecisionId))
 
 	if err != nil {
 		return fmt.Errorf("saveScalingHistory failed appId(%s) scalingtype(%d) reason(%s): %w", history.AppId, history.ScalingType, history.Reason, err)
@@ -78,30 +85,33 @@ func (sdb *ScalingEngineSQLDB) SaveScalingHistory(history *models.AppScalingHist
 	return nil
 }
 
-func (sdb *ScalingEngineSQLDB) CountScalingHistories(ctx context.Context, appId string, start int64, end int64, includeAll bool) (int, error) {
-	query := sdb.sqldb.Rebind("SELECT COUNT(*) FROM scalinghistory WHERE appid = ? AND timestamp >= ? AND timestamp <= ?" + statusFilter(includeAll))
+func (sdb *ScalingEngineSQLDB) CountScalingHistories(ctx context.Context, appId string, start int64, end int64, filter db.ScalingHistoryFilter) (int, error) {
+	filterQuery, filterArgs := historyFilter(filter)
+	query := sdb.sqldb.Rebind("SELECT COUNT(*) FROM scalinghistory WHERE appid = ? AND timestamp >= ? AND timestamp <= ?" + filterQuery)
 
 	if end < 0 {
 		end = time.Now().UnixNano()
 	}
 
 	var count int
-	err := sdb.sqldb.GetContext(ctx, &count, query, appId, start, end)
+	args := append([]interface{}{appId, start, end}, filterArgs...)
+	err := sdb.sqldb.GetContext(ctx, &count, query, args...)
 	if err != nil {
 		sdb.logger.Error("count-scaling-histories", err,
-			lager.Data{"query": query, "appid": appId, "start": start, "end": end})
+			lager.Data{"query": query, "appid": appId, "start": start, "end": end, "filter": filter})
 		return 0, err
 	}
 
 	return count, nil
 }
 
-func (sdb *ScalingEngineSQLDB) RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType db.OrderType, includeAll bool, page int, resultsPerPage int) ([]*models.AppScalingHistory, error) {
-	query := sdb.sqldb.Rebind("SELECT timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error FROM scalinghistory WHERE" +
+func (sdb *ScalingEngineSQLDB) RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType db.OrderType, filter db.ScalingHistoryFilter, page int, resultsPerPage int) ([]*models.AppScalingHistory, error) {
+	filterQuery, filterArgs := historyFilter(filter)
+	query := sdb.sqldb.Rebind("SELECT " + scalingHistoryColumns + " FROM scalinghistory WHERE" +
 		" appid = ? " +
 		" AND timestamp >= ?" +
 		" AND timestamp <= ?" +
-		statusFilter(includeAll) +
+		filterQuery +
 		" ORDER BY timestamp " + orderTypeToString(orderType) +
 		" LIMIT ? OFFSET ?")
 
@@ -110,47 +120,433 @@ func (sdb *ScalingEngineSQLDB) RetrieveScalingHistories(ctx context.Context, app
 	}
 
 	histories := []*models.AppScalingHistory{}
-	rows, err := sdb.sqldb.QueryContext(ctx, query, appId, start, end, resultsPerPage, (page-1)*resultsPerPage)
+	args := append([]interface{}{appId, start, end}, filterArgs...)
+	args = append(args, resultsPerPage, (page-1)*resultsPerPage)
+	rows, err := sdb.sqldb.QueryContext(ctx, query, args...)
 	if err != nil {
 		sdb.logger.Error("retrieve-scaling-histories", err,
-			lager.Data{"query": query, "appid": appId, "start": start, "end": end, "orderType": orderType})
+			lager.Data{"query": query, "appid": appId, "start": start, "end": end, "orderType": orderType, "filter": filter})
 		return nil, err
 	}
 
 	defer func() { _ = rows.Close() }()
 
-	var timestamp int64
-	var scalingType, status, oldInstances, newInstances int
+	for rows.Next() {
+		history, err := scanScalingHistory(rows, appId)
+		if err != nil {
+			sdb.logger.Error("retrieve-scaling-history-scan", err)
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+	return histories, rows.Err()
+}
+
+// scalingHistoryColumns are the columns of the scalinghistory table read by scanScalingHistory.
+const scalingHistoryColumns = "timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error, emergency," +
+	" ruleindex, metrictype, observedvalue, threshold, operator, adjustment, scheduleid, cooldownexpiredat, correlationid, decisionid"
+
+func scanScalingHistory(rows *sql.Rows, appId string) (*models.AppScalingHistory, error) {
+	var timestamp, cooldownExpiredAt int64
+	var scalingType, status, oldInstances, newInstances, emergency int
 	var reason, message, errorMsg string
+	var ruleIndex, observedValue, threshold sql.NullInt64
+	var metricType, operator, adjustment, scheduleId, correlationId, decisionId sql.NullString
+
+	if err := rows.Scan(&timestamp, &scalingType, &status, &oldInstances, &newInstances, &reason, &message, &errorMsg, &emergency,
+		&ruleIndex, &metricType, &observedValue, &threshold, &operator, &adjustment, &scheduleId, &cooldownExpiredAt, &correlationId, &decisionId); err != nil {
+		return nil, err
+	}
+
+	history := &models.AppScalingHistory{
+		AppId:        appId,
+		Timestamp:    timestamp,
+		ScalingType:  models.ScalingType(scalingType),
+		Status:       models.ScalingStatus(status),
+		OldInstances: oldInstances,
+		NewInstances: newInstances,
+		Reason:       reason,
+		Message:      message,
+		Error:        errorMsg,
+		Emergency:    emergency != 0,
+
+		MetricType:        metricType.String,
+		Operator:          operator.String,
+		Adjustment:        adjustment.String,
+		ScheduleId:        scheduleId.String,
+		CooldownExpiredAt: cooldownExpiredAt,
+		CorrelationId:     correlationId.String,
+		DecisionId:        decisionId.String,
+	}
+	if ruleIndex.Valid {
+		index := int(ruleIndex.Int64)
+		history.RuleIndex = &index
+	}
+	if observedValue.Valid {
+		history.ObservedValue = &observedValue.Int64
+	}
+	if threshold.Valid {
+		history.Threshold = &threshold.Int64
+	}
+	return history, nil
+}
+
+// SaveScalingDecision stores the trace of a scaling decision, its scaling history is stored separately.
+func (sdb *ScalingEngineSQLDB) SaveScalingDecision(ctx context.Context, decision *models.DecisionTrace) error {
+	evaluation, err := json.Marshal(decision.Evaluation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal evaluation of decision %s: %w", decision.DecisionId, err)
+	}
+	var cfResponse *string
+	if decision.CFResponse != nil {
+		data, err := json.Marshal(decision.CFResponse)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cf response of decision %s: %w", decision.DecisionId, err)
+		}
+		cfResponse = nullableString(string(data))
+	}
+
+	query := sdb.sqldb.Rebind("INSERT INTO scalingdecision(decisionid, appid, timestamp, evaluation, cfresponse) VALUES(?, ?, ?, ?, ?)")
+	_, err = sdb.sqldb.ExecContext(ctx, query, decision.DecisionId, decision.AppId, decision.Timestamp, string(evaluation), cfResponse)
+	if err != nil {
+		sdb.logger.Error("save-scaling-decision", err, lager.Data{"query": query, "decisionid": decision.DecisionId, "appid": decision.AppId})
+		return err
+	}
+	return nil
+}
+
+// RetrieveScalingDecision returns the trace of a scaling decision together with its scaling history,
+// or nil if there is no decision with the id for the app.
+func (sdb *ScalingEngineSQLDB) RetrieveScalingDecision(ctx context.Context, appId string, decisionId string) (*models.DecisionTrace, error) {
+	query := sdb.sqldb.Rebind("SELECT timestamp, evaluation, cfresponse FROM scalingdecision WHERE appid = ? AND decisionid = ?")
+
+	decision := &models.DecisionTrace{DecisionId: decisionId, AppId: appId}
+	var evaluation string
+	var cfResponse sql.NullString
+	err := sdb.sqldb.QueryRowContext(ctx, query, appId, decisionId).Scan(&decision.Timestamp, &evaluation, &cfResponse)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		sdb.logger.Error("retrieve-scaling-decision", err, lager.Data{"query": query, "appid": appId, "decisionid": decisionId})
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(evaluation), &decision.Evaluation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal evaluation of decision %s: %w", decisionId, err)
+	}
+	if cfResponse.Valid {
+		decision.CFResponse = &models.CFScalingResponse{}
+		if err := json.Unmarshal([]byte(cfResponse.String), decision.CFResponse); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cf response of decision %s: %w", decisionId, err)
+		}
+	}
+
+	query = sdb.sqldb.Rebind("SELECT " + scalingHistoryColumns + " FROM scalinghistory WHERE appid = ? AND decisionid = ?")
+	rows, err := sdb.sqldb.QueryContext(ctx, query, appId, decisionId)
+	if err != nil {
+		sdb.logger.Error("retrieve-scaling-decision-history", err, lager.Data{"query": query, "appid": appId, "decisionid": decisionId})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if rows.Next() {
+		if decision.Scaling, err = scanScalingHistory(rows, appId); err != nil {
+			sdb.logger.Error("retrieve-scaling-decision-history-scan", err)
+			return nil, err
+		}
+	}
+	return decision, rows.Err()
+}
+
+// SaveGroupScalingHistory stores the record of a scaling of an app group.
+func (sdb *ScalingEngineSQLDB) SaveGroupScalingHistory(ctx context.Context, history *models.GroupScalingHistory) error {
+	apps, err := json.Marshal(history.Apps)
+	if err != nil {
+		return fmt.Errorf("failed to marshal apps of group scaling history of group %s: %w", history.GroupId, err)
+	}
+
+	query := sdb.sqldb.Rebind("INSERT INTO groupscalinghistory(groupid, timestamp, metrictype, reason, status, apps, error) VALUES(?, ?, ?, ?, ?, ?, ?)")
+	_, err = sdb.sqldb.ExecContext(ctx, query, history.GroupId, history.Timestamp, history.MetricType, nullableString(history.Reason), history.Status, string(apps), nullableString(history.Error))
+	if err != nil {
+		sdb.logger.Error("save-group-scaling-history", err, lager.Data{"query": query, "groupid": history.GroupId})
+		return err
+	}
+	return nil
+}
+
+// RetrieveGroupScalingHistories returns at most limit scaling histories of an app group between start and end,
+// the latest first.
+func (sdb *ScalingEngineSQLDB) RetrieveGroupScalingHistories(ctx context.Context, groupId string, start int64, end int64, limit int) ([]*models.GroupScalingHistory, error) {
+	if end < 0 {
+		end = time.Now().UnixNano()
+	}
+	query := sdb.sqldb.Rebind("SELECT timestamp, metrictype, reason, status, apps, error FROM groupscalinghistory WHERE groupid = ? AND timestamp >= ? AND timestamp <= ? ORDER BY timestamp DESC LIMIT ?")
+	rows, err := sdb.sqldb.QueryContext(ctx, query, groupId, start, end, limit)
+	if err != nil {
+		sdb.logger.Error("retrieve-group-scaling-histories", err, lager.Data{"query": query, "groupid": groupId, "start": start, "end": end})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
 
+	histories := []*models.GroupScalingHistory{}
 	for rows.Next() {
-		if err = rows.Scan(&timestamp, &scalingType, &status, &oldInstances, &newInstances, &reason, &message, &errorMsg); err != nil {
-			sdb.logger.Error("retrieve-scaling-history-scan", err)
+		history := &models.GroupScalingHistory{GroupId: groupId}
+		var reason, historyErr sql.NullString
+		var apps string
+		if err := rows.Scan(&history.Timestamp, &history.MetricType, &reason, &history.Status, &apps, &historyErr); err != nil {
+			sdb.logger.Error("retrieve-group-scaling-histories-scan", err)
+			return nil, err
+		}
+		history.Reason = reason.String
+		history.Error = historyErr.String
+		if err := json.Unmarshal([]byte(apps), &history.Apps); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal apps of group scaling history of group %s: %w", groupId, err)
+		}
+		histories = append(histories, history)
+	}
+	return histories, rows.Err()
+}
+
+// SaveScalingEvent stores an event of the live scaling event stream of an app, the id is assigned by the database.
+func (sdb *ScalingEngineSQLDB) SaveScalingEvent(ctx context.Context, event *models.ScalingEvent) error {
+	var history *string
+	if event.History != nil {
+		data, err := json.Marshal(event.History)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history of scaling event: %w", err)
+		}
+		history = nullableString(string(data))
+	}
+	var evaluation *string
+	if event.Evaluation != nil {
+		data, err := json.Marshal(event.Evaluation)
+		if err != nil {
+			return fmt.Errorf("failed to marshal evaluation of scaling event: %w", err)
+		}
+		evaluation = nullableString(string(data))
+	}
+
+	query := sdb.sqldb.Rebind("INSERT INTO scalingevent(appid, timestamp, eventtype, history, evaluation) VALUES(?, ?, ?, ?, ?)")
+	_, err := sdb.sqldb.ExecContext(ctx, query, event.AppId, event.Timestamp, event.Type, history, evaluation)
+	if err != nil {
+		sdb.logger.Error("save-scaling-event", err, lager.Data{"query": query, "appid": event.AppId, "eventtype": event.Type})
+		return err
+	}
+	return nil
+}
+
+// RetrieveScalingEvents returns at most limit events of an app with an id greater than afterId in ascending order.
+func (sdb *ScalingEngineSQLDB) RetrieveScalingEvents(ctx context.Context, appId string, afterId int64, limit int) ([]*models.ScalingEvent, error) {
+	query := sdb.sqldb.Rebind("SELECT id, timestamp, eventtype, history, evaluation FROM scalingevent WHERE appid = ? AND id > ? ORDER BY id ASC LIMIT ?")
+	rows, err := sdb.sqldb.QueryContext(ctx, query, appId, afterId, limit)
+	if err != nil {
+		sdb.logger.Error("retrieve-scaling-events", err, lager.Data{"query": query, "appid": appId, "afterId": afterId})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := []*models.ScalingEvent{}
+	for rows.Next() {
+		event := &models.ScalingEvent{AppId: appId}
+		var history, evaluation sql.NullString
+		if err := rows.Scan(&event.Id, &event.Timestamp, &event.Type, &history, &evaluation); err != nil {
+			sdb.logger.Error("retrieve-scaling-events-scan", err)
 			return nil, err
 		}
+		if history.Valid {
+			event.History = &models.AppScalingHistory{}
+			if err := json.Unmarshal([]byte(history.String), event.History); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal history of scaling event %d: %w", event.Id, err)
+			}
+		}
+		if evaluation.Valid {
+			if err := json.Unmarshal([]byte(evaluation.String), &event.Evaluation); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal evaluation of scaling event %d: %w", event.Id, err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetLastScalingEventId returns the id of the latest event of an app, or 0 if there is none.
+func (sdb *ScalingEngineSQLDB) GetLastScalingEventId(ctx context.Context, appId string) (int64, error) {
+	query := sdb.sqldb.Rebind("SELECT COALESCE(MAX(id), 0) FROM scalingevent WHERE appid = ?")
+	var lastId int64
+	if err := sdb.sqldb.QueryRowContext(ctx, query, appId).Scan(&lastId); err != nil {
+		sdb.logger.Error("get-last-scaling-event-id", err, lager.Data{"query": query, "appid": appId})
+		return 0, err
+	}
+	return lastId, nil
+}
+
+// RetrieveInstanceChanges returns the succeeded scaling histories of an app in ascending order,
+// i.e. every change of its instance count in the given time range.
+func (sdb *ScalingEngineSQLDB) RetrieveInstanceChanges(ctx context.Context, appId string, start int64, end int64) ([]*models.AppScalingHistory, error) {
+	query := sdb.sqldb.Rebind("SELECT timestamp, scalingtype, oldinstances, newinstances FROM scalinghistory WHERE" +
+		" appid = ? " +
+		" AND timestamp >= ?" +
+		" AND timestamp <= ?" +
+		" AND status = ?" +
+		" ORDER BY timestamp " + db.ASCSTR)
 
-		history := models.AppScalingHistory{
-			AppId:        appId,
-			Timestamp:    timestamp,
-			ScalingType:  models.ScalingType(scalingType),
-			Status:       models.ScalingStatus(status),
-			OldInstances: oldInstances,
-			NewInstances: newInstances,
-			Reason:       reason,
-			Message:      message,
-			Error:        errorMsg,
+	histories := []*models.AppScalingHistory{}
+	rows, err := sdb.sqldb.QueryContext(ctx, query, appId, start, end, models.ScalingStatusSucceeded)
+	if err != nil {
+		sdb.logger.Error("retrieve-instance-changes", err,
+			lager.Data{"query": query, "appid": appId, "start": start, "end": end})
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		history := models.AppScalingHistory{AppId: appId, Status: models.ScalingStatusSucceeded}
+		if err = rows.Scan(&history.Timestamp, &history.ScalingType, &history.OldInstances, &history.NewInstances); err != nil {
+			sdb.logger.Error("retrieve-instance-changes-scan", err)
+			return nil, err
 		}
 		histories = append(histories, &history)
 	}
 	return histories, rows.Err()
 }
 
-func statusFilter(includeAll bool) string {
-	statusFilter := " AND status != " + strconv.Itoa(int(models.ScalingStatusIgnored))
-	if includeAll {
-		statusFilter = ""
+// CountEmergencyScalings returns the number of succeeded emergency scalings of an app in the given time range.
+func (sdb *ScalingEngineSQLDB) CountEmergencyScalings(ctx context.Context, appId string, start int64, end int64) (int, error) {
+	query := sdb.sqldb.Rebind("SELECT COUNT(*) FROM scalinghistory WHERE appid = ? AND timestamp >= ? AND timestamp <= ? AND status = ? AND emergency = 1")
+
+	var count int
+	err := sdb.sqldb.GetContext(ctx, &count, query, appId, start, end, models.ScalingStatusSucceeded)
+	if err != nil {
+		sdb.logger.Error("count-emergency-scalings", err,
+			lager.Data{"query": query, "appid": appId, "start": start, "end": end})
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// RetrieveScalingAnalytics aggregates the scaling histories of an app in the given time range.
+// The instance-hours and the time at the instance limits are computed from the segments between
+// succeeded scalings. The instance count at the start of the time range is taken from the last
+// succeeded scaling before it, from the first one within it, or it is the current instance count
+// if the app has never been scaled.
+func (sdb *ScalingEngineSQLDB) RetrieveScalingAnalytics(ctx context.Context, appId string, start int64, end int64, currentInstances int, instanceMin int, instanceMax int) (*models.ScalingAnalytics, error) {
+	instanceChanges := " FROM scalinghistory WHERE appid = ?" +
+		" AND status = " + strconv.Itoa(int(models.ScalingStatusSucceeded)) +
+		" AND oldinstances >= 0 AND newinstances >= 0"
+	segmentsQuery := sdb.sqldb.Rebind("SELECT" +
+		" COALESCE(SUM(instances * (duration / 1000000)), 0)," +
+		" COALESCE(SUM(CASE WHEN instances = ? THEN duration ELSE 0 END), 0)," +
+		" COALESCE(SUM(CASE WHEN instances = ? THEN duration ELSE 0 END), 0)" +
+		" FROM (" +
+		" SELECT COALESCE(" +
+		"(SELECT newinstances" + instanceChanges + " AND timestamp < ? ORDER BY timestamp DESC LIMIT 1), " +
+		"(SELECT oldinstances" + instanceChanges + " AND timestamp >= ? AND timestamp <= ? ORDER BY timestamp ASC LIMIT 1), " +
+		"?) AS instances," +
+		" COALESCE((SELECT MIN(timestamp)" + instanceChanges + " AND timestamp >= ? AND timestamp <= ?), ?) - ? AS duration" +
+		" UNION ALL" +
+		" SELECT newinstances AS instances, COALESCE(LEAD(timestamp) OVER (ORDER BY timestamp), ?) - timestamp AS duration" +
+		instanceChanges + " AND timestamp >= ? AND timestamp <= ?" +
+		") segments")
+
+	analytics := &models.ScalingAnalytics{
+		StartTime:        start,
+		EndTime:          end,
+		InstanceMinCount: instanceMin,
+		InstanceMaxCount: instanceMax,
+	}
+
+	var instanceMilliseconds, nanosecondsAtMin, nanosecondsAtMax float64
+	err := sdb.sqldb.QueryRowContext(ctx, segmentsQuery,
+		instanceMin, instanceMax,
+		appId, start,
+		appId, start, end,
+		currentInstances,
+		appId, start, end, end, start,
+		end, appId, start, end,
+	).Scan(&instanceMilliseconds, &nanosecondsAtMin, &nanosecondsAtMax)
+	if err != nil {
+		sdb.logger.Error("retrieve-scaling-analytics-segments", err,
+			lager.Data{"query": segmentsQuery, "appid": appId, "start": start, "end": end})
+		return nil, err
+	}
+	analytics.InstanceHours = instanceMilliseconds / float64(time.Hour/time.Millisecond)
+	analytics.SecondsAtMin = nanosecondsAtMin / float64(time.Second)
+	analytics.SecondsAtMax = nanosecondsAtMax / float64(time.Second)
+
+	succeeded := "status = " + strconv.Itoa(int(models.ScalingStatusSucceeded)) + " AND oldinstances >= 0 AND newinstances >= 0"
+	action := succeeded + " AND newinstances <> oldinstances"
+	actionsQuery := sdb.sqldb.Rebind("SELECT" +
+		" COUNT(CASE WHEN " + succeeded + " AND newinstances > oldinstances THEN 1 END)," +
+		" COUNT(CASE WHEN " + succeeded + " AND newinstances < oldinstances THEN 1 END)," +
+		" COUNT(CASE WHEN status = " + strconv.Itoa(int(models.ScalingStatusFailed)) + " THEN 1 END)," +
+		" (MAX(CASE WHEN " + action + " THEN timestamp END) - MIN(CASE WHEN " + action + " THEN timestamp END))" +
+		" / NULLIF(COUNT(CASE WHEN " + action + " THEN 1 END) - 1, 0)" +
+		" FROM scalinghistory WHERE appid = ? AND timestamp >= ? AND timestamp <= ?")
+
+	var meanNanosecondsBetweenActions sql.NullFloat64
+	err = sdb.sqldb.QueryRowContext(ctx, actionsQuery, appId, start, end).
+		Scan(&analytics.ScaleOuts, &analytics.ScaleIns, &analytics.Failures, &meanNanosecondsBetweenActions)
+	if err != nil {
+		sdb.logger.Error("retrieve-scaling-analytics-actions", err,
+			lager.Data{"query": actionsQuery, "appid": appId, "start": start, "end": end})
+		return nil, err
+	}
+	if meanNanosecondsBetweenActions.Valid {
+		meanSeconds := meanNanosecondsBetweenActions.Float64 / float64(time.Second)
+		analytics.MeanSecondsBetweenActions = &meanSeconds
+	}
+
+	return analytics, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// historyFilter returns the conditions for the scalinghistory table that implement the given filter together with their arguments.
+func historyFilter(filter db.ScalingHistoryFilter) (string, []interface{}) {
+	query := ""
+	args := []interface{}{}
+
+	if len(filter.Statuses) > 0 {
+		query += " AND status IN (" + placeholders(len(filter.Statuses)) + ")"
+		for _, status := range filter.Statuses {
+			args = append(args, status)
+		}
+	} else if !filter.IncludeAll {
+		query += " AND status != " + strconv.Itoa(int(models.ScalingStatusIgnored))
+	}
+
+	if len(filter.ScalingTypes) > 0 {
+		query += " AND scalingtype IN (" + placeholders(len(filter.ScalingTypes)) + ")"
+		for _, scalingType := range filter.ScalingTypes {
+			args = append(args, scalingType)
+		}
+	}
+
+	if filter.MinInstanceDelta != nil || filter.MaxInstanceDelta != nil {
+		// -1 marks instance counts which are not applicable, e.g. for ignored scalings
+		query += " AND oldinstances >= 0 AND newinstances >= 0"
 	}
-	return statusFilter
+	if filter.MinInstanceDelta != nil {
+		query += " AND newinstances - oldinstances >= ?"
+		args = append(args, *filter.MinInstanceDelta)
+	}
+	if filter.MaxInstanceDelta != nil {
+		query += " AND newinstances - oldinstances <= ?"
+		args = append(args, *filter.MaxInstanceDelta)
+	}
+
+	return query, args
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
 }
 
 func orderTypeToString(orderType db.OrderType) string {
@@ -166,6 +562,27 @@ func (sdb *ScalingEngineSQLDB) PruneScalingHistories(ctx context.Context, before
 	_, err := sdb.sqldb.ExecContext(ctx, query, before)
 	if err != nil {
 		sdb.logger.Error("failed-prune-scaling-histories-from-scalinghistory-table", err, lager.Data{"query": query, "before": before})
+		return err
+	}
+
+	query = sdb.sqldb.Rebind("DELETE FROM scalingdecision WHERE timestamp <= ?")
+	_, err = sdb.sqldb.ExecContext(ctx, query, before)
+	if err != nil {
+		sdb.logger.Error("failed-prune-scaling-decisions-from-scalingdecision-table", err, lager.Data{"query": query, "before": before})
+		return err
+	}
+
+	query = sdb.sqldb.Rebind("DELETE FROM scalingevent WHERE timestamp <= ?")
+	_, err = sdb.sqldb.ExecContext(ctx, query, before)
+	if err != nil {
+		sdb.logger.Error("failed-prune-scaling-events-from-scalingevent-table", err, lager.Data{"query": query, "before": before})
+		return err
+	}
+
+	query = sdb.sqldb.Rebind("DELETE FROM groupscalinghistory WHERE timestamp <= ?")
+	_, err = sdb.sqldb.ExecContext(ctx, query, before)
+	if err != nil {
+		sdb.logger.Error("failed-prune-group-scaling-histories-from-groupscalinghistory-table", err, lager.Data{"query": query, "before": before})
 	}
 	return err
 }
@@ -179,40 +596,42 @@ func (sdb *ScalingEngineSQLDB) PruneCooldowns(ctx context.Context, before int64)
 	return err
 }
 
-func (sdb *ScalingEngineSQLDB) CanScaleApp(appId string) (bool, int64, error) {
-	query := sdb.sqldb.Rebind("SELECT expireat FROM scalingcooldown WHERE appid = ?")
-	rows, err := sdb.sqldb.Query(query, appId)
+// CanScaleApp checks the cool-down of the app for scaling actions in `direction`. Cool-downs
+// recorded without a direction block both directions.
+func (sdb *ScalingEngineSQLDB) CanScaleApp(appId string, direction models.ScalingDirection) (bool, int64, error) {
+	query := sdb.sqldb.Rebind("SELECT expireat FROM scalingcooldown WHERE appid = ? AND direction IN (?, '')")
+	rows, err := sdb.sqldb.Query(query, appId, direction)
 	if err != nil {
-		sdb.logger.Error("can-scale-app-query-record", err, lager.Data{"query": query, "appid": appId})
+		sdb.logger.Error("can-scale-app-query-record", err, lager.Data{"query": query, "appid": appId, "direction": direction})
 		return false, 0, err
 	}
 	defer func() { _ = rows.Close() }()
 
 	var expireAt int64 = 0
-	if rows.Next() {
-		if err = rows.Scan(&expireAt); err != nil {
-			sdb.logger.Error("can-scale-app-scan", err, lager.Data{"query": query, "appid": appId})
+	for rows.Next() {
+		var recordExpireAt int64
+		if err = rows.Scan(&recordExpireAt); err != nil {
+			sdb.logger.Error("can-scale-app-scan", err, lager.Data{"query": query, "appid": appId, "direction": direction})
 			return false, expireAt, err
 		}
-		if expireAt < time.Now().UnixNano() {
-			return true, expireAt, nil
-		} else {
-			return false, expireAt, nil
-		}
+		expireAt = max(expireAt, recordExpireAt)
+	}
+	if err = rows.Err(); err != nil {
+		return false, expireAt, err
 	}
-	return true, expireAt, rows.Err()
+	return expireAt < time.Now().UnixNano(), expireAt, nil
 }
 
-func (sdb *ScalingEngineSQLDB) UpdateScalingCooldownExpireTime(appId string, expireAt int64) error {
-	_, err := sdb.sqldb.Exec(sdb.sqldb.Rebind("DELETE FROM scalingcooldown WHERE appid = ?"), appId)
+func (sdb *ScalingEngineSQLDB) UpdateScalingCooldownExpireTime(appId string, direction models.ScalingDirection, expireAt int64) error {
+	_, err := sdb.sqldb.Exec(sdb.sqldb.Rebind("DELETE FROM scalingcooldown WHERE appid = ? AND direction = ?"), appId, direction)
 	if err != nil {
-		sdb.logger.Error("update-scaling-cooldown-time-delete", err, lager.Data{"appid": appId})
+		sdb.logger.Error("update-scaling-cooldown-time-delete", err, lager.Data{"appid": appId, "direction": direction})
 		return err
 	}
 
-	_, err = sdb.sqldb.Exec(sdb.sqldb.Rebind("INSERT INTO scalingcooldown(appid, expireat) values(?, ?)"), appId, expireAt)
+	_, err = sdb.sqldb.Exec(sdb.sqldb.Rebind("INSERT INTO scalingcooldown(appid, direction, expireat) values(?, ?, ?)"), appId, direction, expireAt)
 	if err != nil {
-		sdb.logger.Error("update-scaling-cooldown-time-insert", err, lager.Data{"appid": appId, "expireAt": expireAt})
+		sdb.logger.Error("update-scaling-cooldown-time-insert", err, lager.Data{"appid": appId, "direction": direction, "expireAt": expireAt})
 		return err
 	}
 	return nil
@@ -42,7 +42,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		activeSchedule    *models.ActiveSchedule
 		schedules         map[string]string
 		before            int64
-		includeAll        bool
+		filter            db.ScalingHistoryFilter
 	)
 
 	dbUrl := GetDbUrl()
@@ -191,7 +191,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 			start = 0
 			end = -1
 			orderType = db.DESC
-			includeAll = true
+			filter = db.ScalingHistoryFilter{IncludeAll: true}
 
 			history = &models.AppScalingHistory{
 				AppId:        appId,
@@ -231,18 +231,78 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		})
 
 		JustBeforeEach(func() {
-			histories, err = sdb.RetrieveScalingHistories(context.TODO(), appId, start, end, orderType, includeAll, 1, 50)
+			histories, err = sdb.RetrieveScalingHistories(context.TODO(), appId, start, end, orderType, filter, 1, 50)
 		})
 
 		Context("When the app has no history", func() {
 			It("returns empty metrics", func() {
-				histories, err = sdb.RetrieveScalingHistories(context.TODO(), "app-id-no-history", start, end, orderType, includeAll, 1, 50)
+				histories, err = sdb.RetrieveScalingHistories(context.TODO(), "app-id-no-history", start, end, orderType, filter, 1, 50)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(histories).To(BeEmpty())
 			})
 
 		})
 
+		Context("when the history has structured fields", func() {
+			BeforeEach(func() {
+				start = 777777
+				end = 777777
+				err = sdb.SaveScalingHistory(&models.AppScalingHistory{
+					AppId:             appId,
+					Timestamp:         777777,
+					ScalingType:       models.ScalingTypeDynamic,
+					Status:            models.ScalingStatusSucceeded,
+					OldInstances:      2,
+					NewInstances:      3,
+					Reason:            "+1 instance(s) because cpu > 80% for 120 seconds",
+					RuleIndex:         ptr(1),
+					MetricType:        "cpu",
+					ObservedValue:     ptr(int64(90)),
+					Threshold:         ptr(int64(80)),
+					Operator:          ">",
+					Adjustment:        "+1",
+					ScheduleId:        "a-schedule-id",
+					CooldownExpiredAt: 888888,
+					CorrelationId:     "a-correlation-id",
+					DecisionId:        "a-decision-id",
+				})
+				FailOnError("Failed to add scaling history", err)
+			})
+
+			It("returns them", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(1))
+				Expect(*histories[0].RuleIndex).To(Equal(1))
+				Expect(histories[0].MetricType).To(Equal("cpu"))
+				Expect(*histories[0].ObservedValue).To(Equal(int64(90)))
+				Expect(*histories[0].Threshold).To(Equal(int64(80)))
+				Expect(histories[0].Operator).To(Equal(">"))
+				Expect(histories[0].Adjustment).To(Equal("+1"))
+				Expect(histories[0].ScheduleId).To(Equal("a-schedule-id"))
+				Expect(histories[0].CooldownExpiredAt).To(Equal(int64(888888)))
+				Expect(histories[0].CorrelationId).To(Equal("a-correlation-id"))
+				Expect(histories[0].DecisionId).To(Equal("a-decision-id"))
+			})
+		})
+
+		Context("when the history has no structured fields", func() {
+			BeforeEach(func() {
+				start = 666666
+				end = 666666
+			})
+
+			It("leaves them empty", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(1))
+				Expect(histories[0].RuleIndex).To(BeNil())
+				Expect(histories[0].ObservedValue).To(BeNil())
+				Expect(histories[0].Threshold).To(BeNil())
+				Expect(histories[0].MetricType).To(BeEmpty())
+				Expect(histories[0].CorrelationId).To(BeEmpty())
+				Expect(histories[0].DecisionId).To(BeEmpty())
+			})
+		})
+
 		Context("when end time is now (end = -1)", func() {
 			BeforeEach(func() {
 				start = 333333
@@ -437,7 +497,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 
 		Context("when only retrieving succeeded and failed history", func() {
 			BeforeEach(func() {
-				includeAll = false
+				filter = db.ScalingHistoryFilter{}
 			})
 
 			It("skips ignored scaling history", func() {
@@ -477,6 +537,502 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					}}))
 			})
 		})
+
+		Context("when filtering by status", func() {
+			BeforeEach(func() {
+				filter = db.ScalingHistoryFilter{Statuses: []models.ScalingStatus{models.ScalingStatusFailed, models.ScalingStatusIgnored}}
+			})
+
+			It("only returns the histories with the given statuses", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(timestampsOf(histories)).To(Equal([]int64{555555, 333333, 222222}))
+			})
+		})
+
+		Context("when filtering by scaling type", func() {
+			BeforeEach(func() {
+				filter = db.ScalingHistoryFilter{ScalingTypes: []models.ScalingType{models.ScalingTypeSchedule}}
+			})
+
+			It("only returns the histories with the given scaling types that are not ignored", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(timestampsOf(histories)).To(Equal([]int64{555555}))
+			})
+		})
+
+		Context("when filtering by instance delta", func() {
+			BeforeEach(func() {
+				history.Timestamp = 444444
+				history.Status = models.ScalingStatusSucceeded
+				history.OldInstances = 4
+				history.NewInstances = 3
+				history.Error = ""
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+
+				history.Timestamp = 777777
+				history.Status = models.ScalingStatusIgnored
+				history.OldInstances = -1
+				history.NewInstances = -1
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+			})
+
+			Context("with a minimum", func() {
+				BeforeEach(func() {
+					filter.MinInstanceDelta = ptr(1)
+				})
+
+				It("only returns the scale-outs", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(timestampsOf(histories)).To(Equal([]int64{666666, 555555, 333333, 222222}))
+				})
+			})
+
+			Context("with a maximum", func() {
+				BeforeEach(func() {
+					filter.MaxInstanceDelta = ptr(-1)
+				})
+
+				It("only returns the scale-ins with applicable instance counts", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(timestampsOf(histories)).To(Equal([]int64{444444}))
+				})
+			})
+
+			Context("with a range", func() {
+				BeforeEach(func() {
+					filter.MinInstanceDelta = ptr(-1)
+					filter.MaxInstanceDelta = ptr(1)
+				})
+
+				It("only returns the histories within the range", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(timestampsOf(histories)).To(Equal([]int64{444444}))
+				})
+			})
+		})
+	})
+
+	Describe("CountScalingHistories", func() {
+		var count int
+
+		BeforeEach(func() {
+			filter = db.ScalingHistoryFilter{}
+			history = &models.AppScalingHistory{AppId: appId, Reason: "a reason"}
+			for _, h := range []struct {
+				timestamp   int64
+				scalingType models.ScalingType
+				status      models.ScalingStatus
+			}{
+				{111111, models.ScalingTypeDynamic, models.ScalingStatusSucceeded},
+				{222222, models.ScalingTypeDynamic, models.ScalingStatusFailed},
+				{333333, models.ScalingTypeSchedule, models.ScalingStatusFailed},
+				{444444, models.ScalingTypeDynamic, models.ScalingStatusIgnored},
+			} {
+				history.Timestamp, history.ScalingType, history.Status = h.timestamp, h.scalingType, h.status
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+			}
+		})
+
+		JustBeforeEach(func() {
+			count, err = sdb.CountScalingHistories(context.TODO(), appId, 0, -1, filter)
+		})
+
+		It("counts the histories that are not ignored", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(3))
+		})
+
+		Context("when filtering", func() {
+			BeforeEach(func() {
+				filter = db.ScalingHistoryFilter{
+					Statuses:     []models.ScalingStatus{models.ScalingStatusFailed},
+					ScalingTypes: []models.ScalingType{models.ScalingTypeDynamic},
+				}
+			})
+
+			It("only counts the matching histories", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("RetrieveScalingAnalytics", func() {
+		var analytics *models.ScalingAnalytics
+
+		secs := func(seconds int64) int64 { return seconds * int64(time.Second) }
+
+		JustBeforeEach(func() {
+			analytics, err = sdb.RetrieveScalingAnalytics(context.TODO(), appId, secs(100), secs(500), 3, 2, 4)
+		})
+
+		Context("when the app has been scaled", func() {
+			BeforeEach(func() {
+				history = &models.AppScalingHistory{AppId: appId, ScalingType: models.ScalingTypeDynamic, Reason: "a reason"}
+				for _, h := range []struct {
+					timestamp    int64
+					status       models.ScalingStatus
+					oldInstances int
+					newInstances int
+				}{
+					{secs(0), models.ScalingStatusSucceeded, 1, 2},
+					{secs(200), models.ScalingStatusSucceeded, 2, 4},
+					{secs(250), models.ScalingStatusFailed, 4, 5},
+					{secs(300), models.ScalingStatusSucceeded, 4, 3},
+					{secs(350), models.ScalingStatusIgnored, -1, -1},
+					{secs(600), models.ScalingStatusSucceeded, 3, 2},
+				} {
+					history.Timestamp, history.Status = h.timestamp, h.status
+					history.OldInstances, history.NewInstances = h.oldInstances, h.newInstances
+					err = sdb.SaveScalingHistory(history)
+					FailOnError("Failed to add scaling history", err)
+				}
+			})
+
+			It("aggregates the scaling histories in the time range", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(analytics.StartTime).To(Equal(secs(100)))
+				Expect(analytics.EndTime).To(Equal(secs(500)))
+				// 100s with 2, 100s with 4 and 200s with 3 instances
+				Expect(analytics.InstanceHours).To(BeNumerically("~", 1200.0/3600, 1e-9))
+				Expect(analytics.ScaleOuts).To(Equal(1))
+				Expect(analytics.ScaleIns).To(Equal(1))
+				Expect(analytics.Failures).To(Equal(1))
+				Expect(analytics.InstanceMinCount).To(Equal(2))
+				Expect(analytics.InstanceMaxCount).To(Equal(4))
+				Expect(analytics.SecondsAtMin).To(BeNumerically("~", 100, 1e-9))
+				Expect(analytics.SecondsAtMax).To(BeNumerically("~", 100, 1e-9))
+				Expect(analytics.MeanSecondsBetweenActions).NotTo(BeNil())
+				Expect(*analytics.MeanSecondsBetweenActions).To(BeNumerically("~", 100, 1e-9))
+			})
+		})
+
+		Context("when the app has never been scaled", func() {
+			It("assumes the current instance count for the whole time range", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(analytics.InstanceHours).To(BeNumerically("~", 1200.0/3600, 1e-9))
+				Expect(analytics.ScaleOuts).To(Equal(0))
+				Expect(analytics.ScaleIns).To(Equal(0))
+				Expect(analytics.Failures).To(Equal(0))
+				Expect(analytics.SecondsAtMin).To(BeZero())
+				Expect(analytics.SecondsAtMax).To(BeZero())
+				Expect(analytics.MeanSecondsBetweenActions).To(BeNil())
+			})
+		})
+	})
+
+	Describe("RetrieveInstanceChanges", func() {
+		BeforeEach(func() {
+			history = &models.AppScalingHistory{AppId: appId, ScalingType: models.ScalingTypeDynamic, Reason: "a reason"}
+			for _, h := range []struct {
+				timestamp    int64
+				status       models.ScalingStatus
+				oldInstances int
+				newInstances int
+			}{
+				{333333, models.ScalingStatusSucceeded, 3, 1},
+				{111111, models.ScalingStatusSucceeded, 1, 2},
+				{222222, models.ScalingStatusFailed, 2, 5},
+				{444444, models.ScalingStatusIgnored, 1, 1},
+				{555555, models.ScalingStatusSucceeded, 1, 4},
+			} {
+				history.Timestamp, history.Status = h.timestamp, h.status
+				history.OldInstances, history.NewInstances = h.oldInstances, h.newInstances
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+			}
+		})
+
+		It("returns the succeeded scaling histories in the time range in ascending order", func() {
+			histories, err = sdb.RetrieveInstanceChanges(context.TODO(), appId, 111112, 555555)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(histories).To(Equal([]*models.AppScalingHistory{
+				{AppId: appId, Timestamp: 333333, ScalingType: models.ScalingTypeDynamic, Status: models.ScalingStatusSucceeded, OldInstances: 3, NewInstances: 1},
+				{AppId: appId, Timestamp: 555555, ScalingType: models.ScalingTypeDynamic, Status: models.ScalingStatusSucceeded, OldInstances: 1, NewInstances: 4},
+			}))
+		})
+	})
+
+	Describe("CountEmergencyScalings", func() {
+		BeforeEach(func() {
+			history = &models.AppScalingHistory{AppId: appId, ScalingType: models.ScalingTypeDynamic, Reason: "a reason"}
+			for _, h := range []struct {
+				timestamp int64
+				status    models.ScalingStatus
+				emergency bool
+			}{
+				{111111, models.ScalingStatusSucceeded, true},
+				{222222, models.ScalingStatusSucceeded, true},
+				{333333, models.ScalingStatusIgnored, true},
+				{444444, models.ScalingStatusSucceeded, false},
+				{555555, models.ScalingStatusSucceeded, true},
+			} {
+				history.Timestamp, history.Status, history.Emergency = h.timestamp, h.status, h.emergency
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+			}
+		})
+
+		It("counts the succeeded emergency scalings in the time range", func() {
+			count, err := sdb.CountEmergencyScalings(context.TODO(), appId, 111112, 555555)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+
+		It("flags emergency scalings in the scaling histories", func() {
+			histories, err = sdb.RetrieveScalingHistories(context.TODO(), appId, 0, -1, db.ASC, db.ScalingHistoryFilter{IncludeAll: true}, 1, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(histories).To(HaveLen(5))
+			Expect(histories[0].Emergency).To(BeTrue())
+			Expect(histories[3].Emergency).To(BeFalse())
+		})
+	})
+
+	Describe("RetrieveGroupScalingHistories", func() {
+		var (
+			groupId   string
+			histories []*models.GroupScalingHistory
+		)
+
+		BeforeEach(func() {
+			groupId = addProcessIdTo("a-group-id")
+			removeGroupScalingHistoriesForGroup(groupId)
+			DeferCleanup(removeGroupScalingHistoriesForGroup, groupId)
+
+			for _, timestamp := range []int64{111111, 222222, 333333} {
+				err = sdb.SaveGroupScalingHistory(context.TODO(), &models.GroupScalingHistory{
+					GroupId:    groupId,
+					Timestamp:  timestamp,
+					MetricType: "throughput",
+					Reason:     "+1 instance(s) because throughput > 100rps for 120 seconds",
+					Status:     models.GroupScalingPartiallyFailed,
+					Apps: []models.GroupMemberScaling{
+						{AppId: appId, OldInstances: 2, NewInstances: 3, Status: models.GroupMemberRollbackFailed, Error: "failed to roll back: cf error"},
+						{AppId: appId2, OldInstances: 4, NewInstances: 6, Status: models.GroupMemberFailed, Error: "cf error"},
+					},
+					Error: "failed to scale member",
+				})
+				FailOnError("Failed to add group scaling history", err)
+			}
+		})
+
+		JustBeforeEach(func() {
+			histories, err = sdb.RetrieveGroupScalingHistories(context.TODO(), groupId, 111111, 222222, 10)
+		})
+
+		It("returns the histories within the time range, the latest first", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(histories).To(HaveLen(2))
+			Expect(histories[0].Timestamp).To(Equal(int64(222222)))
+			Expect(histories[1].Timestamp).To(Equal(int64(111111)))
+			Expect(histories[0].GroupId).To(Equal(groupId))
+			Expect(histories[0].Status).To(Equal(models.GroupScalingPartiallyFailed))
+			Expect(histories[0].Error).To(Equal("failed to scale member"))
+			Expect(histories[0].Apps).To(Equal([]models.GroupMemberScaling{
+				{AppId: appId, OldInstances: 2, NewInstances: 3, Status: models.GroupMemberRollbackFailed, Error: "failed to roll back: cf error"},
+				{AppId: appId2, OldInstances: 4, NewInstances: 6, Status: models.GroupMemberFailed, Error: "cf error"},
+			}))
+		})
+
+		Context("when the limit is reached", func() {
+			JustBeforeEach(func() {
+				histories, err = sdb.RetrieveGroupScalingHistories(context.TODO(), groupId, 0, -1, 1)
+			})
+
+			It("returns only the latest histories", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(1))
+				Expect(histories[0].Timestamp).To(Equal(int64(333333)))
+			})
+		})
+
+		Context("when db fails", func() {
+			BeforeEach(func() {
+				_ = sdb.Close()
+			})
+
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("RetrieveScalingDecision", func() {
+		var (
+			decisionId string
+			decision   *models.DecisionTrace
+		)
+
+		BeforeEach(func() {
+			decisionId = addProcessIdTo("a-decision-id")
+			err = sdb.SaveScalingDecision(context.TODO(), &models.DecisionTrace{
+				DecisionId: decisionId,
+				AppId:      appId,
+				Timestamp:  111111,
+				Evaluation: []models.RuleEvaluation{{
+					RuleIndex:  ptr(0),
+					MetricType: "cpu",
+					Threshold:  80,
+					Operator:   ">",
+					Adjustment: "+1",
+					Samples:    []models.MetricSample{{Timestamp: 111110, Value: "90", Unit: "%"}},
+					Breached:   true,
+				}},
+				CFResponse: &models.CFScalingResponse{Instances: 3},
+			})
+			FailOnError("Failed to add scaling decision", err)
+			err = sdb.SaveScalingHistory(&models.AppScalingHistory{
+				AppId:        appId,
+				Timestamp:    111111,
+				ScalingType:  models.ScalingTypeDynamic,
+				Status:       models.ScalingStatusSucceeded,
+				OldInstances: 2,
+				NewInstances: 3,
+				DecisionId:   decisionId,
+			})
+			FailOnError("Failed to add scaling history", err)
+		})
+
+		JustBeforeEach(func() {
+			decision, err = sdb.RetrieveScalingDecision(context.TODO(), appId, decisionId)
+		})
+
+		Context("when the decision exists", func() {
+			It("returns the decision with its scaling history", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decision.AppId).To(Equal(appId))
+				Expect(decision.Timestamp).To(Equal(int64(111111)))
+				Expect(decision.Evaluation).To(HaveLen(1))
+				Expect(decision.Evaluation[0].Samples).To(Equal([]models.MetricSample{{Timestamp: 111110, Value: "90", Unit: "%"}}))
+				Expect(decision.Evaluation[0].Breached).To(BeTrue())
+				Expect(decision.CFResponse).To(Equal(&models.CFScalingResponse{Instances: 3}))
+				Expect(decision.Scaling.NewInstances).To(Equal(3))
+				Expect(decision.Scaling.DecisionId).To(Equal(decisionId))
+			})
+		})
+
+		Context("when the decision belongs to another app", func() {
+			JustBeforeEach(func() {
+				decision, err = sdb.RetrieveScalingDecision(context.TODO(), appId2, decisionId)
+			})
+
+			It("returns nil", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decision).To(BeNil())
+			})
+		})
+
+		Context("when db fails", func() {
+			BeforeEach(func() {
+				_ = sdb.Close()
+			})
+
+			It("should error", func() {
+				Expect(err).To(MatchError(MatchRegexp("sql: .*")))
+			})
+		})
+	})
+
+	Describe("ScalingEvents", func() {
+		var (
+			events      []*models.ScalingEvent
+			lastEventId int64
+		)
+
+		BeforeEach(func() {
+			for i, eventType := range []models.ScalingEventType{models.ScalingEventScheduleStart, models.ScalingEventScaling, models.ScalingEventScheduleEnd} {
+				err = sdb.SaveScalingEvent(context.TODO(), &models.ScalingEvent{
+					AppId:     appId,
+					Timestamp: int64(111111 + i),
+					Type:      eventType,
+					History:   &models.AppScalingHistory{AppId: appId, Timestamp: int64(111111 + i), OldInstances: i, NewInstances: i + 1},
+				})
+				FailOnError("Failed to add scaling event", err)
+			}
+			err = sdb.SaveScalingEvent(context.TODO(), &models.ScalingEvent{AppId: appId2, Timestamp: 222222, Type: models.ScalingEventScaling})
+			FailOnError("Failed to add scaling event", err)
+		})
+
+		Context("when retrieving the events after the first one", func() {
+			JustBeforeEach(func() {
+				events, err = sdb.RetrieveScalingEvents(context.TODO(), appId, 0, 10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(3))
+				events, err = sdb.RetrieveScalingEvents(context.TODO(), appId, events[0].Id, 1)
+			})
+
+			It("returns the next events of the app ordered by their id", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].AppId).To(Equal(appId))
+				Expect(events[0].Timestamp).To(Equal(int64(111112)))
+				Expect(events[0].Type).To(Equal(models.ScalingEventScaling))
+				Expect(events[0].History).To(Equal(&models.AppScalingHistory{AppId: appId, Timestamp: 111112, OldInstances: 1, NewInstances: 2}))
+			})
+		})
+
+		Context("when retrieving an evaluation event", func() {
+			var ruleIndex = 0
+
+			BeforeEach(func() {
+				err = sdb.SaveScalingEvent(context.TODO(), models.NewEvaluationEvent(appId, 333333, []models.RuleEvaluation{
+					{RuleIndex: &ruleIndex, MetricType: "memoryused", Threshold: 100, Operator: ">", Adjustment: "+1", Samples: []models.MetricSample{}, Breached: false},
+				}))
+				FailOnError("Failed to add scaling event", err)
+			})
+
+			JustBeforeEach(func() {
+				events, err = sdb.RetrieveScalingEvents(context.TODO(), appId, 0, 10)
+			})
+
+			It("returns the evaluation of the event", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(4))
+				Expect(events[3].Type).To(Equal(models.ScalingEventEvaluation))
+				Expect(events[3].History).To(BeNil())
+				Expect(events[3].Evaluation).To(Equal([]models.RuleEvaluation{
+					{RuleIndex: &ruleIndex, MetricType: "memoryused", Threshold: 100, Operator: ">", Adjustment: "+1", Samples: []models.MetricSample{}, Breached: false},
+				}))
+			})
+		})
+
+		Context("when getting the id of the last event", func() {
+			JustBeforeEach(func() {
+				lastEventId, err = sdb.GetLastScalingEventId(context.TODO(), appId)
+			})
+
+			It("returns the id of the latest event of the app", func() {
+				Expect(err).NotTo(HaveOccurred())
+				events, err = sdb.RetrieveScalingEvents(context.TODO(), appId, 0, 10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lastEventId).To(Equal(events[2].Id))
+			})
+
+			Context("when the app has no events", func() {
+				JustBeforeEach(func() {
+					lastEventId, err = sdb.GetLastScalingEventId(context.TODO(), appId3)
+				})
+
+				It("returns 0", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lastEventId).To(BeZero())
+				})
+			})
+		})
+
+		Context("when db fails", func() {
+			JustBeforeEach(func() {
+				_ = sdb.Close()
+				events, err = sdb.RetrieveScalingEvents(context.TODO(), appId, 0, 10)
+			})
+
+			It("should error", func() {
+				Expect(err).To(MatchError(MatchRegexp("sql: .*")))
+			})
+		})
 	})
 
 	Describe("PruneScalingHistories", Serial, func() {
@@ -497,6 +1053,11 @@ var _ = Describe("ScalingEngineSqldb", func() {
 			history.Timestamp = 333333
 			err = sdb.SaveScalingHistory(history)
 			Expect(err).NotTo(HaveOccurred())
+
+			err = sdb.SaveScalingEvent(context.TODO(), &models.ScalingEvent{AppId: appId, Timestamp: 222222, Type: models.ScalingEventScaling})
+			Expect(err).NotTo(HaveOccurred())
+			err = sdb.SaveScalingEvent(context.TODO(), &models.ScalingEvent{AppId: appId, Timestamp: 555555, Type: models.ScalingEventScaling})
+			Expect(err).NotTo(HaveOccurred())
 		})
 
 		JustBeforeEach(func() {
@@ -530,9 +1091,13 @@ var _ = Describe("ScalingEngineSqldb", func() {
 				before = 333333
 			})
 
-			It("removes histories before the time specified", func() {
+			It("removes histories and events before the time specified", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(getScalingHistoryForApp(appId)).To(Equal(2))
+				events, err := sdb.RetrieveScalingEvents(context.TODO(), appId, 0, 10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].Timestamp).To(Equal(int64(555555)))
 			})
 		})
 
@@ -554,7 +1119,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 			appIds = make([]string, 10)
 			for i := 0; i < 10; i++ {
 				appIds[i] = addProcessIdTo("an-app-id-" + strconv.Itoa(i))
-				err := sdb.UpdateScalingCooldownExpireTime(appIds[i], 111111*int64(i+1))
+				err := sdb.UpdateScalingCooldownExpireTime(appIds[i], models.ScalingDirectionOut, 111111*int64(i+1))
 				Expect(err).NotTo(HaveOccurred())
 			}
 
@@ -610,33 +1175,46 @@ var _ = Describe("ScalingEngineSqldb", func() {
 	Describe("UpdateScalingCooldownExpireTime", func() {
 
 		JustBeforeEach(func() {
-			err = sdb.UpdateScalingCooldownExpireTime(appId, 222222)
+			err = sdb.UpdateScalingCooldownExpireTime(appId, models.ScalingDirectionOut, 222222)
 		})
 
 		Context("when there is no previous app cooldown record", func() {
 			It("creates the record", func() {
 				Expect(err).NotTo(HaveOccurred())
-				Expect(hasScalingCooldownRecord(appId, 222222)).To(BeTrue())
+				Expect(hasScalingCooldownRecord(appId, models.ScalingDirectionOut, 222222)).To(BeTrue())
 			})
 		})
 
 		Context("when there is previous app cooldown record", func() {
 			BeforeEach(func() {
-				err = sdb.UpdateScalingCooldownExpireTime(appId, 111111)
+				err = sdb.UpdateScalingCooldownExpireTime(appId, models.ScalingDirectionOut, 111111)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
 			It("removes the previous record and inserts a new record", func() {
 				Expect(err).NotTo(HaveOccurred())
-				Expect(hasScalingCooldownRecord(appId, 111111)).To(BeFalse())
-				Expect(hasScalingCooldownRecord(appId, 222222)).To(BeTrue())
+				Expect(hasScalingCooldownRecord(appId, models.ScalingDirectionOut, 111111)).To(BeFalse())
+				Expect(hasScalingCooldownRecord(appId, models.ScalingDirectionOut, 222222)).To(BeTrue())
+			})
+		})
+
+		Context("when there is an app cooldown record for the other direction", func() {
+			BeforeEach(func() {
+				err = sdb.UpdateScalingCooldownExpireTime(appId, models.ScalingDirectionIn, 111111)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("keeps the record of the other direction", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasScalingCooldownRecord(appId, models.ScalingDirectionIn, 111111)).To(BeTrue())
+				Expect(hasScalingCooldownRecord(appId, models.ScalingDirectionOut, 222222)).To(BeTrue())
 			})
 		})
 	})
 
 	Describe("CanScaleApp", func() {
 		JustBeforeEach(func() {
-			canScale, cooldownExpiredAt, err = sdb.CanScaleApp(appId)
+			canScale, cooldownExpiredAt, err = sdb.CanScaleApp(appId, models.ScalingDirectionOut)
 		})
 
 		Context("when there is no cooldown record before", func() {
@@ -650,7 +1228,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		Context("when the app is still in cooldown period", func() {
 			fakeCoolDownExpiredTime := time.Now().Add(100 * time.Second).UnixNano()
 			BeforeEach(func() {
-				err = sdb.UpdateScalingCooldownExpireTime(appId, fakeCoolDownExpiredTime)
+				err = sdb.UpdateScalingCooldownExpireTime(appId, models.ScalingDirectionOut, fakeCoolDownExpiredTime)
 				Expect(err).NotTo(HaveOccurred())
 			})
 			It("returns false", func() {
@@ -663,7 +1241,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		Context("when the app passes cooldown period", func() {
 			fakeCoolDownExpiredTime := time.Now().Add(0 - 100*time.Second).UnixNano()
 			BeforeEach(func() {
-				err = sdb.UpdateScalingCooldownExpireTime(appId, fakeCoolDownExpiredTime)
+				err = sdb.UpdateScalingCooldownExpireTime(appId, models.ScalingDirectionOut, fakeCoolDownExpiredTime)
 				Expect(err).NotTo(HaveOccurred())
 			})
 			It("returns true", func() {
@@ -672,6 +1250,30 @@ var _ = Describe("ScalingEngineSqldb", func() {
 				Expect(cooldownExpiredAt).To(Equal(fakeCoolDownExpiredTime))
 			})
 		})
+
+		Context("when the app is only in cooldown period for the other direction", func() {
+			BeforeEach(func() {
+				err = sdb.UpdateScalingCooldownExpireTime(appId, models.ScalingDirectionIn, time.Now().Add(100*time.Second).UnixNano())
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns true", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(canScale).To(BeTrue())
+				Expect(cooldownExpiredAt).To(Equal(int64(0)))
+			})
+		})
+
+		Context("when the app is in a cooldown period recorded without direction", func() {
+			fakeCoolDownExpiredTime := time.Now().Add(100 * time.Second).UnixNano()
+			BeforeEach(func() {
+				insertScalingCooldownWithoutDirection(appId, fakeCoolDownExpiredTime)
+			})
+			It("returns false", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(canScale).To(BeFalse())
+				Expect(cooldownExpiredAt).To(Equal(fakeCoolDownExpiredTime))
+			})
+		})
 	})
 
 	Describe("GetActiveSchedule", func() {
@@ -833,6 +1435,8 @@ var _ = Describe("ScalingEngineSqldb", func() {
 
 func cleanupForApp(appId string) {
 	removeScalingHistoryForApp(appId)
+	removeScalingDecisionForApp(appId)
+	removeScalingEventsForApp(appId)
 	removeCooldownForApp(appId)
 	removeActiveScheduleForApp(appId)
 }
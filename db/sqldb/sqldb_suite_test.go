@@ -173,6 +173,12 @@ func insertPolicyWithGuid(appId string, scalingPolicy *models.PolicyDefinition,
 	}
 }
 
+func removePolicyRevisionsForApp(appId string) {
+	query := dbHelper.Rebind("DELETE from policy_revision where app_id = ?")
+	_, err := dbHelper.Exec(query, appId)
+	FailOnError("can not clean table policy_revision", err)
+}
+
 func getAppPolicy(appId string) string {
 	query := dbHelper.Rebind("SELECT policy_json FROM policy_json WHERE app_id=? ")
 	rows, err := dbHelper.Query(query, appId)
@@ -216,6 +222,24 @@ func removeScalingHistoryForApp(appId string) {
 	FailOnError("can not clean table scalinghistory", err)
 }
 
+func removeScalingDecisionForApp(appId string) {
+	query := dbHelper.Rebind("DELETE from scalingdecision where appId = ?")
+	_, err := dbHelper.Exec(query, appId)
+	FailOnError("can not clean table scalingdecision", err)
+}
+
+func removeScalingEventsForApp(appId string) {
+	query := dbHelper.Rebind("DELETE from scalingevent where appId = ?")
+	_, err := dbHelper.Exec(query, appId)
+	FailOnError("can not clean table scalingevent", err)
+}
+
+func removeGroupScalingHistoriesForGroup(groupId string) {
+	query := dbHelper.Rebind("DELETE from groupscalinghistory where groupid = ?")
+	_, err := dbHelper.Exec(query, groupId)
+	FailOnError("can not clean table groupscalinghistory", err)
+}
+
 func getNumberOfCooldownEntries() int {
 	var num int
 	query := dbHelper.Rebind("SELECT COUNT(*) FROM scalingcooldown")
@@ -255,9 +279,9 @@ func getScalingHistoryForApp(appId string) int {
 	return num
 }
 
-func hasScalingCooldownRecord(appId string, expireAt int64) bool {
-	query := dbHelper.Rebind("SELECT * FROM scalingcooldown WHERE appid = ? AND expireat = ?")
-	rows, e := dbHelper.Query(query, appId, expireAt)
+func hasScalingCooldownRecord(appId string, direction models.ScalingDirection, expireAt int64) bool {
+	query := dbHelper.Rebind("SELECT * FROM scalingcooldown WHERE appid = ? AND direction = ? AND expireat = ?")
+	rows, e := dbHelper.Query(query, appId, direction, expireAt)
 	FailOnError("can not query table scalingcooldown", e)
 	defer func() { _ = rows.Close() }()
 	item := rows.Next()
@@ -265,6 +289,12 @@ func hasScalingCooldownRecord(appId string, expireAt int64) bool {
 	return item
 }
 
+func insertScalingCooldownWithoutDirection(appId string, expireAt int64) {
+	query := dbHelper.Rebind("INSERT INTO scalingcooldown(appid, expireat) VALUES (?, ?)")
+	_, err := dbHelper.Exec(query, appId, expireAt)
+	FailOnError("can not insert into table scalingcooldown", err)
+}
+
 func insertActiveSchedule(appId, scheduleId string, instanceMin, instanceMax, instanceMinInitial int) error {
 	query := dbHelper.Rebind("INSERT INTO activeschedule(appid, scheduleid, instancemincount, instancemaxcount, initialmininstancecount) " +
 		" VALUES (?, ?, ?, ?, ?)")
@@ -419,3 +449,15 @@ func expectServiceInstancesToEqual(actual *models.ServiceInstance, expected *mod
 	ExpectWithOffset(1, actual.DefaultPolicy).To(MatchJSON(expected.DefaultPolicy))
 	ExpectWithOffset(1, actual.DefaultPolicyGuid).To(Equal(expected.DefaultPolicyGuid))
 }
+
+func timestampsOf(histories []*models.AppScalingHistory) []int64 {
+	timestamps := make([]int64, len(histories))
+	for i, history := range histories {
+		timestamps[i] = history.Timestamp
+	}
+	return timestamps
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
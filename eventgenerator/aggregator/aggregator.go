@@ -52,9 +52,10 @@ func (a *Aggregator) getAppMonitors(policyMap map[string]*models.AppPolicy) map[
 	for appID, appPolicy := range policyMap {
 		for _, rule := range appPolicy.ScalingPolicy.ScalingRules {
 			appMonitors[fmt.Sprintf("%s-%s", appID, rule.MetricType)] = &models.AppMonitor{
-				AppId:      appID,
-				MetricType: rule.MetricType,
-				StatWindow: time.Second * time.Duration(a.defaultStatWindowSecs),
+				AppId:       appID,
+				MetricType:  rule.MetricType,
+				StatWindow:  time.Second * time.Duration(a.defaultStatWindowSecs),
+				SourceAppId: rule.SourceAppId,
 			}
 		}
 	}
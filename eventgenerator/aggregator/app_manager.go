@@ -21,6 +21,10 @@ type GetPoliciesFunc func() map[string]*models.AppPolicy
 type SaveAppMetricToCacheFunc func(*models.AppMetric) bool
 type QueryAppMetricsFunc func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error)
 
+// QueryAppMetricsPageFunc queries at most limit metrics of an app, or with a step the metrics of at most limit
+// buckets of the step.
+type QueryAppMetricsPageFunc func(appID string, metricType string, start int64, end int64, orderType db.OrderType, step time.Duration, limit int) ([]*models.AppMetric, error)
+
 type AppManager struct {
 	logger                lager.Logger
 	interval              time.Duration
@@ -103,8 +107,7 @@ func (am *AppManager) retrievePolicies() ([]*models.PolicyJson, error) {
 }
 
 func (am *AppManager) isEventgeneratorRespForApp(appID string) bool {
-	// #nosec G115 -- nodes will be in the range of a dozen max - no need to worry about integer overflow
-	return helpers.FNVHash(appID)%uint32(am.nodeNum) == uint32(am.nodeIndex)
+	return helpers.OwnerIndex(appID, am.nodeNum) == am.nodeIndex
 }
 
 func (am *AppManager) computePolicies(policyJsons []*models.PolicyJson) map[string]*models.AppPolicy {
@@ -153,30 +156,93 @@ func (am *AppManager) SaveMetricToCache(metric *models.AppMetric) bool {
 }
 
 func (am *AppManager) QueryAppMetrics(appID string, metricType string, start int64, end int64, order db.OrderType) ([]*models.AppMetric, error) {
+	if end == -1 {
+		end = time.Now().UnixNano()
+	}
+
+	if metrics, hit := am.queryCachedAppMetrics(appID, metricType, start, end, order); hit {
+		return metrics, nil
+	}
+	return am.appMetricDB.RetrieveAppMetrics(appID, metricType, start, end, order)
+}
+
+// QueryAppMetricsPage queries at most limit metrics of an app, or with a step the metrics of at most limit buckets of
+// the step, which are aligned to multiples of the step since the epoch. Only these metrics are read from the
+// database, the buckets are determined there first.
+func (am *AppManager) QueryAppMetricsPage(appID string, metricType string, start int64, end int64, order db.OrderType, step time.Duration, limit int) ([]*models.AppMetric, error) {
+	if end == -1 {
+		end = time.Now().UnixNano()
+	}
+
+	if metrics, hit := am.queryCachedAppMetrics(appID, metricType, start, end, order); hit {
+		return limitAppMetrics(metrics, step, limit), nil
+	}
+	if step <= 0 {
+		return am.appMetricDB.RetrieveAppMetricsPage(appID, metricType, start, end, order, limit)
+	}
+
+	buckets, err := am.appMetricDB.RetrieveAppMetricBuckets(appID, metricType, start, end, step.Nanoseconds(), order, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(buckets) == 0 {
+		return []*models.AppMetric{}, nil
+	}
+	lastBucket := buckets[len(buckets)-1]
+	if order == db.ASC {
+		end = min(end, lastBucket+step.Nanoseconds()-1)
+	} else {
+		start = max(start, lastBucket)
+	}
+	return am.appMetricDB.RetrieveAppMetrics(appID, metricType, start, end, order)
+}
+
+func (am *AppManager) queryCachedAppMetrics(appID string, metricType string, start int64, end int64, order db.OrderType) ([]*models.AppMetric, bool) {
 	am.mLock.RLock()
 	appCache := am.metricCache[appID]
 	am.mLock.RUnlock()
 
-	if end == -1 {
-		end = time.Now().UnixNano()
+	if appCache == nil {
+		return nil, false
+	}
+	labels := map[string]string{models.MetricLabelName: metricType}
+	result, hit := appCache.Query(start, end+1, labels)
+	if !hit {
+		return nil, false
+	}
+	metrics := make([]*models.AppMetric, len(result))
+	if order == db.ASC {
+		for index, tsd := range result {
+			metrics[index] = tsd.(*models.AppMetric)
+		}
+	} else {
+		for index, tsd := range result {
+			metrics[len(result)-1-index] = tsd.(*models.AppMetric)
+		}
 	}
+	return metrics, true
+}
 
-	if appCache != nil {
-		labels := map[string]string{models.MetricLabelName: metricType}
-		result, hit := appCache.Query(start, end+1, labels)
-		if hit {
-			metrics := make([]*models.AppMetric, len(result))
-			if order == db.ASC {
-				for index, tsd := range result {
-					metrics[index] = tsd.(*models.AppMetric)
-				}
-			} else {
-				for index, tsd := range result {
-					metrics[len(result)-1-index] = tsd.(*models.AppMetric)
-				}
+// limitAppMetrics cuts ordered metrics down to at most limit metrics, or with a step to the metrics of at most limit
+// buckets of the step which contain a value.
+func limitAppMetrics(metrics []*models.AppMetric, step time.Duration, limit int) []*models.AppMetric {
+	if step <= 0 {
+		return metrics[:min(len(metrics), limit)]
+	}
+
+	count := 0
+	var bucket int64
+	for i, metric := range metrics {
+		if metric.Value == "" {
+			continue
+		}
+		if metricBucket := metric.Timestamp - metric.Timestamp%step.Nanoseconds(); count == 0 || metricBucket != bucket {
+			if count == limit {
+				return metrics[:i]
 			}
-			return metrics, nil
+			count++
+			bucket = metricBucket
 		}
 	}
-	return am.appMetricDB.RetrieveAppMetrics(appID, metricType, start, end, order)
+	return metrics
 }
@@ -111,6 +111,67 @@ var _ = Describe("AppManager", func() {
 					Expect(data).To(Equal([]*models.AppMetric{appMetric1, appMetric2}))
 
 				})
+
+				It("should query pages of metrics", func() {
+					Eventually(policyDB.RetrievePoliciesCallCount).Should(Equal(1))
+					clock.Increment(1 * testAggregator.PolicyPollerInterval)
+					Eventually(policyDB.RetrievePoliciesCallCount).Should(Equal(2))
+
+					appMetric2 := newAppMetric(testAppId, 200)
+					appMetric3 := newAppMetric(testAppId, 300)
+					appMetric4 := newAppMetric(testAppId, 400)
+					Expect(appManager.SaveMetricToCache(appMetric2)).To(BeTrue())
+					Expect(appManager.SaveMetricToCache(appMetric3)).To(BeTrue())
+					Expect(appManager.SaveMetricToCache(appMetric4)).To(BeTrue())
+
+					By("cache hit")
+					data, err := appManager.QueryAppMetricsPage(testAppId, "test-metric-type", 200, 500, db.ASC, 0, 2)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(data).To(Equal([]*models.AppMetric{appMetric2, appMetric3}))
+
+					By("cache hit with a step")
+					data, err = appManager.QueryAppMetricsPage(testAppId, "test-metric-type", 200, 500, db.DESC, 200, 1)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(data).To(Equal([]*models.AppMetric{appMetric4}))
+					data, err = appManager.QueryAppMetricsPage(testAppId, "test-metric-type", 200, 500, db.ASC, 200, 1)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(data).To(Equal([]*models.AppMetric{appMetric2, appMetric3}))
+
+					By("cache miss")
+					appMetricDB.RetrieveAppMetricsPageReturns([]*models.AppMetric{appMetric2}, nil)
+					data, err = appManager.QueryAppMetricsPage(testAppId, "test-metric-type", 100, 500, db.ASC, 0, 1)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(data).To(Equal([]*models.AppMetric{appMetric2}))
+					_, _, start, end, order, limit := appMetricDB.RetrieveAppMetricsPageArgsForCall(0)
+					Expect([]int64{start, end}).To(Equal([]int64{100, 500}))
+					Expect(order).To(Equal(db.ASC))
+					Expect(limit).To(Equal(1))
+
+					By("cache miss with a step")
+					appMetricDB.RetrieveAppMetricBucketsReturns([]int64{0, 100}, nil)
+					appMetricDB.RetrieveAppMetricsReturns([]*models.AppMetric{appMetric2}, nil)
+					_, err = appManager.QueryAppMetricsPage(testAppId, "test-metric-type", 50, 500, db.ASC, 100, 2)
+					Expect(err).NotTo(HaveOccurred())
+					_, _, start, end, step, order, limit := appMetricDB.RetrieveAppMetricBucketsArgsForCall(0)
+					Expect([]int64{start, end, step}).To(Equal([]int64{50, 500, 100}))
+					Expect(order).To(Equal(db.ASC))
+					Expect(limit).To(Equal(2))
+					_, _, start, end, _ = appMetricDB.RetrieveAppMetricsArgsForCall(0)
+					Expect([]int64{start, end}).To(Equal([]int64{50, 199}))
+
+					appMetricDB.RetrieveAppMetricBucketsReturns([]int64{400, 300}, nil)
+					_, err = appManager.QueryAppMetricsPage(testAppId, "test-metric-type", 50, 500, db.DESC, 100, 2)
+					Expect(err).NotTo(HaveOccurred())
+					_, _, start, end, _ = appMetricDB.RetrieveAppMetricsArgsForCall(1)
+					Expect([]int64{start, end}).To(Equal([]int64{300, 500}))
+
+					By("no buckets")
+					appMetricDB.RetrieveAppMetricBucketsReturns([]int64{}, nil)
+					data, err = appManager.QueryAppMetricsPage(testAppId, "test-metric-type", 50, 500, db.DESC, 100, 2)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(data).To(BeEmpty())
+					Expect(appMetricDB.RetrieveAppMetricsCallCount()).To(Equal(2))
+				})
 			})
 
 			When("running with 3 nodes and current node index is 0", func() {
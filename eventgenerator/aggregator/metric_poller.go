@@ -62,7 +62,13 @@ func (m *MetricPoller) retrieveMetric(appMonitor *models.AppMonitor) error {
 	endTime := time.Now()
 	startTime := endTime.Add(0 - statWindow)
 
-	metrics, err := m.metricClient.FetchMetrics(appId, metricType, startTime, endTime)
+	sourceId := appId
+	if appMonitor.SourceAppId != "" {
+		sourceId = appMonitor.SourceAppId
+	}
+
+	// the metrics of a source app are aggregated as the metrics of the scaled app
+	metrics, err := m.metricClient.FetchMetrics(sourceId, metricType, startTime, endTime)
 	if err != nil {
 		return fmt.Errorf("retrieveMetric Failed: %w", err)
 	}
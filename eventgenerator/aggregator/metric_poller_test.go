@@ -235,6 +235,53 @@ var _ = Describe("MetricPoller", func() {
 			})
 		})
 
+		Context("when the metrics are taken from a source app", func() {
+			BeforeEach(func() {
+				appMonitor.SourceAppId = "testSourceAppId"
+				mockLogCache.ReadReturns(testAppId, &rpc.ReadResponse{}, errors.New("error"))
+				mockLogCache.ReadReturns("testSourceAppId", &rpc.ReadResponse{
+					Envelopes: &loggregator_v2.EnvelopeBatch{
+						Batch: []*loggregator_v2.Envelope{
+							{
+								SourceId:   "testSourceAppId",
+								InstanceId: "0",
+								Timestamp:  111100,
+								DeprecatedTags: map[string]*loggregator_v2.Value{
+									"origin": {
+										Data: &loggregator_v2.Value_Text{
+											Text: "autoscaler_metrics_forwarder",
+										},
+									},
+								},
+								Message: &loggregator_v2.Envelope_Gauge{
+									Gauge: &loggregator_v2.Gauge{
+										Metrics: map[string]*loggregator_v2.GaugeValue{
+											testMetricType: {
+												Unit:  testMetricUnit,
+												Value: 42,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				}, nil)
+			})
+
+			It("sends the average metrics of the source app as metrics of the app to appMetric channel", func() {
+				appMetric = <-appMetricChan
+				appMetric.Timestamp = timestamp
+
+				Expect(appMetric).To(Equal(&models.AppMetric{
+					AppId:      testAppId,
+					MetricType: testMetricType,
+					Value:      "42",
+					Unit:       testMetricUnit,
+					Timestamp:  timestamp}))
+			})
+		})
+
 		Context("when an error occurs during metric retrieval", func() {
 			BeforeEach(func() {
 				mockLogCache.ReadReturns(testAppId, &rpc.ReadResponse{}, errors.New("error"))
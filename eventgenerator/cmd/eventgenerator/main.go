@@ -49,7 +49,7 @@ func main() {
 	evaluationManager, err := generator.NewAppEvaluationManager(logger, conf.Evaluator.EvaluationManagerInterval, clock, triggersChan, appManager.GetPolicies, *conf.CircuitBreaker)
 	startup.ExitOnError(err, logger, "failed to create Evaluation Manager")
 
-	evaluators, err := createEvaluators(logger, conf, triggersChan, appManager.QueryAppMetrics, evaluationManager.GetBreaker, evaluationManager.SetCoolDownExpired)
+	evaluators, err := createEvaluators(logger, conf, triggersChan, appManager.QueryAppMetrics, evaluationManager.GetBreaker, evaluationManager.SetCoolDownExpired, evaluationManager.RecordEvaluation)
 	startup.ExitOnError(err, logger, "failed to create Evaluators")
 
 	appMonitorsChan := make(chan *models.AppMonitor, conf.Aggregator.AppMonitorChannelSize)
@@ -68,7 +68,7 @@ func main() {
 	eventGenerator := ifrit.RunFunc(runFunc(appManager, evaluators, evaluationManager, metricPollers, anAggregator))
 
 	// Server setup
-	eventgeneratorServer := server.NewServer(logger.Session("http_server"), conf, appMetricDB.DB, policyDb.DB, appManager.QueryAppMetrics, httpStatusCollector)
+	eventgeneratorServer := server.NewServer(logger.Session("http_server"), conf, appMetricDB.DB, policyDb.DB, appManager.QueryAppMetrics, appManager.QueryAppMetricsPage, metricFetcher, evaluationManager.GetEvaluationState, httpStatusCollector)
 	xm := auth.NewXfccAuthMiddleware(logger, conf.CFServer.XFCC)
 
 	// Start services
@@ -105,7 +105,7 @@ func runFunc(appManager *aggregator.AppManager, evaluators []*generator.Evaluato
 	}
 }
 
-func createEvaluators(logger lager.Logger, conf *config.Config, triggersChan chan []*models.Trigger, queryMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, int64)) ([]*generator.Evaluator, error) {
+func createEvaluators(logger lager.Logger, conf *config.Config, triggersChan chan []*models.Trigger, queryMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, models.ScalingDirection, int64), recordEvaluation func(string, []models.RuleEvaluation)) ([]*generator.Evaluator, error) {
 	count := conf.Evaluator.EvaluatorCount
 
 	seClient, err := helpers.CreateHTTPSClient(&conf.ScalingEngine.TLSClientCerts, helpers.DefaultClientConfig(), logger.Session("scaling_client"))
@@ -116,7 +116,7 @@ func createEvaluators(logger lager.Logger, conf *config.Config, triggersChan cha
 	evaluators := make([]*generator.Evaluator, count)
 	for i := range evaluators {
 		evaluators[i] = generator.NewEvaluator(logger, seClient, conf.ScalingEngine.ScalingEngineURL, triggersChan,
-			conf.DefaultBreachDurationSecs, queryMetrics, getBreaker, setCoolDownExpired)
+			conf.DefaultBreachDurationSecs, queryMetrics, getBreaker, setCoolDownExpired, recordEvaluation, conf.Evaluator.PublishEvaluationEvents)
 	}
 
 	return evaluators, nil
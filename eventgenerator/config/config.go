@@ -57,6 +57,9 @@ type EvaluatorConfig struct {
 	EvaluatorCount            int           `yaml:"evaluator_count" json:"evaluator_count"`
 	TriggerArrayChannelSize   int           `yaml:"trigger_array_channel_size" json:"trigger_array_channel_size"`
 	EvaluationManagerInterval time.Duration `yaml:"evaluation_manager_execute_interval" json:"evaluation_manager_execute_interval"`
+	// PublishEvaluationEvents sends every evaluation of the scaling rules of an app to the scaling engine, which
+	// streams it to the clients of the scaling events of the app.
+	PublishEvaluationEvents bool `yaml:"publish_evaluation_events" json:"publish_evaluation_events"`
 }
 
 type ScalingEngineConfig struct {
@@ -147,6 +150,7 @@ func defaultConfig() Config {
 			EvaluationManagerInterval: DefaultEvaluationExecuteInterval,
 			EvaluatorCount:            DefaultEvaluatorCount,
 			TriggerArrayChannelSize:   DefaultTriggerArrayChannelSize,
+			PublishEvaluationEvents:   true,
 		},
 		HttpClientTimeout: &DefaultHttpClientTimeout,
 	}
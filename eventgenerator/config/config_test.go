@@ -161,6 +161,7 @@ evaluator:
   evaluation_manager_execute_interval: 30s
   evaluator_count: 10
   trigger_array_channel_size: 100
+  publish_evaluation_events: false
 scalingEngine:
   scaling_engine_url: http://localhost:8082
   tls:
@@ -223,7 +224,8 @@ circuitBreaker:
 						Evaluator: &EvaluatorConfig{
 							EvaluationManagerInterval: 30 * time.Second,
 							EvaluatorCount:            10,
-							TriggerArrayChannelSize:   100},
+							TriggerArrayChannelSize:   100,
+							PublishEvaluationEvents:   false},
 						ScalingEngine: ScalingEngineConfig{
 							ScalingEngineURL: "http://localhost:8082",
 							TLSClientCerts: models.TLSCerts{
@@ -362,6 +364,7 @@ defaultBreachDurationSecs: 600
 						EvaluationManagerInterval: DefaultEvaluationExecuteInterval,
 						EvaluatorCount:            DefaultEvaluatorCount,
 						TriggerArrayChannelSize:   DefaultTriggerArrayChannelSize,
+						PublishEvaluationEvents:   true,
 					}))
 					Expect(conf.ScalingEngine).To(Equal(ScalingEngineConfig{
 						ScalingEngineURL: "http://localhost:8082",
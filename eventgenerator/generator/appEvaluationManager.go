@@ -1,6 +1,8 @@
 package generator
 
 import (
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,6 +18,20 @@ import (
 
 type ConsumeAppMonitorMap func(map[string][]*models.Trigger, chan []*models.Trigger)
 
+type GetEvaluationStateFunc func(appID string) (*models.AppEvaluationState, bool)
+
+// cooldownKey identifies the cool-down of an app for scaling actions in one direction.
+type cooldownKey struct {
+	appID     string
+	direction models.ScalingDirection
+}
+
+// ruleKey identifies the evaluation of a scaling rule, or of its emergency threshold, of an app.
+type ruleKey struct {
+	ruleIndex int
+	emergency bool
+}
+
 type AppEvaluationManager struct {
 	evaluateInterval time.Duration
 	logger           lager.Logger
@@ -25,9 +41,12 @@ type AppEvaluationManager struct {
 	getPolicies      aggregator.GetPoliciesFunc
 	breakerConfig    config.CircuitBreakerConfig
 	breakers         map[string]*circuit.Breaker
-	cooldownExpired  map[string]int64
+	cooldownExpired  map[cooldownKey]int64
+	evaluations      map[string]map[ruleKey]models.RuleEvaluationState
+	evaluatedRules   map[string][]*models.ScalingRule
 	breakerLock      *sync.RWMutex
 	cooldownLock     *sync.RWMutex
+	evaluationLock   *sync.RWMutex
 }
 
 func NewAppEvaluationManager(logger lager.Logger, evaluateInterval time.Duration, emClock clock.Clock,
@@ -41,9 +60,12 @@ func NewAppEvaluationManager(logger lager.Logger, evaluateInterval time.Duration
 		triggerChan:      triggerChan,
 		getPolicies:      getPolicies,
 		breakerConfig:    breakerConfig,
-		cooldownExpired:  map[string]int64{},
+		cooldownExpired:  map[cooldownKey]int64{},
+		evaluations:      map[string]map[ruleKey]models.RuleEvaluationState{},
+		evaluatedRules:   map[string][]*models.ScalingRule{},
 		breakerLock:      &sync.RWMutex{},
 		cooldownLock:     &sync.RWMutex{},
+		evaluationLock:   &sync.RWMutex{},
 	}, nil
 }
 
@@ -54,31 +76,63 @@ func (a *AppEvaluationManager) getTriggers(policyMap map[string]*models.AppPolic
 	triggersByApp := make(map[string][]*models.Trigger)
 	for appID, policy := range policyMap {
 		now := a.emClock.Now().UnixNano()
-		a.cooldownLock.RLock()
-		cooldownExpiredAt, found := a.cooldownExpired[appID]
-		a.cooldownLock.RUnlock()
-		if found {
-			if cooldownExpiredAt > now {
+		// emergency triggers come first so that a breached emergency threshold wins over the
+		// regular threshold of the same rule, they are not subject to the cool-down
+		triggers := emergencyTriggers(appID, policy.ScalingPolicy)
+		for i, rule := range policy.ScalingPolicy.ScalingRules {
+			direction := models.DirectionOf(rule.Adjustment)
+			if a.inCooldown(appID, direction, now) {
 				continue
 			}
-		}
-		triggers := []*models.Trigger{}
-		for _, rule := range policy.ScalingPolicy.ScalingRules {
 			triggers = append(triggers, &models.Trigger{
-				AppId:                 appID,
-				MetricType:            rule.MetricType,
-				BreachDurationSeconds: rule.BreachDurationSeconds,
-				CoolDownSeconds:       rule.CoolDownSeconds,
-				Threshold:             rule.Threshold,
-				Operator:              rule.Operator,
-				Adjustment:            rule.Adjustment,
+				AppId:                      appID,
+				MetricType:                 rule.MetricType,
+				BreachDurationSeconds:      rule.BreachDurationSeconds,
+				CoolDownSeconds:            rule.CoolDownSeconds,
+				DirectionalCoolDownSeconds: policy.ScalingPolicy.DirectionalCoolDownSeconds(rule, direction),
+				Threshold:                  rule.Threshold,
+				Operator:                   rule.Operator,
+				Adjustment:                 rule.Adjustment,
+				RuleIndex:                  &i,
 			})
 		}
+		if len(triggers) == 0 {
+			continue
+		}
 		triggersByApp[appID] = triggers
 	}
 	return triggersByApp
 }
 
+func emergencyTriggers(appID string, policy *models.PolicyDefinition) []*models.Trigger {
+	triggers := []*models.Trigger{}
+	for i, rule := range policy.ScalingRules {
+		if rule.EmergencyThreshold == nil {
+			continue
+		}
+		triggers = append(triggers, &models.Trigger{
+			AppId:                      appID,
+			MetricType:                 rule.MetricType,
+			BreachDurationSeconds:      rule.BreachDurationSeconds,
+			CoolDownSeconds:            rule.CoolDownSeconds,
+			DirectionalCoolDownSeconds: policy.DirectionalCoolDownSeconds(rule, models.DirectionOf(rule.EmergencyAdjustment)),
+			Threshold:                  *rule.EmergencyThreshold,
+			Operator:                   rule.Operator,
+			Adjustment:                 rule.EmergencyAdjustment,
+			Emergency:                  true,
+			RuleIndex:                  &i,
+		})
+	}
+	return triggers
+}
+
+func (a *AppEvaluationManager) inCooldown(appID string, direction models.ScalingDirection, now int64) bool {
+	a.cooldownLock.RLock()
+	defer a.cooldownLock.RUnlock()
+	cooldownExpiredAt, found := a.cooldownExpired[cooldownKey{appID: appID, direction: direction}]
+	return found && cooldownExpiredAt > now
+}
+
 func (a *AppEvaluationManager) Start() {
 	go a.doEvaluate()
 	a.logger.Info("started")
@@ -120,6 +174,7 @@ func (a *AppEvaluationManager) doEvaluate() {
 			a.breakerLock.Lock()
 			a.breakers = newBreakers
 			a.breakerLock.Unlock()
+			a.removeEvaluations(policies)
 
 			triggers := a.getTriggers(policies)
 			for _, triggerArray := range triggers {
@@ -135,8 +190,85 @@ func (a *AppEvaluationManager) GetBreaker(appID string) *circuit.Breaker {
 	return a.breakers[appID]
 }
 
-func (a *AppEvaluationManager) SetCoolDownExpired(appID string, expiredAt int64) {
+func (a *AppEvaluationManager) SetCoolDownExpired(appID string, direction models.ScalingDirection, expiredAt int64) {
 	a.cooldownLock.Lock()
 	defer a.cooldownLock.Unlock()
-	a.cooldownExpired[appID] = expiredAt
+	a.cooldownExpired[cooldownKey{appID: appID, direction: direction}] = expiredAt
+}
+
+// RecordEvaluation keeps the latest evaluation of each scaling rule of an app. Rules which are in cool-down or which
+// have not been evaluated because another rule was breached before keep their previous evaluation.
+func (a *AppEvaluationManager) RecordEvaluation(appID string, evaluation []models.RuleEvaluation) {
+	now := a.emClock.Now().UnixNano()
+
+	a.evaluationLock.Lock()
+	defer a.evaluationLock.Unlock()
+	appEvaluations, found := a.evaluations[appID]
+	if !found {
+		appEvaluations = map[ruleKey]models.RuleEvaluationState{}
+		a.evaluations[appID] = appEvaluations
+	}
+	for _, ruleEvaluation := range evaluation {
+		if ruleEvaluation.RuleIndex == nil {
+			continue
+		}
+		key := ruleKey{ruleIndex: *ruleEvaluation.RuleIndex, emergency: ruleEvaluation.Emergency}
+		appEvaluations[key] = models.RuleEvaluationState{RuleEvaluation: ruleEvaluation, EvaluatedAt: now}
+	}
+}
+
+// GetEvaluationState returns the circuit breaker and the latest rule evaluations of an app. It returns false if the
+// app is not evaluated by this instance.
+func (a *AppEvaluationManager) GetEvaluationState(appID string) (*models.AppEvaluationState, bool) {
+	breaker := a.GetBreaker(appID)
+	if breaker == nil {
+		return nil, false
+	}
+
+	state := &models.AppEvaluationState{
+		AppId:   appID,
+		Breaker: &models.BreakerState{Tripped: breaker.Tripped(), ConsecutiveFailures: breaker.ConsecFailures()},
+		Rules:   []models.RuleEvaluationState{},
+	}
+
+	a.evaluationLock.RLock()
+	for _, ruleEvaluation := range a.evaluations[appID] {
+		state.Rules = append(state.Rules, ruleEvaluation)
+	}
+	a.evaluationLock.RUnlock()
+
+	sort.Slice(state.Rules, func(i, j int) bool {
+		if *state.Rules[i].RuleIndex != *state.Rules[j].RuleIndex {
+			return *state.Rules[i].RuleIndex < *state.Rules[j].RuleIndex
+		}
+		return !state.Rules[i].Emergency && state.Rules[j].Emergency
+	})
+	return state, true
+}
+
+// removeEvaluations forgets the evaluations of apps which are no longer evaluated by this instance, and of apps
+// whose scaling rules have changed, as the rule indexes of their evaluations may no longer refer to the same rules.
+func (a *AppEvaluationManager) removeEvaluations(policies map[string]*models.AppPolicy) {
+	a.evaluationLock.Lock()
+	defer a.evaluationLock.Unlock()
+	for appID := range a.evaluatedRules {
+		if _, found := policies[appID]; !found {
+			delete(a.evaluatedRules, appID)
+		}
+	}
+	for appID := range a.evaluations {
+		if _, found := policies[appID]; !found {
+			delete(a.evaluations, appID)
+		}
+	}
+	for appID, policy := range policies {
+		var rules []*models.ScalingRule
+		if policy != nil && policy.ScalingPolicy != nil {
+			rules = policy.ScalingPolicy.ScalingRules
+		}
+		if evaluatedRules, found := a.evaluatedRules[appID]; found && !reflect.DeepEqual(evaluatedRules, rules) {
+			delete(a.evaluations, appID)
+		}
+		a.evaluatedRules[appID] = rules
+	}
 }
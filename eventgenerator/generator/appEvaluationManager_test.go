@@ -2,6 +2,7 @@ package generator_test
 
 import (
 	"reflect"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
@@ -31,6 +32,7 @@ var _ = Describe("AppEvaluationManager", func() {
 		testMetricName       = "Test-Metric-Name"
 		testBreakerConfig    = config.CircuitBreakerConfig{}
 		fakeTime             = time.Now()
+		firstRuleIndex       = 0
 
 		appPolicy1 = &models.AppPolicy{
 			AppId: testAppId1,
@@ -118,6 +120,7 @@ var _ = Describe("AppEvaluationManager", func() {
 							Threshold:             80,
 							Operator:              ">=",
 							Adjustment:            "1",
+							RuleIndex:             &firstRuleIndex,
 						}}))
 					Expect(triggerArray).Should(ContainElement(
 						[]*models.Trigger{{
@@ -128,6 +131,7 @@ var _ = Describe("AppEvaluationManager", func() {
 							Threshold:             20,
 							Operator:              "<=",
 							Adjustment:            "-1",
+							RuleIndex:             &firstRuleIndex,
 						}}))
 				})
 			})
@@ -143,7 +147,7 @@ var _ = Describe("AppEvaluationManager", func() {
 				})
 
 				JustBeforeEach(func() {
-					manager.SetCoolDownExpired(testAppId2, fakeTime.Add(30*testEvaluateInterval).UnixNano())
+					manager.SetCoolDownExpired(testAppId2, models.ScalingDirectionIn, fakeTime.Add(30*testEvaluateInterval).UnixNano())
 				})
 
 				It("should add triggers to evaluate after cooldown expired", func() {
@@ -168,9 +172,105 @@ var _ = Describe("AppEvaluationManager", func() {
 							Threshold:             20,
 							Operator:              "<=",
 							Adjustment:            "-1",
+							RuleIndex:             &firstRuleIndex,
 						}}))
 				})
 			})
+			Context("when there is cooldownExpiredAt setting for the other direction of testAppId2", func() {
+				BeforeEach(func() {
+					getPolicies = func() map[string]*models.AppPolicy {
+						return map[string]*models.AppPolicy{
+							testAppId2: appPolicy2,
+						}
+					}
+				})
+
+				JustBeforeEach(func() {
+					manager.SetCoolDownExpired(testAppId2, models.ScalingDirectionOut, fakeTime.Add(30*testEvaluateInterval).UnixNano())
+				})
+
+				It("should add triggers to evaluate", func() {
+					fclock.Increment(10 * testEvaluateInterval)
+					Eventually(triggerArrayChan).Should(Receive())
+				})
+			})
+
+			Context("when a rule has an emergency threshold and the app is in cooldown period", func() {
+				BeforeEach(func() {
+					emergencyThreshold := int64(95)
+					getPolicies = func() map[string]*models.AppPolicy {
+						return map[string]*models.AppPolicy{
+							testAppId1: {
+								AppId: testAppId1,
+								ScalingPolicy: &models.PolicyDefinition{
+									InstanceMax: 5,
+									InstanceMin: 1,
+									ScalingRules: []*models.ScalingRule{
+										{
+											MetricType:          testMetricName,
+											Threshold:           80,
+											Operator:            ">=",
+											Adjustment:          "+1",
+											EmergencyThreshold:  &emergencyThreshold,
+											EmergencyAdjustment: "+3",
+										},
+									},
+								},
+							},
+						}
+					}
+				})
+
+				JustBeforeEach(func() {
+					manager.SetCoolDownExpired(testAppId1, models.ScalingDirectionOut, fakeTime.Add(30*testEvaluateInterval).UnixNano())
+				})
+
+				It("should add the emergency trigger only", func() {
+					fclock.Increment(10 * testEvaluateInterval)
+					var arr []*models.Trigger
+					Eventually(triggerArrayChan).Should(Receive(&arr))
+					Expect(arr).To(Equal([]*models.Trigger{{
+						AppId:      testAppId1,
+						MetricType: testMetricName,
+						Threshold:  95,
+						Operator:   ">=",
+						Adjustment: "+3",
+						Emergency:  true,
+						RuleIndex:  &firstRuleIndex,
+					}}))
+				})
+			})
+
+			Context("when the policy has directional cool-downs", func() {
+				BeforeEach(func() {
+					getPolicies = func() map[string]*models.AppPolicy {
+						return map[string]*models.AppPolicy{
+							testAppId1: {
+								AppId: testAppId1,
+								ScalingPolicy: &models.PolicyDefinition{
+									InstanceMax:             5,
+									InstanceMin:             1,
+									ScaleOutCoolDownSeconds: 60,
+									ScaleInCoolDownSeconds:  600,
+									ScalingRules: []*models.ScalingRule{
+										{MetricType: testMetricName, Threshold: 80, Operator: ">=", Adjustment: "+1"},
+										{MetricType: testMetricName, Threshold: 20, Operator: "<=", Adjustment: "-1", ScaleInCoolDownSeconds: 900},
+									},
+								},
+							},
+						}
+					}
+				})
+
+				It("should resolve the cool-down of each trigger", func() {
+					fclock.Increment(10 * testEvaluateInterval)
+					var arr []*models.Trigger
+					Eventually(triggerArrayChan).Should(Receive(&arr))
+					Expect(arr).To(HaveLen(2))
+					Expect(arr[0].DirectionalCoolDownSeconds).To(Equal(60))
+					Expect(arr[1].DirectionalCoolDownSeconds).To(Equal(900))
+				})
+			})
 		})
 
 		Context("when there is no trigger", func() {
@@ -270,21 +370,21 @@ var _ = Describe("AppEvaluationManager", func() {
 
 		It("insert the cooldownExpiredAt records in map", func() {
 
-			manager.SetCoolDownExpired(testAppId1, fakeTime.Add(time.Duration(20)*time.Second).UnixNano())
-			manager.SetCoolDownExpired(testAppId2, fakeTime.Add(time.Duration(30)*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId1, models.ScalingDirectionOut, fakeTime.Add(time.Duration(20)*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId1, models.ScalingDirectionIn, fakeTime.Add(time.Duration(40)*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId2, models.ScalingDirectionOut, fakeTime.Add(time.Duration(30)*time.Second).UnixNano())
 
 			v := reflect.ValueOf(manager).Elem()
 			coolDownExpiredReflect := v.FieldByName("cooldownExpired")
-			Expect(coolDownExpiredReflect.Len()).Should(Equal(2))
+			Expect(coolDownExpiredReflect.Len()).Should(Equal(3))
+			expected := map[string]int64{
+				testAppId1 + "/out": fakeTime.Add(time.Duration(20) * time.Second).UnixNano(),
+				testAppId1 + "/in":  fakeTime.Add(time.Duration(40) * time.Second).UnixNano(),
+				testAppId2 + "/out": fakeTime.Add(time.Duration(30) * time.Second).UnixNano(),
+			}
 			for _, key := range coolDownExpiredReflect.MapKeys() {
 				value := coolDownExpiredReflect.MapIndex(key).Int()
-				if key.String() == testAppId1 {
-					Expect(value).Should(Equal(fakeTime.Add(time.Duration(20) * time.Second).UnixNano()))
-				}
-				if key.String() == testAppId2 {
-					Expect(value).Should(Equal(fakeTime.Add(time.Duration(30) * time.Second).UnixNano()))
-				}
-
+				Expect(value).Should(Equal(expected[key.Field(0).String()+"/"+key.Field(1).String()]))
 			}
 
 		})
@@ -294,4 +394,141 @@ var _ = Describe("AppEvaluationManager", func() {
 		})
 
 	})
+
+	Describe("GetEvaluationState", func() {
+		var (
+			evaluated    bool
+			policies     map[string]*models.AppPolicy
+			policiesLock sync.Mutex
+		)
+
+		setPolicies := func(appPolicies map[string]*models.AppPolicy) {
+			policiesLock.Lock()
+			defer policiesLock.Unlock()
+			policies = appPolicies
+		}
+
+		BeforeEach(func() {
+			setPolicies(map[string]*models.AppPolicy{testAppId1: appPolicy1})
+			getPolicies = func() map[string]*models.AppPolicy {
+				policiesLock.Lock()
+				defer policiesLock.Unlock()
+				return policies
+			}
+			var err error
+			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, triggerArrayChan, getPolicies, testBreakerConfig)
+			Expect(err).NotTo(HaveOccurred())
+			manager.Start()
+			Eventually(fclock.WatcherCount).Should(Equal(1))
+		})
+
+		AfterEach(func() {
+			manager.Stop()
+		})
+
+		It("is not known before the app is evaluated by this instance", func() {
+			_, evaluated = manager.GetEvaluationState(testAppId1)
+			Expect(evaluated).To(BeFalse())
+		})
+
+		Context("when the app is evaluated", func() {
+			BeforeEach(func() {
+				fclock.Increment(1 * testEvaluateInterval)
+				Eventually(func() *circuit.Breaker { return manager.GetBreaker(testAppId1) }).ShouldNot(BeNil())
+			})
+
+			It("returns the latest evaluation of each rule and the state of the breaker", func() {
+				secondRuleIndex := 1
+				manager.RecordEvaluation(testAppId1, []models.RuleEvaluation{
+					{RuleIndex: &secondRuleIndex, MetricType: testMetricName, Breached: true},
+					{RuleIndex: &firstRuleIndex, MetricType: testMetricName, Emergency: true},
+				})
+				fclock.Increment(10 * time.Second)
+				manager.RecordEvaluation(testAppId1, []models.RuleEvaluation{
+					{RuleIndex: &firstRuleIndex, MetricType: testMetricName, Samples: []models.MetricSample{{Value: "90"}}},
+					{MetricType: "without-rule-index"},
+				})
+				manager.GetBreaker(testAppId1).Fail()
+
+				state, evaluated := manager.GetEvaluationState(testAppId1)
+				Expect(evaluated).To(BeTrue())
+				Expect(state.AppId).To(Equal(testAppId1))
+				Expect(state.Breaker).To(Equal(&models.BreakerState{Tripped: false, ConsecutiveFailures: 1}))
+				Expect(state.Rules).To(HaveLen(3))
+				Expect(*state.Rules[0].RuleIndex).To(Equal(0))
+				Expect(state.Rules[0].Emergency).To(BeFalse())
+				Expect(state.Rules[0].Samples).To(Equal([]models.MetricSample{{Value: "90"}}))
+				Expect(state.Rules[0].EvaluatedAt).To(Equal(fclock.Now().UnixNano()))
+				Expect(state.Rules[1].Emergency).To(BeTrue())
+				Expect(state.Rules[1].EvaluatedAt).To(Equal(fakeTime.Add(1 * testEvaluateInterval).UnixNano()))
+				Expect(*state.Rules[2].RuleIndex).To(Equal(1))
+				Expect(state.Rules[2].Breached).To(BeTrue())
+			})
+
+			Context("when the scaling rules of the app change", func() {
+				BeforeEach(func() {
+					manager.RecordEvaluation(testAppId1, []models.RuleEvaluation{{RuleIndex: &firstRuleIndex, MetricType: testMetricName}})
+					setPolicies(map[string]*models.AppPolicy{testAppId1: {
+						AppId: testAppId1,
+						ScalingPolicy: &models.PolicyDefinition{
+							InstanceMax:  5,
+							InstanceMin:  1,
+							ScalingRules: appPolicy2.ScalingPolicy.ScalingRules,
+						},
+					}})
+					fclock.Increment(1 * testEvaluateInterval)
+				})
+
+				It("forgets the evaluations of the previous rules", func() {
+					Eventually(func() []models.RuleEvaluationState {
+						state, _ := manager.GetEvaluationState(testAppId1)
+						return state.Rules
+					}).Should(BeEmpty())
+				})
+			})
+
+			Context("when only the instance limits of the app change", func() {
+				BeforeEach(func() {
+					manager.RecordEvaluation(testAppId1, []models.RuleEvaluation{{RuleIndex: &firstRuleIndex, MetricType: testMetricName}})
+					setPolicies(map[string]*models.AppPolicy{testAppId1: {
+						AppId: testAppId1,
+						ScalingPolicy: &models.PolicyDefinition{
+							InstanceMax:  10,
+							InstanceMin:  2,
+							ScalingRules: appPolicy1.ScalingPolicy.ScalingRules,
+						},
+					}})
+					fclock.Increment(1 * testEvaluateInterval)
+				})
+
+				It("keeps the evaluations of the rules", func() {
+					Consistently(func() []models.RuleEvaluationState {
+						state, _ := manager.GetEvaluationState(testAppId1)
+						return state.Rules
+					}).Should(HaveLen(1))
+				})
+			})
+
+			Context("when the app is no longer evaluated by this instance", func() {
+				BeforeEach(func() {
+					manager.RecordEvaluation(testAppId1, []models.RuleEvaluation{{RuleIndex: &firstRuleIndex, MetricType: testMetricName}})
+					setPolicies(map[string]*models.AppPolicy{testAppId2: appPolicy2})
+					fclock.Increment(1 * testEvaluateInterval)
+					Eventually(func() *circuit.Breaker { return manager.GetBreaker(testAppId1) }).Should(BeNil())
+				})
+
+				It("forgets the evaluations of the app", func() {
+					_, evaluated = manager.GetEvaluationState(testAppId1)
+					Expect(evaluated).To(BeFalse())
+
+					setPolicies(map[string]*models.AppPolicy{testAppId1: appPolicy1})
+					fclock.Increment(1 * testEvaluateInterval)
+					Eventually(func() *circuit.Breaker { return manager.GetBreaker(testAppId1) }).ShouldNot(BeNil())
+					state, evaluated := manager.GetEvaluationState(testAppId1)
+					Expect(evaluated).To(BeTrue())
+					Expect(state.Rules).To(BeEmpty())
+				})
+			})
+		})
+	})
 })
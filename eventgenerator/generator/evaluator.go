@@ -2,6 +2,7 @@ package generator
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,11 +12,16 @@ import (
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
 
 	"code.cloudfoundry.org/lager/v3"
+	"github.com/google/uuid"
 	circuit "github.com/rubyist/circuitbreaker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var validOperators = []string{">", ">=", "<", "<="}
@@ -29,11 +35,14 @@ type Evaluator struct {
 	defaultBreachDurationSecs int
 	queryAppMetrics           aggregator.QueryAppMetricsFunc
 	getBreaker                func(string) *circuit.Breaker
-	setCoolDownExpired        func(string, int64)
+	setCoolDownExpired        func(string, models.ScalingDirection, int64)
+	recordEvaluation          func(string, []models.RuleEvaluation)
+	publishEvaluations        bool
 }
 
 func NewEvaluator(logger lager.Logger, httpClient *http.Client, scalingEngineUrl string, triggerChan chan []*models.Trigger,
-	defaultBreachDurationSecs int, queryAppMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, int64)) *Evaluator {
+	defaultBreachDurationSecs int, queryAppMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, models.ScalingDirection, int64),
+	recordEvaluation func(string, []models.RuleEvaluation), publishEvaluations bool) *Evaluator {
 	return &Evaluator{
 		logger:                    logger.Session("Evaluator"),
 		httpClient:                httpClient,
@@ -44,6 +53,8 @@ func NewEvaluator(logger lager.Logger, httpClient *http.Client, scalingEngineUrl
 		queryAppMetrics:           queryAppMetrics,
 		getBreaker:                getBreaker,
 		setCoolDownExpired:        setCoolDownExpired,
+		recordEvaluation:          recordEvaluation,
+		publishEvaluations:        publishEvaluations,
 	}
 }
 
@@ -70,6 +81,21 @@ func (e *Evaluator) Stop() {
 }
 
 func (e *Evaluator) doEvaluate(triggerArray []*models.Trigger) {
+	// the decision id is propagated with the trace context to the scaling engine and the cloud controller
+	decisionId := uuid.NewString()
+	ctx, span := otel.Tracer("eventgenerator").Start(helpers.ContextWithDecisionId(context.Background(), decisionId), "evaluate",
+		trace.WithAttributes(attribute.String("decision_id", decisionId)))
+	defer span.End()
+
+	var evaluation []models.RuleEvaluation
+	if len(triggerArray) > 0 {
+		defer func() {
+			e.recordEvaluation(triggerArray[0].AppId, evaluation)
+			if e.publishEvaluations && len(evaluation) > 0 {
+				e.publishEvaluation(ctx, triggerArray[0].AppId, evaluation)
+			}
+		}()
+	}
 	for _, trigger := range triggerArray {
 		if trigger.BreachDurationSeconds <= 0 {
 			trigger.BreachDurationSeconds = e.defaultBreachDurationSecs
@@ -87,25 +113,32 @@ func (e *Evaluator) doEvaluate(triggerArray []*models.Trigger) {
 		}
 		if len(appMetricList) == 0 {
 			e.logger.Debug("no-available-appmetric", lager.Data{"trigger": trigger})
+			evaluation = append(evaluation, newRuleEvaluation(trigger, appMetricList, false))
 			continue
 		}
 
 		isBreached, appMetric := checkForBreach(appMetricList, e, trigger, operator, threshold)
+		evaluation = append(evaluation, newRuleEvaluation(trigger, appMetricList, isBreached))
 
 		if isBreached {
 			trigger.MetricUnit = appMetricList[0].Unit
-			e.logger.Info("send trigger alarm to scaling engine", lager.Data{"trigger": trigger, "last_metric": appMetric})
+			// the metrics are ordered from the latest to the oldest and have all been parsed by checkForBreach
+			if value, err := strconv.ParseInt(appMetricList[0].Value, 10, 64); err == nil {
+				trigger.ObservedValue = &value
+			}
+			trigger.Evaluation = evaluation
+			e.logger.Info("send trigger alarm to scaling engine", lager.Data{"trigger": trigger, "last_metric": appMetric, "decision_id": decisionId})
 
 			if appBreaker := e.getBreaker(trigger.AppId); appBreaker != nil {
 				if appBreaker.Tripped() {
 					e.logger.Info("circuit-tripped", lager.Data{"appId": trigger.AppId, "consecutiveFailures": appBreaker.ConsecFailures()})
 				}
-				err = appBreaker.Call(func() error { return e.sendTriggerAlarm(trigger) }, 0)
+				err = appBreaker.Call(func() error { return e.sendTriggerAlarm(ctx, trigger) }, 0)
 				if err != nil {
 					e.logger.Error("circuit-alarm-failed", err, lager.Data{"appId": trigger.AppId})
 				}
 			} else {
-				err = e.sendTriggerAlarm(trigger)
+				err = e.sendTriggerAlarm(ctx, trigger)
 				if err != nil {
 					e.logger.Error("circuit-alarm-failed", err, lager.Data{"appId": trigger.AppId})
 				}
@@ -115,6 +148,23 @@ func (e *Evaluator) doEvaluate(triggerArray []*models.Trigger) {
 	}
 }
 
+func newRuleEvaluation(trigger *models.Trigger, appMetricList []*models.AppMetric, breached bool) models.RuleEvaluation {
+	samples := make([]models.MetricSample, len(appMetricList))
+	for i, appMetric := range appMetricList {
+		samples[i] = models.MetricSample{Timestamp: appMetric.Timestamp, Value: appMetric.Value, Unit: appMetric.Unit}
+	}
+	return models.RuleEvaluation{
+		RuleIndex:  trigger.RuleIndex,
+		MetricType: trigger.MetricType,
+		Threshold:  trigger.Threshold,
+		Operator:   trigger.Operator,
+		Adjustment: trigger.Adjustment,
+		Emergency:  trigger.Emergency,
+		Samples:    samples,
+		Breached:   breached,
+	}
+}
+
 func checkForBreach(appMetricList []*models.AppMetric, e *Evaluator, trigger *models.Trigger, operator string, threshold int64) (bool, *models.AppMetric) {
 	var appMetric *models.AppMetric
 	for _, appMetric = range appMetricList {
@@ -182,7 +232,7 @@ func (e *Evaluator) retrieveAppMetrics(trigger *models.Trigger) ([]*models.AppMe
 	return result, nil
 }
 
-func (e *Evaluator) sendTriggerAlarm(trigger *models.Trigger) error {
+func (e *Evaluator) sendTriggerAlarm(ctx context.Context, trigger *models.Trigger) error {
 	jsonBytes, err := json.Marshal(trigger)
 	if err != nil {
 		e.logger.Error("failed-marshal-trigger", err)
@@ -197,7 +247,14 @@ func (e *Evaluator) sendTriggerAlarm(trigger *models.Trigger) error {
 		return fmt.Errorf("failed to create url ScaleRouteName, %s: %w", trigger.AppId, err)
 	}
 
-	resp, err := e.httpClient.Post(e.scalingEngineUrl+path.Path, "application/json", bytes.NewReader(jsonBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.scalingEngineUrl+path.Path, bytes.NewReader(jsonBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create trigger alarm request for %s: %w", trigger.AppId, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	helpers.InjectTraceContext(ctx, req.Header)
+
+	resp, err := e.httpClient.Do(req)
 	if err != nil {
 		e.logger.Error("failed-send-trigger-alarm-request", err, lager.Data{"trigger": trigger})
 		return err
@@ -219,7 +276,7 @@ func (e *Evaluator) sendTriggerAlarm(trigger *models.Trigger) error {
 		}
 		e.logger.Debug("successfully-send-trigger-alarm with trigger", lager.Data{"trigger": trigger, "responseBody": string(respBody)})
 		if scalingResult.CooldownExpiredAt != 0 {
-			e.setCoolDownExpired(trigger.AppId, scalingResult.CooldownExpiredAt)
+			e.setCoolDownExpired(trigger.AppId, trigger.Direction(), scalingResult.CooldownExpiredAt)
 		}
 		return nil
 	}
@@ -228,6 +285,45 @@ func (e *Evaluator) sendTriggerAlarm(trigger *models.Trigger) error {
 	return err
 }
 
+// publishEvaluation sends an evaluation of the scaling rules of an app to the scaling engine, which stores it as an
+// event of the live event stream of the app. Failures are only logged, as the evaluation does not depend on it.
+func (e *Evaluator) publishEvaluation(ctx context.Context, appId string, evaluation []models.RuleEvaluation) {
+	jsonBytes, err := json.Marshal(models.NewEvaluationEvent(appId, time.Now().UnixNano(), evaluation))
+	if err != nil {
+		e.logger.Error("failed-marshal-evaluation-event", err)
+		return
+	}
+
+	r := routes.NewRouter()
+	scalingEngineRouter := r.CreateScalingEngineRoutes()
+
+	path, err := scalingEngineRouter.Get(routes.SaveEvaluationEventRouteName).URLPath("appid", appId)
+	if err != nil {
+		e.logger.Error("failed-to-create-evaluation-event-url", err, lager.Data{"appId": appId})
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.scalingEngineUrl+path.Path, bytes.NewReader(jsonBytes))
+	if err != nil {
+		e.logger.Error("failed-to-create-evaluation-event-request", err, lager.Data{"appId": appId})
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	helpers.InjectTraceContext(ctx, req.Header)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		e.logger.Error("failed-send-evaluation-event-request", err, lager.Data{"appId": appId})
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		e.logger.Error("failed-send-evaluation-event", fmt.Errorf("got %d when sending evaluation event", resp.StatusCode), lager.Data{"appId": appId, "responseBody": string(respBody)})
+	}
+}
+
 func (e *Evaluator) isValidOperator(operator string) bool {
 	for _, o := range validOperators {
 		if o == operator {
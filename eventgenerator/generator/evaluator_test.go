@@ -1,6 +1,7 @@
 package generator_test
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"sync"
@@ -9,6 +10,7 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
 
@@ -35,9 +37,12 @@ var _ = Describe("Evaluator", func() {
 		breachDurationSecs = 30
 		queryAppMetrics    aggregator.QueryAppMetricsFunc
 		getBreaker         func(string) *circuit.Breaker
-		setCoolDownExpired func(string, int64)
+		setCoolDownExpired func(string, models.ScalingDirection, int64)
+		recordEvaluation   func(string, []models.RuleEvaluation)
+		evaluations        map[string][]models.RuleEvaluation
 		cbEventChan        <-chan circuit.BreakerEvent
 		cooldownExpired    map[string]int64
+		cooldownDirections map[string]models.ScalingDirection
 		fakeTime           = time.Now()
 		lock               = &sync.Mutex{}
 		scalingResult      *models.AppScalingResult
@@ -124,10 +129,18 @@ var _ = Describe("Evaluator", func() {
 		}
 
 		cooldownExpired = map[string]int64{}
-		setCoolDownExpired = func(appId string, expiredAt int64) {
+		cooldownDirections = map[string]models.ScalingDirection{}
+		setCoolDownExpired = func(appId string, direction models.ScalingDirection, expiredAt int64) {
 			lock.Lock()
 			defer lock.Unlock()
 			cooldownExpired[appId] = expiredAt
+			cooldownDirections[appId] = direction
+		}
+		evaluations = map[string][]models.RuleEvaluation{}
+		recordEvaluation = func(appId string, evaluation []models.RuleEvaluation) {
+			lock.Lock()
+			defer lock.Unlock()
+			evaluations[appId] = evaluation
 		}
 
 	})
@@ -137,7 +150,7 @@ var _ = Describe("Evaluator", func() {
 
 	Context("Start", func() {
 		JustBeforeEach(func() {
-			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired)
+			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired, recordEvaluation, false)
 			evaluator.Start()
 		})
 
@@ -182,7 +195,7 @@ var _ = Describe("Evaluator", func() {
 							}
 							scalingEngine.RouteToHandler("POST", urlPath,
 								ghttp.CombineHandlers(
-									ghttp.VerifyJSONRepresenting(models.Trigger{
+									verifyTriggerAlarm(withObservedValue(models.Trigger{
 										AppId:                 testAppId,
 										MetricType:            testMetricType,
 										MetricUnit:            testMetricUnit,
@@ -191,7 +204,7 @@ var _ = Describe("Evaluator", func() {
 										Threshold:             500,
 										Operator:              ">",
 										Adjustment:            "+1",
-									}),
+									}, 620)),
 									ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult)),
 							)
 						})
@@ -200,6 +213,13 @@ var _ = Describe("Evaluator", func() {
 							Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("send trigger alarm to scaling engine")))
 						})
 
+						It("records the breached evaluation", func() {
+							Eventually(getEvaluation(lock, evaluations, testAppId)).Should(HaveLen(1))
+							evaluation := getEvaluation(lock, evaluations, testAppId)()
+							Expect(evaluation[0].Breached).To(BeTrue())
+							Expect(evaluation[0].Samples[0].Value).To(Equal("620"))
+						})
+
 					})
 					Context("when the appMetrics do not breach the trigger", func() {
 						BeforeEach(func() {
@@ -213,6 +233,11 @@ var _ = Describe("Evaluator", func() {
 							Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("should not send trigger alarm to scaling engine")))
 						})
 
+						It("records the evaluation which is not breached", func() {
+							Eventually(getEvaluation(lock, evaluations, testAppId)).Should(HaveLen(1))
+							Expect(getEvaluation(lock, evaluations, testAppId)()[0].Breached).To(BeFalse())
+						})
+
 					})
 					Context("when appMetrics is empty", func() {
 						BeforeEach(func() {
@@ -436,7 +461,7 @@ var _ = Describe("Evaluator", func() {
 						scalingEngine.AppendHandlers(
 							ghttp.CombineHandlers(
 								ghttp.VerifyRequest("POST", urlPath),
-								ghttp.VerifyJSONRepresenting(firstTrigger),
+								verifyTriggerAlarm(withObservedValue(firstTrigger, 600)),
 								ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
 							),
 						)
@@ -458,7 +483,7 @@ var _ = Describe("Evaluator", func() {
 						scalingEngine.AppendHandlers(
 							ghttp.CombineHandlers(
 								ghttp.VerifyRequest("POST", urlPath),
-								ghttp.VerifyJSONRepresenting(secondTrigger),
+								verifyTriggerAlarm(withObservedValue(secondTrigger, 500)),
 								ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
 							),
 						)
@@ -475,12 +500,61 @@ var _ = Describe("Evaluator", func() {
 					})
 				})
 
+				Context("when a decision is taken", func() {
+					var (
+						triggers chan models.Trigger
+						headers  chan http.Header
+					)
+
+					BeforeEach(func() {
+						helpers.SetupOpenTelemetry()
+						triggers = make(chan models.Trigger, 1)
+						headers = make(chan http.Header, 1)
+						scalingEngine.AppendHandlers(
+							ghttp.CombineHandlers(
+								func(_ http.ResponseWriter, req *http.Request) {
+									var trigger models.Trigger
+									Expect(json.NewDecoder(req.Body).Decode(&trigger)).To(Succeed())
+									headers <- req.Header
+									triggers <- trigger
+								},
+								ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
+							),
+						)
+						appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{300, 400, 500}, breachDurationSecs, true)
+						queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+							return appMetrics, nil
+						}
+					})
+
+					It("sends the evaluation of the rules with the trigger alarm", func() {
+						var trigger models.Trigger
+						Eventually(triggers).Should(Receive(&trigger))
+						Expect(trigger.Evaluation).To(HaveLen(2))
+						Expect(trigger.Evaluation[0].Operator).To(Equal(">="))
+						Expect(trigger.Evaluation[0].Breached).To(BeFalse())
+						Expect(trigger.Evaluation[1].Operator).To(Equal("<="))
+						Expect(trigger.Evaluation[1].Breached).To(BeTrue())
+						Expect(trigger.Evaluation[1].Samples).To(HaveLen(3))
+						Expect(trigger.Evaluation[1].Samples[0].Value).To(Equal("500"))
+						Expect(trigger.Evaluation[1].Samples[0].Unit).To(Equal(testMetricUnit))
+					})
+
+					It("propagates the decision id with the trace context", func() {
+						var header http.Header
+						Eventually(headers).Should(Receive(&header))
+						Expect(header.Get("traceparent")).NotTo(BeEmpty())
+						Expect(header.Get("baggage")).To(MatchRegexp(`^autoscaler-decision-id=[0-9a-f-]{36}$`))
+						Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("send trigger alarm to scaling engine")))
+					})
+				})
+
 				Context("when both tiggers breach", func() {
 					BeforeEach(func() {
 						scalingEngine.AppendHandlers(
 							ghttp.CombineHandlers(
 								ghttp.VerifyRequest("POST", urlPath),
-								ghttp.VerifyJSONRepresenting(firstTrigger),
+								verifyTriggerAlarm(withObservedValue(firstTrigger, 500)),
 								ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
 							),
 						)
@@ -525,6 +599,7 @@ var _ = Describe("Evaluator", func() {
 							lock.Lock()
 							Eventually(cooldownExpired).Should(HaveLen(1))
 							Eventually(cooldownExpired[testAppId]).Should(Equal(fakeTime.Add(time.Duration(300) * time.Second).UnixNano()))
+							Expect(cooldownDirections[testAppId]).To(Equal(models.ScalingDirectionOut))
 							lock.Unlock()
 						})
 					})
@@ -747,13 +822,57 @@ var _ = Describe("Evaluator", func() {
 		})
 	})
 
+	Context("when evaluations are published", func() {
+		var publishedEvents chan *models.ScalingEvent
+
+		BeforeEach(func() {
+			scalingEngine = ghttp.NewServer()
+			path, err := routes.NewRouter().CreateScalingEngineRoutes().Get(routes.SaveEvaluationEventRouteName).URLPath("appid", testAppId)
+			Expect(err).NotTo(HaveOccurred())
+			publishedEvents = make(chan *models.ScalingEvent, 1)
+			scalingEngine.RouteToHandler(http.MethodPost, path.Path, ghttp.CombineHandlers(
+				ghttp.VerifyContentType("application/json"),
+				func(_ http.ResponseWriter, r *http.Request) {
+					event := &models.ScalingEvent{}
+					Expect(json.NewDecoder(r.Body).Decode(event)).To(Succeed())
+					publishedEvents <- event
+				},
+				ghttp.RespondWith(http.StatusOK, nil),
+			))
+
+			appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{200, 150, 600}, breachDurationSecs, true)
+			queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+				return appMetrics, nil
+			}
+			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired, recordEvaluation, true)
+			evaluator.Start()
+			Expect(triggerChan).To(BeSent(triggerArrayGT))
+		})
+
+		AfterEach(func() {
+			evaluator.Stop()
+			scalingEngine.Close()
+		})
+
+		It("sends the evaluation to the scaling engine", func() {
+			var event *models.ScalingEvent
+			Eventually(publishedEvents).Should(Receive(&event))
+			Expect(event.AppId).To(Equal(testAppId))
+			Expect(event.Type).To(Equal(models.ScalingEventEvaluation))
+			Expect(event.Timestamp).To(BeNumerically(">", 0))
+			Expect(event.Evaluation).To(HaveLen(1))
+			Expect(event.Evaluation[0].MetricType).To(Equal(testMetricType))
+			Expect(event.Evaluation[0].Breached).To(BeFalse())
+		})
+	})
+
 	Context("Stop", func() {
 		BeforeEach(func() {
 			scalingEngine = ghttp.NewServer()
 			queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
 				return nil, nil
 			}
-			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired)
+			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired, recordEvaluation, false)
 			evaluator.Start()
 			Expect(triggerChan).To(BeSent(triggerArrayGT))
 
@@ -772,7 +891,7 @@ var _ = Describe("Evaluator", func() {
 			queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
 				return appMetrics, nil
 			}
-			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired)
+			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired, recordEvaluation, false)
 			evaluator.Start()
 			Expect(triggerChan).To(BeSent(triggerArrayGT))
 		})
@@ -787,3 +906,11 @@ var _ = Describe("Evaluator", func() {
 		})
 	})
 })
+
+func getEvaluation(lock *sync.Mutex, evaluations map[string][]models.RuleEvaluation, appId string) func() []models.RuleEvaluation {
+	return func() []models.RuleEvaluation {
+		lock.Lock()
+		defer lock.Unlock()
+		return evaluations[appId]
+	}
+}
@@ -1,6 +1,8 @@
 package generator_test
 
 import (
+	"encoding/json"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -39,3 +41,24 @@ func generateTestAppMetrics(appId string, metricType string, unit string, metric
 	}
 	return appMetrics
 }
+
+// withObservedValue returns a copy of the trigger as it is sent to the scaling engine after the latest metric value breached it.
+// The evaluation is left out, as it is verified by verifyTriggerAlarm.
+func withObservedValue(trigger models.Trigger, value int64) models.Trigger {
+	trigger.ObservedValue = &value
+	trigger.Evaluation = nil
+	return trigger
+}
+
+// verifyTriggerAlarm verifies that the trigger alarm is sent for the expected trigger, together with
+// the evaluation of the rules that ends with the breached one.
+func verifyTriggerAlarm(expected models.Trigger) http.HandlerFunc {
+	return func(_ http.ResponseWriter, req *http.Request) {
+		trigger := models.Trigger{}
+		Expect(json.NewDecoder(req.Body).Decode(&trigger)).To(Succeed())
+		Expect(trigger.Evaluation).NotTo(BeEmpty())
+		Expect(trigger.Evaluation[len(trigger.Evaluation)-1].Breached).To(BeTrue())
+		trigger.Evaluation = nil
+		Expect(trigger).To(Equal(expected))
+	}
+}
@@ -4,75 +4,305 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/metric"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
 	"code.cloudfoundry.org/lager/v3"
 )
 
+// Per-instance metric histories are read from log-cache on request, which only holds the recent past of an app.
+// The window of a request ends at its end time, or now, and is limited to maxMetricHistoriesWindow.
+const (
+	defaultMetricHistoriesWindow = 5 * time.Minute
+	maxMetricHistoriesWindow     = 30 * time.Minute
+)
+
 type EventGenHandler struct {
-	logger         lager.Logger
-	queryAppMetric aggregator.QueryAppMetricsFunc
+	logger             lager.Logger
+	queryAppMetric     aggregator.QueryAppMetricsFunc
+	queryAppMetricPage aggregator.QueryAppMetricsPageFunc
+	metricFetcher      metric.Fetcher
+	getEvaluationState generator.GetEvaluationStateFunc
 }
 
-func NewEventGenHandler(logger lager.Logger, queryAppMetric aggregator.QueryAppMetricsFunc) *EventGenHandler {
+func NewEventGenHandler(logger lager.Logger, queryAppMetric aggregator.QueryAppMetricsFunc, queryAppMetricPage aggregator.QueryAppMetricsPageFunc, metricFetcher metric.Fetcher, getEvaluationState generator.GetEvaluationStateFunc) *EventGenHandler {
 	return &EventGenHandler{
-		logger:         logger,
-		queryAppMetric: queryAppMetric,
+		logger:             logger,
+		queryAppMetric:     queryAppMetric,
+		queryAppMetricPage: queryAppMetricPage,
+		metricFetcher:      metricFetcher,
+		getEvaluationState: getEvaluationState,
+	}
+}
+
+// GetEvaluationState returns the circuit breaker and the latest rule evaluations of an app. As they are kept in
+// memory, only the instance which evaluates the app knows them, the others respond with 404.
+func (h *EventGenHandler) GetEvaluationState(w http.ResponseWriter, _ *http.Request, vars map[string]string) {
+	appID := vars["appid"]
+	h.logger.Debug("get-evaluation-state", lager.Data{"appid": appID})
+
+	state, found := h.getEvaluationState(appID)
+	if !found {
+		handlers.WriteJSONResponse(w, http.StatusNotFound, models.ErrorResponse{
+			Code:    "Not-Found",
+			Message: "App is not evaluated by this instance"})
+		return
 	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, state)
 }
 
+// GetAggregatedMetricHistories returns the aggregated metrics of an app in a time range. If a step is given, the
+// metrics are downsampled into buckets of the step with the avg, min or max of the aggregation parameter. If a limit
+// is given, a page of at most that many metrics is returned together with the cursor of the next page. Only the
+// metrics of the page and of the first metric or bucket after it are queried, to know whether there is a next page.
 func (h *EventGenHandler) GetAggregatedMetricHistories(w http.ResponseWriter, r *http.Request, vars map[string]string) {
 	appID := vars["appid"]
 	metricType := vars["metrictype"]
+
+	h.logger.Debug("get-aggregated-metric-histories", lager.Data{"appid": appID, "metrictype": metricType, "query": r.URL.Query()})
+
+	start, end, order, ok := h.parseHistoryQuery(w, r, "get-aggregated-metric-histories")
+	if !ok {
+		return
+	}
+	step, aggregation, limit, cursor, errMessage := parseDownsamplingQuery(r)
+	if cursor != nil && (order == db.ASC) != (cursor.Start > 0) {
+		errMessage = "Incorrect cursor parameter in query string, the cursor belongs to the other order"
+	}
+	if errMessage != "" {
+		h.logger.Info("get-aggregated-metric-histories-parse-downsampling", lager.Data{"query": r.URL.Query(), "error": errMessage})
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: errMessage})
+		return
+	}
+	if cursor != nil {
+		if order == db.ASC && cursor.Start > start {
+			start = cursor.Start
+		}
+		if order == db.DESC && (end < 0 || cursor.End < end) {
+			end = cursor.End
+		}
+	}
+
+	var mtrcs []*models.AppMetric
+	var err error
+	if limit > 0 {
+		mtrcs, err = h.queryAppMetricPage(appID, metricType, start, end, order, step, limit+1)
+	} else {
+		mtrcs, err = h.queryAppMetric(appID, metricType, start, end, order)
+	}
+	if err != nil {
+		h.logger.Error("get-aggregated-metric-histories-retrieve-metrics", err, lager.Data{"appid": appID, "metrictype": metricType, "start": start, "end": end, "order": order, "limit": limit})
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting aggregated metric histories"})
+		return
+	}
+
+	if step > 0 {
+		if order == db.DESC {
+			slices.Reverse(mtrcs)
+			mtrcs = downsampleAppMetrics(mtrcs, step, aggregation)
+			slices.Reverse(mtrcs)
+		} else {
+			mtrcs = downsampleAppMetrics(mtrcs, step, aggregation)
+		}
+	}
+
+	if limit > 0 {
+		page := models.AppMetricPage{Resources: mtrcs}
+		if len(mtrcs) > limit {
+			page.Resources = mtrcs[:limit]
+			page.NextCursor = encodeMetricsCursor(nextMetricsCursor(mtrcs[limit-1], order, step))
+		}
+		if page.Resources == nil {
+			page.Resources = []*models.AppMetric{}
+		}
+		handlers.WriteJSONResponse(w, http.StatusOK, page)
+		return
+	}
+
+	body, err := json.Marshal(mtrcs)
+	if err != nil {
+		h.logger.Error("get-aggregated-metric-histories-marshal", err, lager.Data{"appid": appID, "metrictype": metricType, "metrics": mtrcs})
+
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error marshaling aggregated metric histories"})
+		return
+	}
+	_, err = w.Write(body) // #nosec G705 -- JSON-marshaled response, not rendered as HTML
+	if err != nil {
+		h.logger.Error("unable to write body", err)
+	}
+}
+
+// GetMetricHistories returns the metrics of the instances of an app, optionally of a single instance given by the
+// instance_index parameter, ordered by their timestamp and instance index.
+func (h *EventGenHandler) GetMetricHistories(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appID := vars["appid"]
+	metricType := vars["metrictype"]
+
+	h.logger.Debug("get-metric-histories", lager.Data{"appid": appID, "metrictype": metricType, "query": r.URL.Query()})
+
+	start, end, order, ok := h.parseHistoryQuery(w, r, "get-metric-histories")
+	if !ok {
+		return
+	}
+
+	instanceIndex := int64(-1)
+	if instanceIndexParam := r.URL.Query()["instance_index"]; len(instanceIndexParam) > 0 {
+		var err error
+		instanceIndex, err = strconv.ParseInt(instanceIndexParam[0], 10, 64)
+		if len(instanceIndexParam) > 1 || err != nil || instanceIndex < 0 {
+			h.logger.Error("get-metric-histories-parse-instance-index", err, lager.Data{"instance_index": instanceIndexParam})
+			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+				Code:    "Bad-Request",
+				Message: "Incorrect instance_index parameter in query string"})
+			return
+		}
+	}
+
+	endTime := time.Now()
+	if end >= 0 && end < endTime.UnixNano() {
+		endTime = time.Unix(0, end)
+	}
+	startTime := endTime.Add(-defaultMetricHistoriesWindow)
+	if start > 0 {
+		startTime = time.Unix(0, start)
+	}
+	if endTime.Sub(startTime) > maxMetricHistoriesWindow {
+		startTime = endTime.Add(-maxMetricHistoriesWindow)
+	}
+
+	fetchedMetrics, err := h.metricFetcher.FetchMetrics(appID, metricType, startTime, endTime)
+	if err != nil {
+		h.logger.Error("get-metric-histories-fetch-metrics", err, lager.Data{"appid": appID, "metrictype": metricType, "start": startTime, "end": endTime})
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting metric histories"})
+		return
+	}
+
+	mtrcs := []models.AppInstanceMetric{}
+	for _, m := range fetchedMetrics {
+		if instanceIndex >= 0 && m.InstanceIndex != uint64(instanceIndex) {
+			continue
+		}
+		if m.Timestamp < startTime.UnixNano() || m.Timestamp > endTime.UnixNano() {
+			continue
+		}
+		mtrcs = append(mtrcs, m)
+	}
+	sort.SliceStable(mtrcs, func(i, j int) bool {
+		if mtrcs[i].Timestamp != mtrcs[j].Timestamp {
+			if order == db.DESC {
+				return mtrcs[i].Timestamp > mtrcs[j].Timestamp
+			}
+			return mtrcs[i].Timestamp < mtrcs[j].Timestamp
+		}
+		return mtrcs[i].InstanceIndex < mtrcs[j].InstanceIndex
+	})
+
+	handlers.WriteJSONResponse(w, http.StatusOK, mtrcs)
+}
+
+// parseDownsamplingQuery parses the step in seconds, the aggregation, the page limit and the cursor of an aggregated
+// metric histories request. It returns a message describing the first invalid parameter.
+func parseDownsamplingQuery(r *http.Request) (step time.Duration, aggregation string, limit int, cursor *metricsCursor, errMessage string) {
+	query := r.URL.Query()
+
+	if stepParam := query.Get("step"); stepParam != "" {
+		stepSecs, err := strconv.ParseInt(stepParam, 10, 64)
+		if err != nil || stepSecs <= 0 {
+			return 0, "", 0, nil, "Incorrect step parameter in query string, the value must be a positive number of seconds"
+		}
+		step = time.Duration(stepSecs) * time.Second
+	}
+
+	aggregation = strings.ToLower(query.Get("aggregation"))
+	switch aggregation {
+	case "":
+		aggregation = AggregationAvg
+	case AggregationAvg, AggregationMin, AggregationMax:
+	default:
+		return 0, "", 0, nil, fmt.Sprintf("Incorrect aggregation parameter in query string, the value can only be %s, %s or %s", AggregationAvg, AggregationMin, AggregationMax)
+	}
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > maxMetricHistoriesLimit {
+			return 0, "", 0, nil, fmt.Sprintf("Incorrect limit parameter in query string, the value must be between 1 and %d", maxMetricHistoriesLimit)
+		}
+	}
+
+	if cursorParam := query.Get("cursor"); cursorParam != "" {
+		decoded, err := decodeMetricsCursor(cursorParam)
+		if err != nil {
+			return 0, "", 0, nil, "Incorrect cursor parameter in query string"
+		}
+		cursor = &decoded
+	}
+	return step, aggregation, limit, cursor, ""
+}
+
+// parseHistoryQuery parses the start and end time in nanoseconds and the order of a history request. If they are
+// invalid, it writes a bad request response and returns false.
+func (h *EventGenHandler) parseHistoryQuery(w http.ResponseWriter, r *http.Request, logPrefix string) (start int64, end int64, order db.OrderType, ok bool) {
 	startParam := r.URL.Query()["start"]
 	endParam := r.URL.Query()["end"]
 	orderParam := r.URL.Query()["order"]
 
-	h.logger.Debug("get-aggregated-metric-histories", lager.Data{"appid": appID, "metrictype": metricType, "start": startParam, "end": endParam, "order": orderParam})
-
 	var err error
-	start := int64(0)
-	end := int64(-1)
-	order := db.ASC
+	start = int64(0)
+	end = int64(-1)
+	order = db.ASC
 
 	if len(startParam) == 1 {
 		start, err = strconv.ParseInt(startParam[0], 10, 64)
 		if err != nil {
-			h.logger.Error("get-aggregated-metric-histories-parse-start-time", err, lager.Data{"start": startParam})
+			h.logger.Error(logPrefix+"-parse-start-time", err, lager.Data{"start": startParam})
 			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
 				Code:    "Bad-Request",
 				Message: "Error parsing start time"})
-			return
+			return 0, 0, 0, false
 		}
 	} else if len(startParam) > 1 {
-		h.logger.Error("get-aggregated-metric-histories-get-start-time", err, lager.Data{"start": startParam})
+		h.logger.Error(logPrefix+"-get-start-time", err, lager.Data{"start": startParam})
 		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
 			Code:    "Bad-Request",
 			Message: "Incorrect start parameter in query string"})
-		return
+		return 0, 0, 0, false
 	}
 
 	if len(endParam) == 1 {
 		end, err = strconv.ParseInt(endParam[0], 10, 64)
 		if err != nil {
-			h.logger.Error("get-aggregated-metric-histories-parse-end-time", err, lager.Data{"end": endParam})
+			h.logger.Error(logPrefix+"-parse-end-time", err, lager.Data{"end": endParam})
 			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
 				Code:    "Bad-Request",
 				Message: "Error parsing end time"})
-			return
+			return 0, 0, 0, false
 		}
 	} else if len(endParam) > 1 {
-		h.logger.Error("get-aggregated-metric-histories-get-end-time", err, lager.Data{"end": endParam})
+		h.logger.Error(logPrefix+"-get-end-time", err, lager.Data{"end": endParam})
 		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
 			Code:    "Bad-Request",
 			Message: "Incorrect end parameter in query string"})
-		return
+		return 0, 0, 0, false
 	}
 
 	if len(orderParam) == 1 {
@@ -83,44 +313,19 @@ func (h *EventGenHandler) GetAggregatedMetricHistories(w http.ResponseWriter, r
 		case db.ASCSTR:
 			order = db.ASC
 		default:
-			h.logger.Error("get-aggregated-metric-histories-parse-order", err, lager.Data{"order": orderParam})
+			h.logger.Error(logPrefix+"-parse-order", err, lager.Data{"order": orderParam})
 			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
 				Code:    "Bad-Request",
 				Message: fmt.Sprintf("Incorrect order parameter in query string, the value can only be %s or %s", db.ASCSTR, db.DESCSTR),
 			})
-			return
+			return 0, 0, 0, false
 		}
 	} else if len(orderParam) > 1 {
-		h.logger.Error("get-aggregated-metric-histories-parse-order", err, lager.Data{"order": orderParam})
+		h.logger.Error(logPrefix+"-parse-order", err, lager.Data{"order": orderParam})
 		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
 			Code:    "Bad-Request",
 			Message: "Incorrect order parameter in query string"})
-		return
-	}
-
-	var mtrcs []*models.AppMetric
-
-	mtrcs, err = h.queryAppMetric(appID, metricType, start, end, order)
-	if err != nil {
-		h.logger.Error("get-aggregated-metric-histories-retrieve-metrics", err, lager.Data{"appid": appID, "metrictype": metricType, "start": start, "end": end, "order": order})
-		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
-			Code:    "Internal-Server-Error",
-			Message: "Error getting aggregated metric histories"})
-		return
-	}
-
-	var body []byte
-	body, err = json.Marshal(mtrcs)
-	if err != nil {
-		h.logger.Error("get-aggregated-metric-histories-marshal", err, lager.Data{"appid": appID, "metrictype": metricType, "metrics": mtrcs})
-
-		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
-			Code:    "Internal-Server-Error",
-			Message: "Error marshaling aggregated metric histories"})
-		return
-	}
-	_, err = w.Write(body) // #nosec G705 -- JSON-marshaled response, not rendered as HTML
-	if err != nil {
-		h.logger.Error("unable to write body", err)
+		return 0, 0, 0, false
 	}
+	return start, end, order, true
 }
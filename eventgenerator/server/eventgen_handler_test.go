@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/server"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"code.cloudfoundry.org/lager/v3"
@@ -18,11 +21,13 @@ import (
 )
 
 var testUrlAggregatedMetricHistories = "http://localhost/v1/apps/an-app-id/aggregated_metric_histories/a-metric-type"
+var testUrlMetricHistories = "http://localhost/v1/apps/an-app-id/metric_histories/a-metric-type"
 
 var _ = Describe("EventgenHandler", func() {
 	var (
-		handler         *EventGenHandler
-		queryAppMetrics aggregator.QueryAppMetricsFunc
+		handler            *EventGenHandler
+		queryAppMetrics    aggregator.QueryAppMetricsFunc
+		queryAppMetricPage aggregator.QueryAppMetricsPageFunc
 
 		resp       *httptest.ResponseRecorder
 		req        *http.Request
@@ -40,7 +45,7 @@ var _ = Describe("EventgenHandler", func() {
 		JustBeforeEach(func() {
 			logger = lager.NewLogger("handler-test")
 			resp = httptest.NewRecorder()
-			handler = NewEventGenHandler(logger, queryAppMetrics)
+			handler = NewEventGenHandler(logger, queryAppMetrics, queryAppMetricPage, &fakes.FakeFetcher{}, nil)
 			handler.GetAggregatedMetricHistories(resp, req, map[string]string{"appid": "an-app-id", "metrictype": "a-metric-type"})
 		})
 
@@ -297,6 +302,345 @@ var _ = Describe("EventgenHandler", func() {
 				})
 			})
 
+			Context("when downsampling or paginating", func() {
+				var (
+					bucketStart int64
+					pageStep    time.Duration
+					pageLimit   int
+				)
+
+				newMetric := func(timestamp int64, value string) *models.AppMetric {
+					return &models.AppMetric{AppId: "an-app-id", MetricType: "a-metric-type", Unit: "ms", Value: value, Timestamp: timestamp}
+				}
+
+				BeforeEach(func() {
+					bucketStart = (10 * time.Minute).Nanoseconds()
+					req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories, nil)
+					Expect(err).ToNot(HaveOccurred())
+					queryAppMetrics = func(appID string, metricType string, startTime int64, endTime int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+						start = startTime
+						end = endTime
+						mtrcs := []*models.AppMetric{
+							newMetric(bucketStart, "10"),
+							newMetric(bucketStart+(20*time.Second).Nanoseconds(), "15"),
+							newMetric(bucketStart+(40*time.Second).Nanoseconds(), "30"),
+							newMetric(bucketStart+(70*time.Second).Nanoseconds(), "8"),
+							newMetric(bucketStart+(130*time.Second).Nanoseconds(), "4"),
+						}
+						if orderType == db.DESC {
+							slices.Reverse(mtrcs)
+						}
+						return mtrcs, nil
+					}
+					pageStep, pageLimit = 0, 0
+					queryAppMetricPage = func(appID string, metricType string, startTime int64, endTime int64, orderType db.OrderType, step time.Duration, limit int) ([]*models.AppMetric, error) {
+						pageStep = step
+						pageLimit = limit
+						mtrcs, err := queryAppMetrics(appID, metricType, startTime, endTime, orderType)
+						if step == 0 {
+							mtrcs = mtrcs[:min(len(mtrcs), limit)]
+						}
+						return mtrcs, err
+					}
+				})
+
+				readMetrics := func() []*models.AppMetric {
+					mtrcs := []*models.AppMetric{}
+					Expect(json.Unmarshal(resp.Body.Bytes(), &mtrcs)).To(Succeed())
+					return mtrcs
+				}
+
+				readPage := func() *models.AppMetricPage {
+					page := &models.AppMetricPage{}
+					Expect(json.Unmarshal(resp.Body.Bytes(), page)).To(Succeed())
+					return page
+				}
+
+				Context("when a step is given", func() {
+					BeforeEach(func() {
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?step=60", nil)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("returns the rounded up average of each bucket", func() {
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						Expect(readMetrics()).To(Equal([]*models.AppMetric{
+							newMetric(bucketStart, "19"),
+							newMetric(bucketStart+time.Minute.Nanoseconds(), "8"),
+							newMetric(bucketStart+(2*time.Minute).Nanoseconds(), "4"),
+						}))
+					})
+				})
+
+				Context("when a step and the max aggregation are given in descending order", func() {
+					BeforeEach(func() {
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?step=120&aggregation=max&order=desc", nil)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("returns the maximum of each bucket in descending order", func() {
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						Expect(readMetrics()).To(Equal([]*models.AppMetric{
+							newMetric(bucketStart+(2*time.Minute).Nanoseconds(), "4"),
+							newMetric(bucketStart, "30"),
+						}))
+					})
+				})
+
+				Context("when the min aggregation is given", func() {
+					BeforeEach(func() {
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?step=60&aggregation=MIN", nil)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("returns the minimum of each bucket", func() {
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						mtrcs := readMetrics()
+						Expect(mtrcs).To(HaveLen(3))
+						Expect(mtrcs[0].Value).To(Equal("10"))
+					})
+				})
+
+				Context("when a limit is given", func() {
+					BeforeEach(func() {
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?step=60&limit=2", nil)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("returns a page with the cursor after the last bucket", func() {
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						By("querying the buckets of the page and one more")
+						Expect(pageStep).To(Equal(time.Minute))
+						Expect(pageLimit).To(Equal(3))
+
+						page := readPage()
+						Expect(page.Resources).To(HaveLen(2))
+						Expect(page.NextCursor).NotTo(BeEmpty())
+
+						By("following the cursor")
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?step=60&limit=2&cursor="+page.NextCursor, nil)
+						Expect(err).ToNot(HaveOccurred())
+						resp = httptest.NewRecorder()
+						handler.GetAggregatedMetricHistories(resp, req, map[string]string{"appid": "an-app-id", "metrictype": "a-metric-type"})
+
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						Expect(start).To(Equal(bucketStart + (2 * time.Minute).Nanoseconds()))
+					})
+				})
+
+				Context("when the limit covers all metrics", func() {
+					BeforeEach(func() {
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?limit=5", nil)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("returns a page without a cursor", func() {
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						page := readPage()
+						Expect(page.Resources).To(HaveLen(5))
+						Expect(page.NextCursor).To(BeEmpty())
+					})
+				})
+
+				Context("when a limit is given in descending order", func() {
+					BeforeEach(func() {
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?order=desc&limit=2", nil)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("continues before the last metric of the page", func() {
+						page := readPage()
+						Expect(page.Resources).To(HaveLen(2))
+						Expect(page.Resources[0].Timestamp).To(BeNumerically(">", page.Resources[1].Timestamp))
+						Expect(pageLimit).To(Equal(3))
+
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?order=desc&limit=2&cursor="+page.NextCursor, nil)
+						Expect(err).ToNot(HaveOccurred())
+						resp = httptest.NewRecorder()
+						handler.GetAggregatedMetricHistories(resp, req, map[string]string{"appid": "an-app-id", "metrictype": "a-metric-type"})
+
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						Expect(end).To(Equal(page.Resources[1].Timestamp - 1))
+
+						By("rejecting the cursor in ascending order")
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?order=asc&limit=2&cursor="+page.NextCursor, nil)
+						Expect(err).ToNot(HaveOccurred())
+						resp = httptest.NewRecorder()
+						handler.GetAggregatedMetricHistories(resp, req, map[string]string{"appid": "an-app-id", "metrictype": "a-metric-type"})
+
+						Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					})
+				})
+
+				DescribeTable("when a parameter is invalid",
+					func(query string, message string) {
+						resp = httptest.NewRecorder()
+						req, err = http.NewRequest(http.MethodGet, testUrlAggregatedMetricHistories+"?"+query, nil)
+						Expect(err).ToNot(HaveOccurred())
+						handler.GetAggregatedMetricHistories(resp, req, map[string]string{"appid": "an-app-id", "metrictype": "a-metric-type"})
+
+						Expect(resp.Code).To(Equal(http.StatusBadRequest))
+						errJson := &models.ErrorResponse{}
+						Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+						Expect(errJson).To(Equal(&models.ErrorResponse{Code: "Bad-Request", Message: message}))
+					},
+					Entry("step is not a number", "step=abc", "Incorrect step parameter in query string, the value must be a positive number of seconds"),
+					Entry("step is zero", "step=0", "Incorrect step parameter in query string, the value must be a positive number of seconds"),
+					Entry("aggregation is unknown", "aggregation=median", "Incorrect aggregation parameter in query string, the value can only be avg, min or max"),
+					Entry("limit is too large", "limit=1001", "Incorrect limit parameter in query string, the value must be between 1 and 1000"),
+					Entry("cursor is malformed", "cursor=not-a-cursor", "Incorrect cursor parameter in query string"),
+				)
+			})
+		})
+	})
+
+	Describe("GetMetricHistories", func() {
+		var (
+			metricFetcher *fakes.FakeFetcher
+			now           int64
+		)
+
+		BeforeEach(func() {
+			now = time.Now().UnixNano()
+			metricFetcher = &fakes.FakeFetcher{}
+			metricFetcher.FetchMetricsReturns([]models.AppInstanceMetric{
+				{AppId: "an-app-id", InstanceIndex: 1, Name: "a-metric-type", Value: "20", Timestamp: now - int64(2*time.Minute)},
+				{AppId: "an-app-id", InstanceIndex: 0, Name: "a-metric-type", Value: "10", Timestamp: now - int64(2*time.Minute)},
+				{AppId: "an-app-id", InstanceIndex: 0, Name: "a-metric-type", Value: "30", Timestamp: now - int64(time.Minute)},
+			}, nil)
+			req, err = http.NewRequest(http.MethodGet, testUrlMetricHistories, nil)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			logger = lager.NewLogger("handler-test")
+			resp = httptest.NewRecorder()
+			handler = NewEventGenHandler(logger, queryAppMetrics, nil, metricFetcher, nil)
+			handler.GetMetricHistories(resp, req, map[string]string{"appid": "an-app-id", "metrictype": "a-metric-type"})
+		})
+
+		It("returns the metrics of the last minutes of all instances ordered by timestamp and instance index", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+
+			var mtrcs []models.AppInstanceMetric
+			Expect(json.Unmarshal(resp.Body.Bytes(), &mtrcs)).To(Succeed())
+			Expect(mtrcs).To(HaveLen(3))
+			Expect([]string{mtrcs[0].Value, mtrcs[1].Value, mtrcs[2].Value}).To(Equal([]string{"10", "20", "30"}))
+
+			Expect(metricFetcher.FetchMetricsCallCount()).To(Equal(1))
+			appId, metricType, startTime, endTime := metricFetcher.FetchMetricsArgsForCall(0)
+			Expect(appId).To(Equal("an-app-id"))
+			Expect(metricType).To(Equal("a-metric-type"))
+			Expect(endTime.Sub(startTime)).To(Equal(5 * time.Minute))
+		})
+
+		Context("when an instance index and the order are given", func() {
+			BeforeEach(func() {
+				req, err = http.NewRequest(http.MethodGet, testUrlMetricHistories+"?instance_index=0&order=desc", nil)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the metrics of that instance in that order", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+
+				var mtrcs []models.AppInstanceMetric
+				Expect(json.Unmarshal(resp.Body.Bytes(), &mtrcs)).To(Succeed())
+				Expect([]string{mtrcs[0].Value, mtrcs[1].Value}).To(Equal([]string{"30", "10"}))
+			})
+		})
+
+		Context("when the window is longer than the maximum", func() {
+			BeforeEach(func() {
+				req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("%s?start=1&end=%d", testUrlMetricHistories, now), nil)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("fetches the metrics of the maximum window before the end", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, _, startTime, endTime := metricFetcher.FetchMetricsArgsForCall(0)
+				Expect(endTime.UnixNano()).To(Equal(now))
+				Expect(endTime.Sub(startTime)).To(Equal(30 * time.Minute))
+			})
+		})
+
+		Context("when the instance index is invalid", func() {
+			BeforeEach(func() {
+				req, err = http.NewRequest(http.MethodGet, testUrlMetricHistories+"?instance_index=-1", nil)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("Incorrect instance_index parameter in query string"))
+				Expect(metricFetcher.FetchMetricsCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when fetching the metrics fails", func() {
+			BeforeEach(func() {
+				metricFetcher.FetchMetricsReturns(nil, errors.New("an error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting metric histories",
+				}))
+			})
+		})
+	})
+
+	Describe("GetEvaluationState", func() {
+		var (
+			state     *models.AppEvaluationState
+			evaluated bool
+		)
+
+		BeforeEach(func() {
+			ruleIndex := 0
+			state = &models.AppEvaluationState{
+				AppId:   "an-app-id",
+				Breaker: &models.BreakerState{Tripped: true, ConsecutiveFailures: 3},
+				Rules: []models.RuleEvaluationState{{
+					RuleEvaluation: models.RuleEvaluation{RuleIndex: &ruleIndex, MetricType: "a-metric-type", Threshold: 80, Operator: ">", Adjustment: "+1", Samples: []models.MetricSample{{Timestamp: 100, Value: "90", Unit: "%"}}, Breached: true},
+					EvaluatedAt:    200,
+				}},
+			}
+			evaluated = true
+		})
+
+		JustBeforeEach(func() {
+			resp = httptest.NewRecorder()
+			getEvaluationState := func(appID string) (*models.AppEvaluationState, bool) {
+				Expect(appID).To(Equal("an-app-id"))
+				return state, evaluated
+			}
+			handler = NewEventGenHandler(lager.NewLogger("handler-test"), queryAppMetrics, nil, &fakes.FakeFetcher{}, getEvaluationState)
+			req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/evaluation_state", nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetEvaluationState(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		It("returns the evaluation state of the app", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			actual := &models.AppEvaluationState{}
+			Expect(json.Unmarshal(resp.Body.Bytes(), actual)).To(Succeed())
+			Expect(actual).To(Equal(state))
+		})
+
+		Context("when the app is not evaluated by this instance", func() {
+			BeforeEach(func() {
+				evaluated = false
+			})
+
+			It("returns 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Not-Found","message":"App is not evaluated by this instance"}`))
+			})
 		})
 	})
 })
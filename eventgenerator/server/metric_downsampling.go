@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+)
+
+const (
+	AggregationAvg = "avg"
+	AggregationMin = "min"
+	AggregationMax = "max"
+
+	maxMetricHistoriesLimit = 1000
+)
+
+// metricsCursor is the position at which the next page of aggregated metric histories starts. For ascending order
+// it is the start of the remaining time range and for descending order its end.
+type metricsCursor struct {
+	Start int64 `json:"start,omitempty"`
+	End   int64 `json:"end,omitempty"`
+}
+
+func encodeMetricsCursor(cursor metricsCursor) string {
+	cursorJson, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorJson)
+}
+
+func decodeMetricsCursor(encoded string) (metricsCursor, error) {
+	var cursor metricsCursor
+	cursorJson, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(cursorJson, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.Start < 0 || cursor.End < 0 || (cursor.Start == 0) == (cursor.End == 0) {
+		return cursor, fmt.Errorf("cursor must contain either a start or an end")
+	}
+	return cursor, nil
+}
+
+// nextMetricsCursor returns the cursor of the page after the last metric of a page. With downsampling, the timestamp
+// of a metric is the start of its bucket, so the next page continues after the whole bucket.
+func nextMetricsCursor(last *models.AppMetric, order db.OrderType, step time.Duration) metricsCursor {
+	if order == db.DESC {
+		return metricsCursor{End: last.Timestamp - 1}
+	}
+	if step > 0 {
+		return metricsCursor{Start: last.Timestamp + step.Nanoseconds()}
+	}
+	return metricsCursor{Start: last.Timestamp + 1}
+}
+
+// downsampleAppMetrics combines the metrics, which are ordered by their timestamp, into one metric per bucket of the
+// step. Buckets are aligned to multiples of the step since the epoch and are timestamped with their start. Metrics
+// without a value are left out, as are buckets without any value.
+func downsampleAppMetrics(metrics []*models.AppMetric, step time.Duration, aggregation string) []*models.AppMetric {
+	stepNanos := step.Nanoseconds()
+	result := []*models.AppMetric{}
+
+	var bucket *models.AppMetric
+	var count, sum, minValue, maxValue int64
+	flush := func() {
+		if bucket == nil || count == 0 {
+			return
+		}
+		var value int64
+		switch aggregation {
+		case AggregationMin:
+			value = minValue
+		case AggregationMax:
+			value = maxValue
+		default:
+			value = int64(math.Ceil(float64(sum) / float64(count)))
+		}
+		bucket.Value = strconv.FormatInt(value, 10)
+		result = append(result, bucket)
+	}
+
+	for _, metric := range metrics {
+		value, err := strconv.ParseInt(metric.Value, 10, 64)
+		if err != nil {
+			continue
+		}
+		bucketStart := metric.Timestamp - metric.Timestamp%stepNanos
+		if bucket == nil || bucket.Timestamp != bucketStart {
+			flush()
+			bucket = &models.AppMetric{AppId: metric.AppId, MetricType: metric.MetricType, Unit: metric.Unit, Timestamp: bucketStart}
+			count, sum, minValue, maxValue = 0, 0, value, value
+		}
+		count++
+		sum += value
+		minValue = min(minValue, value)
+		maxValue = max(maxValue, value)
+	}
+	flush()
+	return result
+}
@@ -7,6 +7,8 @@ import (
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/metric"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/auth"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
@@ -33,7 +35,7 @@ func Liveness(w http.ResponseWriter, r *http.Request, vars map[string]string) {
 }
 
 func (s *Server) createEventGeneratorRoutes() *mux.Router {
-	eh := NewEventGenHandler(s.logger, s.queryAppMetric)
+	eh := NewEventGenHandler(s.logger, s.queryAppMetric, s.queryAppMetricPage, s.metricFetcher, s.getEvaluationState)
 
 	r := s.autoscalerRouter.CreateEventGeneratorSubrouter()
 	r.Use(otelmux.Middleware("eventgenerator"))
@@ -41,6 +43,8 @@ func (s *Server) createEventGeneratorRoutes() *mux.Router {
 
 	r.Get(routes.LivenessRouteName).Handler(VarsFunc(Liveness))
 	r.Get(routes.GetAggregatedMetricHistoriesRouteName).Handler(VarsFunc(eh.GetAggregatedMetricHistories))
+	r.Get(routes.GetMetricHistoriesRouteName).Handler(VarsFunc(eh.GetMetricHistories))
+	r.Get(routes.GetEvaluationStateRouteName).Handler(VarsFunc(eh.GetEvaluationState))
 
 	return r
 }
@@ -51,13 +55,16 @@ type Server struct {
 	appMetricDB         db.AppMetricDB
 	policyDb            db.PolicyDB
 	queryAppMetric      aggregator.QueryAppMetricsFunc
+	queryAppMetricPage  aggregator.QueryAppMetricsPageFunc
+	metricFetcher       metric.Fetcher
+	getEvaluationState  generator.GetEvaluationStateFunc
 	httpStatusCollector healthendpoint.HTTPStatusCollector
 
 	autoscalerRouter *routes.Router
 	healthRouter     *mux.Router
 }
 
-func NewServer(logger lager.Logger, conf *config.Config, appMetricDB db.AppMetricDB, policyDb db.PolicyDB, queryAppMetric aggregator.QueryAppMetricsFunc, httpStatusCollector healthendpoint.HTTPStatusCollector) *Server {
+func NewServer(logger lager.Logger, conf *config.Config, appMetricDB db.AppMetricDB, policyDb db.PolicyDB, queryAppMetric aggregator.QueryAppMetricsFunc, queryAppMetricPage aggregator.QueryAppMetricsPageFunc, metricFetcher metric.Fetcher, getEvaluationState generator.GetEvaluationStateFunc, httpStatusCollector healthendpoint.HTTPStatusCollector) *Server {
 	return &Server{
 		logger:              logger,
 		conf:                conf,
@@ -65,6 +72,9 @@ func NewServer(logger lager.Logger, conf *config.Config, appMetricDB db.AppMetri
 		policyDb:            policyDb,
 		autoscalerRouter:    routes.NewRouter(),
 		queryAppMetric:      queryAppMetric,
+		queryAppMetricPage:  queryAppMetricPage,
+		metricFetcher:       metricFetcher,
+		getEvaluationState:  getEvaluationState,
 		httpStatusCollector: httpStatusCollector,
 	}
 }
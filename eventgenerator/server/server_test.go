@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/configutil"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
@@ -35,8 +36,9 @@ var _ = Describe("Server", func() {
 		httpStatusCollector *fakes.FakeHTTPStatusCollector
 		xfccAuthMiddleware  *fakes.FakeXFCCAuthMiddleware
 
-		appMetricDB     *fakes.FakeAppMetricDB
-		queryAppMetrics aggregator.QueryAppMetricsFunc
+		appMetricDB        *fakes.FakeAppMetricDB
+		queryAppMetrics    aggregator.QueryAppMetricsFunc
+		queryAppMetricPage aggregator.QueryAppMetricsPageFunc
 	)
 
 	BeforeEach(func() {
@@ -66,12 +68,19 @@ var _ = Describe("Server", func() {
 		queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
 			return nil, nil
 		}
+		queryAppMetricPage = func(appID string, metricType string, start int64, end int64, orderType db.OrderType, step time.Duration, limit int) ([]*models.AppMetric, error) {
+			return nil, nil
+		}
 
 		httpStatusCollector = &fakes.FakeHTTPStatusCollector{}
 		policyDB = &fakes.FakePolicyDB{}
 		appMetricDB = &fakes.FakeAppMetricDB{}
 
-		server = NewServer(lager.NewLogger("test"), conf, appMetricDB, policyDB, queryAppMetrics, httpStatusCollector)
+		getEvaluationState := func(appID string) (*models.AppEvaluationState, bool) {
+			return &models.AppEvaluationState{AppId: appID, Rules: []models.RuleEvaluationState{}}, true
+		}
+
+		server = NewServer(lager.NewLogger("test"), conf, appMetricDB, policyDB, queryAppMetrics, queryAppMetricPage, &fakes.FakeFetcher{}, getEvaluationState, httpStatusCollector)
 	})
 
 	AfterEach(func() {
@@ -105,6 +114,38 @@ var _ = Describe("Server", func() {
 			})
 		})
 
+		Describe("request on /v1/apps/an-app-id/metric_histories/a-metric-type", func() {
+			BeforeEach(func() {
+				serverUrl.Path = "/v1/apps/an-app-id/metric_histories/a-metric-type"
+			})
+
+			JustBeforeEach(func() {
+				rsp, err = http.Get(serverUrl.String())
+			})
+
+			It("should return 200", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+				rsp.Body.Close()
+			})
+		})
+
+		Describe("request on /v1/apps/an-app-id/evaluation_state", func() {
+			BeforeEach(func() {
+				serverUrl.Path = "/v1/apps/an-app-id/evaluation_state"
+			})
+
+			JustBeforeEach(func() {
+				rsp, err = http.Get(serverUrl.String())
+			})
+
+			It("should return 200", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+				rsp.Body.Close()
+			})
+		})
+
 		When("requesting the wrong path", func() {
 			BeforeEach(func() {
 				serverUrl.Path = "/not-exist-path"
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	MediaTypeJSON   = "application/json"
+	MediaTypeCSV    = "text/csv"
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+type acceptHeaderKey struct{}
+
+// WithAcceptHeader makes the Accept header of requests available via AcceptHeaderFromContext
+// to handlers which only get the request context, e.g. the ones generated by ogen.
+func WithAcceptHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(ContextWithAcceptHeader(r.Context(), r.Header.Get("Accept"))))
+	})
+}
+
+func ContextWithAcceptHeader(ctx context.Context, accept string) context.Context {
+	return context.WithValue(ctx, acceptHeaderKey{}, accept)
+}
+
+func AcceptHeaderFromContext(ctx context.Context) string {
+	accept, _ := ctx.Value(acceptHeaderKey{}).(string)
+	return accept
+}
+
+// NegotiateMediaType returns the offered media type the client prefers according to the given Accept header.
+// More specific media ranges take precedence over wildcards with the same quality. The first offered
+// media type is the default, which is returned as well if none of them is acceptable.
+func NegotiateMediaType(accept string, offered ...string) string {
+	best := offered[0]
+	bestQuality, bestSpecificity := 0.0, -1
+	for _, acceptedRange := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(acceptedRange))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality <= 0 {
+				continue
+			}
+		}
+		for _, mediaType := range offered {
+			specificity := mediaRangeSpecificity(mediaRange, mediaType)
+			if specificity < 0 {
+				continue
+			}
+			if quality > bestQuality || (quality == bestQuality && specificity > bestSpecificity) {
+				best, bestQuality, bestSpecificity = mediaType, quality, specificity
+			}
+		}
+	}
+	return best
+}
+
+// mediaRangeSpecificity returns how specific the media range matches the media type, or -1 if it does not match.
+func mediaRangeSpecificity(mediaRange string, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	case mediaRange == "*/*":
+		return 0
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,42 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content negotiation", func() {
+	Describe("WithAcceptHeader", func() {
+		It("makes the Accept header available in the request context", func() {
+			var accept string
+			handler := WithAcceptHeader(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				accept = AcceptHeaderFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", "text/csv")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(accept).To(Equal("text/csv"))
+		})
+	})
+
+	DescribeTable("NegotiateMediaType",
+		func(accept string, expected string) {
+			Expect(NegotiateMediaType(accept, MediaTypeJSON, MediaTypeCSV, MediaTypeNDJSON)).To(Equal(expected))
+		},
+		Entry("defaults to the first offered media type without Accept header", "", MediaTypeJSON),
+		Entry("defaults to the first offered media type for any media type", "*/*", MediaTypeJSON),
+		Entry("selects the accepted media type", "text/csv", MediaTypeCSV),
+		Entry("ignores media type parameters", "application/x-ndjson; charset=utf-8", MediaTypeNDJSON),
+		Entry("selects the media type with the highest quality", "text/csv;q=0.5, application/x-ndjson;q=0.8", MediaTypeNDJSON),
+		Entry("prefers specific media types over wildcards", "*/*, text/csv", MediaTypeCSV),
+		Entry("matches wildcard subtypes", "text/*", MediaTypeCSV),
+		Entry("skips media types which are not acceptable", "text/csv;q=0, */*;q=0.1", MediaTypeJSON),
+		Entry("defaults to the first offered media type if none is acceptable", "application/xml", MediaTypeJSON),
+	)
+})
@@ -7,3 +7,10 @@ func FNVHash(key string) uint32 {
 	h.Write([]byte(key))
 	return h.Sum32()
 }
+
+// OwnerIndex returns the index of the instance of a pool of the given size which is responsible for the key, e.g. the
+// eventgenerator instance which evaluates an app.
+func OwnerIndex(key string, poolSize int) int {
+	// #nosec G115 -- pools will be in the range of a dozen instances max - no need to worry about integer overflow
+	return int(FNVHash(key) % uint32(poolSize))
+}
@@ -1,12 +1,44 @@
 package helpers
 
 import (
+	"context"
+	"net/http"
+
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
+// decisionIdKey is the baggage member carrying the id of a scaling decision from the eventgenerator,
+// which takes the decision, to the components acting on it.
+const decisionIdKey = "autoscaler-decision-id"
+
 func SetupOpenTelemetry() {
 	otel.SetTracerProvider(trace.NewTracerProvider())
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 }
+
+// ContextWithDecisionId adds the id of a scaling decision to the baggage of the context,
+// so that it is propagated together with the trace context of outgoing requests.
+func ContextWithDecisionId(ctx context.Context, decisionId string) context.Context {
+	member, err := baggage.NewMember(decisionIdKey, decisionId)
+	if err != nil {
+		return ctx
+	}
+	b, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, b)
+}
+
+// DecisionIdFromContext returns the id of the scaling decision a request belongs to, or "" if there is none.
+func DecisionIdFromContext(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(decisionIdKey).Value()
+}
+
+// InjectTraceContext adds the trace context and the baggage of the context to the headers of an outgoing request.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,39 @@
+package helpers_test
+
+import (
+	"context"
+	"net/http"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var _ = Describe("OpenTelemetry", func() {
+	BeforeEach(func() {
+		SetupOpenTelemetry()
+	})
+
+	Describe("DecisionIdFromContext", func() {
+		It("returns the decision id added to the context", func() {
+			ctx := ContextWithDecisionId(context.Background(), "a-decision-id")
+			Expect(DecisionIdFromContext(ctx)).To(Equal("a-decision-id"))
+		})
+
+		It("returns an empty id if the context has none", func() {
+			Expect(DecisionIdFromContext(context.Background())).To(BeEmpty())
+		})
+	})
+
+	Describe("InjectTraceContext", func() {
+		It("propagates the decision id with the request headers", func() {
+			header := http.Header{}
+			InjectTraceContext(ContextWithDecisionId(context.Background(), "a-decision-id"), header)
+			Expect(header.Get("baggage")).To(Equal("autoscaler-decision-id=a-decision-id"))
+
+			ctx := propagation.Baggage{}.Extract(context.Background(), propagation.HeaderCarrier(header))
+			Expect(DecisionIdFromContext(ctx)).To(Equal("a-decision-id"))
+		})
+	})
+})
@@ -0,0 +1,18 @@
+package models
+
+// ScalingAnalytics aggregates the scaling history of an app over a time range. The time at min
+// and at max refers to the instance limits of the app's current policy. The mean time between
+// actions is nil if there were fewer than two scale-outs or scale-ins in the time range.
+type ScalingAnalytics struct {
+	StartTime                 int64    `json:"start_time"`
+	EndTime                   int64    `json:"end_time"`
+	InstanceHours             float64  `json:"instance_hours"`
+	ScaleOuts                 int      `json:"scale_outs"`
+	ScaleIns                  int      `json:"scale_ins"`
+	Failures                  int      `json:"failures"`
+	InstanceMinCount          int      `json:"instance_min_count"`
+	InstanceMaxCount          int      `json:"instance_max_count"`
+	SecondsAtMin              float64  `json:"seconds_at_min"`
+	SecondsAtMax              float64  `json:"seconds_at_max"`
+	MeanSecondsBetweenActions *float64 `json:"mean_seconds_between_actions"`
+}
@@ -119,6 +119,14 @@ type AppMetricResponse struct {
 	PublicApiResponseBase
 	Resources []AppMetric `json:"resources"`
 }
+
+// AppMetricPage is a page of aggregated metric histories, which is continued with the next cursor if there are
+// more metrics.
+type AppMetricPage struct {
+	Resources  []*AppMetric `json:"resources"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+	NextUrl    string       `json:"next_url,omitempty"`
+}
 type AppScalingHistoryResponse struct {
 	PublicApiResponseBase
 	Resources []AppScalingHistory `json:"resources"`
@@ -31,12 +31,28 @@ type AppScalingHistory struct {
 	Reason       string        `json:"reason"`
 	Message      string        `json:"message"`
 	Error        string        `json:"error"`
+	Emergency    bool          `json:"emergency,omitempty"`
+
+	// The following fields describe the scaling in a structured way, while Reason is meant for humans.
+	// They are empty if they do not apply to the scaling type or were unknown at the time of the scaling.
+	RuleIndex         *int   `json:"rule_index,omitempty"`
+	MetricType        string `json:"metric_type,omitempty"`
+	ObservedValue     *int64 `json:"observed_value,omitempty"`
+	Threshold         *int64 `json:"threshold,omitempty"`
+	Operator          string `json:"operator,omitempty"`
+	Adjustment        string `json:"adjustment,omitempty"`
+	ScheduleId        string `json:"schedule_id,omitempty"`
+	CooldownExpiredAt int64  `json:"cooldown_expired_at,omitempty"`
+	CorrelationId     string `json:"correlation_id,omitempty"`
+	DecisionId        string `json:"decision_id,omitempty"`
 }
 
 type AppMonitor struct {
 	AppId      string
 	MetricType string
 	StatWindow time.Duration
+	// SourceAppId is the app whose metrics are fetched for the app, if it is not the app itself.
+	SourceAppId string
 }
 
 type AppScalingResult struct {
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// MaxAppGroupMembers is the maximum number of members of an app group, besides its leader.
+const MaxAppGroupMembers = 20
+
+var metricTypePattern = regexp.MustCompile(`^[a-zA-Z0-9_]{1,100}$`)
+
+// AppGroup is a group of apps which are scaled together. The leader of the group is the app of the leading metric:
+// when a scaling rule of its policy for the leading metric triggers a scaling, the leader is scaled according to its
+// policy, and all members are scaled to the instances which follow from the new instances of the leader.
+type AppGroup struct {
+	GroupId       string           `json:"group_id"`
+	LeadingMetric LeadingMetric    `json:"leading_metric"`
+	Members       []AppGroupMember `json:"members"`
+}
+
+// LeadingMetric is the metric of the leader of an app group which drives the scaling of the group.
+type LeadingMetric struct {
+	AppId      string `json:"app_id"`
+	MetricType string `json:"metric_type"`
+}
+
+// AppGroupMember is an app which follows the leader of its group. It has either a ratio, which is multiplied with the
+// instances of the leader, or its own instance limits, within which it gets the same instances as the leader.
+type AppGroupMember struct {
+	AppId       string   `json:"app_id"`
+	Ratio       *float64 `json:"ratio,omitempty"`
+	InstanceMin *int     `json:"instance_min_count,omitempty"`
+	InstanceMax *int     `json:"instance_max_count,omitempty"`
+}
+
+// LeaderAppId returns the id of the leader of the group.
+func (g *AppGroup) LeaderAppId() string {
+	return g.LeadingMetric.AppId
+}
+
+// AppIds returns the ids of all apps of the group, starting with the leader.
+func (g *AppGroup) AppIds() []string {
+	appIds := []string{g.LeaderAppId()}
+	for _, member := range g.Members {
+		appIds = append(appIds, member.AppId)
+	}
+	return appIds
+}
+
+// Validate checks that the group has a leading metric and between 1 and MaxAppGroupMembers distinct members besides
+// the leader, each of them with either a positive ratio or valid instance limits.
+func (g *AppGroup) Validate() error {
+	if g.LeadingMetric.AppId == "" {
+		return errors.New("leading_metric.app_id is required")
+	}
+	if !metricTypePattern.MatchString(g.LeadingMetric.MetricType) {
+		return errors.New("leading_metric.metric_type must consist of 1 to 100 letters, digits and underscores")
+	}
+	if len(g.Members) == 0 || len(g.Members) > MaxAppGroupMembers {
+		return fmt.Errorf("members must contain between 1 and %d apps", MaxAppGroupMembers)
+	}
+
+	seen := map[string]bool{g.LeadingMetric.AppId: true}
+	for i, member := range g.Members {
+		if member.AppId == "" {
+			return fmt.Errorf("members[%d].app_id is required", i)
+		}
+		if seen[member.AppId] {
+			return fmt.Errorf("members[%d]: app %s is contained in the group more than once", i, member.AppId)
+		}
+		seen[member.AppId] = true
+		if err := member.validate(); err != nil {
+			return fmt.Errorf("members[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (m AppGroupMember) validate() error {
+	hasLimits := m.InstanceMin != nil || m.InstanceMax != nil
+	switch {
+	case m.Ratio != nil && hasLimits:
+		return errors.New("either ratio or instance_min_count and instance_max_count must be given, not both")
+	case m.Ratio != nil:
+		if *m.Ratio <= 0 || math.IsInf(*m.Ratio, 0) {
+			return errors.New("ratio must be greater than 0")
+		}
+	case m.InstanceMin == nil || m.InstanceMax == nil:
+		return errors.New("either ratio or instance_min_count and instance_max_count must be given")
+	case *m.InstanceMin < 1 || *m.InstanceMax < *m.InstanceMin:
+		return errors.New("instance_min_count must be at least 1 and not greater than instance_max_count")
+	}
+	return nil
+}
+
+// Instances returns the instances of the member for the given instances of the leader: the instances of the leader
+// multiplied with the ratio and rounded up, or the instances of the leader limited to the instance limits of the
+// member. A member has at least one instance.
+func (m AppGroupMember) Instances(leaderInstances int) int {
+	instances := leaderInstances
+	if m.Ratio != nil {
+		instances = int(math.Ceil(float64(leaderInstances) * *m.Ratio))
+	} else if m.InstanceMin != nil && m.InstanceMax != nil {
+		instances = max(*m.InstanceMin, min(instances, *m.InstanceMax))
+	}
+	return max(instances, 1)
+}
+
+// GroupScalingStatus is the outcome of the scaling of an app group.
+type GroupScalingStatus string
+
+const (
+	// GroupScalingSucceeded means that all apps of the group have been scaled.
+	GroupScalingSucceeded GroupScalingStatus = "succeeded"
+	// GroupScalingFailed means that no app of the group has been scaled.
+	GroupScalingFailed GroupScalingStatus = "failed"
+	// GroupScalingRolledBack means that a member could not be scaled and that all apps which had been scaled already
+	// have been scaled back to their previous instances.
+	GroupScalingRolledBack GroupScalingStatus = "rolled_back"
+	// GroupScalingPartiallyFailed means that a member could not be scaled and that some apps which had been scaled
+	// already could not be scaled back, so that the group is not in its previous state.
+	GroupScalingPartiallyFailed GroupScalingStatus = "partially_failed"
+)
+
+// GroupMemberScalingStatus is the outcome of the scaling of an app within the scaling of its group.
+type GroupMemberScalingStatus string
+
+const (
+	GroupMemberScaled         GroupMemberScalingStatus = "scaled"
+	GroupMemberUnchanged      GroupMemberScalingStatus = "unchanged"
+	GroupMemberIgnored        GroupMemberScalingStatus = "ignored"
+	GroupMemberFailed         GroupMemberScalingStatus = "failed"
+	GroupMemberSkipped        GroupMemberScalingStatus = "skipped"
+	GroupMemberRolledBack     GroupMemberScalingStatus = "rolled_back"
+	GroupMemberRollbackFailed GroupMemberScalingStatus = "rollback_failed"
+)
+
+// GroupScalingHistory is the record of a scaling of an app group, with the outcome for each of its apps. The leader
+// is the first app.
+type GroupScalingHistory struct {
+	GroupId    string               `json:"group_id"`
+	Timestamp  int64                `json:"timestamp"`
+	MetricType string               `json:"metric_type"`
+	Reason     string               `json:"reason"`
+	Status     GroupScalingStatus   `json:"status"`
+	Apps       []GroupMemberScaling `json:"apps"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// GroupMemberScaling is the scaling of an app within the scaling of its group.
+type GroupMemberScaling struct {
+	AppId        string                   `json:"app_id"`
+	OldInstances int                      `json:"old_instances"`
+	NewInstances int                      `json:"new_instances"`
+	Status       GroupMemberScalingStatus `json:"status"`
+	Message      string                   `json:"message,omitempty"`
+	Error        string                   `json:"error,omitempty"`
+}
@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppGroup", func() {
+	var (
+		ratio       = 1.5
+		instanceMin = 2
+		instanceMax = 4
+	)
+
+	validGroup := func() *AppGroup {
+		return &AppGroup{
+			GroupId:       "a-group-id",
+			LeadingMetric: LeadingMetric{AppId: "leader-app-id", MetricType: "throughput"},
+			Members: []AppGroupMember{
+				{AppId: "ratio-app-id", Ratio: &ratio},
+				{AppId: "limited-app-id", InstanceMin: &instanceMin, InstanceMax: &instanceMax},
+			},
+		}
+	}
+
+	It("returns the ids of its apps, starting with the leader", func() {
+		Expect(validGroup().AppIds()).To(Equal([]string{"leader-app-id", "ratio-app-id", "limited-app-id"}))
+	})
+
+	DescribeTable("Validate",
+		func(modify func(group *AppGroup), expectedError string) {
+			group := validGroup()
+			modify(group)
+			err := group.Validate()
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expectedError))
+			}
+		},
+		Entry("a valid group", func(_ *AppGroup) {}, ""),
+		Entry("without a leader", func(group *AppGroup) { group.LeadingMetric.AppId = "" }, "leading_metric.app_id is required"),
+		Entry("with an invalid metric type", func(group *AppGroup) { group.LeadingMetric.MetricType = "a metric" },
+			"leading_metric.metric_type must consist of 1 to 100 letters, digits and underscores"),
+		Entry("without members", func(group *AppGroup) { group.Members = nil }, "members must contain between 1 and 20 apps"),
+		Entry("with the leader as member", func(group *AppGroup) { group.Members[1].AppId = "leader-app-id" },
+			"members[1]: app leader-app-id is contained in the group more than once"),
+		Entry("with a member with ratio and instance limits", func(group *AppGroup) { group.Members[0].InstanceMin = &instanceMin },
+			"members[0]: either ratio or instance_min_count and instance_max_count must be given, not both"),
+		Entry("with a member without ratio and instance limits", func(group *AppGroup) { group.Members[0].Ratio = nil },
+			"members[0]: either ratio or instance_min_count and instance_max_count must be given"),
+		Entry("with a member with a negative ratio", func(group *AppGroup) { negative := -1.0; group.Members[0].Ratio = &negative },
+			"members[0]: ratio must be greater than 0"),
+		Entry("with a member with a minimum above its maximum", func(group *AppGroup) { group.Members[1].InstanceMax = new(int) },
+			"members[1]: instance_min_count must be at least 1 and not greater than instance_max_count"),
+	)
+
+	DescribeTable("AppGroupMember.Instances",
+		func(member AppGroupMember, leaderInstances int, expectedInstances int) {
+			Expect(member.Instances(leaderInstances)).To(Equal(expectedInstances))
+		},
+		Entry("multiplies with the ratio and rounds up", AppGroupMember{Ratio: &ratio}, 3, 5),
+		Entry("has at least one instance", AppGroupMember{Ratio: new(float64)}, 3, 1),
+		Entry("follows the leader within the instance limits", AppGroupMember{InstanceMin: &instanceMin, InstanceMax: &instanceMax}, 3, 3),
+		Entry("is limited by the minimum", AppGroupMember{InstanceMin: &instanceMin, InstanceMax: &instanceMax}, 1, 2),
+		Entry("is limited by the maximum", AppGroupMember{InstanceMin: &instanceMin, InstanceMax: &instanceMax}, 6, 4),
+	)
+})
@@ -0,0 +1,150 @@
+package models
+
+import "strconv"
+
+// AppScalingState is what the scaling engine knows about the current scaling of an app.
+type AppScalingState struct {
+	AppId              string             `json:"app_id"`
+	AppState           string             `json:"app_state"`
+	Instances          int                `json:"instances"`
+	InstanceMin        int                `json:"instance_min_count"`
+	InstanceMax        int                `json:"instance_max_count"`
+	ActiveSchedule     *ActiveSchedule    `json:"active_schedule,omitempty"`
+	DisableAutoscaling *string            `json:"disable_autoscaling,omitempty"`
+	Cooldowns          []Cooldown         `json:"cooldowns"`
+	LastScaling        *AppScalingHistory `json:"last_scaling,omitempty"`
+}
+
+// Cooldown is a running cool-down of an app, which ignores scalings in its direction until it expires.
+type Cooldown struct {
+	Direction ScalingDirection `json:"direction"`
+	ExpiredAt int64            `json:"expired_at"`
+}
+
+// AppEvaluationState is the in-memory state of the eventgenerator for an app, which is only known to the
+// eventgenerator instance which evaluates the app.
+type AppEvaluationState struct {
+	AppId   string                `json:"app_id"`
+	Breaker *BreakerState         `json:"circuit_breaker,omitempty"`
+	Rules   []RuleEvaluationState `json:"rules"`
+}
+
+// BreakerState is the state of the circuit breaker which stops the eventgenerator from sending triggers of an app
+// to the scaling engine after consecutive failures.
+type BreakerState struct {
+	Tripped             bool  `json:"tripped"`
+	ConsecutiveFailures int64 `json:"consecutive_failures"`
+}
+
+// RuleEvaluationState is the last evaluation of a scaling rule, or of its emergency threshold.
+type RuleEvaluationState struct {
+	RuleEvaluation
+	EvaluatedAt int64 `json:"evaluated_at"`
+}
+
+// AppStatus is the current autoscaling state of an app, combined from its policy, the scaling engine and the
+// eventgenerator.
+type AppStatus struct {
+	AppId string `json:"app_id"`
+	// Instances is the number of instances of the app, InstanceMin and InstanceMax are the limits that apply now,
+	// which are those of the active schedule if there is one.
+	Instances      int             `json:"instances"`
+	InstanceMin    int             `json:"instance_min_count"`
+	InstanceMax    int             `json:"instance_max_count"`
+	ActiveSchedule *ActiveSchedule `json:"active_schedule,omitempty"`
+	// ScalingDisabled is set while dynamic scalings of the app are ignored, the reason is given by DisabledReason.
+	ScalingDisabled bool               `json:"scaling_disabled"`
+	DisabledReason  string             `json:"disabled_reason,omitempty"`
+	Cooldowns       []Cooldown         `json:"cooldowns"`
+	Breaker         *BreakerState      `json:"circuit_breaker,omitempty"`
+	Rules           []RuleStatus       `json:"scaling_rules"`
+	LastScaling     *AppScalingHistory `json:"last_scaling,omitempty"`
+}
+
+// RuleStatus is the state of a scaling rule of the policy of an app. The metric fields are only set once a metric of
+// the rule's type has been aggregated, Breached and EvaluatedAt once the rule has been evaluated.
+// DistanceToThreshold is the last value minus the threshold.
+type RuleStatus struct {
+	RuleIndex           int    `json:"rule_index"`
+	MetricType          string `json:"metric_type"`
+	Threshold           int64  `json:"threshold"`
+	Operator            string `json:"operator"`
+	Adjustment          string `json:"adjustment"`
+	LastValue           *int64 `json:"last_value,omitempty"`
+	Unit                string `json:"unit,omitempty"`
+	MetricTimestamp     int64  `json:"metric_timestamp,omitempty"`
+	DistanceToThreshold *int64 `json:"distance_to_threshold,omitempty"`
+	Breached            bool   `json:"breached"`
+	EvaluatedAt         int64  `json:"evaluated_at,omitempty"`
+}
+
+const (
+	disabledReasonAppNotStarted = "app is not started"
+	disabledReasonLabel         = "app has the label app-autoscaler.cloudfoundry.org/disable-autoscaling"
+)
+
+// NewAppStatus combines the policy of an app with the state of the scaling engine, the latest aggregated metrics of the
+// app by metric type and, if it is known, the evaluation state of the eventgenerator.
+func NewAppStatus(policy *PolicyDefinition, scaling *AppScalingState, evaluation *AppEvaluationState, latestMetrics map[string]*AppMetric) *AppStatus {
+	status := &AppStatus{
+		AppId:          scaling.AppId,
+		Instances:      scaling.Instances,
+		InstanceMin:    scaling.InstanceMin,
+		InstanceMax:    scaling.InstanceMax,
+		ActiveSchedule: scaling.ActiveSchedule,
+		Cooldowns:      scaling.Cooldowns,
+		LastScaling:    scaling.LastScaling,
+		Rules:          []RuleStatus{},
+	}
+	if status.Cooldowns == nil {
+		status.Cooldowns = []Cooldown{}
+	}
+
+	switch {
+	case scaling.AppState != AppStatusStarted:
+		status.ScalingDisabled = true
+		status.DisabledReason = disabledReasonAppNotStarted
+	case scaling.DisableAutoscaling != nil:
+		status.ScalingDisabled = true
+		status.DisabledReason = disabledReasonLabel
+		if *scaling.DisableAutoscaling != "" {
+			status.DisabledReason += ": " + *scaling.DisableAutoscaling
+		}
+	}
+
+	evaluations := map[int]RuleEvaluationState{}
+	if evaluation != nil {
+		status.Breaker = evaluation.Breaker
+		for _, rule := range evaluation.Rules {
+			if rule.RuleIndex != nil && !rule.Emergency {
+				evaluations[*rule.RuleIndex] = rule
+			}
+		}
+	}
+
+	for i, rule := range policy.ScalingRules {
+		ruleStatus := RuleStatus{
+			RuleIndex:  i,
+			MetricType: rule.MetricType,
+			Threshold:  rule.Threshold,
+			Operator:   rule.Operator,
+			Adjustment: rule.Adjustment,
+		}
+		if latest := latestMetrics[rule.MetricType]; latest != nil {
+			ruleStatus.Unit = latest.Unit
+			ruleStatus.MetricTimestamp = latest.Timestamp
+			if value, err := strconv.ParseInt(latest.Value, 10, 64); err == nil {
+				distance := value - rule.Threshold
+				ruleStatus.LastValue = &value
+				ruleStatus.DistanceToThreshold = &distance
+			}
+		}
+		// an evaluation of another metric type belongs to a rule of a previous policy
+		if ruleEvaluation, found := evaluations[i]; found && ruleEvaluation.MetricType == rule.MetricType {
+			ruleStatus.Breached = ruleEvaluation.Breached
+			ruleStatus.EvaluatedAt = ruleEvaluation.EvaluatedAt
+		}
+		status.Rules = append(status.Rules, ruleStatus)
+	}
+	return status
+}
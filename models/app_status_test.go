@@ -0,0 +1,148 @@
+package models_test
+
+import (
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppStatus", func() {
+	var (
+		policy        *PolicyDefinition
+		scaling       *AppScalingState
+		evaluation    *AppEvaluationState
+		latestMetrics map[string]*AppMetric
+		status        *AppStatus
+	)
+
+	BeforeEach(func() {
+		ruleIndex := 1
+		policy = &PolicyDefinition{
+			InstanceMin: 1,
+			InstanceMax: 5,
+			ScalingRules: []*ScalingRule{
+				{MetricType: "memoryused", Threshold: 300, Operator: ">=", Adjustment: "+1"},
+				{MetricType: "cpu", Threshold: 20, Operator: "<", Adjustment: "-1"},
+			},
+		}
+		scaling = &AppScalingState{AppId: "an-app-id", AppState: AppStatusStarted, Instances: 3, InstanceMin: 2, InstanceMax: 4}
+		evaluation = &AppEvaluationState{
+			AppId:   "an-app-id",
+			Breaker: &BreakerState{ConsecutiveFailures: 1},
+			Rules: []RuleEvaluationState{
+				{
+					RuleEvaluation: RuleEvaluation{
+						RuleIndex:  &ruleIndex,
+						MetricType: "cpu",
+						Samples:    []MetricSample{{Timestamp: 200, Value: "15", Unit: "%"}, {Timestamp: 100, Value: "12", Unit: "%"}},
+						Breached:   true,
+					},
+					EvaluatedAt: 250,
+				},
+				{
+					RuleEvaluation: RuleEvaluation{
+						RuleIndex:  &ruleIndex,
+						MetricType: "cpu",
+						Samples:    []MetricSample{{Timestamp: 200, Value: "15", Unit: "%"}},
+						Emergency:  true,
+					},
+					EvaluatedAt: 250,
+				},
+			},
+		}
+		latestMetrics = map[string]*AppMetric{
+			"memoryused": nil,
+			"cpu":        {AppId: "an-app-id", MetricType: "cpu", Value: "15", Unit: "%", Timestamp: 200},
+		}
+	})
+
+	JustBeforeEach(func() {
+		status = NewAppStatus(policy, scaling, evaluation, latestMetrics)
+	})
+
+	It("combines the scaling state with the latest metric and evaluation of each rule", func() {
+		Expect(status.InstanceMin).To(Equal(2))
+		Expect(status.InstanceMax).To(Equal(4))
+		Expect(status.ScalingDisabled).To(BeFalse())
+		Expect(status.Cooldowns).To(BeEmpty())
+		Expect(status.Breaker).To(Equal(&BreakerState{ConsecutiveFailures: 1}))
+		Expect(status.Rules).To(HaveLen(2))
+		Expect(status.Rules[0].LastValue).To(BeNil())
+		Expect(status.Rules[0].DistanceToThreshold).To(BeNil())
+
+		value, distance := int64(15), int64(-5)
+		Expect(status.Rules[1]).To(Equal(RuleStatus{
+			RuleIndex:           1,
+			MetricType:          "cpu",
+			Threshold:           20,
+			Operator:            "<",
+			Adjustment:          "-1",
+			LastValue:           &value,
+			Unit:                "%",
+			MetricTimestamp:     200,
+			DistanceToThreshold: &distance,
+			Breached:            true,
+			EvaluatedAt:         250,
+		}))
+	})
+
+	Context("when the evaluation belongs to a rule of a previous policy", func() {
+		BeforeEach(func() {
+			policy.ScalingRules[1].MetricType = "throughput"
+		})
+
+		It("ignores the evaluation", func() {
+			Expect(status.Rules[1].Breached).To(BeFalse())
+			Expect(status.Rules[1].EvaluatedAt).To(BeZero())
+		})
+	})
+
+	Context("when the evaluation state is unknown", func() {
+		BeforeEach(func() {
+			evaluation = nil
+		})
+
+		It("returns the rules of the policy with their latest metrics only", func() {
+			Expect(status.Breaker).To(BeNil())
+			Expect(status.Rules).To(HaveLen(2))
+			Expect(*status.Rules[1].LastValue).To(Equal(int64(15)))
+			Expect(status.Rules[1].Breached).To(BeFalse())
+			Expect(status.Rules[1].EvaluatedAt).To(BeZero())
+		})
+	})
+
+	Context("when the rule has no aggregated metric yet", func() {
+		BeforeEach(func() {
+			latestMetrics = map[string]*AppMetric{}
+		})
+
+		It("leaves out the metric fields", func() {
+			Expect(status.Rules[1].LastValue).To(BeNil())
+			Expect(status.Rules[1].DistanceToThreshold).To(BeNil())
+			Expect(status.Rules[1].Breached).To(BeTrue())
+		})
+	})
+
+	Context("when the app is not started", func() {
+		BeforeEach(func() {
+			scaling.AppState = "STOPPED"
+		})
+
+		It("reports scaling as disabled", func() {
+			Expect(status.ScalingDisabled).To(BeTrue())
+			Expect(status.DisabledReason).To(Equal("app is not started"))
+		})
+	})
+
+	Context("when the app has the disable-autoscaling label", func() {
+		BeforeEach(func() {
+			reason := "maintenance"
+			scaling.DisableAutoscaling = &reason
+		})
+
+		It("reports scaling as disabled with the value of the label", func() {
+			Expect(status.ScalingDisabled).To(BeTrue())
+			Expect(status.DisabledReason).To(Equal("app has the label app-autoscaler.cloudfoundry.org/disable-autoscaling: maintenance"))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// BudgetPeriod is the calendar period an instance-hour budget applies to. Periods start at
+// midnight UTC, weeks start on Monday.
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDay   BudgetPeriod = "day"
+	BudgetPeriodWeek  BudgetPeriod = "week"
+	BudgetPeriodMonth BudgetPeriod = "month"
+)
+
+// InstanceHourBudget caps the instance-hours an app may consume per period, e.g. "max 2000
+// instance-hours per month". Scale-outs that would exceed it are refused. The budget of the
+// default policy of a service instance is shared by all apps which are bound with that policy.
+type InstanceHourBudget struct {
+	InstanceHours int          `json:"instance_hours"`
+	Period        BudgetPeriod `json:"period"`
+}
+
+// Bounds returns the start and end of the period that contains t.
+func (p BudgetPeriod) Bounds(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case BudgetPeriodWeek:
+		// Weekday() starts with Sunday (0), shift it so that weeks start on Monday.
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case BudgetPeriodMonth:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return day, day.AddDate(0, 0, 1)
+	}
+}
+
+// InstanceHourUsage is the consumption of an app in the current budget period, including the
+// consumption of the apps it shares the budget with, which are listed in SharedWithAppIds.
+// ForecastInstanceHours assumes that the apps keep their current number of instances until the
+// end of the period.
+type InstanceHourUsage struct {
+	Budget                InstanceHourBudget `json:"budget"`
+	PeriodStart           time.Time          `json:"period_start"`
+	PeriodEnd             time.Time          `json:"period_end"`
+	CurrentInstances      int                `json:"current_instances"`
+	ConsumedInstanceHours float64            `json:"consumed_instance_hours"`
+	ForecastInstanceHours float64            `json:"forecast_instance_hours"`
+	SharedWithAppIds      []string           `json:"shared_with_app_ids,omitempty"`
+}
+
+// NewInstanceHourUsage computes the usage from the succeeded scaling histories of the current
+// period in ascending order. The instance count before the first history entry is taken from
+// its old instances, or from `currentInstances` if the app has not been scaled in this period.
+func NewInstanceHourUsage(budget InstanceHourBudget, histories []*AppScalingHistory, currentInstances int, now time.Time) *InstanceHourUsage {
+	periodStart, periodEnd := budget.Period.Bounds(now)
+
+	instances := currentInstances
+	if len(histories) > 0 {
+		instances = histories[0].OldInstances
+	}
+
+	consumed := 0.0
+	from := periodStart
+	for _, history := range histories {
+		at := time.Unix(0, history.Timestamp).UTC()
+		if at.Before(periodStart) || at.After(now) {
+			continue
+		}
+		consumed += float64(instances) * at.Sub(from).Hours()
+		from, instances = at, history.NewInstances
+	}
+	consumed += float64(currentInstances) * now.Sub(from).Hours()
+
+	return &InstanceHourUsage{
+		Budget:                budget,
+		PeriodStart:           periodStart,
+		PeriodEnd:             periodEnd,
+		CurrentInstances:      currentInstances,
+		ConsumedInstanceHours: consumed,
+		ForecastInstanceHours: consumed + float64(currentInstances)*periodEnd.Sub(now).Hours(),
+	}
+}
+
+// Add adds the usage of another app which shares the budget.
+func (u *InstanceHourUsage) Add(appId string, other *InstanceHourUsage) {
+	u.SharedWithAppIds = append(u.SharedWithAppIds, appId)
+	u.CurrentInstances += other.CurrentInstances
+	u.ConsumedInstanceHours += other.ConsumedInstanceHours
+	u.ForecastInstanceHours += other.ForecastInstanceHours
+}
+
+// Allows reports whether scaling an app from `instances` to `newInstances` stays within the
+// budget if all apps sharing it keep their instances from now until the end of the period.
+func (u *InstanceHourUsage) Allows(instances int, newInstances int, now time.Time) bool {
+	forecast := u.ConsumedInstanceHours + float64(u.CurrentInstances-instances+newInstances)*u.PeriodEnd.Sub(now).Hours()
+	return forecast <= float64(u.Budget.InstanceHours)
+}
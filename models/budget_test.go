@@ -0,0 +1,90 @@
+package models_test
+
+import (
+	"time"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstanceHourBudget", func() {
+	// Wednesday
+	now := time.Date(2026, time.April, 15, 12, 0, 0, 0, time.UTC)
+
+	DescribeTable("BudgetPeriod.Bounds",
+		func(period BudgetPeriod, expectedStart time.Time, expectedEnd time.Time) {
+			start, end := period.Bounds(now)
+			Expect(start).To(Equal(expectedStart))
+			Expect(end).To(Equal(expectedEnd))
+		},
+		Entry("day", BudgetPeriodDay, time.Date(2026, time.April, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, time.April, 16, 0, 0, 0, 0, time.UTC)),
+		Entry("week", BudgetPeriodWeek, time.Date(2026, time.April, 13, 0, 0, 0, 0, time.UTC), time.Date(2026, time.April, 20, 0, 0, 0, 0, time.UTC)),
+		Entry("month", BudgetPeriodMonth, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	Describe("NewInstanceHourUsage", func() {
+		var (
+			budget    InstanceHourBudget
+			histories []*AppScalingHistory
+			usage     *InstanceHourUsage
+		)
+
+		BeforeEach(func() {
+			budget = InstanceHourBudget{InstanceHours: 100, Period: BudgetPeriodDay}
+			histories = nil
+		})
+
+		JustBeforeEach(func() {
+			usage = NewInstanceHourUsage(budget, histories, 2, now)
+		})
+
+		Context("when the app was not scaled in the period", func() {
+			It("uses the current instances for the whole period", func() {
+				Expect(usage.ConsumedInstanceHours).To(BeNumerically("~", 24))
+				Expect(usage.ForecastInstanceHours).To(BeNumerically("~", 48))
+				Expect(usage.CurrentInstances).To(Equal(2))
+			})
+		})
+
+		Context("when the app was scaled in the period", func() {
+			BeforeEach(func() {
+				histories = []*AppScalingHistory{
+					{Timestamp: now.Add(-10 * time.Hour).UnixNano(), OldInstances: 1, NewInstances: 4},
+					{Timestamp: now.Add(-4 * time.Hour).UnixNano(), OldInstances: 4, NewInstances: 2},
+				}
+			})
+
+			It("sums the instance-hours of each interval", func() {
+				// 2h with 1 instance, 6h with 4 instances, 4h with 2 instances
+				Expect(usage.ConsumedInstanceHours).To(BeNumerically("~", 2+24+8))
+				Expect(usage.ForecastInstanceHours).To(BeNumerically("~", 34+24))
+			})
+		})
+
+		It("allows scale-outs that stay within the budget only", func() {
+			Expect(usage.Allows(2, 6, now)).To(BeTrue())
+			Expect(usage.Allows(2, 7, now)).To(BeFalse())
+		})
+
+		Context("when another app shares the budget", func() {
+			JustBeforeEach(func() {
+				usage.Add("another-app-id", NewInstanceHourUsage(budget, nil, 1, now))
+			})
+
+			It("sums the usage of both apps", func() {
+				Expect(usage.SharedWithAppIds).To(Equal([]string{"another-app-id"}))
+				Expect(usage.CurrentInstances).To(Equal(3))
+				Expect(usage.ConsumedInstanceHours).To(BeNumerically("~", 36))
+				Expect(usage.ForecastInstanceHours).To(BeNumerically("~", 72))
+			})
+
+			It("allows scale-outs that keep the usage of both apps within the budget only", func() {
+				// 36 instance-hours consumed, 12 hours left with 1 instance of the other app
+				Expect(usage.Allows(2, 4, now)).To(BeTrue())
+				Expect(usage.Allows(2, 5, now)).To(BeFalse())
+			})
+		})
+	})
+})
@@ -36,6 +36,8 @@ type CustomMetricsCredentials struct {
 }
 type Credentials struct {
 	CustomMetrics CustomMetricsCredentials `json:"custom_metrics"`
+	// PolicyWarnings describes why the policy of the binding is most likely not behaving as intended.
+	PolicyWarnings []string `json:"policy_warnings,omitempty"`
 }
 type CredentialResponse struct {
 	Credentials Credentials `json:"credentials"`
@@ -0,0 +1,39 @@
+package models
+
+// MetricSample is a metric value which has been considered for a scaling decision.
+type MetricSample struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+	Unit      string `json:"unit"`
+}
+
+// RuleEvaluation describes how a scaling rule has been evaluated against the metric samples within
+// its breach duration. Samples is empty if there were not enough metrics to evaluate the rule.
+type RuleEvaluation struct {
+	RuleIndex  *int           `json:"rule_index,omitempty"`
+	MetricType string         `json:"metric_type"`
+	Threshold  int64          `json:"threshold"`
+	Operator   string         `json:"operator"`
+	Adjustment string         `json:"adjustment"`
+	Emergency  bool           `json:"emergency,omitempty"`
+	Samples    []MetricSample `json:"samples"`
+	Breached   bool           `json:"breached"`
+}
+
+// CFScalingResponse is the response of the cloud controller to the request to scale an app.
+type CFScalingResponse struct {
+	Instances  int    `json:"instances"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DecisionTrace links a scaling decision of the eventgenerator with its outcome. Scaling is the scaling
+// history of the decision, CFResponse is nil if the app has not been scaled, e.g. due to the cool-down.
+type DecisionTrace struct {
+	DecisionId string             `json:"decision_id"`
+	AppId      string             `json:"app_id"`
+	Timestamp  int64              `json:"timestamp"`
+	Evaluation []RuleEvaluation   `json:"evaluation"`
+	Scaling    *AppScalingHistory `json:"scaling,omitempty"`
+	CFResponse *CFScalingResponse `json:"cf_response,omitempty"`
+}
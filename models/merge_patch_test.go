@@ -0,0 +1,40 @@
+package models_test
+
+import (
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyMergePatch", func() {
+	DescribeTable("applies the patch as in the examples of RFC 7386",
+		func(document string, patch string, expected string) {
+			result, err := ApplyMergePatch([]byte(document), []byte(patch))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(MatchJSON(expected))
+		},
+		Entry("replacing a member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`),
+		Entry("adding a member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`),
+		Entry("removing a member", `{"a":"b"}`, `{"a":null}`, `{}`),
+		Entry("removing one of several members", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`),
+		Entry("replacing an array", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`),
+		Entry("replacing a value by an array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`),
+		Entry("merging nested objects", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`),
+		Entry("replacing arrays of objects", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`),
+		Entry("replacing the document by an array", `["a","b"]`, `["c","d"]`, `["c","d"]`),
+		Entry("replacing an object by an array", `{"a":"b"}`, `["c"]`, `["c"]`),
+		Entry("replacing the document by null", `{"a":"foo"}`, `null`, `null`),
+		Entry("replacing the document by a string", `{"a":"foo"}`, `"bar"`, `"bar"`),
+		Entry("keeping null members of the patch out of new objects", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`),
+		Entry("patching a non-object", `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`),
+		Entry("creating nested objects", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`),
+	)
+
+	Context("when the patch is not JSON", func() {
+		It("returns an error", func() {
+			_, err := ApplyMergePatch([]byte(`{}`), []byte(`{`))
+			Expect(err).To(MatchError(ContainSubstring("invalid merge patch")))
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -303,10 +304,14 @@ func (s *CustomMetricsStrategy) UnmarshalJSON(data []byte) error {
 // It can be created/deleted/retrieved by the user via the binding process and public api. If a change is required in the policy,
 // the corresponding endpoints should be also be updated in the public api server.
 type PolicyDefinition struct {
-	InstanceMin  int               `json:"instance_min_count"`
-	InstanceMax  int               `json:"instance_max_count"`
-	ScalingRules []*ScalingRule    `json:"scaling_rules,omitempty"`
-	Schedules    *ScalingSchedules `json:"schedules,omitempty"`
+	InstanceMin             int                 `json:"instance_min_count"`
+	InstanceMax             int                 `json:"instance_max_count"`
+	ScalingRules            []*ScalingRule      `json:"scaling_rules,omitempty"`
+	Schedules               *ScalingSchedules   `json:"schedules,omitempty"`
+	BlackoutPeriods         *BlackoutPeriods    `json:"blackout_periods,omitempty"`
+	InstanceHourBudget      *InstanceHourBudget `json:"instance_hour_budget,omitempty"`
+	ScaleOutCoolDownSeconds int                 `json:"scale_out_cooldown_secs,omitempty"`
+	ScaleInCoolDownSeconds  int                 `json:"scale_in_cooldown_secs,omitempty"`
 }
 
 func (pd PolicyDefinition) ToRawJSON() (json.RawMessage, error) {
@@ -333,12 +338,17 @@ func (pd PolicyDefinition) String() string {
 var _ fmt.Stringer = &PolicyDefinition{}
 
 type ScalingRule struct {
-	MetricType            string `json:"metric_type"`
-	BreachDurationSeconds int    `json:"breach_duration_secs,omitempty"`
-	Threshold             int64  `json:"threshold"`
-	Operator              string `json:"operator"`
-	CoolDownSeconds       int    `json:"cool_down_secs,omitempty"`
-	Adjustment            string `json:"adjustment"`
+	MetricType              string `json:"metric_type"`
+	BreachDurationSeconds   int    `json:"breach_duration_secs,omitempty"`
+	Threshold               int64  `json:"threshold"`
+	Operator                string `json:"operator"`
+	CoolDownSeconds         int    `json:"cool_down_secs,omitempty"`
+	ScaleOutCoolDownSeconds int    `json:"scale_out_cooldown_secs,omitempty"`
+	ScaleInCoolDownSeconds  int    `json:"scale_in_cooldown_secs,omitempty"`
+	Adjustment              string `json:"adjustment"`
+	EmergencyThreshold      *int64 `json:"emergency_threshold,omitempty"`
+	EmergencyAdjustment     string `json:"emergency_adjustment,omitempty"`
+	SourceAppId             string `json:"source_app_id,omitempty"`
 }
 
 type ScalingSchedules struct {
@@ -380,6 +390,153 @@ type SpecificDateSchedule struct {
 	ScheduledInstanceInit int    `json:"initial_min_instance_count,omitempty"`
 }
 
+// BlackoutMode determines which scaling actions are suppressed during a blackout period.
+type BlackoutMode string
+
+const (
+	BlackoutModeNoScaleIn BlackoutMode = "no_scale_in"
+	BlackoutModeNoScaling BlackoutMode = "no_scaling"
+
+	blackoutDateTimeLayout = "2006-01-02T15:04"
+	blackoutDateLayout     = "2006-01-02"
+	blackoutTimeLayout     = "15:04"
+)
+
+// Suppresses reports whether a change from `currentInstances` to `newInstances` must not be
+// carried out while a blackout period with this mode is active.
+func (m BlackoutMode) Suppresses(currentInstances int, newInstances int) bool {
+	switch m {
+	case BlackoutModeNoScaling:
+		return newInstances != currentInstances
+	case BlackoutModeNoScaleIn:
+		return newInstances < currentInstances
+	default:
+		return false
+	}
+}
+
+// BlackoutPeriods are maintenance windows (e.g. database migrations or release freezes) during
+// which dynamic scaling is restricted. They are modelled like `ScalingSchedules`.
+type BlackoutPeriods struct {
+	Timezone              string                  `json:"timezone"`
+	RecurringBlackouts    []*RecurringBlackout    `json:"recurring_blackout,omitempty"`
+	SpecificDateBlackouts []*SpecificDateBlackout `json:"specific_date,omitempty"`
+}
+
+type RecurringBlackout struct {
+	StartTime   string       `json:"start_time"`
+	EndTime     string       `json:"end_time"`
+	DaysOfWeek  []int        `json:"days_of_week,omitempty"`
+	DaysOfMonth []int        `json:"days_of_month,omitempty"`
+	StartDate   string       `json:"start_date,omitempty"`
+	EndDate     string       `json:"end_date,omitempty"`
+	Mode        BlackoutMode `json:"mode"`
+}
+
+type SpecificDateBlackout struct {
+	StartDateTime string       `json:"start_date_time"`
+	EndDateTime   string       `json:"end_date_time"`
+	Mode          BlackoutMode `json:"mode"`
+}
+
+// ActiveMode returns the mode of the blackout period that is active at the given point in time
+// and an empty mode if there is none. If several periods match, then `BlackoutModeNoScaling`
+// takes precedence.
+func (b *BlackoutPeriods) ActiveMode(t time.Time) BlackoutMode {
+	if b == nil {
+		return ""
+	}
+
+	location, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	localTime := t.In(location)
+
+	var activeMode BlackoutMode
+	activate := func(mode BlackoutMode) {
+		if activeMode != BlackoutModeNoScaling {
+			activeMode = mode
+		}
+	}
+
+	for _, recurring := range b.RecurringBlackouts {
+		if recurring.isActive(localTime) {
+			activate(recurring.Mode)
+		}
+	}
+
+	for _, specific := range b.SpecificDateBlackouts {
+		if specific.isActive(localTime) {
+			activate(specific.Mode)
+		}
+	}
+
+	return activeMode
+}
+
+func (r *RecurringBlackout) isActive(localTime time.Time) bool {
+	date := localTime.Format(blackoutDateLayout)
+	if r.StartDate != "" && date < r.StartDate {
+		return false
+	}
+	if r.EndDate != "" && date > r.EndDate {
+		return false
+	}
+
+	// Days of the week are numbered from 1 (Monday) to 7 (Sunday) like in `RecurringSchedule`.
+	weekday := int(localTime.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	if len(r.DaysOfWeek) > 0 && !slices.Contains(r.DaysOfWeek, weekday) {
+		return false
+	}
+	if len(r.DaysOfMonth) > 0 && !slices.Contains(r.DaysOfMonth, localTime.Day()) {
+		return false
+	}
+
+	timeOfDay := localTime.Format(blackoutTimeLayout)
+	return r.StartTime <= timeOfDay && timeOfDay < r.EndTime
+}
+
+func (s *SpecificDateBlackout) isActive(localTime time.Time) bool {
+	start, err := time.ParseInLocation(blackoutDateTimeLayout, s.StartDateTime, localTime.Location())
+	if err != nil {
+		return false
+	}
+	end, err := time.ParseInLocation(blackoutDateTimeLayout, s.EndDateTime, localTime.Location())
+	if err != nil {
+		return false
+	}
+	return !localTime.Before(start) && localTime.Before(end)
+}
+
+// MetricSourceId returns the id of the app whose metrics the rule is evaluated on when it scales the app `appId`.
+// This is the source app of the rule if it has one, otherwise the scaled app itself.
+func (r *ScalingRule) MetricSourceId(appId string) string {
+	if r.SourceAppId == "" {
+		return appId
+	}
+	return r.SourceAppId
+}
+
+// SourceAppIds returns the ids of the apps other than `appId` whose metrics the scaling rules of the policy use,
+// each only once.
+func (pd *PolicyDefinition) SourceAppIds(appId string) []string {
+	if pd == nil {
+		return nil
+	}
+	var sourceAppIds []string
+	for _, rule := range pd.ScalingRules {
+		sourceAppId := rule.MetricSourceId(appId)
+		if sourceAppId != appId && !slices.Contains(sourceAppIds, sourceAppId) {
+			sourceAppIds = append(sourceAppIds, sourceAppId)
+		}
+	}
+	return sourceAppIds
+}
+
 func (r *ScalingRule) BreachDuration(defaultBreachDurationSecs int) time.Duration {
 	if r.BreachDurationSeconds <= 0 {
 		return time.Duration(defaultBreachDurationSecs) * time.Second
@@ -394,26 +551,77 @@ func (r *ScalingRule) CoolDown(defaultCoolDownSecs int) time.Duration {
 	return time.Duration(r.CoolDownSeconds) * time.Second
 }
 
+// DirectionalCoolDownSeconds returns the cool-down in seconds to apply after a scaling action of
+// `rule` in `direction`. A directional cool-down of the rule takes precedence over its
+// `cool_down_secs`, which in turn takes precedence over the directional cool-down of the policy.
+// 0 means that the caller's fallback applies.
+func (pd *PolicyDefinition) DirectionalCoolDownSeconds(rule *ScalingRule, direction ScalingDirection) int {
+	ruleSecs, policySecs := rule.ScaleOutCoolDownSeconds, pd.ScaleOutCoolDownSeconds
+	if direction == ScalingDirectionIn {
+		ruleSecs, policySecs = rule.ScaleInCoolDownSeconds, pd.ScaleInCoolDownSeconds
+	}
+	switch {
+	case ruleSecs > 0:
+		return ruleSecs
+	case rule.CoolDownSeconds > 0:
+		return 0
+	default:
+		return policySecs
+	}
+}
+
+// ScalingDirection distinguishes scale-outs from scale-ins, which have independent cool-downs.
+type ScalingDirection string
+
+const (
+	ScalingDirectionOut ScalingDirection = "out"
+	ScalingDirectionIn  ScalingDirection = "in"
+)
+
+// DirectionOf returns the direction of a scaling `adjustment` like "+1" or "-50%".
+func DirectionOf(adjustment string) ScalingDirection {
+	if strings.HasPrefix(adjustment, "-") {
+		return ScalingDirectionIn
+	}
+	return ScalingDirectionOut
+}
+
 type Trigger struct {
-	AppId                 string `json:"app_id"`
-	MetricType            string `json:"metric_type"`
-	MetricUnit            string `json:"metric_unit"`
-	BreachDurationSeconds int    `json:"breach_duration_secs"`
-	Threshold             int64  `json:"threshold"`
-	Operator              string `json:"operator"`
-	CoolDownSeconds       int    `json:"cool_down_secs"`
-	Adjustment            string `json:"adjustment"`
+	AppId                      string `json:"app_id"`
+	MetricType                 string `json:"metric_type"`
+	MetricUnit                 string `json:"metric_unit"`
+	BreachDurationSeconds      int    `json:"breach_duration_secs"`
+	Threshold                  int64  `json:"threshold"`
+	Operator                   string `json:"operator"`
+	CoolDownSeconds            int    `json:"cool_down_secs"`
+	DirectionalCoolDownSeconds int    `json:"directional_cool_down_secs,omitempty"`
+	Adjustment                 string `json:"adjustment"`
+	Emergency                  bool   `json:"emergency,omitempty"`
+	// RuleIndex is the index of the scaling rule in the policy the trigger is derived from.
+	RuleIndex *int `json:"rule_index,omitempty"`
+	// ObservedValue is the latest metric value, it is set once the threshold is breached.
+	ObservedValue *int64 `json:"observed_value,omitempty"`
+	// Evaluation describes the rules evaluated for the scaling decision up to the breached one.
+	Evaluation []RuleEvaluation `json:"evaluation,omitempty"`
 }
 
 func (t Trigger) BreachDuration() time.Duration {
 	return time.Duration(t.BreachDurationSeconds) * time.Second
 }
 
+func (t Trigger) Direction() ScalingDirection {
+	return DirectionOf(t.Adjustment)
+}
+
 func (t Trigger) CoolDown(defaultCoolDownSecs int) time.Duration {
-	if t.CoolDownSeconds <= 0 {
+	switch {
+	case t.DirectionalCoolDownSeconds > 0:
+		return time.Duration(t.DirectionalCoolDownSeconds) * time.Second
+	case t.CoolDownSeconds > 0:
+		return time.Duration(t.CoolDownSeconds) * time.Second
+	default:
 		return time.Duration(defaultCoolDownSecs) * time.Second
 	}
-	return time.Duration(t.CoolDownSeconds) * time.Second
 }
 
 type ActiveSchedule struct {
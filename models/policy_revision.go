@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// PolicySource is the channel through which the policy of an app has been changed.
+type PolicySource string
+
+const (
+	PolicySourceBrokerBind    PolicySource = "broker-bind"
+	PolicySourcePublicAPI     PolicySource = "public-api"
+	PolicySourceDefaultPolicy PolicySource = "default-policy"
+	PolicySourceRollback      PolicySource = "rollback"
+)
+
+// PolicyChange describes who changed the policy of an app and how, it is recorded with the revision.
+type PolicyChange struct {
+	Author string
+	Source PolicySource
+}
+
+// PolicyRevision is a policy of an app as it has been saved. Revisions are numbered per app, starting with 1.
+type PolicyRevision struct {
+	AppId      string            `json:"app_id"`
+	Revision   int               `json:"revision"`
+	PolicyGuid string            `json:"policy_guid"`
+	Author     string            `json:"author"`
+	Source     PolicySource      `json:"source"`
+	Timestamp  int64             `json:"timestamp"`
+	Policy     *PolicyDefinition `json:"policy"`
+}
+
+// PolicyDiffEntry is a difference between two policies. Path addresses the differing value in the JSON
+// representation of the policies, e.g. "scaling_rules[0].threshold". From is nil for added values, To is
+// nil for removed ones.
+type PolicyDiffEntry struct {
+	Path string `json:"path"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// PolicyRevisionDiff lists the differences between two revisions of the policy of an app.
+type PolicyRevisionDiff struct {
+	From    int               `json:"from"`
+	To      int               `json:"to"`
+	Changes []PolicyDiffEntry `json:"changes"`
+}
+
+// DiffPolicies returns the differences between two policies ordered by path.
+func DiffPolicies(from *PolicyDefinition, to *PolicyDefinition) ([]PolicyDiffEntry, error) {
+	fromValues, err := flattenPolicy(from)
+	if err != nil {
+		return nil, err
+	}
+	toValues, err := flattenPolicy(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := []PolicyDiffEntry{}
+	for path, fromValue := range fromValues {
+		toValue, ok := toValues[path]
+		if !ok || !reflect.DeepEqual(fromValue, toValue) {
+			diff = append(diff, PolicyDiffEntry{Path: path, From: fromValue, To: toValue})
+		}
+	}
+	for path, toValue := range toValues {
+		if _, ok := fromValues[path]; !ok {
+			diff = append(diff, PolicyDiffEntry{Path: path, To: toValue})
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Path < diff[j].Path })
+	return diff, nil
+}
+
+// flattenPolicy maps the paths of all scalar values in the JSON representation of the policy to the values.
+func flattenPolicy(policy *PolicyDefinition) (map[string]any, error) {
+	values := map[string]any{}
+	if policy == nil {
+		return values, nil
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy: %w", err)
+	}
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy: %w", err)
+	}
+	flattenValue("", document, values)
+	return values, nil
+}
+
+func flattenValue(path string, value any, values map[string]any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if path == "" {
+				flattenValue(key, child, values)
+			} else {
+				flattenValue(path+"."+key, child, values)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, values)
+		}
+	default:
+		values[path] = v
+	}
+}
@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiffPolicies", func() {
+	var (
+		from *PolicyDefinition
+		to   *PolicyDefinition
+		diff []PolicyDiffEntry
+		err  error
+	)
+
+	BeforeEach(func() {
+		from = &PolicyDefinition{
+			InstanceMin: 1,
+			InstanceMax: 5,
+			ScalingRules: []*ScalingRule{
+				{MetricType: "cpu", BreachDurationSeconds: 120, Threshold: 80, Operator: ">", Adjustment: "+1"},
+			},
+		}
+		to = &PolicyDefinition{
+			InstanceMin: 1,
+			InstanceMax: 5,
+			ScalingRules: []*ScalingRule{
+				{MetricType: "cpu", BreachDurationSeconds: 120, Threshold: 80, Operator: ">", Adjustment: "+1"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		diff, err = DiffPolicies(from, to)
+	})
+
+	Context("when the policies are equal", func() {
+		It("returns no differences", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(BeEmpty())
+		})
+	})
+
+	Context("when values have been changed, added and removed", func() {
+		BeforeEach(func() {
+			to.InstanceMax = 10
+			to.ScalingRules[0].Threshold = 90
+			to.ScalingRules = append(to.ScalingRules, &ScalingRule{MetricType: "memoryused", Threshold: 100, Operator: ">", Adjustment: "+1"})
+			from.ScalingRules[0].CoolDownSeconds = 300
+		})
+
+		It("returns the differences ordered by path", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(Equal([]PolicyDiffEntry{
+				{Path: "instance_max_count", From: float64(5), To: float64(10)},
+				{Path: "scaling_rules[0].cool_down_secs", From: float64(300)},
+				{Path: "scaling_rules[0].threshold", From: float64(80), To: float64(90)},
+				{Path: "scaling_rules[1].adjustment", To: "+1"},
+				{Path: "scaling_rules[1].metric_type", To: "memoryused"},
+				{Path: "scaling_rules[1].operator", To: ">"},
+				{Path: "scaling_rules[1].threshold", To: float64(100)},
+			}))
+		})
+	})
+
+	Context("when there is no previous policy", func() {
+		BeforeEach(func() {
+			from = nil
+		})
+
+		It("returns all values as added", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(ContainElement(PolicyDiffEntry{Path: "instance_min_count", To: float64(1)}))
+			for _, entry := range diff {
+				Expect(entry.From).To(BeNil())
+			}
+		})
+	})
+})
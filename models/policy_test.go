@@ -290,6 +290,65 @@ var _ = Describe("Policy", func() {
 		Entry("one Specific schedule", &ScalingSchedules{SpecificDateSchedules: []*SpecificDateSchedule{{}}}, false),
 	)
 
+	Context("BlackoutPeriods", func() {
+		// Monday, 2 March 2026, 11:30 in Europe/Berlin
+		now := time.Date(2026, time.March, 2, 10, 30, 0, 0, time.UTC)
+
+		DescribeTable("ActiveMode",
+			func(blackoutPeriods *BlackoutPeriods, expectedMode BlackoutMode) {
+				Expect(blackoutPeriods.ActiveMode(now)).To(Equal(expectedMode))
+			},
+			Entry("nil", nil, BlackoutMode("")),
+			Entry("recurring blackout on the current day of the week",
+				&BlackoutPeriods{Timezone: "Europe/Berlin", RecurringBlackouts: []*RecurringBlackout{
+					{StartTime: "11:00", EndTime: "12:00", DaysOfWeek: []int{1}, Mode: BlackoutModeNoScaleIn},
+				}}, BlackoutModeNoScaleIn),
+			Entry("recurring blackout on another day of the week",
+				&BlackoutPeriods{Timezone: "Europe/Berlin", RecurringBlackouts: []*RecurringBlackout{
+					{StartTime: "11:00", EndTime: "12:00", DaysOfWeek: []int{2, 7}, Mode: BlackoutModeNoScaleIn},
+				}}, BlackoutMode("")),
+			Entry("recurring blackout evaluated in the timezone of the blackout periods",
+				&BlackoutPeriods{Timezone: "UTC", RecurringBlackouts: []*RecurringBlackout{
+					{StartTime: "11:00", EndTime: "12:00", DaysOfWeek: []int{1}, Mode: BlackoutModeNoScaleIn},
+				}}, BlackoutMode("")),
+			Entry("recurring blackout on the current day of the month",
+				&BlackoutPeriods{Timezone: "Europe/Berlin", RecurringBlackouts: []*RecurringBlackout{
+					{StartTime: "00:00", EndTime: "23:59", DaysOfMonth: []int{2}, Mode: BlackoutModeNoScaling},
+				}}, BlackoutModeNoScaling),
+			Entry("recurring blackout that has ended",
+				&BlackoutPeriods{Timezone: "Europe/Berlin", RecurringBlackouts: []*RecurringBlackout{
+					{StartTime: "11:00", EndTime: "12:00", DaysOfWeek: []int{1}, EndDate: "2026-03-01", Mode: BlackoutModeNoScaling},
+				}}, BlackoutMode("")),
+			Entry("specific date blackout",
+				&BlackoutPeriods{Timezone: "Europe/Berlin", SpecificDateBlackouts: []*SpecificDateBlackout{
+					{StartDateTime: "2026-03-02T11:30", EndDateTime: "2026-03-02T11:31", Mode: BlackoutModeNoScaling},
+				}}, BlackoutModeNoScaling),
+			Entry("specific date blackout that has ended",
+				&BlackoutPeriods{Timezone: "Europe/Berlin", SpecificDateBlackouts: []*SpecificDateBlackout{
+					{StartDateTime: "2026-03-02T10:00", EndDateTime: "2026-03-02T11:30", Mode: BlackoutModeNoScaling},
+				}}, BlackoutMode("")),
+			Entry("no_scaling takes precedence over no_scale_in",
+				&BlackoutPeriods{Timezone: "Europe/Berlin",
+					RecurringBlackouts: []*RecurringBlackout{
+						{StartTime: "11:00", EndTime: "12:00", DaysOfWeek: []int{1}, Mode: BlackoutModeNoScaling},
+					},
+					SpecificDateBlackouts: []*SpecificDateBlackout{
+						{StartDateTime: "2026-03-02T11:00", EndDateTime: "2026-03-02T12:00", Mode: BlackoutModeNoScaleIn},
+					}}, BlackoutModeNoScaling),
+		)
+
+		DescribeTable("BlackoutMode.Suppresses",
+			func(mode BlackoutMode, currentInstances int, newInstances int, suppressed bool) {
+				Expect(mode.Suppresses(currentInstances, newInstances)).To(Equal(suppressed))
+			},
+			Entry("no blackout", BlackoutMode(""), 2, 1, false),
+			Entry("no_scale_in when scaling in", BlackoutModeNoScaleIn, 2, 1, true),
+			Entry("no_scale_in when scaling out", BlackoutModeNoScaleIn, 2, 3, false),
+			Entry("no_scaling when scaling in", BlackoutModeNoScaling, 2, 1, true),
+			Entry("no_scaling when scaling out", BlackoutModeNoScaling, 2, 3, true),
+		)
+	})
+
 	Context("ScalingRules", func() {
 		JustBeforeEach(func() {
 			policy, err = policyJson.GetAppPolicy()
@@ -321,4 +380,53 @@ var _ = Describe("Policy", func() {
 			})
 		})
 	})
+
+	Context("DirectionalCoolDownSeconds", func() {
+		DescribeTable("resolves the cool-down of a rule per direction",
+			func(rule ScalingRule, direction ScalingDirection, expected int) {
+				pd := &PolicyDefinition{ScaleOutCoolDownSeconds: 60, ScaleInCoolDownSeconds: 600}
+				Expect(pd.DirectionalCoolDownSeconds(&rule, direction)).To(Equal(expected))
+			},
+			Entry("policy-level scale-out", ScalingRule{}, ScalingDirectionOut, 60),
+			Entry("policy-level scale-in", ScalingRule{}, ScalingDirectionIn, 600),
+			Entry("rule-level scale-out", ScalingRule{ScaleOutCoolDownSeconds: 30}, ScalingDirectionOut, 30),
+			Entry("rule-level scale-in", ScalingRule{ScaleInCoolDownSeconds: 900}, ScalingDirectionIn, 900),
+			Entry("rule-level cool_down_secs", ScalingRule{CoolDownSeconds: 120}, ScalingDirectionIn, 0),
+		)
+	})
+
+	Context("SourceAppIds", func() {
+		It("returns the other apps whose metrics the rules use, each only once", func() {
+			pd := &PolicyDefinition{ScalingRules: []*ScalingRule{
+				{MetricType: "memoryutil"},
+				{MetricType: "queue_length", SourceAppId: "source-app-id"},
+				{MetricType: "queue_length", SourceAppId: "source-app-id"},
+				{MetricType: "throughput", SourceAppId: "the-app-id"},
+			}}
+			Expect(pd.SourceAppIds("the-app-id")).To(Equal([]string{"source-app-id"}))
+			Expect(pd.ScalingRules[0].MetricSourceId("the-app-id")).To(Equal("the-app-id"))
+			Expect(pd.ScalingRules[1].MetricSourceId("the-app-id")).To(Equal("source-app-id"))
+		})
+	})
+
+	Context("Trigger", func() {
+		DescribeTable("CoolDown",
+			func(trigger Trigger, expected time.Duration) {
+				Expect(trigger.CoolDown(DefaultCoolDownSecs)).To(Equal(expected))
+			},
+			Entry("directional cool-down", Trigger{CoolDownSeconds: 120, DirectionalCoolDownSeconds: 30}, 30*time.Second),
+			Entry("cool_down_secs", Trigger{CoolDownSeconds: 120}, 120*time.Second),
+			Entry("default", Trigger{}, DefaultCoolDownSecs*time.Second),
+		)
+
+		DescribeTable("Direction",
+			func(adjustment string, expected ScalingDirection) {
+				Expect(Trigger{Adjustment: adjustment}.Direction()).To(Equal(expected))
+			},
+			Entry("absolute scale-out", "+1", ScalingDirectionOut),
+			Entry("relative scale-out", "+50%", ScalingDirectionOut),
+			Entry("absolute scale-in", "-1", ScalingDirectionIn),
+			Entry("relative scale-in", "-50%", ScalingDirectionIn),
+		)
+	})
 })
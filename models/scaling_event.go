@@ -0,0 +1,56 @@
+package models
+
+// ScalingEventType is the kind of an event in the live scaling event stream of an app.
+type ScalingEventType string
+
+const (
+	ScalingEventScaling        ScalingEventType = "scaling"
+	ScalingEventTriggerIgnored ScalingEventType = "trigger_ignored"
+	ScalingEventScheduleStart  ScalingEventType = "schedule_start"
+	ScalingEventScheduleEnd    ScalingEventType = "schedule_end"
+	ScalingEventEvaluation     ScalingEventType = "evaluation"
+	ScalingEventHeartbeat      ScalingEventType = "heartbeat"
+)
+
+// ScalingEvent is something the scaling engine did for an app, or an evaluation of its scaling rules by the
+// eventgenerator. The ids of the events increase in the order in which they are stored, so a client can resume the
+// stream after the last event it has received. Heartbeats only keep an idle stream open, they have no id.
+type ScalingEvent struct {
+	Id         int64              `json:"id,omitempty"`
+	AppId      string             `json:"app_id"`
+	Timestamp  int64              `json:"timestamp"`
+	Type       ScalingEventType   `json:"type"`
+	History    *AppScalingHistory `json:"history,omitempty"`
+	Evaluation []RuleEvaluation   `json:"evaluation,omitempty"`
+}
+
+// ScalingEvents are the events of an app after an event id. LastEventId is the id after which the next events of
+// the app will follow.
+type ScalingEvents struct {
+	Events      []*ScalingEvent `json:"events"`
+	LastEventId int64           `json:"last_event_id"`
+}
+
+// NewScalingEvent returns the event of a dynamic scaling or of the start or end of a schedule, which is described by
+// its scaling history. Dynamic scalings which have been ignored, e.g. due to the cool-down, are trigger_ignored events.
+func NewScalingEvent(eventType ScalingEventType, history *AppScalingHistory) *ScalingEvent {
+	if eventType == ScalingEventScaling && history.Status == ScalingStatusIgnored {
+		eventType = ScalingEventTriggerIgnored
+	}
+	return &ScalingEvent{
+		AppId:     history.AppId,
+		Timestamp: history.Timestamp,
+		Type:      eventType,
+		History:   history,
+	}
+}
+
+// NewEvaluationEvent returns the event of an evaluation of the scaling rules of an app.
+func NewEvaluationEvent(appId string, timestamp int64, evaluation []RuleEvaluation) *ScalingEvent {
+	return &ScalingEvent{
+		AppId:      appId,
+		Timestamp:  timestamp,
+		Type:       ScalingEventEvaluation,
+		Evaluation: evaluation,
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+// SpaceApps is the overview of the apps of a space which are bound to the autoscaler.
+type SpaceApps struct {
+	SpaceId string     `json:"space_id"`
+	Apps    []SpaceApp `json:"apps"`
+}
+
+// OrgApps is the overview of the apps of an organization which are bound to the autoscaler, by space.
+type OrgApps struct {
+	OrgId  string      `json:"org_id"`
+	Spaces []SpaceApps `json:"spaces"`
+}
+
+// SpaceApp is the overview of an app which is bound to the autoscaler. Policy is nil if no policy is attached, and
+// Instances is nil if the app has no web process.
+type SpaceApp struct {
+	AppId       string             `json:"app_id"`
+	Instances   *int               `json:"instances"`
+	Policy      *PolicySummary     `json:"policy"`
+	LastScaling *AppScalingHistory `json:"last_scaling,omitempty"`
+}
+
+// PolicySummary is a short description of a scaling policy.
+type PolicySummary struct {
+	InstanceMin  int      `json:"instance_min_count"`
+	InstanceMax  int      `json:"instance_max_count"`
+	ScalingRules int      `json:"scaling_rules"`
+	MetricTypes  []string `json:"metric_types"`
+	Schedules    int      `json:"schedules"`
+}
+
+func NewPolicySummary(policy *PolicyDefinition) *PolicySummary {
+	summary := &PolicySummary{
+		InstanceMin:  policy.InstanceMin,
+		InstanceMax:  policy.InstanceMax,
+		ScalingRules: len(policy.ScalingRules),
+		MetricTypes:  []string{},
+	}
+	seen := map[string]bool{}
+	for _, rule := range policy.ScalingRules {
+		if !seen[rule.MetricType] {
+			seen[rule.MetricType] = true
+			summary.MetricTypes = append(summary.MetricTypes, rule.MetricType)
+		}
+	}
+	if policy.Schedules != nil {
+		summary.Schedules = len(policy.Schedules.RecurringSchedules) + len(policy.Schedules.SpecificDateSchedules)
+	}
+	return summary
+}
@@ -46,7 +46,7 @@ func (as ApplicationSynchronizer) Operate(ctx context.Context) {
 			as.logger.Error("failed-to-get-app-info", err)
 			if cf.IsNotFound(err) {
 				// Application does not exist, lets clean up app details from policyDB
-				err = as.policyDb.DeletePolicy(ctx, appID)
+				err = as.policyDb.DeletePolicyAndRevisions(ctx, appID)
 				if err != nil {
 					as.logger.Error("failed-to-prune-non-existent-application-details", err)
 					continue
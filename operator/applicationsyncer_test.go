@@ -47,7 +47,7 @@ var _ = Describe("AppSynchronizer", func() {
 			It("should not delete", func() {
 				Eventually(policyDB.GetAppIdsCallCount).Should(Equal(1))
 				Eventually(cfc.GetAppCallCount).Should(Equal(1))
-				Consistently(policyDB.DeletePolicyCallCount).Should(Equal(0))
+				Consistently(policyDB.DeletePolicyAndRevisionsCallCount).Should(Equal(0))
 			})
 		})
 
@@ -58,18 +58,18 @@ var _ = Describe("AppSynchronizer", func() {
 			It("should successfully delete", func() {
 				Eventually(policyDB.GetAppIdsCallCount).Should(Equal(1))
 				Eventually(cfc.GetAppCallCount).Should(Equal(1))
-				Eventually(policyDB.DeletePolicyCallCount).Should(Equal(1))
+				Eventually(policyDB.DeletePolicyAndRevisionsCallCount).Should(Equal(1))
 			})
 
 			When("deleting non-existent application records from policy db fails", func() {
 				BeforeEach(func() {
 					appDetails["a-second-id"] = true
-					policyDB.DeletePolicyReturns(errors.New("some error"))
+					policyDB.DeletePolicyAndRevisionsReturns(errors.New("some error"))
 				})
 				It("it should continue and try to delete the others", func() {
 					Eventually(policyDB.GetAppIdsCallCount).Should(Equal(1))
 					Eventually(cfc.GetAppCallCount).Should(Equal(2))
-					Eventually(policyDB.DeletePolicyCallCount).Should(Equal(2))
+					Eventually(policyDB.DeletePolicyAndRevisionsCallCount).Should(Equal(2))
 				})
 			})
 		})
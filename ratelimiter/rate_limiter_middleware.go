@@ -12,13 +12,24 @@ import (
 
 type RateLimiterMiddleware struct {
 	Key         string
+	keyFunc     func(r *http.Request) string
 	logger      lager.Logger
 	RateLimiter Limiter
 }
 
+// NewRateLimiterMiddleware limits the requests by the value of the route variable `key`.
 func NewRateLimiterMiddleware(key string, rateLimiter Limiter, logger lager.Logger) *RateLimiterMiddleware {
+	return NewRateLimiterMiddlewareWithKeyFunc(key, func(r *http.Request) string {
+		return mux.Vars(r)[key]
+	}, rateLimiter, logger)
+}
+
+// NewRateLimiterMiddlewareWithKeyFunc limits the requests by the value keyFunc returns for them, for requests which
+// have no suitable route variable. The key names the value in logs.
+func NewRateLimiterMiddlewareWithKeyFunc(key string, keyFunc func(r *http.Request) string, rateLimiter Limiter, logger lager.Logger) *RateLimiterMiddleware {
 	return &RateLimiterMiddleware{
 		Key:         key,
+		keyFunc:     keyFunc,
 		logger:      logger,
 		RateLimiter: rateLimiter,
 	}
@@ -26,8 +37,7 @@ func NewRateLimiterMiddleware(key string, rateLimiter Limiter, logger lager.Logg
 
 func (mw *RateLimiterMiddleware) CheckRateLimit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		key := vars[mw.Key]
+		key := mw.keyFunc(r)
 		if key == "" {
 			mw.logger.Error("Key "+mw.Key+" is not present in the request", nil, lager.Data{"url": r.URL.String()})
 			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
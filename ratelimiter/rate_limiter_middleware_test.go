@@ -70,6 +70,51 @@ var _ = Describe("RateLimiterMiddleware", func() {
 		})
 	})
 
+	Describe("CheckRateLimit with a key function", func() {
+		BeforeEach(func() {
+			rateLimiter = &fakes.FakeLimiter{}
+			rlmw = ratelimiter.NewRateLimiterMiddlewareWithKeyFunc("user", func(r *http.Request) string {
+				return r.Header.Get("X-User")
+			}, rateLimiter, lagertest.NewTestLogger("ratelimiter-middleware"))
+			router = mux.NewRouter()
+			router.HandleFunc("/ratelimit/anotherpath", GetTestHandler())
+			router.Use(rlmw.CheckRateLimit)
+
+			resp = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/ratelimit/anotherpath", nil)
+		})
+
+		JustBeforeEach(func() {
+			router.ServeHTTP(resp, req)
+		})
+
+		Context("without a key", func() {
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Missing rate limit key"}`))
+			})
+		})
+
+		Context("exceed rate limiting", func() {
+			BeforeEach(func() {
+				rateLimiter.ExceedsLimitReturns(true)
+				req.Header.Set("X-User", "a-user")
+			})
+			It("should fail with 429 for the key of the request", func() {
+				Expect(resp.Code).To(Equal(http.StatusTooManyRequests))
+				Expect(rateLimiter.ExceedsLimitArgsForCall(0)).To(Equal("a-user"))
+			})
+		})
+
+		Context("below rate limiting", func() {
+			BeforeEach(func() {
+				req.Header.Set("X-User", "a-user")
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
 })
 
 func GetTestHandler() http.HandlerFunc {
@@ -19,6 +19,33 @@ const (
 	ScalingHistoriesPath         = "/v1/apps/{guid}/scaling_histories"
 	GetScalingHistoriesRouteName = "GetScalingHistories"
 
+	InstanceHourUsagePath         = "/v1/apps/{appid}/instance_hour_usage"
+	GetInstanceHourUsageRouteName = "GetInstanceHourUsage"
+
+	ScalingAnalyticsPath         = "/v1/apps/{appid}/scaling_analytics"
+	GetScalingAnalyticsRouteName = "GetScalingAnalytics"
+
+	ScalingDecisionPath         = "/v1/apps/{appid}/scaling_decisions/{decisionid}"
+	GetScalingDecisionRouteName = "GetScalingDecision"
+
+	GroupScalingHistoriesPath         = "/v1/app_groups/{groupid}/scaling_histories"
+	GetGroupScalingHistoriesRouteName = "GetGroupScalingHistories"
+
+	ScalingEventsPath         = "/v1/apps/{appid}/scaling_events"
+	GetScalingEventsRouteName = "GetScalingEvents"
+
+	EvaluationEventsPath         = "/v1/apps/{appid}/scaling_events/evaluations"
+	SaveEvaluationEventRouteName = "SaveEvaluationEvent"
+
+	ScalingStatePath         = "/v1/apps/{appid}/scaling_state"
+	GetScalingStateRouteName = "GetScalingState"
+
+	LastScalingHistoriesPath         = "/v1/last_scaling_histories"
+	GetLastScalingHistoriesRouteName = "GetLastScalingHistories"
+
+	EvaluationStatePath         = "/v1/apps/{appid}/evaluation_state"
+	GetEvaluationStateRouteName = "GetEvaluationState"
+
 	LivenessPath      = "/v1/liveness"
 	LivenessRouteName = "Liveness"
 
@@ -45,15 +72,69 @@ const (
 	PublicApiScalingHistoryPath      = "/{appId}/scaling_histories"
 	PublicApiScalingHistoryRouteName = "GetPublicApiScalingHistories"
 
-	PublicApiMetricsHistoryPath = "/{appId}/metric_histories/{metricType}"
+	PublicApiMetricsHistoryPath      = "/{appId}/metric_histories/{metricType}"
+	PublicApiMetricsHistoryRouteName = "GetPublicApiMetricsHistories"
 
 	PublicApiAggregatedMetricsHistoryPath      = "/{appId}/aggregated_metric_histories/{metricType}"
 	PublicApiAggregatedMetricsHistoryRouteName = "GetPublicApiAggregatedMetricsHistories"
 
+	PublicApiInstanceHourUsagePath      = "/{appId}/instance_hour_usage"
+	PublicApiInstanceHourUsageRouteName = "GetPublicApiInstanceHourUsage"
+
+	PublicApiScalingAnalyticsPath      = "/{appId}/scaling_analytics"
+	PublicApiScalingAnalyticsRouteName = "GetPublicApiScalingAnalytics"
+
+	PublicApiScalingDecisionPath      = "/{appId}/scaling_decisions/{decisionId}"
+	PublicApiScalingDecisionRouteName = "GetPublicApiScalingDecision"
+
+	PublicApiScalingEventsPath      = "/{appId}/events"
+	PublicApiScalingEventsRouteName = "GetPublicApiScalingEvents"
+
+	PublicApiAppStatusPath      = "/{appId}/status"
+	PublicApiAppStatusRouteName = "GetPublicApiAppStatus"
+
+	PublicApiAppGroupPath            = "/{appId}/group"
+	PublicApiGetAppGroupRouteName    = "GetPublicApiAppGroup"
+	PublicApiSetAppGroupRouteName    = "SetPublicApiAppGroup"
+	PublicApiDeleteAppGroupRouteName = "DeletePublicApiAppGroup"
+
+	PublicApiGroupScalingHistoriesPath      = "/{appId}/group/scaling_histories"
+	PublicApiGroupScalingHistoriesRouteName = "GetPublicApiGroupScalingHistories"
+
+	PublicApiPolicyRevisionsPath      = "/{appId}/policy/revisions"
+	PublicApiPolicyRevisionsRouteName = "GetPublicApiPolicyRevisions"
+
+	PublicApiPolicyRevisionDiffPath      = "/{appId}/policy/revisions/diff"
+	PublicApiPolicyRevisionDiffRouteName = "GetPublicApiPolicyRevisionDiff"
+
+	PublicApiPolicyRollbackPath      = "/{appId}/policy/revisions/{revision:[0-9]+}/rollback"
+	PublicApiPolicyRollbackRouteName = "RollbackPublicApiPolicy"
+
+	PublicApiSchedulePreviewPath      = "/{appId}/schedule_preview"
+	PublicApiSchedulePreviewRouteName = "GetPublicApiSchedulePreview"
+
+	PublicApiSpaceAppsPath      = "/v1/spaces/{spaceId}/apps"
+	PublicApiSpaceAppsRouteName = "GetPublicApiSpaceApps"
+
+	PublicApiOrgAppsPath      = "/v1/organizations/{orgId}/apps"
+	PublicApiOrgAppsRouteName = "GetPublicApiOrgApps"
+
+	PublicApiDefaultPolicyPath            = "/v1/service_instances/{instanceId}/default_policy"
+	PublicApiGetDefaultPolicyRouteName    = "GetDefaultPolicy"
+	PublicApiSetDefaultPolicyRouteName    = "SetDefaultPolicy"
+	PublicApiDeleteDefaultPolicyRouteName = "DeleteDefaultPolicy"
+
 	PublicApiPolicyPath            = "/v1/apps/{appId:.+}/policy"
 	PublicApiGetPolicyRouteName    = "GetPolicy"
 	PublicApiAttachPolicyRouteName = "AttachPolicy"
 	PublicApiDetachPolicyRouteName = "DetachPolicy"
+	PublicApiPatchPolicyRouteName  = "PatchPolicy"
+
+	PublicApiValidatePolicyPath      = "/v1/policy/validate"
+	PublicApiValidatePolicyRouteName = "ValidatePolicy"
+
+	PublicApiPreviewPolicySchedulesPath      = "/v1/policy/schedule_preview"
+	PublicApiPreviewPolicySchedulesRouteName = "PreviewPolicySchedules"
 
 	PublicApiInfoPath      = "/v1/info"
 	PublicApiInfoRouteName = "GetPublicApiInfo"
@@ -80,7 +161,12 @@ func (r *Router) RegisterRoutes() {
 	r.CreateEventGeneratorSubrouter()
 	r.CreateApiPublicSubrouter()
 	r.CreateApiSubrouter()
+	r.CreateApiSpaceSubrouter()
+	r.CreateApiOrgSubrouter()
+	r.CreateApiDefaultPolicySubrouter()
 	r.CreateApiPolicySubrouter()
+	r.CreateApiPolicyValidationSubrouter()
+	r.CreateApiPolicySchedulePreviewSubrouter()
 }
 
 func (r *Router) CreateScalingEngineRoutes() *mux.Router {
@@ -90,6 +176,14 @@ func (r *Router) CreateScalingEngineRoutes() *mux.Router {
 	r.router.Path(ActiveSchedulePath).Methods(http.MethodDelete).Name(DeleteActiveScheduleRouteName)
 	r.router.Path(ActiveSchedulesPath).Methods(http.MethodGet).Name(GetActiveSchedulesRouteName)
 	r.router.Path(SyncActiveSchedulesPath).Methods(http.MethodPut).Name(SyncActiveSchedulesRouteName)
+	r.router.Path(InstanceHourUsagePath).Methods(http.MethodGet).Name(GetInstanceHourUsageRouteName)
+	r.router.Path(ScalingAnalyticsPath).Methods(http.MethodGet).Name(GetScalingAnalyticsRouteName)
+	r.router.Path(ScalingDecisionPath).Methods(http.MethodGet).Name(GetScalingDecisionRouteName)
+	r.router.Path(GroupScalingHistoriesPath).Methods(http.MethodGet).Name(GetGroupScalingHistoriesRouteName)
+	r.router.Path(ScalingEventsPath).Methods(http.MethodGet).Name(GetScalingEventsRouteName)
+	r.router.Path(EvaluationEventsPath).Methods(http.MethodPost).Name(SaveEvaluationEventRouteName)
+	r.router.Path(ScalingStatePath).Methods(http.MethodGet).Name(GetScalingStateRouteName)
+	r.router.Path(LastScalingHistoriesPath).Methods(http.MethodGet).Name(GetLastScalingHistoriesRouteName)
 	r.router.Path(LivenessPath).Methods(http.MethodGet).Name(LivenessRouteName)
 
 	return r.router
@@ -111,6 +205,8 @@ func (r *Router) registerSchedulerRoutes() {
 func (r *Router) CreateEventGeneratorSubrouter() *mux.Router {
 	eventgeneratorRoutes := r.router.PathPrefix("").Subrouter()
 	eventgeneratorRoutes.Path(AggregatedMetricHistoriesPath).Methods(http.MethodGet).Name(GetAggregatedMetricHistoriesRouteName)
+	eventgeneratorRoutes.Path(MetricHistoriesPath).Methods(http.MethodGet).Name(GetMetricHistoriesRouteName)
+	eventgeneratorRoutes.Path(EvaluationStatePath).Methods(http.MethodGet).Name(GetEvaluationStateRouteName)
 	eventgeneratorRoutes.Path(LivenessPath).Methods(http.MethodGet).Name(LivenessRouteName)
 	return eventgeneratorRoutes
 }
@@ -126,18 +222,65 @@ func (r *Router) CreateApiPublicSubrouter() *mux.Router {
 func (r *Router) CreateApiSubrouter() *mux.Router {
 	apiRoutes := r.router.PathPrefix("/v1/apps").Subrouter()
 	apiRoutes.Path(PublicApiScalingHistoryPath).Methods(http.MethodGet).Name(PublicApiScalingHistoryRouteName)
+	apiRoutes.Path(PublicApiMetricsHistoryPath).Methods(http.MethodGet).Name(PublicApiMetricsHistoryRouteName)
 	apiRoutes.Path(PublicApiAggregatedMetricsHistoryPath).Methods(http.MethodGet).Name(PublicApiAggregatedMetricsHistoryRouteName)
+	apiRoutes.Path(PublicApiInstanceHourUsagePath).Methods(http.MethodGet).Name(PublicApiInstanceHourUsageRouteName)
+	apiRoutes.Path(PublicApiScalingAnalyticsPath).Methods(http.MethodGet).Name(PublicApiScalingAnalyticsRouteName)
+	apiRoutes.Path(PublicApiScalingDecisionPath).Methods(http.MethodGet).Name(PublicApiScalingDecisionRouteName)
+	apiRoutes.Path(PublicApiScalingEventsPath).Methods(http.MethodGet).Name(PublicApiScalingEventsRouteName)
+	apiRoutes.Path(PublicApiAppStatusPath).Methods(http.MethodGet).Name(PublicApiAppStatusRouteName)
+	apiRoutes.Path(PublicApiPolicyRevisionsPath).Methods(http.MethodGet).Name(PublicApiPolicyRevisionsRouteName)
+	apiRoutes.Path(PublicApiPolicyRevisionDiffPath).Methods(http.MethodGet).Name(PublicApiPolicyRevisionDiffRouteName)
+	apiRoutes.Path(PublicApiPolicyRollbackPath).Methods(http.MethodPost).Name(PublicApiPolicyRollbackRouteName)
+	apiRoutes.Path(PublicApiSchedulePreviewPath).Methods(http.MethodGet).Name(PublicApiSchedulePreviewRouteName)
+	apiRoutes.Path(PublicApiAppGroupPath).Methods(http.MethodGet).Name(PublicApiGetAppGroupRouteName)
+	apiRoutes.Path(PublicApiAppGroupPath).Methods(http.MethodPut).Name(PublicApiSetAppGroupRouteName)
+	apiRoutes.Path(PublicApiAppGroupPath).Methods(http.MethodDelete).Name(PublicApiDeleteAppGroupRouteName)
+	apiRoutes.Path(PublicApiGroupScalingHistoriesPath).Methods(http.MethodGet).Name(PublicApiGroupScalingHistoriesRouteName)
 	return apiRoutes
 }
 
+func (r *Router) CreateApiSpaceSubrouter() *mux.Router {
+	apiSpaceRoutes := r.router.Path(PublicApiSpaceAppsPath).Subrouter()
+	apiSpaceRoutes.Path("").Methods(http.MethodGet).Name(PublicApiSpaceAppsRouteName)
+	return apiSpaceRoutes
+}
+
+func (r *Router) CreateApiOrgSubrouter() *mux.Router {
+	apiOrgRoutes := r.router.Path(PublicApiOrgAppsPath).Subrouter()
+	apiOrgRoutes.Path("").Methods(http.MethodGet).Name(PublicApiOrgAppsRouteName)
+	return apiOrgRoutes
+}
+
+func (r *Router) CreateApiDefaultPolicySubrouter() *mux.Router {
+	apiDefaultPolicyRoutes := r.router.Path(PublicApiDefaultPolicyPath).Subrouter()
+	apiDefaultPolicyRoutes.Path("").Methods(http.MethodGet).Name(PublicApiGetDefaultPolicyRouteName)
+	apiDefaultPolicyRoutes.Path("").Methods(http.MethodPut).Name(PublicApiSetDefaultPolicyRouteName)
+	apiDefaultPolicyRoutes.Path("").Methods(http.MethodDelete).Name(PublicApiDeleteDefaultPolicyRouteName)
+	return apiDefaultPolicyRoutes
+}
+
 func (r *Router) CreateApiPolicySubrouter() *mux.Router {
 	apiPolicyRoutes := r.router.Path(PublicApiPolicyPath).Subrouter()
 	apiPolicyRoutes.Path("").Methods(http.MethodGet).Name(PublicApiGetPolicyRouteName)
 	apiPolicyRoutes.Path("").Methods(http.MethodPut).Name(PublicApiAttachPolicyRouteName)
 	apiPolicyRoutes.Path("").Methods(http.MethodDelete).Name(PublicApiDetachPolicyRouteName)
+	apiPolicyRoutes.Path("").Methods(http.MethodPatch).Name(PublicApiPatchPolicyRouteName)
 	return apiPolicyRoutes
 }
 
+func (r *Router) CreateApiPolicyValidationSubrouter() *mux.Router {
+	apiPolicyValidationRoutes := r.router.Path(PublicApiValidatePolicyPath).Subrouter()
+	apiPolicyValidationRoutes.Path("").Methods(http.MethodPost).Name(PublicApiValidatePolicyRouteName)
+	return apiPolicyValidationRoutes
+}
+
+func (r *Router) CreateApiPolicySchedulePreviewSubrouter() *mux.Router {
+	apiPolicySchedulePreviewRoutes := r.router.Path(PublicApiPreviewPolicySchedulesPath).Subrouter()
+	apiPolicySchedulePreviewRoutes.Path("").Methods(http.MethodPost).Name(PublicApiPreviewPolicySchedulesRouteName)
+	return apiPolicySchedulePreviewRoutes
+}
+
 func (r *Router) GetRouter() *mux.Router {
 	return r.router
 }
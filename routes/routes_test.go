@@ -106,6 +106,14 @@ var _ = Describe("Routes", func() {
 				})
 			})
 		})
+		Context("PublicApiMetricsHistoryRouteName", func() {
+			It("should return the correct path", func() {
+				path, err := router.Get(routes.PublicApiMetricsHistoryRouteName).URLPath("appId", testAppId, "metricType", testMetricType)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/metric_histories/" + testMetricType))
+			})
+		})
+
 		Context("PublicApiAggregatedMetricsHistoryRouteName", func() {
 
 			Context("when provide correct route variable", func() {
@@ -132,6 +140,186 @@ var _ = Describe("Routes", func() {
 			})
 		})
 
+		Context("PublicApiInstanceHourUsageRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiInstanceHourUsageRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/instance_hour_usage"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiInstanceHourUsageRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiScalingAnalyticsRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiScalingAnalyticsRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_analytics"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiScalingAnalyticsRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiAppStatusRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiAppStatusRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/status"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiAppStatusRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiScalingDecisionRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiScalingDecisionRouteName).URLPath("appId", testAppId, "decisionId", "a-decision-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_decisions/a-decision-id"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiScalingDecisionRouteName).URLPath("appId", testAppId)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiGetAppGroupRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiGetAppGroupRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/group"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiGetAppGroupRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiGroupScalingHistoriesRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiGroupScalingHistoriesRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/group/scaling_histories"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiGroupScalingHistoriesRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiScalingEventsRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiScalingEventsRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/events"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiScalingEventsRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiPolicyRevisionsRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiPolicyRevisionsRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy/revisions"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiPolicyRevisionsRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiPolicyRevisionDiffRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiPolicyRevisionDiffRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy/revisions/diff"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiPolicyRevisionDiffRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiPolicyRollbackRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiPolicyRollbackRouteName).URLPath("appId", testAppId, "revision", "3")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy/revisions/3/rollback"))
+				})
+			})
+
+			Context("when the revision is not a number", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiPolicyRollbackRouteName).URLPath("appId", testAppId, "revision", "latest")
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiSchedulePreviewRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiSchedulePreviewRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/schedule_preview"))
+				})
+			})
+		})
+
 		Context("PublicApiGetPolicyRouteName", func() {
 
 			Context("when provide correct route variable", func() {
@@ -209,6 +397,133 @@ var _ = Describe("Routes", func() {
 				})
 			})
 		})
+
+		Context("PublicApiPatchPolicyRouteName", func() {
+
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiPolicyRoutes().Get(routes.PublicApiPatchPolicyRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiPolicyRoutes().Get(routes.PublicApiPatchPolicyRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiValidatePolicyRouteName", func() {
+			It("should return the correct path", func() {
+				path, err := routes.ApiPolicyRoutes().Get(routes.PublicApiValidatePolicyRouteName).URLPath()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path.Path).To(Equal("/v1/policy/validate"))
+			})
+		})
+
+		Context("PublicApiPreviewPolicySchedulesRouteName", func() {
+			It("should return the correct path", func() {
+				path, err := routes.ApiPolicyRoutes().Get(routes.PublicApiPreviewPolicySchedulesRouteName).URLPath()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path.Path).To(Equal("/v1/policy/schedule_preview"))
+			})
+		})
+	})
+
+	Describe("ApiSpaceRoutes", func() {
+		JustBeforeEach(func() {
+			autoscalerRouter.CreateApiSpaceSubrouter()
+		})
+
+		Context("PublicApiSpaceAppsRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiSpaceAppsRouteName).URLPath("spaceId", "a-space-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/spaces/a-space-id/apps"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiSpaceAppsRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Describe("ApiOrgRoutes", func() {
+		JustBeforeEach(func() {
+			autoscalerRouter.CreateApiOrgSubrouter()
+		})
+
+		Context("PublicApiOrgAppsRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiOrgAppsRouteName).URLPath("orgId", "an-org-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/organizations/an-org-id/apps"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiOrgAppsRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+
+			Context("when using the registered routes", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiPolicyRoutes().Get(routes.PublicApiOrgAppsRouteName).URLPath("orgId", "an-org-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/organizations/an-org-id/apps"))
+				})
+			})
+		})
+	})
+
+	Describe("ApiDefaultPolicyRoutes", func() {
+		JustBeforeEach(func() {
+			autoscalerRouter.CreateApiDefaultPolicySubrouter()
+		})
+
+		Context("PublicApiGetDefaultPolicyRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.PublicApiGetDefaultPolicyRouteName).URLPath("instanceId", "an-instance-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/service_instances/an-instance-id/default_policy"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.PublicApiGetDefaultPolicyRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiSetDefaultPolicyRouteName", func() {
+			It("should return the correct path", func() {
+				path, err := router.Get(routes.PublicApiSetDefaultPolicyRouteName).URLPath("instanceId", "an-instance-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path.Path).To(Equal("/v1/service_instances/an-instance-id/default_policy"))
+			})
+		})
+
+		Context("PublicApiDeleteDefaultPolicyRouteName", func() {
+			It("should return the correct path", func() {
+				path, err := router.Get(routes.PublicApiDeleteDefaultPolicyRouteName).URLPath("instanceId", "an-instance-id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path.Path).To(Equal("/v1/service_instances/an-instance-id/default_policy"))
+			})
+		})
 	})
 
 	Describe("CreateEventGeneratorRoutes", func() {
@@ -242,6 +557,31 @@ var _ = Describe("Routes", func() {
 			})
 		})
 
+		Context("GetEvaluationStateRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.GetEvaluationStateRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/evaluation_state"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.GetEvaluationStateRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("GetMetricHistoriesRouteName", func() {
+			It("should return the correct path", func() {
+				path, err := router.Get(routes.GetMetricHistoriesRouteName).URLPath("appid", testAppId, "metrictype", testMetricType)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/metric_histories/" + testMetricType))
+			})
+		})
+
 	})
 
 	Describe("CreateScalingEngineRoutes", func() {
@@ -274,6 +614,31 @@ var _ = Describe("Routes", func() {
 			})
 		})
 
+		Context("GetScalingStateRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.GetScalingStateRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_state"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.GetScalingStateRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("GetLastScalingHistoriesRouteName", func() {
+			It("should return the correct path", func() {
+				path, err := router.Get(routes.GetLastScalingHistoriesRouteName).URLPath()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path.Path).To(Equal("/v1/last_scaling_histories"))
+			})
+		})
+
 		Context("GetScalingHistoriesRoute", func() {
 			Context("when provide correct route variable", func() {
 				It("should return the correct path", func() {
@@ -300,6 +665,74 @@ var _ = Describe("Routes", func() {
 			})
 		})
 
+		Context("GetScalingDecisionRoute", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.GetScalingDecisionRouteName).URLPath("appid", testAppId, "decisionid", "a-decision-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_decisions/a-decision-id"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.GetScalingDecisionRouteName).URLPath("appid", testAppId)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("GetGroupScalingHistoriesRoute", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.GetGroupScalingHistoriesRouteName).URLPath("groupid", "a-group-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/app_groups/a-group-id/scaling_histories"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.GetGroupScalingHistoriesRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("GetScalingEventsRoute", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.GetScalingEventsRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_events"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.GetScalingEventsRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("SaveEvaluationEventRoute", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := router.Get(routes.SaveEvaluationEventRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_events/evaluations"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := router.Get(routes.SaveEvaluationEventRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
 		Context("SetActiveScheduleRoute", func() {
 			Context("when provide correct route variable", func() {
 				It("should return the correct path", func() {
@@ -31,7 +31,7 @@ func main() {
 	cfClient := startup.CreateAndLoginCFClient(&conf.CF, logger)
 
 	// Business logic
-	scalingEngine := scalingengine.NewScalingEngine(logger, cfClient, policyDb.DB, scalingEngineDB.DB, clock, conf.DefaultCoolDownSecs, conf.LockSize)
+	scalingEngine := scalingengine.NewScalingEngine(logger, cfClient, policyDb.DB, scalingEngineDB.DB, clock, conf.DefaultCoolDownSecs, conf.MaxEmergencyScalingsPerHour, conf.LockSize)
 	synchronizer := schedule.NewActiveScheduleSychronizer(logger, schedulerDB.DB, scalingEngineDB.DB, scalingEngine)
 
 	// Server setup
@@ -30,6 +30,9 @@ type Config struct {
 	DefaultCoolDownSecs   int           `yaml:"defaultCoolDownSecs"`
 	LockSize              int           `yaml:"lockSize"`
 	HttpClientTimeout     time.Duration `yaml:"http_client_timeout"`
+	// MaxEmergencyScalingsPerHour limits the scalings per app and hour that skip the cool-down
+	// because an emergency threshold was breached. 0 disables emergency scalings.
+	MaxEmergencyScalingsPerHour int `yaml:"max_emergency_scalings_per_hour"`
 }
 
 func defaultConfig() Config {
@@ -54,9 +57,10 @@ func defaultConfig() Config {
 		CF: cf.Config{
 			ClientConfig: cf.ClientConfig{SkipSSLValidation: false},
 		},
-		DefaultCoolDownSecs: 300,
-		LockSize:            100,
-		HttpClientTimeout:   DefaultHttpClientTimeout,
+		DefaultCoolDownSecs:         300,
+		LockSize:                    100,
+		HttpClientTimeout:           DefaultHttpClientTimeout,
+		MaxEmergencyScalingsPerHour: 3,
 	}
 }
 
@@ -100,6 +104,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("Configuration error: LockSize is less than or equal to 0")
 	}
 
+	if c.MaxEmergencyScalingsPerHour < 0 {
+		return fmt.Errorf("Configuration error: max_emergency_scalings_per_hour is less than 0")
+	}
+
 	if c.HttpClientTimeout <= time.Duration(0) {
 		return fmt.Errorf("Configuration error: http_client_timeout is less-equal than 0")
 	}
@@ -148,6 +148,8 @@ var _ = Describe("Config", func() {
 					Expect(conf.LockSize).To(Equal(32))
 
 					Expect(conf.HttpClientTimeout).To(Equal(10 * time.Second))
+
+					Expect(conf.MaxEmergencyScalingsPerHour).To(Equal(5))
 				})
 			})
 
@@ -185,6 +187,7 @@ var _ = Describe("Config", func() {
 						}))
 
 					Expect(conf.HttpClientTimeout).To(Equal(5 * time.Second))
+					Expect(conf.MaxEmergencyScalingsPerHour).To(Equal(3))
 					Expect(conf.Health.ServerConfig.Port).To(Equal(8081))
 					Expect(conf.Server.Port).To(Equal(8080))
 				})
@@ -364,6 +367,16 @@ health:
 				})
 			})
 
+			When("max_emergency_scalings_per_hour < 0", func() {
+				BeforeEach(func() {
+					conf.MaxEmergencyScalingsPerHour = -1
+				})
+
+				It("should error", func() {
+					Expect(err).To(MatchError("Configuration error: max_emergency_scalings_per_hour is less than 0"))
+				})
+			})
+
 			When("HttpClientTimeout is <= 0", func() {
 				BeforeEach(func() {
 					conf.HttpClientTimeout = 0
@@ -0,0 +1,139 @@
+package scalingengine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// scaleGroup scales the leader of an app group to newInstances and all members of the group to the instances which
+// follow from it. The members are read before any app is scaled, so that the group is not scaled at all if one of them
+// cannot be read. Members which no longer exist are ignored, as they are removed from the group once they are unbound,
+// which may happen only after they have been deleted. If a member cannot be scaled, the remaining members are skipped and the apps which have been scaled
+// already are scaled back to their previous instances in reverse order. The outcome is recorded as a group scaling
+// history. The returned error is the error of the leader or of the member which could not be scaled.
+func (s *scalingEngine) scaleGroup(ctx context.Context, logger lager.Logger, group *models.AppGroup, history *models.AppScalingHistory, newInstances int) error {
+	logger = logger.Session("scale-group", lager.Data{"groupId": group.GroupId})
+	ctx = cf.ContextWithRequestId(ctx, history.DecisionId)
+
+	groupHistory := &models.GroupScalingHistory{
+		GroupId:    group.GroupId,
+		Timestamp:  history.Timestamp,
+		MetricType: history.MetricType,
+		Reason:     history.Reason,
+		Apps: []models.GroupMemberScaling{{
+			AppId:        group.LeaderAppId(),
+			OldInstances: history.OldInstances,
+			NewInstances: newInstances,
+			Status:       models.GroupMemberSkipped,
+		}},
+	}
+	defer func() {
+		err := s.scalingEngineDB.SaveGroupScalingHistory(ctx, groupHistory)
+		if err != nil {
+			logger.Error("failed-to-save-group-scaling-history", err)
+		}
+	}()
+
+	for i, member := range group.Members {
+		scaling, err := s.prepareGroupMemberScaling(ctx, member, newInstances)
+		if cf.IsNotFound(err) {
+			logger.Info("ignore-member-which-does-not-exist", lager.Data{"memberAppId": member.AppId})
+			scaling = models.GroupMemberScaling{AppId: member.AppId, OldInstances: -1, NewInstances: -1, Status: models.GroupMemberIgnored, Message: "app does not exist"}
+			err = nil
+		}
+		if err != nil {
+			logger.Error("failed-to-get-member-app-info", err, lager.Data{"memberAppId": member.AppId})
+			err = fmt.Errorf("failed to get app info of member %s of group %s: %w", member.AppId, group.GroupId, err)
+			groupHistory.Status = models.GroupScalingFailed
+			groupHistory.Error = err.Error()
+			groupHistory.Apps = append(groupHistory.Apps, models.GroupMemberScaling{AppId: member.AppId, OldInstances: -1, NewInstances: -1, Status: models.GroupMemberFailed, Error: err.Error()})
+			for _, member := range group.Members[i+1:] {
+				groupHistory.Apps = append(groupHistory.Apps, models.GroupMemberScaling{AppId: member.AppId, OldInstances: -1, NewInstances: -1, Status: models.GroupMemberSkipped})
+			}
+			return err
+		}
+		groupHistory.Apps = append(groupHistory.Apps, scaling)
+	}
+
+	// apps which are still to be scaled are skipped until they have been scaled, so that they remain skipped if the
+	// scaling of another app fails
+	var scaled []int
+	for i := range groupHistory.Apps {
+		app := &groupHistory.Apps[i]
+		if app.Status != models.GroupMemberSkipped {
+			continue
+		}
+		err := s.cfClient.ScaleAppWebProcess(ctx, cf.Guid(app.AppId), app.NewInstances)
+		if err != nil {
+			logger.Error("failed-to-set-app-instances", err, lager.Data{"memberAppId": app.AppId, "newInstances": app.NewInstances})
+			app.Status = models.GroupMemberFailed
+			app.Error = err.Error()
+			if i > 0 {
+				err = fmt.Errorf("failed to scale member %s of group %s: %w", app.AppId, group.GroupId, err)
+			}
+			groupHistory.Error = err.Error()
+			groupHistory.Status = s.rollbackGroupScaling(ctx, logger, groupHistory, scaled)
+			return err
+		}
+		app.Status = models.GroupMemberScaled
+		scaled = append(scaled, i)
+	}
+
+	groupHistory.Status = models.GroupScalingSucceeded
+	return nil
+}
+
+// prepareGroupMemberScaling determines the instances of a member of an app group for the new instances of the
+// leader. Members which are not started or have the label to disable autoscaling are ignored, members which already
+// have these instances are unchanged, and all others are still to be scaled.
+func (s *scalingEngine) prepareGroupMemberScaling(ctx context.Context, member models.AppGroupMember, leaderInstances int) (models.GroupMemberScaling, error) {
+	appAndProcesses, err := s.cfClient.GetAppAndProcesses(ctx, cf.Guid(member.AppId))
+	if err != nil {
+		return models.GroupMemberScaling{}, err
+	}
+	instances := appAndProcesses.Processes.GetInstances()
+	scaling := models.GroupMemberScaling{AppId: member.AppId, OldInstances: instances, NewInstances: instances}
+
+	switch {
+	case strings.ToUpper(appAndProcesses.App.State) != models.AppStatusStarted:
+		scaling.Status = models.GroupMemberIgnored
+		scaling.Message = "app is not started"
+	case appAndProcesses.App.DisableAutoscaling != nil:
+		scaling.Status = models.GroupMemberIgnored
+		scaling.Message = "app has the label app-autoscaler.cloudfoundry.org/disable-autoscaling set"
+	case member.Instances(leaderInstances) == instances:
+		scaling.Status = models.GroupMemberUnchanged
+	default:
+		scaling.NewInstances = member.Instances(leaderInstances)
+		scaling.Status = models.GroupMemberSkipped
+	}
+	return scaling, nil
+}
+
+// rollbackGroupScaling scales the apps of the group history with the given indexes back to their previous instances
+// in reverse order. The apps which have not been scaled yet remain skipped.
+func (s *scalingEngine) rollbackGroupScaling(ctx context.Context, logger lager.Logger, groupHistory *models.GroupScalingHistory, scaled []int) models.GroupScalingStatus {
+	if len(scaled) == 0 {
+		return models.GroupScalingFailed
+	}
+
+	status := models.GroupScalingRolledBack
+	for j := len(scaled) - 1; j >= 0; j-- {
+		app := &groupHistory.Apps[scaled[j]]
+		err := s.cfClient.ScaleAppWebProcess(ctx, cf.Guid(app.AppId), app.OldInstances)
+		if err != nil {
+			logger.Error("failed-to-roll-back-app-instances", err, lager.Data{"memberAppId": app.AppId, "oldInstances": app.OldInstances})
+			app.Status = models.GroupMemberRollbackFailed
+			app.Error = "failed to roll back: " + err.Error()
+			status = models.GroupScalingPartiallyFailed
+			continue
+		}
+		app.Status = models.GroupMemberRolledBack
+	}
+	return status
+}
@@ -2,15 +2,20 @@ package scalingengine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager/v3"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ScalingEngine interface {
@@ -18,6 +23,9 @@ type ScalingEngine interface {
 	ComputeNewInstances(currentInstances int, adjustment string) (int, error)
 	SetActiveSchedule(ctx context.Context, appId string, schedule *models.ActiveSchedule) error
 	RemoveActiveSchedule(ctx context.Context, appId string, scheduleId string) error
+	GetInstanceHourUsage(ctx context.Context, appId string) (*models.InstanceHourUsage, error)
+	GetScalingAnalytics(ctx context.Context, appId string, start int64, end int64) (*models.ScalingAnalytics, error)
+	GetScalingState(ctx context.Context, appId string) (*models.AppScalingState, error)
 }
 
 type scalingEngine struct {
@@ -28,8 +36,15 @@ type scalingEngine struct {
 	appLock             *StripedLock
 	clock               clock.Clock
 	defaultCoolDownSecs int
+
+	maxEmergencyScalingsPerHour int
 }
 
+const (
+	quotaLimitedByOrg   = "limited by org quota"
+	quotaLimitedBySpace = "limited by space quota"
+)
+
 type ActiveScheduleNotFoundError struct {
 }
 type AppNotFoundError struct {
@@ -39,15 +54,16 @@ func (ase *ActiveScheduleNotFoundError) Error() string {
 	return "active schedule not found"
 }
 
-func NewScalingEngine(logger lager.Logger, cfClient cf.CFClient, policyDB db.PolicyDB, scalingEngineDB db.ScalingEngineDB, clock clock.Clock, defaultCoolDownSecs int, lockSize int) ScalingEngine {
+func NewScalingEngine(logger lager.Logger, cfClient cf.CFClient, policyDB db.PolicyDB, scalingEngineDB db.ScalingEngineDB, clock clock.Clock, defaultCoolDownSecs int, maxEmergencyScalingsPerHour int, lockSize int) ScalingEngine {
 	return &scalingEngine{
-		logger:              logger.Session("scalingEngine"),
-		cfClient:            cfClient,
-		policyDB:            policyDB,
-		scalingEngineDB:     scalingEngineDB,
-		appLock:             NewStripedLock(lockSize),
-		clock:               clock,
-		defaultCoolDownSecs: defaultCoolDownSecs,
+		logger:                      logger.Session("scalingEngine"),
+		cfClient:                    cfClient,
+		policyDB:                    policyDB,
+		scalingEngineDB:             scalingEngineDB,
+		appLock:                     NewStripedLock(lockSize),
+		clock:                       clock,
+		defaultCoolDownSecs:         defaultCoolDownSecs,
+		maxEmergencyScalingsPerHour: maxEmergencyScalingsPerHour,
 	}
 }
 
@@ -65,6 +81,26 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 		OldInstances: -1,
 		NewInstances: -1,
 		Reason:       getDynamicScalingReason(trigger),
+		Emergency:    trigger.Emergency,
+
+		RuleIndex:     trigger.RuleIndex,
+		MetricType:    trigger.MetricType,
+		ObservedValue: trigger.ObservedValue,
+		Threshold:     &trigger.Threshold,
+		Operator:      trigger.Operator,
+		Adjustment:    trigger.Adjustment,
+		CorrelationId: correlationId(ctx),
+		DecisionId:    helpers.DecisionIdFromContext(ctx),
+	}
+
+	var decision *models.DecisionTrace
+	if history.DecisionId != "" {
+		decision = &models.DecisionTrace{
+			DecisionId: history.DecisionId,
+			AppId:      appId,
+			Timestamp:  history.Timestamp,
+			Evaluation: trigger.Evaluation,
+		}
 	}
 
 	defer func() {
@@ -72,6 +108,13 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 		if err != nil {
 			s.logger.Error("Scale failed to save history", err)
 		}
+		s.saveScalingEvent(ctx, models.ScalingEventScaling, history)
+		if decision != nil {
+			err = s.scalingEngineDB.SaveScalingDecision(ctx, decision)
+			if err != nil {
+				s.logger.Error("Scale failed to save decision", err, lager.Data{"decisionId": decision.DecisionId})
+			}
+		}
 	}()
 
 	result := &models.AppScalingResult{
@@ -117,7 +160,33 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 		return result, nil
 	}
 
-	ok, expiredAt, err := s.scalingEngineDB.CanScaleApp(appId)
+	group, err := s.policyDB.GetAppGroupByAppId(ctx, appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-group", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get app group"
+		return nil, err
+	}
+	if group != nil {
+		// members of an app group are only scaled together with the leader, by the leading metric
+		message := ""
+		if group.LeaderAppId() != appId {
+			message = fmt.Sprintf("app is scaled with the leader %s of app group %s", group.LeaderAppId(), group.GroupId)
+		} else if trigger.MetricType != group.LeadingMetric.MetricType {
+			message = fmt.Sprintf("app group %s is only scaled by the leading metric %s", group.GroupId, group.LeadingMetric.MetricType)
+		}
+		if message != "" {
+			logger.Info("check-app-group", lager.Data{"message": "ignore scaling since " + message})
+			history.Status = models.ScalingStatusIgnored
+			history.NewInstances = instances
+			history.Message = message
+			result.Status = history.Status
+			return result, nil
+		}
+	}
+
+	direction := trigger.Direction()
+	ok, expiredAt, err := s.scalingEngineDB.CanScaleApp(appId, direction)
 	if err != nil {
 		logger.Error("failed-to-check-cooldown", err)
 		history.Status = models.ScalingStatusFailed
@@ -125,11 +194,27 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 		return nil, err
 	}
 	result.CooldownExpiredAt = expiredAt
+	if !ok && trigger.Emergency {
+		ok, err = s.emergencyScalingAllowed(ctx, appId, now)
+		if err != nil {
+			logger.Error("failed-to-count-emergency-scalings", err)
+			history.Status = models.ScalingStatusFailed
+			history.Error = "failed to count emergency scalings"
+			return nil, err
+		}
+		if ok {
+			logger.Info("emergency scaling: skipping cooldown", lager.Data{"direction": direction})
+		}
+	}
 	if !ok {
-		logger.Info("scaling ignored: App in cooldown")
+		logger.Info("scaling ignored: App in cooldown", lager.Data{"direction": direction})
 		history.Status = models.ScalingStatusIgnored
 		history.NewInstances = instances
-		history.Message = "app in cooldown period"
+		history.Message = fmt.Sprintf("app in scale-%s cooldown period", direction)
+		history.CooldownExpiredAt = expiredAt
+		if trigger.Emergency {
+			history.Message += fmt.Sprintf(", limit of %d emergency scalings per hour reached", s.maxEmergencyScalingsPerHour)
+		}
 		result.Status = history.Status
 		return result, nil
 	}
@@ -149,6 +234,17 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 		history.Error = "failed to get active schedule"
 		return nil, err
 	}
+	if schedule != nil {
+		history.ScheduleId = schedule.ScheduleId
+	}
+
+	policy, err := s.policyDB.GetAppPolicy(ctx, appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-policy", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get scaling policy"
+		return nil, err
+	}
 
 	var instanceMin, instanceMax int
 
@@ -156,13 +252,6 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 		instanceMin = schedule.InstanceMin
 		instanceMax = schedule.InstanceMax
 	} else {
-		policy, err := s.policyDB.GetAppPolicy(ctx, appId)
-		if err != nil {
-			logger.Error("failed-to-get-app-policy", err)
-			history.Status = models.ScalingStatusFailed
-			history.Error = "failed to get scaling policy"
-			return nil, err
-		}
 		if policy == nil {
 			logger.Info("check-get-app-policy", lager.Data{"message": "ignore scaling since app does not have scaling policy"})
 			history.Status = models.ScalingStatusIgnored
@@ -194,9 +283,73 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 		return result, nil
 	}
 
-	err = s.cfClient.ScaleAppWebProcess(ctx, cf.Guid(appId), newInstances)
+	if policy != nil {
+		if mode := policy.BlackoutPeriods.ActiveMode(now); mode.Suppresses(instances, newInstances) {
+			logger.Info("check-blackout-period", lager.Data{"message": "ignore scaling since app is in a blackout period", "mode": mode})
+			history.Status = models.ScalingStatusIgnored
+			history.NewInstances = instances
+			history.Message = fmt.Sprintf("app in blackout period with mode %s", mode)
+			result.Status = history.Status
+			result.CooldownExpiredAt = 0
+			return result, nil
+		}
+	}
+
+	if newInstances > instances {
+		if limit, message := s.limitByQuota(ctx, logger, appId, appAndProcesses.Processes, instances, newInstances); limit < newInstances {
+			logger.Info("check-quota", lager.Data{"message": message, "newInstances": newInstances, "limit": limit})
+			newInstances = limit
+			history.NewInstances = newInstances
+			history.Message = message
+			if newInstances == instances {
+				history.Status = models.ScalingStatusIgnored
+				result.Status = history.Status
+				result.CooldownExpiredAt = 0
+				return result, nil
+			}
+		}
+	}
+
+	if newInstances > instances && policy != nil && policy.InstanceHourBudget != nil {
+		budget := *policy.InstanceHourBudget
+		usage, err := s.instanceHourUsage(ctx, appId, budget, instances, now)
+		if err != nil {
+			logger.Error("failed-to-get-instance-hour-usage", err)
+			history.Status = models.ScalingStatusFailed
+			history.Error = "failed to get instance-hour usage"
+			return nil, err
+		}
+		if !usage.Allows(instances, newInstances, now) {
+			logger.Info("check-instance-hour-budget", lager.Data{"message": "ignore scaling since it would exceed the instance-hour budget", "usage": usage})
+			history.Status = models.ScalingStatusIgnored
+			history.NewInstances = instances
+			history.Message = fmt.Sprintf("exceeds instance-hour budget of %d per %s", budget.InstanceHours, budget.Period)
+			result.Status = history.Status
+			result.CooldownExpiredAt = 0
+			return result, nil
+		}
+	}
+
+	if group != nil {
+		err = s.scaleGroup(ctx, logger, group, history, newInstances)
+	} else {
+		err = s.cfClient.ScaleAppWebProcess(cf.ContextWithRequestId(ctx, history.DecisionId), cf.Guid(appId), newInstances)
+	}
+	if decision != nil {
+		decision.CFResponse = newCFScalingResponse(newInstances, err)
+	}
 	if err != nil {
 		logger.Error("failed-to-set-app-instances", err, lager.Data{"newInstances": newInstances})
+		if message, exceeded := quotaExceededMessage(err); exceeded {
+			// the quota was exhausted concurrently, this is not an error of the autoscaler
+			history.Status = models.ScalingStatusFailed
+			history.NewInstances = instances
+			history.Message = message
+			history.Error = "failed to set app instances: " + err.Error()
+			result.Status = history.Status
+			result.CooldownExpiredAt = 0
+			return result, nil
+		}
 		history.Status = models.ScalingStatusFailed
 		history.Error = "failed to set app instances: " + err.Error()
 		return nil, err
@@ -206,13 +359,220 @@ func (s *scalingEngine) Scale(ctx context.Context, appId string, trigger *models
 	result.Status = history.Status
 	result.Adjustment = newInstances - instances
 	result.CooldownExpiredAt = now.Add(trigger.CoolDown(s.defaultCoolDownSecs)).UnixNano()
-	err = s.scalingEngineDB.UpdateScalingCooldownExpireTime(appId, result.CooldownExpiredAt)
+	history.CooldownExpiredAt = result.CooldownExpiredAt
+	err = s.scalingEngineDB.UpdateScalingCooldownExpireTime(appId, direction, result.CooldownExpiredAt)
 	if err != nil {
 		logger.Error("failed-to-update-scaling-cool-down-expire-time", err, lager.Data{"newInstances": newInstances})
 	}
 	return result, nil
 }
 
+// emergencyScalingAllowed reports whether an emergency scaling may skip the cool-down of the app,
+// i.e. whether the limit of emergency scalings within the last hour is not reached yet.
+func (s *scalingEngine) emergencyScalingAllowed(ctx context.Context, appId string, now time.Time) (bool, error) {
+	if s.maxEmergencyScalingsPerHour <= 0 {
+		return false, nil
+	}
+	count, err := s.scalingEngineDB.CountEmergencyScalings(ctx, appId, now.Add(-time.Hour).UnixNano(), now.UnixNano())
+	if err != nil {
+		return false, err
+	}
+	return count < s.maxEmergencyScalingsPerHour, nil
+}
+
+func (s *scalingEngine) GetInstanceHourUsage(ctx context.Context, appId string) (*models.InstanceHourUsage, error) {
+	policy, err := s.policyDB.GetAppPolicy(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scaling policy: %w", err)
+	}
+	if policy == nil || policy.InstanceHourBudget == nil {
+		return nil, nil
+	}
+
+	processes, err := s.cfClient.GetAppProcesses(ctx, cf.Guid(appId), cf.ProcessTypeWeb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app processes: %w", err)
+	}
+
+	return s.instanceHourUsage(ctx, appId, *policy.InstanceHourBudget, processes.GetInstances(), s.clock.Now())
+}
+
+// GetScalingAnalytics aggregates the scaling history of an app in the given time range, an end
+// below 0 means now. It returns nil if the app has no policy.
+func (s *scalingEngine) GetScalingAnalytics(ctx context.Context, appId string, start int64, end int64) (*models.ScalingAnalytics, error) {
+	policy, err := s.policyDB.GetAppPolicy(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scaling policy: %w", err)
+	}
+	if policy == nil {
+		return nil, nil
+	}
+
+	processes, err := s.cfClient.GetAppProcesses(ctx, cf.Guid(appId), cf.ProcessTypeWeb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app processes: %w", err)
+	}
+
+	if end < 0 {
+		end = s.clock.Now().UnixNano()
+	}
+	analytics, err := s.scalingEngineDB.RetrieveScalingAnalytics(ctx, appId, start, end, processes.GetInstances(), policy.InstanceMin, policy.InstanceMax)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve scaling analytics: %w", err)
+	}
+	return analytics, nil
+}
+
+// GetScalingState returns the instance limits that apply to an app now, its running cool-downs and its last scaling.
+// It returns nil if the app has no policy.
+func (s *scalingEngine) GetScalingState(ctx context.Context, appId string) (*models.AppScalingState, error) {
+	policy, err := s.policyDB.GetAppPolicy(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scaling policy: %w", err)
+	}
+	if policy == nil {
+		return nil, nil
+	}
+
+	appAndProcesses, err := s.cfClient.GetAppAndProcesses(ctx, cf.Guid(appId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app info: %w", err)
+	}
+
+	state := &models.AppScalingState{
+		AppId:              appId,
+		AppState:           strings.ToUpper(appAndProcesses.App.State),
+		Instances:          appAndProcesses.Processes.GetInstances(),
+		InstanceMin:        policy.InstanceMin,
+		InstanceMax:        policy.InstanceMax,
+		DisableAutoscaling: appAndProcesses.App.DisableAutoscaling,
+		Cooldowns:          []models.Cooldown{},
+	}
+
+	state.ActiveSchedule, err = s.scalingEngineDB.GetActiveSchedule(appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active schedule: %w", err)
+	}
+	if state.ActiveSchedule != nil {
+		state.InstanceMin = state.ActiveSchedule.InstanceMin
+		state.InstanceMax = state.ActiveSchedule.InstanceMax
+	}
+
+	for _, direction := range []models.ScalingDirection{models.ScalingDirectionOut, models.ScalingDirectionIn} {
+		canScale, expiredAt, err := s.scalingEngineDB.CanScaleApp(appId, direction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check cooldown: %w", err)
+		}
+		if !canScale {
+			state.Cooldowns = append(state.Cooldowns, models.Cooldown{Direction: direction, ExpiredAt: expiredAt})
+		}
+	}
+
+	// ignored triggers did not scale the app, so only succeeded and failed scalings count as the last scaling
+	lastScaling := db.ScalingHistoryFilter{Statuses: []models.ScalingStatus{models.ScalingStatusSucceeded, models.ScalingStatusFailed}}
+	histories, err := s.scalingEngineDB.RetrieveScalingHistories(ctx, appId, 0, s.clock.Now().UnixNano(), db.DESC, lastScaling, 1, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve last scaling: %w", err)
+	}
+	if len(histories) > 0 {
+		state.LastScaling = histories[0]
+	}
+	return state, nil
+}
+
+// instanceHourUsage returns the usage of the instance-hour budget of an app. Apps bound with the default policy of
+// their service instance share its guid, so its budget is consumed by all of them together. Apps which no longer
+// exist are skipped.
+func (s *scalingEngine) instanceHourUsage(ctx context.Context, appId string, budget models.InstanceHourBudget, currentInstances int, now time.Time) (*models.InstanceHourUsage, error) {
+	usage, err := s.appInstanceHourUsage(ctx, appId, budget, currentInstances, now)
+	if err != nil {
+		return nil, err
+	}
+
+	appIds, err := s.policyDB.GetAppIdsSharingPolicy(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get apps sharing the policy: %w", err)
+	}
+	for _, otherAppId := range appIds {
+		if otherAppId == appId {
+			continue
+		}
+		processes, err := s.cfClient.GetAppProcesses(ctx, cf.Guid(otherAppId), cf.ProcessTypeWeb)
+		if cf.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get app processes of %s: %w", otherAppId, err)
+		}
+		otherUsage, err := s.appInstanceHourUsage(ctx, otherAppId, budget, processes.GetInstances(), now)
+		if err != nil {
+			return nil, err
+		}
+		usage.Add(otherAppId, otherUsage)
+	}
+	return usage, nil
+}
+
+func (s *scalingEngine) appInstanceHourUsage(ctx context.Context, appId string, budget models.InstanceHourBudget, currentInstances int, now time.Time) (*models.InstanceHourUsage, error) {
+	periodStart, _ := budget.Period.Bounds(now)
+	histories, err := s.scalingEngineDB.RetrieveInstanceChanges(ctx, appId, periodStart.UnixNano(), now.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	return models.NewInstanceHourUsage(budget, histories, currentInstances, now), nil
+}
+
+// limitByQuota returns the number of instances the app can be scaled out to without exceeding
+// the remaining org or space quota, together with the message to record if it is limited.
+// Quota lookup failures are logged only, the cloud controller still enforces the quota.
+func (s *scalingEngine) limitByQuota(ctx context.Context, logger lager.Logger, appId string, processes cf.Processes, instances int, newInstances int) (int, string) {
+	usage, err := s.cfClient.GetAppQuotaUsage(ctx, cf.Guid(appId))
+	if err != nil {
+		logger.Error("failed-to-get-quota-usage", err)
+		return newInstances, ""
+	}
+
+	memoryPerInstance := 0
+	if len(processes) > 0 {
+		memoryPerInstance = processes[0].MemoryInMb
+	}
+
+	limit, message := newInstances, ""
+	if remaining, limited := usage.Org.RemainingInstances(memoryPerInstance); limited && instances+remaining < limit {
+		limit, message = instances+remaining, quotaLimitedByOrg
+	}
+	if usage.Space != nil {
+		if remaining, limited := usage.Space.RemainingInstances(memoryPerInstance); limited && instances+remaining < limit {
+			limit, message = instances+remaining, quotaLimitedBySpace
+		}
+	}
+	return limit, message
+}
+
+// newCFScalingResponse records the outcome of setting the instances of an app in the cloud controller.
+func newCFScalingResponse(instances int, err error) *models.CFScalingResponse {
+	response := &models.CFScalingResponse{Instances: instances}
+	if err != nil {
+		response.Error = err.Error()
+		var cfError *cf.CfError
+		if errors.As(err, &cfError) {
+			response.StatusCode = cfError.StatusCode
+		}
+	}
+	return response
+}
+
+func quotaExceededMessage(err error) (string, bool) {
+	switch {
+	case cf.IsSpaceQuotaExceeded(err):
+		return quotaLimitedBySpace, true
+	case cf.IsOrgQuotaExceeded(err):
+		return quotaLimitedByOrg, true
+	default:
+		return "", false
+	}
+}
+
 func (s *scalingEngine) ComputeNewInstances(currentInstances int, adjustment string) (int, error) {
 	var newInstances int
 	if strings.HasSuffix(adjustment, "%") {
@@ -276,12 +636,16 @@ func (s *scalingEngine) SetActiveSchedule(ctx context.Context, appId string, sch
 		OldInstances: -1,
 		NewInstances: -1,
 		Reason:       getScheduledScalingReason(schedule),
+
+		ScheduleId:    schedule.ScheduleId,
+		CorrelationId: correlationId(ctx),
 	}
 	defer func() {
 		err := s.scalingEngineDB.SaveScalingHistory(history)
 		if err != nil {
 			s.logger.Error("SetActiveSchedule failed to save history", err)
 		}
+		s.saveScalingEvent(ctx, models.ScalingEventScheduleStart, history)
 	}()
 
 	processes, err := s.cfClient.GetAppProcesses(ctx, cf.Guid(appId), cf.ProcessTypeWeb)
@@ -357,12 +721,16 @@ func (s *scalingEngine) RemoveActiveSchedule(ctx context.Context, appId string,
 		OldInstances: -1,
 		NewInstances: -1,
 		Reason:       "schedule ends",
+
+		ScheduleId:    scheduleId,
+		CorrelationId: correlationId(ctx),
 	}
 	defer func() {
 		err := s.scalingEngineDB.SaveScalingHistory(history)
 		if err != nil {
 			s.logger.Error("RemoveActiveSchedule failed to save history", err)
 		}
+		s.saveScalingEvent(ctx, models.ScalingEventScheduleEnd, history)
 	}()
 
 	processes, err := s.cfClient.GetAppProcesses(ctx, cf.Guid(appId), cf.ProcessTypeWeb)
@@ -423,6 +791,24 @@ func (s *scalingEngine) RemoveActiveSchedule(ctx context.Context, appId string,
 	return nil
 }
 
+// saveScalingEvent publishes a scaling history to the live event stream of the app. The events are stored in the
+// database, from which the API instances stream them to their clients.
+func (s *scalingEngine) saveScalingEvent(ctx context.Context, eventType models.ScalingEventType, history *models.AppScalingHistory) {
+	err := s.scalingEngineDB.SaveScalingEvent(ctx, models.NewScalingEvent(eventType, history))
+	if err != nil {
+		s.logger.Error("failed-to-save-scaling-event", err, lager.Data{"appId": history.AppId, "eventType": eventType})
+	}
+}
+
+// correlationId returns the id of the trace the scaling request belongs to, so that its history can be
+// correlated with the logs of all components involved. A random id is used if the request is not traced.
+func correlationId(ctx context.Context) string {
+	if traceId := trace.SpanContextFromContext(ctx).TraceID(); traceId.IsValid() {
+		return traceId.String()
+	}
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
 func getDynamicScalingReason(trigger *models.Trigger) string {
 	return fmt.Sprintf("%s instance(s) because %s %s %d%s for %d seconds",
 		trigger.Adjustment,
@@ -11,3 +11,7 @@ func TestSchedule(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Scaling Engine Suite")
 }
+
+func ptr[T any](value T) *T {
+	return &value
+}
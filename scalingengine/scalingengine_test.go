@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine"
 	"code.cloudfoundry.org/clock/fakeclock"
@@ -15,6 +17,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ = Describe("ScalingEngine", func() {
@@ -31,17 +34,21 @@ var _ = Describe("ScalingEngine", func() {
 		trigger       *models.Trigger
 		buffer        *gbytes.Buffer
 		err           error
+
+		traceId   = trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+		tracedCtx = trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId}))
 	)
 
 	BeforeEach(func() {
 		cfc = &fakes.FakeCFClient{}
+		cfc.GetAppQuotaUsageReturns(&cf.AppQuotaUsage{}, nil)
 		policyDB = &fakes.FakePolicyDB{}
 		scalingEngineDB = &fakes.FakeScalingEngineDB{}
 
 		logger := lagertest.NewTestLogger("schedule-test")
 		buffer = logger.Buffer()
 		clock = fakeclock.NewFakeClock(time.Now())
-		scalingEngine = NewScalingEngine(logger, cfc, policyDB, scalingEngineDB, clock, 300, 32)
+		scalingEngine = NewScalingEngine(logger, cfc, policyDB, scalingEngineDB, clock, 300, 2, 32)
 		appState = models.AppStatusStarted
 		activeSchedule = &models.ActiveSchedule{
 			ScheduleId:         "a-schedule-id",
@@ -51,6 +58,13 @@ var _ = Describe("ScalingEngine", func() {
 		}
 	})
 
+	// withScheduleFields adds the structured fields describing the active schedule to the expected history.
+	withScheduleFields := func(history *models.AppScalingHistory) *models.AppScalingHistory {
+		history.ScheduleId = activeSchedule.ScheduleId
+		history.CorrelationId = traceId.String()
+		return history
+	}
+
 	setAppAndProcesses := func(instances int, aState string) {
 		cfc.GetAppAndProcessesReturns(&cf.AppAndProcesses{Processes: cf.Processes{{Instances: instances}}, App: &cf.App{State: aState}}, nil)
 	}
@@ -65,13 +79,27 @@ var _ = Describe("ScalingEngine", func() {
 				Threshold:             80,
 				Operator:              ">",
 				Adjustment:            "+1",
+				RuleIndex:             ptr(0),
+				ObservedValue:         ptr(int64(90)),
 			}
 		})
 
 		JustBeforeEach(func() {
-			scalingResult, err = scalingEngine.Scale(context.Background(), "an-app-id", trigger)
+			scalingResult, err = scalingEngine.Scale(tracedCtx, "an-app-id", trigger)
 		})
 
+		// withTriggerFields adds the structured fields describing the trigger to the expected history.
+		withTriggerFields := func(history *models.AppScalingHistory) *models.AppScalingHistory {
+			history.RuleIndex = trigger.RuleIndex
+			history.MetricType = trigger.MetricType
+			history.ObservedValue = trigger.ObservedValue
+			history.Threshold = &trigger.Threshold
+			history.Operator = trigger.Operator
+			history.Adjustment = trigger.Adjustment
+			history.CorrelationId = traceId.String()
+			return history
+		}
+
 		Context("when scaling succeeds", func() {
 			BeforeEach(func() {
 				setAppAndProcesses(2, appState)
@@ -86,19 +114,26 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(guid.String()).To(Equal("an-app-id"))
 				Expect(num).To(Equal(3))
 
-				id, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				id, direction, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
 				Expect(id).To(Equal("an-app-id"))
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
 				Expect(expiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusSucceeded,
-					OldInstances: 2,
-					NewInstances: 3,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-				}))
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+					AppId:             "an-app-id",
+					Timestamp:         clock.Now().UnixNano(),
+					ScalingType:       models.ScalingTypeDynamic,
+					Status:            models.ScalingStatusSucceeded,
+					OldInstances:      2,
+					NewInstances:      3,
+					Reason:            "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+				})))
+
+				Expect(scalingEngineDB.SaveScalingEventCallCount()).To(Equal(1))
+				_, event := scalingEngineDB.SaveScalingEventArgsForCall(0)
+				Expect(event.Type).To(Equal(models.ScalingEventScaling))
+				Expect(event.History).To(Equal(scalingEngineDB.SaveScalingHistoryArgsForCall(0)))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
@@ -107,6 +142,111 @@ var _ = Describe("ScalingEngine", func() {
 			})
 		})
 
+		Context("when the request is not traced", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("stores the scaling history with a random correlation id", func() {
+				_, err = scalingEngine.Scale(context.Background(), "an-app-id", trigger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(1).CorrelationId).To(MatchRegexp("^[0-9a-f]{32}$"))
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(1).CorrelationId).NotTo(Equal(traceId.String()))
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(1).DecisionId).To(BeEmpty())
+				Expect(scalingEngineDB.SaveScalingDecisionCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the scaling belongs to a decision", func() {
+			var decisionCtx context.Context
+
+			BeforeEach(func() {
+				decisionCtx = helpers.ContextWithDecisionId(tracedCtx, "a-decision-id")
+				trigger.Evaluation = []models.RuleEvaluation{{
+					RuleIndex:  ptr(0),
+					MetricType: "test-metric-type",
+					Threshold:  80,
+					Operator:   ">",
+					Adjustment: "+1",
+					Samples:    []models.MetricSample{{Timestamp: 111111, Value: "90", Unit: "test-unit"}},
+					Breached:   true,
+				}}
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("stores the decision with the evaluation and the cf response", func() {
+				_, err = scalingEngine.Scale(decisionCtx, "an-app-id", trigger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(1).DecisionId).To(Equal("a-decision-id"))
+
+				Expect(scalingEngineDB.SaveScalingDecisionCallCount()).To(Equal(1))
+				_, decision := scalingEngineDB.SaveScalingDecisionArgsForCall(0)
+				Expect(decision).To(Equal(&models.DecisionTrace{
+					DecisionId: "a-decision-id",
+					AppId:      "an-app-id",
+					Timestamp:  clock.Now().UnixNano(),
+					Evaluation: trigger.Evaluation,
+					CFResponse: &models.CFScalingResponse{Instances: 3},
+				}))
+			})
+
+			Context("when setting the instances fails", func() {
+				BeforeEach(func() {
+					cfc.ScaleAppWebProcessReturns(&cf.CfError{StatusCode: 503, Url: "https://api.example.com"})
+				})
+
+				It("stores the status code of the cf response", func() {
+					_, err = scalingEngine.Scale(decisionCtx, "an-app-id", trigger)
+					Expect(err).To(HaveOccurred())
+
+					_, decision := scalingEngineDB.SaveScalingDecisionArgsForCall(0)
+					Expect(decision.CFResponse.Instances).To(Equal(3))
+					Expect(decision.CFResponse.StatusCode).To(Equal(503))
+					Expect(decision.CFResponse.Error).To(ContainSubstring("cf api Error"))
+				})
+			})
+
+			Context("when the app is not started", func() {
+				BeforeEach(func() {
+					setAppAndProcesses(2, models.AppStatusStopped)
+				})
+
+				It("stores the decision without a cf response", func() {
+					_, err = scalingEngine.Scale(decisionCtx, "an-app-id", trigger)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, decision := scalingEngineDB.SaveScalingDecisionArgsForCall(0)
+					Expect(decision.CFResponse).To(BeNil())
+					Expect(decision.Evaluation).To(Equal(trigger.Evaluation))
+				})
+			})
+		})
+
+		Context("when scaling in with a directional cool-down", func() {
+			BeforeEach(func() {
+				trigger.Adjustment = "-1"
+				trigger.DirectionalCoolDownSeconds = 600
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("checks and updates the scale-in cool-down only", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, direction := scalingEngineDB.CanScaleAppArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionIn))
+
+				_, direction, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionIn))
+				Expect(expiredAt).To(Equal(clock.Now().Add(600 * time.Second).UnixNano()))
+				Expect(scalingResult.CooldownExpiredAt).To(Equal(expiredAt))
+			})
+		})
+
 		Context("When app is not started", func() {
 			BeforeEach(func() {
 				setAppAndProcesses(2, "test-state")
@@ -115,7 +255,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("check-app-state"))
 				Eventually(buffer).Should(gbytes.Say("ignore scaling since app is not started"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -124,7 +264,11 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 2,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Message:      "app is not started",
-				}))
+				})))
+
+				_, event := scalingEngineDB.SaveScalingEventArgsForCall(0)
+				Expect(event.Type).To(Equal(models.ScalingEventTriggerIgnored))
+				Expect(event.History).To(Equal(scalingEngineDB.SaveScalingHistoryArgsForCall(0)))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
@@ -142,7 +286,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("check-app-label"))
 				Eventually(buffer).Should(gbytes.Say("ignore scaling since app has the label app-autoscaler.cloudfoundry.org/disable-autoscaling set"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -151,7 +295,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 2,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Message:      "The application was not scaled as the label \"app-autoscaler.cloudfoundry.org/disable-autoscaling\" was set on the app. The content of the label might give a hint on why the label was set: \"for test purposes\"",
-				}))
+				})))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
@@ -170,16 +314,20 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusIgnored,
-					OldInstances: 2,
-					NewInstances: 2,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "app in cooldown period",
-				}))
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+					AppId:             "an-app-id",
+					Timestamp:         clock.Now().UnixNano(),
+					ScalingType:       models.ScalingTypeDynamic,
+					Status:            models.ScalingStatusIgnored,
+					OldInstances:      2,
+					NewInstances:      2,
+					Reason:            "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Message:           "app in scale-out cooldown period",
+					CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+				})))
+
+				_, direction := scalingEngineDB.CanScaleAppArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
@@ -189,6 +337,89 @@ var _ = Describe("ScalingEngine", func() {
 			})
 		})
 
+		Context("when an emergency threshold is breached in cooldown period", func() {
+			BeforeEach(func() {
+				trigger.Emergency = true
+				trigger.Adjustment = "+3"
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(false, clock.Now().Add(30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			Context("when the limit of emergency scalings is not reached", func() {
+				BeforeEach(func() {
+					scalingEngineDB.CountEmergencyScalingsReturns(1, nil)
+				})
+
+				It("skips the cooldown and stores the scaling history flagged as emergency", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, appId, start, end := scalingEngineDB.CountEmergencyScalingsArgsForCall(0)
+					Expect(appId).To(Equal("an-app-id"))
+					Expect(start).To(Equal(clock.Now().Add(-time.Hour).UnixNano()))
+					Expect(end).To(Equal(clock.Now().UnixNano()))
+
+					_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					Expect(num).To(Equal(5))
+
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+						AppId:             "an-app-id",
+						Timestamp:         clock.Now().UnixNano(),
+						ScalingType:       models.ScalingTypeDynamic,
+						Status:            models.ScalingStatusSucceeded,
+						OldInstances:      2,
+						NewInstances:      5,
+						Reason:            "+3 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Emergency:         true,
+						CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+					})))
+					Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
+				})
+			})
+
+			Context("when the limit of emergency scalings is reached", func() {
+				BeforeEach(func() {
+					scalingEngineDB.CountEmergencyScalingsReturns(2, nil)
+				})
+
+				It("ignores the scaling", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(history.Emergency).To(BeTrue())
+					Expect(history.Message).To(Equal("app in scale-out cooldown period, limit of 2 emergency scalings per hour reached"))
+				})
+			})
+
+			Context("when counting the emergency scalings fails", func() {
+				BeforeEach(func() {
+					scalingEngineDB.CountEmergencyScalingsReturns(0, errors.New("test error"))
+				})
+
+				It("should error", func() {
+					Expect(err).To(MatchError("test error"))
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Error).To(Equal("failed to count emergency scalings"))
+				})
+			})
+		})
+
+		Context("when the app is not in cooldown period", func() {
+			BeforeEach(func() {
+				trigger.Emergency = true
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("does not count emergency scalings", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.CountEmergencyScalingsCallCount()).To(BeZero())
+				Expect(cfc.ScaleAppWebProcessCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("when app instances not changed", func() {
 			BeforeEach(func() {
 				trigger.Adjustment = "+1"
@@ -202,7 +433,7 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -211,7 +442,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 6,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Message:      "limited by max instances 6",
-				}))
+				})))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
@@ -235,20 +466,21 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(guid.String()).To(Equal("an-app-id"))
 				Expect(num).To(Equal(6))
 
-				cooldownId, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				cooldownId, _, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
 				Expect(cooldownId).To(Equal("an-app-id"))
 				Expect(expiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusSucceeded,
-					OldInstances: 5,
-					NewInstances: 6,
-					Reason:       "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "limited by max instances 6",
-				}))
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+					AppId:             "an-app-id",
+					Timestamp:         clock.Now().UnixNano(),
+					ScalingType:       models.ScalingTypeDynamic,
+					Status:            models.ScalingStatusSucceeded,
+					OldInstances:      5,
+					NewInstances:      6,
+					Reason:            "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Message:           "limited by max instances 6",
+					CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+				})))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
@@ -271,7 +503,7 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
 				Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -280,7 +512,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 6,
 					Reason:       "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Message:      "limited by max instances 6",
-				}))
+				})))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
@@ -305,16 +537,17 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(guid.String()).To(Equal("an-app-id"))
 				Expect(num).To(Equal(2))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusSucceeded,
-					OldInstances: 3,
-					NewInstances: 2,
-					Reason:       "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "limited by min instances 2",
-				}))
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+					AppId:             "an-app-id",
+					Timestamp:         clock.Now().UnixNano(),
+					ScalingType:       models.ScalingTypeDynamic,
+					Status:            models.ScalingStatusSucceeded,
+					OldInstances:      3,
+					NewInstances:      2,
+					Reason:            "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Message:           "limited by min instances 2",
+					CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+				})))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
@@ -330,6 +563,7 @@ var _ = Describe("ScalingEngine", func() {
 					InstanceMin: 3,
 					InstanceMax: 7,
 				}, nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
 			})
 
 			Context("when it exceeds max instances limit in active schedule", func() {
@@ -341,22 +575,23 @@ var _ = Describe("ScalingEngine", func() {
 
 				It("updates the app instance with  max instances and stores the succeeded scaling history", func() {
 					Expect(err).NotTo(HaveOccurred())
-					Expect(policyDB.GetAppPolicyCallCount()).To(BeZero())
 
 					_, id, num := cfc.ScaleAppWebProcessArgsForCall(0)
 					Expect(id.String()).To(Equal("an-app-id"))
 					Expect(num).To(Equal(7))
 
-					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-						AppId:        "an-app-id",
-						Timestamp:    clock.Now().UnixNano(),
-						ScalingType:  models.ScalingTypeDynamic,
-						Status:       models.ScalingStatusSucceeded,
-						OldInstances: 6,
-						NewInstances: 7,
-						Reason:       "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-						Message:      "limited by max instances 7",
-					}))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+						AppId:             "an-app-id",
+						Timestamp:         clock.Now().UnixNano(),
+						ScalingType:       models.ScalingTypeDynamic,
+						Status:            models.ScalingStatusSucceeded,
+						OldInstances:      6,
+						NewInstances:      7,
+						Reason:            "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Message:           "limited by max instances 7",
+						ScheduleId:        "111111",
+						CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+					})))
 
 					Expect(scalingResult.AppId).To(Equal("an-app-id"))
 					Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
@@ -374,22 +609,23 @@ var _ = Describe("ScalingEngine", func() {
 
 				It("updates the app instance with min instances and stores the succeeded scaling history", func() {
 					Expect(err).NotTo(HaveOccurred())
-					Expect(policyDB.GetAppPolicyCallCount()).To(BeZero())
 
 					_, id, num := cfc.ScaleAppWebProcessArgsForCall(0)
 					Expect(id.String()).To(Equal("an-app-id"))
 					Expect(num).To(Equal(3))
 
-					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-						AppId:        "an-app-id",
-						Timestamp:    clock.Now().UnixNano(),
-						ScalingType:  models.ScalingTypeDynamic,
-						Status:       models.ScalingStatusSucceeded,
-						OldInstances: 5,
-						NewInstances: 3,
-						Reason:       "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
-						Message:      "limited by min instances 3",
-					}))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+						AppId:             "an-app-id",
+						Timestamp:         clock.Now().UnixNano(),
+						ScalingType:       models.ScalingTypeDynamic,
+						Status:            models.ScalingStatusSucceeded,
+						OldInstances:      5,
+						NewInstances:      3,
+						Reason:            "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Message:           "limited by min instances 3",
+						ScheduleId:        "111111",
+						CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+					})))
 
 					Expect(scalingResult.AppId).To(Equal("an-app-id"))
 					Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
@@ -409,7 +645,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-info"))
 				Eventually(buffer).Should(gbytes.Say("test error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -418,7 +654,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Error:        "failed to get app info: test error",
-				}))
+				})))
 
 				Expect(scalingResult).To(BeNil())
 
@@ -435,7 +671,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-check-cooldown"))
 				Eventually(buffer).Should(gbytes.Say("test error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -444,7 +680,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Error:        "failed to check app cooldown setting",
-				}))
+				})))
 
 				Expect(scalingResult).To(BeNil())
 			})
@@ -462,7 +698,7 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(err).To(HaveOccurred())
 				Eventually(buffer).Should(gbytes.Say("failed-to-compute-new-instance"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -471,7 +707,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "+a instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Error:        "failed to compute new app instances",
-				}))
+				})))
 
 				Expect(scalingResult).To(BeNil())
 
@@ -490,7 +726,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-get-active-schedule"))
 				Eventually(buffer).Should(gbytes.Say("test error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -499,7 +735,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Error:        "failed to get active schedule",
-				}))
+				})))
 
 				Expect(scalingResult).To(BeNil())
 
@@ -518,7 +754,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-policy"))
 				Eventually(buffer).Should(gbytes.Say("test error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -527,7 +763,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Error:        "failed to get scaling policy",
-				}))
+				})))
 
 				Expect(scalingResult).To(BeNil())
 
@@ -545,7 +781,7 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -554,7 +790,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 2,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Message:      "app does not have policy set",
-				}))
+				})))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
@@ -563,6 +799,513 @@ var _ = Describe("ScalingEngine", func() {
 			})
 		})
 
+		Context("when app is in a blackout period", func() {
+			var blackoutMode models.BlackoutMode
+
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+			})
+
+			setPolicyWithBlackout := func() {
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{
+					InstanceMin: 1,
+					InstanceMax: 6,
+					BlackoutPeriods: &models.BlackoutPeriods{
+						Timezone: "UTC",
+						SpecificDateBlackouts: []*models.SpecificDateBlackout{{
+							StartDateTime: clock.Now().UTC().Add(-1 * time.Hour).Format("2006-01-02T15:04"),
+							EndDateTime:   clock.Now().UTC().Add(1 * time.Hour).Format("2006-01-02T15:04"),
+							Mode:          blackoutMode,
+						}},
+					},
+				}, nil)
+			}
+
+			Context("when the mode is no_scaling", func() {
+				BeforeEach(func() {
+					blackoutMode = models.BlackoutModeNoScaling
+					setPolicyWithBlackout()
+				})
+
+				It("ignores the scaling and stores the ignored scaling history", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
+
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+						AppId:        "an-app-id",
+						Timestamp:    clock.Now().UnixNano(),
+						ScalingType:  models.ScalingTypeDynamic,
+						Status:       models.ScalingStatusIgnored,
+						OldInstances: 2,
+						NewInstances: 2,
+						Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Message:      "app in blackout period with mode no_scaling",
+					})))
+
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(scalingResult.Adjustment).To(Equal(0))
+					Expect(scalingResult.CooldownExpiredAt).To(Equal(int64(0)))
+				})
+			})
+
+			Context("when the mode is no_scale_in", func() {
+				BeforeEach(func() {
+					blackoutMode = models.BlackoutModeNoScaleIn
+					setPolicyWithBlackout()
+				})
+
+				Context("when scaling out", func() {
+					It("scales the app", func() {
+						Expect(err).NotTo(HaveOccurred())
+						_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+						Expect(num).To(Equal(3))
+						Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
+					})
+				})
+
+				Context("when scaling in", func() {
+					BeforeEach(func() {
+						trigger.Adjustment = "-1"
+					})
+
+					It("ignores the scaling and stores the ignored scaling history", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+
+						Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+							AppId:        "an-app-id",
+							Timestamp:    clock.Now().UnixNano(),
+							ScalingType:  models.ScalingTypeDynamic,
+							Status:       models.ScalingStatusIgnored,
+							OldInstances: 2,
+							NewInstances: 2,
+							Reason:       "-1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+							Message:      "app in blackout period with mode no_scale_in",
+						})))
+						Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					})
+				})
+			})
+		})
+
+		Context("when scaling out is limited by quota", func() {
+			limit := func(v int) *int { return &v }
+
+			BeforeEach(func() {
+				trigger.Adjustment = "+3"
+				cfc.GetAppAndProcessesReturns(&cf.AppAndProcesses{Processes: cf.Processes{{Instances: 2, MemoryInMb: 256}}, App: &cf.App{State: appState}}, nil)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			Context("when the org quota has capacity for fewer instances", func() {
+				BeforeEach(func() {
+					cfc.GetAppQuotaUsageReturns(&cf.AppQuotaUsage{
+						Org: cf.QuotaUsage{TotalInstances: limit(10), StartedInstances: 9},
+					}, nil)
+				})
+
+				It("scales to the remaining capacity and stores the limited scaling history", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					Expect(num).To(Equal(3))
+
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+						AppId:             "an-app-id",
+						Timestamp:         clock.Now().UnixNano(),
+						ScalingType:       models.ScalingTypeDynamic,
+						Status:            models.ScalingStatusSucceeded,
+						OldInstances:      2,
+						NewInstances:      3,
+						Reason:            "+3 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Message:           "limited by org quota",
+						CooldownExpiredAt: clock.Now().Add(30 * time.Second).UnixNano(),
+					})))
+					Expect(scalingResult.Adjustment).To(Equal(1))
+				})
+			})
+
+			Context("when the space quota is the tighter limit", func() {
+				BeforeEach(func() {
+					cfc.GetAppQuotaUsageReturns(&cf.AppQuotaUsage{
+						Org:   cf.QuotaUsage{TotalInstances: limit(10), StartedInstances: 7},
+						Space: &cf.QuotaUsage{TotalMemoryInMb: limit(1024), MemoryInMb: 512},
+					}, nil)
+				})
+
+				It("scales to the remaining space capacity", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					Expect(num).To(Equal(4))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("limited by space quota"))
+				})
+			})
+
+			Context("when the org quota is exhausted", func() {
+				BeforeEach(func() {
+					cfc.GetAppQuotaUsageReturns(&cf.AppQuotaUsage{
+						Org: cf.QuotaUsage{TotalMemoryInMb: limit(4096), MemoryInMb: 4000},
+					}, nil)
+				})
+
+				It("ignores the scaling without returning an error", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
+
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+						AppId:        "an-app-id",
+						Timestamp:    clock.Now().UnixNano(),
+						ScalingType:  models.ScalingTypeDynamic,
+						Status:       models.ScalingStatusIgnored,
+						OldInstances: 2,
+						NewInstances: 2,
+						Reason:       "+3 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Message:      "limited by org quota",
+					})))
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(scalingResult.CooldownExpiredAt).To(Equal(int64(0)))
+				})
+			})
+
+			Context("when getting the quota usage fails", func() {
+				BeforeEach(func() {
+					cfc.GetAppQuotaUsageReturns(nil, errors.New("test error"))
+				})
+
+				It("scales the app as requested", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Eventually(buffer).Should(gbytes.Say("failed-to-get-quota-usage"))
+					_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					Expect(num).To(Equal(5))
+				})
+			})
+
+			Context("when cloud controller rejects the scaling because of the org quota", func() {
+				BeforeEach(func() {
+					cfc.ScaleAppWebProcessReturns(&cf.CfError{
+						StatusCode: 422,
+						Errors:     []cf.CfErrorItem{{Code: 10008, Title: "CF-UnprocessableEntity", Detail: "app_instance_limit quota_exceeded"}},
+					})
+				})
+
+				It("stores the failed scaling history without returning an error", func() {
+					Expect(err).NotTo(HaveOccurred())
+
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+					Expect(history.NewInstances).To(Equal(2))
+					Expect(history.Message).To(Equal("limited by org quota"))
+					Expect(history.Error).To(ContainSubstring("quota_exceeded"))
+
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusFailed))
+					Expect(scalingResult.CooldownExpiredAt).To(Equal(int64(0)))
+				})
+			})
+		})
+
+		Context("when the policy has an instance-hour budget", func() {
+			var budgetInstanceHours int
+
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+			})
+
+			setPolicyWithBudget := func() {
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{
+					InstanceMin:        1,
+					InstanceMax:        6,
+					InstanceHourBudget: &models.InstanceHourBudget{InstanceHours: budgetInstanceHours, Period: models.BudgetPeriodDay},
+				}, nil)
+			}
+
+			Context("when the scale-out stays within the budget", func() {
+				BeforeEach(func() {
+					// 2 instances so far and 3 for the rest of the day never exceed 3 instances all day
+					budgetInstanceHours = 72
+					setPolicyWithBudget()
+				})
+
+				It("scales the app", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					Expect(num).To(Equal(3))
+
+					_, appId, start, end := scalingEngineDB.RetrieveInstanceChangesArgsForCall(0)
+					Expect(appId).To(Equal("an-app-id"))
+					dayStart, _ := models.BudgetPeriodDay.Bounds(clock.Now())
+					Expect(start).To(Equal(dayStart.UnixNano()))
+					Expect(end).To(Equal(clock.Now().UnixNano()))
+				})
+			})
+
+			Context("when the scale-out would exceed the budget", func() {
+				BeforeEach(func() {
+					budgetInstanceHours = 48
+					setPolicyWithBudget()
+				})
+
+				It("refuses the scaling and stores the ignored scaling history", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
+
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
+						AppId:        "an-app-id",
+						Timestamp:    clock.Now().UnixNano(),
+						ScalingType:  models.ScalingTypeDynamic,
+						Status:       models.ScalingStatusIgnored,
+						OldInstances: 2,
+						NewInstances: 2,
+						Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Message:      "exceeds instance-hour budget of 48 per day",
+					})))
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+				})
+			})
+
+			Context("when the budget is shared with another app bound with the default policy", func() {
+				BeforeEach(func() {
+					// the scale-out alone stays within the budget, but not together with the instance of the other app
+					budgetInstanceHours = 72
+					setPolicyWithBudget()
+					policyDB.GetAppIdsSharingPolicyReturns([]string{"an-app-id", "another-app-id"}, nil)
+					cfc.GetAppProcessesReturns(cf.Processes{{Instances: 1}}, nil)
+				})
+
+				It("refuses the scaling as the apps would exceed the budget together", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("exceeds instance-hour budget of 72 per day"))
+
+					_, appId, _ := cfc.GetAppProcessesArgsForCall(0)
+					Expect(appId).To(Equal(cf.Guid("another-app-id")))
+					Expect(scalingEngineDB.RetrieveInstanceChangesCallCount()).To(Equal(2))
+					_, appIdOfHistories, _, _ := scalingEngineDB.RetrieveInstanceChangesArgsForCall(1)
+					Expect(appIdOfHistories).To(Equal("another-app-id"))
+				})
+
+				Context("when the other app no longer exists", func() {
+					BeforeEach(func() {
+						cfc.GetAppProcessesReturns(nil, &cf.CfError{StatusCode: 404, Errors: []cf.CfErrorItem{{Code: 10010, Title: "CF-ResourceNotFound", Detail: "App not found"}}})
+					})
+
+					It("scales the app", func() {
+						Expect(err).NotTo(HaveOccurred())
+						_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+						Expect(num).To(Equal(3))
+					})
+				})
+
+				Context("when retrieving the apps sharing the policy fails", func() {
+					BeforeEach(func() {
+						policyDB.GetAppIdsSharingPolicyReturns(nil, errors.New("test error"))
+					})
+
+					It("should error and store failed scaling history", func() {
+						Expect(err).To(HaveOccurred())
+						Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+						Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Error).To(Equal("failed to get instance-hour usage"))
+					})
+				})
+			})
+
+			Context("when scaling in", func() {
+				BeforeEach(func() {
+					trigger.Adjustment = "-1"
+					budgetInstanceHours = 1
+					setPolicyWithBudget()
+				})
+
+				It("does not check the budget", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingEngineDB.RetrieveInstanceChangesCallCount()).To(BeZero())
+					_, _, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					Expect(num).To(Equal(1))
+				})
+			})
+
+			Context("when retrieving the scaling histories fails", func() {
+				BeforeEach(func() {
+					budgetInstanceHours = 72
+					setPolicyWithBudget()
+					scalingEngineDB.RetrieveInstanceChangesReturns(nil, errors.New("test error"))
+				})
+
+				It("should error and store failed scaling history", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Status).To(Equal(models.ScalingStatusFailed))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Error).To(Equal("failed to get instance-hour usage"))
+				})
+			})
+		})
+
+		Context("when the app belongs to an app group", func() {
+			var group *models.AppGroup
+
+			BeforeEach(func() {
+				group = &models.AppGroup{
+					GroupId:       "a-group-id",
+					LeadingMetric: models.LeadingMetric{AppId: "an-app-id", MetricType: "test-metric-type"},
+					Members: []models.AppGroupMember{
+						{AppId: "ratio-app-id", Ratio: ptr(1.5)},
+						{AppId: "limited-app-id", InstanceMin: ptr(1), InstanceMax: ptr(2)},
+					},
+				}
+				policyDB.GetAppGroupByAppIdReturns(group, nil)
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				cfc.GetAppAndProcessesStub = func(_ context.Context, appId cf.Guid) (*cf.AppAndProcesses, error) {
+					instances := map[cf.Guid]int{"an-app-id": 2, "ratio-app-id": 3, "limited-app-id": 2}[appId]
+					return &cf.AppAndProcesses{Processes: cf.Processes{{Instances: instances}}, App: &cf.App{State: models.AppStatusStarted}}, nil
+				}
+			})
+
+			Context("when all apps are scaled", func() {
+				It("scales the members according to the new instances of the leader and stores the group scaling history", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
+
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(Equal(2))
+					_, guid, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					Expect(guid.String()).To(Equal("an-app-id"))
+					Expect(num).To(Equal(3))
+					_, guid, num = cfc.ScaleAppWebProcessArgsForCall(1)
+					Expect(guid.String()).To(Equal("ratio-app-id"))
+					Expect(num).To(Equal(5))
+
+					Expect(scalingEngineDB.SaveGroupScalingHistoryCallCount()).To(Equal(1))
+					_, groupHistory := scalingEngineDB.SaveGroupScalingHistoryArgsForCall(0)
+					Expect(groupHistory.GroupId).To(Equal("a-group-id"))
+					Expect(groupHistory.Status).To(Equal(models.GroupScalingSucceeded))
+					Expect(groupHistory.Apps).To(Equal([]models.GroupMemberScaling{
+						{AppId: "an-app-id", OldInstances: 2, NewInstances: 3, Status: models.GroupMemberScaled},
+						{AppId: "ratio-app-id", OldInstances: 3, NewInstances: 5, Status: models.GroupMemberScaled},
+						{AppId: "limited-app-id", OldInstances: 2, NewInstances: 2, Status: models.GroupMemberUnchanged},
+					}))
+				})
+			})
+
+			Context("when a member cannot be scaled", func() {
+				BeforeEach(func() {
+					cfc.ScaleAppWebProcessReturnsOnCall(1, errors.New("test error"))
+				})
+
+				It("scales the leader back and stores a rolled back group scaling history", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(scalingResult).To(BeNil())
+
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(Equal(3))
+					_, guid, num := cfc.ScaleAppWebProcessArgsForCall(2)
+					Expect(guid.String()).To(Equal("an-app-id"))
+					Expect(num).To(Equal(2))
+
+					_, groupHistory := scalingEngineDB.SaveGroupScalingHistoryArgsForCall(0)
+					Expect(groupHistory.Status).To(Equal(models.GroupScalingRolledBack))
+					Expect(groupHistory.Error).To(Equal("failed to scale member ratio-app-id of group a-group-id: test error"))
+					Expect(groupHistory.Apps[0].Status).To(Equal(models.GroupMemberRolledBack))
+					Expect(groupHistory.Apps[1].Status).To(Equal(models.GroupMemberFailed))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Status).To(Equal(models.ScalingStatusFailed))
+				})
+
+				Context("when the leader cannot be scaled back", func() {
+					BeforeEach(func() {
+						cfc.ScaleAppWebProcessReturnsOnCall(2, errors.New("rollback error"))
+					})
+
+					It("stores a partially failed group scaling history", func() {
+						Expect(err).To(HaveOccurred())
+						_, groupHistory := scalingEngineDB.SaveGroupScalingHistoryArgsForCall(0)
+						Expect(groupHistory.Status).To(Equal(models.GroupScalingPartiallyFailed))
+						Expect(groupHistory.Apps[0].Status).To(Equal(models.GroupMemberRollbackFailed))
+						Expect(groupHistory.Apps[0].Error).To(Equal("failed to roll back: rollback error"))
+					})
+				})
+			})
+
+			Context("when a member cannot be read", func() {
+				BeforeEach(func() {
+					cfc.GetAppAndProcessesStub = func(_ context.Context, appId cf.Guid) (*cf.AppAndProcesses, error) {
+						if appId == "ratio-app-id" {
+							return nil, errors.New("test error")
+						}
+						return &cf.AppAndProcesses{Processes: cf.Processes{{Instances: 2}}, App: &cf.App{State: models.AppStatusStarted}}, nil
+					}
+				})
+
+				It("scales no app and stores a failed group scaling history", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					_, groupHistory := scalingEngineDB.SaveGroupScalingHistoryArgsForCall(0)
+					Expect(groupHistory.Status).To(Equal(models.GroupScalingFailed))
+					Expect(groupHistory.Apps[0].Status).To(Equal(models.GroupMemberSkipped))
+					Expect(groupHistory.Apps[2].Status).To(Equal(models.GroupMemberSkipped))
+				})
+			})
+
+			Context("when a member no longer exists", func() {
+				BeforeEach(func() {
+					cfc.GetAppAndProcessesStub = func(_ context.Context, appId cf.Guid) (*cf.AppAndProcesses, error) {
+						if appId == "ratio-app-id" {
+							return nil, &cf.CfError{StatusCode: 404, Errors: []cf.CfErrorItem{{Code: 10010, Title: "CF-ResourceNotFound", Detail: "App not found"}}}
+						}
+						return &cf.AppAndProcesses{Processes: cf.Processes{{Instances: 2}}, App: &cf.App{State: models.AppStatusStarted}}, nil
+					}
+				})
+
+				It("ignores the member and scales the other apps", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(Equal(1))
+					_, groupHistory := scalingEngineDB.SaveGroupScalingHistoryArgsForCall(0)
+					Expect(groupHistory.Status).To(Equal(models.GroupScalingSucceeded))
+					Expect(groupHistory.Apps[0].Status).To(Equal(models.GroupMemberScaled))
+					Expect(groupHistory.Apps[1]).To(Equal(models.GroupMemberScaling{AppId: "ratio-app-id", OldInstances: -1, NewInstances: -1, Status: models.GroupMemberIgnored, Message: "app does not exist"}))
+					Expect(groupHistory.Apps[2].Status).To(Equal(models.GroupMemberUnchanged))
+				})
+			})
+
+			Context("when the app is a member of the group", func() {
+				BeforeEach(func() {
+					group.LeadingMetric.AppId = "leader-app-id"
+					group.Members[0].AppId = "an-app-id"
+				})
+
+				It("ignores the scaling", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("app is scaled with the leader leader-app-id of app group a-group-id"))
+				})
+			})
+
+			Context("when the scaling is not triggered by the leading metric", func() {
+				BeforeEach(func() {
+					group.LeadingMetric.MetricType = "throughput"
+				})
+
+				It("ignores the scaling", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when getting the app group fails", func() {
+				BeforeEach(func() {
+					policyDB.GetAppGroupByAppIdReturns(nil, errors.New("test error"))
+				})
+
+				It("errors and stores a failed scaling history", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Error).To(Equal("failed to get app group"))
+				})
+			})
+		})
+
 		Context("when set new instances fails", func() {
 			BeforeEach(func() {
 				setAppAndProcesses(2, appState)
@@ -576,7 +1319,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-set-app-instances"))
 				Eventually(buffer).Should(gbytes.Say("test error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withTriggerFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeDynamic,
@@ -585,7 +1328,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 3,
 					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
 					Error:        "failed to set app instances: test error",
-				}))
+				})))
 
 				Expect(scalingResult).To(BeNil())
 
@@ -593,6 +1336,225 @@ var _ = Describe("ScalingEngine", func() {
 		})
 	})
 
+	Describe("GetInstanceHourUsage", func() {
+		var usage *models.InstanceHourUsage
+
+		BeforeEach(func() {
+			cfc.GetAppProcessesReturns(cf.Processes{{Instances: 3}}, nil)
+		})
+
+		JustBeforeEach(func() {
+			usage, err = scalingEngine.GetInstanceHourUsage(context.Background(), "an-app-id")
+		})
+
+		Context("when the policy has an instance-hour budget", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{
+					InstanceMin:        1,
+					InstanceMax:        6,
+					InstanceHourBudget: &models.InstanceHourBudget{InstanceHours: 2000, Period: models.BudgetPeriodMonth},
+				}, nil)
+			})
+
+			It("returns the consumption and forecast of the current period", func() {
+				Expect(err).NotTo(HaveOccurred())
+				periodStart, periodEnd := models.BudgetPeriodMonth.Bounds(clock.Now())
+				Expect(usage.Budget).To(Equal(models.InstanceHourBudget{InstanceHours: 2000, Period: models.BudgetPeriodMonth}))
+				Expect(usage.PeriodStart).To(Equal(periodStart))
+				Expect(usage.CurrentInstances).To(Equal(3))
+				Expect(usage.ConsumedInstanceHours).To(BeNumerically("~", 3*clock.Now().Sub(periodStart).Hours()))
+				Expect(usage.ForecastInstanceHours).To(BeNumerically("~", 3*periodEnd.Sub(periodStart).Hours()))
+			})
+		})
+
+		Context("when the policy has no instance-hour budget", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("returns no usage", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(usage).To(BeNil())
+				Expect(scalingEngineDB.RetrieveInstanceChangesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when getting the policy fails", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(nil, errors.New("test error"))
+			})
+
+			It("should error", func() {
+				Expect(err).To(MatchError(ContainSubstring("test error")))
+			})
+		})
+	})
+
+	Describe("GetScalingAnalytics", func() {
+		var (
+			analytics *models.ScalingAnalytics
+			end       int64
+		)
+
+		BeforeEach(func() {
+			end = 500
+			cfc.GetAppProcessesReturns(cf.Processes{{Instances: 3}}, nil)
+			policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			scalingEngineDB.RetrieveScalingAnalyticsReturns(&models.ScalingAnalytics{InstanceHours: 42}, nil)
+		})
+
+		JustBeforeEach(func() {
+			analytics, err = scalingEngine.GetScalingAnalytics(context.Background(), "an-app-id", 100, end)
+		})
+
+		It("aggregates the scaling history with the current instances and the limits of the policy", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analytics.InstanceHours).To(Equal(42.0))
+			_, appId, start, end, currentInstances, instanceMin, instanceMax := scalingEngineDB.RetrieveScalingAnalyticsArgsForCall(0)
+			Expect(appId).To(Equal("an-app-id"))
+			Expect(start).To(Equal(int64(100)))
+			Expect(end).To(Equal(int64(500)))
+			Expect(currentInstances).To(Equal(3))
+			Expect(instanceMin).To(Equal(1))
+			Expect(instanceMax).To(Equal(6))
+		})
+
+		Context("when the end is not given", func() {
+			BeforeEach(func() {
+				end = -1
+			})
+
+			It("aggregates the scaling history until now", func() {
+				_, _, _, end, _, _, _ := scalingEngineDB.RetrieveScalingAnalyticsArgsForCall(0)
+				Expect(end).To(Equal(clock.Now().UnixNano()))
+			})
+		})
+
+		Context("when the app has no policy", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(nil, nil)
+			})
+
+			It("returns no analytics", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(analytics).To(BeNil())
+				Expect(scalingEngineDB.RetrieveScalingAnalyticsCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when retrieving the analytics fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingAnalyticsReturns(nil, errors.New("test error"))
+			})
+
+			It("should error", func() {
+				Expect(err).To(MatchError(ContainSubstring("test error")))
+			})
+		})
+	})
+
+	Describe("GetScalingState", func() {
+		var state *models.AppScalingState
+
+		BeforeEach(func() {
+			setAppAndProcesses(3, "started")
+			policyDB.GetAppPolicyReturns(&models.PolicyDefinition{InstanceMin: 1, InstanceMax: 6}, nil)
+			scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+			scalingEngineDB.RetrieveScalingHistoriesReturns([]*models.AppScalingHistory{{AppId: "an-app-id", NewInstances: 3}}, nil)
+		})
+
+		JustBeforeEach(func() {
+			state, err = scalingEngine.GetScalingState(context.Background(), "an-app-id")
+		})
+
+		It("returns the limits of the policy, the instances and the last scaling of the app", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(&models.AppScalingState{
+				AppId:       "an-app-id",
+				AppState:    models.AppStatusStarted,
+				Instances:   3,
+				InstanceMin: 1,
+				InstanceMax: 6,
+				Cooldowns:   []models.Cooldown{},
+				LastScaling: &models.AppScalingHistory{AppId: "an-app-id", NewInstances: 3},
+			}))
+
+			_, appId, _, end, order, filter, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+			Expect(appId).To(Equal("an-app-id"))
+			Expect(end).To(Equal(clock.Now().UnixNano()))
+			Expect(order).To(Equal(db.DESC))
+			Expect(filter.IncludeAll).To(BeFalse())
+			Expect(filter.Statuses).To(ConsistOf(models.ScalingStatusSucceeded, models.ScalingStatusFailed))
+			Expect(page).To(Equal(1))
+			Expect(resultsPerPage).To(Equal(1))
+		})
+
+		Context("when a schedule is active", func() {
+			BeforeEach(func() {
+				scalingEngineDB.GetActiveScheduleReturns(&models.ActiveSchedule{ScheduleId: "a-schedule-id", InstanceMin: 2, InstanceMax: 4}, nil)
+			})
+
+			It("returns the limits of the schedule", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.ActiveSchedule.ScheduleId).To(Equal("a-schedule-id"))
+				Expect(state.InstanceMin).To(Equal(2))
+				Expect(state.InstanceMax).To(Equal(4))
+			})
+		})
+
+		Context("when the app is in cool-down", func() {
+			BeforeEach(func() {
+				scalingEngineDB.CanScaleAppStub = func(_ string, direction models.ScalingDirection) (bool, int64, error) {
+					if direction == models.ScalingDirectionOut {
+						return false, clock.Now().Add(30 * time.Second).UnixNano(), nil
+					}
+					return true, clock.Now().Add(0 - 30*time.Second).UnixNano(), nil
+				}
+			})
+
+			It("returns the running cool-downs", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.Cooldowns).To(Equal([]models.Cooldown{
+					{Direction: models.ScalingDirectionOut, ExpiredAt: clock.Now().Add(30 * time.Second).UnixNano()},
+				}))
+			})
+		})
+
+		Context("when the app has the disable-autoscaling label", func() {
+			BeforeEach(func() {
+				reason := "maintenance"
+				cfc.GetAppAndProcessesReturns(&cf.AppAndProcesses{Processes: cf.Processes{{Instances: 3}}, App: &cf.App{State: "STARTED", Metadata: cf.Metadata{Labels: cf.Labels{DisableAutoscaling: &reason}}}}, nil)
+			})
+
+			It("returns the value of the label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*state.DisableAutoscaling).To(Equal("maintenance"))
+			})
+		})
+
+		Context("when the app has no policy", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(nil, nil)
+			})
+
+			It("returns no state", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state).To(BeNil())
+				Expect(cfc.GetAppAndProcessesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when checking the cool-down fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.CanScaleAppReturns(false, 0, errors.New("test error"))
+			})
+
+			It("should error", func() {
+				Expect(err).To(MatchError(ContainSubstring("test error")))
+			})
+		})
+	})
+
 	Describe("ComputeNewInstances", func() {
 		var adjustment string
 		var newInstances int
@@ -662,7 +1624,7 @@ var _ = Describe("ScalingEngine", func() {
 
 	Describe("SetActiveSchedule", func() {
 		JustBeforeEach(func() {
-			err = scalingEngine.SetActiveSchedule(context.Background(), "an-app-id", activeSchedule)
+			err = scalingEngine.SetActiveSchedule(tracedCtx, "an-app-id", activeSchedule)
 		})
 
 		BeforeEach(func() {
@@ -687,7 +1649,7 @@ var _ = Describe("ScalingEngine", func() {
 				_, appid, instances := cfc.ScaleAppWebProcessArgsForCall(0)
 				Expect(appid.String()).To(Equal("an-app-id"))
 				Expect(instances).To(Equal(10))
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -696,7 +1658,11 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 10,
 					Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 5",
 					Message:      "limited by max instances 10",
-				}))
+				})))
+
+				_, event := scalingEngineDB.SaveScalingEventArgsForCall(0)
+				Expect(event.Type).To(Equal(models.ScalingEventScheduleStart))
+				Expect(event.History).To(Equal(scalingEngineDB.SaveScalingHistoryArgsForCall(0)))
 
 			})
 		})
@@ -718,7 +1684,7 @@ var _ = Describe("ScalingEngine", func() {
 					Expect(appid.String()).To(Equal("an-app-id"))
 					Expect(instances).To(Equal(2))
 
-					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 						AppId:        "an-app-id",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
@@ -727,7 +1693,7 @@ var _ = Describe("ScalingEngine", func() {
 						NewInstances: 2,
 						Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 0",
 						Message:      "limited by min instances 2",
-					}))
+					})))
 
 				})
 			})
@@ -740,7 +1706,7 @@ var _ = Describe("ScalingEngine", func() {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
 
-					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 						AppId:        "an-app-id",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
@@ -748,7 +1714,7 @@ var _ = Describe("ScalingEngine", func() {
 						OldInstances: 3,
 						NewInstances: 3,
 						Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 0",
-					}))
+					})))
 				})
 			})
 		})
@@ -766,7 +1732,7 @@ var _ = Describe("ScalingEngine", func() {
 					Expect(appid.String()).To(Equal("an-app-id"))
 					Expect(instances).To(Equal(5))
 
-					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 						AppId:        "an-app-id",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
@@ -775,7 +1741,7 @@ var _ = Describe("ScalingEngine", func() {
 						NewInstances: 5,
 						Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 5",
 						Message:      "limited by min instances 5",
-					}))
+					})))
 
 				})
 			})
@@ -788,7 +1754,7 @@ var _ = Describe("ScalingEngine", func() {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
 
-					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 						AppId:        "an-app-id",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
@@ -796,7 +1762,7 @@ var _ = Describe("ScalingEngine", func() {
 						OldInstances: 6,
 						NewInstances: 6,
 						Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 5",
-					}))
+					})))
 
 				})
 			})
@@ -865,7 +1831,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-info"))
 				Eventually(buffer).Should(gbytes.Say("an error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -874,7 +1840,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 5",
 					Error:        "failed to get app info: an error",
-				}))
+				})))
 
 			})
 		})
@@ -889,7 +1855,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-set-app-instances"))
 				Eventually(buffer).Should(gbytes.Say("an error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -899,7 +1865,7 @@ var _ = Describe("ScalingEngine", func() {
 					Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 5",
 					Message:      "limited by min instances 5",
 					Error:        "failed to set app instances: an error",
-				}))
+				})))
 
 			})
 		})
@@ -907,7 +1873,7 @@ var _ = Describe("ScalingEngine", func() {
 
 	Describe("RemoveActiveSchedule", func() {
 		JustBeforeEach(func() {
-			err = scalingEngine.RemoveActiveSchedule(context.Background(), "an-app-id", "a-schedule-id")
+			err = scalingEngine.RemoveActiveSchedule(tracedCtx, "an-app-id", "a-schedule-id")
 		})
 
 		BeforeEach(func() {
@@ -922,7 +1888,7 @@ var _ = Describe("ScalingEngine", func() {
 
 			It("does not change the instance number", func() {
 				Expect(cfc.ScaleAppWebProcessCallCount()).To(Equal(0))
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -930,7 +1896,11 @@ var _ = Describe("ScalingEngine", func() {
 					OldInstances: 5,
 					NewInstances: 5,
 					Reason:       "schedule ends",
-				}))
+				})))
+
+				_, event := scalingEngineDB.SaveScalingEventArgsForCall(0)
+				Expect(event.Type).To(Equal(models.ScalingEventScheduleEnd))
+				Expect(event.History).To(Equal(scalingEngineDB.SaveScalingHistoryArgsForCall(0)))
 			})
 		})
 
@@ -944,7 +1914,7 @@ var _ = Describe("ScalingEngine", func() {
 				_, appId, instances := cfc.ScaleAppWebProcessArgsForCall(0)
 				Expect(appId.String()).To(Equal("an-app-id"))
 				Expect(instances).To(Equal(3))
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -953,7 +1923,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 3,
 					Reason:       "schedule ends",
 					Message:      "limited by min instances 3",
-				}))
+				})))
 
 			})
 		})
@@ -969,7 +1939,7 @@ var _ = Describe("ScalingEngine", func() {
 				Expect(appId.String()).To(Equal("an-app-id"))
 				Expect(instances).To(Equal(6))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -978,7 +1948,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: 6,
 					Reason:       "schedule ends",
 					Message:      "limited by max instances 6",
-				}))
+				})))
 
 			})
 		})
@@ -1036,7 +2006,7 @@ var _ = Describe("ScalingEngine", func() {
 			It("should error", func() {
 				Expect(err).To(HaveOccurred())
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -1045,7 +2015,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "schedule ends",
 					Error:        "failed to get app info: an error",
-				}))
+				})))
 
 			})
 		})
@@ -1062,7 +2032,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-policy"))
 				Eventually(buffer).Should(gbytes.Say("an error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -1071,7 +2041,7 @@ var _ = Describe("ScalingEngine", func() {
 					NewInstances: -1,
 					Reason:       "schedule ends",
 					Error:        "failed to get app policy",
-				}))
+				})))
 
 			})
 		})
@@ -1102,7 +2072,7 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-set-app-instances"))
 				Eventually(buffer).Should(gbytes.Say("an error"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(withScheduleFields(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
@@ -1112,7 +2082,7 @@ var _ = Describe("ScalingEngine", func() {
 					Reason:       "schedule ends",
 					Error:        "failed to set app instances: an error",
 					Message:      "limited by min instances 3",
-				}))
+				})))
 
 			})
 		})
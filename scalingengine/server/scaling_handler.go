@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
@@ -14,6 +15,14 @@ import (
 	"code.cloudfoundry.org/lager/v3"
 )
 
+const (
+	defaultScalingEventsLimit = 100
+	maxScalingEventsLimit     = 1000
+
+	// maxLastScalingHistoriesApps is the number of apps whose last scaling can be requested at once.
+	maxLastScalingHistoriesApps = 100
+)
+
 type ScalingHandler struct {
 	logger          lager.Logger
 	scalingEngineDB db.ScalingEngineDB
@@ -165,3 +174,308 @@ func (h *ScalingHandler) GetActiveSchedule(w http.ResponseWriter, r *http.Reques
 		logger.Error("failed-to-write-body", err)
 	}
 }
+
+func (h *ScalingHandler) GetInstanceHourUsage(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("get-instance-hour-usage", lager.Data{"appid": appId})
+	logger.Info("handle-instance-hour-usage-get")
+
+	usage, err := h.scalingEngine.GetInstanceHourUsage(r.Context(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-instance-hour-usage", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting instance-hour usage"})
+		return
+	}
+
+	if usage == nil {
+		handlers.WriteJSONResponse(w, http.StatusNotFound, models.ErrorResponse{
+			Code:    "Not-Found",
+			Message: "Instance-hour budget not found",
+		})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, usage)
+}
+
+func (h *ScalingHandler) GetScalingAnalytics(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("get-scaling-analytics", lager.Data{"appid": appId})
+	logger.Info("handle-scaling-analytics-get")
+
+	start, end, err := parseTimeRange(r)
+	if err != nil {
+		logger.Error("failed-to-parse-time-range", err)
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: err.Error()})
+		return
+	}
+
+	analytics, err := h.scalingEngine.GetScalingAnalytics(r.Context(), appId, start, end)
+	if err != nil {
+		logger.Error("failed-to-get-scaling-analytics", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting scaling analytics"})
+		return
+	}
+
+	if analytics == nil {
+		handlers.WriteJSONResponse(w, http.StatusNotFound, models.ErrorResponse{
+			Code:    "Not-Found",
+			Message: "Policy not found",
+		})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, analytics)
+}
+
+// GetScalingState returns the current scaling state of an app, which the public API combines into the status of
+// the app.
+func (h *ScalingHandler) GetScalingState(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("get-scaling-state", lager.Data{"appid": appId})
+	logger.Debug("handle-scaling-state-get")
+
+	state, err := h.scalingEngine.GetScalingState(r.Context(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-scaling-state", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting scaling state"})
+		return
+	}
+
+	if state == nil {
+		handlers.WriteJSONResponse(w, http.StatusNotFound, models.ErrorResponse{
+			Code:    "Not-Found",
+			Message: "Policy not found",
+		})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, state)
+}
+
+// GetLastScalingHistories returns the latest scaling history entry of each of the apps given by the app-id
+// parameters, keyed by app. Apps which have never been scaled are missing in the result.
+func (h *ScalingHandler) GetLastScalingHistories(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	appIds := r.URL.Query()["app-id"]
+
+	logger := h.logger.Session("get-last-scaling-histories", lager.Data{"appids": appIds})
+	logger.Debug("handle-last-scaling-histories-get")
+
+	if len(appIds) > maxLastScalingHistoriesApps {
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: fmt.Sprintf("at most %d app-id parameters are allowed", maxLastScalingHistoriesApps)})
+		return
+	}
+
+	result := map[string]*models.AppScalingHistory{}
+	for _, appId := range appIds {
+		histories, err := h.scalingEngineDB.RetrieveScalingHistories(r.Context(), appId, 0, -1, db.DESC, db.ScalingHistoryFilter{IncludeAll: true}, 1, 1)
+		if err != nil {
+			logger.Error("failed-to-retrieve-scaling-histories", err, lager.Data{"appid": appId})
+			handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+				Code:    "Internal-Server-Error",
+				Message: "Error getting scaling histories from database"})
+			return
+		}
+		if len(histories) > 0 {
+			result[appId] = histories[0]
+		}
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+func (h *ScalingHandler) GetScalingDecision(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+	decisionId := vars["decisionid"]
+
+	logger := h.logger.Session("get-scaling-decision", lager.Data{"appid": appId, "decisionid": decisionId})
+	logger.Info("handle-scaling-decision-get")
+
+	decision, err := h.scalingEngineDB.RetrieveScalingDecision(r.Context(), appId, decisionId)
+	if err != nil {
+		logger.Error("failed-to-retrieve-scaling-decision", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting scaling decision from database"})
+		return
+	}
+
+	if decision == nil {
+		handlers.WriteJSONResponse(w, http.StatusNotFound, models.ErrorResponse{
+			Code:    "Not-Found",
+			Message: "Decision not found",
+		})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, decision)
+}
+
+// GetGroupScalingHistories returns the scaling histories of an app group between the start-time and the optional
+// end-time, the latest first. The optional limit parameter restricts their number.
+func (h *ScalingHandler) GetGroupScalingHistories(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	groupId := vars["groupid"]
+
+	logger := h.logger.Session("get-group-scaling-histories", lager.Data{"groupid": groupId})
+	logger.Info("handle-group-scaling-histories-get")
+
+	start, end, err := parseTimeRange(r)
+	var limit int
+	if err == nil {
+		limit, err = parseLimit(r)
+	}
+	if err != nil {
+		logger.Error("failed-to-parse-query", err)
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: err.Error()})
+		return
+	}
+
+	histories, err := h.scalingEngineDB.RetrieveGroupScalingHistories(r.Context(), groupId, start, end, limit)
+	if err != nil {
+		logger.Error("failed-to-retrieve-group-scaling-histories", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting group scaling histories from database"})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, histories)
+}
+
+// GetScalingEvents returns the events of an app after the event id given as after parameter. Without it, no events
+// are returned, but the id of the latest event, after which a stream of the upcoming events starts.
+func (h *ScalingHandler) GetScalingEvents(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("get-scaling-events", lager.Data{"appid": appId})
+	logger.Debug("handle-scaling-events-get")
+
+	afterId, limit, err := parseScalingEventsQuery(r)
+	if err != nil {
+		logger.Error("failed-to-parse-query", err)
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: err.Error()})
+		return
+	}
+
+	result := &models.ScalingEvents{Events: []*models.ScalingEvent{}}
+	if afterId < 0 {
+		result.LastEventId, err = h.scalingEngineDB.GetLastScalingEventId(r.Context(), appId)
+	} else {
+		result.LastEventId = afterId
+		result.Events, err = h.scalingEngineDB.RetrieveScalingEvents(r.Context(), appId, afterId, limit)
+		if len(result.Events) > 0 {
+			result.LastEventId = result.Events[len(result.Events)-1].Id
+		}
+	}
+	if err != nil {
+		logger.Error("failed-to-retrieve-scaling-events", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting scaling events from database"})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// SaveEvaluationEvent stores an evaluation of the scaling rules of an app, which the eventgenerator publishes to the
+// live event stream of the app.
+func (h *ScalingHandler) SaveEvaluationEvent(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("save-evaluation-event", lager.Data{"appid": appId})
+	logger.Debug("handle-evaluation-event-post")
+
+	event := &models.ScalingEvent{}
+	err := json.NewDecoder(r.Body).Decode(event)
+	if err != nil || event.Evaluation == nil {
+		logger.Info("failed-to-decode", lager.Data{"error": err})
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: "Incorrect evaluation event in request body"})
+		return
+	}
+
+	err = h.scalingEngineDB.SaveScalingEvent(r.Context(), models.NewEvaluationEvent(appId, event.Timestamp, event.Evaluation))
+	if err != nil {
+		logger.Error("failed-to-save-evaluation-event", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error saving evaluation event"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseScalingEventsQuery reads the optional after and limit parameters, a missing after parameter is returned as -1.
+func parseScalingEventsQuery(r *http.Request) (int64, int, error) {
+	afterId := int64(-1)
+	if after := r.URL.Query().Get("after"); after != "" {
+		var err error
+		afterId, err = strconv.ParseInt(after, 10, 64)
+		if err != nil || afterId < 0 {
+			return 0, 0, errors.New("after must be a non-negative integer")
+		}
+	}
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	return afterId, limit, nil
+}
+
+// parseLimit reads the optional limit parameter, which defaults to defaultScalingEventsLimit.
+func parseLimit(r *http.Request) (int, error) {
+	limit := defaultScalingEventsLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxScalingEventsLimit {
+			return 0, fmt.Errorf("limit must be an integer between 1 and %d", maxScalingEventsLimit)
+		}
+	}
+	return limit, nil
+}
+
+// parseTimeRange reads the mandatory start-time and the optional end-time, which defaults to -1 for now.
+func parseTimeRange(r *http.Request) (int64, int64, error) {
+	startTime := r.URL.Query().Get("start-time")
+	if startTime == "" {
+		return 0, 0, errors.New("start-time is required")
+	}
+	start, err := strconv.ParseInt(startTime, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, errors.New("start-time must be a non-negative integer")
+	}
+
+	end := int64(-1)
+	if endTime := r.URL.Query().Get("end-time"); endTime != "" {
+		end, err = strconv.ParseInt(endTime, 10, 64)
+		if err != nil {
+			return 0, 0, errors.New("end-time must be an integer")
+		}
+		if end >= 0 && end < start {
+			return 0, 0, errors.New("end-time must not be before start-time")
+		}
+	}
+	return start, end, nil
+}
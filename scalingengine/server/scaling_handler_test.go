@@ -2,13 +2,17 @@ package server_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/server"
@@ -342,4 +346,581 @@ var _ = Describe("ScalingHandler", func() {
 			})
 		})
 	})
+
+	Describe("GetInstanceHourUsage", func() {
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/instance_hour_usage", nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetInstanceHourUsage(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when the app has an instance-hour budget", func() {
+			var usage *models.InstanceHourUsage
+
+			BeforeEach(func() {
+				usage = &models.InstanceHourUsage{
+					Budget:                models.InstanceHourBudget{InstanceHours: 2000, Period: models.BudgetPeriodMonth},
+					PeriodStart:           time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+					PeriodEnd:             time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC),
+					CurrentInstances:      2,
+					ConsumedInstanceHours: 672,
+					ForecastInstanceHours: 1440,
+				}
+				scalingEngine.GetInstanceHourUsageReturns(usage, nil)
+			})
+
+			It("returns 200 with the usage in message body", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, appId := scalingEngine.GetInstanceHourUsageArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+
+				actualUsage := &models.InstanceHourUsage{}
+				err = json.Unmarshal(resp.Body.Bytes(), actualUsage)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualUsage).To(Equal(usage))
+			})
+		})
+
+		Context("when the app has no instance-hour budget", func() {
+			BeforeEach(func() {
+				scalingEngine.GetInstanceHourUsageReturns(nil, nil)
+			})
+
+			It("returns 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("when getting the usage fails", func() {
+			BeforeEach(func() {
+				scalingEngine.GetInstanceHourUsageReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting instance-hour usage",
+				}))
+			})
+		})
+	})
+
+	Describe("GetScalingAnalytics", func() {
+		var query string
+
+		BeforeEach(func() {
+			query = "start-time=100&end-time=500"
+		})
+
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/scaling_analytics?"+query, nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetScalingAnalytics(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when the app has a policy", func() {
+			var analytics *models.ScalingAnalytics
+
+			BeforeEach(func() {
+				meanSeconds := 120.5
+				analytics = &models.ScalingAnalytics{
+					StartTime:                 100,
+					EndTime:                   500,
+					InstanceHours:             12.5,
+					ScaleOuts:                 3,
+					ScaleIns:                  2,
+					Failures:                  1,
+					InstanceMinCount:          1,
+					InstanceMaxCount:          5,
+					SecondsAtMin:              60,
+					SecondsAtMax:              30,
+					MeanSecondsBetweenActions: &meanSeconds,
+				}
+				scalingEngine.GetScalingAnalyticsReturns(analytics, nil)
+			})
+
+			It("returns 200 with the analytics in message body", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, appId, start, end := scalingEngine.GetScalingAnalyticsArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(start).To(Equal(int64(100)))
+				Expect(end).To(Equal(int64(500)))
+
+				actualAnalytics := &models.ScalingAnalytics{}
+				err = json.Unmarshal(resp.Body.Bytes(), actualAnalytics)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualAnalytics).To(Equal(analytics))
+			})
+
+			Context("when the end time is omitted", func() {
+				BeforeEach(func() {
+					query = "start-time=100"
+				})
+
+				It("aggregates until now", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+					_, _, _, end := scalingEngine.GetScalingAnalyticsArgsForCall(0)
+					Expect(end).To(Equal(int64(-1)))
+				})
+			})
+		})
+
+		DescribeTable("when the time range is invalid",
+			func(invalidQuery string, message string) {
+				req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/scaling_analytics?"+invalidQuery, nil)
+				Expect(err).ToNot(HaveOccurred())
+				resp = httptest.NewRecorder()
+				handler.GetScalingAnalytics(resp, req, map[string]string{"appid": "an-app-id"})
+
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson.Message).To(Equal(message))
+			},
+			Entry("without start time", "end-time=500", "start-time is required"),
+			Entry("with an invalid start time", "start-time=abc", "start-time must be a non-negative integer"),
+			Entry("with an invalid end time", "start-time=100&end-time=abc", "end-time must be an integer"),
+			Entry("with an end before the start", "start-time=500&end-time=100", "end-time must not be before start-time"),
+		)
+
+		Context("when the app has no policy", func() {
+			BeforeEach(func() {
+				scalingEngine.GetScalingAnalyticsReturns(nil, nil)
+			})
+
+			It("returns 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("when getting the analytics fails", func() {
+			BeforeEach(func() {
+				scalingEngine.GetScalingAnalyticsReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting scaling analytics",
+				}))
+			})
+		})
+	})
+
+	Describe("GetScalingDecision", func() {
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/scaling_decisions/a-decision-id", nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetScalingDecision(resp, req, map[string]string{"appid": "an-app-id", "decisionid": "a-decision-id"})
+		})
+
+		Context("when the decision exists", func() {
+			var decision *models.DecisionTrace
+
+			BeforeEach(func() {
+				decision = &models.DecisionTrace{
+					DecisionId: "a-decision-id",
+					AppId:      "an-app-id",
+					Timestamp:  111,
+					Evaluation: []models.RuleEvaluation{{
+						MetricType: "cpu",
+						Threshold:  80,
+						Operator:   ">",
+						Adjustment: "+1",
+						Samples:    []models.MetricSample{{Timestamp: 100, Value: "90", Unit: "%"}},
+						Breached:   true,
+					}},
+					Scaling:    &models.AppScalingHistory{AppId: "an-app-id", Timestamp: 111, OldInstances: 2, NewInstances: 3, DecisionId: "a-decision-id"},
+					CFResponse: &models.CFScalingResponse{Instances: 3},
+				}
+				scalingEngineDB.RetrieveScalingDecisionReturns(decision, nil)
+			})
+
+			It("returns 200 with the decision in message body", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, appId, decisionId := scalingEngineDB.RetrieveScalingDecisionArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(decisionId).To(Equal("a-decision-id"))
+
+				actualDecision := &models.DecisionTrace{}
+				err = json.Unmarshal(resp.Body.Bytes(), actualDecision)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualDecision).To(Equal(decision))
+			})
+		})
+
+		Context("when the decision does not exist", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingDecisionReturns(nil, nil)
+			})
+
+			It("returns 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("when retrieving the decision fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingDecisionReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("GetScalingEvents", func() {
+		var query string
+
+		BeforeEach(func() {
+			query = "?after=5&limit=2"
+		})
+
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/scaling_events"+query, nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetScalingEvents(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when events after the given id exist", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingEventsReturns([]*models.ScalingEvent{
+					{Id: 6, AppId: "an-app-id", Timestamp: 111, Type: models.ScalingEventScaling, History: &models.AppScalingHistory{AppId: "an-app-id", Timestamp: 111, OldInstances: 2, NewInstances: 3}},
+					{Id: 8, AppId: "an-app-id", Timestamp: 222, Type: models.ScalingEventScheduleEnd, History: &models.AppScalingHistory{AppId: "an-app-id", Timestamp: 222, OldInstances: 3, NewInstances: 2}},
+				}, nil)
+			})
+
+			It("returns 200 with the events and the id of the last one", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, appId, afterId, limit := scalingEngineDB.RetrieveScalingEventsArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(afterId).To(Equal(int64(5)))
+				Expect(limit).To(Equal(2))
+
+				events := &models.ScalingEvents{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), events)).To(Succeed())
+				Expect(events.Events).To(HaveLen(2))
+				Expect(events.Events[1].Type).To(Equal(models.ScalingEventScheduleEnd))
+				Expect(events.LastEventId).To(Equal(int64(8)))
+			})
+		})
+
+		Context("when there are no events after the given id", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingEventsReturns([]*models.ScalingEvent{}, nil)
+			})
+
+			It("returns the given id as the id of the last event", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"events":[],"last_event_id":5}`))
+			})
+		})
+
+		Context("when no id is given", func() {
+			BeforeEach(func() {
+				query = ""
+				scalingEngineDB.GetLastScalingEventIdReturns(42, nil)
+			})
+
+			It("returns no events but the id of the latest one", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"events":[],"last_event_id":42}`))
+				Expect(scalingEngineDB.RetrieveScalingEventsCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the query is invalid", func() {
+			DescribeTable("returns 400",
+				func(invalidQuery string, message string) {
+					req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/scaling_events"+invalidQuery, nil)
+					Expect(err).ToNot(HaveOccurred())
+					resp = httptest.NewRecorder()
+					handler.GetScalingEvents(resp, req, map[string]string{"appid": "an-app-id"})
+
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					errJson := &models.ErrorResponse{}
+					Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+					Expect(errJson).To(Equal(&models.ErrorResponse{Code: "Bad-Request", Message: message}))
+				},
+				Entry("with a negative id", "?after=-1", "after must be a non-negative integer"),
+				Entry("with an id which is not a number", "?after=abc", "after must be a non-negative integer"),
+				Entry("with a limit of zero", "?after=1&limit=0", "limit must be an integer between 1 and 1000"),
+				Entry("with a limit which is too large", "?after=1&limit=1001", "limit must be an integer between 1 and 1000"),
+			)
+		})
+
+		Context("when retrieving the events fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingEventsReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson).To(Equal(&models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting scaling events from database"}))
+			})
+		})
+	})
+
+	Describe("SaveEvaluationEvent", func() {
+		BeforeEach(func() {
+			body = []byte(`{"timestamp": 111, "type": "scaling", "evaluation": [{"rule_index": 0, "metric_type": "memoryused", "threshold": 100, "operator": ">", "adjustment": "+1", "samples": [], "breached": false}]}`)
+		})
+
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodPost, "/v1/apps/an-app-id/scaling_events/evaluations", bytes.NewReader(body))
+			Expect(err).ToNot(HaveOccurred())
+			handler.SaveEvaluationEvent(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		It("saves an evaluation event of the app", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(scalingEngineDB.SaveScalingEventCallCount()).To(Equal(1))
+			_, event := scalingEngineDB.SaveScalingEventArgsForCall(0)
+			Expect(event.AppId).To(Equal("an-app-id"))
+			Expect(event.Timestamp).To(Equal(int64(111)))
+			Expect(event.Type).To(Equal(models.ScalingEventEvaluation))
+			Expect(event.Evaluation).To(HaveLen(1))
+			Expect(event.Evaluation[0].MetricType).To(Equal("memoryused"))
+		})
+
+		Context("when the body has no evaluation", func() {
+			BeforeEach(func() {
+				body = []byte(`{"timestamp": 111}`)
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Bad-Request","message":"Incorrect evaluation event in request body"}`))
+				Expect(scalingEngineDB.SaveScalingEventCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when saving the event fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.SaveScalingEventReturns(errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Internal-Server-Error","message":"Error saving evaluation event"}`))
+			})
+		})
+	})
+
+	Describe("GetGroupScalingHistories", func() {
+		var query string
+
+		BeforeEach(func() {
+			query = "?start-time=100&end-time=300&limit=10"
+		})
+
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "/v1/app_groups/a-group-id/scaling_histories"+query, nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetGroupScalingHistories(resp, req, map[string]string{"groupid": "a-group-id"})
+		})
+
+		Context("when the histories are retrieved", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveGroupScalingHistoriesReturns([]*models.GroupScalingHistory{{
+					GroupId:    "a-group-id",
+					Timestamp:  200,
+					MetricType: "throughput",
+					Status:     models.GroupScalingRolledBack,
+					Apps: []models.GroupMemberScaling{
+						{AppId: "leader-app-id", OldInstances: 2, NewInstances: 3, Status: models.GroupMemberRolledBack},
+						{AppId: "member-app-id", OldInstances: 4, NewInstances: 6, Status: models.GroupMemberFailed, Error: "cf error"},
+					},
+					Error: "cf error",
+				}}, nil)
+			})
+
+			It("returns 200 with the histories", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, groupId, start, end, limit := scalingEngineDB.RetrieveGroupScalingHistoriesArgsForCall(0)
+				Expect(groupId).To(Equal("a-group-id"))
+				Expect(start).To(Equal(int64(100)))
+				Expect(end).To(Equal(int64(300)))
+				Expect(limit).To(Equal(10))
+
+				var histories []*models.GroupScalingHistory
+				Expect(json.Unmarshal(resp.Body.Bytes(), &histories)).To(Succeed())
+				Expect(histories).To(HaveLen(1))
+				Expect(histories[0].Status).To(Equal(models.GroupScalingRolledBack))
+				Expect(histories[0].Apps[1].Status).To(Equal(models.GroupMemberFailed))
+			})
+		})
+
+		Context("when the start time is missing", func() {
+			BeforeEach(func() {
+				query = "?limit=10"
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(scalingEngineDB.RetrieveGroupScalingHistoriesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the limit is invalid", func() {
+			BeforeEach(func() {
+				query = "?start-time=100&limit=0"
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson.Message).To(Equal("limit must be an integer between 1 and 1000"))
+			})
+		})
+
+		Context("when retrieving the histories fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveGroupScalingHistoriesReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("GetScalingState", func() {
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "/v1/apps/an-app-id/scaling_state", nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetScalingState(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when the app has a policy", func() {
+			var state *models.AppScalingState
+
+			BeforeEach(func() {
+				state = &models.AppScalingState{
+					AppId:       "an-app-id",
+					AppState:    models.AppStatusStarted,
+					Instances:   2,
+					InstanceMin: 1,
+					InstanceMax: 5,
+					Cooldowns:   []models.Cooldown{{Direction: models.ScalingDirectionOut, ExpiredAt: 300}},
+				}
+				scalingEngine.GetScalingStateReturns(state, nil)
+			})
+
+			It("returns 200 with the scaling state in message body", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, appId := scalingEngine.GetScalingStateArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+
+				actualState := &models.AppScalingState{}
+				err = json.Unmarshal(resp.Body.Bytes(), actualState)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualState).To(Equal(state))
+			})
+		})
+
+		Context("when the app has no policy", func() {
+			BeforeEach(func() {
+				scalingEngine.GetScalingStateReturns(nil, nil)
+			})
+
+			It("returns 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson).To(Equal(&models.ErrorResponse{Code: "Not-Found", Message: "Policy not found"}))
+			})
+		})
+
+		Context("when getting the scaling state fails", func() {
+			BeforeEach(func() {
+				scalingEngine.GetScalingStateReturns(nil, errors.New("an error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson).To(Equal(&models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting scaling state"}))
+			})
+		})
+	})
+
+	Describe("GetLastScalingHistories", func() {
+		var query string
+
+		BeforeEach(func() {
+			query = "app-id=an-app-id&app-id=another-app-id"
+			scalingEngineDB.RetrieveScalingHistoriesStub = func(_ context.Context, appId string, _ int64, _ int64, _ db.OrderType, _ db.ScalingHistoryFilter, _ int, _ int) ([]*models.AppScalingHistory, error) {
+				if appId == "an-app-id" {
+					return []*models.AppScalingHistory{{AppId: appId, Timestamp: 300, NewInstances: 3}}, nil
+				}
+				return []*models.AppScalingHistory{}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "/v1/last_scaling_histories?"+query, nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler.GetLastScalingHistories(resp, req, map[string]string{})
+		})
+
+		It("returns 200 with the last scaling history of each app which has been scaled", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(scalingEngineDB.RetrieveScalingHistoriesCallCount()).To(Equal(2))
+			_, _, _, end, order, filter, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+			Expect(end).To(Equal(int64(-1)))
+			Expect(order).To(Equal(db.DESC))
+			Expect(filter.IncludeAll).To(BeTrue())
+			Expect(page).To(Equal(1))
+			Expect(resultsPerPage).To(Equal(1))
+
+			histories := map[string]*models.AppScalingHistory{}
+			Expect(json.Unmarshal(resp.Body.Bytes(), &histories)).To(Succeed())
+			Expect(histories).To(Equal(map[string]*models.AppScalingHistory{
+				"an-app-id": {AppId: "an-app-id", Timestamp: 300, NewInstances: 3},
+			}))
+		})
+
+		Context("when too many apps are requested", func() {
+			BeforeEach(func() {
+				query = strings.Repeat("app-id=an-app-id&", 101)
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson).To(Equal(&models.ErrorResponse{Code: "Bad-Request", Message: "at most 100 app-id parameters are allowed"}))
+			})
+		})
+
+		Context("when retrieving the scaling histories fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingHistoriesStub = nil
+				scalingEngineDB.RetrieveScalingHistoriesReturns(nil, errors.New("an error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				errJson := &models.ErrorResponse{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), errJson)).To(Succeed())
+				Expect(errJson).To(Equal(&models.ErrorResponse{Code: "Internal-Server-Error", Message: "Error getting scaling histories from database"}))
+			})
+		})
+	})
 })
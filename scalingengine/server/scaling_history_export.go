@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// exportBatchSize is the number of scaling histories which are read from the database at once during an export.
+const exportBatchSize = 500
+
+var csvHistoryHeader = []string{"app_id", "timestamp", "scaling_type", "status", "old_instances", "new_instances", "reason", "message", "error", "emergency",
+	"rule_index", "metric_type", "observed_value", "threshold", "operator", "adjustment", "schedule_id", "cooldown_expired_at", "correlation_id", "decision_id"}
+
+type historyWriter interface {
+	Write(history *models.AppScalingHistory) error
+	Flush() error
+}
+
+type newHistoryWriterFunc func(w io.Writer) (historyWriter, error)
+
+// export streams all scaling histories matching the filter to the returned reader. They are read from the
+// database batch by batch while the reader is consumed, so that exports do not need to fit into memory.
+func (h *ScalingHistoryHandler) export(ctx context.Context, logger lager.Logger, appId string, start int64, end int64, order db.OrderType, filter db.ScalingHistoryFilter, newHistoryWriter newHistoryWriterFunc) io.ReadCloser {
+	if end < 0 {
+		// fix the end of the time range, as it would move on with every batch otherwise
+		end = time.Now().UnixNano()
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		err := h.writeHistories(ctx, writer, appId, start, end, order, filter, newHistoryWriter)
+		if err != nil {
+			logger.Error("failed-to-export-histories", err)
+		}
+		_ = writer.CloseWithError(err)
+	}()
+	return reader
+}
+
+func (h *ScalingHistoryHandler) writeHistories(ctx context.Context, w io.Writer, appId string, start int64, end int64, order db.OrderType, filter db.ScalingHistoryFilter, newHistoryWriter newHistoryWriterFunc) error {
+	historyWriter, err := newHistoryWriter(w)
+	if err != nil {
+		return err
+	}
+
+	for page := 1; ; page++ {
+		histories, err := h.scalingEngineDB.RetrieveScalingHistories(ctx, appId, start, end, order, filter, page, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, history := range histories {
+			if err := historyWriter.Write(history); err != nil {
+				return err
+			}
+		}
+		if err := historyWriter.Flush(); err != nil {
+			return err
+		}
+		if len(histories) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+type csvHistoryWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVHistoryWriter(w io.Writer) (historyWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHistoryHeader); err != nil {
+		return nil, err
+	}
+	return &csvHistoryWriter{writer: writer}, nil
+}
+
+func (c *csvHistoryWriter) Write(history *models.AppScalingHistory) error {
+	cooldownExpiredAt := ""
+	if history.CooldownExpiredAt != 0 {
+		cooldownExpiredAt = formatInt64(history.CooldownExpiredAt)
+	}
+	return c.writer.Write([]string{
+		history.AppId,
+		formatInt64(history.Timestamp),
+		strconv.Itoa(int(history.ScalingType)),
+		strconv.Itoa(int(history.Status)),
+		strconv.Itoa(history.OldInstances),
+		strconv.Itoa(history.NewInstances),
+		history.Reason,
+		history.Message,
+		history.Error,
+		strconv.FormatBool(history.Emergency),
+		formatOptional(history.RuleIndex, strconv.Itoa),
+		history.MetricType,
+		formatOptional(history.ObservedValue, formatInt64),
+		formatOptional(history.Threshold, formatInt64),
+		history.Operator,
+		history.Adjustment,
+		history.ScheduleId,
+		cooldownExpiredAt,
+		history.CorrelationId,
+		history.DecisionId,
+	})
+}
+
+// formatOptional formats the value with the given function, values which are not set are left empty.
+func formatOptional[T any](value *T, format func(T) string) string {
+	if value == nil {
+		return ""
+	}
+	return format(*value)
+}
+
+func formatInt64(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
+
+func (c *csvHistoryWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type ndjsonHistoryWriter struct {
+	writer *bufio.Writer
+}
+
+func newNDJSONHistoryWriter(w io.Writer) (historyWriter, error) {
+	return &ndjsonHistoryWriter{writer: bufio.NewWriter(w)}, nil
+}
+
+func (n *ndjsonHistoryWriter) Write(history *models.AppScalingHistory) error {
+	entry := toHistoryEntry(history)
+	line, err := entry.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if _, err := n.writer.Write(line); err != nil {
+		return err
+	}
+	return n.writer.WriteByte('\n')
+}
+
+func (n *ndjsonHistoryWriter) Flush() error {
+	return n.writer.Flush()
+}
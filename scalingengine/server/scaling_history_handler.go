@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
 	"github.com/ogen-go/ogen/ogenerrors"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
@@ -55,7 +56,7 @@ func (h *ScalingHistoryHandler) NewError(_ context.Context, err error) *scalingh
 	return result
 }
 
-func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Context, params scalinghistory.V1AppsGUIDScalingHistoriesGetParams) (*scalinghistory.History, error) {
+func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Context, params scalinghistory.V1AppsGUIDScalingHistoriesGetParams) (scalinghistory.V1AppsGUIDScalingHistoriesGetRes, error) {
 	appId := params.GUID
 	// actually not necessary if a default is provided in the schema, however this is not exposed yet:
 	// https://github.com/ogen-go/ogen/issues/966
@@ -77,7 +78,7 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	if orderDirection == scalinghistory.V1AppsGUIDScalingHistoriesGetOrderDirectionAsc {
 		dbOrder = db.ASC
 	}
-	includeAll := false
+	filter := toScalingHistoryFilter(params)
 	page := params.Page.Or(1)
 	resultsPerPage := params.ResultsPerPage.Or(50)
 
@@ -86,12 +87,26 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	parameters.Add("end-time", strconv.Itoa(endTime))
 	parameters.Add("order-direction", string(orderDirection))
 	parameters.Add("results-per-page", strconv.Itoa(resultsPerPage))
+	addFilterParameters(parameters, params)
 
-	logger := h.logger.Session("get-scaling-histories", helpers.AddTraceID(ctx, lager.Data{"parameters": parameters, "app-guid": appId}))
+	mediaType := handlers.NegotiateMediaType(handlers.AcceptHeaderFromContext(ctx), handlers.MediaTypeJSON, handlers.MediaTypeCSV, handlers.MediaTypeNDJSON)
+
+	logger := h.logger.Session("get-scaling-histories", helpers.AddTraceID(ctx, lager.Data{"parameters": parameters, "app-guid": appId, "media-type": mediaType}))
 	logger.Info("start")
 	defer logger.Info("end")
 
-	count, err := h.scalingEngineDB.CountScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), includeAll)
+	switch mediaType {
+	case handlers.MediaTypeCSV:
+		return &scalinghistory.V1AppsGUIDScalingHistoriesGetOKTextCsv{
+			Data: h.export(ctx, logger, string(appId), int64(startTime), int64(endTime), dbOrder, filter, newCSVHistoryWriter),
+		}, nil
+	case handlers.MediaTypeNDJSON:
+		return &scalinghistory.V1AppsGUIDScalingHistoriesGetOKApplicationXNdjson{
+			Data: h.export(ctx, logger, string(appId), int64(startTime), int64(endTime), dbOrder, filter, newNDJSONHistoryWriter),
+		}, nil
+	}
+
+	count, err := h.scalingEngineDB.CountScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), filter)
 	if err != nil {
 		logger.Error("failed-to-count-histories", err)
 		return nil, errors.New("error counting scaling histories in database")
@@ -99,7 +114,7 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	totalPages := int(math.Ceil(float64(count) / float64(resultsPerPage)))
 	logger.Debug("count-results", lager.Data{"count": count, "totalPages": totalPages})
 
-	histories, err := h.scalingEngineDB.RetrieveScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), dbOrder, includeAll, page, resultsPerPage)
+	histories, err := h.scalingEngineDB.RetrieveScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), dbOrder, filter, page, resultsPerPage)
 	if err != nil {
 		logger.Error("failed-to-retrieve-histories", err)
 		return nil, errors.New("error getting scaling histories from database")
@@ -108,27 +123,7 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	resources := make([]scalinghistory.HistoryEntry, len(histories))
 
 	for i, item := range histories {
-		entry := scalinghistory.HistoryEntry{
-			AppID:        scalinghistory.NewOptGUID(scalinghistory.GUID(item.AppId)),
-			Status:       scalinghistory.NewOptHistoryEntryStatus(scalinghistory.HistoryEntryStatus(item.Status)),
-			Timestamp:    scalinghistory.NewOptInt(int(item.Timestamp)),
-			ScalingType:  scalinghistory.NewOptHistoryEntryScalingType(scalinghistory.HistoryEntryScalingType(item.ScalingType)),
-			OldInstances: scalinghistory.NewOptInt64(int64(item.OldInstances)),
-			NewInstances: scalinghistory.NewOptInt64(int64(item.NewInstances)),
-			Reason:       scalinghistory.NewOptString(item.Reason),
-			Message:      scalinghistory.NewOptString(item.Message),
-		}
-
-		switch item.Status {
-		case models.ScalingStatusSucceeded:
-			entry.SetOneOf(scalinghistory.NewHistorySuccessEntryHistoryEntrySum(scalinghistory.HistorySuccessEntry{}))
-		case models.ScalingStatusIgnored:
-			entry.SetOneOf(scalinghistory.NewHistoryIgnoreEntryHistoryEntrySum(scalinghistory.HistoryIgnoreEntry{IgnoreReason: scalinghistory.NewOptString(item.Message)}))
-		case models.ScalingStatusFailed:
-			entry.SetOneOf(scalinghistory.NewHistoryErrorEntryHistoryEntrySum(scalinghistory.HistoryErrorEntry{Error: scalinghistory.NewOptString(item.Error)}))
-		}
-
-		resources[i] = entry
+		resources[i] = toHistoryEntry(item)
 	}
 
 	prevURL := scalinghistory.OptURI{}
@@ -171,3 +166,86 @@ func getPageURL(appId scalinghistory.GUID, page int, parameters url.Values) (sca
 	pageURL.RawQuery = parameters.Encode()
 	return scalinghistory.NewOptURI(pageURL), nil
 }
+
+func toScalingHistoryFilter(params scalinghistory.V1AppsGUIDScalingHistoriesGetParams) db.ScalingHistoryFilter {
+	filter := db.ScalingHistoryFilter{}
+	for _, status := range params.Status {
+		filter.Statuses = append(filter.Statuses, models.ScalingStatus(status))
+	}
+	for _, scalingType := range params.ScalingType {
+		filter.ScalingTypes = append(filter.ScalingTypes, models.ScalingType(scalingType))
+	}
+	if params.MinInstanceDelta.IsSet() {
+		filter.MinInstanceDelta = &params.MinInstanceDelta.Value
+	}
+	if params.MaxInstanceDelta.IsSet() {
+		filter.MaxInstanceDelta = &params.MaxInstanceDelta.Value
+	}
+	return filter
+}
+
+func addFilterParameters(parameters url.Values, params scalinghistory.V1AppsGUIDScalingHistoriesGetParams) {
+	for _, status := range params.Status {
+		parameters.Add("status", strconv.Itoa(int(status)))
+	}
+	for _, scalingType := range params.ScalingType {
+		parameters.Add("scaling-type", strconv.Itoa(int(scalingType)))
+	}
+	if params.MinInstanceDelta.IsSet() {
+		parameters.Add("min-instance-delta", strconv.Itoa(params.MinInstanceDelta.Value))
+	}
+	if params.MaxInstanceDelta.IsSet() {
+		parameters.Add("max-instance-delta", strconv.Itoa(params.MaxInstanceDelta.Value))
+	}
+}
+
+func toHistoryEntry(item *models.AppScalingHistory) scalinghistory.HistoryEntry {
+	entry := scalinghistory.HistoryEntry{
+		AppID:        scalinghistory.NewOptGUID(scalinghistory.GUID(item.AppId)),
+		Status:       scalinghistory.NewOptHistoryEntryStatus(scalinghistory.HistoryEntryStatus(item.Status)),
+		Timestamp:    scalinghistory.NewOptInt(int(item.Timestamp)),
+		ScalingType:  scalinghistory.NewOptHistoryEntryScalingType(scalinghistory.HistoryEntryScalingType(item.ScalingType)),
+		OldInstances: scalinghistory.NewOptInt64(int64(item.OldInstances)),
+		NewInstances: scalinghistory.NewOptInt64(int64(item.NewInstances)),
+		Reason:       scalinghistory.NewOptString(item.Reason),
+		Message:      scalinghistory.NewOptString(item.Message),
+		Emergency:    scalinghistory.NewOptBool(item.Emergency),
+
+		MetricType:    optString(item.MetricType),
+		Operator:      optString(item.Operator),
+		Adjustment:    optString(item.Adjustment),
+		ScheduleID:    optString(item.ScheduleId),
+		CorrelationID: optString(item.CorrelationId),
+		DecisionID:    optString(item.DecisionId),
+	}
+	if item.RuleIndex != nil {
+		entry.RuleIndex = scalinghistory.NewOptInt64(int64(*item.RuleIndex))
+	}
+	if item.ObservedValue != nil {
+		entry.ObservedValue = scalinghistory.NewOptInt64(*item.ObservedValue)
+	}
+	if item.Threshold != nil {
+		entry.Threshold = scalinghistory.NewOptInt64(*item.Threshold)
+	}
+	if item.CooldownExpiredAt != 0 {
+		entry.CooldownExpiredAt = scalinghistory.NewOptInt(int(item.CooldownExpiredAt))
+	}
+
+	switch item.Status {
+	case models.ScalingStatusSucceeded:
+		entry.SetOneOf(scalinghistory.NewHistorySuccessEntryHistoryEntrySum(scalinghistory.HistorySuccessEntry{}))
+	case models.ScalingStatusIgnored:
+		entry.SetOneOf(scalinghistory.NewHistoryIgnoreEntryHistoryEntrySum(scalinghistory.HistoryIgnoreEntry{IgnoreReason: scalinghistory.NewOptString(item.Message)}))
+	case models.ScalingStatusFailed:
+		entry.SetOneOf(scalinghistory.NewHistoryErrorEntryHistoryEntrySum(scalinghistory.HistoryErrorEntry{Error: scalinghistory.NewOptString(item.Error)}))
+	}
+	return entry
+}
+
+// optString returns an unset optional for empty strings, so that they are omitted from the response.
+func optString(s string) scalinghistory.OptString {
+	if s == "" {
+		return scalinghistory.OptString{}
+	}
+	return scalinghistory.NewOptString(s)
+}
@@ -2,9 +2,13 @@ package server_test
 
 import (
 	"context"
+	"errors"
+	"io"
+	"strings"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/lager/v3/lagertest"
 
@@ -21,6 +25,8 @@ var _ = Describe("ScalingHistoryHandler", func() {
 		err                          error
 		history1, history2, history3 *models.AppScalingHistory
 		scalingHistoryParams         scalinghistory.V1AppsGUIDScalingHistoriesGetParams
+		ctx                          context.Context
+		response                     scalinghistory.V1AppsGUIDScalingHistoriesGetRes
 		history                      *scalinghistory.History
 	)
 
@@ -32,6 +38,17 @@ var _ = Describe("ScalingHistoryHandler", func() {
 		OldInstances: 2,
 		NewInstances: 4,
 		Reason:       "a reason",
+		Emergency:    true,
+
+		RuleIndex:         ptr(1),
+		MetricType:        "cpu",
+		ObservedValue:     ptr(int64(90)),
+		Threshold:         ptr(int64(80)),
+		Operator:          ">",
+		Adjustment:        "+2",
+		CooldownExpiredAt: 555,
+		CorrelationId:     "a-correlation-id",
+		DecisionId:        "a-decision-id",
 	}
 
 	history2 = &models.AppScalingHistory{
@@ -67,6 +84,7 @@ var _ = Describe("ScalingHistoryHandler", func() {
 		NewInstances: scalinghistory.OptInt64{Value: 4, Set: true},
 		Reason:       scalinghistory.OptString{Value: "a reason", Set: true},
 		Message:      scalinghistory.OptString{Value: "a message", Set: true},
+		Emergency:    scalinghistory.OptBool{Value: false, Set: true},
 		OneOf:        scalinghistory.NewHistoryIgnoreEntryHistoryEntrySum(scalinghistory.HistoryIgnoreEntry{IgnoreReason: scalinghistory.NewOptString("a message")}),
 	}
 
@@ -79,6 +97,7 @@ var _ = Describe("ScalingHistoryHandler", func() {
 		NewInstances: scalinghistory.OptInt64{Value: 4, Set: true},
 		Reason:       scalinghistory.OptString{Value: "a reason", Set: true},
 		Message:      scalinghistory.OptString{Value: "a message", Set: true},
+		Emergency:    scalinghistory.OptBool{Value: false, Set: true},
 		OneOf:        scalinghistory.NewHistoryErrorEntryHistoryEntrySum(scalinghistory.HistoryErrorEntry{Error: scalinghistory.NewOptString("an error")}),
 	}
 	history3Entry := scalinghistory.HistoryEntry{
@@ -90,7 +109,18 @@ var _ = Describe("ScalingHistoryHandler", func() {
 		NewInstances: scalinghistory.OptInt64{Value: 4, Set: true},
 		Reason:       scalinghistory.OptString{Value: "a reason", Set: true},
 		Message:      scalinghistory.OptString{Value: "", Set: true},
+		Emergency:    scalinghistory.OptBool{Value: true, Set: true},
 		OneOf:        scalinghistory.NewHistorySuccessEntryHistoryEntrySum(scalinghistory.HistorySuccessEntry{}),
+
+		RuleIndex:         scalinghistory.OptInt64{Value: 1, Set: true},
+		MetricType:        scalinghistory.OptString{Value: "cpu", Set: true},
+		ObservedValue:     scalinghistory.OptInt64{Value: 90, Set: true},
+		Threshold:         scalinghistory.OptInt64{Value: 80, Set: true},
+		Operator:          scalinghistory.OptString{Value: ">", Set: true},
+		Adjustment:        scalinghistory.OptString{Value: "+2", Set: true},
+		CooldownExpiredAt: scalinghistory.OptInt{Value: 555, Set: true},
+		CorrelationID:     scalinghistory.OptString{Value: "a-correlation-id", Set: true},
+		DecisionID:        scalinghistory.OptString{Value: "a-decision-id", Set: true},
 	}
 	BeforeEach(func() {
 		logger := lagertest.NewTestLogger("scaling-handler-test")
@@ -104,10 +134,12 @@ var _ = Describe("ScalingHistoryHandler", func() {
 			scalingHistoryParams = scalinghistory.V1AppsGUIDScalingHistoriesGetParams{
 				GUID: "an-app-id",
 			}
+			ctx = context.TODO()
 		})
 		JustBeforeEach(func() {
-			history, err = handler.V1AppsGUIDScalingHistoriesGet(context.TODO(), scalingHistoryParams)
+			response, err = handler.V1AppsGUIDScalingHistoriesGet(ctx, scalingHistoryParams)
 			Expect(err).ToNot(HaveOccurred())
+			history, _ = response.(*scalinghistory.History)
 		})
 
 		Context("when request query string is valid", func() {
@@ -119,13 +151,13 @@ var _ = Describe("ScalingHistoryHandler", func() {
 				})
 
 				It("retrieves scaling histories from database with the given start and end time and order ", func() {
-					ctx, appid, start, end, order, includeAll, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+					ctx, appid, start, end, order, filter, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
 					Expect(ctx).NotTo(BeNil())
 					Expect(appid).To(Equal("an-app-id"))
 					Expect(start).To(Equal(int64(123)))
 					Expect(end).To(Equal(int64(567)))
 					Expect(order).To(Equal(db.DESC))
-					Expect(includeAll).To(BeFalse())
+					Expect(filter).To(Equal(db.ScalingHistoryFilter{}))
 					Expect(page).To(Equal(1))
 					Expect(resultsPerPage).To(Equal(50))
 				})
@@ -199,13 +231,13 @@ var _ = Describe("ScalingHistoryHandler", func() {
 						Expect(history.TotalPages.Value).To(Equal(int64(3)))
 					})
 					By("forwarding the direction parameter to the DB", func() {
-						ctx, appid, start, end, order, includeAll, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+						ctx, appid, start, end, order, filter, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
 						Expect(ctx).NotTo(BeNil())
 						Expect(appid).To(Equal("an-app-id"))
 						Expect(start).To(Equal(int64(123)))
 						Expect(end).To(Equal(int64(567)))
 						Expect(order).To(Equal(db.ASC))
-						Expect(includeAll).To(BeFalse())
+						Expect(filter).To(Equal(db.ScalingHistoryFilter{}))
 						Expect(page).To(Equal(2))
 						Expect(resultsPerPage).To(Equal(1))
 					})
@@ -221,6 +253,135 @@ var _ = Describe("ScalingHistoryHandler", func() {
 					})
 				})
 			})
+
+			Context("when filtering", func() {
+				BeforeEach(func() {
+					scalingHistoryParams.Status = []scalinghistory.V1AppsGUIDScalingHistoriesGetStatusItem{
+						scalinghistory.V1AppsGUIDScalingHistoriesGetStatusItem1,
+						scalinghistory.V1AppsGUIDScalingHistoriesGetStatusItem2,
+					}
+					scalingHistoryParams.ScalingType = []scalinghistory.V1AppsGUIDScalingHistoriesGetScalingTypeItem{
+						scalinghistory.V1AppsGUIDScalingHistoriesGetScalingTypeItem0,
+					}
+					scalingHistoryParams.MinInstanceDelta = scalinghistory.NewOptInt(-2)
+					scalingHistoryParams.MaxInstanceDelta = scalinghistory.NewOptInt(-1)
+					scalingHistoryParams.ResultsPerPage = scalinghistory.NewOptInt(1)
+
+					scalingEngineDB.CountScalingHistoriesReturns(2, nil)
+					scalingEngineDB.RetrieveScalingHistoriesReturns([]*models.AppScalingHistory{history3}, nil)
+				})
+
+				It("filters the scaling histories in the database", func() {
+					minDelta, maxDelta := -2, -1
+					expectedFilter := db.ScalingHistoryFilter{
+						Statuses:         []models.ScalingStatus{models.ScalingStatusFailed, models.ScalingStatusIgnored},
+						ScalingTypes:     []models.ScalingType{models.ScalingTypeDynamic},
+						MinInstanceDelta: &minDelta,
+						MaxInstanceDelta: &maxDelta,
+					}
+					_, _, _, _, filter := scalingEngineDB.CountScalingHistoriesArgsForCall(0)
+					Expect(filter).To(Equal(expectedFilter))
+					_, _, _, _, _, filter, _, _ = scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+					Expect(filter).To(Equal(expectedFilter))
+				})
+
+				It("keeps the filters when linking to the next page", func() {
+					Expect(history.NextURL.IsSet()).To(BeTrue())
+					query := history.NextURL.Value.Query()
+					Expect(query["status"]).To(Equal([]string{"1", "2"}))
+					Expect(query["scaling-type"]).To(Equal([]string{"0"}))
+					Expect(query.Get("min-instance-delta")).To(Equal("-2"))
+					Expect(query.Get("max-instance-delta")).To(Equal("-1"))
+				})
+			})
+		})
+
+		Context("when exporting", func() {
+			var exported string
+
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingHistoriesReturns([]*models.AppScalingHistory{history3, history2, history1}, nil)
+			})
+
+			readExport := func(reader io.Reader) string {
+				data, err := io.ReadAll(reader)
+				Expect(err).NotTo(HaveOccurred())
+				return string(data)
+			}
+
+			Context("as CSV", func() {
+				BeforeEach(func() {
+					ctx = handlers.ContextWithAcceptHeader(context.TODO(), "text/csv")
+				})
+
+				JustBeforeEach(func() {
+					Expect(response).To(BeAssignableToTypeOf(&scalinghistory.V1AppsGUIDScalingHistoriesGetOKTextCsv{}))
+					exported = readExport(response.(*scalinghistory.V1AppsGUIDScalingHistoriesGetOKTextCsv))
+				})
+
+				It("streams all scaling histories as CSV", func() {
+					Expect(exported).To(Equal("app_id,timestamp,scaling_type,status,old_instances,new_instances,reason,message,error,emergency," +
+						"rule_index,metric_type,observed_value,threshold,operator,adjustment,schedule_id,cooldown_expired_at,correlation_id,decision_id\n" +
+						"an-app-id,444,0,2,2,4,a reason,a message,,false,,,,,,,,,,\n" +
+						"an-app-id,333,1,1,2,4,a reason,a message,an error,false,,,,,,,,,,\n" +
+						"an-app-id,222,0,0,2,4,a reason,,,true,1,cpu,90,80,>,+2,,555,a-correlation-id,a-decision-id\n"))
+					Expect(scalingEngineDB.CountScalingHistoriesCallCount()).To(Equal(0))
+				})
+
+				It("ignores the pagination", func() {
+					_, _, _, end, _, _, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+					Expect(end).To(BeNumerically(">", 0))
+					Expect(page).To(Equal(1))
+					Expect(resultsPerPage).To(Equal(500))
+				})
+
+				Context("when there are more histories than fit into one batch", func() {
+					BeforeEach(func() {
+						batch := make([]*models.AppScalingHistory, 500)
+						for i := range batch {
+							batch[i] = history1
+						}
+						scalingEngineDB.RetrieveScalingHistoriesReturnsOnCall(0, batch, nil)
+						scalingEngineDB.RetrieveScalingHistoriesReturnsOnCall(1, []*models.AppScalingHistory{history2}, nil)
+					})
+
+					It("reads the histories batch by batch", func() {
+						Expect(strings.Count(exported, "\n")).To(Equal(502))
+						Expect(scalingEngineDB.RetrieveScalingHistoriesCallCount()).To(Equal(2))
+						_, _, _, _, _, _, page, _ := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(1)
+						Expect(page).To(Equal(2))
+					})
+				})
+			})
+
+			Context("as NDJSON", func() {
+				BeforeEach(func() {
+					ctx = handlers.ContextWithAcceptHeader(context.TODO(), "application/x-ndjson")
+				})
+
+				It("streams all scaling histories as one JSON object per line", func() {
+					Expect(response).To(BeAssignableToTypeOf(&scalinghistory.V1AppsGUIDScalingHistoriesGetOKApplicationXNdjson{}))
+					lines := strings.Split(strings.TrimSuffix(readExport(response.(*scalinghistory.V1AppsGUIDScalingHistoriesGetOKApplicationXNdjson)), "\n"), "\n")
+					Expect(lines).To(HaveLen(3))
+					for i, expectedEntry := range []scalinghistory.HistoryEntry{history1Entry, history2Entry, history3Entry} {
+						entry := scalinghistory.HistoryEntry{}
+						Expect(entry.UnmarshalJSON([]byte(lines[i]))).To(Succeed())
+						Expect(entry).To(Equal(expectedEntry))
+					}
+				})
+			})
+
+			Context("when the database query fails", func() {
+				BeforeEach(func() {
+					ctx = handlers.ContextWithAcceptHeader(context.TODO(), "text/csv")
+					scalingEngineDB.RetrieveScalingHistoriesReturns(nil, errors.New("db error"))
+				})
+
+				It("aborts the export with the error", func() {
+					_, err := io.ReadAll(response.(*scalinghistory.V1AppsGUIDScalingHistoriesGetOKTextCsv))
+					Expect(err).To(MatchError("db error"))
+				})
+			})
 		})
 	})
 })
@@ -7,6 +7,7 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/healthendpoint"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/auth"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/apis/scalinghistory"
@@ -152,6 +153,14 @@ func (s *Server) createScalingEngineRoutes() (*mux.Router, error) {
 	r.Get(routes.SetActiveScheduleRouteName).Handler(VarsFunc(se.StartActiveSchedule))
 	r.Get(routes.DeleteActiveScheduleRouteName).Handler(VarsFunc(se.RemoveActiveSchedule))
 	r.Get(routes.GetActiveSchedulesRouteName).Handler(VarsFunc(se.GetActiveSchedule))
+	r.Get(routes.GetInstanceHourUsageRouteName).Handler(VarsFunc(se.GetInstanceHourUsage))
+	r.Get(routes.GetScalingAnalyticsRouteName).Handler(VarsFunc(se.GetScalingAnalytics))
+	r.Get(routes.GetScalingDecisionRouteName).Handler(VarsFunc(se.GetScalingDecision))
+	r.Get(routes.GetScalingEventsRouteName).Handler(VarsFunc(se.GetScalingEvents))
+	r.Get(routes.SaveEvaluationEventRouteName).Handler(VarsFunc(se.SaveEvaluationEvent))
+	r.Get(routes.GetGroupScalingHistoriesRouteName).Handler(VarsFunc(se.GetGroupScalingHistories))
+	r.Get(routes.GetScalingStateRouteName).Handler(VarsFunc(se.GetScalingState))
+	r.Get(routes.GetLastScalingHistoriesRouteName).Handler(VarsFunc(se.GetLastScalingHistories))
 
 	r.Get(routes.SyncActiveSchedulesRouteName).Handler(VarsFunc(syncHandler.Sync))
 	return r, nil
@@ -166,5 +175,5 @@ func newScalingHistoryHandler(logger lager.Logger, scalingEngineDB db.ScalingEng
 	if err != nil {
 		return nil, fmt.Errorf("error creating ogen scaling history server: %w", err)
 	}
-	return server, err
+	return handlers.WithAcceptHeader(server), err
 }
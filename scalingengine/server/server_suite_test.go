@@ -11,3 +11,7 @@ func TestServer(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Server Suite")
 }
+
+func ptr[T any](value T) *T {
+	return &value
+}
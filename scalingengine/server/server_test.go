@@ -145,6 +145,16 @@ var _ = Describe("Server", func() {
 				Expect(rsp.StatusCode).To(Equal(http.StatusOK))
 				rsp.Body.Close()
 			})
+
+			It("should export the scaling histories in the accepted format", func() {
+				rsp.Body.Close()
+				req.Header.Set("Accept", "text/csv")
+				csvRsp, err := http.DefaultClient.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+				defer csvRsp.Body.Close()
+				Expect(csvRsp.StatusCode).To(Equal(http.StatusOK))
+				Expect(csvRsp.Header.Get("Content-Type")).To(Equal("text/csv"))
+			})
 		})
 
 		Describe("PUT /v1/apps/{appid}/active_schedules/{scheduleid}", func() {